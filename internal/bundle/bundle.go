@@ -0,0 +1,309 @@
+// Package bundle packs the config, and optionally the memory database, into
+// one gzipped tar file, so that a new machine can be set up from another in a
+// single step. The config carries the chat system prompt, embedding template,
+// tag vocabulary, and every other setting; API keys are left out unless asked
+// for.
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// Version is the bundle format written by Export.
+const Version = 1
+
+// Entries of a bundle.
+const (
+	manifestEntry = "manifest.json"
+	settingsEntry = utils.SettingFile
+	databaseEntry = utils.DBFile
+)
+
+// Manifest describes what a bundle holds.
+type Manifest struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Secrets   bool      `json:"secrets"`  // the config includes API keys and passwords
+	Database  bool      `json:"database"` // the bundle includes the memory database
+}
+
+// ExportOptions chooses what goes into a bundle besides the config.
+type ExportOptions struct {
+	// Secrets keeps API keys, the SMTP password, the MCP HTTP token, and MCP
+	// server environments in the exported config.
+	Secrets bool
+	// Database adds a consistent copy of the memory database.
+	Database bool
+}
+
+// ImportOptions controls how a bundle replaces the local setup.
+type ImportOptions struct {
+	// Force replaces an existing config and database, after backing them up.
+	Force bool
+}
+
+// ImportResult reports what Import restored and where the replaced files
+// were backed up.
+type ImportResult struct {
+	Manifest           Manifest
+	SettingsBackupPath string
+	DatabaseBackupPath string
+}
+
+// Export writes a bundle of the current config, and the options' extras, to w.
+func Export(w io.Writer, opts ExportOptions) (*Manifest, error) {
+	configPath, err := utils.GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	config, err := readConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, utils.ConfigErrorf("no config to export at %s. Run 'gomor set' first", configPath)
+	}
+	if !opts.Secrets {
+		*config = config.WithoutSecrets()
+	}
+	settings, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	manifest := &Manifest{Version: Version, CreatedAt: time.Now().UTC(), Secrets: opts.Secrets, Database: opts.Database}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	if err := writeEntry(tw, manifestEntry, manifestData); err != nil {
+		return nil, err
+	}
+	if err := writeEntry(tw, settingsEntry, settings); err != nil {
+		return nil, err
+	}
+	if opts.Database {
+		if err := writeDatabase(tw); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return manifest, nil
+}
+
+// writeDatabase adds a copy of the memory database taken with the store's
+// backup, so the bundle is consistent even while gomor is running.
+func writeDatabase(tw *tar.Writer) error {
+	dir, err := os.MkdirTemp("", "gomor-bundle-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		return fmt.Errorf("failed to open memory store: %w", err)
+	}
+	copyPath := filepath.Join(dir, databaseEntry)
+	err = memStore.Backup(copyPath)
+	memStore.Close()
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(copyPath)
+	if err != nil {
+		return err
+	}
+	return writeEntry(tw, databaseEntry, data)
+}
+
+func writeEntry(tw *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{Name: name, Mode: 0600, Size: int64(len(data)), ModTime: time.Now()}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return nil
+}
+
+// Import restores the config, and the database when the bundle has one, from
+// the bundle read from r. A config without keys keeps the keys configured on
+// this machine. Existing files are only replaced with opts.Force, and are
+// backed up to ~/.gomor/backups first.
+func Import(r io.Reader, opts ImportOptions) (*ImportResult, error) {
+	entries, err := readEntries(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest Manifest
+	data, ok := entries[manifestEntry]
+	if !ok {
+		return nil, fmt.Errorf("not a gomor bundle: %s is missing", manifestEntry)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid bundle manifest: %w", err)
+	}
+	if manifest.Version > Version {
+		return nil, fmt.Errorf("bundle version %d is newer than this gomor supports (%d); upgrade gomor first", manifest.Version, Version)
+	}
+	settings, ok := entries[settingsEntry]
+	if !ok {
+		return nil, fmt.Errorf("not a gomor bundle: %s is missing", settingsEntry)
+	}
+	var config utils.Config
+	if err := json.Unmarshal(settings, &config); err != nil {
+		return nil, fmt.Errorf("invalid bundle config: %w", err)
+	}
+	database, hasDatabase := entries[databaseEntry]
+
+	configPath, err := utils.GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	dbPath, err := utils.GetDBPath()
+	if err != nil {
+		return nil, err
+	}
+	existing, err := readConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	dbExists := fileExists(dbPath)
+	if !opts.Force {
+		if existing != nil {
+			return nil, fmt.Errorf("%s already exists; use --force to replace it", configPath)
+		}
+		if hasDatabase && dbExists {
+			return nil, fmt.Errorf("%s already exists; use --force to replace it", dbPath)
+		}
+	}
+
+	result := &ImportResult{Manifest: manifest}
+	backupDir, err := utils.GetBackupDir()
+	if err != nil {
+		return nil, err
+	}
+	stamp := time.Now().Format("20060102-150405.000000")
+
+	if existing != nil {
+		config.KeepSecrets(existing)
+		result.SettingsBackupPath = filepath.Join(backupDir, fmt.Sprintf("settings-%s.json", stamp))
+		if err := copyFile(configPath, result.SettingsBackupPath); err != nil {
+			return nil, fmt.Errorf("failed to back up config: %w", err)
+		}
+	}
+	if hasDatabase {
+		if dbExists {
+			result.DatabaseBackupPath = filepath.Join(backupDir, fmt.Sprintf("memory-%s.db", stamp))
+			if err := backupDatabase(result.DatabaseBackupPath); err != nil {
+				return nil, err
+			}
+		}
+		// Write next to the database and rename, so a failed import never
+		// leaves half a database behind.
+		tmp := dbPath + ".import"
+		if err := os.WriteFile(tmp, database, 0600); err != nil {
+			return nil, fmt.Errorf("failed to write memory database: %w", err)
+		}
+		if err := os.Rename(tmp, dbPath); err != nil {
+			os.Remove(tmp)
+			return nil, fmt.Errorf("failed to replace memory database: %w", err)
+		}
+	}
+
+	if err := utils.SaveConfig(&config); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// readEntries reads every file of a gzipped tar bundle into memory.
+func readEntries(r io.Reader) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a gomor bundle: %w", err)
+	}
+	defer gz.Close()
+
+	entries := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		entries[header.Name] = data
+	}
+}
+
+// readConfig parses the config file at path as written, without defaults, or
+// returns nil if there is none.
+func readConfig(path string) (*utils.Config, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var config utils.Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	return &config, nil
+}
+
+func backupDatabase(path string) error {
+	memStore, err := store.NewStore()
+	if err != nil {
+		return fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+	return memStore.Backup(path)
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0600)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,101 @@
+package bundle
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	config := utils.DefaultConfig()
+	config.Providers.OpenAI.APIKey = "sk-source"
+	config.Chat.SystemPrompt = "You are terse.\n{{.History}}"
+	config.Memory.TagVocabulary = []string{"golang", "infra"}
+	if err := utils.SaveConfig(config); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	memStore, err := store.NewStore()
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	item := &memtypes.MemoryItem{Text: "deploys go out on Tuesdays", Source: memtypes.SourceExplicit}
+	if err := memStore.SaveMemory(item); err != nil {
+		t.Fatalf("save memory: %v", err)
+	}
+	memStore.Close()
+
+	var buf bytes.Buffer
+	manifest, err := Export(&buf, ExportOptions{Database: true})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if manifest.Secrets || !manifest.Database {
+		t.Fatalf("unexpected manifest: %+v", manifest)
+	}
+	data := buf.Bytes()
+
+	// A fresh machine gets the settings and memories, but not the key.
+	t.Setenv("HOME", t.TempDir())
+	result, err := Import(bytes.NewReader(data), ImportOptions{})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if result.SettingsBackupPath != "" || result.DatabaseBackupPath != "" {
+		t.Fatalf("expected nothing to back up, got %+v", result)
+	}
+	imported, err := utils.LoadConfig()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if imported.Providers.OpenAI.APIKey != "" {
+		t.Fatal("expected the API key to be left out")
+	}
+	if imported.Chat.SystemPrompt != config.Chat.SystemPrompt || len(imported.Memory.TagVocabulary) != 2 {
+		t.Fatalf("expected the settings to be restored, got %+v", imported.Chat)
+	}
+	memStore, err = store.NewStore()
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	memories, err := memStore.GetAllMemories()
+	memStore.Close()
+	if err != nil || len(memories) != 1 || memories[0].ID != item.ID {
+		t.Fatalf("expected the memory to be restored, got %+v (%v)", memories, err)
+	}
+
+	// Importing over an existing setup needs --force, and keeps local keys.
+	if _, err := Import(bytes.NewReader(data), ImportOptions{}); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected an existing config to need --force, got %v", err)
+	}
+	imported.Providers.OpenAI.APIKey = "sk-local"
+	if err := utils.SaveConfig(imported); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	result, err = Import(bytes.NewReader(data), ImportOptions{Force: true})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	for _, path := range []string{result.SettingsBackupPath, result.DatabaseBackupPath} {
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("expected a backup at %q: %v", path, err)
+		}
+	}
+	if imported, err = utils.LoadConfig(); err != nil || imported.Providers.OpenAI.APIKey != "sk-local" {
+		t.Fatalf("expected the local key to be kept (%v)", err)
+	}
+}
+
+func TestImportRejectsOtherFiles(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if _, err := Import(strings.NewReader("not a bundle"), ImportOptions{}); err == nil {
+		t.Fatal("expected an error importing a file that is not a bundle")
+	}
+}
@@ -0,0 +1,98 @@
+// Package chaos injects failures and latency into providers and the memory
+// store, so the retry, fallback, degraded-retrieval, and queueing paths can be
+// exercised by hand. It is configured under "chaos" and only applies in debug
+// mode.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// Targets failures can be injected into.
+const (
+	TargetChat      = "chat"      // every query client, whatever its model role
+	TargetEmbedding = "embedding" // embedding clients
+	TargetStore     = "store"     // memory database statements
+)
+
+// defaultErrorStatus makes injected provider errors look like an outage, which
+// the fallback chain and the embedding queue treat as transient.
+const defaultErrorStatus = http.StatusServiceUnavailable
+
+// ErrInjected is wrapped by every injected failure.
+var ErrInjected = errors.New("chaos: injected failure")
+
+// Injector decides, call by call, whether to delay or fail.
+type Injector struct {
+	config utils.ChaosConfig
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// For returns the injector for target, or nil when config injects nothing
+// there: debug is off, both rates are zero, or target is not listed.
+func For(config *utils.Config, target string) *Injector {
+	chaos := config.Chaos
+	if !config.Debug || (chaos.ErrorRate <= 0 && (chaos.LatencyRate <= 0 || chaos.LatencyMs <= 0)) {
+		return nil
+	}
+	if len(chaos.Targets) > 0 && !slices.Contains(chaos.Targets, target) {
+		return nil
+	}
+	return New(chaos)
+}
+
+// New returns an injector for config.
+func New(config utils.ChaosConfig) *Injector {
+	seed := rand.Uint64()
+	if config.Seed != nil {
+		seed = uint64(*config.Seed)
+	}
+	return &Injector{config: config, rand: rand.New(rand.NewPCG(seed, seed))}
+}
+
+// roll decides whether the next call is delayed and whether it fails.
+func (i *Injector) roll() (delay, fail bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delay = i.config.LatencyMs > 0 && i.rand.Float64() < i.config.LatencyRate
+	fail = i.rand.Float64() < i.config.ErrorRate
+	return delay, fail
+}
+
+// Inject delays the call named op and fails it, at the configured rates. It
+// returns ctx's error if ctx ends during the delay.
+func (i *Injector) Inject(ctx context.Context, op string) error {
+	delay, fail := i.roll()
+	if delay {
+		timer := time.NewTimer(time.Duration(i.config.LatencyMs) * time.Millisecond)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if fail {
+		return fmt.Errorf("%w: %s", ErrInjected, op)
+	}
+	return nil
+}
+
+// errorStatus is the HTTP status injected provider errors are classified by.
+func (i *Injector) errorStatus() int {
+	if i.config.ErrorStatus > 0 {
+		return i.config.ErrorStatus
+	}
+	return defaultErrorStatus
+}
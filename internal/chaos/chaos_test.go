@@ -0,0 +1,142 @@
+package chaos_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/chaos"
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/provider/mock"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+	_ "modernc.org/sqlite"
+)
+
+func seeded(config utils.ChaosConfig) utils.ChaosConfig {
+	seed := int64(7)
+	config.Seed = &seed
+	return config
+}
+
+func TestForOnlyInDebugModeAndListedTargets(t *testing.T) {
+	config := utils.DefaultConfig()
+	config.Chaos = utils.ChaosConfig{ErrorRate: 0.5, Targets: []string{chaos.TargetEmbedding}}
+	if chaos.For(config, chaos.TargetEmbedding) != nil {
+		t.Fatal("expected no injection outside debug mode")
+	}
+
+	config.Debug = true
+	if chaos.For(config, chaos.TargetEmbedding) == nil || chaos.For(config, chaos.TargetChat) != nil {
+		t.Fatal("expected injection only into the listed target")
+	}
+
+	config.Chaos = utils.ChaosConfig{LatencyRate: 1}
+	if chaos.For(config, chaos.TargetStore) != nil {
+		t.Fatal("expected no injection without an error rate or a latency")
+	}
+}
+
+func TestInjectFailsAtTheConfiguredRate(t *testing.T) {
+	injector := chaos.New(seeded(utils.ChaosConfig{ErrorRate: 0.3}))
+	failures := 0
+	for range 1000 {
+		if err := injector.Inject(context.Background(), "op"); err != nil {
+			if !errors.Is(err, chaos.ErrInjected) {
+				t.Fatalf("expected an injected failure, got %v", err)
+			}
+			failures++
+		}
+	}
+	if failures < 250 || failures > 350 {
+		t.Fatalf("expected about 300 failures, got %d", failures)
+	}
+
+	again := chaos.New(seeded(utils.ChaosConfig{ErrorRate: 0.3}))
+	replay := 0
+	for range 1000 {
+		if again.Inject(context.Background(), "op") != nil {
+			replay++
+		}
+	}
+	if replay != failures {
+		t.Fatalf("expected the same seed to repeat the failures, got %d and %d", failures, replay)
+	}
+}
+
+func TestInjectDelaysUntilContextEnds(t *testing.T) {
+	injector := chaos.New(utils.ChaosConfig{LatencyRate: 1, LatencyMs: 20})
+	start := time.Now()
+	if err := injector.Inject(context.Background(), "op"); err != nil {
+		t.Fatalf("expected a slow call to succeed, got %v", err)
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Fatal("expected the call to be delayed")
+	}
+
+	slow := chaos.New(utils.ChaosConfig{LatencyRate: 1, LatencyMs: 10_000})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := slow.Inject(ctx, "op"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to cut the delay short, got %v", err)
+	}
+}
+
+func TestQueryClientFailsLikeAnOutage(t *testing.T) {
+	injector := chaos.New(utils.ChaosConfig{ErrorRate: 1})
+	qc := injector.QueryClient("mock", mock.NewQueryClient())
+	_, err := qc.ChatStream(context.Background(), types.Model{Provider: "mock", ModelID: mock.ModelChat}, "hi")
+	if !errors.Is(err, chaos.ErrInjected) || !errors.Is(err, client.ErrUnavailable) || !client.ShouldFallback(err) {
+		t.Fatalf("expected an injected outage the fallback chain retries, got %v", err)
+	}
+
+	limited := chaos.New(utils.ChaosConfig{ErrorRate: 1, ErrorStatus: 429})
+	ec := limited.EmbeddingClient("mock", mock.NewEmbeddingClient())
+	if _, err := ec.Embed(context.Background(), types.Model{Provider: "mock", ModelID: mock.ModelEmbedding}, "hi"); !errors.Is(err, client.ErrRateLimited) {
+		t.Fatalf("expected a rate limit, got %v", err)
+	}
+	if ec.Dimensions(types.Model{Provider: "mock", ModelID: mock.ModelEmbedding}) != 512 {
+		t.Fatal("expected Dimensions to pass through")
+	}
+}
+
+func TestConnectorInjectsIntoTheStore(t *testing.T) {
+	open := func(config utils.ChaosConfig) *store.Store {
+		t.Helper()
+		base, err := sql.Open("sqlite", ":memory:")
+		if err != nil {
+			t.Fatalf("open sqlite: %v", err)
+		}
+		db := sql.OpenDB(chaos.New(config).Connector(base.Driver(), ":memory:"))
+		db.SetMaxOpenConns(1)
+		base.Close()
+		s, err := store.NewStoreWithDB(db)
+		if err != nil {
+			t.Fatalf("new store: %v", err)
+		}
+		t.Cleanup(func() { s.Close() })
+		return s
+	}
+
+	calm := open(utils.ChaosConfig{})
+	item := &memtypes.MemoryItem{Text: "Deploys run on Argo", Embedding: []float32{0.6, 0.8}, Dim: 2, CreatedAt: time.Now()}
+	if err := calm.SaveMemory(item); err != nil {
+		t.Fatalf("save through the connector: %v", err)
+	}
+	got, err := calm.GetAllMemories()
+	if err != nil || len(got) != 1 || got[0].Text != item.Text || len(got[0].Embedding) != 2 {
+		t.Fatalf("expected the memory back through the connector, got %+v, %v", got, err)
+	}
+
+	failing := chaos.New(utils.ChaosConfig{ErrorRate: 1})
+	base, _ := sql.Open("sqlite", ":memory:")
+	db := sql.OpenDB(failing.Connector(base.Driver(), ":memory:"))
+	defer db.Close()
+	if _, err := db.Exec("SELECT 1"); !errors.Is(err, chaos.ErrInjected) {
+		t.Fatalf("expected an injected store failure, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package chaos
+
+import (
+	"context"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+// QueryClient wraps qc, served by provider, so its calls can be delayed or
+// fail. Injected failures are classified like a provider error with the
+// configured status. Tool calling is kept when qc supports it.
+func (i *Injector) QueryClient(provider string, qc client.QueryClient) client.QueryClient {
+	wrapped := &queryClient{QueryClient: qc, injector: i, provider: provider}
+	if tc, ok := qc.(client.ToolClient); ok {
+		return &toolQueryClient{queryClient: wrapped, tools: tc}
+	}
+	return wrapped
+}
+
+// EmbeddingClient wraps ec, served by provider, so its calls can be delayed
+// or fail.
+func (i *Injector) EmbeddingClient(provider string, ec client.EmbeddingClient) client.EmbeddingClient {
+	return &embeddingClient{EmbeddingClient: ec, injector: i, provider: provider}
+}
+
+// providerError injects into the call named op, as provider would fail.
+func (i *Injector) providerError(ctx context.Context, provider, op string) error {
+	if err := i.Inject(ctx, op); err != nil {
+		return client.WrapError(provider, i.errorStatus(), err)
+	}
+	return nil
+}
+
+type queryClient struct {
+	client.QueryClient
+	injector *Injector
+	provider string
+}
+
+func (q *queryClient) ChatStream(ctx context.Context, model types.Model, query string) (client.StreamResponse, error) {
+	if err := q.injector.providerError(ctx, q.provider, "chat"); err != nil {
+		return nil, err
+	}
+	return q.QueryClient.ChatStream(ctx, model, query)
+}
+
+func (q *queryClient) ChatStreamWithContext(ctx context.Context, model types.Model, systemContext, query string) (client.StreamResponse, error) {
+	if err := q.injector.providerError(ctx, q.provider, "chat"); err != nil {
+		return nil, err
+	}
+	return q.QueryClient.ChatStreamWithContext(ctx, model, systemContext, query)
+}
+
+func (q *queryClient) ListModels(ctx context.Context) ([]string, error) {
+	if err := q.injector.providerError(ctx, q.provider, "list models"); err != nil {
+		return nil, err
+	}
+	return q.QueryClient.ListModels(ctx)
+}
+
+type toolQueryClient struct {
+	*queryClient
+	tools client.ToolClient
+}
+
+func (q *toolQueryClient) ChatWithTools(ctx context.Context, model types.Model, systemContext string, messages []client.ToolMessage, tools []client.Tool) (*client.ToolReply, error) {
+	if err := q.injector.providerError(ctx, q.provider, "chat with tools"); err != nil {
+		return nil, err
+	}
+	return q.tools.ChatWithTools(ctx, model, systemContext, messages, tools)
+}
+
+type embeddingClient struct {
+	client.EmbeddingClient
+	injector *Injector
+	provider string
+}
+
+func (e *embeddingClient) Embed(ctx context.Context, model types.Model, text string) ([]float32, error) {
+	if err := e.injector.providerError(ctx, e.provider, "embed"); err != nil {
+		return nil, err
+	}
+	return e.EmbeddingClient.Embed(ctx, model, text)
+}
+
+func (e *embeddingClient) EmbedBatch(ctx context.Context, model types.Model, texts []string) ([][]float32, error) {
+	if err := e.injector.providerError(ctx, e.provider, "embed batch"); err != nil {
+		return nil, err
+	}
+	return e.EmbeddingClient.EmbedBatch(ctx, model, texts)
+}
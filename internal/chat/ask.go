@@ -0,0 +1,137 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/pricing"
+	"github.com/austiecodes/gomor/internal/tokenizer"
+	"github.com/austiecodes/gomor/internal/trace"
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+// Ask streams the answer to a one-off prompt into out and returns it. Unlike
+// Session.Send, the turns are not recorded. A non-nil budget is checked before
+// sending and charged afterwards.
+func Ask(ctx context.Context, queryClient client.QueryClient, model types.Model, prompt string, budget *pricing.Budget, out io.Writer) (string, error) {
+	return AskWithContext(ctx, queryClient, model, "", prompt, budget, out)
+}
+
+// AskWithContext is Ask with systemContext, when not empty, sent as the
+// system message ahead of the prompt.
+func AskWithContext(ctx context.Context, queryClient client.QueryClient, model types.Model, systemContext, prompt string, budget *pricing.Budget, out io.Writer) (string, error) {
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		return "", fmt.Errorf("prompt must be a non-empty string")
+	}
+
+	counter := tokenizer.ForModel(model)
+	usage := tokenizer.Usage{InputTokens: tokenizer.EstimateInput(counter, systemContext, prompt)}
+	if budget != nil {
+		if err := budget.Check(model, usage.InputTokens); err != nil {
+			return "", err
+		}
+	}
+
+	trace.Printf(trace.Info, "request: %s, about %d input tokens", trace.Model(model), usage.InputTokens)
+	if systemContext != "" {
+		trace.Block(trace.Debug, "context", systemContext)
+	}
+	trace.Block(trace.Debug, "prompt", prompt)
+	start := time.Now()
+
+	var stream client.StreamResponse
+	var err error
+	if systemContext != "" {
+		stream, err = queryClient.ChatStreamWithContext(ctx, model, systemContext, prompt)
+	} else {
+		stream, err = queryClient.ChatStream(ctx, model, prompt)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to start chat: %w", err)
+	}
+	defer stream.Close()
+
+	answer, first, err := copyStream(out, stream)
+	usage.OutputTokens = counter.Count(answer)
+	traceUsage(model, usage, budget, start, first)
+	if budget != nil {
+		if rerr := budget.Record(model, usage); rerr != nil && err == nil {
+			err = rerr
+		}
+	}
+	return answer, err
+}
+
+// traceUsage reports the estimated usage and cost of a request sent at start,
+// and, when the answer was streamed, the time to its first chunk at first and
+// the output rate from then on.
+func traceUsage(model types.Model, usage tokenizer.Usage, budget *pricing.Budget, start, first time.Time) {
+	if !trace.Enabled(trace.Info) {
+		return
+	}
+	line := fmt.Sprintf("usage: %d input + %d output tokens (estimated) in %s", usage.InputTokens, usage.OutputTokens, trace.Since(start))
+	if !first.IsZero() {
+		line += fmt.Sprintf(", first token after %s", first.Sub(start).Round(time.Millisecond))
+		if elapsed := time.Since(first).Seconds(); elapsed > 0 {
+			line += fmt.Sprintf(", %.0f tokens/s", float64(usage.OutputTokens)/elapsed)
+		}
+	}
+	var cost float64
+	var ok bool
+	if budget != nil {
+		cost, ok = budget.Cost(model, usage)
+	} else if price, known := pricing.Lookup(model, nil); known {
+		cost, ok = pricing.Cost(price, usage), true
+	}
+	if ok {
+		line += fmt.Sprintf(", about $%.4f", cost)
+	}
+	trace.Printf(trace.Info, "%s", line)
+}
+
+// copyStream writes each chunk of stream to out as it arrives and returns the
+// full answer and when its first chunk arrived, zero if none did. On a stream
+// error the partial answer is returned with the error.
+func copyStream(out io.Writer, stream client.StreamResponse) (string, time.Time, error) {
+	var answer strings.Builder
+	var first time.Time
+	for stream.Next() {
+		chunk := stream.GetChunk()
+		if first.IsZero() && chunk != "" {
+			first = time.Now()
+		}
+		answer.WriteString(chunk)
+		if _, err := io.WriteString(out, chunk); err != nil {
+			return "", first, err
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return answer.String(), first, fmt.Errorf("chat stream failed: %w", err)
+	}
+	return answer.String(), first, nil
+}
+
+// SwitchNotice returns a client.SwitchFunc that tells the user on w which
+// model took over an answer and why.
+func SwitchNotice(w io.Writer) client.SwitchFunc {
+	return func(from, to types.Model, err error) {
+		fmt.Fprintf(w, "Note: %s/%s %s; answering with %s/%s\n", from.Provider, from.ModelID, switchReason(err), to.Provider, to.ModelID)
+	}
+}
+
+func switchReason(err error) string {
+	switch {
+	case errors.Is(err, client.ErrRateLimited):
+		return "is rate limited"
+	case errors.Is(err, client.ErrUnavailable):
+		return "is unavailable"
+	default:
+		return "is unreachable"
+	}
+}
@@ -0,0 +1,314 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/tokenizer"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// maxSummarizedTurns bounds how many older turns the summary policy reads.
+const maxSummarizedTurns = 200
+
+// maxBudgetTurns bounds how many turns the token budget policy considers.
+const maxBudgetTurns = 500
+
+const summaryPrompt = `Summarize the earlier part of a conversation below in a short paragraph.
+Keep facts, names, decisions, and open questions the rest of the conversation may
+refer to. Return only the summary.
+
+%s`
+
+// ContextBuilder assembles earlier turns of a session into the system context
+// sent with the next prompt, following one of the chat context policies.
+type ContextBuilder struct {
+	policy      string
+	turns       int
+	tokens      int
+	counter     tokenizer.Counter
+	queryClient client.QueryClient
+	model       types.Model
+
+	// system renders chat.system_prompt; nil sends the history alone.
+	system *template.Template
+
+	// The summary policy re-summarizes only when older turns change.
+	summaryOf string
+	summary   string
+}
+
+// PromptData is what a chat.system_prompt template can reference.
+type PromptData struct {
+	History string // earlier turns, as the context policy renders them
+	Date    string // today, as YYYY-MM-DD
+	CWD     string // the working directory
+
+	memories func() string
+}
+
+// Memories returns the memories relevant to the prompt. They are retrieved
+// only when the template uses them.
+func (d PromptData) Memories() string {
+	if d.memories == nil {
+		return ""
+	}
+	return d.memories()
+}
+
+// NewContextBuilder creates a builder for config's context policy. model and
+// queryClient are used to count tokens and, for the summary policy, to
+// summarize older turns.
+func NewContextBuilder(config utils.ChatConfig, queryClient client.QueryClient, model types.Model) (*ContextBuilder, error) {
+	defaults := utils.DefaultConfig().Chat
+	b := &ContextBuilder{
+		policy:      config.ContextPolicy,
+		turns:       config.ContextTurns,
+		tokens:      config.ContextTokens,
+		counter:     tokenizer.ForModel(model),
+		queryClient: queryClient,
+		model:       model,
+	}
+	if b.policy == "" {
+		b.policy = defaults.ContextPolicy
+	}
+	if b.turns <= 0 {
+		b.turns = defaults.ContextTurns
+	}
+	if b.tokens <= 0 {
+		b.tokens = defaults.ContextTokens
+	}
+
+	if config.SystemPrompt != "" {
+		system, err := template.New("system_prompt").Parse(config.SystemPrompt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chat system_prompt: %w", err)
+		}
+		b.system = system
+	}
+
+	switch b.policy {
+	case utils.ContextPolicyLastTurns, utils.ContextPolicyTokenBudget, utils.ContextPolicySummary:
+		return b, nil
+	default:
+		return nil, fmt.Errorf("unknown chat context policy %q (want %s, %s, or %s)", b.policy,
+			utils.ContextPolicyLastTurns, utils.ContextPolicyTokenBudget, utils.ContextPolicySummary)
+	}
+}
+
+// limit is how many of the most recent turns Build needs to see.
+func (b *ContextBuilder) limit() int {
+	switch b.policy {
+	case utils.ContextPolicyTokenBudget:
+		return maxBudgetTurns
+	case utils.ContextPolicySummary:
+		return b.turns + maxSummarizedTurns
+	default:
+		return b.turns
+	}
+}
+
+// Build renders history, oldest first, as a system context block. Only the
+// latest of several retried answers to a prompt is included.
+func (b *ContextBuilder) Build(ctx context.Context, history []memtypes.HistoryItem) string {
+	history = latestAnswers(history)
+	if len(history) == 0 {
+		return ""
+	}
+
+	switch b.policy {
+	case utils.ContextPolicyTokenBudget:
+		return b.fitBudget(history)
+	case utils.ContextPolicySummary:
+		return b.summarize(ctx, history)
+	default:
+		return renderTurns(lastTurns(history, b.turns))
+	}
+}
+
+// System renders the system context for the next prompt: the history as
+// Build renders it, filled into chat.system_prompt when one is set. memories
+// is called only if the template uses {{.Memories}}.
+func (b *ContextBuilder) System(ctx context.Context, history []memtypes.HistoryItem, memories func() string) (string, error) {
+	conversation := b.Build(ctx, history)
+	if b.system == nil {
+		return conversation, nil
+	}
+
+	cwd, _ := os.Getwd()
+	data := PromptData{
+		History:  conversation,
+		Date:     time.Now().Format("2006-01-02"),
+		CWD:      cwd,
+		memories: memories,
+	}
+	var sb strings.Builder
+	if err := b.system.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render chat system_prompt: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// fitBudget keeps the first turn and as many recent turns as fit in the token
+// budget, dropping turns from the middle of the conversation first.
+func (b *ContextBuilder) fitBudget(history []memtypes.HistoryItem) string {
+	costs := make([]int, len(history))
+	total := b.counter.Count(conversationHeader)
+	for i, h := range history {
+		costs[i] = b.counter.Count(renderTurn(h))
+		total += costs[i]
+	}
+	if total <= b.tokens {
+		return renderTurns(history)
+	}
+
+	// Drop from just after the first turn until the rest fits with a marker.
+	dropped := 0
+	for dropped < len(history)-2 {
+		dropped++
+		total -= costs[dropped]
+		if total+b.counter.Count(omittedMarker(dropped)) <= b.tokens {
+			break
+		}
+	}
+	kept := append([]memtypes.HistoryItem{history[0]}, history[dropped+1:]...)
+	if total+b.counter.Count(omittedMarker(dropped)) <= b.tokens {
+		return renderWithOmitted(kept[:1], dropped, kept[1:])
+	}
+
+	// Not even the first and last turns fit: keep the middle-truncated last one.
+	last := history[len(history)-1]
+	budget := b.tokens - b.counter.Count(conversationHeader) - b.counter.Count(omittedMarker(len(history)-1))
+	last.Content = truncateMiddle(last.Content, b.counter, budget-b.counter.Count(last.Role+": \n"))
+	return renderWithOmitted(nil, len(history)-1, []memtypes.HistoryItem{last})
+}
+
+// summarize sends the most recent turns verbatim, preceded by a summary of
+// the older ones. If summarizing fails the older turns are left out.
+func (b *ContextBuilder) summarize(ctx context.Context, history []memtypes.HistoryItem) string {
+	if len(history) <= b.turns {
+		return renderTurns(history)
+	}
+	older, recent := history[:len(history)-b.turns], history[len(history)-b.turns:]
+
+	key := older[0].ID + ".." + older[len(older)-1].ID
+	if b.summaryOf != key {
+		summary, err := b.requestSummary(ctx, older)
+		if err != nil || summary == "" {
+			return renderTurns(recent)
+		}
+		b.summaryOf, b.summary = key, summary
+	}
+
+	return "Summary of earlier turns:\n" + b.summary + "\n\n" + renderTurns(recent)
+}
+
+func (b *ContextBuilder) requestSummary(ctx context.Context, older []memtypes.HistoryItem) (string, error) {
+	if b.queryClient == nil {
+		return "", fmt.Errorf("no chat client to summarize with")
+	}
+
+	var sb strings.Builder
+	for _, h := range older {
+		sb.WriteString(renderTurn(h))
+	}
+
+	stream, err := b.queryClient.ChatStream(ctx, b.model, fmt.Sprintf(summaryPrompt, sb.String()))
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+
+	var summary strings.Builder
+	for stream.Next() {
+		summary.WriteString(stream.GetChunk())
+	}
+	if err := stream.Err(); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(summary.String()), nil
+}
+
+const conversationHeader = "Conversation so far:\n"
+
+// latestAnswers drops answers superseded by a later retry of the same prompt.
+func latestAnswers(history []memtypes.HistoryItem) []memtypes.HistoryItem {
+	latest := make(map[string]string)
+	for _, h := range history {
+		if h.ParentID != "" {
+			latest[h.ParentID] = h.ID
+		}
+	}
+
+	kept := make([]memtypes.HistoryItem, 0, len(history))
+	for _, h := range history {
+		if h.ParentID != "" && latest[h.ParentID] != h.ID {
+			continue
+		}
+		kept = append(kept, h)
+	}
+	return kept
+}
+
+func lastTurns(history []memtypes.HistoryItem, n int) []memtypes.HistoryItem {
+	if len(history) > n {
+		return history[len(history)-n:]
+	}
+	return history
+}
+
+func renderTurn(h memtypes.HistoryItem) string {
+	return fmt.Sprintf("%s: %s\n", h.Role, strings.TrimSpace(h.Content))
+}
+
+func renderTurns(history []memtypes.HistoryItem) string {
+	return renderWithOmitted(history, 0, nil)
+}
+
+func omittedMarker(n int) string {
+	return fmt.Sprintf("[... %d earlier turns omitted ...]\n", n)
+}
+
+// renderWithOmitted renders head and tail with a marker for the omitted turns
+// between them.
+func renderWithOmitted(head []memtypes.HistoryItem, omitted int, tail []memtypes.HistoryItem) string {
+	var sb strings.Builder
+	sb.WriteString(conversationHeader)
+	for _, h := range head {
+		sb.WriteString(renderTurn(h))
+	}
+	if omitted > 0 {
+		sb.WriteString(omittedMarker(omitted))
+	}
+	for _, h := range tail {
+		sb.WriteString(renderTurn(h))
+	}
+	return sb.String()
+}
+
+// truncateMiddle shortens text to at most budget tokens by cutting out its
+// middle, keeping the beginning and the end.
+func truncateMiddle(text string, counter tokenizer.Counter, budget int) string {
+	const marker = " [...] "
+	if counter.Count(text) <= budget {
+		return text
+	}
+
+	runes := []rune(text)
+	keep := len(runes)
+	for keep > 0 {
+		keep = keep * 9 / 10
+		candidate := string(runes[:keep/2]) + marker + string(runes[len(runes)-keep/2:])
+		if counter.Count(candidate) <= budget {
+			return candidate
+		}
+	}
+	return marker
+}
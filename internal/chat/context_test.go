@@ -0,0 +1,123 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/consts"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+func testTurns(n int) []memtypes.HistoryItem {
+	turns := make([]memtypes.HistoryItem, n)
+	for i := range turns {
+		role := "user"
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		turns[i] = memtypes.HistoryItem{ID: fmt.Sprintf("t%d", i), Role: role, Content: fmt.Sprintf("turn %d", i)}
+	}
+	return turns
+}
+
+func newTestBuilder(t *testing.T, config utils.ChatConfig, qc *fakeQueryClient) *ContextBuilder {
+	t.Helper()
+	b, err := NewContextBuilder(config, qc, types.Model{Provider: consts.ProviderOpenAI, ModelID: "fake-chat"})
+	if err != nil {
+		t.Fatalf("new context builder: %v", err)
+	}
+	return b
+}
+
+func TestContextLastTurns(t *testing.T) {
+	b := newTestBuilder(t, utils.ChatConfig{ContextPolicy: utils.ContextPolicyLastTurns, ContextTurns: 2}, nil)
+
+	got := b.Build(context.Background(), testTurns(5))
+	if got != "Conversation so far:\nassistant: turn 3\nuser: turn 4\n" {
+		t.Fatalf("unexpected context %q", got)
+	}
+	if b.Build(context.Background(), nil) != "" {
+		t.Fatal("expected no context without history")
+	}
+}
+
+func TestContextTokenBudgetDropsMiddleTurns(t *testing.T) {
+	b := newTestBuilder(t, utils.ChatConfig{ContextPolicy: utils.ContextPolicyTokenBudget, ContextTokens: 30}, nil)
+
+	got := b.Build(context.Background(), testTurns(20))
+	if !strings.Contains(got, "user: turn 0\n[... ") || !strings.HasSuffix(got, "assistant: turn 19\n") {
+		t.Fatalf("expected first and latest turns around an omission marker, got %q", got)
+	}
+	if strings.Contains(got, "turn 10\n") {
+		t.Fatalf("expected middle turns to be dropped, got %q", got)
+	}
+	if tokens := b.counter.Count(got); tokens > 30 {
+		t.Fatalf("context uses %d tokens, over the budget of 30", tokens)
+	}
+}
+
+func TestContextTokenBudgetTruncatesLongTurn(t *testing.T) {
+	b := newTestBuilder(t, utils.ChatConfig{ContextPolicy: utils.ContextPolicyTokenBudget, ContextTokens: 40}, nil)
+
+	history := testTurns(2)
+	history[1].Content = "start " + strings.Repeat("x", 1000) + " end"
+
+	got := b.Build(context.Background(), history)
+	if !strings.Contains(got, "assistant: start") || !strings.HasSuffix(got, " end\n") || !strings.Contains(got, "[...]") {
+		t.Fatalf("expected the long turn to keep its start and end, got %q", got)
+	}
+	if tokens := b.counter.Count(got); tokens > 40 {
+		t.Fatalf("context uses %d tokens, over the budget of 40", tokens)
+	}
+}
+
+func TestContextSummaryOfOlderTurns(t *testing.T) {
+	qc := &fakeQueryClient{chunks: []string{"they discussed turns"}}
+	b := newTestBuilder(t, utils.ChatConfig{ContextPolicy: utils.ContextPolicySummary, ContextTurns: 2}, qc)
+
+	history := testTurns(6)
+	got := b.Build(context.Background(), history)
+	want := "Summary of earlier turns:\nthey discussed turns\n\nConversation so far:\nuser: turn 4\nassistant: turn 5\n"
+	if got != want {
+		t.Fatalf("unexpected context %q", got)
+	}
+
+	b.Build(context.Background(), history)
+	if len(qc.contexts) != 1 {
+		t.Fatalf("expected the summary to be reused, got %d summary requests", len(qc.contexts))
+	}
+}
+
+func TestNewContextBuilderRejectsUnknownPolicy(t *testing.T) {
+	if _, err := NewContextBuilder(utils.ChatConfig{ContextPolicy: "everything"}, nil, types.Model{}); err == nil {
+		t.Fatal("expected error for an unknown policy")
+	}
+}
+
+func TestContextSystemPrompt(t *testing.T) {
+	b := newTestBuilder(t, utils.ChatConfig{ContextTurns: 1, SystemPrompt: "Today is {{.Date}} in {{.CWD}}.\n{{.History}}"}, nil)
+
+	got, err := b.System(context.Background(), testTurns(2), nil)
+	if err != nil {
+		t.Fatalf("system: %v", err)
+	}
+	cwd, _ := os.Getwd()
+	want := "Today is " + time.Now().Format("2006-01-02") + " in " + cwd + ".\nConversation so far:\nassistant: turn 1\n"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	if _, err := NewContextBuilder(utils.ChatConfig{SystemPrompt: "{{.Memories"}, nil, types.Model{}); err == nil {
+		t.Fatal("expected an error for a malformed template")
+	}
+	b = newTestBuilder(t, utils.ChatConfig{SystemPrompt: "{{.Unknown}}"}, nil)
+	if _, err := b.System(context.Background(), nil, nil); err == nil {
+		t.Fatal("expected an error for an unknown placeholder")
+	}
+}
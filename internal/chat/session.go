@@ -0,0 +1,453 @@
+// Package chat runs conversations against the configured chat model and
+// records each turn in the history table.
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/hooks"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/moderation"
+	"github.com/austiecodes/gomor/internal/pricing"
+	"github.com/austiecodes/gomor/internal/tokenizer"
+	"github.com/austiecodes/gomor/internal/trace"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+	"github.com/google/uuid"
+)
+
+// ErrNothingToRetry is returned by Retry when the session has no prompt yet.
+var ErrNothingToRetry = errors.New("no previous prompt to retry")
+
+// maxConversationTurns bounds how many turns Conversation loads.
+const maxConversationTurns = 10000
+
+// Session is one conversation. Its turns are stored under ID so that later
+// prompts, retrieval rewriting, and prompt recall can see them.
+type Session struct {
+	ID           string
+	store        *store.Store
+	queryClient  client.QueryClient
+	model        types.Model
+	builder      *ContextBuilder
+	usage        tokenizer.Usage
+	started      time.Time // when the current request was sent
+	firstChunk   time.Time // when the first chunk of its answer arrived
+	budget       *pricing.Budget
+	moderator    *moderation.Moderator
+	tools        ToolRunner
+	toolNotice   io.Writer
+	hooks        *hooks.Runner
+	hookNotice   io.Writer
+	recall       Recall
+	recallNotice io.Writer
+	titler       Titler
+	titling      sync.WaitGroup
+}
+
+// Recall returns the memories relevant to prompt as text for the system
+// prompt's {{.Memories}}.
+type Recall func(ctx context.Context, prompt string) (string, error)
+
+// ToolRunner offers tools to the chat model and runs the calls it makes;
+// *mcpclient.Toolset implements it.
+type ToolRunner interface {
+	Tools() []client.Tool
+	Instructions() string
+	Call(ctx context.Context, name, arguments string) (string, error)
+}
+
+// maxToolRounds bounds how many times the model may call tools for one answer.
+const maxToolRounds = 10
+
+// NewSession creates a session, generating an ID when id is empty.
+func NewSession(s *store.Store, queryClient client.QueryClient, model types.Model, id string) *Session {
+	if id == "" {
+		id = uuid.New().String()
+	}
+	builder, _ := NewContextBuilder(utils.ChatConfig{}, queryClient, model)
+	return &Session{
+		ID:          id,
+		store:       s,
+		queryClient: queryClient,
+		model:       model,
+		builder:     builder,
+	}
+}
+
+// SetContextConfig selects how earlier turns are assembled into context. The
+// default sends the 20 most recent turns.
+func (s *Session) SetContextConfig(config utils.ChatConfig) error {
+	builder, err := NewContextBuilder(config, s.queryClient, s.model)
+	if err != nil {
+		return err
+	}
+	s.builder = builder
+	return nil
+}
+
+// Send streams the answer to prompt into out and records both turns. Earlier
+// turns of the session are passed to the model as context. After the first
+// exchange the session is named by the Titler, if one is set.
+func (s *Session) Send(ctx context.Context, prompt string, out io.Writer) (string, error) {
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		return "", fmt.Errorf("prompt must be a non-empty string")
+	}
+	prompt, err := s.preQuery(ctx, "chat", prompt)
+	if err != nil {
+		return "", err
+	}
+
+	history, err := s.store.GetConversation(s.ID, s.builder.limit())
+	if err != nil {
+		return "", err
+	}
+
+	systemContext, err := s.systemContext(ctx, history, prompt)
+	if err != nil {
+		return "", err
+	}
+	answer, err := s.stream(ctx, systemContext, prompt, out)
+	if err != nil {
+		return answer, service.RecordInterrupted(ctx, answer, err, func(ctx context.Context) error {
+			return s.record(ctx, prompt, answer, true)
+		})
+	}
+
+	if err := s.record(ctx, prompt, answer, false); err != nil {
+		return answer, err
+	}
+	if len(history) == 0 {
+		s.startTitle(ctx, prompt, answer)
+	}
+	s.postResponse(ctx, "chat", prompt, answer)
+	return answer, nil
+}
+
+// Model returns the model the session answers with.
+func (s *Session) Model() types.Model {
+	return s.model
+}
+
+// SetModel switches the session to answer with model through queryClient.
+// Earlier turns are kept and sent to the new model as context.
+func (s *Session) SetModel(queryClient client.QueryClient, model types.Model) {
+	s.queryClient = queryClient
+	s.model = model
+	s.builder.queryClient = queryClient
+	s.builder.model = model
+	s.builder.counter = tokenizer.ForModel(model)
+}
+
+// SetBudget checks each request against budget before sending it and records
+// what it cost afterwards.
+func (s *Session) SetBudget(budget *pricing.Budget) {
+	s.budget = budget
+}
+
+// SetTools lets the chat model call the tools of runner when its client
+// supports tool calls. Each call is reported on notice.
+func (s *Session) SetTools(runner ToolRunner, notice io.Writer) {
+	if notice == nil {
+		notice = io.Discard
+	}
+	s.tools = runner
+	s.toolNotice = notice
+}
+
+// SetHooks runs the pre_query and post_response hooks of runner around each
+// Send and Retry. Failed post_response hooks are reported on notice.
+func (s *Session) SetHooks(runner *hooks.Runner, notice io.Writer) {
+	if notice == nil {
+		notice = io.Discard
+	}
+	s.hooks = runner
+	s.hookNotice = notice
+}
+
+// SetRecall fills in {{.Memories}} in chat.system_prompt with recall. Failed
+// lookups are reported on notice and leave the memories out.
+func (s *Session) SetRecall(recall Recall, notice io.Writer) {
+	if notice == nil {
+		notice = io.Discard
+	}
+	s.recall = recall
+	s.recallNotice = notice
+}
+
+// systemContext renders the system context for prompt, recalling memories at
+// most once and only when the system prompt asks for them.
+func (s *Session) systemContext(ctx context.Context, history []memtypes.HistoryItem, prompt string) (string, error) {
+	var memories func() string
+	if s.recall != nil {
+		var text string
+		recalled := false
+		memories = func() string {
+			if !recalled {
+				recalled = true
+				var err error
+				if text, err = s.recall(ctx, prompt); err != nil {
+					fmt.Fprintf(s.recallNotice, "Warning: memory retrieval failed: %v\n", err)
+				}
+			}
+			return text
+		}
+	}
+	return s.builder.System(ctx, history, memories)
+}
+
+func (s *Session) preQuery(ctx context.Context, command, prompt string) (string, error) {
+	return s.hooks.PreQuery(ctx, hooks.Query{Command: command, SessionID: s.ID, Model: hooks.ModelName(s.model), Prompt: prompt})
+}
+
+func (s *Session) postResponse(ctx context.Context, command, prompt, answer string) {
+	event := hooks.NewResponse(command, s.ID, s.model, prompt, answer, s.usage, s.started)
+	if err := s.hooks.PostResponse(ctx, event); err != nil {
+		fmt.Fprintf(s.hookNotice, "Warning: %v\n", err)
+	}
+}
+
+// stream sends prompt with systemContext and copies the answer into out,
+// tracking the request's token usage. Input tokens are estimated before the
+// request is sent.
+func (s *Session) stream(ctx context.Context, systemContext, prompt string, out io.Writer) (string, error) {
+	s.usage = tokenizer.Usage{InputTokens: tokenizer.EstimateInput(s.builder.counter, systemContext, prompt)}
+	if s.budget != nil {
+		if err := s.budget.Check(s.model, s.usage.InputTokens); err != nil {
+			return "", err
+		}
+	}
+	trace.Printf(trace.Info, "request: %s, %d characters of context, about %d input tokens", trace.Model(s.model), len(systemContext), s.usage.InputTokens)
+	trace.Block(trace.Debug, "context", systemContext)
+	trace.Block(trace.Debug, "prompt", prompt)
+	s.started = time.Now()
+	s.firstChunk = time.Time{}
+	if toolClient, ok := s.queryClient.(client.ToolClient); ok && s.tools != nil {
+		if tools := s.tools.Tools(); len(tools) > 0 {
+			return s.answerWithTools(ctx, toolClient, tools, systemContext, prompt, out)
+		}
+	}
+
+	stream, err := s.queryClient.ChatStreamWithContext(ctx, s.model, systemContext, prompt)
+	if err != nil {
+		return "", fmt.Errorf("failed to start chat: %w", err)
+	}
+	defer stream.Close()
+
+	answer, first, err := copyStream(out, stream)
+	s.firstChunk = first
+	s.usage.OutputTokens = s.builder.counter.Count(answer)
+	return answer, s.recordUsage(err)
+}
+
+// answerWithTools runs the tool calls the model asks for and sends their
+// results back until it answers, then writes the answer to out. Every round
+// counts toward the request's usage. Failed calls are reported to the model,
+// which may try another way.
+func (s *Session) answerWithTools(ctx context.Context, toolClient client.ToolClient, tools []client.Tool, systemContext, prompt string, out io.Writer) (string, error) {
+	if instructions := s.tools.Instructions(); instructions != "" {
+		systemContext = strings.TrimSpace(systemContext + "\n\n" + instructions)
+	}
+	messages := []client.ToolMessage{{Role: client.ToolRoleUser, Content: prompt}}
+	s.usage = tokenizer.Usage{}
+
+	for round := 0; round < maxToolRounds; round++ {
+		s.usage.InputTokens += tokenizer.EstimateInput(s.builder.counter, append([]string{systemContext}, toolMessageTexts(messages)...)...)
+		reply, err := toolClient.ChatWithTools(ctx, s.model, systemContext, messages, tools)
+		if err != nil {
+			if round == 0 {
+				return "", fmt.Errorf("failed to start chat: %w", err)
+			}
+			return "", s.recordUsage(err)
+		}
+		s.usage.OutputTokens += s.builder.counter.Count(reply.Content)
+		for _, call := range reply.ToolCalls {
+			s.usage.OutputTokens += s.builder.counter.Count(call.Name + call.Arguments)
+		}
+
+		if len(reply.ToolCalls) == 0 {
+			_, err := io.WriteString(out, reply.Content)
+			return reply.Content, s.recordUsage(err)
+		}
+
+		messages = append(messages, client.ToolMessage{Role: client.ToolRoleAssistant, Content: reply.Content, ToolCalls: reply.ToolCalls})
+		for _, call := range reply.ToolCalls {
+			fmt.Fprintf(s.toolNotice, "Tool: %s %s\n", call.Name, call.Arguments)
+			result, err := s.tools.Call(ctx, call.Name, call.Arguments)
+			if ctx.Err() != nil {
+				return "", s.recordUsage(ctx.Err())
+			}
+			if err != nil {
+				result = "Error: " + err.Error()
+			}
+			messages = append(messages, client.ToolMessage{Role: client.ToolRoleTool, Content: result, ToolCallID: call.ID, ToolName: call.Name})
+		}
+	}
+	return "", s.recordUsage(fmt.Errorf("the chat model was still calling tools after %d rounds", maxToolRounds))
+}
+
+func toolMessageTexts(messages []client.ToolMessage) []string {
+	texts := make([]string, 0, len(messages))
+	for _, m := range messages {
+		text := m.Content
+		for _, call := range m.ToolCalls {
+			text += call.Name + call.Arguments
+		}
+		texts = append(texts, text)
+	}
+	return texts
+}
+
+// recordUsage records the request's usage against the budget, returning err
+// or, when there is none, a failure to record.
+func (s *Session) recordUsage(err error) error {
+	traceUsage(s.model, s.usage, s.budget, s.started, s.firstChunk)
+	if s.budget != nil {
+		if rerr := s.budget.Record(s.model, s.usage); rerr != nil && err == nil {
+			err = rerr
+		}
+	}
+	return err
+}
+
+// SetModerator screens each prompt and answer before it is recorded. Blocked
+// turns are recorded as a placeholder, so the conversation keeps its shape.
+func (s *Session) SetModerator(moderator *moderation.Moderator) {
+	s.moderator = moderator
+}
+
+// Usage returns the estimated token usage of the last Send or Retry.
+func (s *Session) Usage() tokenizer.Usage {
+	return s.usage
+}
+
+func (s *Session) record(ctx context.Context, prompt, answer string, interrupted bool) error {
+	if err := s.store.EnsureSession(s.ID); err != nil {
+		return err
+	}
+	turn := memtypes.HistoryItem{Role: "user", Content: prompt, SessionID: s.ID}
+	if err := service.SaveTurn(ctx, s.store, s.moderator, &turn); err != nil {
+		return err
+	}
+	return s.recordAnswer(ctx, turn.ID, answer, interrupted)
+}
+
+func (s *Session) recordAnswer(ctx context.Context, parentID, answer string, interrupted bool) error {
+	turn := memtypes.HistoryItem{Role: "assistant", Content: answer, SessionID: s.ID, ParentID: parentID, Interrupted: interrupted, Model: service.TurnModel(s.model)}
+	return service.SaveTurn(ctx, s.store, s.moderator, &turn)
+}
+
+// Retry regenerates the answer to the conversation's last prompt, streaming it
+// into out. The conversation before that prompt is used as context, and the new
+// answer is stored as a sibling of the previous one. In a fresh fork this
+// retries the prompt at the fork point, with the new answer on the branch.
+func (s *Session) Retry(ctx context.Context, out io.Writer) (string, error) {
+	history, err := s.store.GetConversation(s.ID, s.builder.limit()+4)
+	if err != nil {
+		return "", err
+	}
+
+	var prompt *memtypes.HistoryItem
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == "user" {
+			prompt = &history[i]
+			history = history[:i]
+			break
+		}
+	}
+	if prompt == nil {
+		return "", ErrNothingToRetry
+	}
+	text, err := s.preQuery(ctx, "retry", prompt.Content)
+	if err != nil {
+		return "", err
+	}
+
+	systemContext, err := s.systemContext(ctx, history, text)
+	if err != nil {
+		return "", err
+	}
+	answer, err := s.stream(ctx, systemContext, text, out)
+	if err != nil {
+		return answer, service.RecordInterrupted(ctx, answer, err, func(ctx context.Context) error {
+			if err := s.store.EnsureSession(s.ID); err != nil {
+				return err
+			}
+			return s.recordAnswer(ctx, prompt.ID, answer, true)
+		})
+	}
+
+	if err := s.store.EnsureSession(s.ID); err != nil {
+		return answer, err
+	}
+	if err := s.recordAnswer(ctx, prompt.ID, answer, false); err != nil {
+		return answer, err
+	}
+	s.postResponse(ctx, "retry", text, answer)
+	return answer, nil
+}
+
+// Fork branches the conversation after the turn ref refers to and switches the
+// session to the new branch, returning it. ref is a 1-based turn number as
+// listed by Conversation, a turn ID, or empty for the latest turn. The original
+// session is left untouched.
+func (s *Session) Fork(ref string) (memtypes.Session, error) {
+	conversation, err := s.Conversation()
+	if err != nil {
+		return memtypes.Session{}, err
+	}
+
+	turn, err := ResolveTurn(conversation, ref)
+	if err != nil {
+		return memtypes.Session{}, err
+	}
+
+	branch, err := s.store.ForkSession(turn.ID)
+	if err != nil {
+		return memtypes.Session{}, err
+	}
+	s.ID = branch.ID
+	return branch, nil
+}
+
+// Conversation returns every turn of the session, including turns inherited
+// from the sessions it was forked from, oldest first.
+func (s *Session) Conversation() ([]memtypes.HistoryItem, error) {
+	return s.store.GetConversation(s.ID, maxConversationTurns)
+}
+
+// ResolveTurn finds the turn ref refers to: a 1-based position in
+// conversation, a turn ID, or the last turn when ref is empty.
+func ResolveTurn(conversation []memtypes.HistoryItem, ref string) (memtypes.HistoryItem, error) {
+	if len(conversation) == 0 {
+		return memtypes.HistoryItem{}, fmt.Errorf("the session has no turns")
+	}
+
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return conversation[len(conversation)-1], nil
+	}
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 || n > len(conversation) {
+			return memtypes.HistoryItem{}, fmt.Errorf("turn %d out of range (1-%d)", n, len(conversation))
+		}
+		return conversation[n-1], nil
+	}
+	for _, turn := range conversation {
+		if turn.ID == ref {
+			return turn, nil
+		}
+	}
+	return memtypes.HistoryItem{}, fmt.Errorf("turn %s is not part of the session", ref)
+}
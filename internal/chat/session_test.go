@@ -0,0 +1,535 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/hooks"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/mockprovider"
+	"github.com/austiecodes/gomor/internal/moderation"
+	"github.com/austiecodes/gomor/internal/provider"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+	_ "modernc.org/sqlite"
+)
+
+type fakeStream struct {
+	chunks []string
+	idx    int
+}
+
+func (s *fakeStream) Next() bool {
+	if s.idx < len(s.chunks) {
+		s.idx++
+		return true
+	}
+	return false
+}
+
+func (s *fakeStream) GetChunk() string { return s.chunks[s.idx-1] }
+func (s *fakeStream) Err() error       { return nil }
+func (s *fakeStream) Close() error     { return nil }
+
+// fakeQueryClient answers every prompt with fixed chunks and records the
+// system context it was given.
+type fakeQueryClient struct {
+	chunks   []string
+	contexts []string
+}
+
+func (c *fakeQueryClient) ChatStream(ctx context.Context, model types.Model, query string) (client.StreamResponse, error) {
+	return c.ChatStreamWithContext(ctx, model, "", query)
+}
+
+func (c *fakeQueryClient) ChatStreamWithContext(ctx context.Context, model types.Model, systemContext, query string) (client.StreamResponse, error) {
+	c.contexts = append(c.contexts, systemContext)
+	return &fakeStream{chunks: c.chunks}, nil
+}
+
+func (c *fakeQueryClient) ListModels(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func newTestStore(t *testing.T) *store.Store {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	memStore, err := store.NewStoreWithDB(db)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = memStore.Close()
+	})
+	return memStore
+}
+
+func TestSendStreamsAndRecordsTurns(t *testing.T) {
+	memStore := newTestStore(t)
+	qc := &fakeQueryClient{chunks: []string{"Hello", ", world"}}
+	session := NewSession(memStore, qc, types.Model{Provider: "fake", ModelID: "fake-chat"}, "")
+
+	var out bytes.Buffer
+	answer, err := session.Send(context.Background(), "say hello", &out)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if answer != "Hello, world" || out.String() != "Hello, world" {
+		t.Fatalf("unexpected answer %q / output %q", answer, out.String())
+	}
+	if qc.contexts[0] != "" {
+		t.Fatalf("expected no context on the first turn, got %q", qc.contexts[0])
+	}
+
+	if _, err := session.Send(context.Background(), "again", &bytes.Buffer{}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if !strings.Contains(qc.contexts[1], "user: say hello") || !strings.Contains(qc.contexts[1], "assistant: Hello, world") {
+		t.Fatalf("expected earlier turns as context, got %q", qc.contexts[1])
+	}
+
+	history, err := memStore.GetSessionHistory(session.ID, 10)
+	if err != nil {
+		t.Fatalf("session history: %v", err)
+	}
+	if len(history) != 4 || history[0].Role != "user" || history[1].Role != "assistant" {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+	if history[0].Model != "" || history[1].Model != "fake/fake-chat" {
+		t.Fatalf("expected the model recorded on answers only, got %q and %q", history[0].Model, history[1].Model)
+	}
+
+	prompts, err := memStore.RecentPrompts(10)
+	if err != nil {
+		t.Fatalf("recent prompts: %v", err)
+	}
+	if len(prompts) != 2 || prompts[0] != "say hello" || prompts[1] != "again" {
+		t.Fatalf("expected prompts oldest first, got %q", prompts)
+	}
+}
+
+// interruptedClient streams chunks, then cancels the request as Ctrl-C would.
+type interruptedClient struct {
+	fakeQueryClient
+	cancel context.CancelFunc
+}
+
+func (c *interruptedClient) ChatStreamWithContext(ctx context.Context, model types.Model, systemContext, query string) (client.StreamResponse, error) {
+	return &interruptedStream{fakeStream: fakeStream{chunks: c.chunks}, ctx: ctx, cancel: c.cancel}, nil
+}
+
+type interruptedStream struct {
+	fakeStream
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (s *interruptedStream) Next() bool {
+	if s.fakeStream.Next() {
+		return true
+	}
+	s.cancel()
+	return false
+}
+
+func (s *interruptedStream) Err() error { return s.ctx.Err() }
+
+func TestSendSavesInterruptedAnswer(t *testing.T) {
+	memStore := newTestStore(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	qc := &interruptedClient{fakeQueryClient: fakeQueryClient{chunks: []string{"Once upon"}}, cancel: cancel}
+	session := NewSession(memStore, qc, types.Model{Provider: "fake", ModelID: "fake-chat"}, "")
+
+	answer, err := session.Send(ctx, "tell a story", &bytes.Buffer{})
+	if !errors.Is(err, context.Canceled) || answer != "Once upon" {
+		t.Fatalf("expected the partial answer and the cancellation, got %q (%v)", answer, err)
+	}
+
+	history, err := memStore.GetSessionHistory(session.ID, 10)
+	if err != nil {
+		t.Fatalf("session history: %v", err)
+	}
+	if len(history) != 2 || history[1].Content != "Once upon" || !history[1].Interrupted || history[0].Interrupted {
+		t.Fatalf("expected the partial answer saved as interrupted, got %+v", history)
+	}
+}
+
+func TestSendRendersSystemPromptWithMemories(t *testing.T) {
+	memStore := newTestStore(t)
+	qc := &fakeQueryClient{chunks: []string{"ok"}}
+	session := NewSession(memStore, qc, types.Model{Provider: "fake", ModelID: "fake-chat"}, "")
+	if err := session.SetContextConfig(utils.ChatConfig{SystemPrompt: "Known about the user:\n{{.Memories}}{{.Memories}}\n{{.History}}"}); err != nil {
+		t.Fatalf("set context config: %v", err)
+	}
+	var recalled []string
+	var notice bytes.Buffer
+	session.SetRecall(func(ctx context.Context, prompt string) (string, error) {
+		recalled = append(recalled, prompt)
+		if prompt == "fail" {
+			return "", errors.New("embedding provider down")
+		}
+		return "- prefers Go", nil
+	}, &notice)
+
+	if _, err := session.Send(context.Background(), "which language?", &bytes.Buffer{}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if qc.contexts[0] != "Known about the user:\n- prefers Go- prefers Go\n" {
+		t.Fatalf("unexpected system context %q", qc.contexts[0])
+	}
+	if len(recalled) != 1 || recalled[0] != "which language?" {
+		t.Fatalf("expected memories recalled once for the prompt, got %q", recalled)
+	}
+
+	if _, err := session.Send(context.Background(), "fail", &bytes.Buffer{}); err != nil {
+		t.Fatalf("a failed recall should not fail the turn: %v", err)
+	}
+	if !strings.HasPrefix(qc.contexts[1], "Known about the user:\n\nConversation so far:\nuser: which language?") {
+		t.Fatalf("expected the history without memories, got %q", qc.contexts[1])
+	}
+	if !strings.Contains(notice.String(), "Warning: memory retrieval failed: embedding provider down") {
+		t.Fatalf("expected a warning, got %q", notice.String())
+	}
+}
+
+func TestRetryStoresSiblingAnswer(t *testing.T) {
+	memStore := newTestStore(t)
+	qc := &fakeQueryClient{chunks: []string{"first"}}
+	session := NewSession(memStore, qc, types.Model{Provider: "fake", ModelID: "fake-chat"}, "s-1")
+
+	if _, err := session.Retry(context.Background(), &bytes.Buffer{}); !errors.Is(err, ErrNothingToRetry) {
+		t.Fatalf("expected ErrNothingToRetry, got %v", err)
+	}
+
+	if _, err := session.Send(context.Background(), "intro", &bytes.Buffer{}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if _, err := session.Send(context.Background(), "pick a color", &bytes.Buffer{}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	qc.chunks = []string{"blue"}
+	var out bytes.Buffer
+	if _, err := session.Retry(context.Background(), &out); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if out.String() != "blue" {
+		t.Fatalf("unexpected retry output %q", out.String())
+	}
+	retryContext := qc.contexts[len(qc.contexts)-1]
+	if !strings.Contains(retryContext, "user: intro") || strings.Contains(retryContext, "pick a color") {
+		t.Fatalf("retry context should hold only turns before the prompt, got %q", retryContext)
+	}
+
+	history, err := memStore.GetSessionHistory("s-1", 10)
+	if err != nil {
+		t.Fatalf("session history: %v", err)
+	}
+	if len(history) != 5 {
+		t.Fatalf("expected 5 turns, got %+v", history)
+	}
+	prompt, answer, retried := history[2], history[3], history[4]
+	if answer.ParentID != prompt.ID || retried.ParentID != prompt.ID {
+		t.Fatalf("expected both answers to share the prompt as parent: %+v", history)
+	}
+
+	qc.chunks = []string{"ok"}
+	if _, err := session.Send(context.Background(), "why?", &bytes.Buffer{}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	next := qc.contexts[len(qc.contexts)-1]
+	// "first" answered both prompts; the superseded answer to the second must be gone.
+	if !strings.Contains(next, "assistant: blue") || strings.Count(next, "assistant: first") != 1 {
+		t.Fatalf("expected only the newest sibling in context, got %q", next)
+	}
+}
+
+func TestForkBranchesAfterTurn(t *testing.T) {
+	memStore := newTestStore(t)
+	qc := &fakeQueryClient{chunks: []string{"ok"}}
+	model := types.Model{Provider: "fake", ModelID: "fake-chat"}
+	session := NewSession(memStore, qc, model, "main")
+
+	for _, prompt := range []string{"one", "two", "three"} {
+		if _, err := session.Send(context.Background(), prompt, &bytes.Buffer{}); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+
+	branch, err := session.Fork("4")
+	if err != nil {
+		t.Fatalf("fork: %v", err)
+	}
+	if branch.ParentID != "main" || session.ID != branch.ID {
+		t.Fatalf("expected session to switch to a branch of main, got %+v (session %s)", branch, session.ID)
+	}
+
+	if _, err := session.Send(context.Background(), "other", &bytes.Buffer{}); err != nil {
+		t.Fatalf("send on branch: %v", err)
+	}
+	branchContext := qc.contexts[len(qc.contexts)-1]
+	if !strings.Contains(branchContext, "user: two") || strings.Contains(branchContext, "three") {
+		t.Fatalf("expected branch context to stop at the fork point, got %q", branchContext)
+	}
+
+	conversation, err := session.Conversation()
+	if err != nil {
+		t.Fatalf("conversation: %v", err)
+	}
+	if len(conversation) != 6 || conversation[4].Content != "other" || conversation[4].SessionID != branch.ID {
+		t.Fatalf("unexpected branch conversation: %+v", conversation)
+	}
+
+	original, err := NewSession(memStore, qc, model, "main").Conversation()
+	if err != nil {
+		t.Fatalf("original conversation: %v", err)
+	}
+	if len(original) != 6 || original[4].Content != "three" {
+		t.Fatalf("expected original session to be untouched, got %+v", original)
+	}
+}
+
+func TestResolveTurn(t *testing.T) {
+	conversation := []memtypes.HistoryItem{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+
+	cases := map[string]string{"": "c", "1": "a", " 2 ": "b", "c": "c"}
+	for ref, want := range cases {
+		turn, err := ResolveTurn(conversation, ref)
+		if err != nil {
+			t.Fatalf("resolve %q: %v", ref, err)
+		}
+		if turn.ID != want {
+			t.Fatalf("resolve %q: expected %s, got %s", ref, want, turn.ID)
+		}
+	}
+
+	for _, ref := range []string{"0", "4", "missing"} {
+		if _, err := ResolveTurn(conversation, ref); err == nil {
+			t.Fatalf("expected error resolving %q", ref)
+		}
+	}
+	if _, err := ResolveTurn(nil, ""); err == nil {
+		t.Fatal("expected error for an empty conversation")
+	}
+}
+
+func TestSendEstimatesUsage(t *testing.T) {
+	memStore := newTestStore(t)
+	qc := &fakeQueryClient{chunks: []string{"hello world"}}
+	session := NewSession(memStore, qc, types.Model{Provider: "openai", ModelID: "gpt-4o-mini"}, "")
+
+	if _, err := session.Send(context.Background(), "say hello world", &bytes.Buffer{}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	usage := session.Usage()
+	if usage.InputTokens <= 3 || usage.OutputTokens != 2 {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+}
+
+func TestSendRecordsModeratedTurnsAsPlaceholders(t *testing.T) {
+	memStore := newTestStore(t)
+	moderator, err := moderation.New(utils.ModerationConfig{
+		Rules: map[string][]string{"secrets": {`sk-[A-Za-z0-9]+`}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("moderation: %v", err)
+	}
+	session := NewSession(memStore, &fakeQueryClient{chunks: []string{"Noted."}}, types.Model{Provider: "fake", ModelID: "fake-chat"}, "")
+	session.SetModerator(moderator)
+
+	var out bytes.Buffer
+	if _, err := session.Send(context.Background(), "my key is sk-abc123", &out); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if out.String() != "Noted." {
+		t.Fatalf("expected the answer to be shown, got %q", out.String())
+	}
+
+	history, err := memStore.GetSessionHistory(session.ID, 10)
+	if err != nil {
+		t.Fatalf("session history: %v", err)
+	}
+	if len(history) != 2 || history[0].Content != "[withheld by moderation: secrets]" || history[1].Content != "Noted." {
+		t.Fatalf("expected only the prompt to be withheld, got %+v", history)
+	}
+}
+
+func TestSendRunsHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands in this test need a POSIX shell")
+	}
+	memStore := newTestStore(t)
+	session := NewSession(memStore, &fakeQueryClient{chunks: []string{"Sure."}}, types.Model{Provider: "fake", ModelID: "fake-chat"}, "")
+	var notice bytes.Buffer
+	session.SetHooks(hooks.New(utils.HooksConfig{
+		PreQuery:     `echo '{"prompt": "rewritten"}'`,
+		PostResponse: "echo 'notifier down' >&2; exit 1",
+	}), &notice)
+
+	if _, err := session.Send(context.Background(), "original", &bytes.Buffer{}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	history, err := memStore.GetSessionHistory(session.ID, 10)
+	if err != nil {
+		t.Fatalf("session history: %v", err)
+	}
+	if len(history) != 2 || history[0].Content != "rewritten" {
+		t.Fatalf("expected the rewritten prompt to be recorded, got %+v", history)
+	}
+	if !strings.Contains(notice.String(), "Warning: post_response hook failed") || !strings.Contains(notice.String(), "notifier down") {
+		t.Fatalf("expected a post_response warning, got %q", notice.String())
+	}
+}
+
+// fakeToolClient replies with each of replies in turn and records the
+// messages it was sent.
+type fakeToolClient struct {
+	fakeQueryClient
+	replies  []client.ToolReply
+	messages [][]client.ToolMessage
+	contexts []string
+}
+
+func (c *fakeToolClient) ChatWithTools(_ context.Context, _ types.Model, systemContext string, messages []client.ToolMessage, _ []client.Tool) (*client.ToolReply, error) {
+	c.contexts = append(c.contexts, systemContext)
+	c.messages = append(c.messages, append([]client.ToolMessage(nil), messages...))
+	reply := c.replies[0]
+	c.replies = c.replies[1:]
+	return &reply, nil
+}
+
+// fakeToolRunner offers one tool that fails for the path "missing".
+type fakeToolRunner struct {
+	calls []string
+}
+
+func (r *fakeToolRunner) Tools() []client.Tool {
+	return []client.Tool{{Name: "fs__read"}}
+}
+
+func (r *fakeToolRunner) Instructions() string {
+	return "Prefer the fs server."
+}
+
+func (r *fakeToolRunner) Call(_ context.Context, name, arguments string) (string, error) {
+	r.calls = append(r.calls, name+" "+arguments)
+	if strings.Contains(arguments, "missing") {
+		return "", errors.New("no such file")
+	}
+	return "alpha", nil
+}
+
+func TestSendRunsToolCallsUntilAnswered(t *testing.T) {
+	memStore := newTestStore(t)
+	qc := &fakeToolClient{replies: []client.ToolReply{
+		{ToolCalls: []client.ToolCall{
+			{ID: "1", Name: "fs__read", Arguments: `{"path":"a.txt"}`},
+			{ID: "2", Name: "fs__read", Arguments: `{"path":"missing"}`},
+		}},
+		{Content: "a.txt says alpha"},
+	}}
+	runner := &fakeToolRunner{}
+	session := NewSession(memStore, qc, types.Model{Provider: "fake", ModelID: "fake-chat"}, "")
+	var notice bytes.Buffer
+	session.SetTools(runner, &notice)
+
+	var out bytes.Buffer
+	answer, err := session.Send(context.Background(), "what is in a.txt?", &out)
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if answer != "a.txt says alpha" || out.String() != answer {
+		t.Fatalf("expected the final answer to be written, got %q and %q", answer, out.String())
+	}
+	if len(runner.calls) != 2 || !strings.Contains(notice.String(), `Tool: fs__read {"path":"a.txt"}`) {
+		t.Fatalf("expected both calls to run and be shown, got %v and %q", runner.calls, notice.String())
+	}
+	if !strings.Contains(qc.contexts[0], "Prefer the fs server.") {
+		t.Fatalf("expected tool instructions in the system context, got %q", qc.contexts[0])
+	}
+
+	second := qc.messages[1]
+	if len(second) != 4 || second[1].Role != client.ToolRoleAssistant || len(second[1].ToolCalls) != 2 {
+		t.Fatalf("expected the prompt, the calls, and two results, got %+v", second)
+	}
+	if second[2].Content != "alpha" || second[3].Content != "Error: no such file" || second[3].ToolCallID != "2" {
+		t.Fatalf("expected results matched to their calls, got %+v", second[2:])
+	}
+
+	turns, err := session.Conversation()
+	if err != nil {
+		t.Fatalf("Conversation: %v", err)
+	}
+	if len(turns) != 2 || turns[1].Content != "a.txt says alpha" {
+		t.Fatalf("expected only the prompt and answer to be recorded, got %+v", turns)
+	}
+	if len(qc.fakeQueryClient.contexts) != 0 {
+		t.Fatal("expected no streamed request when tools are offered")
+	}
+}
+
+func TestSendThroughOpenAIProvider(t *testing.T) {
+	server := mockprovider.New(t)
+	config := server.Config()
+	qc, err := provider.NewQueryClient(config, config.Model.ChatModel.Provider)
+	if err != nil {
+		t.Fatalf("NewQueryClient: %v", err)
+	}
+	memStore := newTestStore(t)
+	session := NewSession(memStore, qc, *config.Model.ChatModel, "")
+
+	server.QueueText("Hello from the mock")
+	var out bytes.Buffer
+	if _, err := session.Send(context.Background(), "say hello", &out); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if out.String() != "Hello from the mock" {
+		t.Fatalf("expected the streamed answer, got %q", out.String())
+	}
+
+	session.SetTools(&fakeToolRunner{}, &bytes.Buffer{})
+	server.QueueReply(
+		mockprovider.Reply{ToolCalls: []mockprovider.ToolCall{{Name: "fs__read", Arguments: `{"path":"a.txt"}`}}},
+		mockprovider.Reply{Content: "a.txt says alpha"},
+	)
+	answer, err := session.Send(context.Background(), "what is in a.txt?", &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Send with tools: %v", err)
+	}
+	if answer != "a.txt says alpha" {
+		t.Fatalf("expected the answer after the tool call, got %q", answer)
+	}
+
+	requests := server.ChatRequests()
+	if len(requests) != 3 {
+		t.Fatalf("expected one streamed and two tool requests, got %d", len(requests))
+	}
+	if !strings.Contains(requests[1].Messages[0].Content, "user: say hello") {
+		t.Fatalf("expected earlier turns in the system context, got %+v", requests[1].Messages)
+	}
+	last := requests[2].Messages
+	if last[len(last)-1].Role != "tool" || last[len(last)-1].Content != "alpha" {
+		t.Fatalf("expected the tool result sent back, got %+v", last)
+	}
+
+	history, err := memStore.GetSessionHistory(session.ID, 10)
+	if err != nil {
+		t.Fatalf("session history: %v", err)
+	}
+	if len(history) != 4 || history[1].Model != "openai/"+mockprovider.ChatModel {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+}
@@ -0,0 +1,105 @@
+package chat
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/termcap"
+)
+
+// spinnerInterval is how often the spinner redraws.
+const spinnerInterval = 100 * time.Millisecond
+
+// Spinner shows a spinner and the time waited on a terminal while an answer
+// is on its way. On anything but a terminal, and for a nil Spinner, it does
+// nothing, so pipes and files never see it.
+type Spinner struct {
+	w      io.Writer
+	frames []string
+
+	mu   sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewSpinner returns a spinner drawing on w, usually stderr.
+func NewSpinner(w io.Writer) *Spinner {
+	f, ok := w.(*os.File)
+	if !ok {
+		return nil
+	}
+	if info, err := f.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+	return &Spinner{w: w, frames: strings.Split(termcap.Glyph("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏", `|/-\`), "")}
+}
+
+// Start shows the spinner until Stop is called or a wrapped writer is
+// written to.
+func (s *Spinner) Start() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return
+	}
+	s.stop, s.done = make(chan struct{}), make(chan struct{})
+	go s.run(time.Now(), s.stop, s.done)
+}
+
+func (s *Spinner) run(start time.Time, stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+	for frame := 0; ; frame++ {
+		fmt.Fprintf(s.w, "\r%s waiting for the answer %.1fs", s.frames[frame%len(s.frames)], time.Since(start).Seconds())
+		select {
+		case <-stop:
+			// Blank the line rather than erase it with an escape sequence,
+			// which the legacy Windows console would print.
+			fmt.Fprintf(s.w, "\r%s\r", strings.Repeat(" ", 40))
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop clears the spinner if it is showing.
+func (s *Spinner) Stop() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	s.stop, s.done = nil, nil
+}
+
+// Wrap returns a writer that clears the spinner before each write to w, so
+// answers and notices never share a line with it.
+func (s *Spinner) Wrap(w io.Writer) io.Writer {
+	if s == nil {
+		return w
+	}
+	return spinnerWriter{w: w, spinner: s}
+}
+
+type spinnerWriter struct {
+	w       io.Writer
+	spinner *Spinner
+}
+
+func (w spinnerWriter) Write(p []byte) (int, error) {
+	w.spinner.Stop()
+	return w.w.Write(p)
+}
@@ -0,0 +1,52 @@
+package chat
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSpinnerOnlyDrawsOnATerminal(t *testing.T) {
+	var buf bytes.Buffer
+	spinner := NewSpinner(&buf)
+	if spinner != nil {
+		t.Fatal("expected no spinner on a buffer")
+	}
+	spinner.Start()
+	if _, err := spinner.Wrap(&buf).Write([]byte("answer")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	spinner.Stop()
+	if buf.String() != "answer" {
+		t.Fatalf("expected only the answer, got %q", buf.String())
+	}
+}
+
+func TestSpinnerClearsBeforeTheAnswer(t *testing.T) {
+	var buf bytes.Buffer
+	spinner := &Spinner{w: &buf, frames: []string{"|"}}
+	spinner.Start()
+	if _, err := spinner.Wrap(&buf).Write([]byte("answer")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	spinner.Stop()
+
+	out := buf.String()
+	if !strings.HasPrefix(out, "\r| waiting for the answer") || !strings.HasSuffix(out, "\ranswer") {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
+
+func TestCopyStreamReportsTheFirstChunk(t *testing.T) {
+	var buf bytes.Buffer
+	answer, first, err := copyStream(&buf, &fakeStream{chunks: []string{"", "hel", "lo"}})
+	if err != nil || answer != "hello" || buf.String() != "hello" {
+		t.Fatalf("unexpected copy: %q %q (%v)", answer, buf.String(), err)
+	}
+	if first.IsZero() {
+		t.Fatal("expected the time of the first chunk")
+	}
+	if _, first, _ = copyStream(&buf, &fakeStream{}); !first.IsZero() {
+		t.Fatal("expected no first chunk for an empty stream")
+	}
+}
@@ -0,0 +1,119 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/trace"
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+// titleInstructions asks the title model for a bare title.
+const titleInstructions = `Name the conversation below in at most six words, like a
+chat app's sidebar would. Reply with the title only: no quotes, no
+punctuation at the end, no preamble.`
+
+const (
+	// maxTitleLength caps a stored title, in characters.
+	maxTitleLength = 60
+	// titleTimeout bounds how long titling may hold up the end of a session.
+	titleTimeout = 30 * time.Second
+	// titleExcerpt caps how much of the first exchange the title model sees.
+	titleExcerpt = 2000
+)
+
+// Titler names a session from its first prompt and answer.
+type Titler func(ctx context.Context, prompt, answer string) (string, error)
+
+// NewTitler returns a Titler asking model through queryClient, usually the
+// configured title_model.
+func NewTitler(queryClient client.QueryClient, model types.Model) Titler {
+	return func(ctx context.Context, prompt, answer string) (string, error) {
+		query := fmt.Sprintf("user: %s\n\nassistant: %s", excerpt(prompt), excerpt(answer))
+		stream, err := queryClient.ChatStreamWithContext(ctx, model, titleInstructions, query)
+		if err != nil {
+			return "", err
+		}
+		defer stream.Close()
+
+		var reply strings.Builder
+		for stream.Next() {
+			reply.WriteString(stream.GetChunk())
+		}
+		if err := stream.Err(); err != nil {
+			return "", err
+		}
+		return cleanTitle(reply.String()), nil
+	}
+}
+
+// SetTitler names new sessions with titler after their first exchange. Titles
+// are generated in the background; call WaitForTitle before exiting.
+func (s *Session) SetTitler(titler Titler) {
+	s.titler = titler
+}
+
+// WaitForTitle waits for a title still being generated to be stored.
+func (s *Session) WaitForTitle() {
+	s.titling.Wait()
+}
+
+// startTitle names the session from its first exchange in the background.
+// Failures only show with -v: the session works the same without a title.
+func (s *Session) startTitle(ctx context.Context, prompt, answer string) {
+	if s.titler == nil {
+		return
+	}
+	s.titling.Add(1)
+	go func() {
+		defer s.titling.Done()
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), titleTimeout)
+		defer cancel()
+
+		title, err := s.titler(ctx, prompt, answer)
+		if err == nil && title != "" {
+			err = s.store.SetSessionTitle(s.ID, title)
+		}
+		if err != nil {
+			trace.Printf(trace.Info, "title: failed to name session %s: %v", s.ID, err)
+			return
+		}
+		trace.Printf(trace.Info, "title: %q", title)
+	}()
+}
+
+// cleanTitle turns a model's reply into a title: its first line, without
+// quotes, a "Title:" label, or a closing period, and at most maxTitleLength
+// characters.
+func cleanTitle(reply string) string {
+	var title string
+	for _, line := range strings.Split(reply, "\n") {
+		if title = strings.TrimSpace(line); title != "" {
+			break
+		}
+	}
+	if len(title) > len("title:") && strings.EqualFold(title[:len("title:")], "title:") {
+		title = strings.TrimSpace(title[len("title:"):])
+	}
+	// Markup and a closing period nest either way round: "Title." or "Title".
+	for trimmed := ""; trimmed != title; {
+		trimmed = title
+		title = strings.TrimSpace(strings.TrimRight(strings.Trim(title, "\"'`*#“”"), "."))
+	}
+	if utf8.RuneCountInString(title) > maxTitleLength {
+		title = strings.TrimSpace(string([]rune(title)[:maxTitleLength-1])) + "…"
+	}
+	return title
+}
+
+// excerpt shortens text to titleExcerpt characters.
+func excerpt(text string) string {
+	if utf8.RuneCountInString(text) <= titleExcerpt {
+		return text
+	}
+	return string([]rune(text)[:titleExcerpt]) + "…"
+}
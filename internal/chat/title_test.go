@@ -0,0 +1,51 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+func TestSendTitlesNewSessionsOnce(t *testing.T) {
+	memStore := newTestStore(t)
+	session := NewSession(memStore, &fakeQueryClient{chunks: []string{"Use a buffered channel."}}, types.Model{Provider: "fake", ModelID: "fake-chat"}, "")
+
+	titleClient := &fakeQueryClient{chunks: []string{"Title: \"Go channel", " deadlocks\".\nextra"}}
+	session.SetTitler(NewTitler(titleClient, types.Model{Provider: "fake", ModelID: "fake-title"}))
+
+	for _, prompt := range []string{"why does my channel deadlock?", "and with select?"} {
+		if _, err := session.Send(context.Background(), prompt, &bytes.Buffer{}); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+		session.WaitForTitle()
+	}
+
+	if len(titleClient.contexts) != 1 {
+		t.Fatalf("expected one title request, got %d", len(titleClient.contexts))
+	}
+	stored, err := memStore.GetSession(session.ID)
+	if err != nil || stored == nil {
+		t.Fatalf("get session: %+v (%v)", stored, err)
+	}
+	if stored.Title != "Go channel deadlocks" {
+		t.Fatalf("unexpected title %q", stored.Title)
+	}
+}
+
+func TestCleanTitle(t *testing.T) {
+	tests := map[string]string{
+		"Deploy checklist":            "Deploy checklist",
+		"\n  **Deploy checklist.**\n": "Deploy checklist",
+		"title: 'Cache invalidation'": "Cache invalidation",
+		"“Rust lifetimes”":            "Rust lifetimes",
+		strings.Repeat("word ", 20):   strings.TrimSpace(strings.Repeat("word ", 12)) + "…",
+	}
+	for reply, want := range tests {
+		if got := cleanTitle(reply); got != want {
+			t.Errorf("cleanTitle(%q) = %q, want %q", reply, got, want)
+		}
+	}
+}
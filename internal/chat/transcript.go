@@ -0,0 +1,161 @@
+package chat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+// Transcript formats.
+const (
+	TranscriptMarkdown = "md"
+	TranscriptHTML     = "html"
+	TranscriptJSON     = "json"
+)
+
+const transcriptTimeLayout = "2006-01-02 15:04:05"
+
+// TranscriptTurn is one turn of an exported transcript.
+type TranscriptTurn struct {
+	Turn      int       `json:"turn"`
+	ID        string    `json:"id"`
+	SessionID string    `json:"session_id"`
+	Role      string    `json:"role"`
+	Model     string    `json:"model,omitempty"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+	// Interrupted marks an answer cut short by Ctrl-C.
+	Interrupted bool `json:"interrupted,omitempty"`
+}
+
+// transcript is the JSON form of an exported session.
+type transcript struct {
+	SessionID  string           `json:"session_id"`
+	ExportedAt time.Time        `json:"exported_at"`
+	Models     []string         `json:"models,omitempty"`
+	Turns      []TranscriptTurn `json:"turns"`
+}
+
+// Transcript renders the turns of a session as a readable Markdown or HTML
+// document, or as JSON, with each turn's time and the model that wrote each
+// answer.
+func Transcript(sessionID string, conversation []memtypes.HistoryItem, format string, exportedAt time.Time) ([]byte, error) {
+	t := transcript{SessionID: sessionID, ExportedAt: exportedAt, Turns: make([]TranscriptTurn, len(conversation))}
+	seen := make(map[string]bool)
+	for i, item := range conversation {
+		t.Turns[i] = TranscriptTurn{
+			Turn:        i + 1,
+			ID:          item.ID,
+			SessionID:   item.SessionID,
+			Role:        item.Role,
+			Model:       item.Model,
+			Content:     item.Content,
+			CreatedAt:   item.CreatedAt,
+			Interrupted: item.Interrupted,
+		}
+		if item.Model != "" && !seen[item.Model] {
+			seen[item.Model] = true
+			t.Models = append(t.Models, item.Model)
+		}
+	}
+
+	switch format {
+	case TranscriptMarkdown:
+		return t.markdown(), nil
+	case TranscriptHTML:
+		return t.html()
+	case TranscriptJSON:
+		data, err := json.MarshalIndent(t, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	default:
+		return nil, fmt.Errorf("unsupported transcript format %q (valid: md, html, json)", format)
+	}
+}
+
+// summary is the line under the title: when it was exported, how many turns
+// it has, and which models answered.
+func (t transcript) summary() string {
+	line := fmt.Sprintf("Exported %s · %d turns", t.ExportedAt.Format(transcriptTimeLayout), len(t.Turns))
+	if len(t.Models) > 0 {
+		line += " · " + strings.Join(t.Models, ", ")
+	}
+	return line
+}
+
+// heading names the speaker of a turn, with the model for answers and a note
+// for interrupted ones.
+func (turn TranscriptTurn) heading() string {
+	speaker := "User"
+	if turn.Role == "assistant" {
+		speaker = "Assistant"
+		if turn.Model != "" {
+			speaker += " (" + turn.Model + ")"
+		}
+	}
+	heading := speaker + " · " + turn.CreatedAt.Format(transcriptTimeLayout)
+	if turn.Interrupted {
+		heading += " · interrupted"
+	}
+	return heading
+}
+
+func (t transcript) markdown() []byte {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Chat session %s\n\n%s\n", t.SessionID, t.summary())
+	for _, turn := range t.Turns {
+		fmt.Fprintf(&sb, "\n## %s\n\n%s\n", turn.heading(), strings.TrimSpace(turn.Content))
+	}
+	return []byte(sb.String())
+}
+
+var transcriptHTML = template.Must(template.New("transcript").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Chat session {{.SessionID}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 48rem; margin: 2rem auto; padding: 0 1rem; color: #222; }
+.summary { color: #666; }
+.turn { border-top: 1px solid #ddd; padding: 0.5rem 0; }
+.turn h2 { font-size: 0.9rem; color: #555; margin: 0.5rem 0; }
+.assistant { background: #f7f7f9; }
+.content { white-space: pre-wrap; font-family: inherit; margin: 0; }
+</style>
+</head>
+<body>
+<h1>Chat session {{.SessionID}}</h1>
+<p class="summary">{{.Summary}}</p>
+{{range .Turns}}<section class="turn {{.Role}}">
+<h2>{{.Heading}}</h2>
+<pre class="content">{{.Content}}</pre>
+</section>
+{{end}}</body>
+</html>
+`))
+
+func (t transcript) html() ([]byte, error) {
+	type htmlTurn struct {
+		Role, Heading, Content string
+	}
+	data := struct {
+		SessionID, Summary string
+		Turns              []htmlTurn
+	}{SessionID: t.SessionID, Summary: t.summary()}
+	for _, turn := range t.Turns {
+		data.Turns = append(data.Turns, htmlTurn{Role: turn.Role, Heading: turn.heading(), Content: strings.TrimSpace(turn.Content)})
+	}
+
+	var buf bytes.Buffer
+	if err := transcriptHTML.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,68 @@
+package chat
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+func transcriptTurns() []memtypes.HistoryItem {
+	at := time.Date(2026, 3, 1, 9, 30, 0, 0, time.Local)
+	return []memtypes.HistoryItem{
+		{ID: "a", SessionID: "s1", Role: "user", Content: "Is <b> bold?", CreatedAt: at},
+		{ID: "b", SessionID: "s1", Role: "assistant", Model: "openai/gpt-5-nano", Content: "Yes.\n", CreatedAt: at.Add(5 * time.Second)},
+	}
+}
+
+func TestTranscriptMarkdown(t *testing.T) {
+	exportedAt := time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local)
+	data, err := Transcript("s1", transcriptTurns(), TranscriptMarkdown, exportedAt)
+	if err != nil {
+		t.Fatalf("Transcript: %v", err)
+	}
+	want := "# Chat session s1\n\nExported 2026-03-02 10:00:00 · 2 turns · openai/gpt-5-nano\n" +
+		"\n## User · 2026-03-01 09:30:00\n\nIs <b> bold?\n" +
+		"\n## Assistant (openai/gpt-5-nano) · 2026-03-01 09:30:05\n\nYes.\n"
+	if string(data) != want {
+		t.Fatalf("unexpected markdown:\n%s", data)
+	}
+}
+
+func TestTranscriptHTMLEscapesContent(t *testing.T) {
+	data, err := Transcript("s1", transcriptTurns(), TranscriptHTML, time.Now())
+	if err != nil {
+		t.Fatalf("Transcript: %v", err)
+	}
+	page := string(data)
+	if !strings.Contains(page, "Is &lt;b&gt; bold?") || strings.Contains(page, "Is <b>") {
+		t.Fatalf("expected escaped content, got:\n%s", page)
+	}
+	if !strings.Contains(page, `<section class="turn assistant">`) || !strings.Contains(page, "Assistant (openai/gpt-5-nano)") {
+		t.Fatalf("expected the answer's model in the page, got:\n%s", page)
+	}
+}
+
+func TestTranscriptJSON(t *testing.T) {
+	data, err := Transcript("s1", transcriptTurns(), TranscriptJSON, time.Now())
+	if err != nil {
+		t.Fatalf("Transcript: %v", err)
+	}
+	var payload struct {
+		SessionID string           `json:"session_id"`
+		Models    []string         `json:"models"`
+		Turns     []TranscriptTurn `json:"turns"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if payload.SessionID != "s1" || len(payload.Models) != 1 || len(payload.Turns) != 2 || payload.Turns[1].Turn != 2 || payload.Turns[1].Model != "openai/gpt-5-nano" {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+
+	if _, err := Transcript("s1", transcriptTurns(), "pdf", time.Now()); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
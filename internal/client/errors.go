@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Kinds of provider failure. Providers wrap their SDK errors in a
+// ProviderError carrying one of these, so callers can test with errors.Is.
+var (
+	ErrAuth          = errors.New("authentication failed")
+	ErrRateLimited   = errors.New("rate limited")
+	ErrModelNotFound = errors.New("model not found")
+	ErrContextLength = errors.New("input exceeds the model's context window")
+	ErrNetwork       = errors.New("provider unreachable")
+	ErrUnavailable   = errors.New("provider unavailable")
+)
+
+// ProviderError is a failure reported by a provider. Kind is one of the
+// error kinds above, or nil when the failure does not fit any of them.
+type ProviderError struct {
+	Provider string
+	Kind     error
+	Err      error
+}
+
+func (e *ProviderError) Error() string {
+	if e.Kind == nil {
+		return fmt.Sprintf("%s: %v", e.Provider, e.Err)
+	}
+	return fmt.Sprintf("%s: %v: %v", e.Provider, e.Kind, e.Err)
+}
+
+// Unwrap exposes both the kind and the underlying SDK error to errors.Is and errors.As.
+func (e *ProviderError) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// statusOverloaded is Anthropic's status for a temporarily overloaded API.
+const statusOverloaded = 529
+
+// contextLengthPhrases identify over-long input in provider error messages,
+// which arrive as a generic 400.
+var contextLengthPhrases = []string{
+	"context_length_exceeded",
+	"context length",
+	"maximum context",
+	"prompt is too long",
+	"input is too long",
+	"too many tokens",
+	"exceeds the maximum number of tokens",
+	"input token count",
+}
+
+// ClassifyStatus maps an HTTP status code and error message to an error kind.
+// It returns nil when neither identifies one.
+func ClassifyStatus(status int, message string) error {
+	switch status {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrAuth
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusNotFound:
+		return ErrModelNotFound
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout, statusOverloaded:
+		return ErrUnavailable
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge:
+		message = strings.ToLower(message)
+		for _, phrase := range contextLengthPhrases {
+			if strings.Contains(message, phrase) {
+				return ErrContextLength
+			}
+		}
+	}
+	return nil
+}
+
+// WrapError classifies err, returned by provider with HTTP status (0 when
+// there was no response), as a ProviderError. Errors that are already
+// classified, cancellations, and nil pass through unchanged.
+func WrapError(provider string, status int, err error) error {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return err
+	}
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		return err
+	}
+
+	kind := ClassifyStatus(status, err.Error())
+	if kind == nil && status == 0 && isNetworkError(err) {
+		kind = ErrNetwork
+	}
+	return &ProviderError{Provider: provider, Kind: kind, Err: err}
+}
+
+// ShouldFallback reports whether err is a transient provider failure, after
+// which another provider may still answer.
+func ShouldFallback(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrUnavailable) || errors.Is(err, ErrNetwork)
+}
+
+func isNetworkError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// Guidance returns a hint on how to fix err, or "" when there is none.
+func Guidance(err error) string {
+	switch {
+	case errors.Is(err, ErrAuth):
+		return "check your API key: run 'gomor set' and choose provider"
+	case errors.Is(err, ErrRateLimited):
+		return "the provider is rate limiting you: wait a moment and retry, or lower embedding_queue.requests_per_second"
+	case errors.Is(err, ErrModelNotFound):
+		return "the model is not available to your account: run 'gomor set' to pick another"
+	case errors.Is(err, ErrContextLength):
+		return "the input is too long for the model: shorten it, or lower chat.context_tokens or memory.max_injected_tokens"
+	case errors.Is(err, ErrUnavailable):
+		return "the provider is having problems: retry later, or list fallback models under model.fallbacks"
+	case errors.Is(err, ErrNetwork):
+		return "could not reach the provider: check your connection and base URL, or run with --offline"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWrapErrorClassifiesStatus(t *testing.T) {
+	tests := []struct {
+		status  int
+		message string
+		want    error
+	}{
+		{http.StatusUnauthorized, "invalid x-api-key", ErrAuth},
+		{http.StatusForbidden, "permission denied", ErrAuth},
+		{http.StatusTooManyRequests, "slow down", ErrRateLimited},
+		{http.StatusNotFound, "model gpt-9 does not exist", ErrModelNotFound},
+		{http.StatusServiceUnavailable, "overloaded", ErrUnavailable},
+		{529, "Overloaded", ErrUnavailable},
+		{http.StatusBadRequest, "prompt is too long: 210000 tokens > 200000 maximum", ErrContextLength},
+		{http.StatusBadRequest, "This model's maximum context length is 8192 tokens", ErrContextLength},
+	}
+	for _, tt := range tests {
+		err := WrapError("test", tt.status, errors.New(tt.message))
+		if !errors.Is(err, tt.want) {
+			t.Errorf("status %d %q: expected %v, got %v", tt.status, tt.message, tt.want, err)
+		}
+	}
+}
+
+func TestWrapErrorKeepsUnderlyingError(t *testing.T) {
+	sdkErr := errors.New("temperature must be <= 2")
+	err := WrapError("openai", http.StatusBadRequest, sdkErr)
+
+	var providerErr *ProviderError
+	if !errors.As(err, &providerErr) || providerErr.Kind != nil {
+		t.Fatalf("expected an unclassified ProviderError, got %#v", err)
+	}
+	if !errors.Is(err, sdkErr) {
+		t.Fatal("expected the SDK error to stay reachable")
+	}
+	if Guidance(err) != "" {
+		t.Fatalf("expected no guidance, got %q", Guidance(err))
+	}
+}
+
+func TestWrapErrorNetwork(t *testing.T) {
+	err := WrapError("google", 0, fmt.Errorf("embedding failed: %w", context.DeadlineExceeded))
+	if !errors.Is(err, ErrNetwork) {
+		t.Fatalf("expected ErrNetwork, got %v", err)
+	}
+
+	if err := WrapError("google", 0, context.Canceled); err != context.Canceled {
+		t.Fatalf("expected cancellation to pass through, got %v", err)
+	}
+	if err := WrapError("google", 0, nil); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+}
+
+func TestWrapErrorDoesNotRewrap(t *testing.T) {
+	first := WrapError("anthropic", http.StatusUnauthorized, errors.New("invalid key"))
+	second := WrapError("anthropic", 0, fmt.Errorf("chat failed: %w", first))
+	if strings.Count(second.Error(), "anthropic:") != 1 {
+		t.Fatalf("expected a single provider prefix, got %q", second.Error())
+	}
+}
+
+func TestGuidanceThroughWrapping(t *testing.T) {
+	err := fmt.Errorf("failed to get response: %w", WrapError("openai", http.StatusUnauthorized, errors.New("bad key")))
+	if hint := Guidance(err); !strings.Contains(hint, "gomor set") {
+		t.Fatalf("expected a hint to run gomor set, got %q", hint)
+	}
+}
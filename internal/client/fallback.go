@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+// Fallback is a model to answer with when the models before it fail, and the
+// client for its provider.
+type Fallback struct {
+	Model  types.Model
+	Client QueryClient
+}
+
+// SwitchFunc is called when a request moves from one model to the next
+// because of err.
+type SwitchFunc func(from, to types.Model, err error)
+
+// FallbackClient answers with the requested model and, when its provider is
+// rate limited or unavailable, with each fallback in turn. A stream only moves
+// on before it has produced output, so an answer never mixes models.
+type FallbackClient struct {
+	primary   QueryClient
+	fallbacks []Fallback
+	onSwitch  SwitchFunc
+}
+
+// NewFallbackClient wraps primary with fallbacks. onSwitch may be nil.
+func NewFallbackClient(primary QueryClient, fallbacks []Fallback, onSwitch SwitchFunc) *FallbackClient {
+	return &FallbackClient{primary: primary, fallbacks: fallbacks, onSwitch: onSwitch}
+}
+
+func (c *FallbackClient) ChatStream(ctx context.Context, model types.Model, query string) (StreamResponse, error) {
+	return c.stream(model, func(qc QueryClient, m types.Model) (StreamResponse, error) {
+		return qc.ChatStream(ctx, m, query)
+	})
+}
+
+func (c *FallbackClient) ChatStreamWithContext(ctx context.Context, model types.Model, systemContext, query string) (StreamResponse, error) {
+	return c.stream(model, func(qc QueryClient, m types.Model) (StreamResponse, error) {
+		return qc.ChatStreamWithContext(ctx, m, systemContext, query)
+	})
+}
+
+// ChatWithTools answers with the requested model and moves on to each
+// fallback whose client can call tools, like ChatStream.
+func (c *FallbackClient) ChatWithTools(ctx context.Context, model types.Model, systemContext string, messages []ToolMessage, tools []Tool) (*ToolReply, error) {
+	var attempts []Fallback
+	for _, a := range append([]Fallback{{Model: model, Client: c.primary}}, c.fallbacks...) {
+		if _, ok := a.Client.(ToolClient); ok {
+			attempts = append(attempts, a)
+		}
+	}
+	if len(attempts) == 0 {
+		return nil, ErrToolsUnsupported
+	}
+
+	for i, a := range attempts {
+		reply, err := a.Client.(ToolClient).ChatWithTools(ctx, a.Model, systemContext, messages, tools)
+		if err == nil {
+			return reply, nil
+		}
+		if i+1 >= len(attempts) || !ShouldFallback(err) {
+			return nil, err
+		}
+		if c.onSwitch != nil {
+			c.onSwitch(a.Model, attempts[i+1].Model, err)
+		}
+	}
+	return nil, ErrToolsUnsupported
+}
+
+// ListModels lists the models of the primary provider.
+func (c *FallbackClient) ListModels(ctx context.Context) ([]string, error) {
+	return c.primary.ListModels(ctx)
+}
+
+func (c *FallbackClient) stream(model types.Model, open func(QueryClient, types.Model) (StreamResponse, error)) (StreamResponse, error) {
+	s := &fallbackStream{
+		attempts: append([]Fallback{{Model: model, Client: c.primary}}, c.fallbacks...),
+		open:     open,
+		onSwitch: c.onSwitch,
+	}
+	if err := s.start(0); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+type fallbackStream struct {
+	attempts []Fallback
+	open     func(QueryClient, types.Model) (StreamResponse, error)
+	onSwitch SwitchFunc
+
+	index   int
+	current StreamResponse
+	started bool
+	err     error
+}
+
+// start opens the stream of attempt i, moving on while opening fails in a way
+// another provider may not.
+func (s *fallbackStream) start(i int) error {
+	for ; ; i++ {
+		stream, err := s.open(s.attempts[i].Client, s.attempts[i].Model)
+		if err == nil {
+			s.index, s.current = i, stream
+			return nil
+		}
+		if !s.switchFrom(i, err) {
+			return err
+		}
+	}
+}
+
+// switchFrom reports whether attempt i failing with err moves on to the next
+// attempt, announcing the switch when it does.
+func (s *fallbackStream) switchFrom(i int, err error) bool {
+	if i+1 >= len(s.attempts) || !ShouldFallback(err) {
+		return false
+	}
+	if s.onSwitch != nil {
+		s.onSwitch(s.attempts[i].Model, s.attempts[i+1].Model, err)
+	}
+	return true
+}
+
+func (s *fallbackStream) Next() bool {
+	for s.current != nil {
+		if s.current.Next() {
+			s.started = true
+			return true
+		}
+		err := s.current.Err()
+		if err == nil || s.started || !s.switchFrom(s.index, err) {
+			return false
+		}
+		s.current.Close()
+		s.current = nil
+		if err := s.start(s.index + 1); err != nil {
+			s.err = err
+		}
+	}
+	return false
+}
+
+func (s *fallbackStream) GetChunk() string {
+	if s.current == nil {
+		return ""
+	}
+	return s.current.GetChunk()
+}
+
+func (s *fallbackStream) Err() error {
+	if s.err != nil || s.current == nil {
+		return s.err
+	}
+	return s.current.Err()
+}
+
+func (s *fallbackStream) Close() error {
+	if s.current == nil {
+		return nil
+	}
+	return s.current.Close()
+}
@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+type fakeStream struct {
+	chunks []string
+	err    error
+	idx    int
+}
+
+func (s *fakeStream) Next() bool {
+	if s.idx >= len(s.chunks) {
+		return false
+	}
+	s.idx++
+	return true
+}
+
+func (s *fakeStream) GetChunk() string { return s.chunks[s.idx-1] }
+func (s *fakeStream) Err() error       { return s.err }
+func (s *fakeStream) Close() error     { return nil }
+
+// fakeQueryClient streams chunks and then fails with streamErr, or fails to
+// open the stream with openErr.
+type fakeQueryClient struct {
+	chunks    []string
+	streamErr error
+	openErr   error
+	models    []string
+}
+
+func (c *fakeQueryClient) ChatStream(ctx context.Context, model types.Model, query string) (StreamResponse, error) {
+	return c.ChatStreamWithContext(ctx, model, "", query)
+}
+
+func (c *fakeQueryClient) ChatStreamWithContext(_ context.Context, model types.Model, _, _ string) (StreamResponse, error) {
+	c.models = append(c.models, model.ModelID)
+	if c.openErr != nil {
+		return nil, c.openErr
+	}
+	return &fakeStream{chunks: c.chunks, err: c.streamErr}, nil
+}
+
+func (c *fakeQueryClient) ListModels(context.Context) ([]string, error) { return nil, nil }
+
+func readAll(t *testing.T, stream StreamResponse) (string, error) {
+	t.Helper()
+	var sb strings.Builder
+	for stream.Next() {
+		sb.WriteString(stream.GetChunk())
+	}
+	return sb.String(), stream.Err()
+}
+
+func TestFallbackClientSwitchesOnRateLimit(t *testing.T) {
+	rateLimited := WrapError("openai", http.StatusTooManyRequests, errors.New("slow down"))
+	primary := &fakeQueryClient{streamErr: rateLimited}
+	unavailable := &fakeQueryClient{openErr: WrapError("google", http.StatusServiceUnavailable, errors.New("overloaded"))}
+	backup := &fakeQueryClient{chunks: []string{"hello", " world"}}
+
+	var switches []string
+	c := NewFallbackClient(primary, []Fallback{
+		{Model: types.Model{Provider: "google", ModelID: "gemini-flash"}, Client: unavailable},
+		{Model: types.Model{Provider: "anthropic", ModelID: "claude-haiku"}, Client: backup},
+	}, func(from, to types.Model, err error) {
+		switches = append(switches, from.ModelID+"->"+to.ModelID)
+	})
+
+	stream, err := c.ChatStream(context.Background(), types.Model{Provider: "openai", ModelID: "gpt-4o"}, "hi")
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	answer, err := readAll(t, stream)
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	if answer != "hello world" {
+		t.Fatalf("expected the backup's answer, got %q", answer)
+	}
+	if strings.Join(switches, ",") != "gpt-4o->gemini-flash,gemini-flash->claude-haiku" {
+		t.Fatalf("unexpected switches: %v", switches)
+	}
+	if len(backup.models) != 1 || backup.models[0] != "claude-haiku" {
+		t.Fatalf("expected the backup to be asked with its own model, got %v", backup.models)
+	}
+}
+
+func TestFallbackClientKeepsOtherErrors(t *testing.T) {
+	authErr := WrapError("openai", http.StatusUnauthorized, errors.New("bad key"))
+	backup := &fakeQueryClient{chunks: []string{"unused"}}
+	c := NewFallbackClient(&fakeQueryClient{openErr: authErr}, []Fallback{
+		{Model: types.Model{Provider: "google", ModelID: "gemini-flash"}, Client: backup},
+	}, nil)
+
+	_, err := c.ChatStream(context.Background(), types.Model{Provider: "openai", ModelID: "gpt-4o"}, "hi")
+	if !errors.Is(err, ErrAuth) {
+		t.Fatalf("expected the auth error, got %v", err)
+	}
+	if len(backup.models) != 0 {
+		t.Fatal("expected no fallback for an auth error")
+	}
+}
+
+func TestFallbackClientDoesNotSwitchMidAnswer(t *testing.T) {
+	rateLimited := WrapError("openai", http.StatusTooManyRequests, errors.New("slow down"))
+	backup := &fakeQueryClient{chunks: []string{"unused"}}
+	c := NewFallbackClient(&fakeQueryClient{chunks: []string{"partial"}, streamErr: rateLimited}, []Fallback{
+		{Model: types.Model{Provider: "google", ModelID: "gemini-flash"}, Client: backup},
+	}, nil)
+
+	stream, err := c.ChatStream(context.Background(), types.Model{Provider: "openai", ModelID: "gpt-4o"}, "hi")
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	answer, err := readAll(t, stream)
+	if answer != "partial" || !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected the partial answer and the rate limit error, got %q, %v", answer, err)
+	}
+	if len(backup.models) != 0 {
+		t.Fatal("expected no fallback once output has started")
+	}
+}
+
+func TestFallbackClientReturnsLastError(t *testing.T) {
+	first := WrapError("openai", http.StatusTooManyRequests, errors.New("slow down"))
+	last := WrapError("google", http.StatusServiceUnavailable, errors.New("overloaded"))
+	c := NewFallbackClient(&fakeQueryClient{streamErr: first}, []Fallback{
+		{Model: types.Model{Provider: "google", ModelID: "gemini-flash"}, Client: &fakeQueryClient{streamErr: last}},
+	}, nil)
+
+	stream, err := c.ChatStream(context.Background(), types.Model{Provider: "openai", ModelID: "gpt-4o"}, "hi")
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	if _, err := readAll(t, stream); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("expected the last provider's error, got %v", err)
+	}
+}
+
+// fakeToolClient answers tool requests with reply, or fails with err.
+type fakeToolClient struct {
+	fakeQueryClient
+	reply string
+	err   error
+}
+
+func (c *fakeToolClient) ChatWithTools(_ context.Context, model types.Model, _ string, _ []ToolMessage, _ []Tool) (*ToolReply, error) {
+	c.models = append(c.models, model.ModelID)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &ToolReply{Content: c.reply}, nil
+}
+
+func TestFallbackClientChatWithToolsSkipsClientsWithoutTools(t *testing.T) {
+	rateLimited := WrapError("openai", http.StatusTooManyRequests, errors.New("slow down"))
+	noTools := &fakeQueryClient{}
+	backup := &fakeToolClient{reply: "done"}
+
+	var switches []string
+	c := NewFallbackClient(&fakeToolClient{err: rateLimited}, []Fallback{
+		{Model: types.Model{Provider: "local", ModelID: "plain"}, Client: noTools},
+		{Model: types.Model{Provider: "anthropic", ModelID: "claude-haiku"}, Client: backup},
+	}, func(from, to types.Model, err error) {
+		switches = append(switches, from.ModelID+"->"+to.ModelID)
+	})
+
+	reply, err := c.ChatWithTools(context.Background(), types.Model{Provider: "openai", ModelID: "gpt-4o"}, "", nil, nil)
+	if err != nil {
+		t.Fatalf("ChatWithTools: %v", err)
+	}
+	if reply.Content != "done" || strings.Join(switches, ",") != "gpt-4o->claude-haiku" {
+		t.Fatalf("expected the backup to answer, got %q after %v", reply.Content, switches)
+	}
+	if len(noTools.models) != 0 {
+		t.Fatal("expected the client without tools to be skipped")
+	}
+}
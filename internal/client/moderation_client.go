@@ -0,0 +1,9 @@
+package client
+
+import "context"
+
+// ModerationClient classifies text against a provider's content policy.
+type ModerationClient interface {
+	// Moderate returns the policy categories text is flagged for, if any.
+	Moderate(ctx context.Context, text string) ([]string, error)
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"errors"
+
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+// Roles of the messages in a tool-using exchange.
+const (
+	ToolRoleUser      = "user"
+	ToolRoleAssistant = "assistant"
+	ToolRoleTool      = "tool"
+)
+
+// Tool is a function the chat model may call.
+type Tool struct {
+	Name        string
+	Description string
+	// Parameters is the JSON Schema of the arguments, an object schema.
+	Parameters map[string]any
+}
+
+// ToolCall is the model asking for a tool to be called.
+type ToolCall struct {
+	ID        string
+	Name      string
+	Arguments string // JSON object
+}
+
+// ToolMessage is one message of a tool-using exchange: a user prompt, an
+// assistant reply that may call tools, or the result of one tool call.
+type ToolMessage struct {
+	Role       string
+	Content    string
+	ToolCalls  []ToolCall // assistant messages that call tools
+	ToolCallID string     // tool messages: the call they answer
+	ToolName   string     // tool messages: the tool that was called
+}
+
+// ToolReply is the model's answer in a tool-using exchange. When ToolCalls is
+// set, the caller runs them and sends the results back in tool messages.
+type ToolReply struct {
+	Content   string
+	ToolCalls []ToolCall
+}
+
+// ErrToolsUnsupported is returned when no client of a request can call tools.
+var ErrToolsUnsupported = errors.New("the chat model's provider cannot call tools")
+
+// ToolClient is implemented by query clients whose models can call tools.
+// Replies are not streamed, since a reply may turn out to be a tool call.
+type ToolClient interface {
+	ChatWithTools(ctx context.Context, model types.Model, systemContext string, messages []ToolMessage, tools []Tool) (*ToolReply, error)
+}
@@ -0,0 +1,330 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/chat"
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/hooks"
+	"github.com/austiecodes/gomor/internal/markdown"
+	"github.com/austiecodes/gomor/internal/mcpclient"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/moderation"
+	"github.com/austiecodes/gomor/internal/pricing"
+	"github.com/austiecodes/gomor/internal/provider"
+	"github.com/austiecodes/gomor/internal/trace"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// recallLimit is how many past prompts are loaded for up-arrow and Ctrl-R recall.
+const recallLimit = 1000
+
+type chatCommandOptions struct {
+	session       string
+	raw           bool
+	enforceBudget bool
+	noTools       bool
+	model         string
+}
+
+// sender sends one prompt and streams the answer; *chat.Session implements it.
+type sender interface {
+	Send(ctx context.Context, prompt string, out io.Writer) (string, error)
+	Retry(ctx context.Context, out io.Writer) (string, error)
+	Fork(ref string) (memtypes.Session, error)
+	SwitchModel(ref string) (types.Model, error)
+}
+
+// modelSession is a chat session that /model can switch to another model.
+type modelSession struct {
+	*chat.Session
+	config  *utils.Config
+	notice  io.Writer
+	spinner *chat.Spinner
+}
+
+// Send answers prompt, showing the spinner until the answer starts.
+func (m *modelSession) Send(ctx context.Context, prompt string, out io.Writer) (string, error) {
+	m.spinner.Start()
+	defer m.spinner.Stop()
+	return m.Session.Send(ctx, prompt, m.spinner.Wrap(out))
+}
+
+// Retry regenerates the last answer, showing the spinner until it starts.
+func (m *modelSession) Retry(ctx context.Context, out io.Writer) (string, error) {
+	m.spinner.Start()
+	defer m.spinner.Stop()
+	return m.Session.Retry(ctx, m.spinner.Wrap(out))
+}
+
+// SwitchModel answers the rest of the session with the model ref names, an
+// alias, provider/model ID, or role, keeping the sampling settings. An empty
+// ref returns the current model.
+func (m *modelSession) SwitchModel(ref string) (types.Model, error) {
+	model := m.Model()
+	if ref == "" {
+		return model, nil
+	}
+	resolved, err := m.config.Model.ResolveModel(ref)
+	if err != nil {
+		return types.Model{}, err
+	}
+	model.Provider, model.ModelID = resolved.Provider, resolved.ModelID
+	queryClient, err := provider.NewRoleQueryClient(m.config, utils.RoleChat, model, chat.SwitchNotice(m.notice))
+	if err != nil {
+		return types.Model{}, fmt.Errorf("failed to create chat client: %w", err)
+	}
+	m.SetModel(queryClient, model)
+	return model, nil
+}
+
+// connectTools connects to the configured MCP servers, offering the model
+// the user's tool preferences and a tool to save new ones.
+func connectTools(ctx context.Context, config *utils.Config, memStore *store.Store) (*mcpclient.Toolset, error) {
+	toolset, err := mcpclient.Connect(ctx, config.MCP.Servers)
+
+	// Preferences only steer the model, so a failed lookup is not fatal.
+	var preferences []string
+	if memories, merr := memStore.GetAllMemories(); merr == nil {
+		preferences = mcpclient.Preferences(memories)
+	}
+	toolset.RememberPreferences(preferences, func(ctx context.Context, text string) error {
+		_, err := memoryservice.Save(ctx, memoryservice.SaveInput{
+			Text:     text,
+			Tags:     []string{mcpclient.PreferenceTag},
+			Kind:     memtypes.KindPreference,
+			Deferred: true,
+		})
+		return err
+	})
+	return toolset, err
+}
+
+// recallFor retrieves memories for the system prompt of session, using its
+// recent turns to resolve follow-up prompts.
+func recallFor(session *chat.Session) chat.Recall {
+	return func(ctx context.Context, prompt string) (string, error) {
+		result, err := memoryservice.Retrieve(ctx, memoryservice.RetrieveInput{Query: prompt, SessionID: session.ID, Command: "chat"})
+		if err != nil {
+			return "", err
+		}
+		return result.Text, nil
+	}
+}
+
+var ChatCmd = newChatCommand()
+
+func newChatCommand() *cobra.Command {
+	opts := &chatCommandOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "chat",
+		Short: "Start an interactive chat with the chat model",
+		Long: `Start an interactive chat with the configured chat model. Every turn is
+saved to history.
+
+Editing:
+  Up/Down, Ctrl-P/Ctrl-N  recall earlier prompts (from all sessions)
+  Ctrl-R                  search earlier prompts; Ctrl-R again for older matches
+  Ctrl-A/E, Ctrl-W/U/K    move and delete as in a shell
+  @                       search memories as you type; Up/Down pick one, Enter
+                          inserts a reference (its text is sent along with
+                          the prompt), Tab inserts its text, Esc closes
+  \ at end of line        continue the prompt on the next line
+  ` + "```" + `                     lines inside a code fence are joined until it closes
+  Ctrl-C                  discard the current prompt
+  Ctrl-D or /exit         leave the chat
+
+Commands:
+  /retry                  regenerate the answer to the last prompt
+  /fork [turn]            branch the conversation after a turn (default: the
+                          latest) and continue on the branch
+  /model [name]           show the model, or answer from now on with another:
+                          an alias from model.aliases, provider/model, or a role
+
+Tools:
+  The model may call the tools of the MCP servers listed under mcp.servers in
+  the config, e.g. a filesystem or web search server. Each call is shown as it
+  runs. Preferences you state about using the tools are saved as memories
+  tagged tool-preference and followed in later chats.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runChatCommand(cmd, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.session, "session", "", "session ID to continue (default: a new session)")
+	cmd.Flags().BoolVar(&opts.raw, "raw", false, "print answers as raw markdown (overrides chat.raw_markdown)")
+	cmd.Flags().StringVarP(&opts.model, "model", "m", "", "chat with this model instead of the chat model: an alias from model.aliases, a provider/model ID, or a role")
+	cmd.Flags().BoolVar(&opts.noTools, "no-tools", false, "do not connect to the configured MCP servers")
+	cmd.Flags().BoolVar(&opts.enforceBudget, "enforce-budget", false, "refuse to send prompts when the budget is used up, instead of warning")
+
+	return cmd
+}
+
+func runChatCommand(cmd *cobra.Command, opts *chatCommandOptions) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	config, err := utils.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	var chatModel types.Model
+	if config.Model.ChatModel != nil {
+		chatModel = *config.Model.ChatModel
+	}
+	if opts.model != "" {
+		model, err := config.Model.ResolveModel(opts.model)
+		if err != nil {
+			return err
+		}
+		chatModel.Provider, chatModel.ModelID = model.Provider, model.ModelID
+	}
+	if chatModel.ModelID == "" {
+		return utils.ConfigErrorf("chat model not configured. Run 'gomor set' to configure")
+	}
+
+	// The spinner shows while an answer is awaited; everything else written to
+	// stderr clears it first.
+	spinner := chat.NewSpinner(cmd.ErrOrStderr())
+	errOut := spinner.Wrap(cmd.ErrOrStderr())
+
+	queryClient, err := provider.NewRoleQueryClient(config, utils.RoleChat, chatModel, chat.SwitchNotice(errOut))
+	if err != nil {
+		return fmt.Errorf("failed to create chat client: %w", err)
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		return fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	prompts, err := memStore.RecentPrompts(recallLimit)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	reader := newLineReader(cmd.InOrStdin(), out, newPromptHistory(prompts), newMemoryMentions(memStore.GetAllMemories))
+	session := chat.NewSession(memStore, queryClient, chatModel, opts.session)
+	if err := session.SetContextConfig(config.Chat); err != nil {
+		return err
+	}
+	session.SetBudget(pricing.NewBudget(memStore, config.Budget, opts.enforceBudget, errOut))
+	moderator, err := moderation.FromConfig(config)
+	if err != nil {
+		return err
+	}
+	session.SetModerator(moderator)
+	session.SetHooks(hooks.New(config.Hooks), errOut)
+	session.SetRecall(recallFor(session), errOut)
+	// Titles are a nicety: without a usable title model sessions stay untitled.
+	if config.Model.TitleModel != nil {
+		if titleClient, err := provider.NewRoleQueryClient(config, utils.RoleTitle, *config.Model.TitleModel, nil); err == nil {
+			session.SetTitler(chat.NewTitler(titleClient, *config.Model.TitleModel))
+			defer session.WaitForTitle()
+		} else {
+			trace.Printf(trace.Info, "title: %v", err)
+		}
+	}
+
+	if len(config.MCP.Servers) > 0 && !opts.noTools {
+		toolset, err := connectTools(ctx, config, memStore)
+		if err != nil {
+			fmt.Fprintf(errOut, "Warning: %v\n", err)
+		}
+		defer toolset.Close()
+		session.SetTools(toolset, errOut)
+	}
+
+	raw := config.Chat.RawMarkdown
+	if cmd.Flags().Changed("raw") {
+		raw = opts.raw
+	}
+
+	return runREPL(ctx, reader, &modelSession{Session: session, config: config, notice: errOut, spinner: spinner}, out, errOut, !raw)
+}
+
+// runREPL reads prompts until EOF or /exit, streaming each answer to out,
+// rendered as markdown when render is set. Failed turns are reported on errOut
+// without ending the session; a turn interrupted by cancelling ctx ends it.
+func runREPL(ctx context.Context, reader lineReader, s sender, out, errOut io.Writer, render bool) error {
+	for {
+		prompt, err := reader.ReadPrompt()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		prompt = strings.TrimSpace(prompt)
+		switch prompt {
+		case "":
+			continue
+		case "/exit", "/quit":
+			return nil
+		}
+
+		if prompt == "/fork" || strings.HasPrefix(prompt, "/fork ") {
+			branch, err := s.Fork(strings.TrimPrefix(prompt, "/fork"))
+			if err != nil {
+				fmt.Fprintf(errOut, "Error: %v\n", err)
+				continue
+			}
+			fmt.Fprintf(out, "Forked from session %s; now in session %s\n", branch.ParentID, branch.ID)
+			continue
+		}
+
+		if prompt == "/model" || strings.HasPrefix(prompt, "/model ") {
+			model, err := s.SwitchModel(strings.TrimSpace(strings.TrimPrefix(prompt, "/model")))
+			if err != nil {
+				fmt.Fprintf(errOut, "Error: %v\n", err)
+				continue
+			}
+			fmt.Fprintf(out, "Model: %s/%s\n", model.Provider, model.ModelID)
+			continue
+		}
+
+		answerOut := out
+		var md *markdown.Renderer
+		if render {
+			md = markdown.NewRenderer(out)
+			answerOut = md
+		}
+
+		if prompt == "/retry" {
+			_, err = s.Retry(ctx, answerOut)
+		} else {
+			_, err = s.Send(ctx, prompt, answerOut)
+		}
+		if md != nil {
+			_ = md.Flush()
+		}
+		if err != nil && ctx.Err() != nil {
+			// Interrupted: the partial answer is saved, and gomor exits.
+			return err
+		}
+		if err != nil {
+			fmt.Fprintf(errOut, "Error: %v\n", err)
+			if hint := client.Guidance(err); hint != "" {
+				fmt.Fprintf(errOut, "Hint: %s\n", hint)
+			}
+			continue
+		}
+		fmt.Fprintln(out)
+	}
+}
@@ -0,0 +1,303 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/types"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type fakeSender struct {
+	prompts []string
+	retries int
+	forks   []string
+	models  []string
+	fail    bool
+}
+
+func (f *fakeSender) Send(ctx context.Context, prompt string, out io.Writer) (string, error) {
+	f.prompts = append(f.prompts, prompt)
+	if f.fail {
+		return "", errors.New("provider down")
+	}
+	_, _ = io.WriteString(out, "answer")
+	return "answer", nil
+}
+
+func (f *fakeSender) Fork(ref string) (memtypes.Session, error) {
+	f.forks = append(f.forks, ref)
+	return memtypes.Session{ID: "branch", ParentID: "main"}, nil
+}
+
+func (f *fakeSender) Retry(ctx context.Context, out io.Writer) (string, error) {
+	f.retries++
+	_, _ = io.WriteString(out, "again")
+	return "again", nil
+}
+
+func (f *fakeSender) SwitchModel(ref string) (types.Model, error) {
+	f.models = append(f.models, ref)
+	if ref == "bogus" {
+		return types.Model{}, errors.New("unknown model")
+	}
+	return types.Model{Provider: "openai", ModelID: "gpt-4o-mini"}, nil
+}
+
+func TestRunREPLJoinsContinuationLines(t *testing.T) {
+	input := "first line \\\nsecond line\n```go\nfunc main() {}\n```\n/exit\nignored\n"
+	history := newPromptHistory(nil)
+	reader := newLineReader(strings.NewReader(input), io.Discard, history, nil)
+
+	s := &fakeSender{}
+	var out bytes.Buffer
+	if err := runREPL(context.Background(), reader, s, &out, io.Discard, false); err != nil {
+		t.Fatalf("run repl: %v", err)
+	}
+
+	want := []string{"first line \nsecond line", "```go\nfunc main() {}\n```"}
+	if len(s.prompts) != len(want) {
+		t.Fatalf("expected %d prompts, got %q", len(want), s.prompts)
+	}
+	for i := range want {
+		if s.prompts[i] != want[i] {
+			t.Fatalf("prompt %d = %q, want %q", i, s.prompts[i], want[i])
+		}
+	}
+	if len(history.entries) != 3 {
+		t.Fatalf("expected submitted prompts in history, got %q", history.entries)
+	}
+}
+
+func TestRunREPLRetry(t *testing.T) {
+	reader := newLineReader(strings.NewReader("question\n/retry\n"), io.Discard, newPromptHistory(nil), nil)
+	s := &fakeSender{}
+	var out bytes.Buffer
+
+	if err := runREPL(context.Background(), reader, s, &out, io.Discard, false); err != nil {
+		t.Fatalf("run repl: %v", err)
+	}
+	if len(s.prompts) != 1 || s.retries != 1 {
+		t.Fatalf("expected one prompt and one retry, got prompts=%q retries=%d", s.prompts, s.retries)
+	}
+	if out.String() != "answer\nagain\n" {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+}
+
+func TestRunREPLFork(t *testing.T) {
+	reader := newLineReader(strings.NewReader("/fork\n/fork 3\n/forks are fun\n"), io.Discard, newPromptHistory(nil), nil)
+	s := &fakeSender{}
+	var out bytes.Buffer
+
+	if err := runREPL(context.Background(), reader, s, &out, io.Discard, false); err != nil {
+		t.Fatalf("run repl: %v", err)
+	}
+	if len(s.forks) != 2 || s.forks[0] != "" || strings.TrimSpace(s.forks[1]) != "3" {
+		t.Fatalf("unexpected forks %q", s.forks)
+	}
+	if len(s.prompts) != 1 || s.prompts[0] != "/forks are fun" {
+		t.Fatalf("expected non-command input to be sent, got %q", s.prompts)
+	}
+	if !strings.Contains(out.String(), "now in session branch") {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+}
+
+func TestRunREPLModel(t *testing.T) {
+	reader := newLineReader(strings.NewReader("/model fast\n/model bogus\n/model\n/models\n"), io.Discard, newPromptHistory(nil), nil)
+	s := &fakeSender{}
+	var out, errOut bytes.Buffer
+
+	if err := runREPL(context.Background(), reader, s, &out, &errOut, false); err != nil {
+		t.Fatalf("run repl: %v", err)
+	}
+	if len(s.models) != 3 || s.models[0] != "fast" || s.models[2] != "" {
+		t.Fatalf("unexpected model switches %q", s.models)
+	}
+	if len(s.prompts) != 1 || s.prompts[0] != "/models" {
+		t.Fatalf("expected non-command input to be sent, got %q", s.prompts)
+	}
+	if !strings.HasPrefix(out.String(), "Model: openai/gpt-4o-mini\n") || !strings.Contains(errOut.String(), "unknown model") {
+		t.Fatalf("unexpected output %q / %q", out.String(), errOut.String())
+	}
+}
+
+func TestRunREPLKeepsGoingAfterFailedTurn(t *testing.T) {
+	reader := newLineReader(strings.NewReader("one\ntwo\n"), io.Discard, newPromptHistory(nil), nil)
+	s := &fakeSender{fail: true}
+	var errOut bytes.Buffer
+
+	if err := runREPL(context.Background(), reader, s, io.Discard, &errOut, false); err != nil {
+		t.Fatalf("run repl: %v", err)
+	}
+	if len(s.prompts) != 2 {
+		t.Fatalf("expected both prompts to be sent, got %q", s.prompts)
+	}
+	if !strings.Contains(errOut.String(), "provider down") {
+		t.Fatalf("expected error to be reported, got %q", errOut.String())
+	}
+}
+
+// markdownSender answers with markdown split across chunks.
+type markdownSender struct{}
+
+func (markdownSender) Send(ctx context.Context, prompt string, out io.Writer) (string, error) {
+	for _, chunk := range []string{"# Ti", "tle\n- item\n", "```go\nx := 1\n```\ndone"} {
+		_, _ = io.WriteString(out, chunk)
+	}
+	return "", nil
+}
+
+func (markdownSender) Retry(ctx context.Context, out io.Writer) (string, error) {
+	return "", nil
+}
+
+func (markdownSender) Fork(ref string) (memtypes.Session, error) {
+	return memtypes.Session{}, nil
+}
+
+func (markdownSender) SwitchModel(ref string) (types.Model, error) {
+	return types.Model{}, nil
+}
+
+func TestRunREPLRendersMarkdown(t *testing.T) {
+	for _, tc := range []struct {
+		render bool
+		want   string
+	}{
+		{render: true, want: "Title\n• item\n  go\n  x := 1\ndone\n"},
+		{render: false, want: "# Title\n- item\n```go\nx := 1\n```\ndone\n"},
+	} {
+		reader := newLineReader(strings.NewReader("hi\n"), io.Discard, newPromptHistory(nil), nil)
+		var out bytes.Buffer
+		if err := runREPL(context.Background(), reader, markdownSender{}, &out, io.Discard, tc.render); err != nil {
+			t.Fatalf("run repl: %v", err)
+		}
+		if out.String() != tc.want {
+			t.Fatalf("render=%v: got %q, want %q", tc.render, out.String(), tc.want)
+		}
+	}
+}
+
+func TestPromptHistoryNavigation(t *testing.T) {
+	h := newPromptHistory([]string{"alpha", "beta"})
+
+	if got, _ := h.prev("draft"); got != "beta" {
+		t.Fatalf("prev = %q, want beta", got)
+	}
+	if got, _ := h.prev("beta"); got != "alpha" {
+		t.Fatalf("prev = %q, want alpha", got)
+	}
+	if _, ok := h.prev("alpha"); ok {
+		t.Fatal("expected no entry before the oldest")
+	}
+	h.next()
+	if got, _ := h.next(); got != "draft" {
+		t.Fatalf("expected to return to the draft, got %q", got)
+	}
+}
+
+func TestPromptModelReverseSearch(t *testing.T) {
+	h := newPromptHistory([]string{"explain go channels", "list files", "go test flags"})
+	var m tea.Model = newPromptModel(h, nil)
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("go")})
+	if got := m.(promptModel).history.entries[m.(promptModel).searchIdx]; got != "go test flags" {
+		t.Fatalf("expected newest match, got %q", got)
+	}
+	if !strings.Contains(m.View(), "(reverse-i-search)`go': go test flags") {
+		t.Fatalf("unexpected search view: %q", m.View())
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	pm := m.(promptModel)
+	if pm.searching || pm.input.Value() != "explain go channels" {
+		t.Fatalf("expected older match accepted for editing, got %q (searching=%v)", pm.input.Value(), pm.searching)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if pm := m.(promptModel); !pm.done || joinLines(pm.lines) != "explain go channels" {
+		t.Fatalf("expected prompt to be submitted, got %+v", pm.lines)
+	}
+}
+
+func TestPromptModelContinuationPrompt(t *testing.T) {
+	var m tea.Model = newPromptModel(newPromptHistory(nil), nil)
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(`one \`)})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	pm := m.(promptModel)
+	if pm.done || pm.input.Prompt != continuationMarker {
+		t.Fatalf("expected continuation prompt, got done=%v prompt=%q", pm.done, pm.input.Prompt)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("two")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if pm := m.(promptModel); !pm.done || joinLines(pm.lines) != "one \ntwo" {
+		t.Fatalf("unexpected prompt: %q", joinLines(pm.lines))
+	}
+}
+
+func testMentions() *memoryMentions {
+	return newMemoryMentions(func() ([]memtypes.MemoryItem, error) {
+		return []memtypes.MemoryItem{
+			{ID: "11111111-aaaa", Text: "Prefers table-driven\ntests in Go"},
+			{ID: "22222222-bbbb", Text: "Deploys with kubectl apply"},
+			{ID: "33333333-cccc", Text: "Hidden memory", Suppressed: true},
+		}, nil
+	})
+}
+
+func TestPromptModelMentionsMemories(t *testing.T) {
+	var m tea.Model = newPromptModel(newPromptHistory(nil), testMentions())
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("see @")})
+	if got := len(m.(promptModel).matches); got != 2 {
+		t.Fatalf("expected the two visible memories offered, got %d", got)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("kbctl")})
+	if pm := m.(promptModel); len(pm.matches) != 1 || !strings.Contains(pm.View(), "22222222  Deploys with kubectl apply") {
+		t.Fatalf("expected a fuzzy match on kubectl, got %q", pm.View())
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if pm := m.(promptModel); pm.done || pm.input.Value() != "see @mem:22222222 " || len(pm.matches) != 0 {
+		t.Fatalf("expected Enter to insert a reference, got %q (done=%v)", pm.input.Value(), pm.done)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("and @tests")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if got := m.(promptModel).input.Value(); got != "see @mem:22222222 and Prefers table-driven tests in Go " {
+		t.Fatalf("expected Tab to insert the memory's text, got %q", got)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("@")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if got := len(m.(promptModel).matches); got != 0 {
+		t.Fatalf("expected Esc to close the list for this @, got %d matches", got)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if pm := m.(promptModel); !pm.done {
+		t.Fatal("expected Enter to submit once the list is closed")
+	}
+}
+
+func TestMentionsExpandReferences(t *testing.T) {
+	reader := newLineReader(strings.NewReader("compare @mem:11111111 with @mem:11111111 and @mem:99999999\n"), io.Discard, newPromptHistory(nil), testMentions())
+	prompt, err := reader.ReadPrompt()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	want := "compare @mem:11111111 with @mem:11111111 and @mem:99999999\n\nMemories referenced above:\n@mem:11111111: Prefers table-driven\ntests in Go"
+	if prompt != want {
+		t.Fatalf("unexpected prompt:\n%q\nwant\n%q", prompt, want)
+	}
+}
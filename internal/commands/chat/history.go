@@ -0,0 +1,96 @@
+package chat
+
+import "strings"
+
+// promptHistory is the recall buffer behind up/down arrows and Ctrl-R,
+// ordered oldest first.
+type promptHistory struct {
+	entries []string
+	pos     int    // index being shown; len(entries) means the draft line
+	draft   string // what was typed before navigating away from it
+}
+
+func newPromptHistory(entries []string) *promptHistory {
+	return &promptHistory{entries: entries, pos: len(entries)}
+}
+
+// add appends a submitted prompt, skipping immediate repeats, and resets navigation.
+func (h *promptHistory) add(prompt string) {
+	if prompt != "" && (len(h.entries) == 0 || h.entries[len(h.entries)-1] != prompt) {
+		h.entries = append(h.entries, prompt)
+	}
+	h.reset()
+}
+
+func (h *promptHistory) reset() {
+	h.pos = len(h.entries)
+	h.draft = ""
+}
+
+// prev moves to the previous (older) entry. current is the line being edited,
+// kept as the draft when leaving it.
+func (h *promptHistory) prev(current string) (string, bool) {
+	if h.pos == 0 {
+		return "", false
+	}
+	if h.pos == len(h.entries) {
+		h.draft = current
+	}
+	h.pos--
+	return h.entries[h.pos], true
+}
+
+// next moves to the next (newer) entry, ending at the draft.
+func (h *promptHistory) next() (string, bool) {
+	if h.pos >= len(h.entries) {
+		return "", false
+	}
+	h.pos++
+	if h.pos == len(h.entries) {
+		return h.draft, true
+	}
+	return h.entries[h.pos], true
+}
+
+// search finds the newest entry before index from that contains query,
+// case-insensitively. It returns the match's index, or -1.
+func (h *promptHistory) search(query string, from int) int {
+	if from > len(h.entries) {
+		from = len(h.entries)
+	}
+	q := strings.ToLower(query)
+	for i := from - 1; i >= 0; i-- {
+		if strings.Contains(strings.ToLower(h.entries[i]), q) {
+			return i
+		}
+	}
+	return -1
+}
+
+// needsContinuation reports whether input spanning lines is incomplete: the
+// last line ends with a backslash, or a ``` code fence is still open.
+func needsContinuation(lines []string) bool {
+	if len(lines) == 0 {
+		return false
+	}
+	if strings.HasSuffix(lines[len(lines)-1], `\`) {
+		return true
+	}
+	fences := 0
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fences++
+		}
+	}
+	return fences%2 == 1
+}
+
+// joinLines assembles continuation lines into one prompt, dropping the
+// trailing backslashes that requested continuation.
+func joinLines(lines []string) string {
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = strings.TrimSuffix(line, `\`)
+	}
+	return strings.Join(out, "\n")
+}
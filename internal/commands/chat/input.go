@@ -0,0 +1,326 @@
+package chat
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/termcap"
+)
+
+const promptMarker = "> "
+
+// continuationMarker prompts for the next line of a multi-line prompt.
+var continuationMarker = termcap.Glyph("… ", ". ")
+
+// lineReader reads one complete prompt, which may span several lines.
+// It returns io.EOF when the user ends the session.
+type lineReader interface {
+	ReadPrompt() (string, error)
+}
+
+// newLineReader picks the interactive editor when stdin is a terminal and a
+// plain line reader otherwise (pipes, scripts). Both add the text of the
+// memories a prompt references through mentions, which may be nil.
+func newLineReader(in io.Reader, out io.Writer, history *promptHistory, mentions *memoryMentions) lineReader {
+	if f, ok := in.(*os.File); ok && isTerminal(f) {
+		return &teaReader{in: f, out: out, history: history, mentions: mentions}
+	}
+	return &plainReader{scanner: bufio.NewScanner(in), history: history, mentions: mentions}
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// plainReader reads prompts from a non-interactive stream, honouring the same
+// continuation rules as the editor.
+type plainReader struct {
+	scanner  *bufio.Scanner
+	history  *promptHistory
+	mentions *memoryMentions
+}
+
+func (r *plainReader) ReadPrompt() (string, error) {
+	var lines []string
+	for r.scanner.Scan() {
+		lines = append(lines, r.scanner.Text())
+		if !needsContinuation(lines) {
+			prompt := joinLines(lines)
+			r.history.add(strings.TrimSpace(prompt))
+			return r.mentions.expand(prompt), nil
+		}
+	}
+	if err := r.scanner.Err(); err != nil {
+		return "", err
+	}
+	if len(lines) > 0 {
+		return r.mentions.expand(joinLines(lines)), nil
+	}
+	return "", io.EOF
+}
+
+// teaReader runs a short-lived Bubble Tea program per prompt so the answer can
+// stream to the terminal normally in between.
+type teaReader struct {
+	in       *os.File
+	out      io.Writer
+	history  *promptHistory
+	mentions *memoryMentions
+}
+
+func (r *teaReader) ReadPrompt() (string, error) {
+	final, err := tea.NewProgram(newPromptModel(r.history, r.mentions), tea.WithInput(r.in), tea.WithOutput(r.out)).Run()
+	if err != nil {
+		return "", err
+	}
+
+	m := final.(promptModel)
+	if m.eof {
+		return "", io.EOF
+	}
+	prompt := joinLines(m.lines)
+	r.history.add(strings.TrimSpace(prompt))
+	return r.mentions.expand(prompt), nil
+}
+
+// promptModel is a single-prompt line editor: readline-style editing keys from
+// textinput, up/down history, Ctrl-R reverse search, continuation lines, and
+// "@" completion of memories.
+type promptModel struct {
+	input   textinput.Model
+	history *promptHistory
+	lines   []string // completed lines of a multi-line prompt
+
+	mentions  *memoryMentions
+	matches   []memtypes.MemoryItem // memories offered for the "@" being typed
+	matchIdx  int
+	dismissed int // where the "@" whose matches Esc closed starts, or -1
+
+	searching   bool
+	searchQuery string
+	searchIdx   int
+	saved       string // input before the search started
+
+	done bool
+	eof  bool
+}
+
+func newPromptModel(history *promptHistory, mentions *memoryMentions) promptModel {
+	ti := textinput.New()
+	ti.Prompt = promptMarker
+	ti.Focus()
+	return promptModel{input: ti, history: history, mentions: mentions, searchIdx: -1, dismissed: -1}
+}
+
+func (m promptModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m promptModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+
+	if m.searching {
+		return m.updateSearch(key)
+	}
+	if len(m.matches) > 0 {
+		if next, ok := m.updateMention(key); ok {
+			return next, nil
+		}
+	}
+
+	switch key.Type {
+	case tea.KeyCtrlD:
+		if m.input.Value() == "" && len(m.lines) == 0 {
+			m.eof = true
+			return m, tea.Quit
+		}
+	case tea.KeyCtrlC:
+		// Ctrl-C discards the current prompt; on an empty prompt it exits.
+		if m.input.Value() == "" && len(m.lines) == 0 {
+			m.eof = true
+			return m, tea.Quit
+		}
+		m.lines = nil
+		m.input.Reset()
+		m.input.Prompt = promptMarker
+		m.history.reset()
+		return m, nil
+	case tea.KeyEnter:
+		m.lines = append(m.lines, m.input.Value())
+		m.input.Reset()
+		m.history.reset()
+		if needsContinuation(m.lines) {
+			m.input.Prompt = continuationMarker
+			return m, nil
+		}
+		m.done = true
+		return m, tea.Quit
+	case tea.KeyUp, tea.KeyCtrlP:
+		if entry, ok := m.history.prev(m.input.Value()); ok {
+			m.setInput(entry)
+		}
+		return m, nil
+	case tea.KeyDown, tea.KeyCtrlN:
+		if entry, ok := m.history.next(); ok {
+			m.setInput(entry)
+		}
+		return m, nil
+	case tea.KeyCtrlR:
+		m.searching = true
+		m.searchQuery = ""
+		m.searchIdx = -1
+		m.saved = m.input.Value()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	m.completeMention()
+	return m, cmd
+}
+
+// updateMention handles keys while memories are offered for an "@": Up/Down
+// pick one, Enter inserts a reference to it, Tab its text, and Esc closes the
+// list. Other keys are reported as not handled, to edit the input.
+func (m promptModel) updateMention(key tea.KeyMsg) (promptModel, bool) {
+	switch key.Type {
+	case tea.KeyUp, tea.KeyCtrlP:
+		m.matchIdx = (m.matchIdx - 1 + len(m.matches)) % len(m.matches)
+	case tea.KeyDown, tea.KeyCtrlN:
+		m.matchIdx = (m.matchIdx + 1) % len(m.matches)
+	case tea.KeyEnter:
+		m.insertMention(mentionRef(m.matches[m.matchIdx]))
+	case tea.KeyTab:
+		m.insertMention(strings.Join(strings.Fields(m.matches[m.matchIdx].Text), " "))
+	case tea.KeyEsc:
+		m.dismissed, _, _ = mentionQuery([]rune(m.input.Value()), m.input.Position())
+		m.matches = nil
+	default:
+		return m, false
+	}
+	return m, true
+}
+
+// completeMention offers the memories matching the "@" word at the cursor.
+func (m *promptModel) completeMention() {
+	m.matches = nil
+	start, query, ok := mentionQuery([]rune(m.input.Value()), m.input.Position())
+	if !ok {
+		m.dismissed = -1
+		return
+	}
+	if start == m.dismissed {
+		return
+	}
+	m.matches = m.mentions.search(query)
+	m.matchIdx = min(m.matchIdx, max(len(m.matches)-1, 0))
+}
+
+// insertMention replaces the "@" word at the cursor with text.
+func (m *promptModel) insertMention(text string) {
+	value := []rune(m.input.Value())
+	pos := m.input.Position()
+	start, _, _ := mentionQuery(value, pos)
+	next := string(value[:start]) + text + " "
+	m.input.SetValue(next + string(value[pos:]))
+	m.input.SetCursor(len([]rune(next)))
+	m.matches = nil
+	m.matchIdx = 0
+}
+
+// updateSearch handles keys during Ctrl-R: typing narrows the search, Ctrl-R
+// again finds an older match, Enter accepts the match for editing, and Esc or
+// Ctrl-G restores the original input.
+func (m promptModel) updateSearch(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key.Type {
+	case tea.KeyCtrlR:
+		from := m.searchIdx
+		if from < 0 {
+			from = len(m.history.entries)
+		}
+		if idx := m.history.search(m.searchQuery, from); idx >= 0 {
+			m.searchIdx = idx
+		}
+	case tea.KeyEsc, tea.KeyCtrlG, tea.KeyCtrlC:
+		m.searching = false
+		m.setInput(m.saved)
+	case tea.KeyEnter, tea.KeyLeft, tea.KeyRight:
+		m.searching = false
+		if m.searchIdx >= 0 {
+			m.setInput(m.history.entries[m.searchIdx])
+		}
+	case tea.KeyBackspace:
+		if r := []rune(m.searchQuery); len(r) > 0 {
+			m.searchQuery = string(r[:len(r)-1])
+			m.searchIdx = m.history.search(m.searchQuery, len(m.history.entries))
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.searchQuery += string(key.Runes)
+		m.searchIdx = m.history.search(m.searchQuery, len(m.history.entries))
+	}
+	return m, nil
+}
+
+func (m *promptModel) setInput(value string) {
+	m.input.SetValue(value)
+	m.input.CursorEnd()
+}
+
+func (m promptModel) View() string {
+	var sb strings.Builder
+	for i, line := range m.lines {
+		if i == 0 {
+			sb.WriteString(promptMarker)
+		} else {
+			sb.WriteString(continuationMarker)
+		}
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+
+	if m.done || m.eof {
+		return sb.String()
+	}
+
+	if m.searching {
+		match := ""
+		if m.searchIdx >= 0 {
+			match = m.history.entries[m.searchIdx]
+		}
+		sb.WriteString("(reverse-i-search)`" + m.searchQuery + "': " + match)
+		return sb.String()
+	}
+
+	sb.WriteString(m.input.View())
+	for i, item := range m.matches {
+		marker := "  "
+		if i == m.matchIdx {
+			marker = promptMarker
+		}
+		sb.WriteString("\n" + marker + strings.TrimPrefix(mentionRef(item), mentionPrefix) + "  " + clip(strings.Join(strings.Fields(item.Text), " "), mentionWidth))
+	}
+	return sb.String()
+}
+
+// mentionWidth is how much of a memory's text the "@" list shows.
+const mentionWidth = 60
+
+// clip shortens s to width runes, ending it with an ellipsis when cut.
+func clip(s string, width int) string {
+	if r := []rune(s); len(r) > width {
+		return string(r[:width-1]) + termcap.Glyph("…", "~")
+	}
+	return s
+}
@@ -0,0 +1,134 @@
+package chat
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sahilm/fuzzy"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/trace"
+)
+
+const (
+	// mentionPrefix starts a reference to a memory in a prompt, followed by
+	// the first mentionIDLength characters of its ID.
+	mentionPrefix   = "@mem:"
+	mentionIDLength = 8
+	// mentionLimit is how many matching memories "@" completion offers.
+	mentionLimit = 5
+)
+
+var mentionPattern = regexp.MustCompile(regexp.QuoteMeta(mentionPrefix) + `([0-9A-Za-z-]{` + fmt.Sprint(mentionIDLength) + `})`)
+
+// memoryMentions offers memories for "@" completion in the prompt editor and
+// adds the text of the memories a prompt references to it. It loads the
+// memories on first use. A nil *memoryMentions offers and expands nothing.
+type memoryMentions struct {
+	load     func() ([]memtypes.MemoryItem, error)
+	loaded   bool
+	memories []memtypes.MemoryItem
+}
+
+func newMemoryMentions(load func() ([]memtypes.MemoryItem, error)) *memoryMentions {
+	return &memoryMentions{load: load}
+}
+
+// all returns the memories that can be referenced: those retrieval would
+// return. Completion is a convenience, so a failed load only traces.
+func (m *memoryMentions) all() []memtypes.MemoryItem {
+	if m.loaded {
+		return m.memories
+	}
+	m.loaded = true
+	memories, err := m.load()
+	if err != nil {
+		trace.Printf(trace.Info, "memory completion: %v", err)
+		return nil
+	}
+	for _, item := range memories {
+		if !item.Suppressed && !item.PendingReview {
+			m.memories = append(m.memories, item)
+		}
+	}
+	return m.memories
+}
+
+// search returns up to mentionLimit memories whose text fuzzy-matches query,
+// best first, or the newest ones for an empty query.
+func (m *memoryMentions) search(query string) []memtypes.MemoryItem {
+	if m == nil {
+		return nil
+	}
+	memories := m.all()
+	if query == "" {
+		return memories[:min(len(memories), mentionLimit)]
+	}
+	matches := fuzzy.FindFrom(query, mentionSource(memories))
+	results := make([]memtypes.MemoryItem, 0, min(len(matches), mentionLimit))
+	for _, match := range matches[:min(len(matches), mentionLimit)] {
+		results = append(results, memories[match.Index])
+	}
+	return results
+}
+
+// expand appends the text of the memories prompt references, so the model
+// sees what each reference stands for. Unknown references are left alone.
+func (m *memoryMentions) expand(prompt string) string {
+	if m == nil || !strings.Contains(prompt, mentionPrefix) {
+		return prompt
+	}
+	var sb strings.Builder
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(prompt, -1) {
+		if seen[match[1]] {
+			continue
+		}
+		seen[match[1]] = true
+		for _, item := range m.all() {
+			if strings.HasPrefix(item.ID, match[1]) {
+				fmt.Fprintf(&sb, "\n%s: %s", match[0], item.Text)
+				break
+			}
+		}
+	}
+	if sb.Len() == 0 {
+		return prompt
+	}
+	return prompt + "\n\nMemories referenced above:" + sb.String()
+}
+
+// mentionRef returns the reference to item that completion inserts.
+func mentionRef(item memtypes.MemoryItem) string {
+	id := item.ID
+	if len(id) > mentionIDLength {
+		id = id[:mentionIDLength]
+	}
+	return mentionPrefix + id
+}
+
+// mentionSource lets fuzzy search memory texts.
+type mentionSource []memtypes.MemoryItem
+
+func (s mentionSource) String(i int) string { return s[i].Text }
+func (s mentionSource) Len() int            { return len(s) }
+
+// mentionQuery returns where the "@" being completed starts in value, as a
+// rune index, and what was typed after it, when the cursor at pos ends an
+// "@" word that is not a finished reference.
+func mentionQuery(value []rune, pos int) (start int, query string, ok bool) {
+	for i := pos - 1; i >= 0; i-- {
+		switch r := value[i]; {
+		case r == ' ' || r == '\t':
+			return 0, "", false
+		case r == '@' && (i == 0 || value[i-1] == ' ' || value[i-1] == '\t'):
+			query = string(value[i+1 : pos])
+			if strings.HasPrefix(query, strings.TrimPrefix(mentionPrefix, "@")) {
+				return 0, "", false
+			}
+			return i, query, true
+		}
+	}
+	return 0, "", false
+}
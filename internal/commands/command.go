@@ -1,13 +1,53 @@
 package commands
 
 import (
+	chatcmd "github.com/austiecodes/gomor/internal/commands/chat"
+	comparecmd "github.com/austiecodes/gomor/internal/commands/compare"
+	configcmd "github.com/austiecodes/gomor/internal/commands/configs"
+	digestcmd "github.com/austiecodes/gomor/internal/commands/digest"
+	doctorcmd "github.com/austiecodes/gomor/internal/commands/doctor"
+	evalcmd "github.com/austiecodes/gomor/internal/commands/eval"
+	exportcmd "github.com/austiecodes/gomor/internal/commands/export"
+	historycmd "github.com/austiecodes/gomor/internal/commands/history"
+	importcmd "github.com/austiecodes/gomor/internal/commands/imports"
+	ingestcmd "github.com/austiecodes/gomor/internal/commands/ingest"
+	initcmd "github.com/austiecodes/gomor/internal/commands/inits"
 	mcpcmd "github.com/austiecodes/gomor/internal/commands/mcp"
 	memorycmd "github.com/austiecodes/gomor/internal/commands/memory"
+	plugincmd "github.com/austiecodes/gomor/internal/commands/plugins"
+	remembercmd "github.com/austiecodes/gomor/internal/commands/remember"
+	retrycmd "github.com/austiecodes/gomor/internal/commands/retry"
+	searchcmd "github.com/austiecodes/gomor/internal/commands/search"
 	setcmd "github.com/austiecodes/gomor/internal/commands/set"
+	shellwidgetcmd "github.com/austiecodes/gomor/internal/commands/shellwidget"
+	statscmd "github.com/austiecodes/gomor/internal/commands/stats"
+	stdinfiltercmd "github.com/austiecodes/gomor/internal/commands/stdinfilter"
+	synccmd "github.com/austiecodes/gomor/internal/commands/syncs"
+	tunecmd "github.com/austiecodes/gomor/internal/commands/tune"
 )
 
 func init() {
+	rootCmd.AddCommand(chatcmd.ChatCmd)
+	rootCmd.AddCommand(comparecmd.CompareCmd)
+	rootCmd.AddCommand(configcmd.ConfigCmd)
+	rootCmd.AddCommand(digestcmd.DigestCmd)
+	rootCmd.AddCommand(doctorcmd.DoctorCmd)
+	rootCmd.AddCommand(evalcmd.EvalCmd)
+	rootCmd.AddCommand(exportcmd.ExportCmd)
+	rootCmd.AddCommand(historycmd.HistoryCmd)
+	rootCmd.AddCommand(importcmd.ImportCmd)
+	rootCmd.AddCommand(initcmd.InitCmd)
+	rootCmd.AddCommand(ingestcmd.IngestCmd)
 	rootCmd.AddCommand(mcpcmd.McpCmd)
 	rootCmd.AddCommand(memorycmd.MemoryCmd)
+	rootCmd.AddCommand(plugincmd.PluginCmd)
+	rootCmd.AddCommand(remembercmd.RememberCmd)
+	rootCmd.AddCommand(retrycmd.RetryCmd)
+	rootCmd.AddCommand(searchcmd.SearchCmd)
 	rootCmd.AddCommand(setcmd.SetCmd)
+	rootCmd.AddCommand(shellwidgetcmd.ShellWidgetCmd)
+	rootCmd.AddCommand(statscmd.StatsCmd)
+	rootCmd.AddCommand(stdinfiltercmd.StdinFilterCmd)
+	rootCmd.AddCommand(synccmd.SyncCmd)
+	rootCmd.AddCommand(tunecmd.TuneCmd)
 }
@@ -0,0 +1,365 @@
+package compare
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/chat"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/moderation"
+	"github.com/austiecodes/gomor/internal/pricing"
+	"github.com/austiecodes/gomor/internal/provider"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+// Layouts for the answers.
+const (
+	layoutSide = "side"
+	layoutList = "list"
+)
+
+const (
+	defaultWidth   = 120
+	minColumnWidth = 30
+	columnGap      = 3
+)
+
+type compareCommandOptions struct {
+	models        []string
+	layout        string
+	width         int
+	temperature   float64
+	maxTokens     int
+	seed          int64
+	enforceBudget bool
+	jsonOutput    bool
+}
+
+// answer is one model's reply to the compared prompt.
+type answer struct {
+	Model    types.Model
+	Text     string
+	Err      error
+	Duration time.Duration
+}
+
+type answerOutput struct {
+	Model      string `json:"model"`
+	Answer     string `json:"answer,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+type compareOutput struct {
+	SessionID string         `json:"session_id,omitempty"`
+	Prompt    string         `json:"prompt"`
+	Answers   []answerOutput `json:"answers"`
+}
+
+var loadConfigFn = utils.LoadConfig
+
+// askFn sends prompt to model alone, without falling back to other models,
+// and returns the answer. Spend is charged to budget.
+var askFn = func(ctx context.Context, config *utils.Config, model types.Model, prompt string, budget *pricing.Budget) (string, error) {
+	queryClient, err := provider.NewQueryClient(config, model.Provider)
+	if err != nil {
+		return "", fmt.Errorf("failed to create chat client: %w", err)
+	}
+	return chat.Ask(ctx, queryClient, model, prompt, budget, io.Discard)
+}
+
+var CompareCmd = newCompareCommand()
+
+func newCompareCommand() *cobra.Command {
+	opts := &compareCommandOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "compare --models <a,b,...> <prompt>",
+		Short: "Ask several models the same prompt and compare the answers",
+		Long: `Send a prompt to several models at once and show their answers side by
+side, or one after another with --layout list. Each model is a provider/model
+ID, such as openai/gpt-4o, an alias from model.aliases in the config, or a
+model role: chat, title, think, or tool. Models answer on their own, without
+fallbacks.
+
+The prompt and every answer are saved to history as one session, the answers
+as siblings labeled with their model, so they can be revisited later:
+  gomor history show <session>
+  gomor history export <session> --format json`,
+		Example:      `  gomor compare --models openai/gpt-4o,anthropic/claude-sonnet-4-5,chat "explain CRDTs in two sentences"`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCompareCommand(cmd, opts, strings.Join(args, " "))
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&opts.models, "models", nil, "comma-separated models to compare: provider/model IDs, aliases, or roles (chat, title, think, tool)")
+	cmd.Flags().StringVar(&opts.layout, "layout", layoutSide, "how to show the answers: side (columns) or list (one after another)")
+	cmd.Flags().IntVar(&opts.width, "width", 0, "total width of the side-by-side layout (default: $COLUMNS, or 120)")
+	cmd.Flags().Float64Var(&opts.temperature, "temperature", 0, "sampling temperature for every model")
+	cmd.Flags().IntVar(&opts.maxTokens, "max-tokens", 0, "maximum answer length in tokens for every model")
+	cmd.Flags().Int64Var(&opts.seed, "seed", 0, "sampling seed for every model (not supported by anthropic)")
+	cmd.Flags().BoolVar(&opts.enforceBudget, "enforce-budget", false, "refuse to send the prompt when the budget is used up, instead of warning")
+	cmd.Flags().BoolVar(&opts.jsonOutput, "json", false, "emit structured JSON output")
+	_ = cmd.MarkFlagRequired("models")
+
+	return cmd
+}
+
+func runCompareCommand(cmd *cobra.Command, opts *compareCommandOptions, prompt string) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		return fmt.Errorf("prompt must be a non-empty string")
+	}
+	if opts.layout != layoutSide && opts.layout != layoutList {
+		return fmt.Errorf("invalid --layout %q (valid: side, list)", opts.layout)
+	}
+	if opts.maxTokens < 0 {
+		return fmt.Errorf("--max-tokens must be positive")
+	}
+
+	config, err := loadConfigFn()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	models, err := resolveModels(config.Model, opts.models)
+	if err != nil {
+		return err
+	}
+	for i := range models {
+		if cmd.Flags().Changed("temperature") {
+			models[i].Temperature = &opts.temperature
+		}
+		if opts.maxTokens > 0 {
+			models[i].MaxTokens = opts.maxTokens
+		}
+		if cmd.Flags().Changed("seed") {
+			models[i].Seed = &opts.seed
+		}
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		return fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	errOut := cmd.ErrOrStderr()
+	answers := askAll(ctx, config, memStore, models, prompt, opts.enforceBudget, errOut)
+
+	sessionID, err := record(ctx, config, memStore, prompt, answers)
+	if err != nil {
+		fmt.Fprintf(errOut, "Warning: failed to save the answers to history: %v\n", err)
+	}
+
+	out := cmd.OutOrStdout()
+	if opts.jsonOutput {
+		if err := writeJSON(out, sessionID, prompt, answers); err != nil {
+			return err
+		}
+	} else {
+		width := opts.width
+		if width <= 0 {
+			width = terminalWidth()
+		}
+		if opts.layout == layoutSide && len(answers) > 1 && (width-columnGap*(len(answers)-1))/len(answers) >= minColumnWidth {
+			fmt.Fprintln(out, renderColumns(answers, width))
+		} else {
+			renderList(out, answers)
+		}
+		if sessionID != "" {
+			fmt.Fprintf(errOut, "Saved as session %s; see it again with 'gomor history show %s'.\n", sessionID, sessionID)
+		}
+	}
+
+	for _, a := range answers {
+		if a.Err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("every model failed to answer")
+}
+
+// resolveModels parses each ref as a provider/model ID or a model role. A
+// model named twice is compared once.
+func resolveModels(config utils.ModelConfig, refs []string) ([]types.Model, error) {
+	var models []types.Model
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		ref = strings.TrimSpace(ref)
+		if ref == "" {
+			continue
+		}
+		model, err := config.ResolveModel(ref)
+		if err != nil {
+			return nil, err
+		}
+		key := model.Provider + "/" + model.ModelID
+		if !seen[key] {
+			seen[key] = true
+			models = append(models, model)
+		}
+	}
+	if len(models) < 2 {
+		return nil, fmt.Errorf("compare needs at least two different models, got %d", len(models))
+	}
+	return models, nil
+}
+
+// askAll asks every model concurrently and returns the answers in the order
+// of models.
+func askAll(ctx context.Context, config *utils.Config, memStore *store.Store, models []types.Model, prompt string, enforceBudget bool, errOut io.Writer) []answer {
+	// Budget warnings may come from several models at once.
+	warnOut := &lockedWriter{w: errOut}
+	answers := make([]answer, len(models))
+	var wg sync.WaitGroup
+	for i, model := range models {
+		wg.Add(1)
+		go func(i int, model types.Model) {
+			defer wg.Done()
+			budget := pricing.NewBudget(memStore, config.Budget, enforceBudget, warnOut)
+			start := time.Now()
+			text, err := askFn(ctx, config, model, prompt, budget)
+			answers[i] = answer{Model: model, Text: strings.TrimSpace(text), Err: err, Duration: time.Since(start)}
+		}(i, model)
+	}
+	wg.Wait()
+	return answers
+}
+
+// record saves prompt and the answers that succeeded as a new session, the
+// answers as siblings replying to the prompt. It returns the session ID, or
+// "" when no model answered.
+func record(ctx context.Context, config *utils.Config, memStore *store.Store, prompt string, answers []answer) (string, error) {
+	var answered []answer
+	for _, a := range answers {
+		if a.Err == nil {
+			answered = append(answered, a)
+		}
+	}
+	if len(answered) == 0 {
+		return "", nil
+	}
+
+	moderator, err := moderation.FromConfig(config)
+	if err != nil {
+		return "", err
+	}
+	sessionID := uuid.New().String()
+	if err := memStore.EnsureSession(sessionID); err != nil {
+		return "", err
+	}
+	turn := memtypes.HistoryItem{Role: "user", Content: prompt, SessionID: sessionID}
+	if err := service.SaveTurn(ctx, memStore, moderator, &turn); err != nil {
+		return "", err
+	}
+	for _, a := range answered {
+		reply := memtypes.HistoryItem{
+			Role:      "assistant",
+			Content:   a.Text,
+			SessionID: sessionID,
+			ParentID:  turn.ID,
+			Model:     service.TurnModel(a.Model),
+		}
+		if err := service.SaveTurn(ctx, memStore, moderator, &reply); err != nil {
+			return "", err
+		}
+	}
+	return sessionID, nil
+}
+
+func writeJSON(out io.Writer, sessionID, prompt string, answers []answer) error {
+	result := compareOutput{SessionID: sessionID, Prompt: prompt, Answers: make([]answerOutput, len(answers))}
+	for i, a := range answers {
+		result.Answers[i] = answerOutput{
+			Model:      a.Model.Provider + "/" + a.Model.ModelID,
+			Answer:     a.Text,
+			DurationMs: a.Duration.Milliseconds(),
+		}
+		if a.Err != nil {
+			result.Answers[i].Error = a.Err.Error()
+		}
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// label names the model that gave a and how long it took.
+func label(a answer) string {
+	return fmt.Sprintf("%s/%s (%s)", a.Model.Provider, a.Model.ModelID, a.Duration.Round(100*time.Millisecond))
+}
+
+// body is a's answer, or its error.
+func body(a answer) string {
+	if a.Err != nil {
+		return "Error: " + a.Err.Error()
+	}
+	return a.Text
+}
+
+// renderList prints the answers one after another under their labels.
+func renderList(out io.Writer, answers []answer) {
+	for i, a := range answers {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "== %s ==\n%s\n", label(a), body(a))
+	}
+}
+
+// renderColumns lays the answers out side by side, each wrapped to its share
+// of width.
+func renderColumns(answers []answer, width int) string {
+	columnWidth := (width - columnGap*(len(answers)-1)) / len(answers)
+	column := lipgloss.NewStyle().Width(columnWidth)
+	header := column.Bold(true).BorderStyle(lipgloss.NormalBorder()).BorderBottom(true)
+	gap := strings.Repeat(" ", columnGap)
+
+	columns := make([]string, 0, 2*len(answers)-1)
+	for i, a := range answers {
+		if i > 0 {
+			columns = append(columns, gap)
+		}
+		columns = append(columns, lipgloss.JoinVertical(lipgloss.Left, header.Render(label(a)), column.Render(body(a))))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, columns...)
+}
+
+// terminalWidth reads the width of the terminal from $COLUMNS.
+func terminalWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return defaultWidth
+}
+
+// lockedWriter serializes writes from concurrent requests.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
@@ -0,0 +1,148 @@
+package compare
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/pricing"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// setup fakes the config and the models, which answer with answers keyed by
+// "provider/model" or fail when they have none. History goes to a store under
+// a temporary home, which is returned.
+func setup(t *testing.T, answers map[string]string) *store.Store {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	oldLoad, oldAsk := loadConfigFn, askFn
+	t.Cleanup(func() { loadConfigFn, askFn = oldLoad, oldAsk })
+
+	loadConfigFn = func() (*utils.Config, error) {
+		config := utils.DefaultConfig()
+		config.Model.ChatModel = &types.Model{Provider: "openai", ModelID: "gpt-4o"}
+		return config, nil
+	}
+	askFn = func(ctx context.Context, config *utils.Config, model types.Model, prompt string, budget *pricing.Budget) (string, error) {
+		if answer, ok := answers[model.Provider+"/"+model.ModelID]; ok {
+			return answer + " (" + prompt + ")", nil
+		}
+		return "", errors.New("model unavailable")
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { _ = memStore.Close() })
+	return memStore
+}
+
+func execute(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := newCompareCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestCompareStoresEveryAnswerAsSiblings(t *testing.T) {
+	memStore := setup(t, map[string]string{"openai/gpt-4o": "A", "google/gemini-2.5-flash": "B"})
+
+	out, err := execute(t, "--models", "chat,google/gemini-2.5-flash,anthropic/claude", "--json", "what", "is", "a", "CRDT?")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	var result compareOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(result.Answers) != 3 {
+		t.Fatalf("expected 3 answers, got %+v", result.Answers)
+	}
+	if result.Answers[0].Model != "openai/gpt-4o" || result.Answers[0].Answer != "A (what is a CRDT?)" {
+		t.Fatalf("answers should keep the order of --models: %+v", result.Answers)
+	}
+	if result.Answers[2].Error != "model unavailable" {
+		t.Fatalf("a failed model should report its error: %+v", result.Answers[2])
+	}
+
+	history, err := memStore.GetSessionHistory(result.SessionID, 10)
+	if err != nil {
+		t.Fatalf("session history: %v", err)
+	}
+	if len(history) != 3 || history[0].Role != "user" || history[0].Content != "what is a CRDT?" {
+		t.Fatalf("expected the prompt and two answers, got %+v", history)
+	}
+	for _, turn := range history[1:] {
+		if turn.Role != "assistant" || turn.ParentID != history[0].ID || turn.Model == "" {
+			t.Fatalf("answers should be siblings labeled with their model, got %+v", turn)
+		}
+	}
+}
+
+func TestCompareLayouts(t *testing.T) {
+	setup(t, map[string]string{"openai/gpt-4o": "first answer", "google/gemini": "second answer"})
+
+	out, err := execute(t, "--models", "openai/gpt-4o,google/gemini", "--width", "80", "hi")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	lines := strings.Split(out, "\n")
+	if !strings.Contains(lines[0], "openai/gpt-4o") || !strings.Contains(lines[0], "google/gemini") {
+		t.Fatalf("side by side should put both labels on the first line:\n%s", out)
+	}
+
+	out, err = execute(t, "--models", "openai/gpt-4o,google/gemini", "--layout", "list", "hi")
+	if err != nil {
+		t.Fatalf("execute --layout list: %v", err)
+	}
+	if !strings.HasPrefix(out, "== openai/gpt-4o (") || !strings.Contains(out, "first answer (hi)\n\n== google/gemini (") {
+		t.Fatalf("unexpected list layout:\n%s", out)
+	}
+}
+
+func TestCompareFailsWhenEveryModelFails(t *testing.T) {
+	setup(t, nil)
+
+	if _, err := execute(t, "--models", "openai/a,openai/b", "hi"); err == nil || !strings.Contains(err.Error(), "every model failed") {
+		t.Fatalf("expected every model to fail, got %v", err)
+	}
+}
+
+func TestResolveModels(t *testing.T) {
+	config := utils.ModelConfig{
+		ChatModel: &types.Model{Provider: "openai", ModelID: "gpt-4o"},
+		Aliases:   map[string]string{"default": "chat", "fast": "openai/gpt-4o-mini", "broken": "gpt-4o"},
+	}
+
+	models, err := resolveModels(config, []string{"chat", "openai/gpt-4o", "default", "openrouter/meta/llama-3", "fast"})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(models) != 3 || models[1].Provider != "openrouter" || models[1].ModelID != "meta/llama-3" || models[2].ModelID != "gpt-4o-mini" {
+		t.Fatalf("expected duplicates dropped, slashes kept in model IDs, and aliases resolved, got %+v", models)
+	}
+
+	if _, err := resolveModels(config, []string{"chat", "broken"}); err == nil || !strings.Contains(err.Error(), `alias "broken"`) {
+		t.Fatalf("expected an error naming the bad alias, got %v", err)
+	}
+
+	if _, err := resolveModels(config, []string{"chat", "think"}); err == nil {
+		t.Fatal("expected an error for a role without a configured model")
+	}
+	if _, err := resolveModels(config, []string{"chat"}); err == nil {
+		t.Fatal("expected an error for a single model")
+	}
+}
@@ -0,0 +1,184 @@
+package configs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/austiecodes/gomor/internal/bundle"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportBundleFn = bundle.Export
+	importBundleFn = bundle.Import
+)
+
+type exportCommandOptions struct {
+	includeKeys bool
+	includeDB   bool
+	jsonOutput  bool
+}
+
+type importCommandOptions struct {
+	force      bool
+	jsonOutput bool
+}
+
+type bundleOutput struct {
+	Message            string `json:"message"`
+	Path               string `json:"path"`
+	Secrets            bool   `json:"secrets"`
+	Database           bool   `json:"database"`
+	SettingsBackupPath string `json:"settings_backup_path,omitempty"`
+	DatabaseBackupPath string `json:"database_backup_path,omitempty"`
+}
+
+var ConfigCmd = newConfigCommand()
+
+func newConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Move gomor's setup between machines",
+	}
+	cmd.AddCommand(newExportCommand())
+	cmd.AddCommand(newImportCommand())
+	return cmd
+}
+
+func newExportCommand() *cobra.Command {
+	opts := &exportCommandOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "export <bundle.tgz>",
+		Short: "Pack the config into a bundle",
+		Long: `Write the config to a gzipped tar bundle for 'gomor config import' on
+another machine. The config carries every setting, including the chat system
+prompt, the embedding template, and the tag vocabulary and aliases.
+
+API keys, the SMTP password, the MCP HTTP token, and the environments of MCP
+servers are left out unless --include-keys is set. --include-db adds a consistent copy of the memory
+database, with its memories, history, and embeddings. The bundle is written
+readable by you only.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runExportCommand(cmd, args[0], opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.includeKeys, "include-keys", false, "keep API keys and passwords in the bundle")
+	cmd.Flags().BoolVar(&opts.includeDB, "include-db", false, "add the memory database to the bundle")
+	cmd.Flags().BoolVar(&opts.jsonOutput, "json", false, "emit structured JSON output")
+
+	return cmd
+}
+
+func runExportCommand(cmd *cobra.Command, path string, opts *exportCommandOptions) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	manifest, err := exportBundleFn(f, bundle.ExportOptions{Secrets: opts.includeKeys, Database: opts.includeDB})
+	if cerr := f.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("failed to write bundle: %w", cerr)
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	output := bundleOutput{
+		Message:  fmt.Sprintf("Exported %s to %s", describe(*manifest), path),
+		Path:     path,
+		Secrets:  manifest.Secrets,
+		Database: manifest.Database,
+	}
+	out := cmd.OutOrStdout()
+	if opts.jsonOutput {
+		return writeJSON(out, output)
+	}
+	_, err = fmt.Fprintln(out, output.Message)
+	return err
+}
+
+func newImportCommand() *cobra.Command {
+	opts := &importCommandOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "import <bundle.tgz>",
+		Short: "Set up gomor from a bundle",
+		Long: `Restore the config, and the memory database if the bundle has one, from a
+bundle written by 'gomor config export'.
+
+An existing config or database is only replaced with --force, and is backed
+up to ~/.gomor/backups first. Keys left out of the bundle keep their values
+from the replaced config, so importing a shared bundle does not log you out.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runImportCommand(cmd, args[0], opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.force, "force", false, "replace an existing config and database")
+	cmd.Flags().BoolVar(&opts.jsonOutput, "json", false, "emit structured JSON output")
+
+	return cmd
+}
+
+func runImportCommand(cmd *cobra.Command, path string, opts *importCommandOptions) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer f.Close()
+
+	result, err := importBundleFn(f, bundle.ImportOptions{Force: opts.force})
+	if err != nil {
+		return err
+	}
+
+	output := bundleOutput{
+		Message:            fmt.Sprintf("Imported %s from %s", describe(result.Manifest), path),
+		Path:               path,
+		Secrets:            result.Manifest.Secrets,
+		Database:           result.Manifest.Database,
+		SettingsBackupPath: result.SettingsBackupPath,
+		DatabaseBackupPath: result.DatabaseBackupPath,
+	}
+	out := cmd.OutOrStdout()
+	if opts.jsonOutput {
+		return writeJSON(out, output)
+	}
+	if _, err := fmt.Fprintln(out, output.Message); err != nil {
+		return err
+	}
+	for _, backup := range []string{result.SettingsBackupPath, result.DatabaseBackupPath} {
+		if backup != "" {
+			if _, err := fmt.Fprintf(out, "Backed up the replaced file to %s\n", backup); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// describe names what a bundle holds.
+func describe(manifest bundle.Manifest) string {
+	what := "the config"
+	if manifest.Secrets {
+		what += " with keys"
+	}
+	if manifest.Database {
+		what += " and the memory database"
+	}
+	return what
+}
+
+func writeJSON(out io.Writer, value any) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
@@ -0,0 +1,96 @@
+package configs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/bundle"
+)
+
+func TestConfigExportCommand(t *testing.T) {
+	oldExport := exportBundleFn
+	defer func() { exportBundleFn = oldExport }()
+
+	var gotOpts bundle.ExportOptions
+	exportBundleFn = func(w io.Writer, opts bundle.ExportOptions) (*bundle.Manifest, error) {
+		gotOpts = opts
+		_, err := w.Write([]byte("bundle"))
+		return &bundle.Manifest{Version: bundle.Version, Database: opts.Database}, err
+	}
+
+	path := filepath.Join(t.TempDir(), "setup.tgz")
+	cmd := newConfigCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"export", path, "--include-db"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if gotOpts.Secrets || !gotOpts.Database {
+		t.Fatalf("unexpected options: %+v", gotOpts)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "bundle" {
+		t.Fatalf("expected the bundle to be written, got %q (%v)", data, err)
+	}
+	if !strings.Contains(out.String(), "the config and the memory database") {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+
+	exportBundleFn = func(w io.Writer, opts bundle.ExportOptions) (*bundle.Manifest, error) {
+		return nil, errors.New("no config")
+	}
+	cmd = newConfigCommand()
+	cmd.SetOut(&out)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"export", path})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected the export error")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected a failed export to leave no bundle, got %v", err)
+	}
+}
+
+func TestConfigImportCommandJSONOutput(t *testing.T) {
+	oldImport := importBundleFn
+	defer func() { importBundleFn = oldImport }()
+
+	var gotOpts bundle.ImportOptions
+	importBundleFn = func(r io.Reader, opts bundle.ImportOptions) (*bundle.ImportResult, error) {
+		gotOpts = opts
+		return &bundle.ImportResult{
+			Manifest:           bundle.Manifest{Version: bundle.Version},
+			SettingsBackupPath: "/backups/settings.json",
+		}, nil
+	}
+
+	path := filepath.Join(t.TempDir(), "setup.tgz")
+	if err := os.WriteFile(path, []byte("bundle"), 0600); err != nil {
+		t.Fatalf("write bundle: %v", err)
+	}
+	cmd := newConfigCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"import", path, "--force", "--json"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if !gotOpts.Force {
+		t.Fatalf("unexpected options: %+v", gotOpts)
+	}
+	var payload bundleOutput
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if payload.Path != path || payload.SettingsBackupPath != "/backups/settings.json" || payload.Database {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+}
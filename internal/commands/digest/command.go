@@ -0,0 +1,128 @@
+package digest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/digest"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/spf13/cobra"
+)
+
+var digestFn = memoryservice.Digest
+
+type digestCommandOptions struct {
+	since      string
+	send       bool
+	jsonOutput bool
+}
+
+type digestOutput struct {
+	digest.Digest
+	Text       string   `json:"text"`
+	Summarized bool     `json:"summarized"`
+	SentTo     []string `json:"sent_to,omitempty"`
+}
+
+var DigestCmd = newDigestCommand()
+
+func newDigestCommand() *cobra.Command {
+	opts := &digestCommandOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "digest",
+		Short: "Review what was learned over a period",
+		Long: `Summarize the memories added (saved, extracted, or imported) and the
+busiest conversations since --since, as a short Markdown review written by the
+tool model. Without a tool model the digest lists them instead.
+
+With --send, the digest is also posted to digest.webhook_url and mailed
+through digest.smtp, whichever are configured. Run it from cron for a weekly
+review:
+
+  0 9 * * 1  gomor digest --since 7d --send`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDigestCommand(cmd, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.since, "since", "7d", "period to review, e.g. 7d, 2w, or 36h")
+	cmd.Flags().BoolVar(&opts.send, "send", false, "deliver the digest to the configured webhook and email")
+	cmd.Flags().BoolVar(&opts.jsonOutput, "json", false, "emit structured JSON output")
+
+	return cmd
+}
+
+func runDigestCommand(cmd *cobra.Command, opts *digestCommandOptions) error {
+	period, err := parsePeriod(opts.since)
+	if err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	result, err := digestFn(ctx, memoryservice.DigestInput{Since: time.Now().Add(-period), Send: opts.send})
+	if err != nil {
+		return err
+	}
+	if result.SummaryErr != nil && !opts.jsonOutput {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: the tool model could not write the digest (%v); listing instead\n", result.SummaryErr)
+	}
+
+	out := cmd.OutOrStdout()
+	if opts.jsonOutput {
+		return writeJSON(out, digestOutput{
+			Digest:     result.Digest,
+			Text:       result.Text,
+			Summarized: result.Summarized,
+			SentTo:     result.SentTo,
+		})
+	}
+
+	if _, err := io.WriteString(out, result.Text); err != nil {
+		return err
+	}
+	if len(result.SentTo) > 0 {
+		_, err = fmt.Fprintf(cmd.ErrOrStderr(), "Sent digest by %s\n", strings.Join(result.SentTo, " and "))
+	}
+	return err
+}
+
+// parsePeriod reads a duration in days ("7d"), weeks ("2w"), or any unit
+// time.ParseDuration accepts ("36h").
+func parsePeriod(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	var period time.Duration
+	var err error
+	switch {
+	case strings.HasSuffix(value, "d"), strings.HasSuffix(value, "w"):
+		var n int
+		n, err = strconv.Atoi(value[:len(value)-1])
+		period = time.Duration(n) * 24 * time.Hour
+		if strings.HasSuffix(value, "w") {
+			period *= 7
+		}
+	default:
+		period, err = time.ParseDuration(value)
+	}
+	if err != nil || period <= 0 {
+		return 0, fmt.Errorf("invalid --since %q: use a positive period such as 7d, 2w, or 36h", value)
+	}
+	return period, nil
+}
+
+func writeJSON(out io.Writer, value any) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
@@ -0,0 +1,54 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+)
+
+func TestDigestCommandPrintsDigestSince(t *testing.T) {
+	oldDigest := digestFn
+	defer func() { digestFn = oldDigest }()
+
+	var gotSince time.Time
+	digestFn = func(ctx context.Context, input memoryservice.DigestInput) (*memoryservice.DigestResult, error) {
+		gotSince = input.Since
+		return &memoryservice.DigestResult{Text: "# gomor digest\n", Summarized: true}, nil
+	}
+
+	cmd := newDigestCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--since", "2w"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if out.String() != "# gomor digest\n" {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+	if age := time.Since(gotSince); age < 14*24*time.Hour-time.Minute || age > 14*24*time.Hour+time.Minute {
+		t.Fatalf("expected a two-week period, got %v", age)
+	}
+}
+
+func TestParsePeriod(t *testing.T) {
+	tests := map[string]time.Duration{
+		"7d":  7 * 24 * time.Hour,
+		"1w":  7 * 24 * time.Hour,
+		"36h": 36 * time.Hour,
+	}
+	for value, want := range tests {
+		if got, err := parsePeriod(value); err != nil || got != want {
+			t.Fatalf("parsePeriod(%q) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"", "d", "-3d", "0h", "soon"} {
+		if _, err := parsePeriod(value); err == nil {
+			t.Fatalf("expected an error for %q", value)
+		}
+	}
+}
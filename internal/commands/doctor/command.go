@@ -0,0 +1,185 @@
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/austiecodes/gomor/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var doctorFn = memoryservice.Doctor
+
+type doctorCommandOptions struct {
+	fix        bool
+	jsonOutput bool
+}
+
+type doctorOutput struct {
+	Healthy          bool                        `json:"healthy"`
+	Integrity        []string                    `json:"integrity,omitempty"`
+	Embeddings       []memtypes.EmbeddingProblem `json:"embeddings,omitempty"`
+	Requeued         int                         `json:"requeued,omitempty"`
+	Reembedded       int                         `json:"reembedded,omitempty"`
+	NeedsReembedding int                         `json:"needs_reembedding,omitempty"` // memories still waiting for a new embedding
+	FTSOrphaned      int                         `json:"fts_orphaned,omitempty"`      // full-text rows pointing at no memory
+	FTSMissing       int                         `json:"fts_missing,omitempty"`       // memories missing from the full-text index
+	FTSRebuilt       bool                        `json:"fts_rebuilt,omitempty"`
+	Dim              int                         `json:"dim,omitempty"`
+	DimMismatches    []string                    `json:"dim_mismatches,omitempty"` // memories embedded at another dimension than dim
+	Warning          string                      `json:"warning,omitempty"`
+}
+
+var DoctorCmd = newDoctorCommand()
+
+func newDoctorCommand() *cobra.Command {
+	opts := &doctorCommandOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the memory store for corruption",
+		Long: `Run SQLite's integrity check over the memory store and look for damaged
+embeddings: truncated blobs, blobs whose length disagrees with their
+dimension, and vectors holding NaN or infinite values. Vector search skips
+such memories (with a warning) and flags them for re-embedding; doctor lists
+every affected memory and history ID and how many memories are flagged.
+It also lists memories embedded at another dimension than the embedding
+model's, which vector search skips silently, and counts full-text index rows
+that point at no memory or memories missing from the index.
+
+With --fix, the damaged and mismatched embeddings are dropped and their rows
+queued for re-embedding, which runs right away when an embedding model is
+configured, and the full-text index is rebuilt.
+Damage found by the integrity check cannot be fixed in place; restore the
+database from a backup in ~/.gomor/backups instead.
+
+Exits with an error while problems remain.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDoctorCommand(cmd, opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.fix, "fix", false, "re-embed the rows with damaged embeddings")
+	cmd.Flags().BoolVar(&opts.jsonOutput, "json", false, "emit structured JSON output")
+
+	return cmd
+}
+
+func runDoctorCommand(cmd *cobra.Command, opts *doctorCommandOptions) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	result, err := doctorFn(ctx, memoryservice.DoctorInput{Fix: opts.fix})
+	if err != nil {
+		return err
+	}
+
+	unusable := len(result.Embeddings) + len(result.DimMismatches)
+	fixed := result.Requeued == unusable
+	drifted := result.FTSOrphaned > 0 || result.FTSMissing > 0
+	output := doctorOutput{
+		Healthy:          len(result.Integrity) == 0 && (unusable == 0 || fixed) && (!drifted || result.FTSRebuilt),
+		Integrity:        result.Integrity,
+		Embeddings:       result.Embeddings,
+		Requeued:         result.Requeued,
+		Reembedded:       result.Reembedded,
+		NeedsReembedding: result.NeedsReembedding,
+		FTSOrphaned:      result.FTSOrphaned,
+		FTSMissing:       result.FTSMissing,
+		FTSRebuilt:       result.FTSRebuilt,
+		Dim:              result.Dim,
+		DimMismatches:    result.DimMismatches,
+	}
+	if result.ReembedErr != nil {
+		output.Warning = fmt.Sprintf("requeued rows were not re-embedded (%v); the embedding worker of the next 'gomor mcp' session will retry them", result.ReembedErr)
+	}
+
+	out := cmd.OutOrStdout()
+	if opts.jsonOutput {
+		if err := writeJSON(out, output); err != nil {
+			return err
+		}
+	} else {
+		if output.Warning != "" {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s\n", output.Warning)
+		}
+		if err := writeText(out, result, fixed); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case len(result.Integrity) > 0:
+		backupDir, err := utils.GetBackupDir()
+		if err != nil {
+			return fmt.Errorf("the database failed its integrity check; restore it from a backup")
+		}
+		return fmt.Errorf("the database failed its integrity check; restore it from a backup in %s", backupDir)
+	case !output.Healthy && unusable > 0 && !fixed:
+		return fmt.Errorf("found %d unusable embeddings; run 'gomor doctor --fix' to re-embed them", unusable)
+	case !output.Healthy:
+		return fmt.Errorf("the full-text index is out of sync; run 'gomor doctor --fix' to rebuild it")
+	}
+	return nil
+}
+
+func writeText(out io.Writer, result *memoryservice.DoctorResult, fixed bool) error {
+	if len(result.Integrity) == 0 {
+		fmt.Fprintln(out, "Database integrity: ok")
+	} else {
+		fmt.Fprintf(out, "Database integrity: %d problems\n", len(result.Integrity))
+		for _, line := range result.Integrity {
+			fmt.Fprintf(out, "  %s\n", line)
+		}
+	}
+
+	if len(result.Embeddings) == 0 {
+		fmt.Fprintln(out, "Embeddings: ok")
+	} else {
+		fmt.Fprintf(out, "Embeddings: %d damaged\n", len(result.Embeddings))
+		for _, problem := range result.Embeddings {
+			fmt.Fprintf(out, "  %s %s: %s\n", problem.TargetKind, problem.TargetID, problem.Reason)
+		}
+	}
+
+	if len(result.DimMismatches) == 0 {
+		fmt.Fprintln(out, "Embedding dimensions: ok")
+	} else {
+		fmt.Fprintf(out, "Embedding dimensions: %d memories not at %d dimensions\n", len(result.DimMismatches), result.Dim)
+		for _, id := range result.DimMismatches {
+			fmt.Fprintf(out, "  memory %s\n", id)
+		}
+	}
+	if fixed && result.Requeued > 0 {
+		fmt.Fprintf(out, "Queued %d rows for re-embedding; %d re-embedded\n", result.Requeued, result.Reembedded)
+	}
+
+	switch {
+	case result.FTSOrphaned == 0 && result.FTSMissing == 0:
+		fmt.Fprintln(out, "Full-text index: ok")
+	case result.FTSRebuilt:
+		fmt.Fprintf(out, "Full-text index: rebuilt (had %d orphaned rows and missed %d memories)\n", result.FTSOrphaned, result.FTSMissing)
+	default:
+		fmt.Fprintf(out, "Full-text index: %d orphaned rows, %d memories missing\n", result.FTSOrphaned, result.FTSMissing)
+	}
+
+	if result.NeedsReembedding == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintf(out, "Memories awaiting re-embedding: %d\n", result.NeedsReembedding)
+	return err
+}
+
+func writeJSON(out io.Writer, value any) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
@@ -0,0 +1,124 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+)
+
+func fakeDoctor(t *testing.T, fn func(input memoryservice.DoctorInput) *memoryservice.DoctorResult) {
+	t.Helper()
+	old := doctorFn
+	t.Cleanup(func() { doctorFn = old })
+	doctorFn = func(ctx context.Context, input memoryservice.DoctorInput) (*memoryservice.DoctorResult, error) {
+		return fn(input), nil
+	}
+}
+
+func execute(args ...string) (string, string, error) {
+	cmd := newDoctorCommand()
+	var out, errOut bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), errOut.String(), err
+}
+
+var damaged = []memtypes.EmbeddingProblem{
+	{TargetKind: memtypes.EmbeddingTargetMemory, TargetID: "mem-1", Reason: "truncated: 10 bytes is not a whole number of float32 values"},
+	{TargetKind: memtypes.EmbeddingTargetHistory, TargetID: "turn-2", Reason: "contains NaN or infinite values"},
+}
+
+func TestDoctorReportsDamagedEmbeddings(t *testing.T) {
+	fakeDoctor(t, func(input memoryservice.DoctorInput) *memoryservice.DoctorResult {
+		if input.Fix {
+			t.Fatal("expected no fix without --fix")
+		}
+		return &memoryservice.DoctorResult{Embeddings: damaged, NeedsReembedding: 1}
+	})
+
+	out, _, err := execute()
+	if err == nil || !strings.Contains(err.Error(), "gomor doctor --fix") {
+		t.Fatalf("expected an error pointing at --fix, got %v", err)
+	}
+	if !strings.Contains(out, "Database integrity: ok\nEmbeddings: 2 damaged\n  memory mem-1: truncated") || !strings.Contains(out, "  history turn-2: contains NaN") ||
+		!strings.Contains(out, "Memories awaiting re-embedding: 1\n") {
+		t.Fatalf("expected the affected IDs listed, got:\n%s", out)
+	}
+}
+
+func TestDoctorFixRequeuesDamagedEmbeddings(t *testing.T) {
+	fakeDoctor(t, func(input memoryservice.DoctorInput) *memoryservice.DoctorResult {
+		result := &memoryservice.DoctorResult{Embeddings: damaged}
+		if input.Fix {
+			result.Requeued = 2
+			result.ReembedErr = errors.New("embedding model not configured")
+		}
+		return result
+	})
+
+	out, errOut, err := execute("--fix", "--json")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	var payload doctorOutput
+	if err := json.Unmarshal([]byte(out), &payload); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if !payload.Healthy || payload.Requeued != 2 || len(payload.Embeddings) != 2 || !strings.Contains(payload.Warning, "embedding model not configured") {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+	if errOut != "" {
+		t.Fatalf("expected warnings in the JSON only, got %q", errOut)
+	}
+}
+
+func TestDoctorFailsOnIntegrityProblems(t *testing.T) {
+	fakeDoctor(t, func(input memoryservice.DoctorInput) *memoryservice.DoctorResult {
+		return &memoryservice.DoctorResult{Integrity: []string{"row 3 missing from index idx_memories_created_at"}}
+	})
+
+	cmd := newDoctorCommand()
+	cmd.SetOut(io.Discard)
+	cmd.SetArgs([]string{"--fix"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "restore it from a backup") {
+		t.Fatalf("expected an integrity failure, got %v", err)
+	}
+}
+
+func TestDoctorReportsIndexDrift(t *testing.T) {
+	fakeDoctor(t, func(input memoryservice.DoctorInput) *memoryservice.DoctorResult {
+		result := &memoryservice.DoctorResult{FTSOrphaned: 2, FTSMissing: 1, Dim: 3, DimMismatches: []string{"mem-7"}}
+		if input.Fix {
+			result.FTSRebuilt = true
+			result.Requeued = 1
+			result.Reembedded = 1
+		}
+		return result
+	})
+
+	out, _, err := execute()
+	if err == nil || !strings.Contains(err.Error(), "1 unusable embeddings") {
+		t.Fatalf("expected the mismatched embedding to fail the check, got %v", err)
+	}
+	if !strings.Contains(out, "Embedding dimensions: 1 memories not at 3 dimensions\n  memory mem-7\n") ||
+		!strings.Contains(out, "Full-text index: 2 orphaned rows, 1 memories missing\n") {
+		t.Fatalf("expected the drift reported, got:\n%s", out)
+	}
+
+	out, _, err = execute("--fix")
+	if err != nil {
+		t.Fatalf("execute --fix: %v", err)
+	}
+	if !strings.Contains(out, "Queued 1 rows for re-embedding; 1 re-embedded\n") || !strings.Contains(out, "Full-text index: rebuilt") {
+		t.Fatalf("expected the fixes reported, got:\n%s", out)
+	}
+}
@@ -0,0 +1,81 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/austiecodes/gomor/internal/memory/calibrate"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/spf13/cobra"
+)
+
+var calibrateFn = memoryservice.Calibrate
+
+type calibrateCommandOptions struct {
+	pairs      int
+	seed       uint64
+	jsonOutput bool
+}
+
+var EvalCmd = newEvalCommand()
+
+func newEvalCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "eval",
+		Short: "Evaluate retrieval settings against your memories",
+	}
+	cmd.AddCommand(newCalibrateCommand())
+	return cmd
+}
+
+func newCalibrateCommand() *cobra.Command {
+	opts := &calibrateCommandOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "calibrate",
+		Short: "Recommend a min_similarity for the embedding model",
+		Long: `Sample random pairs of stored memories, plot how similar the configured
+embedding model finds them, and recommend a memory.min_similarity.
+
+Embedding models differ widely in how similar they score unrelated text, so a
+threshold that suits one model can miss everything or let everything through
+with another. Random pairs are nearly all unrelated, so the recommendation is
+the similarity that 95% of sampled pairs fall below. Only memories embedded by
+the configured model are sampled; re-embed older memories first if you
+switched models.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCalibrateCommand(cmd, opts)
+		},
+	}
+
+	cmd.Flags().IntVar(&opts.pairs, "pairs", calibrate.DefaultPairs, "number of memory pairs to sample")
+	cmd.Flags().Uint64Var(&opts.seed, "seed", 0, "seed for a reproducible sample (default random)")
+	cmd.Flags().BoolVar(&opts.jsonOutput, "json", false, "emit structured JSON output")
+	return cmd
+}
+
+func runCalibrateCommand(cmd *cobra.Command, opts *calibrateCommandOptions) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	report, err := calibrateFn(ctx, memoryservice.CalibrateInput{Pairs: opts.pairs, Seed: opts.seed})
+	if err != nil {
+		return err
+	}
+
+	if opts.jsonOutput {
+		return writeJSON(cmd.OutOrStdout(), report)
+	}
+	return calibrate.WriteText(cmd.OutOrStdout(), report)
+}
+
+func writeJSON(out io.Writer, value any) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
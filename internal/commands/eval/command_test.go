@@ -0,0 +1,79 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/calibrate"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+)
+
+func fakeReport() *calibrate.Report {
+	return &calibrate.Report{
+		Provider:    "openai",
+		ModelID:     "text-embedding-3-small",
+		Memories:    40,
+		Pairs:       780,
+		Median:      0.21,
+		P95:         0.38,
+		Max:         0.71,
+		Histogram:   []calibrate.Bin{{Low: 0.2, High: 0.25, Count: 780}},
+		Current:     0.8,
+		Recommended: 0.38,
+	}
+}
+
+func TestCalibrateCommandPassesFlags(t *testing.T) {
+	oldCalibrate := calibrateFn
+	defer func() { calibrateFn = oldCalibrate }()
+
+	var gotInput memoryservice.CalibrateInput
+	calibrateFn = func(ctx context.Context, input memoryservice.CalibrateInput) (*calibrate.Report, error) {
+		gotInput = input
+		return fakeReport(), nil
+	}
+
+	cmd := newEvalCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"calibrate", "--pairs", "300", "--seed", "42"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if gotInput.Pairs != 300 || gotInput.Seed != 42 {
+		t.Fatalf("unexpected input: %+v", gotInput)
+	}
+	if !strings.Contains(out.String(), "Recommended min_similarity: 0.38") {
+		t.Fatalf("expected recommendation in output, got %q", out.String())
+	}
+}
+
+func TestCalibrateCommandJSONOutput(t *testing.T) {
+	oldCalibrate := calibrateFn
+	defer func() { calibrateFn = oldCalibrate }()
+
+	calibrateFn = func(ctx context.Context, input memoryservice.CalibrateInput) (*calibrate.Report, error) {
+		return fakeReport(), nil
+	}
+
+	cmd := newEvalCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"calibrate", "--json"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	var got calibrate.Report
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out.String())
+	}
+	if got.Recommended != 0.38 || got.Current != 0.8 || len(got.Histogram) != 1 {
+		t.Fatalf("unexpected report: %+v", got)
+	}
+}
@@ -0,0 +1,109 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/memory/importer"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/spf13/cobra"
+)
+
+var exportFn = memoryservice.Export
+
+type exportCommandOptions struct {
+	to              string
+	output          string
+	includeArchived bool
+	jsonOutput      bool
+}
+
+type exportOutput struct {
+	Message  string `json:"message"`
+	Format   string `json:"format"`
+	Path     string `json:"path"`
+	Memories int    `json:"memories"`
+}
+
+var ExportCmd = newExportCommand()
+
+func newExportCommand() *cobra.Command {
+	opts := &exportCommandOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export memories for another memory system",
+		Long: `Export every stored memory in another memory system's JSON format.
+Tags, timestamps, kinds, and imported metadata are carried over; embeddings are
+not, so the receiving system re-embeds on import.
+
+Formats:
+  mem0   mem0 get_all output ({"results": [...]})
+  letta  a list of Letta archival passages
+
+Memories moved out of search by 'gomor memory archive' are left out unless
+--include-archived is set. Without --output the export is written to stdout.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runExportCommand(cmd, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.to, "to", "", "export format: mem0 or letta (required)")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "file to write instead of stdout")
+	cmd.Flags().BoolVar(&opts.includeArchived, "include-archived", false, "also export archived memories")
+	cmd.Flags().BoolVar(&opts.jsonOutput, "json", false, "emit structured JSON output (requires --output)")
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+func runExportCommand(cmd *cobra.Command, opts *exportCommandOptions) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	path := strings.TrimSpace(opts.output)
+	if opts.jsonOutput && path == "" {
+		return fmt.Errorf("--json requires --output")
+	}
+
+	result, err := exportFn(ctx, memoryservice.ExportInput{
+		Format:          importer.Format(strings.ToLower(strings.TrimSpace(opts.to))),
+		Path:            path,
+		IncludeArchived: opts.includeArchived,
+	})
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if path == "" {
+		_, err = out.Write(result.Data)
+		return err
+	}
+
+	output := exportOutput{
+		Message:  fmt.Sprintf("Exported %d memories to %s", result.Memories, result.Path),
+		Format:   string(result.Format),
+		Path:     result.Path,
+		Memories: result.Memories,
+	}
+	if opts.jsonOutput {
+		return writeJSON(out, output)
+	}
+
+	_, err = fmt.Fprintln(out, output.Message)
+	return err
+}
+
+func writeJSON(out io.Writer, value any) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
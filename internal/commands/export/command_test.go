@@ -0,0 +1,97 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/importer"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+)
+
+func TestExportCommandWritesStdout(t *testing.T) {
+	oldExport := exportFn
+	defer func() { exportFn = oldExport }()
+
+	var gotInput memoryservice.ExportInput
+	exportFn = func(ctx context.Context, input memoryservice.ExportInput) (*memoryservice.ExportResult, error) {
+		gotInput = input
+		return &memoryservice.ExportResult{Format: input.Format, Memories: 1, Data: []byte("[]\n")}, nil
+	}
+
+	cmd := newExportCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--to", "Letta"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if gotInput.Format != importer.FormatLetta || gotInput.Path != "" || gotInput.IncludeArchived {
+		t.Fatalf("unexpected input: %+v", gotInput)
+	}
+	if out.String() != "[]\n" {
+		t.Fatalf("expected raw export on stdout, got %q", out.String())
+	}
+}
+
+func TestExportCommandJSONOutput(t *testing.T) {
+	oldExport := exportFn
+	defer func() { exportFn = oldExport }()
+
+	exportFn = func(ctx context.Context, input memoryservice.ExportInput) (*memoryservice.ExportResult, error) {
+		return &memoryservice.ExportResult{Format: input.Format, Path: input.Path, Memories: 4}, nil
+	}
+
+	cmd := newExportCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--to", "mem0", "-o", "out.json", "--json"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	var payload exportOutput
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if payload.Format != "mem0" || payload.Path != "out.json" || payload.Memories != 4 {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+}
+
+func TestExportCommandJSONRequiresOutput(t *testing.T) {
+	cmd := newExportCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"--to", "mem0", "--json"})
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error for --json without --output")
+	}
+}
+
+func TestExportCommandIncludeArchived(t *testing.T) {
+	oldExport := exportFn
+	defer func() { exportFn = oldExport }()
+
+	var gotInput memoryservice.ExportInput
+	exportFn = func(ctx context.Context, input memoryservice.ExportInput) (*memoryservice.ExportResult, error) {
+		gotInput = input
+		return &memoryservice.ExportResult{Format: input.Format, Data: []byte("{}\n")}, nil
+	}
+
+	cmd := newExportCommand()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--to", "mem0", "--include-archived"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if !gotInput.IncludeArchived {
+		t.Fatalf("expected archived memories to be requested, got %+v", gotInput)
+	}
+}
@@ -0,0 +1,329 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/chat"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// conversationFn returns every turn of a session, including turns inherited
+// from the sessions it was forked from.
+var conversationFn = func(sessionID string) ([]memtypes.HistoryItem, error) {
+	memStore, err := store.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	return chat.NewSession(memStore, nil, types.Model{}, sessionID).Conversation()
+}
+
+// forkFn branches a session after the turn ref refers to.
+var forkFn = func(sessionID, ref string) (memtypes.Session, error) {
+	memStore, err := store.NewStore()
+	if err != nil {
+		return memtypes.Session{}, fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	return chat.NewSession(memStore, nil, types.Model{}, sessionID).Fork(ref)
+}
+
+// sessionsFn returns up to limit sessions, the most recently active first.
+var sessionsFn = func(limit int) ([]memtypes.Session, error) {
+	memStore, err := store.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	return memStore.RecentSessions(limit)
+}
+
+type historyCommandOptions struct {
+	jsonOutput bool
+}
+
+type listCommandOptions struct {
+	limit      int
+	jsonOutput bool
+}
+
+type exportCommandOptions struct {
+	format string
+	output string
+}
+
+type turnOutput struct {
+	Turn      int    `json:"turn"`
+	ID        string `json:"id"`
+	SessionID string `json:"session_id"`
+	Role      string `json:"role"`
+	Content   string `json:"content"`
+	CreatedAt string `json:"created_at"`
+	// Interrupted marks an answer cut short by Ctrl-C.
+	Interrupted bool `json:"interrupted,omitempty"`
+}
+
+type forkOutput struct {
+	Message    string `json:"message"`
+	SessionID  string `json:"session_id"`
+	ParentID   string `json:"parent_id"`
+	ForkTurnID string `json:"fork_turn_id"`
+}
+
+var HistoryCmd = newHistoryCommand()
+
+func newHistoryCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Inspect, branch, and export chat sessions",
+	}
+	cmd.AddCommand(newListCommand())
+	cmd.AddCommand(newShowCommand())
+	cmd.AddCommand(newForkCommand())
+	cmd.AddCommand(newExportCommand())
+	return cmd
+}
+
+func newListCommand() *cobra.Command {
+	opts := &listCommandOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List chat sessions, most recent first",
+		Long: `List chat sessions with their titles, the most recently active first.
+The title model names a session in the background after its first exchange;
+sessions it has not named yet show "(untitled)".`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runListCommand(cmd, opts)
+		},
+	}
+
+	cmd.Flags().IntVarP(&opts.limit, "limit", "n", 20, "maximum number of sessions to list")
+	cmd.Flags().BoolVar(&opts.jsonOutput, "json", false, "emit structured JSON output")
+
+	return cmd
+}
+
+func runListCommand(cmd *cobra.Command, opts *listCommandOptions) error {
+	if opts.limit <= 0 {
+		return fmt.Errorf("--limit must be positive")
+	}
+	sessions, err := sessionsFn(opts.limit)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if opts.jsonOutput {
+		if sessions == nil {
+			sessions = []memtypes.Session{}
+		}
+		return writeJSON(out, sessions)
+	}
+
+	if len(sessions) == 0 {
+		_, err = fmt.Fprintln(out, "No sessions yet")
+		return err
+	}
+	for _, session := range sessions {
+		title := session.Title
+		if title == "" {
+			title = "(untitled)"
+		}
+		if _, err := fmt.Fprintf(out, "%s  %s  %s\n", session.ID, session.CreatedAt.Format("2006-01-02 15:04"), title); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newShowCommand() *cobra.Command {
+	opts := &historyCommandOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "show <session>",
+		Short: "List the turns of a session",
+		Long: `List the turns of a session, numbered from 1. Turns a forked session
+inherits from its parent come first. The numbers can be passed to
+'gomor history fork'.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runShowCommand(cmd, opts, args[0])
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.jsonOutput, "json", false, "emit structured JSON output")
+
+	return cmd
+}
+
+func newForkCommand() *cobra.Command {
+	opts := &historyCommandOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "fork <session> <turn>",
+		Short: "Branch a session after a turn",
+		Long: `Create a new session that shares the conversation of <session> up to and
+including <turn>, then diverges. <turn> is a turn number as listed by
+'gomor history show', or a turn ID. The original session is not changed.
+
+Continue the branch with 'gomor chat --session <new session>'.`,
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runForkCommand(cmd, opts, args[0], args[1])
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.jsonOutput, "json", false, "emit structured JSON output")
+
+	return cmd
+}
+
+func newExportCommand() *cobra.Command {
+	opts := &exportCommandOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "export <session>",
+		Short: "Export a session as a Markdown, HTML, or JSON transcript",
+		Long: `Export every turn of a session as a transcript for sharing or archiving,
+with the time of each turn and the model that wrote each answer. Turns a
+forked session inherits from its parent are included.
+
+Formats:
+  md    Markdown (default)
+  html  a standalone HTML page
+  json  the turns as structured JSON
+
+Without --output the transcript is written to stdout.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runExportCommand(cmd, opts, args[0])
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.format, "format", chat.TranscriptMarkdown, "transcript format: md, html, or json")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "file to write instead of stdout")
+
+	return cmd
+}
+
+func runShowCommand(cmd *cobra.Command, opts *historyCommandOptions, sessionID string) error {
+	conversation, err := conversationFn(sessionID)
+	if err != nil {
+		return err
+	}
+
+	turns := make([]turnOutput, len(conversation))
+	for i, item := range conversation {
+		turns[i] = turnOutput{
+			Turn:        i + 1,
+			ID:          item.ID,
+			SessionID:   item.SessionID,
+			Role:        item.Role,
+			Content:     item.Content,
+			CreatedAt:   item.CreatedAt.Format("2006-01-02 15:04:05"),
+			Interrupted: item.Interrupted,
+		}
+	}
+
+	out := cmd.OutOrStdout()
+	if opts.jsonOutput {
+		return writeJSON(out, turns)
+	}
+
+	if len(turns) == 0 {
+		_, err = fmt.Fprintf(out, "No turns in session %s\n", sessionID)
+		return err
+	}
+	for _, turn := range turns {
+		content := summarize(turn.Content)
+		if turn.Interrupted {
+			content += " [interrupted]"
+		}
+		if _, err := fmt.Fprintf(out, "%3d  %-9s  %s\n", turn.Turn, turn.Role, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runForkCommand(cmd *cobra.Command, opts *historyCommandOptions, sessionID, turn string) error {
+	branch, err := forkFn(sessionID, turn)
+	if err != nil {
+		return err
+	}
+
+	output := forkOutput{
+		Message:    fmt.Sprintf("Forked session %s into %s", branch.ParentID, branch.ID),
+		SessionID:  branch.ID,
+		ParentID:   branch.ParentID,
+		ForkTurnID: branch.ForkTurnID,
+	}
+
+	out := cmd.OutOrStdout()
+	if opts.jsonOutput {
+		return writeJSON(out, output)
+	}
+
+	_, err = fmt.Fprintln(out, output.Message)
+	return err
+}
+
+func runExportCommand(cmd *cobra.Command, opts *exportCommandOptions, sessionID string) error {
+	conversation, err := conversationFn(sessionID)
+	if err != nil {
+		return err
+	}
+	if len(conversation) == 0 {
+		return fmt.Errorf("no turns in session %s", sessionID)
+	}
+
+	data, err := chat.Transcript(sessionID, conversation, strings.ToLower(strings.TrimSpace(opts.format)), time.Now())
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	path := strings.TrimSpace(opts.output)
+	if path == "" {
+		_, err = out.Write(data)
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+	_, err = fmt.Fprintf(out, "Exported %d turns of session %s to %s\n", len(conversation), sessionID, path)
+	return err
+}
+
+// summarize collapses content onto a single line of at most 80 runes.
+func summarize(content string) string {
+	line := strings.Join(strings.Fields(content), " ")
+	runes := []rune(line)
+	if len(runes) > 80 {
+		return string(runes[:79]) + "…"
+	}
+	return line
+}
+
+func writeJSON(out io.Writer, value any) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
@@ -0,0 +1,136 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+func TestListCommandShowsTitles(t *testing.T) {
+	oldSessions := sessionsFn
+	defer func() { sessionsFn = oldSessions }()
+
+	var gotLimit int
+	sessionsFn = func(limit int) ([]memtypes.Session, error) {
+		gotLimit = limit
+		return []memtypes.Session{
+			{ID: "s2", CreatedAt: time.Now()},
+			{ID: "s1", Title: "Go channel deadlocks", CreatedAt: time.Now()},
+		}, nil
+	}
+
+	cmd := newHistoryCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"list", "-n", "5"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if gotLimit != 5 {
+		t.Fatalf("expected limit 5, got %d", gotLimit)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "(untitled)") || !strings.HasSuffix(lines[1], "Go channel deadlocks") {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+}
+
+func TestShowCommandNumbersTurns(t *testing.T) {
+	oldConversation := conversationFn
+	defer func() { conversationFn = oldConversation }()
+
+	conversationFn = func(sessionID string) ([]memtypes.HistoryItem, error) {
+		if sessionID != "s1" {
+			t.Fatalf("unexpected session %q", sessionID)
+		}
+		return []memtypes.HistoryItem{
+			{ID: "a", SessionID: "s1", Role: "user", Content: "first\nquestion", CreatedAt: time.Now()},
+			{ID: "b", SessionID: "s1", Role: "assistant", Content: "answer", CreatedAt: time.Now()},
+		}, nil
+	}
+
+	cmd := newHistoryCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"show", "s1"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if !strings.Contains(out.String(), "  1  user       first question") {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+	if !strings.Contains(out.String(), "  2  assistant  answer") {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+}
+
+func TestForkCommandJSONOutput(t *testing.T) {
+	oldFork := forkFn
+	defer func() { forkFn = oldFork }()
+
+	var gotSession, gotTurn string
+	forkFn = func(sessionID, ref string) (memtypes.Session, error) {
+		gotSession, gotTurn = sessionID, ref
+		return memtypes.Session{ID: "branch", ParentID: sessionID, ForkTurnID: "b"}, nil
+	}
+
+	cmd := newHistoryCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"fork", "s1", "2", "--json"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if gotSession != "s1" || gotTurn != "2" {
+		t.Fatalf("unexpected fork args %q %q", gotSession, gotTurn)
+	}
+
+	var payload forkOutput
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if payload.SessionID != "branch" || payload.ParentID != "s1" || payload.ForkTurnID != "b" {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+}
+
+func TestExportCommandWritesTranscript(t *testing.T) {
+	oldConversation := conversationFn
+	defer func() { conversationFn = oldConversation }()
+
+	conversationFn = func(sessionID string) ([]memtypes.HistoryItem, error) {
+		return []memtypes.HistoryItem{
+			{ID: "a", SessionID: sessionID, Role: "user", Content: "hello", CreatedAt: time.Now()},
+			{ID: "b", SessionID: sessionID, Role: "assistant", Model: "anthropic/claude-haiku", Content: "hi", CreatedAt: time.Now()},
+		}, nil
+	}
+
+	path := filepath.Join(t.TempDir(), "s1.html")
+	cmd := newHistoryCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"export", "s1", "--format", "html", "-o", path})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if out.String() != "Exported 2 turns of session s1 to "+path+"\n" {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read transcript: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "<!DOCTYPE html>") || !strings.Contains(string(data), "anthropic/claude-haiku") {
+		t.Fatalf("unexpected transcript:\n%s", data)
+	}
+}
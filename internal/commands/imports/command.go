@@ -0,0 +1,117 @@
+package imports
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/memory/importer"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/spf13/cobra"
+)
+
+var importFn = memoryservice.Import
+
+type importCommandOptions struct {
+	from       string
+	tags       string
+	jsonOutput bool
+}
+
+type importOutput struct {
+	Message    string `json:"message"`
+	Parsed     int    `json:"parsed"`
+	Imported   int    `json:"imported"`
+	Duplicates int    `json:"duplicates"`
+	Pending    bool   `json:"pending,omitempty"`
+}
+
+var ImportCmd = newImportCommand()
+
+func newImportCommand() *cobra.Command {
+	opts := &importCommandOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import memories from another assistant",
+		Long: `Import memories exported from another assistant. Entries whose text is
+already stored are skipped.
+
+Formats:
+  chatgpt  saved memories copied from ChatGPT (one per line) or a JSON list
+  claude   projects.json or memories.json from a Claude data export
+  mem0     mem0 get_all output, as a list or {"results": [...]}
+  letta    Letta archival passages or an agent file (.af)
+
+Imported memories are re-embedded with the configured embedding model.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runImportCommand(cmd, args[0], opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.from, "from", "", "export format: chatgpt, claude, mem0, or letta (required)")
+	cmd.Flags().StringVar(&opts.tags, "tags", "", "comma-separated tags added to every imported memory")
+	cmd.Flags().BoolVar(&opts.jsonOutput, "json", false, "emit structured JSON output")
+	_ = cmd.MarkFlagRequired("from")
+
+	return cmd
+}
+
+func runImportCommand(cmd *cobra.Command, path string, opts *importCommandOptions) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	result, err := importFn(ctx, memoryservice.ImportInput{
+		Format: importer.Format(strings.ToLower(strings.TrimSpace(opts.from))),
+		Path:   path,
+		Tags:   parseTags(opts.tags),
+	})
+	if err != nil {
+		return err
+	}
+
+	output := importOutput{
+		Message:    fmt.Sprintf("Imported %d memories (%d duplicates skipped)", result.Imported, result.Duplicates),
+		Parsed:     result.Parsed,
+		Imported:   result.Imported,
+		Duplicates: result.Duplicates,
+		Pending:    result.Pending,
+	}
+	if result.Pending {
+		output.Message += "; embeddings were queued for retry"
+	}
+
+	out := cmd.OutOrStdout()
+	if opts.jsonOutput {
+		return writeJSON(out, output)
+	}
+
+	_, err = fmt.Fprintln(out, output.Message)
+	return err
+}
+
+func writeJSON(out io.Writer, value any) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func parseTags(input string) []string {
+	if strings.TrimSpace(input) == "" {
+		return nil
+	}
+
+	var tags []string
+	for _, p := range strings.Split(input, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			tags = append(tags, p)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,58 @@
+package imports
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/importer"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+)
+
+func TestImportCommandRequiresFrom(t *testing.T) {
+	cmd := newImportCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"memories.txt"})
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected missing --from error")
+	}
+}
+
+func TestImportCommandJSONOutput(t *testing.T) {
+	oldImport := importFn
+	defer func() { importFn = oldImport }()
+
+	var gotInput memoryservice.ImportInput
+	importFn = func(ctx context.Context, input memoryservice.ImportInput) (*memoryservice.ImportResult, error) {
+		gotInput = input
+		return &memoryservice.ImportResult{
+			Parsed: 5,
+			Result: importer.Result{Imported: 3, Duplicates: 2},
+		}, nil
+	}
+
+	cmd := newImportCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"memories.txt", "--from", "ChatGPT", "--json"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if gotInput.Format != importer.FormatChatGPT || gotInput.Path != "memories.txt" {
+		t.Fatalf("unexpected input: %+v", gotInput)
+	}
+
+	var payload importOutput
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v", err)
+	}
+	if payload.Imported != 3 || payload.Duplicates != 2 || payload.Parsed != 5 {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+}
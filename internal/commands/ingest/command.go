@@ -0,0 +1,134 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/spf13/cobra"
+)
+
+var ingestFn = memoryservice.Ingest
+
+type ingestCommandOptions struct {
+	tags       string
+	jsonOutput bool
+}
+
+type ingestFileOutput struct {
+	Path    string `json:"path"`
+	Chunks  int    `json:"chunks"`
+	Pending bool   `json:"pending,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type ingestOutput struct {
+	Message string             `json:"message"`
+	Chunks  int                `json:"chunks"`
+	Files   []ingestFileOutput `json:"files"`
+}
+
+var IngestCmd = newIngestCommand()
+
+func newIngestCommand() *cobra.Command {
+	opts := &ingestCommandOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "ingest <path>...",
+		Short: "Ingest documents into memory",
+		Long: `Chunk markdown, text, and PDF files (or directories of them) and store the
+chunks as document memories. Re-ingesting a file replaces its previous chunks.
+PDFs are converted with the external command set in ingest.pdf_to_text (default: pdftotext).`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runIngestCommand(cmd, args, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.tags, "tags", "", "comma-separated tags applied to every chunk")
+	cmd.Flags().BoolVar(&opts.jsonOutput, "json", false, "emit structured JSON output")
+
+	return cmd
+}
+
+func runIngestCommand(cmd *cobra.Command, paths []string, opts *ingestCommandOptions) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	result, err := ingestFn(ctx, memoryservice.IngestInput{
+		Paths: paths,
+		Tags:  parseTags(opts.tags),
+	})
+	if err != nil {
+		return err
+	}
+
+	output := ingestOutput{
+		Message: fmt.Sprintf("Ingested %d chunks from %d files", result.Chunks, len(result.Files)),
+		Chunks:  result.Chunks,
+		Files:   make([]ingestFileOutput, 0, len(result.Files)),
+	}
+	failed := 0
+	for _, f := range result.Files {
+		fileOutput := ingestFileOutput{Path: f.Path, Chunks: f.Chunks, Pending: f.Pending}
+		if f.Err != nil {
+			fileOutput.Error = f.Err.Error()
+			failed++
+		}
+		output.Files = append(output.Files, fileOutput)
+	}
+	if failed > 0 {
+		output.Message += fmt.Sprintf(" (%d failed)", failed)
+	}
+
+	out := cmd.OutOrStdout()
+	if opts.jsonOutput {
+		return writeJSON(out, output)
+	}
+	return writeText(out, output)
+}
+
+func writeText(out io.Writer, output ingestOutput) error {
+	for _, f := range output.Files {
+		var line string
+		switch {
+		case f.Error != "":
+			line = fmt.Sprintf("  %s: error: %s", f.Path, f.Error)
+		case f.Pending:
+			line = fmt.Sprintf("  %s: %d chunks (embedding queued)", f.Path, f.Chunks)
+		default:
+			line = fmt.Sprintf("  %s: %d chunks", f.Path, f.Chunks)
+		}
+		if _, err := fmt.Fprintln(out, line); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(out, output.Message)
+	return err
+}
+
+func writeJSON(out io.Writer, value any) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func parseTags(input string) []string {
+	if strings.TrimSpace(input) == "" {
+		return nil
+	}
+
+	var tags []string
+	for _, p := range strings.Split(input, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			tags = append(tags, p)
+		}
+	}
+	return tags
+}
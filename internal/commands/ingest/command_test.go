@@ -0,0 +1,73 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/ingest"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+)
+
+func TestIngestCommandRequiresPath(t *testing.T) {
+	cmd := newIngestCommand()
+	cmd.SetArgs([]string{})
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected missing path error")
+	}
+}
+
+func TestIngestCommandJSONOutput(t *testing.T) {
+	oldIngest := ingestFn
+	defer func() { ingestFn = oldIngest }()
+
+	var gotInput memoryservice.IngestInput
+	ingestFn = func(ctx context.Context, input memoryservice.IngestInput) (*memoryservice.IngestResult, error) {
+		gotInput = input
+		return &memoryservice.IngestResult{
+			Chunks: 4,
+			Files: []ingest.FileResult{
+				{Path: "/docs/a.md", Chunks: 4},
+				{Path: "/docs/b.pdf", Err: errors.New("pdftotext not found")},
+			},
+		}, nil
+	}
+
+	cmd := newIngestCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"docs", "--tags", "atlas, docs", "--json"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	if len(gotInput.Paths) != 1 || gotInput.Paths[0] != "docs" {
+		t.Fatalf("unexpected paths: %v", gotInput.Paths)
+	}
+	if strings.Join(gotInput.Tags, ",") != "atlas,docs" {
+		t.Fatalf("unexpected tags: %v", gotInput.Tags)
+	}
+
+	var payload ingestOutput
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v", err)
+	}
+	if payload.Chunks != 4 || len(payload.Files) != 2 {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+	if payload.Files[1].Error != "pdftotext not found" {
+		t.Fatalf("expected per-file error, got %+v", payload.Files[1])
+	}
+	if !strings.Contains(payload.Message, "1 failed") {
+		t.Fatalf("unexpected message: %s", payload.Message)
+	}
+}
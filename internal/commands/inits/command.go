@@ -0,0 +1,364 @@
+package inits
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/consts"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/provider"
+	"github.com/austiecodes/gomor/internal/provider/local"
+	"github.com/austiecodes/gomor/internal/provider/mock"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+	"github.com/openai/openai-go/v3"
+	"github.com/spf13/cobra"
+)
+
+// defaultModels are the chat and embedding models a provider is set up with
+// when none are given. Anthropic serves no embedding models, so it embeds
+// with the built-in local model.
+var defaultModels = map[string][2]types.Model{
+	consts.ProviderOpenAI: {
+		{Provider: consts.ProviderOpenAI, ModelID: string(openai.ChatModelGPT5Nano)},
+		{Provider: consts.ProviderOpenAI, ModelID: "text-embedding-3-small"},
+	},
+	consts.ProviderGoogle: {
+		{Provider: consts.ProviderGoogle, ModelID: "gemini-2.5-flash"},
+		{Provider: consts.ProviderGoogle, ModelID: "gemini-embedding-001"},
+	},
+	consts.ProviderAnthropic: {
+		{Provider: consts.ProviderAnthropic, ModelID: "claude-sonnet-4-5"},
+		{Provider: consts.ProviderLocal, ModelID: local.ModelNgramHash512},
+	},
+	consts.ProviderOllama: {
+		{Provider: consts.ProviderOllama, ModelID: "llama3.2"},
+		{Provider: consts.ProviderOllama, ModelID: "nomic-embed-text"},
+	},
+	consts.ProviderMock: {
+		{Provider: consts.ProviderMock, ModelID: mock.ModelChat},
+		{Provider: consts.ProviderMock, ModelID: mock.ModelEmbedding},
+	},
+}
+
+type initCommandOptions struct {
+	nonInteractive bool
+	provider       string
+	apiKeyEnv      string
+	baseURL        string
+	chatModel      string
+	embeddingModel string
+	noVerify       bool
+	jsonOutput     bool
+}
+
+// check is the outcome of one setup check.
+type check struct {
+	Name    string `json:"name"`
+	OK      bool   `json:"ok"`
+	Skipped bool   `json:"skipped,omitempty"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+type initOutput struct {
+	ConfigPath     string  `json:"config_path"`
+	Saved          bool    `json:"saved"`
+	ChatModel      string  `json:"chat_model"`
+	EmbeddingModel string  `json:"embedding_model"`
+	Checks         []check `json:"checks,omitempty"`
+}
+
+var InitCmd = newInitCommand()
+
+func newInitCommand() *cobra.Command {
+	opts := &initCommandOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Set up gomor from flags, for scripts and package managers",
+		Long: `Write a working config from flags and the environment, then check it: the
+provider accepts the key and offers the chat model, the embedding model embeds,
+and the memory database opens. The config is only saved when every check
+passes, so a failed post-install step leaves no broken setup behind.
+
+  gomor init --non-interactive --provider openai --api-key-env OPENAI_API_KEY
+
+The key is read from the named environment variable, never from the command
+line. Models default to the provider's recommended ones; a model already
+configured is kept unless --chat-model or --embedding-model is given, so
+running init again is safe. The title, think, and tool models follow the chat
+model when unset.
+
+Providers are openai, google, anthropic (which embeds with the built-in local
+model), ollama (which needs no key, only the models pulled), and mock. Use 'gomor set' to configure gomor interactively.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runInitCommand(cmd, opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.nonInteractive, "non-interactive", false, "configure from flags instead of prompting")
+	cmd.Flags().StringVar(&opts.provider, "provider", "", "provider to set up: openai, google, anthropic, ollama, or mock")
+	cmd.Flags().StringVar(&opts.apiKeyEnv, "api-key-env", "", "environment variable holding the provider's API key")
+	cmd.Flags().StringVar(&opts.baseURL, "base-url", "", "provider API base URL, for proxies and compatible servers")
+	cmd.Flags().StringVar(&opts.chatModel, "chat-model", "", "chat model ID, or provider/model")
+	cmd.Flags().StringVar(&opts.embeddingModel, "embedding-model", "", "embedding model ID, or provider/model")
+	cmd.Flags().BoolVar(&opts.noVerify, "no-verify", false, "save the config without checking it")
+	cmd.Flags().BoolVar(&opts.jsonOutput, "json", false, "emit structured JSON output")
+
+	return cmd
+}
+
+func runInitCommand(cmd *cobra.Command, opts *initCommandOptions) error {
+	if !opts.nonInteractive {
+		return fmt.Errorf("run 'gomor set' to configure gomor interactively, or pass --non-interactive with --provider")
+	}
+	defaults, ok := defaultModels[opts.provider]
+	if !ok {
+		return fmt.Errorf("unknown provider %q: use openai, google, anthropic, ollama, or mock", opts.provider)
+	}
+
+	configPath, err := utils.GetConfigPath()
+	if err != nil {
+		return err
+	}
+	config, err := utils.LoadConfig()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		// The default config's models are OpenAI's; a fresh setup uses the
+		// chosen provider's throughout.
+		config.Model.ChatModel, config.Model.TitleModel, config.Model.ThinkModel = nil, nil, nil
+		config.Model.ToolModel, config.Model.EmbeddingModel = nil, nil
+	}
+	if err := applyProvider(config, opts); err != nil {
+		return err
+	}
+	config.Model.ChatModel = pickModel(opts.chatModel, opts.provider, config.Model.ChatModel, defaults[0])
+	config.Model.EmbeddingModel = pickModel(opts.embeddingModel, opts.provider, config.Model.EmbeddingModel, defaults[1])
+	for _, role := range []**types.Model{&config.Model.TitleModel, &config.Model.ThinkModel, &config.Model.ToolModel} {
+		if *role == nil {
+			model := *config.Model.ChatModel
+			*role = &model
+		}
+	}
+
+	output := initOutput{
+		ConfigPath:     configPath,
+		ChatModel:      modelRef(*config.Model.ChatModel),
+		EmbeddingModel: modelRef(*config.Model.EmbeddingModel),
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var failed []string
+	if !opts.noVerify {
+		output.Checks = verify(ctx, config)
+		for _, c := range output.Checks {
+			if !c.OK {
+				failed = append(failed, c.Name)
+			}
+		}
+	}
+	if len(failed) == 0 {
+		if err := utils.SaveConfig(config); err != nil {
+			return err
+		}
+		output.Saved = true
+	}
+
+	out := cmd.OutOrStdout()
+	if opts.jsonOutput {
+		if err := writeJSON(out, output); err != nil {
+			return err
+		}
+	} else if err := writeText(out, output); err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("setup check failed (%s); the config was not saved", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// applyProvider stores the provider's key and base URL in config.
+func applyProvider(config *utils.Config, opts *initCommandOptions) error {
+	var apiKey string
+	if opts.apiKeyEnv != "" {
+		apiKey = strings.TrimSpace(os.Getenv(opts.apiKeyEnv))
+		if apiKey == "" {
+			return fmt.Errorf("environment variable %s is not set", opts.apiKeyEnv)
+		}
+	}
+
+	switch opts.provider {
+	case consts.ProviderOpenAI:
+		setCredentials(&config.Providers.OpenAI.APIKey, &config.Providers.OpenAI.BaseURL, apiKey, opts.baseURL)
+		if config.Providers.OpenAI.APIKey == "" {
+			return fmt.Errorf("no OpenAI API key: pass --api-key-env with the variable holding it")
+		}
+	case consts.ProviderAnthropic:
+		setCredentials(&config.Providers.Anthropic.APIKey, &config.Providers.Anthropic.BaseURL, apiKey, opts.baseURL)
+		if config.Providers.Anthropic.APIKey == "" {
+			return fmt.Errorf("no Anthropic API key: pass --api-key-env with the variable holding it")
+		}
+	case consts.ProviderGoogle:
+		// Vertex AI projects and GOOGLE_API_KEY work without a configured key.
+		setCredentials(&config.Providers.Google.APIKey, &config.Providers.Google.BaseURL, apiKey, opts.baseURL)
+	case consts.ProviderOllama:
+		if opts.baseURL != "" {
+			config.Providers.Ollama.BaseURL = opts.baseURL
+		}
+	}
+	return nil
+}
+
+func setCredentials(key, baseURL *string, newKey, newBaseURL string) {
+	if newKey != "" {
+		*key = newKey
+	}
+	if newBaseURL != "" {
+		*baseURL = newBaseURL
+	}
+}
+
+// pickModel resolves a --chat-model or --embedding-model flag, keeping the
+// configured model, or falling back to the provider default, when it is unset.
+func pickModel(flag, providerName string, configured *types.Model, fallback types.Model) *types.Model {
+	flag = strings.TrimSpace(flag)
+	switch {
+	case flag != "":
+		model := types.Model{Provider: providerName, ModelID: flag}
+		if ref, ok := utils.ParseModelRef(flag); ok {
+			if _, known := defaultModels[ref.Provider]; known || ref.Provider == consts.ProviderLocal {
+				model = ref
+			}
+		}
+		if configured != nil && configured.Provider == model.Provider && configured.ModelID == model.ModelID {
+			return configured
+		}
+		return &model
+	case configured != nil:
+		return configured
+	default:
+		return &fallback
+	}
+}
+
+// verify checks that the config works before it is saved.
+func verify(ctx context.Context, config *utils.Config) []check {
+	return []check{
+		checkChatModel(ctx, config, *config.Model.ChatModel),
+		checkEmbeddingModel(ctx, config, *config.Model.EmbeddingModel),
+		checkStore(),
+	}
+}
+
+func checkChatModel(ctx context.Context, config *utils.Config, model types.Model) check {
+	c := check{Name: "chat model " + modelRef(model)}
+	qc, err := provider.NewQueryClient(config, model.Provider)
+	if err != nil {
+		return failure(c, err)
+	}
+	models, err := qc.ListModels(ctx)
+	if err != nil {
+		return failure(c, err)
+	}
+	// Listings may only name dated snapshots of an alias, e.g.
+	// claude-sonnet-4-5-20250929 for claude-sonnet-4-5, or tagged ones, e.g.
+	// llama3.2:latest for llama3.2.
+	for _, id := range models {
+		if id == model.ModelID || strings.HasPrefix(id, model.ModelID+"-") || strings.HasPrefix(id, model.ModelID+":") {
+			c.OK = true
+			return c
+		}
+	}
+	c.Detail = fmt.Sprintf("%s does not offer %s; pass --chat-model", model.Provider, model.ModelID)
+	return c
+}
+
+func checkEmbeddingModel(ctx context.Context, config *utils.Config, model types.Model) check {
+	c := check{Name: "embedding model " + modelRef(model)}
+	ec, err := provider.NewEmbeddingClient(config, model.Provider)
+	if err != nil {
+		return failure(c, err)
+	}
+	vector, err := ec.Embed(ctx, model, "gomor setup check")
+	if err != nil {
+		return failure(c, err)
+	}
+	if len(vector) == 0 {
+		c.Detail = "the model returned an empty embedding"
+		return c
+	}
+	c.OK = true
+	c.Detail = fmt.Sprintf("%d dimensions", len(vector))
+	return c
+}
+
+func checkStore() check {
+	c := check{Name: "memory database"}
+	memStore, err := store.NewStore()
+	if err != nil {
+		return failure(c, err)
+	}
+	memStore.Close()
+	c.OK = true
+	if path, err := utils.GetDBPath(); err == nil {
+		c.Detail = path
+	}
+	return c
+}
+
+// failure records err on c. Checks needing the network pass as skipped in
+// offline mode.
+func failure(c check, err error) check {
+	if errors.Is(err, provider.ErrOffline) {
+		c.OK = true
+		c.Skipped = true
+		c.Detail = "skipped offline"
+		return c
+	}
+	c.Detail = err.Error()
+	return c
+}
+
+func modelRef(model types.Model) string {
+	return model.Provider + "/" + model.ModelID
+}
+
+func writeText(out io.Writer, output initOutput) error {
+	for _, c := range output.Checks {
+		status := "ok"
+		if !c.OK {
+			status = "FAIL"
+		}
+		line := fmt.Sprintf("%-4s  %s", status, c.Name)
+		if c.Detail != "" {
+			line += ": " + c.Detail
+		}
+		if _, err := fmt.Fprintln(out, line); err != nil {
+			return err
+		}
+	}
+	if !output.Saved {
+		return nil
+	}
+	_, err := fmt.Fprintf(out, "Saved %s (chat %s, embeddings %s)\n", output.ConfigPath, output.ChatModel, output.EmbeddingModel)
+	return err
+}
+
+func writeJSON(out io.Writer, value any) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
@@ -0,0 +1,133 @@
+package inits
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/mockprovider"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+func TestInitNonInteractiveOpenAI(t *testing.T) {
+	server := mockprovider.New(t)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("TEST_OPENAI_KEY", mockprovider.APIKey)
+
+	cmd := newInitCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{
+		"--non-interactive", "--provider", "openai", "--api-key-env", "TEST_OPENAI_KEY",
+		"--base-url", server.BaseURL(),
+		"--chat-model", mockprovider.ChatModel, "--embedding-model", mockprovider.EmbeddingModel,
+		"--json",
+	})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v\n%s", err, out.String())
+	}
+	var payload initOutput
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if !payload.Saved || len(payload.Checks) != 3 {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+	for _, c := range payload.Checks {
+		if !c.OK || c.Skipped {
+			t.Fatalf("expected every check to pass, got %+v", c)
+		}
+	}
+
+	config, err := utils.LoadConfig()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if config.Providers.OpenAI.APIKey != mockprovider.APIKey || config.Providers.OpenAI.BaseURL != server.BaseURL() {
+		t.Fatalf("unexpected provider config: %+v", config.Providers.OpenAI)
+	}
+	if config.Model.ChatModel.ModelID != mockprovider.ChatModel || config.Model.ToolModel.ModelID != mockprovider.ChatModel {
+		t.Fatalf("unexpected models: %+v", config.Model)
+	}
+}
+
+func TestInitDoesNotSaveAFailingConfig(t *testing.T) {
+	server := mockprovider.New(t)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("TEST_OPENAI_KEY", "wrong")
+
+	cmd := newInitCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{
+		"--non-interactive", "--provider", "openai", "--api-key-env", "TEST_OPENAI_KEY",
+		"--base-url", server.BaseURL(),
+	})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "not saved") {
+		t.Fatalf("expected the checks to fail, got %v", err)
+	}
+	if !strings.Contains(out.String(), "FAIL  chat model openai/gpt-5-nano") {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+	configPath, err := utils.GetConfigPath()
+	if err != nil {
+		t.Fatalf("config path: %v", err)
+	}
+	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+		t.Fatalf("expected no config to be saved, got %v", err)
+	}
+}
+
+func TestInitKeepsConfiguredModels(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	run := func(args ...string) {
+		t.Helper()
+		cmd := newInitCommand()
+		cmd.SetOut(io.Discard)
+		cmd.SetArgs(append([]string{"--non-interactive", "--provider", "mock"}, args...))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+	}
+	run("--embedding-model", "local/ngram-hash-512")
+	run()
+
+	config, err := utils.LoadConfig()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if got := modelRef(*config.Model.EmbeddingModel); got != "local/ngram-hash-512" {
+		t.Fatalf("expected a second run to keep the embedding model, got %s", got)
+	}
+	if got := modelRef(*config.Model.ChatModel); got != "mock/mock-chat" {
+		t.Fatalf("expected the mock chat model, got %s", got)
+	}
+}
+
+func TestInitRequiresNonInteractiveAndKey(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("TEST_MISSING_KEY", "")
+
+	for _, args := range [][]string{
+		{"--provider", "openai"},
+		{"--non-interactive", "--provider", "cohere"},
+		{"--non-interactive", "--provider", "openai"},
+		{"--non-interactive", "--provider", "openai", "--api-key-env", "TEST_MISSING_KEY"},
+	} {
+		cmd := newInitCommand()
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err == nil {
+			t.Fatalf("expected %v to fail", args)
+		}
+	}
+}
@@ -4,25 +4,59 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sync/atomic"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/spf13/cobra"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/memory/embedtext"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/memory/worker"
+	"github.com/austiecodes/gomor/internal/provider"
+	"github.com/austiecodes/gomor/internal/utils"
 )
 
+// deferEmbeddings is set while the background embedding worker is running, so
+// memory_save can return without waiting on the embedding provider.
+var deferEmbeddings atomic.Bool
+
+// retriever is the server's long-lived retriever. memory_retrieve builds one
+// per call when it is nil.
+var retriever *memoryservice.Retriever
+
 // McpCmd is the command to start the MCP server
-var McpCmd = &cobra.Command{
-	Use:   "mcp",
-	Short: "Start the MCP server over stdio",
-	Long:  `Start a Model Context Protocol (MCP) server that communicates over stdio. This allows gomor to be used as an MCP tool provider.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		if err := runMcpServer(); err != nil {
-			fmt.Fprintf(os.Stderr, "MCP server error: %v\n", err)
-			os.Exit(1)
-		}
-	},
+var McpCmd = newMcpCommand()
+
+func newMcpCommand() *cobra.Command {
+	var httpAddr string
+
+	cmd := &cobra.Command{
+		Use:   "mcp",
+		Short: "Start the MCP server over stdio or HTTP",
+		Long: `Start a Model Context Protocol (MCP) server that communicates over stdio. This allows gomor to be used as an MCP tool provider.
+
+With --http, serve remote agents and web-based clients over HTTP instead:
+streamable HTTP at /mcp and the older SSE transport at /sse. Set mcp.http_token
+in the config to require "Authorization: Bearer <token>" on every request;
+without one, only a loopback address such as 127.0.0.1:8080 is accepted.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runMcpServer(cmd.Context(), httpAddr); err != nil {
+				fmt.Fprintf(os.Stderr, "MCP server error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&httpAddr, "http", "", "serve over HTTP on this address, e.g. 127.0.0.1:8080, instead of stdio")
+
+	return cmd
 }
 
-func runMcpServer() error {
+// newServer creates the MCP server with every memory tool registered.
+func newServer() *mcp.Server {
 	// Create the MCP server
 	server := mcp.NewServer(
 		&mcp.Implementation{
@@ -35,7 +69,7 @@ func runMcpServer() error {
 	// Register the memory_save tool
 	memorySaveTool := &mcp.Tool{
 		Name:        "memory_save",
-		Description: "Save a user preference or fact to memory. Use this to store declarative statements about user preferences, knowledge, or context.",
+		Description: "Save a user preference or fact to memory. Use this to store declarative statements about user preferences, knowledge, or context. Saved memories may wait for the user to approve them before they are retrieved.",
 	}
 	mcp.AddTool(server, memorySaveTool, handleMemorySave)
 
@@ -46,6 +80,27 @@ func runMcpServer() error {
 	}
 	mcp.AddTool(server, memoryRetrieveTool, handleMemoryRetrieve)
 
+	// Register the memory_retrieve_batch tool
+	memoryRetrieveBatchTool := &mcp.Tool{
+		Name:        "memory_retrieve_batch",
+		Description: "Retrieve relevant memories for several queries in one call, e.g. one per topic of a task. Results are grouped by query, and each memory is listed once, under the query it is most relevant to.",
+	}
+	mcp.AddTool(server, memoryRetrieveBatchTool, handleMemoryRetrieveBatch)
+
+	// Register the memory_find tool
+	memoryFindTool := &mcp.Tool{
+		Name:        "memory_find",
+		Description: "List the memories matching an exact search, e.g. `tag:golang source:explicit before:2024-06 \"unit test\"`. Use this to slice the memory base by tag, source, kind, or date; use memory_retrieve to recall what is relevant to a question.",
+	}
+	mcp.AddTool(server, memoryFindTool, handleMemoryFind)
+
+	// Register the memory_list tool
+	memoryListTool := &mcp.Tool{
+		Name:        "memory_list",
+		Description: "List the stored memories page by page, newest first, optionally only those with a tag. Use this to enumerate memories; use memory_find to search them.",
+	}
+	mcp.AddTool(server, memoryListTool, handleMemoryList)
+
 	// Register the memory_delete tool
 	memoryDeleteTool := &mcp.Tool{
 		Name:        "memory_delete",
@@ -53,7 +108,123 @@ func runMcpServer() error {
 	}
 	mcp.AddTool(server, memoryDeleteTool, handleMemoryDelete)
 
-	// Start the stdio server
-	ctx := context.Background()
-	return server.Run(ctx, &mcp.StdioTransport{})
+	// Register the memory_update tool
+	memoryUpdateTool := &mcp.Tool{
+		Name:        "memory_update",
+		Description: "Correct a memory's text or replace its tags by ID, keeping its ID, pin, and history. The memory is re-embedded. Use memory_save with supersedes instead when the old fact should stay on record.",
+	}
+	mcp.AddTool(server, memoryUpdateTool, handleMemoryUpdate)
+
+	// Register the memory_feedback tool
+	memoryFeedbackTool := &mcp.Tool{
+		Name:        "memory_feedback",
+		Description: "Report whether a retrieved memory was useful or wrong. Wrong memories lose confidence and rank lower in later retrievals.",
+	}
+	mcp.AddTool(server, memoryFeedbackTool, handleMemoryFeedback)
+
+	return server
+}
+
+// runMcpServer serves the memory tools over stdio, or over HTTP on httpAddr
+// when it is set, until ctx is done or the client disconnects.
+func runMcpServer(ctx context.Context, httpAddr string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var token string
+	if httpAddr != "" {
+		config, err := utils.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		token = config.MCP.HTTPToken
+		if err := checkExposure(httpAddr, token); err != nil {
+			return err
+		}
+	}
+
+	stopWorker := startEmbeddingWorker(ctx)
+	defer stopWorker()
+
+	if config, err := utils.LoadConfig(); err == nil {
+		if r, err := memoryservice.NewRetriever(config); err == nil {
+			retriever = r
+			defer func() {
+				retriever = nil
+				r.Close()
+			}()
+		} else {
+			fmt.Fprintf(os.Stderr, "retriever will be built per call: %v\n", err)
+		}
+	}
+
+	server := newServer()
+	if httpAddr != "" {
+		return serveHTTP(ctx, httpAddr, httpHandler(server, token))
+	}
+
+	// Start the stdio server; Ctrl-C stops it cleanly
+	if err := server.Run(ctx, &mcp.StdioTransport{}); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+// startEmbeddingWorker launches the background embedding worker if an embedding
+// model is configured. It returns a function that stops the worker and closes
+// its store.
+func startEmbeddingWorker(ctx context.Context) func() {
+	config, err := utils.LoadConfig()
+	if err != nil || config.Model.EmbeddingModel == nil {
+		return func() {}
+	}
+
+	embeddingModel := *config.Model.EmbeddingModel
+	embClient, err := provider.NewEmbeddingClient(config, embeddingModel.Provider)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "embedding worker disabled: %v\n", err)
+		return func() {}
+	}
+
+	tmpl, err := embedtext.Parse(config.Memory.EmbeddingTemplate)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "embedding worker disabled: %v\n", err)
+		return func() {}
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "embedding worker disabled: %v\n", err)
+		return func() {}
+	}
+
+	w := worker.NewEmbeddingWorker(memStore, embClient, embeddingModel, tmpl, config.EmbeddingQueue)
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = w.Run(ctx)
+	}()
+	deferEmbeddings.Store(true)
+
+	return func() {
+		deferEmbeddings.Store(false)
+		// The worker runs until its context is done, which the server's own
+		// cancel does not guarantee yet when this runs.
+		cancel()
+		<-done
+		memStore.Close()
+	}
+}
+
+// withGuidance appends the fix for a provider failure to err, so the agent
+// can pass it on to the user instead of a raw SDK error.
+func withGuidance(err error) error {
+	if hint := client.Guidance(err); hint != "" {
+		return fmt.Errorf("%w (%s)", err, hint)
+	}
+	return err
 }
@@ -0,0 +1,35 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRunMcpServerReturnsOnStdinEOFWithWorker(t *testing.T) {
+	useMockProvider(t, nil)
+
+	stdin, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	oldStdin := os.Stdin
+	os.Stdin = stdin
+	t.Cleanup(func() { os.Stdin = oldStdin })
+	// The client disconnects right away.
+	w.Close()
+
+	done := make(chan error, 1)
+	go func() { done <- runMcpServer(context.Background(), "") }()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the server to return once stdin closed, with the embedding worker running")
+	}
+	if deferEmbeddings.Load() {
+		t.Fatal("expected the embedding worker stopped")
+	}
+}
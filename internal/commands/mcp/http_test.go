@@ -0,0 +1,113 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// bearerTransport sends token with every request.
+type bearerTransport struct {
+	token string
+}
+
+func (t bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestHTTPHandlerRequiresToken(t *testing.T) {
+	useMockProvider(t, nil)
+	ts := httptest.NewServer(httpHandler(newServer(), "s3cret"))
+	defer ts.Close()
+
+	for _, header := range []string{"", "Bearer wrong", "Basic s3cret"} {
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/mcp", strings.NewReader(`{}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("Authorization %q: expected 401, got %d", header, resp.StatusCode)
+		}
+	}
+
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "0"}, nil)
+	session, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+		Endpoint:   ts.URL + "/mcp",
+		HTTPClient: &http.Client{Transport: bearerTransport{token: "s3cret"}},
+		MaxRetries: -1,
+	}, nil)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer session.Close()
+
+	if _, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "memory_save",
+		Arguments: map[string]any{"text": "The user deploys with Argo"},
+	}); err != nil {
+		t.Fatalf("memory_save: %v", err)
+	}
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "memory_list", Arguments: map[string]any{}})
+	if err != nil {
+		t.Fatalf("memory_list: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("memory_list failed: %+v", result.Content)
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	if !strings.Contains(text, "deploys with Argo") {
+		t.Fatalf("expected the saved memory over HTTP, got %s", text)
+	}
+}
+
+func TestHTTPHandlerWithoutToken(t *testing.T) {
+	ts := httptest.NewServer(httpHandler(newServer(), ""))
+	defer ts.Close()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "0"}, nil)
+	session, err := client.Connect(context.Background(), &mcp.SSEClientTransport{Endpoint: ts.URL + "/sse"}, nil)
+	if err != nil {
+		t.Fatalf("connect over SSE: %v", err)
+	}
+	defer session.Close()
+
+	tools, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("list tools: %v", err)
+	}
+	if len(tools.Tools) == 0 {
+		t.Fatal("expected the memory tools over SSE")
+	}
+}
+
+func TestCheckExposure(t *testing.T) {
+	for _, addr := range []string{"127.0.0.1:8080", "localhost:8080", "[::1]:8080"} {
+		if err := checkExposure(addr, ""); err != nil {
+			t.Errorf("%s: expected a loopback address to be served without a token, got %v", addr, err)
+		}
+	}
+	for _, addr := range []string{":8080", "0.0.0.0:8080", "192.168.1.5:8080"} {
+		err := checkExposure(addr, "")
+		if err == nil || !strings.Contains(err.Error(), "mcp.http_token") {
+			t.Errorf("%s: expected serving without a token to be refused, got %v", addr, err)
+		}
+		if err := checkExposure(addr, "s3cret"); err != nil {
+			t.Errorf("%s: expected a token to allow any address, got %v", addr, err)
+		}
+	}
+}
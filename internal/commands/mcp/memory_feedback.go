@@ -0,0 +1,45 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type MemoryFeedbackInput struct {
+	ID      string `json:"id" jsonschema:"the id of a retrieved memory"`
+	Verdict string `json:"verdict" jsonschema:"useful if the memory helped, wrong if it was wrong or unhelpful"`
+}
+
+type MemoryFeedbackOutput struct {
+	Message    string  `json:"message" jsonschema:"feedback result message"`
+	ID         string  `json:"id" jsonschema:"the memory id"`
+	Confidence float64 `json:"confidence" jsonschema:"the memory's confidence after the feedback"`
+	Useful     int     `json:"useful" jsonschema:"useful votes so far"`
+	Wrong      int     `json:"wrong" jsonschema:"wrong votes so far"`
+}
+
+func handleMemoryFeedback(ctx context.Context, request *mcp.CallToolRequest, input MemoryFeedbackInput) (*mcp.CallToolResult, MemoryFeedbackOutput, error) {
+	_ = request
+
+	verdict := strings.ToLower(strings.TrimSpace(input.Verdict))
+	if verdict != "useful" && verdict != "wrong" {
+		return nil, MemoryFeedbackOutput{}, fmt.Errorf("parameter 'verdict' must be 'useful' or 'wrong'")
+	}
+
+	result, err := memoryservice.Feedback(ctx, memoryservice.FeedbackInput{ID: input.ID, Useful: verdict == "useful"})
+	if err != nil {
+		return nil, MemoryFeedbackOutput{}, err
+	}
+
+	return nil, MemoryFeedbackOutput{
+		Message:    fmt.Sprintf("Memory marked as %s; confidence %.2f -> %.2f.", verdict, result.PreviousConfidence, result.Confidence),
+		ID:         result.MemoryID,
+		Confidence: result.Confidence,
+		Useful:     result.Useful,
+		Wrong:      result.Wrong,
+	}, nil
+}
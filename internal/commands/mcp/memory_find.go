@@ -0,0 +1,53 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type MemoryFindInput struct {
+	Query string `json:"query" jsonschema:"search with qualifiers tag:NAME -tag:NAME source:explicit|extracted|document|imported kind:fact|preference|document-chunk|episodic before:DATE after:DATE (YYYY, YYYY-MM, or YYYY-MM-DD) plus words and \"quoted phrases\" that must all appear"`
+	Limit int    `json:"limit,omitempty" jsonschema:"the most memories to return (default 50)"`
+}
+
+type MemoryFindOutput struct {
+	Matches []MemoryFindMatch `json:"matches" jsonschema:"the matching memories, by text relevance or newest first"`
+}
+
+type MemoryFindMatch struct {
+	ID        string   `json:"id" jsonschema:"memory id"`
+	Text      string   `json:"text" jsonschema:"memory text"`
+	Tags      []string `json:"tags,omitempty" jsonschema:"memory tags"`
+	Kind      string   `json:"kind,omitempty" jsonschema:"memory kind"`
+	Source    string   `json:"source" jsonschema:"how the memory was created"`
+	CreatedAt string   `json:"created_at" jsonschema:"when the memory was saved, RFC 3339"`
+	Snippet   string   `json:"snippet,omitempty" jsonschema:"the part of the memory that matched, between >>> and <<<"`
+}
+
+// handleMemoryFind handles the memory_find tool call: an exact listing of the
+// memories matching a query, without embedding or ranking by relevance.
+func handleMemoryFind(ctx context.Context, request *mcp.CallToolRequest, input MemoryFindInput) (*mcp.CallToolResult, MemoryFindOutput, error) {
+	_ = request
+
+	result, err := memoryservice.Find(ctx, memoryservice.FindInput{Query: input.Query, Limit: input.Limit})
+	if err != nil {
+		return nil, MemoryFindOutput{}, err
+	}
+
+	output := MemoryFindOutput{Matches: make([]MemoryFindMatch, 0, len(result.Matches))}
+	for _, match := range result.Matches {
+		output.Matches = append(output.Matches, MemoryFindMatch{
+			ID:        match.Item.ID,
+			Text:      match.Item.Text,
+			Tags:      match.Item.Tags,
+			Kind:      string(match.Item.Kind),
+			Source:    string(match.Item.Source),
+			CreatedAt: match.Item.CreatedAt.Format(time.RFC3339),
+			Snippet:   match.Snippet,
+		})
+	}
+	return nil, output, nil
+}
@@ -0,0 +1,64 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type MemoryListInput struct {
+	Tag    string `json:"tag,omitempty" jsonschema:"list only the memories with this tag"`
+	Limit  int    `json:"limit,omitempty" jsonschema:"the most memories to return (default 50, at most 500)"`
+	Offset int    `json:"offset,omitempty" jsonschema:"how many memories to skip, e.g. the next_offset of the previous page"`
+}
+
+type MemoryListOutput struct {
+	Memories   []MemoryListItem `json:"memories" jsonschema:"the memories on this page, newest first"`
+	Total      int              `json:"total" jsonschema:"how many memories there are on all pages"`
+	NextOffset int              `json:"next_offset,omitempty" jsonschema:"the offset of the next page; absent on the last page"`
+}
+
+type MemoryListItem struct {
+	ID            string   `json:"id" jsonschema:"memory id"`
+	Text          string   `json:"text" jsonschema:"memory text"`
+	Tags          []string `json:"tags,omitempty" jsonschema:"memory tags"`
+	Kind          string   `json:"kind,omitempty" jsonschema:"memory kind"`
+	Source        string   `json:"source" jsonschema:"how the memory was created"`
+	CreatedAt     string   `json:"created_at" jsonschema:"when the memory was saved, RFC 3339"`
+	Pinned        bool     `json:"pinned,omitempty" jsonschema:"whether the memory is included in every retrieval"`
+	Suppressed    bool     `json:"suppressed,omitempty" jsonschema:"whether the memory is kept but never retrieved"`
+	PendingReview bool     `json:"pending_review,omitempty" jsonschema:"whether the memory awaits human review before it can be retrieved"`
+}
+
+// handleMemoryList handles the memory_list tool call: one page of the stored
+// memories, newest first.
+func handleMemoryList(ctx context.Context, request *mcp.CallToolRequest, input MemoryListInput) (*mcp.CallToolResult, MemoryListOutput, error) {
+	_ = request
+
+	result, err := memoryservice.List(ctx, memoryservice.ListInput{Tag: input.Tag, Limit: input.Limit, Offset: input.Offset})
+	if err != nil {
+		return nil, MemoryListOutput{}, err
+	}
+
+	output := MemoryListOutput{
+		Memories:   make([]MemoryListItem, 0, len(result.Memories)),
+		Total:      result.Total,
+		NextOffset: result.NextOffset,
+	}
+	for _, item := range result.Memories {
+		output.Memories = append(output.Memories, MemoryListItem{
+			ID:            item.ID,
+			Text:          item.Text,
+			Tags:          item.Tags,
+			Kind:          string(item.Kind),
+			Source:        string(item.Source),
+			CreatedAt:     item.CreatedAt.Format(time.RFC3339),
+			Pinned:        item.Pinned,
+			Suppressed:    item.Suppressed,
+			PendingReview: item.PendingReview,
+		})
+	}
+	return nil, output, nil
+}
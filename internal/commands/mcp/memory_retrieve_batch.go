@@ -0,0 +1,85 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/austiecodes/gomor/internal/utils"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// MemoryRetrieveBatchInput defines the input schema for the batch memory retrieve tool
+type MemoryRetrieveBatchInput struct {
+	Queries            []string `json:"queries" jsonschema:"the queries to search for related memories, one per topic (at most 10)"`
+	SessionID          string   `json:"session_id,omitempty" jsonschema:"optional conversation session id used to resolve follow-up queries"`
+	Strict             *bool    `json:"strict,omitempty" jsonschema:"fail instead of returning partial results when a retrieval path fails; defaults to the strict_retrieval config"`
+	ExcludeTags        string   `json:"exclude_tags,omitempty" jsonschema:"comma-separated tags whose memories are left out of the results"`
+	FTSStrategy        string   `json:"fts_strategy,omitempty" jsonschema:"full-text search strategy for these queries: direct, summary, or auto; defaults to the fts_strategy config"`
+	FusionVectorWeight float64  `json:"fusion_vector_weight,omitempty" jsonschema:"share of vector similarity, between 0 and 1, in the fused score for these queries; defaults to the fusion_vector_weight config"`
+}
+
+// MemoryRetrieveBatchOutput defines the output schema for the batch memory retrieve tool
+type MemoryRetrieveBatchOutput struct {
+	Groups   []MemoryRetrieveGroup `json:"groups" jsonschema:"the memories retrieved for each query, in the order of the queries"`
+	Degraded bool                  `json:"degraded,omitempty" jsonschema:"true when a retrieval path failed and results may be incomplete"`
+	Warnings []string              `json:"warnings,omitempty" jsonschema:"why a retrieval path failed"`
+}
+
+type MemoryRetrieveGroup struct {
+	Query   string                `json:"query" jsonschema:"the query these memories were retrieved for"`
+	Results string                `json:"results" jsonschema:"formatted text containing retrieved memories"`
+	Matches []MemoryRetrieveMatch `json:"matches,omitempty" jsonschema:"structured retrieved memories"`
+}
+
+// handleMemoryRetrieveBatch handles the memory_retrieve_batch tool call. Each
+// memory is listed once, under the query it is most relevant to.
+func handleMemoryRetrieveBatch(ctx context.Context, request *mcp.CallToolRequest, input MemoryRetrieveBatchInput) (*mcp.CallToolResult, MemoryRetrieveBatchOutput, error) {
+	result, err := retrieveAll(ctx, memoryservice.RetrieveBatchInput{
+		Queries:            input.Queries,
+		SessionID:          input.SessionID,
+		Strict:             input.Strict,
+		ExcludeTags:        splitTags(input.ExcludeTags),
+		FTSStrategy:        input.FTSStrategy,
+		FusionVectorWeight: input.FusionVectorWeight,
+		Command:            "mcp",
+	})
+	if err != nil {
+		return nil, MemoryRetrieveBatchOutput{}, withGuidance(err)
+	}
+
+	output := MemoryRetrieveBatchOutput{Groups: make([]MemoryRetrieveGroup, 0, len(result.Results))}
+	seen := make(map[string]bool)
+	for _, r := range result.Results {
+		output.Groups = append(output.Groups, MemoryRetrieveGroup{
+			Query:   r.Response.Query,
+			Results: r.Text,
+			Matches: buildRetrieveMatches(r.Response),
+		})
+		output.Degraded = output.Degraded || r.Response.Degraded
+		// Every query reports the same failed path; list each reason once.
+		for _, w := range r.Response.Warnings {
+			if !seen[w] {
+				seen[w] = true
+				output.Warnings = append(output.Warnings, w)
+			}
+		}
+	}
+	return nil, output, nil
+}
+
+// retrieveAll is retrieve for several queries.
+func retrieveAll(ctx context.Context, input memoryservice.RetrieveBatchInput) (*memoryservice.RetrieveBatchResult, error) {
+	if retriever == nil {
+		return memoryservice.RetrieveAll(ctx, input)
+	}
+
+	config, err := utils.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := retriever.Refresh(config); err != nil {
+		return nil, err
+	}
+	return retriever.RetrieveAll(ctx, input)
+}
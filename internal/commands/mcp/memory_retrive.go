@@ -7,26 +7,36 @@ import (
 
 	"github.com/austiecodes/gomor/internal/memory/retrieval"
 	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/austiecodes/gomor/internal/utils"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 // MemoryRetrieveInput defines the input schema for the memory retrieve tool
 type MemoryRetrieveInput struct {
-	Query string `json:"query" jsonschema:"the query to search for related memories"`
+	Query              string  `json:"query" jsonschema:"the query to search for related memories"`
+	SessionID          string  `json:"session_id,omitempty" jsonschema:"optional conversation session id used to resolve follow-up queries"`
+	Strict             *bool   `json:"strict,omitempty" jsonschema:"fail instead of returning partial results when a retrieval path fails; defaults to the strict_retrieval config"`
+	ExcludeTags        string  `json:"exclude_tags,omitempty" jsonschema:"comma-separated tags whose memories are left out of the results"`
+	FTSStrategy        string  `json:"fts_strategy,omitempty" jsonschema:"full-text search strategy for this call: direct searches the query's own words (best for terse keyword queries), summary searches a summary of it (best for long questions), auto runs both; defaults to the fts_strategy config"`
+	FusionVectorWeight float64 `json:"fusion_vector_weight,omitempty" jsonschema:"share of vector similarity, between 0 and 1, in the score of memories found by both vector and full-text search for this call; defaults to the fusion_vector_weight config"`
 }
 
 // MemoryRetrieveOutput defines the output schema for the memory retrieve tool
 type MemoryRetrieveOutput struct {
-	Results string                `json:"results" jsonschema:"formatted text containing retrieved memories"`
-	Matches []MemoryRetrieveMatch `json:"matches,omitempty" jsonschema:"structured retrieved memories"`
+	Results  string                `json:"results" jsonschema:"formatted text containing retrieved memories"`
+	Matches  []MemoryRetrieveMatch `json:"matches,omitempty" jsonschema:"structured retrieved memories"`
+	Degraded bool                  `json:"degraded,omitempty" jsonschema:"true when a retrieval path failed and results may be incomplete"`
+	Warnings []string              `json:"warnings,omitempty" jsonschema:"why a retrieval path failed"`
 }
 
 type MemoryRetrieveMatch struct {
-	ID     string   `json:"id" jsonschema:"memory id"`
-	Text   string   `json:"text" jsonschema:"memory text"`
-	Tags   []string `json:"tags,omitempty" jsonschema:"memory tags"`
-	Score  float64  `json:"score" jsonschema:"final ranking score"`
-	Source string   `json:"source" jsonschema:"retrieval source"`
+	ID      string   `json:"id" jsonschema:"memory id"`
+	Text    string   `json:"text" jsonschema:"memory text"`
+	Tags    []string `json:"tags,omitempty" jsonschema:"memory tags"`
+	Kind    string   `json:"kind,omitempty" jsonschema:"memory kind"`
+	Score   float64  `json:"score" jsonschema:"final ranking score"`
+	Source  string   `json:"source" jsonschema:"retrieval source"`
+	Snippet string   `json:"snippet,omitempty" jsonschema:"the part of the memory that matched, between >>> and <<<"`
 }
 
 // handleMemoryRetrieve handles the goa_memory_retrieve tool call (unified hybrid search)
@@ -37,13 +47,23 @@ func handleMemoryRetrieve(ctx context.Context, request *mcp.CallToolRequest, inp
 		return nil, MemoryRetrieveOutput{}, fmt.Errorf("parameter 'query' must be a non-empty string")
 	}
 
-	result, err := memoryservice.Retrieve(ctx, memoryservice.RetrieveInput{Query: query})
+	result, err := retrieve(ctx, memoryservice.RetrieveInput{
+		Query:              query,
+		SessionID:          input.SessionID,
+		Strict:             input.Strict,
+		ExcludeTags:        splitTags(input.ExcludeTags),
+		FTSStrategy:        input.FTSStrategy,
+		FusionVectorWeight: input.FusionVectorWeight,
+		Command:            "mcp",
+	})
 	if err != nil {
-		return nil, MemoryRetrieveOutput{}, err
+		return nil, MemoryRetrieveOutput{}, withGuidance(err)
 	}
 	return nil, MemoryRetrieveOutput{
-		Results: result.Text,
-		Matches: buildRetrieveMatches(result.Response),
+		Results:  result.Text,
+		Matches:  buildRetrieveMatches(result.Response),
+		Degraded: result.Response.Degraded,
+		Warnings: result.Response.Warnings,
 	}, nil
 }
 
@@ -55,12 +75,32 @@ func buildRetrieveMatches(resp *retrieval.RetrievalResponse) []MemoryRetrieveMat
 	matches := make([]MemoryRetrieveMatch, 0, len(resp.Results))
 	for _, result := range resp.Results {
 		matches = append(matches, MemoryRetrieveMatch{
-			ID:     result.Item.ID,
-			Text:   result.Item.Text,
-			Tags:   result.Item.Tags,
-			Score:  result.Score,
-			Source: result.Source,
+			ID:      result.Item.ID,
+			Text:    result.Item.Text,
+			Tags:    result.Item.Tags,
+			Kind:    string(result.Item.Kind),
+			Score:   result.Score,
+			Source:  result.Source,
+			Snippet: result.Snippet,
 		})
 	}
 	return matches
 }
+
+// retrieve uses the server's retriever, refreshed with the current config,
+// when there is one. Refreshing reuses the provider clients unless the
+// providers or models changed.
+func retrieve(ctx context.Context, input memoryservice.RetrieveInput) (*memoryservice.RetrieveResult, error) {
+	if retriever == nil {
+		return memoryservice.Retrieve(ctx, input)
+	}
+
+	config, err := utils.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := retriever.Refresh(config); err != nil {
+		return nil, err
+	}
+	return retriever.Retrieve(ctx, input)
+}
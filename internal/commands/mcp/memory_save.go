@@ -5,20 +5,27 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
 	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 // MemorySaveInput defines the input schema for the memory save tool
 type MemorySaveInput struct {
-	Text string `json:"text" jsonschema:"the preference or fact to save"`
-	Tags string `json:"tags,omitempty" jsonschema:"comma-separated tags for categorization"`
+	Text       string `json:"text" jsonschema:"the preference or fact to save"`
+	Tags       string `json:"tags,omitempty" jsonschema:"comma-separated tags for categorization"`
+	Kind       string `json:"kind,omitempty" jsonschema:"memory kind: fact (default), preference, document-chunk, or episodic"`
+	Pinned     bool   `json:"pinned,omitempty" jsonschema:"always include this memory in retrieved context, for standing instructions"`
+	Supersedes string `json:"supersedes,omitempty" jsonschema:"comma-separated ids of retrieved memories this one corrects or replaces; they stop being retrieved"`
 }
 
 // MemorySaveOutput defines the output schema for the memory save tool
 type MemorySaveOutput struct {
-	Message string `json:"message" jsonschema:"success message with memory ID"`
-	ID      string `json:"id" jsonschema:"the ID of the saved memory"`
+	Message string   `json:"message" jsonschema:"success message with memory ID"`
+	ID      string   `json:"id" jsonschema:"the ID of the saved memory"`
+	Tags    []string `json:"tags,omitempty" jsonschema:"the memory's tags, including any proposed by auto-tagging"`
+	Pending bool     `json:"pending,omitempty" jsonschema:"whether the embedding is queued for the background worker"`
+	Review  bool     `json:"pending_review,omitempty" jsonschema:"whether the memory awaits human review before it can be retrieved"`
 }
 
 // handleMemorySave handles the memory_save tool call
@@ -30,26 +37,54 @@ func handleMemorySave(ctx context.Context, request *mcp.CallToolRequest, input M
 	}
 
 	// Extract tags (optional)
-	var tags []string
-	if input.Tags != "" {
-		for _, t := range strings.Split(input.Tags, ",") {
-			t = strings.TrimSpace(t)
-			if t != "" {
-				tags = append(tags, t)
-			}
-		}
-	}
+	tags := splitTags(input.Tags)
 
 	result, err := memoryservice.Save(ctx, memoryservice.SaveInput{
-		Text: text,
-		Tags: tags,
+		Text:       text,
+		Tags:       tags,
+		Source:     memtypes.SourceExtracted,
+		Kind:       memtypes.MemoryKind(strings.TrimSpace(input.Kind)),
+		Deferred:   deferEmbeddings.Load(),
+		Pinned:     input.Pinned,
+		Supersedes: splitTags(input.Supersedes),
 	})
 	if err != nil {
-		return nil, MemorySaveOutput{}, err
+		return nil, MemorySaveOutput{}, withGuidance(err)
+	}
+
+	message := fmt.Sprintf("Memory saved successfully (id: %s)", result.Item.ID)
+	if result.PendingErr != nil {
+		message += fmt.Sprintf("; embedding failed and was queued for retry: %v", result.PendingErr)
+	}
+
+	if result.AutoTagged {
+		message += fmt.Sprintf("; tagged %s", strings.Join(result.Item.Tags, ", "))
+	}
+	if input.Supersedes != "" {
+		message += "; the memories it supersedes will no longer be retrieved"
+	}
+	if result.Item.PendingReview {
+		message += "; it will be retrieved once approved with 'gomor memory review'"
 	}
 
 	return nil, MemorySaveOutput{
-		Message: fmt.Sprintf("Memory saved successfully (id: %s)", result.Item.ID),
+		Message: message,
 		ID:      result.Item.ID,
+		Tags:    result.Item.Tags,
+		Pending: result.Pending,
+		Review:  result.Item.PendingReview,
 	}, nil
 }
+
+// splitTags parses a comma-separated list of tags or ids, dropping empty
+// entries.
+func splitTags(input string) []string {
+	var tags []string
+	for _, t := range strings.Split(input, ",") {
+		t = strings.TrimSpace(t)
+		if t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
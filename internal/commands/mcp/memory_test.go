@@ -2,13 +2,33 @@ package mcp
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
 	"github.com/austiecodes/gomor/internal/memory/retrieval"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/mockprovider"
+	"github.com/austiecodes/gomor/internal/utils"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// useMockProvider serves every model from a mock API and keeps memories in a
+// temporary home directory, so the handlers run without API keys.
+func useMockProvider(t *testing.T, configure func(*utils.Config)) *mockprovider.Server {
+	t.Helper()
+	server := mockprovider.New(t)
+	config := server.Config()
+	if configure != nil {
+		configure(config)
+	}
+	mockprovider.Install(t, config)
+	return server
+}
+
 // TestHandleMemorySave_EmptyText tests that empty text returns an error
 func TestHandleMemorySave_EmptyText(t *testing.T) {
 	ctx := context.Background()
@@ -34,6 +54,7 @@ func TestHandleMemorySave_EmptyText(t *testing.T) {
 
 // TestHandleMemorySave_Success tests successful memory saving
 func TestHandleMemorySave_Success(t *testing.T) {
+	useMockProvider(t, nil)
 	ctx := context.Background()
 	request := &mcp.CallToolRequest{}
 
@@ -80,6 +101,21 @@ func TestHandleMemoryRetrieve_EmptyQuery(t *testing.T) {
 	}
 }
 
+func TestHandleMemoryRetrieve_InvalidOverrides(t *testing.T) {
+	useMockProvider(t, nil)
+	ctx := context.Background()
+	request := &mcp.CallToolRequest{}
+
+	_, _, err := handleMemoryRetrieve(ctx, request, MemoryRetrieveInput{Query: "deploys", FTSStrategy: "keywords"})
+	if err == nil || !strings.Contains(err.Error(), "valid: auto, direct, summary") {
+		t.Fatalf("expected an unknown strategy to be rejected, got %v", err)
+	}
+	_, _, err = handleMemoryRetrieve(ctx, request, MemoryRetrieveInput{Query: "deploys", FusionVectorWeight: 1.5})
+	if err == nil || !strings.Contains(err.Error(), "between 0 and 1") {
+		t.Fatalf("expected an out-of-range weight to be rejected, got %v", err)
+	}
+}
+
 func TestHandleMemoryDelete_EmptyID(t *testing.T) {
 	ctx := context.Background()
 	request := &mcp.CallToolRequest{}
@@ -93,6 +129,19 @@ func TestHandleMemoryDelete_EmptyID(t *testing.T) {
 	}
 }
 
+func TestHandleMemoryFeedback_InvalidVerdict(t *testing.T) {
+	ctx := context.Background()
+	request := &mcp.CallToolRequest{}
+
+	_, _, err := handleMemoryFeedback(ctx, request, MemoryFeedbackInput{ID: "mem-1", Verdict: "meh"})
+	if err == nil {
+		t.Fatal("expected error for invalid verdict, got nil")
+	}
+	if !strings.Contains(err.Error(), "'useful' or 'wrong'") {
+		t.Fatalf("unexpected error message: %v", err)
+	}
+}
+
 func TestBuildRetrieveMatches(t *testing.T) {
 	resp := &retrieval.RetrievalResponse{
 		Results: []retrieval.UnifiedResult{
@@ -122,6 +171,9 @@ func TestBuildRetrieveMatches(t *testing.T) {
 
 // TestHandleMemoryRetrieve_Success tests successful memory retrieval
 func TestHandleMemoryRetrieve_Success(t *testing.T) {
+	useMockProvider(t, func(config *utils.Config) {
+		config.Memory.AutoApproveExtracted = true
+	})
 	ctx := context.Background()
 	request := &mcp.CallToolRequest{}
 
@@ -146,13 +198,14 @@ func TestHandleMemoryRetrieve_Success(t *testing.T) {
 	t.Logf("Retrieved results:\n%s", retrieveOutput.Results)
 
 	// Results should not be empty (we just saved a matching memory)
-	if retrieveOutput.Results == "" {
-		t.Log("Warning: no results returned, but this may be due to embedding similarity threshold")
+	if !strings.Contains(retrieveOutput.Results, saveInput.Text) {
+		t.Fatalf("expected the saved memory to be retrieved, got: %s", retrieveOutput.Results)
 	}
 }
 
 // TestHandleMemorySave_TagsParsing tests tag parsing logic
 func TestHandleMemorySave_TagsParsing(t *testing.T) {
+	useMockProvider(t, nil)
 	ctx := context.Background()
 	request := &mcp.CallToolRequest{}
 
@@ -191,3 +244,310 @@ func TestHandleMemorySave_TagsParsing(t *testing.T) {
 		})
 	}
 }
+
+// TestHandleMemoryRetrieveBatch tests that results are grouped by query with
+// each memory listed once
+func TestHandleMemoryRetrieveBatch(t *testing.T) {
+	useMockProvider(t, func(config *utils.Config) {
+		config.Memory.AutoApproveExtracted = true
+	})
+	ctx := context.Background()
+	request := &mcp.CallToolRequest{}
+
+	for _, text := range []string{"The deploy pipeline runs on Argo CD", "The user prefers tabs over spaces"} {
+		if _, _, err := handleMemorySave(ctx, request, MemorySaveInput{Text: text}); err != nil {
+			t.Fatalf("failed to save test memory: %v", err)
+		}
+	}
+
+	_, output, err := handleMemoryRetrieveBatch(ctx, request, MemoryRetrieveBatchInput{Queries: []string{"deploy pipeline Argo", " ", "tabs spaces"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(output.Groups) != 2 || output.Groups[0].Query != "deploy pipeline Argo" || output.Groups[1].Query != "tabs spaces" {
+		t.Fatalf("expected one group per non-empty query in order, got %+v", output.Groups)
+	}
+
+	seen := make(map[string]int)
+	for _, group := range output.Groups {
+		for _, match := range group.Matches {
+			seen[match.ID]++
+		}
+	}
+	for id, n := range seen {
+		if n > 1 {
+			t.Fatalf("memory %s listed %d times", id, n)
+		}
+	}
+	if !strings.Contains(output.Groups[0].Results, "Argo CD") {
+		t.Fatalf("expected the deploy memory under its query, got: %s", output.Groups[0].Results)
+	}
+
+	if _, _, err := handleMemoryRetrieveBatch(ctx, request, MemoryRetrieveBatchInput{}); err == nil {
+		t.Fatal("expected an error without queries")
+	}
+}
+
+// TestHandleMemoryFind tests that qualifiers filter the memories and quoted
+// phrases must appear in them
+func TestHandleMemoryFind(t *testing.T) {
+	useMockProvider(t, func(config *utils.Config) {
+		config.Memory.AutoApproveExtracted = true
+	})
+	ctx := context.Background()
+	request := &mcp.CallToolRequest{}
+
+	saves := []MemorySaveInput{
+		{Text: "Go services use table tests for every handler", Tags: "go, testing"},
+		{Text: "Python services use pytest fixtures for unit tests", Tags: "python, testing"},
+		{Text: "Go modules are vendored in the monorepo", Tags: "go"},
+	}
+	for _, input := range saves {
+		if _, _, err := handleMemorySave(ctx, request, input); err != nil {
+			t.Fatalf("failed to save test memory: %v", err)
+		}
+	}
+
+	_, output, err := handleMemoryFind(ctx, request, MemoryFindInput{Query: `tag:go "table tests"`})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(output.Matches) != 1 || !strings.HasPrefix(output.Matches[0].Text, "Go services use table tests") {
+		t.Fatalf("expected only the Go testing memory, got %+v", output.Matches)
+	}
+
+	_, output, err = handleMemoryFind(ctx, request, MemoryFindInput{Query: "tag:testing -tag:python source:extracted"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(output.Matches) != 1 || output.Matches[0].Source != "extracted" {
+		t.Fatalf("expected the tag filters to leave one memory, got %+v", output.Matches)
+	}
+
+	_, output, err = handleMemoryFind(ctx, request, MemoryFindInput{Query: "tag:go before:2000", Limit: 1})
+	if err != nil || len(output.Matches) != 0 {
+		t.Fatalf("expected no memories saved before 2000, got %+v (%v)", output.Matches, err)
+	}
+
+	if _, _, err := handleMemoryFind(ctx, request, MemoryFindInput{Query: "source:somewhere"}); err == nil || !strings.Contains(err.Error(), "unknown memory source") {
+		t.Fatalf("expected an error for an unknown source, got %v", err)
+	}
+}
+
+// TestHandleMemorySave_Supersedes tests that a superseded memory is related
+// to its replacement and no longer retrieved
+func TestHandleMemorySave_Supersedes(t *testing.T) {
+	useMockProvider(t, func(config *utils.Config) {
+		config.Memory.AutoApproveExtracted = true
+	})
+	ctx := context.Background()
+	request := &mcp.CallToolRequest{}
+
+	_, old, err := handleMemorySave(ctx, request, MemorySaveInput{Text: "Deploys go out on Fridays"})
+	if err != nil {
+		t.Fatalf("failed to save test memory: %v", err)
+	}
+	_, current, err := handleMemorySave(ctx, request, MemorySaveInput{Text: "Deploys go out on Tuesdays", Supersedes: old.ID})
+	if err != nil {
+		t.Fatalf("failed to save superseding memory: %v", err)
+	}
+
+	relations, err := memoryservice.Relations(ctx, old.ID)
+	if err != nil {
+		t.Fatalf("failed to load relations: %v", err)
+	}
+	if len(relations) != 1 || relations[0].FromID != current.ID || relations[0].Kind != memtypes.RelationSupersedes {
+		t.Fatalf("expected the new memory to supersede the old one, got %+v", relations)
+	}
+
+	_, found, err := handleMemoryFind(ctx, request, MemoryFindInput{Query: "Deploys"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, match := range found.Matches {
+		if match.ID == old.ID {
+			t.Fatalf("expected the superseded memory to be suppressed, got %+v", found.Matches)
+		}
+	}
+
+	if _, _, err := handleMemorySave(ctx, request, MemorySaveInput{Text: "Deploys need approval", Supersedes: "missing"}); err == nil {
+		t.Fatal("expected an error superseding a missing memory")
+	}
+}
+
+func TestHandleMemorySave_ChunksLongText(t *testing.T) {
+	useMockProvider(t, func(config *utils.Config) {
+		config.Memory.AutoApproveExtracted = true
+		config.Memory.ChunkTokens = 12
+	})
+	ctx := context.Background()
+	request := &mcp.CallToolRequest{}
+
+	text := "Deploys go out on Tuesdays after the weekly planning meeting. " +
+		"The staging cluster runs postgres on three dedicated nodes."
+	_, saved, err := handleMemorySave(ctx, request, MemorySaveInput{Text: text})
+	if err != nil {
+		t.Fatalf("failed to save long memory: %v", err)
+	}
+
+	_, found, err := handleMemoryFind(ctx, request, MemoryFindInput{Query: "postgres"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(found.Matches) != 1 || found.Matches[0].ID != saved.ID {
+		t.Fatalf("expected only the whole memory to be listed, got %+v", found.Matches)
+	}
+
+	_, retrieved, err := handleMemoryRetrieve(ctx, request, MemoryRetrieveInput{Query: "staging cluster postgres nodes"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(retrieved.Results, "Found 1 memories") || !strings.Contains(retrieved.Results, text) {
+		t.Fatalf("expected the chunks to retrieve the whole memory once, got: %s", retrieved.Results)
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer memStore.Close()
+	memories, err := memStore.SearchableMemories()
+	if err != nil {
+		t.Fatalf("searchable memories: %v", err)
+	}
+	chunks := 0
+	for _, m := range memories {
+		if m.ParentID == saved.ID && m.Dim > 0 {
+			chunks++
+		}
+	}
+	if chunks != 2 {
+		t.Fatalf("expected 2 embedded chunks, got %d of %d rows", chunks, len(memories))
+	}
+}
+
+func TestHandleMemoryUpdate(t *testing.T) {
+	useMockProvider(t, func(config *utils.Config) {
+		config.Memory.AutoApproveExtracted = true
+		config.Memory.ChunkTokens = 12
+	})
+	ctx := context.Background()
+	request := &mcp.CallToolRequest{}
+
+	_, saved, err := handleMemorySave(ctx, request, MemorySaveInput{Text: "Deploys go out on Fridays", Tags: "deploys"})
+	if err != nil {
+		t.Fatalf("failed to save test memory: %v", err)
+	}
+
+	text := "Deploys go out on Tuesdays after the weekly planning meeting. " +
+		"The staging cluster runs postgres on three dedicated nodes."
+	_, updated, err := handleMemoryUpdate(ctx, request, MemoryUpdateInput{ID: saved.ID, Text: text})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !updated.Updated || updated.Pending || updated.Text != text || len(updated.Tags) != 1 {
+		t.Fatalf("expected the text replaced and re-embedded, keeping the tags, got %+v", updated)
+	}
+
+	_, found, err := handleMemoryFind(ctx, request, MemoryFindInput{Query: "postgres"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(found.Matches) != 1 || found.Matches[0].ID != saved.ID {
+		t.Fatalf("expected the updated memory found under its id, got %+v", found.Matches)
+	}
+
+	tags := "ops, staging"
+	if _, updated, err = handleMemoryUpdate(ctx, request, MemoryUpdateInput{ID: saved.ID, Tags: &tags}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !updated.Updated || strings.Join(updated.Tags, ",") != "ops,staging" {
+		t.Fatalf("expected the tags replaced, got %+v", updated)
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer memStore.Close()
+	memories, err := memStore.SearchableMemories()
+	if err != nil {
+		t.Fatalf("searchable memories: %v", err)
+	}
+	chunks := 0
+	for _, m := range memories {
+		if m.ParentID == saved.ID {
+			chunks++
+			if m.Dim == 0 || strings.Join(m.Tags, ",") != "ops,staging" {
+				t.Fatalf("expected the chunks re-saved with the new tags and embedded, got %+v", m)
+			}
+		}
+	}
+	if chunks != 2 {
+		t.Fatalf("expected 2 chunks of the new text, got %d of %d rows", chunks, len(memories))
+	}
+
+	if _, updated, err = handleMemoryUpdate(ctx, request, MemoryUpdateInput{ID: saved.ID, Text: text}); err != nil || updated.Updated {
+		t.Fatalf("expected an unchanged memory to be left alone, got %+v (%v)", updated, err)
+	}
+	if _, _, err := handleMemoryUpdate(ctx, request, MemoryUpdateInput{ID: saved.ID}); err == nil {
+		t.Fatal("expected an error when neither text nor tags are given")
+	}
+	if _, _, err := handleMemoryUpdate(ctx, request, MemoryUpdateInput{ID: "missing", Text: "x"}); !errors.Is(err, store.ErrMemoryNotFound) {
+		t.Fatalf("expected a missing memory to be reported, got %v", err)
+	}
+}
+
+func TestHandleMemoryList(t *testing.T) {
+	useMockProvider(t, func(config *utils.Config) {
+		config.Memory.TagAliases = map[string]string{"golang": "go"}
+	})
+	ctx := context.Background()
+	request := &mcp.CallToolRequest{}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, item := range []*memtypes.MemoryItem{
+		{Text: "Go services use table tests", Tags: []string{"go"}},
+		{Text: "Python services use pytest", Tags: []string{"python"}},
+		{Text: "Go modules are vendored", Tags: []string{"golang"}},
+		{Text: "Deploys go out on Tuesdays"},
+	} {
+		item.Source = memtypes.SourceExplicit
+		item.CreatedAt = start.AddDate(0, 0, i)
+		if err := memStore.SaveMemory(item); err != nil {
+			t.Fatalf("save memory: %v", err)
+		}
+	}
+	memStore.Close()
+
+	_, page, err := handleMemoryList(ctx, request, MemoryListInput{Limit: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if page.Total != 4 || page.NextOffset != 3 || len(page.Memories) != 3 || page.Memories[0].Text != "Deploys go out on Tuesdays" {
+		t.Fatalf("expected the 3 newest of 4 memories, got %+v", page)
+	}
+	_, page, err = handleMemoryList(ctx, request, MemoryListInput{Limit: 3, Offset: page.NextOffset})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page.Memories) != 1 || page.NextOffset != 0 || page.Memories[0].Text != "Go services use table tests" {
+		t.Fatalf("expected the oldest memory on the last page, got %+v", page)
+	}
+
+	_, page, err = handleMemoryList(ctx, request, MemoryListInput{Tag: "go"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if page.Total != 2 || len(page.Memories) != 2 || page.Memories[0].Text != "Go modules are vendored" {
+		t.Fatalf("expected the memories tagged go or its alias golang, got %+v", page)
+	}
+
+	if _, _, err := handleMemoryList(ctx, request, MemoryListInput{Offset: -1}); err == nil {
+		t.Fatal("expected an error for a negative offset")
+	}
+}
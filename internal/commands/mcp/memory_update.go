@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type MemoryUpdateInput struct {
+	ID   string  `json:"id" jsonschema:"the memory id to update"`
+	Text string  `json:"text,omitempty" jsonschema:"the memory's new text; omit to keep it"`
+	Tags *string `json:"tags,omitempty" jsonschema:"comma-separated tags replacing the memory's tags; omit to keep them, or pass an empty string to clear them"`
+}
+
+type MemoryUpdateOutput struct {
+	Message string   `json:"message" jsonschema:"update result message"`
+	ID      string   `json:"id" jsonschema:"the memory id"`
+	Text    string   `json:"text" jsonschema:"the memory's text after the update"`
+	Tags    []string `json:"tags,omitempty" jsonschema:"the memory's tags after the update"`
+	Updated bool     `json:"updated" jsonschema:"whether the text or tags changed"`
+	Pending bool     `json:"pending,omitempty" jsonschema:"whether the new embedding is queued for the background worker"`
+}
+
+func handleMemoryUpdate(ctx context.Context, request *mcp.CallToolRequest, input MemoryUpdateInput) (*mcp.CallToolResult, MemoryUpdateOutput, error) {
+	_ = request
+
+	if strings.TrimSpace(input.Text) == "" && input.Tags == nil {
+		return nil, MemoryUpdateOutput{}, fmt.Errorf("pass 'text', 'tags', or both to update")
+	}
+	update := memoryservice.UpdateInput{ID: input.ID, Text: input.Text}
+	if input.Tags != nil {
+		update.Tags = splitTags(*input.Tags)
+		if update.Tags == nil {
+			update.Tags = []string{}
+		}
+	}
+
+	result, err := memoryservice.Update(ctx, update)
+	if err != nil {
+		return nil, MemoryUpdateOutput{}, withGuidance(err)
+	}
+
+	message := "Memory updated successfully."
+	switch {
+	case !result.Updated:
+		message = "Memory unchanged."
+	case result.Pending:
+		message += " Its embedding was queued; until then it is found by full-text search only."
+	}
+
+	return nil, MemoryUpdateOutput{
+		Message: message,
+		ID:      result.ID,
+		Text:    result.Text,
+		Tags:    result.Tags,
+		Updated: result.Updated,
+		Pending: result.Pending,
+	}, nil
+}
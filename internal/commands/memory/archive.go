@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/decay"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/spf13/cobra"
+)
+
+var (
+	archiveMemoryFn = memoryservice.Archive
+	restoreMemoryFn = memoryservice.Restore
+)
+
+type memoryArchiveItem struct {
+	ID         string  `json:"id"`
+	Text       string  `json:"text"`
+	Confidence float64 `json:"confidence"`
+	LastUsed   string  `json:"last_used"`
+}
+
+type memoryArchiveOutput struct {
+	Message         string              `json:"message"`
+	AfterDays       int                 `json:"after_days"`
+	BelowConfidence float64             `json:"below_confidence"`
+	DryRun          bool                `json:"dry_run,omitempty"`
+	Archived        int                 `json:"archived"`
+	Memories        []memoryArchiveItem `json:"memories"`
+}
+
+type memoryRestoreOutput struct {
+	Message  string `json:"message"`
+	ID       string `json:"id"`
+	Restored bool   `json:"restored"`
+}
+
+func newArchiveCommand() *cobra.Command {
+	var days int
+	var belowConfidence float64
+	var restoreID string
+	var dryRun, jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Move unused, low-confidence memories out of search",
+		Long: `Archive memories that have not been retrieved for --days days (counted from
+when they were saved if never retrieved) and whose confidence is below
+--below-confidence. Archived memories are no longer searched, which keeps
+retrieval fast, but are kept in the database and included by
+'gomor export --include-archived'. Pinned memories and memories awaiting
+review are never archived.
+
+The policy defaults to memory.archive_after_days and
+memory.archive_below_confidence. --restore moves a memory back.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if restoreID != "" {
+				if cmd.Flags().Changed("days") || cmd.Flags().Changed("below-confidence") || dryRun {
+					return fmt.Errorf("--restore cannot be combined with --days, --below-confidence, or --dry-run")
+				}
+				return runRestore(cmd, restoreID, jsonOutput)
+			}
+			return runArchive(cmd, memoryservice.ArchiveInput{
+				AfterDays:       days,
+				BelowConfidence: belowConfidence,
+				DryRun:          dryRun,
+			}, jsonOutput)
+		},
+	}
+
+	cmd.Flags().IntVar(&days, "days", 0, "archive memories not retrieved for this many days")
+	cmd.Flags().Float64Var(&belowConfidence, "below-confidence", 0, "archive only memories with confidence below this (0-1)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "list the memories that would be archived without moving them")
+	cmd.Flags().StringVar(&restoreID, "restore", "", "move the archived memory with this ID back into search")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "emit structured JSON output")
+	return cmd
+}
+
+func runArchive(cmd *cobra.Command, input memoryservice.ArchiveInput, jsonOutput bool) error {
+	result, err := archiveMemoryFn(cmd.Context(), input)
+	if err != nil {
+		return err
+	}
+
+	output := memoryArchiveOutput{
+		AfterDays:       result.AfterDays,
+		BelowConfidence: result.BelowConfidence,
+		DryRun:          input.DryRun,
+		Archived:        result.Archived,
+		Memories:        make([]memoryArchiveItem, 0, len(result.Memories)),
+	}
+	for _, m := range result.Memories {
+		output.Memories = append(output.Memories, memoryArchiveItem{
+			ID:         m.ID,
+			Text:       m.Text,
+			Confidence: m.Confidence,
+			LastUsed:   decay.EffectiveLastRetrievedAt(m).Format(time.RFC3339),
+		})
+	}
+	policy := fmt.Sprintf("not retrieved in %d days with confidence below %.2f", result.AfterDays, result.BelowConfidence)
+	switch {
+	case len(result.Memories) == 0:
+		output.Message = "No memories " + policy
+	case input.DryRun:
+		output.Message = fmt.Sprintf("Would archive %d memories %s", len(result.Memories), policy)
+	default:
+		output.Message = fmt.Sprintf("Archived %d memories %s", result.Archived, policy)
+	}
+
+	if jsonOutput {
+		return writeJSON(cmd.OutOrStdout(), output)
+	}
+	if _, err := fmt.Fprintln(cmd.OutOrStdout(), output.Message); err != nil {
+		return err
+	}
+	for i, item := range output.Memories {
+		if _, err := fmt.Fprintf(cmd.OutOrStdout(), "%d. %s\n   ID: %s  confidence %.2f  last used %s\n",
+			i+1, item.Text, item.ID, item.Confidence, item.LastUsed[:len("2006-01-02")]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runRestore(cmd *cobra.Command, id string, jsonOutput bool) error {
+	result, err := restoreMemoryFn(cmd.Context(), memoryservice.RestoreInput{ID: id})
+	if err != nil {
+		return err
+	}
+
+	output := memoryRestoreOutput{ID: result.ID, Restored: result.Restored}
+	output.Message = fmt.Sprintf("Restored memory %s", result.ID)
+	if !result.Restored {
+		output.Message = fmt.Sprintf("No archived memory with ID %s", result.ID)
+	}
+
+	if jsonOutput {
+		return writeJSON(cmd.OutOrStdout(), output)
+	}
+	_, err = fmt.Fprintln(cmd.OutOrStdout(), output.Message)
+	return err
+}
@@ -1,11 +1,16 @@
 package memory
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 
+	"github.com/austiecodes/gomor/internal/exitcode"
+	"github.com/austiecodes/gomor/internal/memory/memquery"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
 	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
@@ -14,7 +19,12 @@ import (
 var (
 	saveMemoryFn         = memoryservice.Save
 	queryMemoryFn        = memoryservice.Retrieve
+	findMemoryFn         = memoryservice.Find
 	deleteMemoryFn       = memoryservice.Delete
+	pinMemoryFn          = memoryservice.Pin
+	suppressMemoryFn     = memoryservice.Suppress
+	entityMemoryFn       = memoryservice.Entity
+	clearMemoryFn        = memoryservice.Clear
 	runInteractiveMemory = func() error {
 		p := tea.NewProgram(initialModel(), tea.WithAltScreen())
 		if _, err := p.Run(); err != nil {
@@ -27,27 +37,83 @@ var (
 type memoryCommandOptions struct {
 	saveText   string
 	queryText  string
+	findText   string
+	limit      int
 	deleteID   string
+	pinID      string
+	unpinID    string
+	pinned     bool
+	suppressID string
+	allowID    string
+	exclude    string
+	entity     string
+	clear      string
+	dryRun     bool
+	yes        bool
+	kind       string
 	tags       string
+	strict     bool
+	strategy   string
+	weight     float64
 	jsonOutput bool
 }
 
 type memoryQueryMatch struct {
-	ID     string   `json:"id"`
-	Text   string   `json:"text"`
-	Tags   []string `json:"tags,omitempty"`
-	Score  float64  `json:"score"`
-	Source string   `json:"source"`
+	ID      string   `json:"id"`
+	Text    string   `json:"text"`
+	Tags    []string `json:"tags,omitempty"`
+	Kind    string   `json:"kind,omitempty"`
+	Score   float64  `json:"score"`
+	Source  string   `json:"source"`
+	Snippet string   `json:"snippet,omitempty"`
 }
 
 type memorySaveOutput struct {
+	Message string   `json:"message"`
+	ID      string   `json:"id"`
+	Tags    []string `json:"tags,omitempty"`
+	Pending bool     `json:"pending,omitempty"`
+}
+
+type memoryQueryOutput struct {
+	Results  string             `json:"results"`
+	Matches  []memoryQueryMatch `json:"matches,omitempty"`
+	Degraded bool               `json:"degraded,omitempty"`
+	Warnings []string           `json:"warnings,omitempty"`
+}
+
+type memoryFindOutput struct {
+	Query   string             `json:"query"`
+	Matches []memoryQueryMatch `json:"matches"`
+}
+
+type memoryEntityOutput struct {
+	Entity  string             `json:"entity"`
+	Matches []memoryQueryMatch `json:"matches"`
+}
+
+type memoryClearOutput struct {
+	Message    string `json:"message"`
+	Target     string `json:"target"`
+	Memories   int    `json:"memories"`
+	History    int    `json:"history"`
+	BackupPath string `json:"backup_path,omitempty"`
+	Cleared    bool   `json:"cleared"`
+	DryRun     bool   `json:"dry_run,omitempty"`
+}
+
+type memoryPinOutput struct {
 	Message string `json:"message"`
 	ID      string `json:"id"`
+	Pinned  bool   `json:"pinned"`
+	Found   bool   `json:"found"`
 }
 
-type memoryQueryOutput struct {
-	Results string             `json:"results"`
-	Matches []memoryQueryMatch `json:"matches,omitempty"`
+type memorySuppressOutput struct {
+	Message    string `json:"message"`
+	ID         string `json:"id"`
+	Suppressed bool   `json:"suppressed"`
+	Found      bool   `json:"found"`
 }
 
 type memoryDeleteOutput struct {
@@ -62,9 +128,16 @@ func newMemoryCommand() *cobra.Command {
 	opts := &memoryCommandOptions{}
 
 	cmd := &cobra.Command{
-		Use:          "memory",
-		Short:        "Manage memories interactively",
-		Long:         `Open an interactive TUI to view, add, edit, and delete stored memories.`,
+		Use:   "memory",
+		Short: "Manage memories interactively",
+		Long: `Open an interactive TUI to view, add, edit, and delete stored memories.
+
+--find lists the memories matching an advanced search, without embedding
+the query or ranking it with the tool model. Qualifiers are ` + memquery.Syntax + `; the other words must all appear in a memory:
+
+  gomor memory --find 'tag:golang source:explicit before:2024-06 "unit test"'
+
+The same syntax filters the memory list in the TUI (press /).`,
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runMemoryCommand(cmd, opts)
@@ -73,24 +146,67 @@ func newMemoryCommand() *cobra.Command {
 
 	cmd.Flags().StringVar(&opts.saveText, "save", "", "save a memory without opening the TUI")
 	cmd.Flags().StringVar(&opts.queryText, "query", "", "retrieve memories without opening the TUI")
+	cmd.Flags().StringVar(&opts.findText, "find", "", "list memories matching an advanced search, e.g. 'tag:golang \"unit test\"'")
+	cmd.Flags().IntVar(&opts.limit, "limit", 0, "with --find, the most memories to list (default 50)")
 	cmd.Flags().StringVar(&opts.deleteID, "delete", "", "delete a memory by id without opening the TUI")
+	cmd.Flags().StringVar(&opts.pinID, "pin", "", "pin a memory by id so it is always included in retrieved context")
+	cmd.Flags().StringVar(&opts.unpinID, "unpin", "", "unpin a memory by id")
+	cmd.Flags().StringVar(&opts.suppressID, "suppress", "", "keep a memory by id but never retrieve it")
+	cmd.Flags().StringVar(&opts.allowID, "unsuppress", "", "let a suppressed memory be retrieved again")
+	cmd.Flags().StringVar(&opts.entity, "entity", "", "list all memories linked to an entity without opening the TUI")
+	cmd.Flags().StringVar(&opts.clear, "clear", "", "delete all memories, history, or all (backs up the database first)")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "with --clear, report what would be deleted without deleting")
+	cmd.Flags().BoolVarP(&opts.yes, "yes", "y", false, "with --clear, skip the typed confirmation")
+	cmd.Flags().StringVar(&opts.kind, "kind", "", "memory kind used with --save: fact, preference, document-chunk, or episodic")
 	cmd.Flags().StringVar(&opts.tags, "tags", "", "comma-separated tags used with --save")
+	cmd.Flags().BoolVar(&opts.pinned, "pinned", false, "with --save, pin the new memory")
+	cmd.Flags().StringVar(&opts.exclude, "exclude-tags", "", "with --query, comma-separated tags whose memories are left out")
+	cmd.Flags().BoolVar(&opts.strict, "strict", false, "with --query, fail if any retrieval path fails instead of returning partial results")
+	cmd.Flags().StringVar(&opts.strategy, "fts-strategy", "", "with --query, override memory.fts_strategy: auto, direct, or summary")
+	cmd.Flags().Float64Var(&opts.weight, "fusion-weight", 0, "with --query, override memory.fusion_vector_weight, the share of vector similarity in fused scores (0-1)")
 	cmd.Flags().BoolVar(&opts.jsonOutput, "json", false, "emit structured JSON output")
 
+	cmd.AddCommand(newArchiveCommand())
+	cmd.AddCommand(newFeedbackCommand())
+	cmd.AddCommand(newGraphCommand())
+	cmd.AddCommand(newRelateCommand())
+	cmd.AddCommand(newReviewCommand())
+
 	return cmd
 }
 
 func runMemoryCommand(cmd *cobra.Command, opts *memoryCommandOptions) error {
-	actionCount := countNonEmpty(opts.saveText, opts.queryText, opts.deleteID)
-	if actionCount == 0 {
+	actionCount := countNonEmpty(opts.saveText, opts.queryText, opts.findText, opts.deleteID, opts.pinID, opts.unpinID, opts.suppressID, opts.allowID, opts.entity, opts.clear)
+	if actionCount == 0 && !opts.pinned {
 		return runInteractiveMemory()
 	}
 	if actionCount > 1 {
-		return fmt.Errorf("--save, --query, and --delete are mutually exclusive")
+		return fmt.Errorf("--save, --query, --find, --delete, --pin, --unpin, --suppress, --unsuppress, --entity, and --clear are mutually exclusive")
+	}
+	if opts.pinned && opts.saveText == "" {
+		return fmt.Errorf("--pinned can only be used with --save")
 	}
 	if opts.tags != "" && opts.saveText == "" {
 		return fmt.Errorf("--tags can only be used with --save")
 	}
+	if opts.kind != "" && opts.saveText == "" {
+		return fmt.Errorf("--kind can only be used with --save")
+	}
+	if opts.strict && opts.queryText == "" {
+		return fmt.Errorf("--strict can only be used with --query")
+	}
+	if opts.exclude != "" && opts.queryText == "" {
+		return fmt.Errorf("--exclude-tags can only be used with --query")
+	}
+	if (opts.strategy != "" || opts.weight != 0) && opts.queryText == "" {
+		return fmt.Errorf("--fts-strategy and --fusion-weight can only be used with --query")
+	}
+	if opts.limit != 0 && opts.findText == "" {
+		return fmt.Errorf("--limit can only be used with --find")
+	}
+	if (opts.dryRun || opts.yes) && opts.clear == "" {
+		return fmt.Errorf("--dry-run and --yes can only be used with --clear")
+	}
 
 	ctx := cmd.Context()
 	if ctx == nil {
@@ -102,6 +218,16 @@ func runMemoryCommand(cmd *cobra.Command, opts *memoryCommandOptions) error {
 		return runSaveCommand(ctx, cmd.OutOrStdout(), opts)
 	case opts.queryText != "":
 		return runQueryCommand(ctx, cmd.OutOrStdout(), opts)
+	case opts.findText != "":
+		return runFindCommand(ctx, cmd.OutOrStdout(), opts)
+	case opts.pinID != "" || opts.unpinID != "":
+		return runPinCommand(ctx, cmd.OutOrStdout(), opts)
+	case opts.suppressID != "" || opts.allowID != "":
+		return runSuppressCommand(ctx, cmd.OutOrStdout(), opts)
+	case opts.entity != "":
+		return runEntityCommand(ctx, cmd.OutOrStdout(), opts)
+	case opts.clear != "":
+		return runClearCommand(ctx, cmd.InOrStdin(), cmd.OutOrStdout(), opts)
 	default:
 		return runDeleteCommand(ctx, cmd.OutOrStdout(), opts)
 	}
@@ -109,16 +235,28 @@ func runMemoryCommand(cmd *cobra.Command, opts *memoryCommandOptions) error {
 
 func runSaveCommand(ctx context.Context, out io.Writer, opts *memoryCommandOptions) error {
 	result, err := saveMemoryFn(ctx, memoryservice.SaveInput{
-		Text: opts.saveText,
-		Tags: parseTags(opts.tags),
+		Text:   opts.saveText,
+		Tags:   parseTags(opts.tags),
+		Kind:   memtypes.MemoryKind(opts.kind),
+		Pinned: opts.pinned,
 	})
 	if err != nil {
 		return err
 	}
 
+	message := fmt.Sprintf("Memory saved successfully (id: %s)", result.Item.ID)
+	if result.AutoTagged {
+		message += fmt.Sprintf("; tagged %s", strings.Join(result.Item.Tags, ", "))
+	}
+	if result.PendingErr != nil {
+		message += fmt.Sprintf("; embedding failed and was queued for retry: %v", result.PendingErr)
+	}
+
 	output := memorySaveOutput{
-		Message: fmt.Sprintf("Memory saved successfully (id: %s)", result.Item.ID),
+		Message: message,
 		ID:      result.Item.ID,
+		Tags:    result.Item.Tags,
+		Pending: result.Pending,
 	}
 
 	if opts.jsonOutput {
@@ -130,19 +268,256 @@ func runSaveCommand(ctx context.Context, out io.Writer, opts *memoryCommandOptio
 }
 
 func runQueryCommand(ctx context.Context, out io.Writer, opts *memoryCommandOptions) error {
-	result, err := queryMemoryFn(ctx, memoryservice.RetrieveInput{Query: opts.queryText})
+	input := memoryservice.RetrieveInput{
+		Query:              opts.queryText,
+		ExcludeTags:        parseTags(opts.exclude),
+		FTSStrategy:        opts.strategy,
+		FusionVectorWeight: opts.weight,
+		Command:            "memory",
+	}
+	if opts.strict {
+		input.Strict = &opts.strict
+	}
+
+	result, err := queryMemoryFn(ctx, input)
 	if err != nil {
 		return err
 	}
 
 	if opts.jsonOutput {
-		return writeJSON(out, memoryQueryOutput{
+		output := memoryQueryOutput{
 			Results: result.Text,
 			Matches: buildMemoryQueryMatches(result),
+		}
+		if result.Response != nil {
+			output.Degraded = result.Response.Degraded
+			output.Warnings = result.Response.Warnings
+		}
+		err = writeJSON(out, output)
+	} else {
+		_, err = fmt.Fprintln(out, result.Text)
+	}
+	if err == nil && result.Response != nil && result.Response.Degraded {
+		return exitcode.ErrPartial
+	}
+	return err
+}
+
+func runFindCommand(ctx context.Context, out io.Writer, opts *memoryCommandOptions) error {
+	result, err := findMemoryFn(ctx, memoryservice.FindInput{Query: opts.findText, Limit: opts.limit})
+	if err != nil {
+		return err
+	}
+
+	matches := make([]memoryQueryMatch, 0, len(result.Matches))
+	for _, match := range result.Matches {
+		matches = append(matches, memoryQueryMatch{
+			ID:      match.Item.ID,
+			Text:    match.Item.Text,
+			Tags:    match.Item.Tags,
+			Kind:    string(match.Item.Kind),
+			Source:  string(match.Item.Source),
+			Snippet: match.Snippet,
+		})
+	}
+
+	if opts.jsonOutput {
+		return writeJSON(out, memoryFindOutput{Query: opts.findText, Matches: matches})
+	}
+
+	if len(matches) == 0 {
+		_, err = fmt.Fprintln(out, "No memories match")
+		return err
+	}
+	for i, m := range matches {
+		line := fmt.Sprintf("%d. %s (id: %s", i+1, m.Text, m.ID)
+		if len(m.Tags) > 0 {
+			line += ", tags: " + strings.Join(m.Tags, ", ")
+		}
+		if _, err := fmt.Fprintln(out, line+")"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runEntityCommand(ctx context.Context, out io.Writer, opts *memoryCommandOptions) error {
+	result, err := entityMemoryFn(ctx, memoryservice.EntityInput{Name: opts.entity})
+	if err != nil {
+		return err
+	}
+
+	matches := make([]memoryQueryMatch, 0, len(result.Memories))
+	for _, item := range result.Memories {
+		matches = append(matches, memoryQueryMatch{
+			ID:     item.ID,
+			Text:   item.Text,
+			Tags:   item.Tags,
+			Kind:   string(item.Kind),
+			Source: string(item.Source),
 		})
 	}
 
-	_, err = fmt.Fprintln(out, result.Text)
+	if opts.jsonOutput {
+		return writeJSON(out, memoryEntityOutput{Entity: result.Name, Matches: matches})
+	}
+
+	if len(matches) == 0 {
+		_, err = fmt.Fprintf(out, "No memories linked to %q\n", result.Name)
+		return err
+	}
+
+	if _, err := fmt.Fprintf(out, "Memories linked to %q:\n", result.Name); err != nil {
+		return err
+	}
+	for i, m := range matches {
+		if _, err := fmt.Fprintf(out, "%d. %s (id: %s)\n", i+1, m.Text, m.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runClearCommand(ctx context.Context, in io.Reader, out io.Writer, opts *memoryCommandOptions) error {
+	target, err := memoryservice.ParseClearTarget(opts.clear)
+	if err != nil {
+		return err
+	}
+
+	// Always look before deleting so the prompt can say what will be lost.
+	preview, err := clearMemoryFn(ctx, memoryservice.ClearInput{Target: target, DryRun: true})
+	if err != nil {
+		return err
+	}
+
+	result := preview
+	if !opts.dryRun {
+		if !opts.yes {
+			if err := confirmClear(in, out, preview); err != nil {
+				return err
+			}
+		}
+		result, err = clearMemoryFn(ctx, memoryservice.ClearInput{Target: target})
+		if err != nil {
+			return err
+		}
+	}
+
+	output := memoryClearOutput{
+		Target:     string(result.Target),
+		Memories:   result.Memories,
+		History:    result.History,
+		BackupPath: result.BackupPath,
+		Cleared:    result.Cleared,
+		DryRun:     opts.dryRun,
+	}
+	if opts.dryRun {
+		output.Message = fmt.Sprintf("Dry run: would delete %s", describeClear(result))
+	} else {
+		output.Message = fmt.Sprintf("Deleted %s (backup: %s)", describeClear(result), result.BackupPath)
+	}
+
+	if opts.jsonOutput {
+		return writeJSON(out, output)
+	}
+
+	_, err = fmt.Fprintln(out, output.Message)
+	return err
+}
+
+// confirmClear requires the user to type the exact confirmation phrase.
+func confirmClear(in io.Reader, out io.Writer, preview *memoryservice.ClearResult) error {
+	phrase := "clear " + string(preview.Target)
+	if _, err := fmt.Fprintf(out, "This will permanently delete %s. A backup is written first.\nType %q to confirm: ", describeClear(preview), phrase); err != nil {
+		return err
+	}
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && line == "" {
+		return fmt.Errorf("clear aborted: no confirmation received (use --yes in scripts)")
+	}
+	if strings.TrimSpace(line) != phrase {
+		return fmt.Errorf("clear aborted: confirmation did not match")
+	}
+	return nil
+}
+
+func describeClear(result *memoryservice.ClearResult) string {
+	switch result.Target {
+	case memoryservice.ClearMemories:
+		return fmt.Sprintf("%d memories", result.Memories)
+	case memoryservice.ClearHistory:
+		return fmt.Sprintf("%d history items", result.History)
+	default:
+		return fmt.Sprintf("%d memories and %d history items", result.Memories, result.History)
+	}
+}
+
+func runPinCommand(ctx context.Context, out io.Writer, opts *memoryCommandOptions) error {
+	input := memoryservice.PinInput{ID: opts.pinID, Pinned: true}
+	if opts.unpinID != "" {
+		input = memoryservice.PinInput{ID: opts.unpinID}
+	}
+
+	result, err := pinMemoryFn(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("Memory pinned (id: %s)", result.ID)
+	if !result.Pinned {
+		message = fmt.Sprintf("Memory unpinned (id: %s)", result.ID)
+	}
+	if !result.Found {
+		message = fmt.Sprintf("Memory not found (id: %s)", result.ID)
+	}
+
+	output := memoryPinOutput{
+		Message: message,
+		ID:      result.ID,
+		Pinned:  result.Pinned && result.Found,
+		Found:   result.Found,
+	}
+
+	if opts.jsonOutput {
+		return writeJSON(out, output)
+	}
+
+	_, err = fmt.Fprintln(out, output.Message)
+	return err
+}
+
+func runSuppressCommand(ctx context.Context, out io.Writer, opts *memoryCommandOptions) error {
+	input := memoryservice.SuppressInput{ID: opts.suppressID, Suppressed: true}
+	if opts.allowID != "" {
+		input = memoryservice.SuppressInput{ID: opts.allowID}
+	}
+
+	result, err := suppressMemoryFn(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("Memory suppressed; it is kept but no longer retrieved (id: %s)", result.ID)
+	if !result.Suppressed {
+		message = fmt.Sprintf("Memory unsuppressed (id: %s)", result.ID)
+	}
+	if !result.Found {
+		message = fmt.Sprintf("Memory not found (id: %s)", result.ID)
+	}
+
+	output := memorySuppressOutput{
+		Message:    message,
+		ID:         result.ID,
+		Suppressed: result.Suppressed && result.Found,
+		Found:      result.Found,
+	}
+
+	if opts.jsonOutput {
+		return writeJSON(out, output)
+	}
+
+	_, err = fmt.Fprintln(out, output.Message)
 	return err
 }
 
@@ -179,11 +554,13 @@ func buildMemoryQueryMatches(result *memoryservice.RetrieveResult) []memoryQuery
 	matches := make([]memoryQueryMatch, 0, len(result.Response.Results))
 	for _, item := range result.Response.Results {
 		matches = append(matches, memoryQueryMatch{
-			ID:     item.Item.ID,
-			Text:   item.Item.Text,
-			Tags:   item.Item.Tags,
-			Score:  item.Score,
-			Source: item.Source,
+			ID:      item.Item.ID,
+			Text:    item.Item.Text,
+			Tags:    item.Item.Tags,
+			Kind:    string(item.Item.Kind),
+			Score:   item.Score,
+			Source:  item.Source,
+			Snippet: item.Snippet,
 		})
 	}
 
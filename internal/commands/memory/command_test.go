@@ -4,9 +4,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/austiecodes/gomor/internal/exitcode"
+	"github.com/austiecodes/gomor/internal/memory/graph"
 	"github.com/austiecodes/gomor/internal/memory/memtypes"
 	"github.com/austiecodes/gomor/internal/memory/retrieval"
 	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
@@ -61,6 +66,43 @@ func TestMemoryCommandRejectsTagsWithoutSave(t *testing.T) {
 	}
 }
 
+func TestMemoryCommandRejectsKindWithoutSave(t *testing.T) {
+	cmd := newMemoryCommand()
+	cmd.SetArgs([]string{"--query", "remember", "--kind", "preference"})
+
+	err := cmd.Execute()
+	if err == nil {
+		t.Fatal("expected kind validation error")
+	}
+	if !strings.Contains(err.Error(), "--kind can only be used with --save") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestMemoryCommandQueryPassesStrict(t *testing.T) {
+	oldQueryMemory := queryMemoryFn
+	defer func() { queryMemoryFn = oldQueryMemory }()
+
+	var gotStrict *bool
+	queryMemoryFn = func(ctx context.Context, input memoryservice.RetrieveInput) (*memoryservice.RetrieveResult, error) {
+		gotStrict = input.Strict
+		return &memoryservice.RetrieveResult{Text: "No memories found."}, nil
+	}
+
+	cmd := newMemoryCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"--query", "remember", "--strict"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if gotStrict == nil || !*gotStrict {
+		t.Fatal("expected strict retrieval to be requested")
+	}
+}
+
 func TestMemoryCommandSaveJSONOutput(t *testing.T) {
 	oldSaveMemory := saveMemoryFn
 	defer func() { saveMemoryFn = oldSaveMemory }()
@@ -94,6 +136,39 @@ func TestMemoryCommandSaveJSONOutput(t *testing.T) {
 	}
 }
 
+func TestMemoryCommandSaveReportsProposedTags(t *testing.T) {
+	oldSaveMemory := saveMemoryFn
+	defer func() { saveMemoryFn = oldSaveMemory }()
+
+	saveMemoryFn = func(ctx context.Context, input memoryservice.SaveInput) (*memoryservice.SaveResult, error) {
+		if input.AutoTag != nil {
+			t.Errorf("expected --save to leave auto-tagging to the config, got %v", *input.AutoTag)
+		}
+		return &memoryservice.SaveResult{
+			Item:       memtypes.MemoryItem{ID: "mem-1", Text: input.Text, Tags: []string{"database", "staging"}},
+			AutoTagged: true,
+		}, nil
+	}
+
+	cmd := newMemoryCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"--save", "the staging database is db-stg-2", "--json"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	var payload memorySaveOutput
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v", err)
+	}
+	if len(payload.Tags) != 2 || !strings.Contains(payload.Message, "tagged database, staging") {
+		t.Fatalf("unexpected output: %+v", payload)
+	}
+}
+
 func TestMemoryCommandQueryJSONOutput(t *testing.T) {
 	oldQueryMemory := queryMemoryFn
 	defer func() { queryMemoryFn = oldQueryMemory }()
@@ -139,6 +214,35 @@ func TestMemoryCommandQueryJSONOutput(t *testing.T) {
 	}
 }
 
+func TestMemoryCommandQueryDegradedIsPartial(t *testing.T) {
+	oldQueryMemory := queryMemoryFn
+	defer func() { queryMemoryFn = oldQueryMemory }()
+
+	queryMemoryFn = func(ctx context.Context, input memoryservice.RetrieveInput) (*memoryservice.RetrieveResult, error) {
+		return &memoryservice.RetrieveResult{
+			Text: "No memories found.",
+			Response: &retrieval.RetrievalResponse{
+				Degraded: true,
+				Warnings: []string{"vector search failed: provider unreachable"},
+			},
+		}, nil
+	}
+
+	cmd := newMemoryCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--query", "remember", "--json"})
+
+	if err := cmd.Execute(); !errors.Is(err, exitcode.ErrPartial) {
+		t.Fatalf("expected a partial result, got %v", err)
+	}
+	var payload memoryQueryOutput
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil || !payload.Degraded {
+		t.Fatalf("expected the degraded results to be printed, got %q (%v)", out.String(), err)
+	}
+}
+
 func TestMemoryCommandDeleteJSONOutput(t *testing.T) {
 	oldDeleteMemory := deleteMemoryFn
 	defer func() { deleteMemoryFn = oldDeleteMemory }()
@@ -171,3 +275,587 @@ func TestMemoryCommandDeleteJSONOutput(t *testing.T) {
 		t.Fatalf("unexpected id: %s", payload.ID)
 	}
 }
+
+func TestMemoryCommandEntityJSONOutput(t *testing.T) {
+	oldEntityMemory := entityMemoryFn
+	defer func() { entityMemoryFn = oldEntityMemory }()
+
+	entityMemoryFn = func(ctx context.Context, input memoryservice.EntityInput) (*memoryservice.EntityResult, error) {
+		return &memoryservice.EntityResult{
+			Name: input.Name,
+			Memories: []memtypes.MemoryItem{
+				{ID: "mem-1", Text: "Atlas ships in March", Source: memtypes.SourceExplicit},
+			},
+		}, nil
+	}
+
+	cmd := newMemoryCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"--entity", "Atlas", "--json"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	var payload memoryEntityOutput
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v", err)
+	}
+	if payload.Entity != "Atlas" {
+		t.Fatalf("unexpected entity: %s", payload.Entity)
+	}
+	if len(payload.Matches) != 1 || payload.Matches[0].ID != "mem-1" {
+		t.Fatalf("unexpected matches: %+v", payload.Matches)
+	}
+}
+
+func stubClear(t *testing.T) *[]memoryservice.ClearInput {
+	t.Helper()
+
+	oldClear := clearMemoryFn
+	t.Cleanup(func() { clearMemoryFn = oldClear })
+
+	var calls []memoryservice.ClearInput
+	clearMemoryFn = func(ctx context.Context, input memoryservice.ClearInput) (*memoryservice.ClearResult, error) {
+		calls = append(calls, input)
+		result := &memoryservice.ClearResult{Target: input.Target, Memories: 3}
+		if !input.DryRun {
+			result.Cleared = true
+			result.BackupPath = "/backups/memory.db"
+		}
+		return result, nil
+	}
+	return &calls
+}
+
+func TestMemoryCommandClearRequiresTypedConfirmation(t *testing.T) {
+	calls := stubClear(t)
+
+	cmd := newMemoryCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetIn(strings.NewReader("yes\n"))
+	cmd.SetArgs([]string{"--clear", "memories"})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "confirmation did not match") {
+		t.Fatalf("expected confirmation mismatch, got %v", err)
+	}
+	if len(*calls) != 1 || !(*calls)[0].DryRun {
+		t.Fatalf("expected only the dry-run preview, got %+v", *calls)
+	}
+
+	cmd = newMemoryCommand()
+	out.Reset()
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetIn(strings.NewReader("clear memories\n"))
+	cmd.SetArgs([]string{"--clear", "memories"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if last := (*calls)[len(*calls)-1]; last.DryRun {
+		t.Fatal("expected the confirmed clear to run")
+	}
+	if !strings.Contains(out.String(), "Deleted 3 memories (backup: /backups/memory.db)") {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+}
+
+func TestMemoryCommandClearYesAndDryRun(t *testing.T) {
+	calls := stubClear(t)
+
+	cmd := newMemoryCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"--clear", "memories", "--dry-run", "--json"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute dry run: %v", err)
+	}
+	var payload memoryClearOutput
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v", err)
+	}
+	if payload.Cleared || !payload.DryRun || payload.Memories != 3 {
+		t.Fatalf("unexpected dry-run payload: %+v", payload)
+	}
+	if len(*calls) != 1 {
+		t.Fatalf("expected dry run to only preview, got %d calls", len(*calls))
+	}
+
+	cmd = newMemoryCommand()
+	out.Reset()
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"--clear", "memories", "--yes", "--json"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute with --yes: %v", err)
+	}
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v", err)
+	}
+	if !payload.Cleared || payload.BackupPath == "" {
+		t.Fatalf("expected clear with backup, got %+v", payload)
+	}
+}
+
+func TestMemoryFeedbackCommand(t *testing.T) {
+	oldFeedbackMemory := feedbackMemoryFn
+	defer func() { feedbackMemoryFn = oldFeedbackMemory }()
+
+	var got memoryservice.FeedbackInput
+	feedbackMemoryFn = func(ctx context.Context, input memoryservice.FeedbackInput) (*memoryservice.FeedbackResult, error) {
+		got = input
+		return &memoryservice.FeedbackResult{MemoryID: input.ID, PreviousConfidence: 0.9, Confidence: 0.54, Wrong: 1}, nil
+	}
+
+	cmd := newMemoryCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"feedback", "mem-1", "--wrong", "--json"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if got.ID != "mem-1" || got.Useful {
+		t.Fatalf("unexpected feedback input: %+v", got)
+	}
+
+	var payload memoryFeedbackOutput
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v", err)
+	}
+	if payload.Confidence != 0.54 || payload.Wrong != 1 || !strings.Contains(payload.Message, "as wrong") {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+}
+
+func TestMemoryArchiveCommandDryRun(t *testing.T) {
+	oldArchive := archiveMemoryFn
+	defer func() { archiveMemoryFn = oldArchive }()
+
+	var got memoryservice.ArchiveInput
+	archiveMemoryFn = func(ctx context.Context, input memoryservice.ArchiveInput) (*memoryservice.ArchiveResult, error) {
+		got = input
+		return &memoryservice.ArchiveResult{
+			AfterDays:       90,
+			BelowConfidence: 0.5,
+			Memories: []memtypes.MemoryItem{
+				{ID: "mem-1", Text: "the user once tried nix flakes", Confidence: 0.42, CreatedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)},
+			},
+		}, nil
+	}
+
+	cmd := newMemoryCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"archive", "--days", "90", "--dry-run"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if got.AfterDays != 90 || got.BelowConfidence != 0 || !got.DryRun {
+		t.Fatalf("unexpected archive input: %+v", got)
+	}
+	want := "Would archive 1 memories not retrieved in 90 days with confidence below 0.50\n" +
+		"1. the user once tried nix flakes\n   ID: mem-1  confidence 0.42  last used 2026-01-02\n"
+	if out.String() != want {
+		t.Fatalf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestMemoryArchiveCommandRestore(t *testing.T) {
+	oldRestore := restoreMemoryFn
+	defer func() { restoreMemoryFn = oldRestore }()
+
+	restoreMemoryFn = func(ctx context.Context, input memoryservice.RestoreInput) (*memoryservice.RestoreResult, error) {
+		return &memoryservice.RestoreResult{ID: input.ID, Restored: true}, nil
+	}
+
+	cmd := newMemoryCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"archive", "--restore", "mem-1", "--json"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	var payload memoryRestoreOutput
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v", err)
+	}
+	if payload.ID != "mem-1" || !payload.Restored || payload.Message != "Restored memory mem-1" {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+
+	cmd = newMemoryCommand()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"archive", "--restore", "mem-1", "--dry-run"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected --restore with --dry-run to fail")
+	}
+}
+
+func TestMemoryFeedbackCommandRequiresOneVerdict(t *testing.T) {
+	for _, args := range [][]string{
+		{"feedback", "mem-1"},
+		{"feedback", "mem-1", "--useful", "--wrong"},
+	} {
+		cmd := newMemoryCommand()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(args)
+
+		err := cmd.Execute()
+		if err == nil || !strings.Contains(err.Error(), "exactly one of --useful or --wrong") {
+			t.Fatalf("%v: unexpected error: %v", args, err)
+		}
+	}
+}
+
+func TestMemoryCommandPinJSONOutput(t *testing.T) {
+	oldPinMemory := pinMemoryFn
+	defer func() { pinMemoryFn = oldPinMemory }()
+
+	var got memoryservice.PinInput
+	pinMemoryFn = func(ctx context.Context, input memoryservice.PinInput) (*memoryservice.PinResult, error) {
+		got = input
+		return &memoryservice.PinResult{ID: input.ID, Pinned: input.Pinned, Found: true}, nil
+	}
+
+	cmd := newMemoryCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"--unpin", "mem-1", "--json"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if got.ID != "mem-1" || got.Pinned {
+		t.Fatalf("unexpected pin input: %+v", got)
+	}
+
+	var payload memoryPinOutput
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v", err)
+	}
+	if payload.Pinned || !payload.Found || payload.Message != "Memory unpinned (id: mem-1)" {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+}
+
+func TestMemoryCommandRejectsPinnedWithoutSave(t *testing.T) {
+	cmd := newMemoryCommand()
+	cmd.SetArgs([]string{"--pinned"})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "--pinned can only be used with --save") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestMemoryCommandSuppressJSONOutput(t *testing.T) {
+	oldSuppressMemory := suppressMemoryFn
+	defer func() { suppressMemoryFn = oldSuppressMemory }()
+
+	var got memoryservice.SuppressInput
+	suppressMemoryFn = func(ctx context.Context, input memoryservice.SuppressInput) (*memoryservice.SuppressResult, error) {
+		got = input
+		return &memoryservice.SuppressResult{ID: input.ID, Suppressed: input.Suppressed, Found: true}, nil
+	}
+
+	cmd := newMemoryCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"--suppress", "mem-1", "--json"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if got.ID != "mem-1" || !got.Suppressed {
+		t.Fatalf("unexpected suppress input: %+v", got)
+	}
+
+	var payload memorySuppressOutput
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v", err)
+	}
+	if !payload.Suppressed || !payload.Found {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+}
+
+func TestMemoryCommandQueryPassesExcludedTags(t *testing.T) {
+	oldQueryMemory := queryMemoryFn
+	defer func() { queryMemoryFn = oldQueryMemory }()
+
+	var got memoryservice.RetrieveInput
+	queryMemoryFn = func(ctx context.Context, input memoryservice.RetrieveInput) (*memoryservice.RetrieveResult, error) {
+		got = input
+		return &memoryservice.RetrieveResult{Text: "No memories found."}, nil
+	}
+
+	cmd := newMemoryCommand()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--query", "deploys", "--exclude-tags", "work, secret"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if len(got.ExcludeTags) != 2 || got.ExcludeTags[0] != "work" || got.ExcludeTags[1] != "secret" {
+		t.Fatalf("unexpected excluded tags: %v", got.ExcludeTags)
+	}
+
+	cmd = newMemoryCommand()
+	cmd.SetArgs([]string{"--save", "x", "--exclude-tags", "work"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--exclude-tags can only be used with --query") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestMemoryGraphCommand(t *testing.T) {
+	oldGraphMemory := graphMemoryFn
+	defer func() { graphMemoryFn = oldGraphMemory }()
+
+	var got memoryservice.GraphInput
+	graphMemoryFn = func(ctx context.Context, input memoryservice.GraphInput) (*graph.Graph, error) {
+		got = input
+		return graph.Build([]memtypes.MemoryItem{{ID: "mem-1", Text: "hello"}}, input.Threshold), nil
+	}
+
+	cmd := newMemoryCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"graph", "--format", "json", "--threshold", "0.9"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if got.Threshold != 0.9 || got.IncludeSuppressed {
+		t.Fatalf("unexpected graph input: %+v", got)
+	}
+
+	var payload graph.Graph
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v", err)
+	}
+	if len(payload.Nodes) != 1 || payload.Nodes[0].ID != "mem-1" {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+}
+
+func TestMemoryGraphCommandRejectsUnknownFormat(t *testing.T) {
+	cmd := newMemoryCommand()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"graph", "--format", "svg"})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), `unknown graph format "svg"`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestMemoryReviewCommandApprovesWithEditedText(t *testing.T) {
+	oldReviewMemory := reviewMemoryFn
+	defer func() { reviewMemoryFn = oldReviewMemory }()
+
+	var got memoryservice.ReviewInput
+	reviewMemoryFn = func(ctx context.Context, input memoryservice.ReviewInput) (*memoryservice.ReviewResult, error) {
+		got = input
+		return &memoryservice.ReviewResult{ID: input.ID, Decision: input.Decision, Edited: true, Found: true}, nil
+	}
+
+	cmd := newMemoryCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"review", "--approve", "mem-1", "--text", "deploys use argo", "--json"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if got.ID != "mem-1" || got.Decision != memoryservice.ReviewApprove || got.Text != "deploys use argo" {
+		t.Fatalf("unexpected review input: %+v", got)
+	}
+
+	var payload memoryReviewOutput
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal json: %v", err)
+	}
+	if !payload.Found || !payload.Edited || payload.Message != "Edited and approved memory mem-1" {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+}
+
+func TestMemoryReviewCommandListsPending(t *testing.T) {
+	oldReviewQueue := reviewQueueFn
+	defer func() { reviewQueueFn = oldReviewQueue }()
+
+	reviewQueueFn = func(ctx context.Context) ([]memtypes.MemoryItem, error) {
+		return []memtypes.MemoryItem{{ID: "mem-1", Text: "prefers tabs", PendingReview: true}}, nil
+	}
+
+	cmd := newMemoryCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"review", "--list"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if !strings.Contains(out.String(), "1. prefers tabs") || !strings.Contains(out.String(), "ID: mem-1") {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
+
+func TestMemoryReviewCommandNoFlagsRunsInteractive(t *testing.T) {
+	oldRunReview := runReviewMemory
+	defer func() { runReviewMemory = oldRunReview }()
+
+	called := false
+	runReviewMemory = func() error {
+		called = true
+		return nil
+	}
+
+	cmd := newMemoryCommand()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"review"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if !called {
+		t.Fatal("expected the interactive review screen to run")
+	}
+}
+
+func TestMemoryReviewCommandRejectsTextWithoutApprove(t *testing.T) {
+	cmd := newMemoryCommand()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"review", "--reject", "mem-1", "--text", "x"})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "--text can only be used with --approve") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestMemoryCommandFindPassesQueryAndLimit(t *testing.T) {
+	oldFindMemory := findMemoryFn
+	defer func() { findMemoryFn = oldFindMemory }()
+
+	var got memoryservice.FindInput
+	findMemoryFn = func(ctx context.Context, input memoryservice.FindInput) (*memoryservice.FindResult, error) {
+		got = input
+		return &memoryservice.FindResult{
+			Matches: []memtypes.MemoryFTSResult{
+				{Item: memtypes.MemoryItem{ID: "mem-1", Text: "Use table tests", Tags: []string{"golang", "testing"}}},
+			},
+		}, nil
+	}
+
+	cmd := newMemoryCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"--find", `tag:golang "table tests"`, "--limit", "5"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if got.Query != `tag:golang "table tests"` || got.Limit != 5 {
+		t.Fatalf("unexpected find input: %+v", got)
+	}
+	if want := "1. Use table tests (id: mem-1, tags: golang, testing)\n"; out.String() != want {
+		t.Fatalf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestMemoryCommandRejectsLimitWithoutFind(t *testing.T) {
+	cmd := newMemoryCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"--query", "go", "--limit", "5"})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "--limit can only be used with --find") {
+		t.Fatalf("expected limit error, got %v", err)
+	}
+}
+
+func TestMemoryRelateCommand(t *testing.T) {
+	oldRelateMemory := relateMemoryFn
+	defer func() { relateMemoryFn = oldRelateMemory }()
+
+	var got memoryservice.RelateInput
+	relateMemoryFn = func(ctx context.Context, input memoryservice.RelateInput) (*memoryservice.RelateResult, error) {
+		got = input
+		return &memoryservice.RelateResult{
+			Relation: memtypes.MemoryRelation{FromID: input.FromID, ToID: input.ToID, Kind: input.Kind},
+		}, nil
+	}
+
+	cmd := newMemoryCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"relate", "mem-2", "mem-1", "--kind", "supersedes"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if got.FromID != "mem-2" || got.ToID != "mem-1" || got.Kind != memtypes.RelationSupersedes || got.Remove {
+		t.Fatalf("unexpected relate input: %+v", got)
+	}
+	if !strings.Contains(out.String(), "no longer be retrieved") {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
+
+func TestMemoryCommandQueryPassesSettingOverrides(t *testing.T) {
+	oldQueryMemory := queryMemoryFn
+	defer func() { queryMemoryFn = oldQueryMemory }()
+
+	var got memoryservice.RetrieveInput
+	queryMemoryFn = func(ctx context.Context, input memoryservice.RetrieveInput) (*memoryservice.RetrieveResult, error) {
+		got = input
+		return &memoryservice.RetrieveResult{Text: "No memories found."}, nil
+	}
+
+	cmd := newMemoryCommand()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--query", "argo", "--fts-strategy", "direct", "--fusion-weight", "0.3"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if got.FTSStrategy != "direct" || got.FusionVectorWeight != 0.3 {
+		t.Fatalf("unexpected overrides: %+v", got)
+	}
+
+	cmd = newMemoryCommand()
+	cmd.SetArgs([]string{"--find", "argo", "--fts-strategy", "direct"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "can only be used with --query") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
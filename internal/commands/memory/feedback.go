@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"fmt"
+
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/spf13/cobra"
+)
+
+var feedbackMemoryFn = memoryservice.Feedback
+
+type memoryFeedbackOutput struct {
+	Message string `json:"message"`
+	*memoryservice.FeedbackResult
+}
+
+func newFeedbackCommand() *cobra.Command {
+	var useful, wrong, jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "feedback <id>",
+		Short: "Mark a memory as useful or wrong",
+		Long: `Record whether a retrieved memory was useful or wrong. Useful memories gain
+confidence and are forgotten more slowly; wrong ones lose confidence and rank
+lower in later retrievals, so consistently unhelpful memories sink.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if useful == wrong {
+				return fmt.Errorf("pass exactly one of --useful or --wrong")
+			}
+
+			result, err := feedbackMemoryFn(cmd.Context(), memoryservice.FeedbackInput{ID: args[0], Useful: useful})
+			if err != nil {
+				return err
+			}
+
+			verdict := "useful"
+			if wrong {
+				verdict = "wrong"
+			}
+			output := memoryFeedbackOutput{
+				Message: fmt.Sprintf("Marked memory %s as %s; confidence %.2f -> %.2f (%d useful, %d wrong)",
+					result.MemoryID, verdict, result.PreviousConfidence, result.Confidence, result.Useful, result.Wrong),
+				FeedbackResult: result,
+			}
+
+			if jsonOutput {
+				return writeJSON(cmd.OutOrStdout(), output)
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), output.Message)
+			return err
+		},
+	}
+
+	cmd.Flags().BoolVar(&useful, "useful", false, "the memory helped")
+	cmd.Flags().BoolVar(&wrong, "wrong", false, "the memory was wrong or unhelpful")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "emit structured JSON output")
+	return cmd
+}
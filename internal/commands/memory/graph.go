@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/austiecodes/gomor/internal/memory/graph"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/spf13/cobra"
+)
+
+var graphMemoryFn = memoryservice.Graph
+
+func newGraphCommand() *cobra.Command {
+	var format, output string
+	var threshold float64
+	var includeSuppressed bool
+
+	cmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Export memories as a similarity graph",
+		Long: `Export memories as a graph for visualization. Each memory is a node; memories
+whose embeddings have a cosine similarity of at least --threshold are joined by
+an edge, and memories sharing a tag are grouped into a cluster.
+
+Formats:
+  dot   Graphviz DOT, e.g. gomor memory graph | dot -Tsvg > memories.svg
+  json  nodes, edges, and clusters for web viewers such as d3 or Cytoscape
+
+Memories embedded by different models are never linked. Suppressed memories
+are left out unless --include-suppressed is set.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var write func(io.Writer, *graph.Graph) error
+			switch format {
+			case graph.FormatDOT:
+				write = graph.WriteDOT
+			case graph.FormatJSON:
+				write = graph.WriteJSON
+			default:
+				return fmt.Errorf("unknown graph format %q (valid: dot, json)", format)
+			}
+
+			g, err := graphMemoryFn(cmd.Context(), memoryservice.GraphInput{
+				Threshold:         threshold,
+				IncludeSuppressed: includeSuppressed,
+			})
+			if err != nil {
+				return err
+			}
+
+			if output == "" {
+				return write(cmd.OutOrStdout(), g)
+			}
+
+			f, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", output, err)
+			}
+			if err := write(f, g); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(cmd.ErrOrStderr(), "Wrote %d memories, %d edges, and %d tag clusters to %s\n",
+				len(g.Nodes), len(g.Edges), len(g.Clusters), output)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", graph.FormatDOT, "output format: dot or json")
+	cmd.Flags().Float64Var(&threshold, "threshold", graph.DefaultThreshold, "minimum cosine similarity for an edge")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "file to write (default stdout)")
+	cmd.Flags().BoolVar(&includeSuppressed, "include-suppressed", false, "include suppressed memories")
+	return cmd
+}
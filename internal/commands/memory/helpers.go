@@ -2,25 +2,29 @@ package memory
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/austiecodes/gomor/internal/memory/memquery"
 	"github.com/austiecodes/gomor/internal/memory/memtypes"
 	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
 	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/termcap"
 )
 
-func createMemoryList(memories []memtypes.MemoryItem, width, height int) list.Model {
+func createMemoryList(memories []memtypes.MemoryItem, order MemorySort, width, height int) list.Model {
 	items := make([]list.Item, len(memories))
 	for i, mem := range memories {
 		items[i] = MemoryListItem{Memory: mem}
 	}
 
-	delegate := list.NewDefaultDelegate()
 	w := min(width-4, 80)
 	h := min(height-6, 20)
 	if w < 40 {
@@ -30,21 +34,96 @@ func createMemoryList(memories []memtypes.MemoryItem, width, height int) list.Mo
 		h = 10
 	}
 
-	l := list.New(items, delegate, w, h)
+	l := termcap.NewList(items, w, h)
 	l.Title = "Memories"
+	if order != SortNewest {
+		l.Title += " (" + order.String() + ")"
+	}
 	l.SetShowStatusBar(true)
 	l.SetFilteringEnabled(true)
+	l.Filter = queryFilter(memories)
 	l.SetShowHelp(true)
 	l.AdditionalShortHelpKeys = func() []key.Binding {
 		return []key.Binding{
 			key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "add")),
 			key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete")),
 			key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit")),
+			key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "pin/unpin")),
+			key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "suppress/unsuppress")),
+			key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "sort")),
+			key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "review queue")),
+			key.NewBinding(key.WithKeys("C"), key.WithHelp("C", "clear all")),
 		}
 	}
 	return l
 }
 
+// queryFilter filters the list of memories with the advanced search syntax
+// (tag:golang "unit test") once the filter uses a qualifier or a quoted
+// phrase, and fuzzily by text until then or while it does not parse.
+func queryFilter(memories []memtypes.MemoryItem) list.FilterFunc {
+	return func(term string, targets []string) []list.Rank {
+		if !strings.ContainsAny(term, `:"`) {
+			return list.DefaultFilter(term, targets)
+		}
+		query, err := memquery.Parse(term, time.Now())
+		if err != nil {
+			return list.DefaultFilter(term, targets)
+		}
+
+		var ranks []list.Rank
+		for i, mem := range memories {
+			// The list shows suppressed and unreviewed memories too.
+			mem.Suppressed, mem.PendingReview = false, false
+			if i < len(targets) && query.Matches(mem) {
+				ranks = append(ranks, list.Rank{Index: i})
+			}
+		}
+		return ranks
+	}
+}
+
+// sortMemories orders memories for the list, newest first among equals.
+func sortMemories(memories []memtypes.MemoryItem, order MemorySort) {
+	lastRetrieved := func(m memtypes.MemoryItem) time.Time {
+		if m.LastRetrievedAt == nil {
+			return time.Time{}
+		}
+		return *m.LastRetrievedAt
+	}
+	sort.SliceStable(memories, func(i, j int) bool {
+		a, b := memories[i], memories[j]
+		switch order {
+		case SortMostRetrieved:
+			if a.TimesRetrieved != b.TimesRetrieved {
+				return a.TimesRetrieved > b.TimesRetrieved
+			}
+		case SortLeastRecentlyUsed:
+			if la, lb := lastRetrieved(a), lastRetrieved(b); !la.Equal(lb) {
+				return la.Before(lb)
+			}
+		}
+		return a.CreatedAt.After(b.CreatedAt)
+	})
+}
+
+// retrievalStats describes how often and how recently a memory was retrieved.
+// Memories retrieved before counting began have a time but no count.
+func retrievalStats(mem memtypes.MemoryItem) string {
+	if mem.LastRetrievedAt == nil {
+		return "never"
+	}
+	last := "last " + mem.LastRetrievedAt.Format("2006-01-02 15:04:05")
+	switch mem.TimesRetrieved {
+	case 0:
+		return last
+	case 1:
+		return "once, " + last
+	default:
+		return fmt.Sprintf("%d times, %s", mem.TimesRetrieved, last)
+	}
+}
+
 func createAddEditInputs(mem *memtypes.MemoryItem) []textinput.Model {
 	inputs := make([]textinput.Model, 2)
 
@@ -82,6 +161,73 @@ func loadMemories() tea.Cmd {
 	}
 }
 
+func loadRelations(id string) tea.Cmd {
+	return func() tea.Msg {
+		relations, err := memoryservice.Relations(context.Background(), id)
+		return RelationsLoadedMsg{MemoryID: id, Relations: relations, Err: err}
+	}
+}
+
+// describeRelation renders a relation of the selected memory from its side,
+// naming the other memory by its text.
+func (m Model) describeRelation(relation memtypes.MemoryRelation) string {
+	outgoing := relation.FromID == m.SelectedMemory.ID
+	otherID := relation.ToID
+	if !outgoing {
+		otherID = relation.FromID
+	}
+
+	var verb string
+	switch {
+	case relation.Kind == memtypes.RelationSupersedes && outgoing:
+		verb = "supersedes"
+	case relation.Kind == memtypes.RelationSupersedes:
+		verb = "superseded by"
+	case relation.Kind == memtypes.RelationDerivedFrom && outgoing:
+		verb = "derived from"
+	case relation.Kind == memtypes.RelationDerivedFrom:
+		verb = "source of"
+	default:
+		verb = "relates to"
+	}
+
+	other := otherID
+	for _, mem := range m.Memories {
+		if mem.ID == otherID {
+			other = fmt.Sprintf("%s (id: %s)", mem.Text, otherID)
+			break
+		}
+	}
+	return verb + " " + other
+}
+
+func loadPendingReview() tea.Cmd {
+	return func() tea.Msg {
+		memories, err := memoryservice.ReviewQueue(context.Background())
+		return PendingReviewLoadedMsg{Memories: memories, Err: err}
+	}
+}
+
+func reviewMemory(id string, decision memoryservice.ReviewDecision, text string) tea.Cmd {
+	return func() tea.Msg {
+		_, err := memoryservice.Review(context.Background(), memoryservice.ReviewInput{
+			ID:       id,
+			Decision: decision,
+			Text:     text,
+		})
+		return MemoryReviewedMsg{Decision: decision, Err: err}
+	}
+}
+
+func createReviewEditInput(mem memtypes.MemoryItem) textinput.Model {
+	input := textinput.New()
+	input.Placeholder = "Enter preference or fact..."
+	input.CharLimit = 500
+	input.Width = 60
+	input.SetValue(mem.Text)
+	return input
+}
+
 func saveNewMemory(text string, tags []string) tea.Cmd {
 	return func() tea.Msg {
 		_, err := memoryservice.Save(context.Background(), memoryservice.SaveInput{
@@ -92,7 +238,7 @@ func saveNewMemory(text string, tags []string) tea.Cmd {
 	}
 }
 
-func updateMemory(id, text string, tags []string) tea.Cmd {
+func updateMemory(old memtypes.MemoryItem, text string, tags []string) tea.Cmd {
 	return func() tea.Msg {
 		memStore, err := store.NewStore()
 		if err != nil {
@@ -100,16 +246,37 @@ func updateMemory(id, text string, tags []string) tea.Cmd {
 		}
 		defer memStore.Close()
 
-		// Delete old and save new (simple update strategy)
-		_ = memStore.DeleteMemory(id)
-		_, err = memoryservice.Save(context.Background(), memoryservice.SaveInput{
-			Text: text,
-			Tags: tags,
+		// Delete old and save new (simple update strategy), keeping its flags.
+		// Tags the user cleared stay cleared.
+		_ = memStore.DeleteMemory(old.ID)
+		autoTag := false
+		result, err := memoryservice.Save(context.Background(), memoryservice.SaveInput{
+			Text:    text,
+			Tags:    tags,
+			Pinned:  old.Pinned,
+			AutoTag: &autoTag,
 		})
+		if err == nil && old.Suppressed {
+			_, err = memStore.SetMemorySuppressed(result.Item.ID, true)
+		}
 		return MemorySavedMsg{Err: err}
 	}
 }
 
+func setMemoryPinned(id string, pinned bool) tea.Cmd {
+	return func() tea.Msg {
+		_, err := memoryservice.Pin(context.Background(), memoryservice.PinInput{ID: id, Pinned: pinned})
+		return MemoryPinnedMsg{Pinned: pinned, Err: err}
+	}
+}
+
+func setMemorySuppressed(id string, suppressed bool) tea.Cmd {
+	return func() tea.Msg {
+		_, err := memoryservice.Suppress(context.Background(), memoryservice.SuppressInput{ID: id, Suppressed: suppressed})
+		return MemorySuppressedMsg{Suppressed: suppressed, Err: err}
+	}
+}
+
 func deleteMemory(id string) tea.Cmd {
 	return func() tea.Msg {
 		memStore, err := store.NewStore()
@@ -123,6 +290,26 @@ func deleteMemory(id string) tea.Cmd {
 	}
 }
 
+func createClearConfirmInput() textinput.Model {
+	input := textinput.New()
+	input.Placeholder = clearConfirmPhrase
+	input.CharLimit = 50
+	input.Width = 40
+	return input
+}
+
+func clearAllMemories() tea.Cmd {
+	return func() tea.Msg {
+		result, err := memoryservice.Clear(context.Background(), memoryservice.ClearInput{
+			Target: memoryservice.ClearMemories,
+		})
+		if err != nil {
+			return MemoriesClearedMsg{Err: err}
+		}
+		return MemoriesClearedMsg{Count: result.Memories, BackupPath: result.BackupPath}
+	}
+}
+
 func parseTags(input string) []string {
 	if strings.TrimSpace(input) == "" {
 		return nil
@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+func TestSortMemories(t *testing.T) {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(days int) *time.Time {
+		t := base.AddDate(0, 0, days)
+		return &t
+	}
+	memories := []memtypes.MemoryItem{
+		{ID: "old-unused", CreatedAt: base},
+		{ID: "popular", CreatedAt: base.AddDate(0, 0, 1), TimesRetrieved: 9, LastRetrievedAt: at(5)},
+		{ID: "stale", CreatedAt: base.AddDate(0, 0, 2), TimesRetrieved: 2, LastRetrievedAt: at(3)},
+		{ID: "new-unused", CreatedAt: base.AddDate(0, 0, 3)},
+	}
+
+	cases := map[MemorySort][]string{
+		SortNewest:            {"new-unused", "stale", "popular", "old-unused"},
+		SortMostRetrieved:     {"popular", "stale", "new-unused", "old-unused"},
+		SortLeastRecentlyUsed: {"new-unused", "old-unused", "stale", "popular"},
+	}
+	for order, want := range cases {
+		sorted := append([]memtypes.MemoryItem(nil), memories...)
+		sortMemories(sorted, order)
+		for i, m := range sorted {
+			if m.ID != want[i] {
+				t.Errorf("%s: position %d is %q, want %q", order, i, m.ID, want[i])
+			}
+		}
+	}
+}
+
+func TestRetrievalStats(t *testing.T) {
+	last := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+	cases := []struct {
+		mem  memtypes.MemoryItem
+		want string
+	}{
+		{memtypes.MemoryItem{}, "never"},
+		{memtypes.MemoryItem{LastRetrievedAt: &last}, "last 2026-03-01 12:30:00"},
+		{memtypes.MemoryItem{TimesRetrieved: 1, LastRetrievedAt: &last}, "once, last 2026-03-01 12:30:00"},
+		{memtypes.MemoryItem{TimesRetrieved: 4, LastRetrievedAt: &last}, "4 times, last 2026-03-01 12:30:00"},
+	}
+	for _, c := range cases {
+		if got := retrievalStats(c.mem); got != c.want {
+			t.Errorf("retrievalStats(%+v) = %q, want %q", c.mem, got, c.want)
+		}
+	}
+}
@@ -1,14 +1,18 @@
 package memory
 
 import (
+	"fmt"
+
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
+
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/austiecodes/gomor/internal/termcap"
 )
 
 func initialModel() Model {
 	// Create an empty list initially, will be populated after load
-	delegate := list.NewDefaultDelegate()
-	l := list.New([]list.Item{}, delegate, 60, 14)
+	l := termcap.NewList([]list.Item{}, 60, 14)
 	l.Title = "Memories"
 	l.SetShowStatusBar(true)
 	l.SetFilteringEnabled(true)
@@ -21,7 +25,19 @@ func initialModel() Model {
 	}
 }
 
+// initialReviewModel opens the TUI on the review queue.
+func initialReviewModel() Model {
+	m := initialModel()
+	m.Screen = ScreenReview
+	m.ReviewOnly = true
+	m.StatusMsg = "Loading review queue..."
+	return m
+}
+
 func (m Model) Init() tea.Cmd {
+	if m.ReviewOnly {
+		return loadPendingReview()
+	}
 	return loadMemories()
 }
 
@@ -36,24 +52,22 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c", "q":
-			if m.Screen == ScreenMemoryList {
+			if msg.String() == "q" && m.Screen == ScreenReviewEdit {
+				break // typed into the text being edited
+			}
+			if m.Screen == ScreenMemoryList || (m.ReviewOnly && m.Screen == ScreenReview) {
 				m.Quitting = true
 				return m, tea.Quit
 			}
-			// Go back to list
-			m.Screen = ScreenMemoryList
-			m.SelectedMemory = nil
-			m.Err = nil
-			m.StatusMsg = ""
-			return m, nil
+			return m.back()
 
 		case "esc":
+			if m.ReviewOnly && m.Screen == ScreenReview {
+				m.Quitting = true
+				return m, tea.Quit
+			}
 			if m.Screen != ScreenMemoryList {
-				m.Screen = ScreenMemoryList
-				m.SelectedMemory = nil
-				m.Err = nil
-				m.StatusMsg = ""
-				return m, nil
+				return m.back()
 			}
 		}
 
@@ -64,7 +78,20 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		m.Memories = msg.Memories
-		m.List = createMemoryList(m.Memories, m.Width, m.Height)
+		sortMemories(m.Memories, m.Sort)
+		m.List = createMemoryList(m.Memories, m.Sort, m.Width, m.Height)
+		return m, nil
+
+	case RelationsLoadedMsg:
+		// Ignore relations of a memory that is no longer shown.
+		if m.SelectedMemory == nil || m.SelectedMemory.ID != msg.MemoryID {
+			return m, nil
+		}
+		if msg.Err != nil {
+			m.Err = msg.Err
+			return m, nil
+		}
+		m.Relations = msg.Relations
 		return m, nil
 
 	case MemorySavedMsg:
@@ -80,6 +107,79 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.StatusMsg = "Memory saved!"
 		return m, loadMemories()
 
+	case PendingReviewLoadedMsg:
+		if msg.Err != nil {
+			m.StatusMsg = ""
+			m.Err = msg.Err
+			return m, nil
+		}
+		if m.StatusMsg == "Loading review queue..." {
+			m.StatusMsg = ""
+		}
+		m.Pending = msg.Memories
+		m.ReviewIndex = min(m.ReviewIndex, max(len(m.Pending)-1, 0))
+		return m, nil
+
+	case MemoryReviewedMsg:
+		m.StatusMsg = ""
+		if msg.Err != nil {
+			m.Err = msg.Err
+			return m, nil
+		}
+		m.Screen = ScreenReview
+		m.Err = nil
+		m.StatusMsg = "Memory rejected."
+		if msg.Decision == memoryservice.ReviewApprove {
+			m.StatusMsg = "Memory approved!"
+		}
+		if m.ReviewOnly {
+			return m, loadPendingReview()
+		}
+		return m, tea.Batch(loadPendingReview(), loadMemories())
+
+	case MemoriesClearedMsg:
+		m.StatusMsg = ""
+		if msg.Err != nil {
+			m.Err = msg.Err
+			return m, nil
+		}
+		m.Screen = ScreenMemoryList
+		m.Err = nil
+		m.StatusMsg = fmt.Sprintf("Cleared %d memories (backup: %s)", msg.Count, msg.BackupPath)
+		return m, loadMemories()
+
+	case MemoryPinnedMsg:
+		m.StatusMsg = ""
+		if msg.Err != nil {
+			m.Err = msg.Err
+			return m, nil
+		}
+		// Reload memories and go back to list
+		m.Screen = ScreenMemoryList
+		m.SelectedMemory = nil
+		m.Err = nil
+		m.StatusMsg = "Memory unpinned!"
+		if msg.Pinned {
+			m.StatusMsg = "Memory pinned!"
+		}
+		return m, loadMemories()
+
+	case MemorySuppressedMsg:
+		m.StatusMsg = ""
+		if msg.Err != nil {
+			m.Err = msg.Err
+			return m, nil
+		}
+		// Reload memories and go back to list
+		m.Screen = ScreenMemoryList
+		m.SelectedMemory = nil
+		m.Err = nil
+		m.StatusMsg = "Memory unsuppressed!"
+		if msg.Suppressed {
+			m.StatusMsg = "Memory suppressed; it will not be retrieved."
+		}
+		return m, loadMemories()
+
 	case MemoryDeletedMsg:
 		m.StatusMsg = ""
 		if msg.Err != nil {
@@ -105,11 +205,31 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.updateMemoryEdit(msg)
 	case ScreenConfirmDelete:
 		return m.updateConfirmDelete(msg)
+	case ScreenConfirmClear:
+		return m.updateConfirmClear(msg)
+	case ScreenReview:
+		return m.updateReview(msg)
+	case ScreenReviewEdit:
+		return m.updateReviewEdit(msg)
 	}
 
 	return m, nil
 }
 
+// back leaves the current screen: editing a memory under review returns to
+// the review queue, and every other screen returns to the memory list.
+func (m Model) back() (tea.Model, tea.Cmd) {
+	m.Err = nil
+	m.StatusMsg = ""
+	if m.Screen == ScreenReviewEdit {
+		m.Screen = ScreenReview
+		return m, nil
+	}
+	m.Screen = ScreenMemoryList
+	m.SelectedMemory = nil
+	return m, nil
+}
+
 func (m Model) View() string {
 	return m.renderView()
 }
@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"fmt"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/spf13/cobra"
+)
+
+var relateMemoryFn = memoryservice.Relate
+
+type memoryRelateOutput struct {
+	Message string `json:"message"`
+	*memoryservice.RelateResult
+}
+
+func newRelateCommand() *cobra.Command {
+	var kind string
+	var remove, jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "relate <from-id> <to-id>",
+		Short: "Link two memories",
+		Long: `Record how one memory relates to another. The first memory supersedes,
+relates to, or is derived from the second. Relations are shown in the memory
+detail view of the TUI.
+
+A superseded memory is suppressed, so retrieval returns only the memory that
+replaces it. Removing the relation with --remove leaves it suppressed; use
+'gomor memory --unsuppress' to retrieve it again.`,
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := relateMemoryFn(cmd.Context(), memoryservice.RelateInput{
+				FromID: args[0],
+				ToID:   args[1],
+				Kind:   memtypes.RelationKind(kind),
+				Remove: remove,
+			})
+			if err != nil {
+				return err
+			}
+
+			relation := result.Relation
+			output := memoryRelateOutput{
+				Message:      fmt.Sprintf("Related memory %s to memory %s (%s)", relation.FromID, relation.ToID, relation.Kind),
+				RelateResult: result,
+			}
+			if relation.Kind == memtypes.RelationSupersedes {
+				output.Message = fmt.Sprintf("Memory %s now supersedes memory %s, which will no longer be retrieved", relation.FromID, relation.ToID)
+			}
+			switch {
+			case remove && result.Found:
+				output.Message = fmt.Sprintf("Removed relation of memory %s to memory %s (%s)", relation.FromID, relation.ToID, relation.Kind)
+			case remove:
+				output.Message = fmt.Sprintf("Memory %s has no %s relation to memory %s", relation.FromID, relation.Kind, relation.ToID)
+			}
+
+			if jsonOutput {
+				return writeJSON(cmd.OutOrStdout(), output)
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), output.Message)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&kind, "kind", string(memtypes.RelationRelatesTo), "relation: supersedes, relates-to, or derived-from")
+	cmd.Flags().BoolVar(&remove, "remove", false, "remove the relation instead of adding it")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "emit structured JSON output")
+	return cmd
+}
@@ -0,0 +1,163 @@
+package memory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reviewQueueFn   = memoryservice.ReviewQueue
+	reviewMemoryFn  = memoryservice.Review
+	runReviewMemory = func() error {
+		p := tea.NewProgram(initialReviewModel(), tea.WithAltScreen())
+		if _, err := p.Run(); err != nil {
+			return fmt.Errorf("error running review queue: %w", err)
+		}
+		return nil
+	}
+)
+
+type memoryReviewItem struct {
+	ID        string   `json:"id"`
+	Text      string   `json:"text"`
+	Tags      []string `json:"tags,omitempty"`
+	Kind      string   `json:"kind,omitempty"`
+	CreatedAt string   `json:"created_at"`
+}
+
+type memoryReviewListOutput struct {
+	Pending []memoryReviewItem `json:"pending"`
+}
+
+type memoryReviewOutput struct {
+	Message  string `json:"message"`
+	ID       string `json:"id"`
+	Decision string `json:"decision"`
+	Edited   bool   `json:"edited,omitempty"`
+	Found    bool   `json:"found"`
+	Pending  bool   `json:"pending,omitempty"`
+}
+
+func newReviewCommand() *cobra.Command {
+	var approveID, rejectID, text string
+	var listOnly, jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "review",
+		Short: "Approve or reject memories saved by agents",
+		Long: `Review the memories agents saved through the MCP server. They wait in a queue
+and are not retrieved until approved, so a bad extraction never reaches your
+context. Set memory.auto_approve_extracted to skip the queue.
+
+Without flags, opens an interactive review screen:
+  a      approve
+  e      edit the text, then approve
+  r      reject (delete)
+  n / p  next / previous memory
+
+The flags review without the interactive screen.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if countNonEmpty(approveID, rejectID) > 1 || (listOnly && countNonEmpty(approveID, rejectID) > 0) {
+				return fmt.Errorf("only one of --list, --approve, or --reject can be used at a time")
+			}
+			if text != "" && approveID == "" {
+				return fmt.Errorf("--text can only be used with --approve")
+			}
+
+			switch {
+			case listOnly:
+				return runReviewList(cmd, jsonOutput)
+			case approveID != "":
+				return runReviewDecision(cmd, approveID, memoryservice.ReviewApprove, text, jsonOutput)
+			case rejectID != "":
+				return runReviewDecision(cmd, rejectID, memoryservice.ReviewReject, "", jsonOutput)
+			}
+
+			if jsonOutput {
+				return fmt.Errorf("--json requires --list, --approve, or --reject")
+			}
+			return runReviewMemory()
+		},
+	}
+
+	cmd.Flags().BoolVar(&listOnly, "list", false, "list the memories awaiting review")
+	cmd.Flags().StringVar(&approveID, "approve", "", "approve the memory with this ID")
+	cmd.Flags().StringVar(&rejectID, "reject", "", "reject and delete the memory with this ID")
+	cmd.Flags().StringVar(&text, "text", "", "replace the memory's text before approving it")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "emit structured JSON output")
+	return cmd
+}
+
+func runReviewList(cmd *cobra.Command, jsonOutput bool) error {
+	pending, err := reviewQueueFn(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		output := memoryReviewListOutput{Pending: make([]memoryReviewItem, 0, len(pending))}
+		for _, item := range pending {
+			output.Pending = append(output.Pending, reviewItem(item))
+		}
+		return writeJSON(cmd.OutOrStdout(), output)
+	}
+
+	if len(pending) == 0 {
+		_, err := fmt.Fprintln(cmd.OutOrStdout(), "No memories awaiting review.")
+		return err
+	}
+	for i, item := range pending {
+		if _, err := fmt.Fprintf(cmd.OutOrStdout(), "%d. %s\n   ID: %s\n", i+1, item.Text, item.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runReviewDecision(cmd *cobra.Command, id string, decision memoryservice.ReviewDecision, text string, jsonOutput bool) error {
+	result, err := reviewMemoryFn(cmd.Context(), memoryservice.ReviewInput{ID: id, Decision: decision, Text: text})
+	if err != nil {
+		return err
+	}
+
+	output := memoryReviewOutput{
+		ID:       result.ID,
+		Decision: string(result.Decision),
+		Edited:   result.Edited,
+		Found:    result.Found,
+		Pending:  result.Pending,
+	}
+	switch {
+	case !result.Found:
+		output.Message = fmt.Sprintf("No memory pending review with ID %s", result.ID)
+	case decision == memoryservice.ReviewReject:
+		output.Message = fmt.Sprintf("Rejected memory %s", result.ID)
+	case result.Edited:
+		output.Message = fmt.Sprintf("Edited and approved memory %s", result.ID)
+	default:
+		output.Message = fmt.Sprintf("Approved memory %s", result.ID)
+	}
+
+	if jsonOutput {
+		return writeJSON(cmd.OutOrStdout(), output)
+	}
+	_, err = fmt.Fprintln(cmd.OutOrStdout(), output.Message)
+	return err
+}
+
+func reviewItem(item memtypes.MemoryItem) memoryReviewItem {
+	return memoryReviewItem{
+		ID:        item.ID,
+		Text:      item.Text,
+		Tags:      item.Tags,
+		Kind:      string(item.Kind),
+		CreatedAt: item.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -4,7 +4,12 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/austiecodes/gomor/internal/client"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
 )
 
 func (m *Model) updateMemoryList(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -17,8 +22,9 @@ func (m *Model) updateMemoryList(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			selected := m.List.SelectedItem().(MemoryListItem)
 			m.SelectedMemory = &selected.Memory
+			m.Relations = nil
 			m.Screen = ScreenMemoryDetail
-			return *m, nil
+			return *m, loadRelations(selected.Memory.ID)
 
 		case "a":
 			// Add new memory
@@ -37,6 +43,53 @@ func (m *Model) updateMemoryList(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.Screen = ScreenConfirmDelete
 			return *m, nil
 
+		case "C":
+			// Clear all memories after a typed confirmation
+			if len(m.Memories) == 0 || m.List.FilterState() == list.Filtering {
+				break
+			}
+			m.TextInputs = []textinput.Model{createClearConfirmInput()}
+			m.FocusedInput = 0
+			m.Err = nil
+			m.Screen = ScreenConfirmClear
+			return *m, m.TextInputs[0].Focus()
+
+		case "p":
+			// Pin or unpin selected memory
+			if len(m.Memories) == 0 || m.List.FilterState() == list.Filtering {
+				break
+			}
+			selected := m.List.SelectedItem().(MemoryListItem)
+			return *m, setMemoryPinned(selected.Memory.ID, !selected.Memory.Pinned)
+
+		case "s":
+			// Suppress or unsuppress selected memory
+			if len(m.Memories) == 0 || m.List.FilterState() == list.Filtering {
+				break
+			}
+			selected := m.List.SelectedItem().(MemoryListItem)
+			return *m, setMemorySuppressed(selected.Memory.ID, !selected.Memory.Suppressed)
+
+		case "o":
+			// Cycle the list order
+			if m.List.FilterState() == list.Filtering {
+				break
+			}
+			m.Sort = (m.Sort + 1) % memorySortCount
+			sortMemories(m.Memories, m.Sort)
+			m.List = createMemoryList(m.Memories, m.Sort, m.Width, m.Height)
+			return *m, nil
+
+		case "R":
+			// Review extracted memories awaiting approval
+			if m.List.FilterState() == list.Filtering {
+				break
+			}
+			m.ReviewIndex = 0
+			m.Err = nil
+			m.Screen = ScreenReview
+			return *m, loadPendingReview()
+
 		case "e":
 			// Edit selected memory
 			if len(m.Memories) == 0 {
@@ -71,6 +124,14 @@ func (m *Model) updateMemoryDetail(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Delete this memory
 			m.Screen = ScreenConfirmDelete
 			return *m, nil
+
+		case "p":
+			// Pin or unpin this memory
+			return *m, setMemoryPinned(m.SelectedMemory.ID, !m.SelectedMemory.Pinned)
+
+		case "s":
+			// Suppress or unsuppress this memory
+			return *m, setMemorySuppressed(m.SelectedMemory.ID, !m.SelectedMemory.Suppressed)
 		}
 	}
 
@@ -133,7 +194,7 @@ func (m *Model) updateMemoryEdit(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			tags := parseTags(m.TextInputs[1].Value())
 			m.StatusMsg = "Updating..."
-			return *m, updateMemory(m.SelectedMemory.ID, text, tags)
+			return *m, updateMemory(*m.SelectedMemory, text, tags)
 		}
 	}
 
@@ -161,6 +222,74 @@ func (m *Model) updateConfirmDelete(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return *m, nil
 }
 
+func (m *Model) updateConfirmClear(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == "enter" {
+		if strings.TrimSpace(m.TextInputs[0].Value()) != clearConfirmPhrase {
+			m.Err = fmt.Errorf("type %q to confirm", clearConfirmPhrase)
+			return *m, nil
+		}
+		m.Err = nil
+		m.StatusMsg = "Backing up and clearing..."
+		return *m, clearAllMemories()
+	}
+
+	var cmd tea.Cmd
+	m.TextInputs[0], cmd = m.TextInputs[0].Update(msg)
+	return *m, cmd
+}
+
+func (m *Model) updateReview(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || len(m.Pending) == 0 {
+		return *m, nil
+	}
+	current := m.Pending[m.ReviewIndex]
+
+	switch keyMsg.String() {
+	case "a", "y":
+		m.StatusMsg = "Approving..."
+		return *m, reviewMemory(current.ID, memoryservice.ReviewApprove, "")
+
+	case "r", "x":
+		m.StatusMsg = "Rejecting..."
+		return *m, reviewMemory(current.ID, memoryservice.ReviewReject, "")
+
+	case "e":
+		m.TextInputs = []textinput.Model{createReviewEditInput(current)}
+		m.FocusedInput = 0
+		m.Err = nil
+		m.Screen = ScreenReviewEdit
+		return *m, m.TextInputs[0].Focus()
+
+	case "n", "j", "right", "down", "tab":
+		m.ReviewIndex = (m.ReviewIndex + 1) % len(m.Pending)
+		m.StatusMsg = ""
+
+	case "p", "k", "left", "up", "shift+tab":
+		m.ReviewIndex = (m.ReviewIndex - 1 + len(m.Pending)) % len(m.Pending)
+		m.StatusMsg = ""
+	}
+
+	return *m, nil
+}
+
+func (m *Model) updateReviewEdit(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == "enter" {
+		text := strings.TrimSpace(m.TextInputs[0].Value())
+		if text == "" {
+			m.Err = fmt.Errorf("memory text is required")
+			return *m, nil
+		}
+		m.Err = nil
+		m.StatusMsg = "Approving..."
+		return *m, reviewMemory(m.Pending[m.ReviewIndex].ID, memoryservice.ReviewApprove, text)
+	}
+
+	var cmd tea.Cmd
+	m.TextInputs[0], cmd = m.TextInputs[0].Update(msg)
+	return *m, cmd
+}
+
 func (m *Model) renderView() string {
 	if m.Quitting {
 		return "Goodbye!\n"
@@ -205,6 +334,25 @@ func (m *Model) renderView() string {
 			s.WriteString(DetailValueStyle.Render(string(m.SelectedMemory.Source)))
 			s.WriteString("\n\n")
 
+			s.WriteString(DetailLabelStyle.Render("Retrieved:"))
+			s.WriteString(" ")
+			s.WriteString(DetailValueStyle.Render(retrievalStats(*m.SelectedMemory)))
+			s.WriteString("\n\n")
+
+			if m.SelectedMemory.Pinned {
+				s.WriteString(DetailLabelStyle.Render("Pinned:"))
+				s.WriteString(" ")
+				s.WriteString(DetailValueStyle.Render("always included in retrieved context"))
+				s.WriteString("\n\n")
+			}
+
+			if m.SelectedMemory.Suppressed {
+				s.WriteString(DetailLabelStyle.Render("Suppressed:"))
+				s.WriteString(" ")
+				s.WriteString(DetailValueStyle.Render("kept for the record, never retrieved"))
+				s.WriteString("\n\n")
+			}
+
 			if len(m.SelectedMemory.Tags) > 0 {
 				s.WriteString(DetailLabelStyle.Render("Tags:"))
 				s.WriteString(" ")
@@ -217,7 +365,17 @@ func (m *Model) renderView() string {
 				s.WriteString("\n\n")
 			}
 
-			s.WriteString(HelpStyle.Render("Press 'e' to edit, 'd' to delete, Esc to go back"))
+			if len(m.Relations) > 0 {
+				s.WriteString(DetailLabelStyle.Render("Relations:"))
+				s.WriteString("\n")
+				for _, relation := range m.Relations {
+					s.WriteString(DetailValueStyle.Render(m.describeRelation(relation)))
+					s.WriteString("\n")
+				}
+				s.WriteString("\n")
+			}
+
+			s.WriteString(HelpStyle.Render("Press 'e' to edit, 'd' to delete, 'p' to pin/unpin, 's' to suppress/unsuppress, Esc to go back"))
 		}
 
 	case ScreenMemoryAdd:
@@ -255,6 +413,64 @@ func (m *Model) renderView() string {
 			s.WriteString("\n\n")
 		}
 		s.WriteString(HelpStyle.Render("Press 'y' to confirm, 'n' or Esc to cancel"))
+
+	case ScreenConfirmClear:
+		s.WriteString(WarningStyle.Render("Clear All Memories"))
+		s.WriteString("\n\n")
+		s.WriteString(fmt.Sprintf("This will permanently delete all %d memories.\n", len(m.Memories)))
+		s.WriteString("A backup of the database is written first.\n\n")
+		s.WriteString(InputLabelStyle.Render(fmt.Sprintf("Type %q to confirm", clearConfirmPhrase)))
+		s.WriteString("\n")
+		s.WriteString(m.TextInputs[0].View())
+		s.WriteString("\n\n")
+		s.WriteString(HelpStyle.Render("Press Enter to confirm, Esc to cancel"))
+
+	case ScreenReview:
+		if len(m.Pending) == 0 {
+			s.WriteString(TitleStyle.Render("Review Queue"))
+			s.WriteString("\n\n")
+			s.WriteString(SubtitleStyle.Render("No memories awaiting review."))
+			s.WriteString("\n\n")
+			s.WriteString(HelpStyle.Render("Press Esc to go back"))
+			break
+		}
+
+		current := m.Pending[m.ReviewIndex]
+		s.WriteString(TitleStyle.Render(fmt.Sprintf("Review Queue (%d of %d)", m.ReviewIndex+1, len(m.Pending))))
+		s.WriteString("\n\n")
+
+		s.WriteString(DetailLabelStyle.Render("Text:"))
+		s.WriteString("\n")
+		s.WriteString(DetailValueStyle.Render(current.Text))
+		s.WriteString("\n\n")
+
+		s.WriteString(DetailLabelStyle.Render("Saved:"))
+		s.WriteString(" ")
+		s.WriteString(DetailValueStyle.Render(current.CreatedAt.Format("2006-01-02 15:04:05")))
+		s.WriteString("\n\n")
+
+		if len(current.Tags) > 0 {
+			s.WriteString(DetailLabelStyle.Render("Tags:"))
+			s.WriteString(" ")
+			for i, tag := range current.Tags {
+				if i > 0 {
+					s.WriteString(" ")
+				}
+				s.WriteString(TagStyle.Render(tag))
+			}
+			s.WriteString("\n\n")
+		}
+
+		s.WriteString(HelpStyle.Render("Press 'a' to approve, 'e' to edit and approve, 'r' to reject, 'n'/'p' for next/previous, Esc to go back"))
+
+	case ScreenReviewEdit:
+		s.WriteString(TitleStyle.Render("Edit and Approve Memory"))
+		s.WriteString("\n\n")
+		s.WriteString(InputLabelStyle.Render("Memory Text (required)"))
+		s.WriteString("\n")
+		s.WriteString(m.TextInputs[0].View())
+		s.WriteString("\n\n")
+		s.WriteString(HelpStyle.Render("Press Enter to approve, Esc to cancel"))
 	}
 
 	if m.StatusMsg != "" {
@@ -265,6 +481,10 @@ func (m *Model) renderView() string {
 	if m.Err != nil {
 		s.WriteString("\n\n")
 		s.WriteString(ErrorStyle.Render(fmt.Sprintf("Error: %v", m.Err)))
+		if hint := client.Guidance(m.Err); hint != "" {
+			s.WriteString("\n")
+			s.WriteString(HelpStyle.Render("Hint: " + hint))
+		}
 	}
 
 	return s.String()
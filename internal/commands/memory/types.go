@@ -1,11 +1,15 @@
 package memory
 
 import (
+	"fmt"
+
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 
 	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/austiecodes/gomor/internal/termcap"
 )
 
 // Screen represents the current TUI screen
@@ -17,15 +21,60 @@ const (
 	ScreenMemoryAdd
 	ScreenMemoryEdit
 	ScreenConfirmDelete
+	ScreenConfirmClear
+	ScreenReview
+	ScreenReviewEdit
 )
 
+// MemorySort is the order of the memory list.
+type MemorySort int
+
+const (
+	SortNewest MemorySort = iota
+	SortMostRetrieved
+	// SortLeastRecentlyUsed puts never-retrieved memories first, then those
+	// retrieved longest ago: the candidates for pruning.
+	SortLeastRecentlyUsed
+	memorySortCount
+)
+
+func (s MemorySort) String() string {
+	switch s {
+	case SortMostRetrieved:
+		return "most retrieved"
+	case SortLeastRecentlyUsed:
+		return "least recently used"
+	default:
+		return "newest"
+	}
+}
+
+// clearConfirmPhrase must be typed to confirm clearing all memories in the TUI
+const clearConfirmPhrase = "clear memories"
+
 // MemoryListItem implements list.Item interface for memory display
 type MemoryListItem struct {
 	Memory memtypes.MemoryItem
 }
 
-func (i MemoryListItem) Title() string       { return i.Memory.Text }
-func (i MemoryListItem) Description() string { return i.Memory.CreatedAt.Format("2006-01-02 15:04") }
+func (i MemoryListItem) Title() string { return i.Memory.Text }
+func (i MemoryListItem) Description() string {
+	sep := termcap.Glyph(" · ", " - ")
+	desc := i.Memory.CreatedAt.Format("2006-01-02 15:04")
+	if i.Memory.Pinned {
+		desc = "pinned" + sep + desc
+	}
+	if i.Memory.Suppressed {
+		desc = "suppressed" + sep + desc
+	}
+	if i.Memory.PendingReview {
+		desc = "pending review" + sep + desc
+	}
+	if i.Memory.TimesRetrieved > 0 {
+		desc += fmt.Sprintf("%sretrieved %d%s", sep, i.Memory.TimesRetrieved, termcap.Glyph("×", "x"))
+	}
+	return desc
+}
 func (i MemoryListItem) FilterValue() string { return i.Memory.Text }
 
 // Model is the Bubble Tea model for the memory command
@@ -37,11 +86,22 @@ type Model struct {
 	FocusedInput   int
 	SelectedMemory *memtypes.MemoryItem
 	Memories       []memtypes.MemoryItem
+	Sort           MemorySort
 	Err            error
 	StatusMsg      string
 	Quitting       bool
 	Width          int
 	Height         int
+
+	// Relations of SelectedMemory, loaded when its detail view opens
+	Relations []memtypes.MemoryRelation
+
+	// Review queue of extracted memories awaiting approval
+	Pending     []memtypes.MemoryItem
+	ReviewIndex int
+	// ReviewOnly is set when the TUI was opened by `gomor memory review`;
+	// leaving the review screen quits instead of showing the memory list.
+	ReviewOnly bool
 }
 
 // MemoriesLoadedMsg is sent when memories are loaded from store
@@ -59,3 +119,41 @@ type MemorySavedMsg struct {
 type MemoryDeletedMsg struct {
 	Err error
 }
+
+// MemoryPinnedMsg is sent when a memory is pinned or unpinned
+type MemoryPinnedMsg struct {
+	Pinned bool
+	Err    error
+}
+
+// MemorySuppressedMsg is sent when a memory is suppressed or unsuppressed
+type MemorySuppressedMsg struct {
+	Suppressed bool
+	Err        error
+}
+
+// RelationsLoadedMsg is sent when the relations of a memory are loaded
+type RelationsLoadedMsg struct {
+	MemoryID  string
+	Relations []memtypes.MemoryRelation
+	Err       error
+}
+
+// PendingReviewLoadedMsg is sent when the review queue is loaded
+type PendingReviewLoadedMsg struct {
+	Memories []memtypes.MemoryItem
+	Err      error
+}
+
+// MemoryReviewedMsg is sent when a memory pending review is approved or rejected
+type MemoryReviewedMsg struct {
+	Decision memoryservice.ReviewDecision
+	Err      error
+}
+
+// MemoriesClearedMsg is sent when all memories are cleared
+type MemoriesClearedMsg struct {
+	Count      int
+	BackupPath string
+	Err        error
+}
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+// maxHeadingChars bounds the prompt quoted in the heading of an appended answer.
+const maxHeadingChars = 80
+
+// answerFile receives a copy of the streamed answer for --output.
+type answerFile struct {
+	*os.File
+	last byte // last byte written, to end the file with a newline
+}
+
+// openAnswerFile opens path to receive the answer to prompt. A new file, or
+// one being overwritten, starts with frontmatter naming the model, the time,
+// and the prompt. With appendMode, an answer added to a file that already has
+// content goes under a heading quoting the prompt instead, so the frontmatter
+// stays at the top.
+func openAnswerFile(path string, appendMode bool, model types.Model, prompt string, now time.Time) (*answerFile, error) {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendMode {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	f, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open output file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to open output file: %w", err)
+	}
+
+	var header strings.Builder
+	modelName := model.Provider + "/" + model.ModelID
+	if info.Size() == 0 {
+		header.WriteString("---\n")
+		fmt.Fprintf(&header, "model: %s\n", modelName)
+		fmt.Fprintf(&header, "date: %s\n", now.UTC().Format(time.RFC3339))
+		fmt.Fprintf(&header, "prompt: %s\n", strconv.Quote(prompt))
+		header.WriteString("---\n\n")
+	} else {
+		fmt.Fprintf(&header, "\n## %s\n\n", heading(prompt))
+		fmt.Fprintf(&header, "*%s · %s*\n\n", modelName, now.Local().Format("2006-01-02 15:04"))
+	}
+	a := &answerFile{File: f}
+	if _, err := a.WriteString(header.String()); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to write output file: %w", err)
+	}
+	return a, nil
+}
+
+func (a *answerFile) Write(p []byte) (int, error) {
+	if len(p) > 0 {
+		a.last = p[len(p)-1]
+	}
+	return a.File.Write(p)
+}
+
+func (a *answerFile) WriteString(s string) (int, error) {
+	return a.Write([]byte(s))
+}
+
+// Close ends the file with a newline and closes it.
+func (a *answerFile) Close() error {
+	if a.last != '\n' {
+		if _, err := a.File.WriteString("\n"); err != nil {
+			a.File.Close()
+			return err
+		}
+	}
+	return a.File.Close()
+}
+
+// heading shortens prompt to one line for the heading of an appended answer.
+func heading(prompt string) string {
+	line := strings.Join(strings.Fields(prompt), " ")
+	if runes := []rune(line); len(runes) > maxHeadingChars {
+		line = string(runes[:maxHeadingChars-3]) + "..."
+	}
+	return line
+}
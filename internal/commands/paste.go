@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/atotto/clipboard"
+)
+
+// maxPasteBytes caps how much of the clipboard --paste adds to a prompt, so a
+// stray copy of a whole log file does not use up the context window.
+const maxPasteBytes = 32 * 1024
+
+// readClipboardFn returns the text on the system clipboard.
+var readClipboardFn = clipboard.ReadAll
+
+// pasteClipboard returns prompt followed by the clipboard text in a code
+// fence, or the clipboard text alone when prompt is empty. Text over
+// maxPasteBytes is cut short with a warning on errOut.
+func pasteClipboard(prompt string, errOut io.Writer) (string, error) {
+	text, err := readClipboardFn()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the clipboard: %w", err)
+	}
+	text = strings.Trim(text, "\r\n")
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("the clipboard is empty")
+	}
+	if len(text) > maxPasteBytes {
+		fmt.Fprintf(errOut, "Warning: the clipboard holds %d bytes; sending the first %d\n", len(text), maxPasteBytes)
+		cut := maxPasteBytes
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = text[:cut]
+	}
+	if prompt == "" {
+		return text, nil
+	}
+
+	// The fence is longer than any run of backticks in the text, so code
+	// fences copied along with it cannot close it early.
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return prompt + "\n\n" + fence + "\n" + text + "\n" + fence, nil
+}
@@ -0,0 +1,87 @@
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/austiecodes/gomor/internal/plugin"
+	"github.com/spf13/cobra"
+)
+
+// listFn returns the plugins on PATH.
+var listFn = plugin.List
+
+type listCommandOptions struct {
+	jsonOutput bool
+}
+
+var PluginCmd = newPluginCommand()
+
+func newPluginCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plugin",
+		Short: "Find the plugins that extend gomor",
+		Long: `Plugins are executables on PATH whose names start with "gomor-":
+
+  gomor-<command>          runs as 'gomor <command> [args...]'
+  gomor-provider-<name>    serves models for provider "<name>" in the config
+  gomor-ingest-<ext>       converts .<ext> files to text for 'gomor ingest'
+
+A provider plugin is run as "gomor-provider-<name> chat", which reads
+{"model": {...}, "system": "...", "prompt": "..."} on stdin and streams the
+reply as text on stdout; "embed", which reads {"model": {...}, "texts": [...]}
+and writes {"embeddings": [[...], ...]}; and "models", which writes one model
+ID per line. An ingest plugin gets the file's path as its argument and writes
+the file's text to stdout. A non-zero exit fails the call, with stderr as the
+message.
+
+Plugins run with GOMOR_BIN, GOMOR_CONFIG, and GOMOR_DB set. Built-in commands
+and providers take precedence over plugins with the same name.`,
+	}
+	cmd.AddCommand(newListCommand())
+	return cmd
+}
+
+func newListCommand() *cobra.Command {
+	opts := &listCommandOptions{}
+
+	cmd := &cobra.Command{
+		Use:          "list",
+		Short:        "List the plugins on PATH",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runListCommand(cmd, opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.jsonOutput, "json", false, "emit structured JSON output")
+
+	return cmd
+}
+
+func runListCommand(cmd *cobra.Command, opts *listCommandOptions) error {
+	plugins := listFn()
+	out := cmd.OutOrStdout()
+
+	if opts.jsonOutput {
+		if plugins == nil {
+			plugins = []plugin.Plugin{}
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(plugins)
+	}
+
+	if len(plugins) == 0 {
+		fmt.Fprintln(out, "No plugins found on PATH (executables named gomor-*).")
+		return nil
+	}
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAME\tPATH")
+	for _, p := range plugins {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", p.Kind, p.Name, p.Path)
+	}
+	return w.Flush()
+}
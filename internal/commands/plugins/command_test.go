@@ -0,0 +1,62 @@
+package plugins
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/plugin"
+)
+
+func TestListCommandPrintsPlugins(t *testing.T) {
+	oldList := listFn
+	defer func() { listFn = oldList }()
+	listFn = func() []plugin.Plugin {
+		return []plugin.Plugin{
+			{Name: "hello", Kind: plugin.KindCommand, Path: "/bin/gomor-hello"},
+			{Name: "fake", Kind: plugin.KindProvider, Path: "/bin/gomor-provider-fake"},
+		}
+	}
+
+	cmd := newPluginCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"list"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if !strings.Contains(out.String(), "provider  fake   /bin/gomor-provider-fake") {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+
+	out.Reset()
+	cmd.SetArgs([]string{"list", "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute --json: %v", err)
+	}
+	var plugins []plugin.Plugin
+	if err := json.Unmarshal(out.Bytes(), &plugins); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(plugins) != 2 || plugins[0].Name != "hello" {
+		t.Fatalf("unexpected plugins %+v", plugins)
+	}
+}
+
+func TestListCommandWithoutPlugins(t *testing.T) {
+	oldList := listFn
+	defer func() { listFn = oldList }()
+	listFn = func() []plugin.Plugin { return nil }
+
+	cmd := newPluginCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"list", "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if strings.TrimSpace(out.String()) != "[]" {
+		t.Fatalf("expected an empty JSON list, got %q", out.String())
+	}
+}
@@ -0,0 +1,449 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/chat"
+	"github.com/austiecodes/gomor/internal/exitcode"
+	"github.com/austiecodes/gomor/internal/hooks"
+	"github.com/austiecodes/gomor/internal/markdown"
+	"github.com/austiecodes/gomor/internal/memory/citation"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/moderation"
+	"github.com/austiecodes/gomor/internal/pricing"
+	"github.com/austiecodes/gomor/internal/provider"
+	"github.com/austiecodes/gomor/internal/tokenizer"
+	"github.com/austiecodes/gomor/internal/trace"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+	"github.com/austiecodes/gomor/internal/websearch"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+type queryOptions struct {
+	codeOnly      bool
+	allBlocks     bool
+	raw           bool
+	enforceBudget bool
+	seed          int64
+	stop          []string
+	web           bool
+	citations     bool
+	output        string
+	appendOutput  bool
+	model         string
+	prompt        string
+	paste         bool
+	noMemory      bool
+}
+
+// askFn sends a one-off prompt to the chat model, with systemContext as the
+// system message when it is not empty, streaming the answer into out. Budget
+// warnings go to errOut.
+var askFn = func(ctx context.Context, config *utils.Config, systemContext, prompt string, enforceBudget bool, out, errOut io.Writer) (string, error) {
+	if config.Model.ChatModel == nil {
+		return "", utils.ConfigErrorf("chat model not configured. Run 'gomor set' to configure")
+	}
+	chatModel := *config.Model.ChatModel
+
+	spinner := chat.NewSpinner(errOut)
+	out, errOut = spinner.Wrap(out), spinner.Wrap(errOut)
+	spinner.Start()
+	defer spinner.Stop()
+
+	queryClient, err := provider.NewRoleQueryClient(config, utils.RoleChat, chatModel, chat.SwitchNotice(errOut))
+	if err != nil {
+		return "", fmt.Errorf("failed to create chat client: %w", err)
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		return "", fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	budget := pricing.NewBudget(memStore, config.Budget, enforceBudget, errOut)
+	return chat.AskWithContext(ctx, queryClient, chatModel, systemContext, prompt, budget, out)
+}
+
+// recordFn saves prompt and answer, given by model, to history as a new
+// session and returns its ID. interrupted marks an answer cut short.
+var recordFn = func(ctx context.Context, config *utils.Config, model types.Model, prompt, answer string, interrupted bool) (string, error) {
+	memStore, err := store.NewStore()
+	if err != nil {
+		return "", fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	moderator, err := moderation.FromConfig(config)
+	if err != nil {
+		return "", err
+	}
+	sessionID := uuid.New().String()
+	if err := memStore.EnsureSession(sessionID); err != nil {
+		return "", err
+	}
+	turn := memtypes.HistoryItem{Role: "user", Content: prompt, SessionID: sessionID}
+	if err := memoryservice.SaveTurn(ctx, memStore, moderator, &turn); err != nil {
+		return "", err
+	}
+	reply := memtypes.HistoryItem{Role: "assistant", Content: answer, SessionID: sessionID, ParentID: turn.ID, Model: memoryservice.TurnModel(model), Interrupted: interrupted}
+	return sessionID, memoryservice.SaveTurn(ctx, memStore, moderator, &reply)
+}
+
+// augmented is a prompt fused with the context gathered for it.
+type augmented struct {
+	prompt     string
+	memoryText string // the memories, when they are not fused into prompt
+	system     string // the system message: chat.system_prompt or memoryText
+	web        []websearch.Result
+	memories   []memtypes.MemoryItem // labeled [M1], [M2], ... when citing
+	degraded   bool                  // memory retrieval failed, in part or in full
+}
+
+// memoryContext introduces the memories sent as the system message of a plain
+// one-off prompt when chat.system_prompt is not set.
+const memoryContext = "These memories are what you know about the user and their work. Use them when they help answer the prompt and ignore them otherwise.\n\nMemories:\n"
+
+// systemPrompt renders the system message of a one-off prompt the way chat
+// renders chat.system_prompt, with memories as {{.Memories}} and no history.
+// Without a system prompt, the memories follow memoryContext, and nothing is
+// sent when there are none.
+func systemPrompt(ctx context.Context, config *utils.Config, memories string) (string, error) {
+	if config.Chat.SystemPrompt == "" {
+		if memories == "" {
+			return "", nil
+		}
+		return memoryContext + memories, nil
+	}
+	builder, err := chat.NewContextBuilder(config.Chat, nil, types.Model{})
+	if err != nil {
+		return "", err
+	}
+	return builder.System(ctx, nil, func() string { return memories })
+}
+
+// augmentFn fuses prompt with fresh web results, when web is set, and the
+// memories relevant to it, unless memory is false. With cite, the memories are
+// labeled for the answer to cite; with neither web nor cite, they are sent as
+// the system message. Memories only add context, so a failed retrieval is
+// reported on errOut instead of failing the prompt.
+var augmentFn = func(ctx context.Context, config *utils.Config, prompt string, web, cite, memory bool, errOut io.Writer) (*augmented, error) {
+	a := &augmented{prompt: prompt}
+	if web {
+		searcher, limit, err := websearch.FromConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		if a.web, err = searcher.Search(ctx, prompt, limit); err != nil {
+			return nil, err
+		}
+	}
+
+	var memories string
+	var injected int
+	// Cited memories are labeled one by one, so they are never summarized.
+	input := memoryservice.RetrieveInput{Query: prompt}
+	if !cite {
+		input.Command = "query"
+	}
+	var retrieved *memoryservice.RetrieveResult
+	var err error
+	if memory {
+		retrieved, err = memoryservice.Retrieve(ctx, input)
+	}
+	plain := !web && !cite
+	if plain && errors.Is(err, utils.ErrConfig) {
+		// Prompts are answered without memories until embeddings are set up.
+		trace.Printf(trace.Info, "memories skipped: %v", err)
+		err = nil
+	}
+	if err == nil && retrieved != nil && retrieved.Response != nil && retrieved.Response.Degraded {
+		a.degraded = true
+		for _, w := range retrieved.Response.Warnings {
+			fmt.Fprintf(errOut, "Warning: memory retrieval degraded: %s\n", w)
+		}
+	}
+	if err != nil {
+		a.degraded = true
+		fmt.Fprintf(errOut, "Warning: memory retrieval failed: %v\n", err)
+	} else if retrieved != nil && retrieved.Response != nil && len(retrieved.Response.Results) > 0 {
+		memories = retrieved.Text
+		injected = len(retrieved.Response.Results)
+		if cite {
+			for _, r := range retrieved.Response.Results {
+				a.memories = append(a.memories, r.Item)
+			}
+			memories = citation.Memories(a.memories)
+		}
+	}
+
+	switch {
+	case web:
+		a.prompt = websearch.Prompt(prompt, memories, a.web)
+	case cite:
+		a.prompt = citation.Prompt(prompt, a.memories)
+	default:
+		a.memoryText = strings.TrimSpace(memories)
+	}
+	trace.Printf(trace.Info, "context: %d memories, %d web results", injected, len(a.web))
+	return a, nil
+}
+
+// partial returns exitcode.ErrPartial when the answer was given without all
+// of the memories it should have had.
+func (a *augmented) partial() error {
+	if a.degraded {
+		return exitcode.ErrPartial
+	}
+	return nil
+}
+
+// footnotes lists the web results and memories answer cites.
+func (a *augmented) footnotes(answer string) string {
+	var parts []string
+	if sources := websearch.Sources(answer, a.web); sources != "" {
+		parts = append(parts, sources)
+	}
+	if memories := citation.Footnotes(answer, a.memories); memories != "" {
+		parts = append(parts, memories+"Mark a wrong memory with 'gomor memory feedback <id> --wrong'.\n")
+	}
+	return strings.Join(parts, "\n")
+}
+
+var loadConfigFn = utils.LoadConfig
+
+var errNoCodeBlock = errors.New("the answer contains no fenced code block")
+
+func addQueryFlags(cmd *cobra.Command, opts *queryOptions) {
+	cmd.Flags().BoolVar(&opts.codeOnly, "code-only", false, "print only the fenced code from the answer (the last block)")
+	cmd.Flags().BoolVar(&opts.allBlocks, "all-blocks", false, "with --code-only, print every code block instead of the last")
+	cmd.Flags().BoolVar(&opts.raw, "raw", false, "print the answer as raw markdown (overrides chat.raw_markdown)")
+	cmd.Flags().BoolVar(&opts.enforceBudget, "enforce-budget", false, "refuse to send the prompt when the budget is used up, instead of warning")
+	cmd.Flags().Int64Var(&opts.seed, "seed", 0, "sampling seed for a repeatable answer (not supported by anthropic)")
+	cmd.Flags().StringArrayVar(&opts.stop, "stop", nil, "end the answer at this sequence (repeatable)")
+	cmd.Flags().BoolVar(&opts.web, "web", false, "answer with fresh web results and relevant memories, citing the sources (needs web_search in the config)")
+	cmd.Flags().BoolVar(&opts.citations, "citations", false, "answer with the relevant memories and list the ones the answer cites")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "also write the answer to this markdown file as it streams, with frontmatter naming the model, time, and prompt")
+	cmd.Flags().BoolVar(&opts.appendOutput, "append", false, "with --output, add the answer to the end of the file instead of replacing it")
+	cmd.Flags().StringVarP(&opts.model, "model", "m", "", "answer with this model instead of the chat model: an alias from model.aliases, a provider/model ID, or a role")
+	cmd.Flags().StringVarP(&opts.prompt, "prompt", "p", "", "send this as the prompt, even if it looks like a command (arguments are appended); same as putting it after --")
+	cmd.Flags().BoolVar(&opts.paste, "paste", false, "append the clipboard contents to the prompt, or send them alone without one (up to 32 KiB)")
+	cmd.Flags().BoolVar(&opts.noMemory, "no-memory", false, "answer without retrieving memories (with --web, from the web results alone)")
+}
+
+// withModel returns chatModel answering with model's provider and model ID,
+// keeping the configured sampling settings.
+func withModel(chatModel *types.Model, model types.Model) *types.Model {
+	var next types.Model
+	if chatModel != nil {
+		next = *chatModel
+	}
+	next.Provider = model.Provider
+	next.ModelID = model.ModelID
+	return &next
+}
+
+// runQuery answers a one-off prompt given as arguments.
+func runQuery(cmd *cobra.Command, args []string, opts *queryOptions) error {
+	if opts.prompt != "" {
+		args = append([]string{opts.prompt}, args...)
+	} else if err := unknownCommandError(cmd, args); err != nil && !opts.paste {
+		cmd.SilenceUsage = true
+		return err
+	}
+	if len(args) == 0 && !opts.paste {
+		return cmd.Help()
+	}
+	if opts.allBlocks && !opts.codeOnly {
+		return fmt.Errorf("--all-blocks requires --code-only")
+	}
+	if opts.appendOutput && opts.output == "" {
+		return fmt.Errorf("--append requires --output")
+	}
+	if opts.noMemory && opts.citations {
+		return fmt.Errorf("--no-memory cannot be used with --citations")
+	}
+	cmd.SilenceUsage = true
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	config, err := loadConfigFn()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	userArgs := strings.Join(args, " ")
+	if opts.paste {
+		if userArgs, err = pasteClipboard(userArgs, cmd.ErrOrStderr()); err != nil {
+			return err
+		}
+	}
+
+	if opts.model != "" {
+		model, err := config.Model.ResolveModel(opts.model)
+		if err != nil {
+			return err
+		}
+		config.Model.ChatModel = withModel(config.Model.ChatModel, model)
+	}
+	if config.Model.ChatModel != nil {
+		chatModel := *config.Model.ChatModel
+		if cmd.Flags().Changed("seed") {
+			chatModel.Seed = &opts.seed
+		}
+		if len(opts.stop) > 0 {
+			chatModel.Stop = opts.stop
+		}
+		config.Model.ChatModel = &chatModel
+	}
+
+	var chatModel types.Model
+	if config.Model.ChatModel != nil {
+		chatModel = *config.Model.ChatModel
+	}
+	runner := hooks.New(config.Hooks)
+	prompt, err := runner.PreQuery(ctx, hooks.Query{Command: "query", Model: hooks.ModelName(chatModel), Prompt: userArgs})
+	if err != nil {
+		return err
+	}
+	userPrompt := prompt
+	out := cmd.OutOrStdout()
+
+	aug := &augmented{prompt: prompt}
+	if opts.web || !opts.noMemory {
+		aug, err = augmentFn(ctx, config, prompt, opts.web, opts.citations, !opts.noMemory, cmd.ErrOrStderr())
+		if err != nil {
+			return err
+		}
+	}
+	if aug.system, err = systemPrompt(ctx, config, aug.memoryText); err != nil {
+		return err
+	}
+	prompt = aug.prompt
+
+	// postResponse saves the answer to history and reports it to the
+	// post_response hook. The answer was already shown, so failures only warn.
+	start := time.Now()
+	postResponse := func(answer string) {
+		if config.History.SavesQueries() {
+			if sessionID, err := recordFn(ctx, config, chatModel, userPrompt, answer, false); err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: failed to save the answer to history: %v\n", err)
+			} else {
+				trace.Printf(trace.Info, "saved as session %s", sessionID)
+			}
+		}
+		counter := tokenizer.ForModel(chatModel)
+		usage := tokenizer.Usage{InputTokens: tokenizer.EstimateInput(counter, aug.system, prompt), OutputTokens: counter.Count(answer)}
+		event := hooks.NewResponse("query", "", chatModel, userPrompt, answer, usage, start)
+		if err := runner.PostResponse(ctx, event); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v\n", err)
+		}
+	}
+
+	// interrupted handles err, the failure of streaming answer, saving what
+	// arrived before Ctrl-C as an interrupted turn.
+	interrupted := func(answer string, err error) error {
+		if !config.History.SavesQueries() {
+			return err
+		}
+		return memoryservice.RecordInterrupted(ctx, answer, err, func(ctx context.Context) error {
+			_, err := recordFn(ctx, config, chatModel, userPrompt, answer, true)
+			return err
+		})
+	}
+
+	// tee receives a raw copy of the answer and its footnotes for --output.
+	var tee io.Writer = io.Discard
+	if opts.output != "" {
+		file, err := openAnswerFile(opts.output, opts.appendOutput, chatModel, userPrompt, time.Now())
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		tee = file
+	}
+
+	if opts.codeOnly {
+		// The answer is buffered so only the extracted code reaches stdout.
+		answer, err := askFn(ctx, config, aug.system, prompt, opts.enforceBudget, tee, cmd.ErrOrStderr())
+		if err != nil {
+			return interrupted(answer, err)
+		}
+		postResponse(answer)
+		if notes := aug.footnotes(answer); notes != "" {
+			fmt.Fprint(cmd.ErrOrStderr(), notes)
+			fmt.Fprint(tee, "\n\n"+notes)
+		}
+		if err := writeCodeBlocks(out, answer, opts.allBlocks); err != nil {
+			return err
+		}
+		return aug.partial()
+	}
+
+	raw := config.Chat.RawMarkdown
+	if cmd.Flags().Changed("raw") {
+		raw = opts.raw
+	}
+
+	answerOut := out
+	var md *markdown.Renderer
+	if !raw {
+		md = markdown.NewRenderer(out)
+		answerOut = md
+	}
+
+	answer, err := askFn(ctx, config, aug.system, prompt, opts.enforceBudget, io.MultiWriter(tee, answerOut), cmd.ErrOrStderr())
+	if md != nil {
+		_ = md.Flush()
+	}
+	if err != nil {
+		return interrupted(answer, err)
+	}
+	postResponse(answer)
+	if _, err := fmt.Fprintln(out); err != nil {
+		return err
+	}
+	if notes := aug.footnotes(answer); notes != "" {
+		fmt.Fprint(tee, "\n\n"+notes)
+		if _, err := fmt.Fprint(out, "\n"+notes); err != nil {
+			return err
+		}
+	}
+	return aug.partial()
+}
+
+// writeCodeBlocks prints the last code block of answer, or all of them
+// separated by blank lines.
+func writeCodeBlocks(out io.Writer, answer string, all bool) error {
+	blocks := markdown.CodeBlocks(answer)
+	if len(blocks) == 0 {
+		return errNoCodeBlock
+	}
+	if !all {
+		blocks = blocks[len(blocks)-1:]
+	}
+
+	for i, b := range blocks {
+		if i > 0 {
+			if _, err := fmt.Fprintln(out); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(out, b.Code); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,497 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/austiecodes/gomor/internal/hooks"
+	"github.com/austiecodes/gomor/internal/memory/citation"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+	"github.com/austiecodes/gomor/internal/websearch"
+	"github.com/spf13/cobra"
+)
+
+// realRecordFn is the recordFn that saves to the history store, for the tests
+// that check what was saved.
+var realRecordFn = recordFn
+
+const codeAnswer = "Here you go:\n```go\nfunc a() {}\n```\nand a test:\n```go\nfunc TestA(t *testing.T) {}\n```\n"
+
+func newTestQueryCommand(answer string) (*cobra.Command, *bytes.Buffer, *string) {
+	var gotPrompt string
+
+	oldAsk, oldLoad, oldAugment, oldRecord := askFn, loadConfigFn, augmentFn, recordFn
+	askFn = func(ctx context.Context, config *utils.Config, systemContext, prompt string, enforceBudget bool, out, errOut io.Writer) (string, error) {
+		gotPrompt = prompt
+		_, _ = io.WriteString(out, answer)
+		return answer, nil
+	}
+	loadConfigFn = func() (*utils.Config, error) { return utils.DefaultConfig(), nil }
+	augmentFn = func(ctx context.Context, config *utils.Config, prompt string, web, cite, memory bool, errOut io.Writer) (*augmented, error) {
+		return &augmented{prompt: prompt}, nil
+	}
+	recordFn = func(ctx context.Context, config *utils.Config, model types.Model, prompt, answer string, interrupted bool) (string, error) {
+		return "session", nil
+	}
+
+	opts := &queryOptions{}
+	cmd := &cobra.Command{
+		Use:  "gomor",
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			defer func() { askFn, loadConfigFn, augmentFn, recordFn = oldAsk, oldLoad, oldAugment, oldRecord }()
+			return runQuery(cmd, args, opts)
+		},
+	}
+	addQueryFlags(cmd, opts)
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(io.Discard)
+	return cmd, &out, &gotPrompt
+}
+
+func TestQueryCodeOnlyPrintsLastBlock(t *testing.T) {
+	cmd, out, prompt := newTestQueryCommand(codeAnswer)
+	cmd.SetArgs([]string{"write", "a", "function", "--code-only"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if *prompt != "write a function" {
+		t.Fatalf("unexpected prompt %q", *prompt)
+	}
+	if got := out.String(); got != "func TestA(t *testing.T) {}\n" {
+		t.Fatalf("unexpected output %q", got)
+	}
+}
+
+func TestQueryCodeOnlyAllBlocks(t *testing.T) {
+	cmd, out, _ := newTestQueryCommand(codeAnswer)
+	cmd.SetArgs([]string{"write code", "--code-only", "--all-blocks"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if got := out.String(); got != "func a() {}\n\nfunc TestA(t *testing.T) {}\n" {
+		t.Fatalf("unexpected output %q", got)
+	}
+}
+
+func TestQueryCodeOnlyWithoutCodeFails(t *testing.T) {
+	cmd, out, _ := newTestQueryCommand("no code here")
+	cmd.SetArgs([]string{"hello", "--code-only"})
+
+	if err := cmd.Execute(); !errors.Is(err, errNoCodeBlock) {
+		t.Fatalf("expected errNoCodeBlock, got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected no output, got %q", out.String())
+	}
+}
+
+func TestQueryRendersAnswerUnlessRaw(t *testing.T) {
+	cmd, out, _ := newTestQueryCommand("# Title\n")
+	cmd.SetArgs([]string{"hello"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if got := out.String(); got != "Title\n\n" {
+		t.Fatalf("unexpected rendered output %q", got)
+	}
+
+	cmd, out, _ = newTestQueryCommand("# Title\n")
+	cmd.SetArgs([]string{"hello", "--raw"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if got := out.String(); got != "# Title\n\n" {
+		t.Fatalf("unexpected raw output %q", got)
+	}
+}
+
+func TestQueryWebFusesResultsAndCitesSources(t *testing.T) {
+	cmd, out, prompt := newTestQueryCommand("Go 1.25 is out [2].")
+	augmentFn = func(ctx context.Context, config *utils.Config, prompt string, web, cite, memory bool, errOut io.Writer) (*augmented, error) {
+		if !web || cite {
+			t.Errorf("expected web without citations, got web=%v cite=%v", web, cite)
+		}
+		results := []websearch.Result{
+			{Title: "Old news", URL: "https://example.com/old"},
+			{Title: "Go blog", URL: "https://go.dev/blog"},
+		}
+		return &augmented{prompt: websearch.Prompt(prompt, "", results), web: results}, nil
+	}
+	cmd.SetArgs([]string{"what's", "new", "in", "go?", "--web", "--raw"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if !strings.Contains(*prompt, "[2] Go blog (https://go.dev/blog)") || !strings.HasSuffix(*prompt, "Prompt: what's new in go?") {
+		t.Fatalf("expected the web results in the prompt, got:\n%s", *prompt)
+	}
+	want := "Go 1.25 is out [2].\n\nSources:\n[2] Go blog - https://go.dev/blog\n"
+	if got := out.String(); got != want {
+		t.Fatalf("expected output %q, got %q", want, got)
+	}
+}
+
+func TestQueryCitationsRendersMemoryFootnotes(t *testing.T) {
+	cmd, out, prompt := newTestQueryCommand("Use table-driven tests [M2].")
+	augmentFn = func(ctx context.Context, config *utils.Config, prompt string, web, cite, memory bool, errOut io.Writer) (*augmented, error) {
+		memories := []memtypes.MemoryItem{
+			{ID: "mem-1", Text: "The user works on gomor."},
+			{ID: "mem-2", Text: "The user prefers table-driven tests."},
+		}
+		return &augmented{prompt: citation.Prompt(prompt, memories), memories: memories}, nil
+	}
+	cmd.SetArgs([]string{"how", "should", "I", "test", "this?", "--citations", "--raw"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if !strings.Contains(*prompt, "[M2] The user prefers table-driven tests.") {
+		t.Fatalf("expected labeled memories in the prompt, got:\n%s", *prompt)
+	}
+	want := "Use table-driven tests [M2].\n\nMemories:\n[M2] The user prefers table-driven tests. (mem-2)\n" +
+		"Mark a wrong memory with 'gomor memory feedback <id> --wrong'.\n"
+	if got := out.String(); got != want {
+		t.Fatalf("expected output %q, got %q", want, got)
+	}
+}
+
+func TestQuerySendsMemoriesAsSystemContext(t *testing.T) {
+	cmd, _, _ := newTestQueryCommand("hi")
+	var gotMemory bool
+	augmentFn = func(ctx context.Context, config *utils.Config, prompt string, web, cite, memory bool, errOut io.Writer) (*augmented, error) {
+		gotMemory = memory
+		return &augmented{prompt: prompt, memoryText: "Found 1 memories:"}, nil
+	}
+	var gotSystem, gotPrompt string
+	askFn = func(ctx context.Context, config *utils.Config, systemContext, prompt string, enforceBudget bool, out, errOut io.Writer) (string, error) {
+		gotSystem, gotPrompt = systemContext, prompt
+		return "", nil
+	}
+	cmd.SetArgs([]string{"where", "is", "staging?"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if !gotMemory || !strings.HasSuffix(gotSystem, "Found 1 memories:") || gotPrompt != "where is staging?" {
+		t.Fatalf("expected the memories as system context beside the prompt, got %q and %q", gotSystem, gotPrompt)
+	}
+
+	cmd, _, _ = newTestQueryCommand("hi")
+	augmentFn = func(ctx context.Context, config *utils.Config, prompt string, web, cite, memory bool, errOut io.Writer) (*augmented, error) {
+		t.Error("expected no retrieval with --no-memory")
+		return &augmented{prompt: prompt}, nil
+	}
+	cmd.SetArgs([]string{"--no-memory", "hi"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	cmd, _, _ = newTestQueryCommand("hi")
+	cmd.SetArgs([]string{"--no-memory", "--citations", "hi"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--no-memory") {
+		t.Fatalf("expected --no-memory to conflict with --citations, got %v", err)
+	}
+}
+
+func TestQueryRendersChatSystemPrompt(t *testing.T) {
+	cmd, _, _ := newTestQueryCommand("hi")
+	loadConfigFn = func() (*utils.Config, error) {
+		config := utils.DefaultConfig()
+		config.Chat.SystemPrompt = "Be brief.\n\nWhat you know about the user:\n{{.Memories}}"
+		return config, nil
+	}
+	augmentFn = func(ctx context.Context, config *utils.Config, prompt string, web, cite, memory bool, errOut io.Writer) (*augmented, error) {
+		return &augmented{prompt: prompt, memoryText: "Found 1 memories:"}, nil
+	}
+	var gotSystem string
+	askFn = func(ctx context.Context, config *utils.Config, systemContext, prompt string, enforceBudget bool, out, errOut io.Writer) (string, error) {
+		gotSystem = systemContext
+		return "", nil
+	}
+	cmd.SetArgs([]string{"where", "is", "staging?"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if want := "Be brief.\n\nWhat you know about the user:\nFound 1 memories:"; gotSystem != want {
+		t.Fatalf("expected chat.system_prompt filled with the memories, got %q want %q", gotSystem, want)
+	}
+
+	cmd, _, _ = newTestQueryCommand("hi")
+	loadConfigFn = func() (*utils.Config, error) {
+		config := utils.DefaultConfig()
+		config.Chat.SystemPrompt = "Be brief."
+		return config, nil
+	}
+	cmd.SetArgs([]string{"--no-memory", "hi"})
+	gotSystem = ""
+	askFn = func(ctx context.Context, config *utils.Config, systemContext, prompt string, enforceBudget bool, out, errOut io.Writer) (string, error) {
+		gotSystem = systemContext
+		return "", nil
+	}
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if gotSystem != "Be brief." {
+		t.Fatalf("expected chat.system_prompt without memories, got %q", gotSystem)
+	}
+}
+
+func TestQuerySavesAnswerToHistory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	saveAndList := func(autoSave *bool) []memtypes.HistoryItem {
+		t.Helper()
+		cmd, _, _ := newTestQueryCommand("Use rebase --onto.")
+		recordFn = realRecordFn
+		loadConfigFn = func() (*utils.Config, error) {
+			config := utils.DefaultConfig()
+			config.History.AutoSave = autoSave
+			return config, nil
+		}
+		cmd.SetArgs([]string{"--raw", "how", "do", "I", "move", "a", "branch?"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+		memStore, err := store.NewStore()
+		if err != nil {
+			t.Fatalf("open store: %v", err)
+		}
+		defer memStore.Close()
+		history, err := memStore.GetRecentHistory(10)
+		if err != nil {
+			t.Fatalf("history: %v", err)
+		}
+		return history
+	}
+
+	off := false
+	if history := saveAndList(&off); len(history) != 0 {
+		t.Fatalf("expected nothing saved with auto_save off, got %+v", history)
+	}
+	history := saveAndList(nil)
+	if len(history) != 2 || history[0].SessionID == "" || history[0].SessionID != history[1].SessionID {
+		t.Fatalf("expected the prompt and answer saved as one session, got %+v", history)
+	}
+	byRole := map[string]string{}
+	for _, h := range history {
+		byRole[h.Role] = h.Content
+	}
+	if byRole["user"] != "how do I move a branch?" || byRole["assistant"] != "Use rebase --onto." {
+		t.Fatalf("unexpected turns %+v", byRole)
+	}
+}
+
+func TestQuerySavesInterruptedAnswer(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cmd, _, _ := newTestQueryCommand("")
+	recordFn = realRecordFn
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	askFn = func(ctx context.Context, config *utils.Config, systemContext, prompt string, enforceBudget bool, out, errOut io.Writer) (string, error) {
+		_, _ = io.WriteString(out, "Use rebase")
+		cancel()
+		return "Use rebase", ctx.Err()
+	}
+	cmd.SetArgs([]string{"--raw", "how", "do", "I", "move", "a", "branch?"})
+	if err := cmd.ExecuteContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the cancellation returned, got %v", err)
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer memStore.Close()
+	history, err := memStore.GetRecentHistory(10)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	var answer *memtypes.HistoryItem
+	for i := range history {
+		if history[i].Role == "assistant" {
+			answer = &history[i]
+		}
+	}
+	if len(history) != 2 || answer == nil || answer.Content != "Use rebase" || !answer.Interrupted {
+		t.Fatalf("expected the partial answer saved as interrupted, got %+v", history)
+	}
+}
+
+func TestQueryModelAlias(t *testing.T) {
+	cmd, _, _ := newTestQueryCommand("hi")
+	loadConfigFn = func() (*utils.Config, error) {
+		config := utils.DefaultConfig()
+		config.Model.Aliases = map[string]string{"smart": "anthropic/claude-sonnet-4-5"}
+		return config, nil
+	}
+	var gotModel types.Model
+	askFn = func(ctx context.Context, config *utils.Config, systemContext, prompt string, enforceBudget bool, out, errOut io.Writer) (string, error) {
+		gotModel = *config.Model.ChatModel
+		return "", nil
+	}
+	cmd.SetArgs([]string{"-m", "smart", "--seed", "3", "hi"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if gotModel.Provider != "anthropic" || gotModel.ModelID != "claude-sonnet-4-5" || gotModel.Seed == nil {
+		t.Fatalf("expected the aliased model with the seed, got %+v", gotModel)
+	}
+
+	cmd, _, _ = newTestQueryCommand("hi")
+	cmd.SetArgs([]string{"--model", "fast", "hi"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), `unknown model "fast"`) {
+		t.Fatalf("expected an unknown model error, got %v", err)
+	}
+}
+
+func TestQueryRunsHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands in this test need a POSIX shell")
+	}
+	event := filepath.Join(t.TempDir(), "event.json")
+	cmd, out, prompt := newTestQueryCommand("hello")
+	loadConfigFn = func() (*utils.Config, error) {
+		config := utils.DefaultConfig()
+		config.Hooks.PreQuery = `echo '{"prompt": "hi, briefly"}'`
+		config.Hooks.PostResponse = "cat > " + event
+		return config, nil
+	}
+	cmd.SetArgs([]string{"hi", "--raw"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if *prompt != "hi, briefly" {
+		t.Fatalf("pre_query should rewrite the prompt, got %q", *prompt)
+	}
+	if out.String() != "hello\n" {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+	data, err := os.ReadFile(event)
+	if err != nil {
+		t.Fatalf("post_response should have run: %v", err)
+	}
+	if !strings.Contains(string(data), `"prompt":"hi, briefly"`) || !strings.Contains(string(data), `"response":"hello"`) {
+		t.Fatalf("unexpected post_response event %s", data)
+	}
+}
+
+func TestQueryPreQueryHookCancels(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands in this test need a POSIX shell")
+	}
+	cmd, _, prompt := newTestQueryCommand("hello")
+	loadConfigFn = func() (*utils.Config, error) {
+		config := utils.DefaultConfig()
+		config.Hooks.PreQuery = "exit 1"
+		return config, nil
+	}
+	cmd.SetArgs([]string{"hi"})
+
+	if err := cmd.Execute(); !errors.Is(err, hooks.ErrCancelled) {
+		t.Fatalf("expected the hook to cancel the query, got %v", err)
+	}
+	if *prompt != "" {
+		t.Fatalf("the prompt should not be sent, got %q", *prompt)
+	}
+}
+
+func TestQueryOutputTeesAnswerToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "answer.md")
+
+	cmd, out, _ := newTestQueryCommand("# Sort\nUse `sort.Ints`.")
+	cmd.SetArgs([]string{"how", "do", "I", "sort?", "--raw", "--output", path})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if out.String() != "# Sort\nUse `sort.Ints`.\n" {
+		t.Fatalf("the answer should still be printed, got %q", out.String())
+	}
+
+	cmd, _, _ = newTestQueryCommand("Use `slices.Sort`.")
+	cmd.SetArgs([]string{"and", "slices?", "-o", path, "--append"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute --append: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	got := string(data)
+	model := utils.DefaultConfig().Model.ChatModel
+	name := model.Provider + "/" + model.ModelID
+	for _, want := range []string{
+		"---\nmodel: " + name + "\ndate: ",
+		"prompt: \"how do I sort?\"\n---\n\n# Sort\nUse `sort.Ints`.\n",
+		"\n## and slices?\n\n*" + name + " · ",
+		"*\n\nUse `slices.Sort`.\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("output file missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Count(got, "---\n") != 2 {
+		t.Fatalf("appending should not add frontmatter:\n%s", got)
+	}
+}
+
+func TestQueryAppendRequiresOutput(t *testing.T) {
+	cmd, _, _ := newTestQueryCommand("hello")
+	cmd.SetArgs([]string{"hi", "--append"})
+
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--append requires --output") {
+		t.Fatalf("expected --append to require --output, got %v", err)
+	}
+}
+
+func TestQueryPasteAppendsClipboard(t *testing.T) {
+	clip := "panic: runtime error\n```go\nx := nil\n```\n"
+	oldRead := readClipboardFn
+	readClipboardFn = func() (string, error) { return clip, nil }
+	t.Cleanup(func() { readClipboardFn = oldRead })
+
+	cmd, _, prompt := newTestQueryCommand("hello")
+	cmd.SetArgs([]string{"--paste", "explain this"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	want := "explain this\n\n````\npanic: runtime error\n```go\nx := nil\n```\n````"
+	if *prompt != want {
+		t.Fatalf("unexpected prompt %q", *prompt)
+	}
+
+	clip = strings.Repeat("é", maxPasteBytes)
+	cmd, _, prompt = newTestQueryCommand("hello")
+	cmd.SetArgs([]string{"--paste"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if len(*prompt) != maxPasteBytes || !utf8.ValidString(*prompt) {
+		t.Fatalf("expected the clipboard alone, cut to %d bytes, got %d", maxPasteBytes, len(*prompt))
+	}
+
+	clip = " \n"
+	cmd, _, _ = newTestQueryCommand("hello")
+	cmd.SetArgs([]string{"--paste", "explain"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "clipboard is empty") {
+		t.Fatalf("expected an empty clipboard error, got %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package remember
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/spf13/cobra"
+)
+
+var saveMemoryFn = memoryservice.Save
+
+type rememberCommandOptions struct {
+	tags       string
+	kind       string
+	pinned     bool
+	noTags     bool
+	jsonOutput bool
+}
+
+type rememberOutput struct {
+	Message    string   `json:"message"`
+	ID         string   `json:"id"`
+	Text       string   `json:"text"`
+	Tags       []string `json:"tags,omitempty"`
+	AutoTagged bool     `json:"auto_tagged,omitempty"`
+	Pending    bool     `json:"pending,omitempty"`
+}
+
+var RememberCmd = newRememberCommand()
+
+func newRememberCommand() *cobra.Command {
+	opts := &rememberCommandOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "remember <fact>",
+		Short: "Save a memory in one short command",
+		Long: `Save a fact as a memory, the quick way to capture something mid-workflow:
+
+  gomor remember "the staging database is db-stg-2"
+
+The words need no quotes. Without --tags, the tool model proposes a few tags,
+reusing ones your memories already have; the confirmation line shows them.
+Use 'gomor memory' to change them later.`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRememberCommand(cmd, strings.Join(args, " "), opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.tags, "tags", "t", "", "comma-separated tags, instead of proposed ones")
+	cmd.Flags().StringVarP(&opts.kind, "kind", "k", "", "memory kind: fact, preference, document-chunk, or episodic (default fact)")
+	cmd.Flags().BoolVar(&opts.pinned, "pin", false, "pin the memory so it is always included in retrieved context")
+	cmd.Flags().BoolVar(&opts.noTags, "no-tags", false, "save without tags instead of asking the tool model for some")
+	cmd.Flags().BoolVar(&opts.jsonOutput, "json", false, "emit structured JSON output")
+	return cmd
+}
+
+func runRememberCommand(cmd *cobra.Command, text string, opts *rememberCommandOptions) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return fmt.Errorf("nothing to remember")
+	}
+	tags := parseTags(opts.tags)
+	if opts.noTags && len(tags) > 0 {
+		return fmt.Errorf("--tags and --no-tags cannot be used together")
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// Unlike other saves, remember proposes tags whatever auto_tag is set to.
+	autoTag := !opts.noTags
+	result, err := saveMemoryFn(ctx, memoryservice.SaveInput{
+		Text:    text,
+		Tags:    tags,
+		Kind:    memtypes.MemoryKind(opts.kind),
+		Pinned:  opts.pinned,
+		AutoTag: &autoTag,
+	})
+	if err != nil {
+		return err
+	}
+
+	output := rememberOutput{
+		ID:         result.Item.ID,
+		Text:       result.Item.Text,
+		Tags:       result.Item.Tags,
+		AutoTagged: result.AutoTagged,
+		Pending:    result.Pending,
+	}
+	output.Message = confirmation(output)
+
+	if opts.jsonOutput {
+		return writeJSON(cmd.OutOrStdout(), output)
+	}
+	_, err = fmt.Fprintln(cmd.OutOrStdout(), output.Message)
+	return err
+}
+
+// confirmation is the one line printed after saving.
+func confirmation(output rememberOutput) string {
+	message := fmt.Sprintf("Remembered %q", output.Text)
+	if len(output.Tags) > 0 {
+		label := "tags"
+		if output.AutoTagged {
+			label = "proposed tags"
+		}
+		message += fmt.Sprintf(" [%s: %s]", label, strings.Join(output.Tags, ", "))
+	}
+	message += fmt.Sprintf(" (id: %s)", output.ID)
+	if output.Pending {
+		message += "; embedding queued"
+	}
+	return message
+}
+
+func parseTags(raw string) []string {
+	var tags []string
+	for _, tag := range strings.Split(raw, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func writeJSON(out io.Writer, value any) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
@@ -0,0 +1,106 @@
+package remember
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+)
+
+func fakeSave(gotInput *memoryservice.SaveInput, proposed []string) func(context.Context, memoryservice.SaveInput) (*memoryservice.SaveResult, error) {
+	return func(ctx context.Context, input memoryservice.SaveInput) (*memoryservice.SaveResult, error) {
+		*gotInput = input
+		item := memtypes.MemoryItem{ID: "mem-1", Text: input.Text, Tags: input.Tags, Kind: input.Kind}
+		autoTagged := false
+		if input.AutoTag != nil && *input.AutoTag && len(input.Tags) == 0 {
+			item.Tags, autoTagged = proposed, len(proposed) > 0
+		}
+		return &memoryservice.SaveResult{Item: item, AutoTagged: autoTagged}, nil
+	}
+}
+
+func TestRememberProposesTags(t *testing.T) {
+	oldSave := saveMemoryFn
+	defer func() { saveMemoryFn = oldSave }()
+
+	var gotInput memoryservice.SaveInput
+	saveMemoryFn = fakeSave(&gotInput, []string{"database", "staging"})
+
+	cmd := newRememberCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"the", "staging", "database", "is", "db-stg-2"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if gotInput.Text != "the staging database is db-stg-2" || gotInput.AutoTag == nil || !*gotInput.AutoTag || gotInput.Tags != nil {
+		t.Fatalf("unexpected input: %+v", gotInput)
+	}
+	want := "Remembered \"the staging database is db-stg-2\" [proposed tags: database, staging] (id: mem-1)\n"
+	if out.String() != want {
+		t.Fatalf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestRememberWithExplicitTagsJSON(t *testing.T) {
+	oldSave := saveMemoryFn
+	defer func() { saveMemoryFn = oldSave }()
+
+	var gotInput memoryservice.SaveInput
+	saveMemoryFn = fakeSave(&gotInput, []string{"unused"})
+
+	cmd := newRememberCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"prefers tabs", "-t", "editor, style", "-k", "preference", "--pin", "--json"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if gotInput.Kind != memtypes.KindPreference || !gotInput.Pinned || len(gotInput.Tags) != 2 {
+		t.Fatalf("unexpected input: %+v", gotInput)
+	}
+
+	var got rememberOutput
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if got.ID != "mem-1" || got.AutoTagged || got.Tags[0] != "editor" || got.Message != `Remembered "prefers tabs" [tags: editor, style] (id: mem-1)` {
+		t.Fatalf("unexpected output: %+v", got)
+	}
+}
+
+func TestRememberNoTags(t *testing.T) {
+	oldSave := saveMemoryFn
+	defer func() { saveMemoryFn = oldSave }()
+
+	var gotInput memoryservice.SaveInput
+	saveMemoryFn = fakeSave(&gotInput, []string{"unused"})
+
+	cmd := newRememberCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--no-tags", "lunch is at noon"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if gotInput.AutoTag == nil || *gotInput.AutoTag {
+		t.Fatal("expected --no-tags to skip tag proposal")
+	}
+	if out.String() != "Remembered \"lunch is at noon\" (id: mem-1)\n" {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+
+	cmd = newRememberCommand()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--no-tags", "--tags", "a", "text"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected --tags and --no-tags to conflict")
+	}
+}
@@ -0,0 +1,212 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/austiecodes/gomor/internal/chat"
+	"github.com/austiecodes/gomor/internal/hooks"
+	"github.com/austiecodes/gomor/internal/markdown"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/moderation"
+	"github.com/austiecodes/gomor/internal/pricing"
+	"github.com/austiecodes/gomor/internal/provider"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+type retryCommandOptions struct {
+	session       string
+	provider      string
+	model         string
+	temperature   float64
+	maxTokens     int
+	seed          int64
+	stop          []string
+	raw           bool
+	enforceBudget bool
+}
+
+// retryFn regenerates the last answer of a session (the most recent session
+// when sessionID is empty) with model, streaming it into out. Budget warnings
+// go to errOut.
+var retryFn = func(ctx context.Context, config *utils.Config, model types.Model, sessionID string, enforceBudget bool, out, errOut io.Writer) error {
+	spinner := chat.NewSpinner(errOut)
+	out, errOut = spinner.Wrap(out), spinner.Wrap(errOut)
+	spinner.Start()
+	defer spinner.Stop()
+
+	queryClient, err := provider.NewRoleQueryClient(config, utils.RoleChat, model, chat.SwitchNotice(errOut))
+	if err != nil {
+		return fmt.Errorf("failed to create chat client: %w", err)
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		return fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	if sessionID == "" {
+		prompt, err := memStore.LastPrompt("")
+		if err != nil {
+			return err
+		}
+		if prompt == nil || prompt.SessionID == "" {
+			return chat.ErrNothingToRetry
+		}
+		sessionID = prompt.SessionID
+	}
+
+	session := chat.NewSession(memStore, queryClient, model, sessionID)
+	if err := session.SetContextConfig(config.Chat); err != nil {
+		return err
+	}
+	session.SetBudget(pricing.NewBudget(memStore, config.Budget, enforceBudget, errOut))
+	moderator, err := moderation.FromConfig(config)
+	if err != nil {
+		return err
+	}
+	session.SetModerator(moderator)
+	session.SetHooks(hooks.New(config.Hooks), errOut)
+	session.SetRecall(func(ctx context.Context, prompt string) (string, error) {
+		result, err := memoryservice.Retrieve(ctx, memoryservice.RetrieveInput{Query: prompt, SessionID: session.ID, Command: "chat"})
+		if err != nil {
+			return "", err
+		}
+		return result.Text, nil
+	}, errOut)
+	_, err = session.Retry(ctx, out)
+	return err
+}
+
+var loadConfigFn = utils.LoadConfig
+
+var RetryCmd = newRetryCommand()
+
+func newRetryCommand() *cobra.Command {
+	opts := &retryCommandOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "retry",
+		Short: "Regenerate the answer to the last prompt",
+		Long: `Re-send the last prompt from history and print a new answer. The new
+answer is saved next to the previous one; later turns of the session see only
+the newest.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRetryCommand(cmd, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.session, "session", "", "session to retry (default: the most recent one)")
+	cmd.Flags().StringVar(&opts.provider, "provider", "", "provider override for this answer")
+	cmd.Flags().StringVar(&opts.model, "model", "", "model ID or alias (from model.aliases) override for this answer")
+	cmd.Flags().Float64Var(&opts.temperature, "temperature", 0, "sampling temperature override for this answer")
+	cmd.Flags().IntVar(&opts.maxTokens, "max-tokens", 0, "maximum answer length in tokens for this answer")
+	cmd.Flags().Int64Var(&opts.seed, "seed", 0, "sampling seed for a repeatable answer (not supported by anthropic)")
+	cmd.Flags().StringArrayVar(&opts.stop, "stop", nil, "end the answer at this sequence (repeatable)")
+	cmd.Flags().BoolVar(&opts.raw, "raw", false, "print the answer as raw markdown (overrides chat.raw_markdown)")
+	cmd.Flags().BoolVar(&opts.enforceBudget, "enforce-budget", false, "refuse to send the prompt when the budget is used up, instead of warning")
+
+	return cmd
+}
+
+func runRetryCommand(cmd *cobra.Command, opts *retryCommandOptions) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	config, err := loadConfigFn()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	overrides := types.Model{
+		Provider:  opts.provider,
+		ModelID:   opts.model,
+		MaxTokens: opts.maxTokens,
+		Stop:      opts.stop,
+	}
+	if cmd.Flags().Changed("temperature") {
+		overrides.Temperature = &opts.temperature
+	}
+	if cmd.Flags().Changed("seed") {
+		overrides.Seed = &opts.seed
+	}
+	// An alias names the provider as well as the model.
+	if _, ok := config.Model.Aliases[opts.model]; ok && opts.provider == "" {
+		aliased, err := config.Model.ResolveModel(opts.model)
+		if err != nil {
+			return err
+		}
+		overrides.Provider, overrides.ModelID = aliased.Provider, aliased.ModelID
+	}
+	if opts.maxTokens < 0 {
+		return fmt.Errorf("--max-tokens must be positive")
+	}
+	model, err := resolveModel(config, overrides)
+	if err != nil {
+		return err
+	}
+
+	raw := config.Chat.RawMarkdown
+	if cmd.Flags().Changed("raw") {
+		raw = opts.raw
+	}
+
+	out := cmd.OutOrStdout()
+	answerOut := out
+	var md *markdown.Renderer
+	if !raw {
+		md = markdown.NewRenderer(out)
+		answerOut = md
+	}
+
+	err = retryFn(ctx, config, model, opts.session, opts.enforceBudget, answerOut, cmd.ErrOrStderr())
+	if md != nil {
+		_ = md.Flush()
+	}
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out)
+	return err
+}
+
+// resolveModel applies the set fields of overrides to the configured chat
+// model. Zero fields keep the configured values.
+func resolveModel(config *utils.Config, overrides types.Model) (types.Model, error) {
+	var model types.Model
+	if config.Model.ChatModel != nil {
+		model = *config.Model.ChatModel
+	}
+	if overrides.Provider != "" {
+		model.Provider = overrides.Provider
+	}
+	if overrides.ModelID != "" {
+		model.ModelID = overrides.ModelID
+	}
+	if overrides.Temperature != nil {
+		model.Temperature = overrides.Temperature
+	}
+	if overrides.MaxTokens > 0 {
+		model.MaxTokens = overrides.MaxTokens
+	}
+	if len(overrides.Stop) > 0 {
+		model.Stop = overrides.Stop
+	}
+	if overrides.Seed != nil {
+		model.Seed = overrides.Seed
+	}
+
+	if model.Provider == "" || model.ModelID == "" {
+		return types.Model{}, utils.ConfigErrorf("chat model not configured. Run 'gomor set' to configure")
+	}
+	return model, nil
+}
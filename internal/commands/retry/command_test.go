@@ -0,0 +1,96 @@
+package retry
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+func TestRetryCommandAppliesOverrides(t *testing.T) {
+	oldRetry, oldLoad := retryFn, loadConfigFn
+	defer func() { retryFn, loadConfigFn = oldRetry, oldLoad }()
+
+	loadConfigFn = func() (*utils.Config, error) { return utils.DefaultConfig(), nil }
+
+	var gotModel types.Model
+	var gotSession string
+	retryFn = func(ctx context.Context, config *utils.Config, model types.Model, sessionID string, enforceBudget bool, out, errOut io.Writer) error {
+		gotModel, gotSession = model, sessionID
+		_, err := io.WriteString(out, "**new** answer")
+		return err
+	}
+
+	cmd := newRetryCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--session", "s-1", "--model", "gpt-5", "--temperature", "0", "--max-tokens", "2000", "--seed", "7", "--stop", "END", "--stop", "\n\n"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if gotSession != "s-1" {
+		t.Fatalf("unexpected session %q", gotSession)
+	}
+	if gotModel.Provider != "openai" || gotModel.ModelID != "gpt-5" {
+		t.Fatalf("unexpected model %+v", gotModel)
+	}
+	if gotModel.Temperature == nil || *gotModel.Temperature != 0 {
+		t.Fatalf("expected explicit zero temperature, got %v", gotModel.Temperature)
+	}
+	if gotModel.MaxTokens != 2000 {
+		t.Fatalf("expected max tokens 2000, got %d", gotModel.MaxTokens)
+	}
+	if gotModel.Seed == nil || *gotModel.Seed != 7 || len(gotModel.Stop) != 2 || gotModel.Stop[0] != "END" {
+		t.Fatalf("expected seed 7 and two stop sequences, got %v / %q", gotModel.Seed, gotModel.Stop)
+	}
+	if out.String() != "new answer\n" {
+		t.Fatalf("expected rendered answer, got %q", out.String())
+	}
+}
+
+func TestRetryCommandModelAlias(t *testing.T) {
+	oldRetry, oldLoad := retryFn, loadConfigFn
+	defer func() { retryFn, loadConfigFn = oldRetry, oldLoad }()
+
+	loadConfigFn = func() (*utils.Config, error) {
+		config := utils.DefaultConfig()
+		config.Model.Aliases = map[string]string{"fast": "google/gemini-2.5-flash"}
+		return config, nil
+	}
+	var gotModel types.Model
+	retryFn = func(ctx context.Context, config *utils.Config, model types.Model, sessionID string, enforceBudget bool, out, errOut io.Writer) error {
+		gotModel = model
+		return nil
+	}
+
+	cmd := newRetryCommand()
+	cmd.SetOut(io.Discard)
+	cmd.SetArgs([]string{"--model", "fast"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if gotModel.Provider != "google" || gotModel.ModelID != "gemini-2.5-flash" {
+		t.Fatalf("expected the alias to set the provider and model, got %+v", gotModel)
+	}
+}
+
+func TestResolveModelKeepsConfiguredTemperatureUnlessOverridden(t *testing.T) {
+	config := utils.DefaultConfig()
+
+	model, err := resolveModel(config, types.Model{})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if model.Temperature != nil || model.ModelID != config.Model.ChatModel.ModelID {
+		t.Fatalf("unexpected model %+v", model)
+	}
+
+	config.Model.ChatModel = nil
+	if _, err := resolveModel(config, types.Model{}); err == nil {
+		t.Fatal("expected error without a chat model")
+	}
+}
@@ -1,16 +1,60 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/exitcode"
+	"github.com/austiecodes/gomor/internal/plugin"
+	"github.com/austiecodes/gomor/internal/trace"
+	"github.com/austiecodes/gomor/internal/utils"
 	"github.com/spf13/cobra"
 )
 
+var rootQueryOpts = &queryOptions{}
+
+var offline bool
+
+var verbose int
+
+var quiet bool
+
 var rootCmd = &cobra.Command{
-	Use:   "gomor",
+	Use:   "gomor [prompt]",
 	Short: "gomor is a MCP server for memory management",
-	Long:  `gomor is a MCP (Model Context Protocol) server that provides memory management capabilities.`,
+	Long: `gomor is a MCP (Model Context Protocol) server that provides memory management capabilities.
+
+Given a prompt, gomor answers it with the configured chat model:
+  gomor "write a bubble sort in go" --code-only > sort.go`,
+	Args: cobra.ArbitraryArgs,
+	// Execute prints errors itself, once, and picks the exit code.
+	SilenceErrors: true,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if offline {
+			utils.SetOffline(true)
+		}
+		trace.SetLevel(trace.Level(verbose))
+		if quiet {
+			cmd.Root().SetErr(io.Discard)
+		}
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runQuery(cmd, args, rootQueryOpts)
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "refuse network calls: search with FTS only and queue embeddings (same as \"offline\": true in the config)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "print only results and errors: drop warnings, notices, and hints from stderr")
+	rootCmd.PersistentFlags().CountVarP(&verbose, "verbose", "v", "print the resolved model, injected context, retrieval timing, token usage, and retries to stderr (-vv adds every HTTP request and the full prompt)")
+	addQueryFlags(rootCmd, rootQueryOpts)
 }
 
 // AddCommand adds a subcommand to the root command
@@ -18,10 +62,56 @@ func AddCommand(cmd *cobra.Command) {
 	rootCmd.AddCommand(cmd)
 }
 
-// Execute adds all child commands to the root command and sets flags appropriately.
+// Execute adds all child commands to the root command and sets flags
+// appropriately. It exits with a code from the exitcode package on errors.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	if code, ok := runPlugin(os.Args[1:]); ok {
+		os.Exit(code)
+	}
+
+	// Ctrl-C cancels the command's context, so a streaming answer stops
+	// cleanly and chat keeps what arrived. A second Ctrl-C kills gomor.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := rootCmd.ExecuteContext(ctx)
+	if err != nil && ctx.Err() != nil {
+		fmt.Fprintln(os.Stderr, "\nInterrupted")
+		os.Exit(exitcode.Interrupted)
+	}
+	if err != nil {
+		// Partial results come with their own warnings.
+		if !errors.Is(err, exitcode.ErrPartial) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			if hint := client.Guidance(err); hint != "" && !quiet {
+				fmt.Fprintf(os.Stderr, "Hint: %s\n", hint)
+			}
+		}
+		os.Exit(exitcode.For(err))
+	}
+}
+
+// runPlugin runs the gomor-<name> plugin on PATH for "gomor <name> [args...]"
+// and returns its exit code, unless <name> is a built-in command.
+func runPlugin(args []string) (int, bool) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return 0, false
+	}
+	rootCmd.InitDefaultHelpCmd()
+	rootCmd.InitDefaultCompletionCmd()
+	if cmd, _, err := rootCmd.Find(args); err == nil && cmd != rootCmd {
+		return 0, false
+	}
+	path, ok := plugin.Find(plugin.KindCommand, args[0])
+	if !ok {
+		return 0, false
+	}
+	code, err := plugin.Run(context.Background(), path, args[1:])
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
 	}
+	return code, true
 }
@@ -0,0 +1,125 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/spf13/cobra"
+)
+
+// searchAllFn runs a full-text search over memories and history.
+var searchAllFn = func(query string, topK int) ([]memtypes.SearchHit, error) {
+	memStore, err := store.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	return memStore.SearchAll(query, topK)
+}
+
+type searchCommandOptions struct {
+	topK       int
+	jsonOutput bool
+}
+
+type searchHitOutput struct {
+	Kind      string   `json:"kind"`
+	ID        string   `json:"id"`
+	Text      string   `json:"text"`
+	Snippet   string   `json:"snippet"`
+	CreatedAt string   `json:"created_at"`
+	Tags      []string `json:"tags,omitempty"`
+	Role      string   `json:"role,omitempty"`
+	SessionID string   `json:"session_id,omitempty"`
+}
+
+type searchOutput struct {
+	Query string            `json:"query"`
+	Hits  []searchHitOutput `json:"hits"`
+}
+
+var SearchCmd = newSearchCommand()
+
+func newSearchCommand() *cobra.Command {
+	opts := &searchCommandOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Search memories and chat history together",
+		Long: `Run a full-text search over stored memories and chat history at once and
+list the hits best first, marking each as a memory or a history turn.
+
+Every word of the query is matched literally and a hit needs any one of them.
+Unlike 'gomor memory query' no model is called, so search works offline and
+finds past conversations as well as memories. Suppressed memories and
+memories pending review are left out.`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSearchCommand(cmd, strings.Join(args, " "), opts)
+		},
+	}
+
+	cmd.Flags().IntVarP(&opts.topK, "top-k", "k", 20, "maximum number of hits")
+	cmd.Flags().BoolVar(&opts.jsonOutput, "json", false, "emit structured JSON output")
+	return cmd
+}
+
+func runSearchCommand(cmd *cobra.Command, query string, opts *searchCommandOptions) error {
+	if strings.TrimSpace(query) == "" {
+		return fmt.Errorf("query is required")
+	}
+	if opts.topK <= 0 {
+		return fmt.Errorf("--top-k must be positive, got %d", opts.topK)
+	}
+
+	hits, err := searchAllFn(query, opts.topK)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if opts.jsonOutput {
+		output := searchOutput{Query: query, Hits: make([]searchHitOutput, 0, len(hits))}
+		for _, hit := range hits {
+			output.Hits = append(output.Hits, searchHitOutput{
+				Kind:      string(hit.Kind),
+				ID:        hit.ID,
+				Text:      hit.Text,
+				Snippet:   hit.Snippet,
+				CreatedAt: hit.CreatedAt.Format(time.RFC3339),
+				Tags:      hit.Tags,
+				Role:      hit.Role,
+				SessionID: hit.SessionID,
+			})
+		}
+		return writeJSON(out, output)
+	}
+
+	if len(hits) == 0 {
+		_, err := fmt.Fprintln(out, "No matches.")
+		return err
+	}
+	for i, hit := range hits {
+		label := "memory"
+		if hit.Kind == memtypes.SearchHitHistory {
+			label = "history, " + hit.Role
+		}
+		if _, err := fmt.Fprintf(out, "%d. [%s] %s\n   ID: %s  %s\n", i+1, label, hit.Snippet, hit.ID, hit.CreatedAt.Format("2006-01-02 15:04")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeJSON(out io.Writer, value any) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
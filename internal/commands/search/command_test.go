@@ -0,0 +1,89 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+func fakeHits() []memtypes.SearchHit {
+	created := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	return []memtypes.SearchHit{
+		{Kind: memtypes.SearchHitHistory, ID: "h1", Text: "when is the atlas launch?", Snippet: "when is the >>>atlas<<< launch?", Rank: -2, CreatedAt: created, Role: "user", SessionID: "s1"},
+		{Kind: memtypes.SearchHitMemory, ID: "m1", Text: "Atlas ships in March", Snippet: ">>>Atlas<<< ships in March", Rank: -1, CreatedAt: created, Tags: []string{"atlas"}},
+	}
+}
+
+func TestSearchCommandListsHits(t *testing.T) {
+	oldSearch := searchAllFn
+	defer func() { searchAllFn = oldSearch }()
+
+	var gotQuery string
+	var gotTopK int
+	searchAllFn = func(query string, topK int) ([]memtypes.SearchHit, error) {
+		gotQuery, gotTopK = query, topK
+		return fakeHits(), nil
+	}
+
+	cmd := newSearchCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"atlas", "launch", "-k", "5"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if gotQuery != "atlas launch" || gotTopK != 5 {
+		t.Fatalf("unexpected search: %q top %d", gotQuery, gotTopK)
+	}
+	text := out.String()
+	for _, want := range []string{"1. [history, user] when is the >>>atlas<<< launch?", "2. [memory] >>>Atlas<<< ships in March", "ID: m1"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("output missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestSearchCommandJSONOutput(t *testing.T) {
+	oldSearch := searchAllFn
+	defer func() { searchAllFn = oldSearch }()
+
+	searchAllFn = func(query string, topK int) ([]memtypes.SearchHit, error) {
+		return fakeHits(), nil
+	}
+
+	cmd := newSearchCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"atlas", "--json"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	var got searchOutput
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out.String())
+	}
+	if got.Query != "atlas" || len(got.Hits) != 2 {
+		t.Fatalf("unexpected output: %+v", got)
+	}
+	if got.Hits[0].Kind != "history" || got.Hits[0].SessionID != "s1" || got.Hits[1].Kind != "memory" || got.Hits[1].Tags[0] != "atlas" {
+		t.Fatalf("unexpected hits: %+v", got.Hits)
+	}
+}
+
+func TestSearchCommandRejectsNonPositiveTopK(t *testing.T) {
+	cmd := newSearchCommand()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"atlas", "--top-k", "0"})
+
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--top-k") {
+		t.Fatalf("expected --top-k error, got %v", err)
+	}
+}
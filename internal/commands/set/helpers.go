@@ -8,6 +8,11 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/austiecodes/gomor/internal/consts"
+	"github.com/austiecodes/gomor/internal/memory/retrieval"
+	googleprov "github.com/austiecodes/gomor/internal/provider/google"
+	ollamaprov "github.com/austiecodes/gomor/internal/provider/ollama"
+	"github.com/austiecodes/gomor/internal/termcap"
+	"github.com/austiecodes/gomor/internal/types"
 	"github.com/austiecodes/gomor/internal/utils"
 )
 
@@ -23,8 +28,7 @@ func createMainMenu() list.Model {
 		MenuItem{title: MenuItemExit, desc: "Exit settings"},
 	}
 
-	delegate := list.NewDefaultDelegate()
-	l := list.New(items, delegate, 60, 30)
+	l := termcap.NewList(items, 60, 30)
 	l.Title = "gomor Settings"
 	l.SetShowStatusBar(false)
 	l.SetFilteringEnabled(false)
@@ -33,14 +37,40 @@ func createMainMenu() list.Model {
 }
 
 func createProviderList() list.Model {
-	items := []list.Item{
+	return newProviderList([]list.Item{
 		MenuItem{title: consts.ProviderOpenAI, desc: "OpenAI API (GPT models)"},
 		MenuItem{title: consts.ProviderGoogle, desc: "Google Gemini API (GEMINI models)"},
 		MenuItem{title: consts.ProviderAnthropic, desc: "Anthropic API (Claude models)"},
-	}
+		MenuItem{title: consts.ProviderOllama, desc: "Local Ollama server (no API key)"},
+	})
+}
 
-	delegate := list.NewDefaultDelegate()
-	l := list.New(items, delegate, 60, 15)
+// createModelProviderList adds the built-in mock provider, which needs no
+// configuration.
+func createModelProviderList() list.Model {
+	return newProviderList([]list.Item{
+		MenuItem{title: consts.ProviderOpenAI, desc: "OpenAI API (GPT models)"},
+		MenuItem{title: consts.ProviderGoogle, desc: "Google Gemini API (GEMINI models)"},
+		MenuItem{title: consts.ProviderAnthropic, desc: "Anthropic API (Claude models)"},
+		MenuItem{title: consts.ProviderOllama, desc: "Local Ollama server (no API key)"},
+		MenuItem{title: consts.ProviderMock, desc: "Canned replies for demos (no API key)"},
+	})
+}
+
+// createEmbeddingProviderList adds the built-in local and mock providers and
+// leaves out Anthropic, which has no embedding API.
+func createEmbeddingProviderList() list.Model {
+	return newProviderList([]list.Item{
+		MenuItem{title: consts.ProviderOpenAI, desc: "OpenAI API (GPT models)"},
+		MenuItem{title: consts.ProviderGoogle, desc: "Google Gemini API (GEMINI models)"},
+		MenuItem{title: consts.ProviderOllama, desc: "Local Ollama server (no API key)"},
+		MenuItem{title: consts.ProviderLocal, desc: "Built-in embedder (offline, no API key)"},
+		MenuItem{title: consts.ProviderMock, desc: "Hash embeddings for demos (no API key)"},
+	})
+}
+
+func newProviderList(items []list.Item) list.Model {
+	l := termcap.NewList(items, 60, 15)
 	l.Title = "Select Provider"
 	l.SetShowStatusBar(false)
 	l.SetFilteringEnabled(false)
@@ -49,6 +79,9 @@ func createProviderList() list.Model {
 }
 
 func createProviderConfigInputs(config *utils.Config, provider string) []textinput.Model {
+	if provider == consts.ProviderOllama {
+		return createOllamaConfigInputs(config)
+	}
 	inputs := make([]textinput.Model, 2)
 
 	// API Key input
@@ -87,17 +120,58 @@ func createProviderConfigInputs(config *utils.Config, provider string) []textinp
 		inputs[1].SetValue(baseURL)
 	}
 
+	// Google can also be reached through Vertex AI with a project and region
+	if provider == consts.ProviderGoogle {
+		project := textinput.New()
+		project.Placeholder = "(optional, uses Application Default Credentials)"
+		project.CharLimit = 128
+		project.Width = 50
+		project.SetValue(config.Providers.Google.Project)
+
+		location := textinput.New()
+		location.Placeholder = googleprov.DefaultVertexLocation
+		location.CharLimit = 64
+		location.Width = 50
+		location.SetValue(config.Providers.Google.Location)
+
+		inputs = append(inputs, project, location)
+	}
+
 	return inputs
 }
 
+// createOllamaConfigInputs asks for the server's base URL and the default
+// model instead of an API key, which Ollama does not need.
+func createOllamaConfigInputs(config *utils.Config) []textinput.Model {
+	baseURL := textinput.New()
+	baseURL.Placeholder = ollamaprov.DefaultBaseURL
+	baseURL.CharLimit = 256
+	baseURL.Width = 50
+	baseURL.SetValue(config.Providers.Ollama.BaseURL)
+
+	model := textinput.New()
+	model.Placeholder = "(optional, e.g. llama3.2)"
+	model.CharLimit = 128
+	model.Width = 50
+	model.SetValue(config.Providers.Ollama.Model)
+
+	return []textinput.Model{baseURL, model}
+}
+
 func createModelList(models []string, mt ModelType) list.Model {
-	items := make([]list.Item, len(models))
-	for i, modelID := range models {
-		items[i] = MenuItem{title: modelID, desc: ""}
+	// Multilingual embedding models are listed first: they match queries
+	// against memories written in another language.
+	var items, others []list.Item
+	for _, modelID := range models {
+		if mt == ModelTypeEmbedding && retrieval.IsMultilingualEmbeddingModel(modelID) {
+			items = append(items, MenuItem{title: modelID, desc: "multilingual"})
+		} else {
+			others = append(others, MenuItem{title: modelID, desc: ""})
+		}
 	}
+	items = append(items, others...)
 
-	delegate := list.NewDefaultDelegate()
-	l := list.New(items, delegate, 60, 30)
+	l := termcap.NewList(items, 60, 30)
 
 	switch mt {
 	case ModelTypeChat:
@@ -119,7 +193,7 @@ func createModelList(models []string, mt ModelType) list.Model {
 }
 
 func createMemoryConfigInputs(config *utils.Config) []textinput.Model {
-	inputs := make([]textinput.Model, 3)
+	inputs := make([]textinput.Model, 5)
 
 	// Min Similarity input
 	inputs[0] = textinput.New()
@@ -142,6 +216,20 @@ func createMemoryConfigInputs(config *utils.Config) []textinput.Model {
 	inputs[2].Width = 20
 	inputs[2].SetValue(formatInt(config.Memory.HistoryTopK))
 
+	// Max Injected Tokens input
+	inputs[3] = textinput.New()
+	inputs[3].Placeholder = "1000"
+	inputs[3].CharLimit = 7
+	inputs[3].Width = 20
+	inputs[3].SetValue(formatInt(config.Memory.MaxInjectedTokens))
+
+	// Memory Language input
+	inputs[4] = textinput.New()
+	inputs[4].Placeholder = utils.MemoryLanguageAuto
+	inputs[4].CharLimit = 30
+	inputs[4].Width = 20
+	inputs[4].SetValue(config.Memory.Language)
+
 	return inputs
 }
 
@@ -159,3 +247,31 @@ func saveConfig(config *utils.Config) tea.Cmd {
 		return ConfigSavedMsg{Err: err}
 	}
 }
+
+// currentModel returns the configured model of the type being set, if any.
+func (m *Model) currentModel() *types.Model {
+	switch m.ModelType {
+	case ModelTypeChat:
+		return m.Config.Model.ChatModel
+	case ModelTypeTitle:
+		return m.Config.Model.TitleModel
+	case ModelTypeThink:
+		return m.Config.Model.ThinkModel
+	case ModelTypeTool:
+		return m.Config.Model.ToolModel
+	default:
+		return m.Config.Model.EmbeddingModel
+	}
+}
+
+// keepModelSettings carries the sampling settings set in the config (temperature,
+// max_tokens, stop, seed) over to newModel when the same model is selected again.
+func keepModelSettings(newModel, old *types.Model) {
+	if old == nil || old.Provider != newModel.Provider || old.ModelID != newModel.ModelID {
+		return
+	}
+	newModel.Temperature = old.Temperature
+	newModel.MaxTokens = old.MaxTokens
+	newModel.Stop = old.Stop
+	newModel.Seed = old.Seed
+}
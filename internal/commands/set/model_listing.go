@@ -5,12 +5,24 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/austiecodes/gomor/internal/consts"
 	"github.com/austiecodes/gomor/internal/provider"
+	"github.com/austiecodes/gomor/internal/provider/local"
+	"github.com/austiecodes/gomor/internal/provider/mock"
 	"github.com/austiecodes/gomor/internal/utils"
 )
 
-func loadModelsForProvider(providerID string, cfg *utils.Config) tea.Cmd {
+func loadModelsForProvider(providerID string, modelType ModelType, cfg *utils.Config) tea.Cmd {
 	return func() tea.Msg {
+		switch {
+		case providerID == consts.ProviderLocal:
+			return ModelsLoadedMsg{Models: local.Models()}
+		case providerID == consts.ProviderMock && modelType == ModelTypeEmbedding:
+			return ModelsLoadedMsg{Models: []string{mock.ModelEmbedding}}
+		case providerID == consts.ProviderMock:
+			return ModelsLoadedMsg{Models: []string{mock.ModelChat}}
+		}
+
 		c, err := provider.NewQueryClient(cfg, providerID)
 		if err != nil {
 			return ModelsLoadedMsg{Err: err}
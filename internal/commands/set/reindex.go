@@ -3,6 +3,7 @@ package set
 import (
 	"context"
 
+	"github.com/austiecodes/gomor/internal/memory/embedtext"
 	"github.com/austiecodes/gomor/internal/memory/retrieval"
 	"github.com/austiecodes/gomor/internal/provider"
 	"github.com/austiecodes/gomor/internal/types"
@@ -32,9 +33,14 @@ func reindexMemories(config *utils.Config, newModel types.Model) tea.Cmd {
 			return ReindexResultMsg{Err: err}
 		}
 
+		tmpl, err := embedtext.Parse(config.Memory.EmbeddingTemplate)
+		if err != nil {
+			return ReindexResultMsg{Err: err}
+		}
+
 		// 3. Perform reindexing
 		// We use a background context here, or could pass a context if available
-		err = retrieval.ReindexMemories(context.Background(), s, client, newModel)
+		err = retrieval.ReindexMemories(context.Background(), s, client, newModel, tmpl)
 		return ReindexResultMsg{Err: err}
 	}
 }
@@ -5,8 +5,12 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/austiecodes/gomor/internal/client"
 	"github.com/austiecodes/gomor/internal/consts"
+	googleprov "github.com/austiecodes/gomor/internal/provider/google"
+	ollamaprov "github.com/austiecodes/gomor/internal/provider/ollama"
 	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -22,23 +26,23 @@ func (m *Model) updateMainMenu(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.Screen = ScreenProviderSelect
 			case MenuItemChatModel:
 				m.ModelType = ModelTypeChat
-				m.List = createProviderList()
+				m.List = createModelProviderList()
 				m.Screen = ScreenModelProviderSelect
 			case MenuItemTitleModel:
 				m.ModelType = ModelTypeTitle
-				m.List = createProviderList()
+				m.List = createModelProviderList()
 				m.Screen = ScreenModelProviderSelect
 			case MenuItemThinkModel:
 				m.ModelType = ModelTypeThink
-				m.List = createProviderList()
+				m.List = createModelProviderList()
 				m.Screen = ScreenModelProviderSelect
 			case MenuItemToolModel:
 				m.ModelType = ModelTypeTool
-				m.List = createProviderList()
+				m.List = createModelProviderList()
 				m.Screen = ScreenModelProviderSelect
 			case MenuItemEmbeddingModel:
 				m.ModelType = ModelTypeEmbedding
-				m.List = createProviderList()
+				m.List = createEmbeddingProviderList()
 				m.Screen = ScreenModelProviderSelect
 			case MenuItemMemory:
 				m.TextInputs = createMemoryConfigInputs(m.Config)
@@ -92,15 +96,31 @@ func (m *Model) updateProviderConfig(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case "enter":
 			// Save config
+			provider := m.List.SelectedItem().(MenuItem).Title()
+			// Ollama asks for a base URL and a default model, both optional.
+			if provider == consts.ProviderOllama {
+				m.Config.Providers.Ollama.BaseURL = strings.TrimSpace(m.TextInputs[0].Value())
+				m.Config.Providers.Ollama.Model = strings.TrimSpace(m.TextInputs[1].Value())
+				return *m, saveConfig(m.Config)
+			}
+
 			apiKey := m.TextInputs[0].Value()
 			baseURL := m.TextInputs[1].Value()
 
-			if apiKey == "" {
+			var project, location string
+			if provider == consts.ProviderGoogle {
+				project = strings.TrimSpace(m.TextInputs[2].Value())
+				location = strings.TrimSpace(m.TextInputs[3].Value())
+			}
+
+			if apiKey == "" && project == "" {
 				m.Err = fmt.Errorf("API key is required")
+				if provider == consts.ProviderGoogle {
+					m.Err = fmt.Errorf("API key or Vertex AI project is required")
+				}
 				return *m, nil
 			}
 
-			provider := m.List.SelectedItem().(MenuItem).Title()
 			switch provider {
 			case consts.ProviderOpenAI:
 				m.Config.Providers.OpenAI.APIKey = apiKey
@@ -108,6 +128,8 @@ func (m *Model) updateProviderConfig(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case consts.ProviderGoogle:
 				m.Config.Providers.Google.APIKey = apiKey
 				m.Config.Providers.Google.BaseURL = baseURL
+				m.Config.Providers.Google.Project = project
+				m.Config.Providers.Google.Location = location
 			case consts.ProviderAnthropic:
 				m.Config.Providers.Anthropic.APIKey = apiKey
 				m.Config.Providers.Anthropic.BaseURL = baseURL
@@ -131,7 +153,7 @@ func (m *Model) updateModelProviderSelect(msg tea.Msg) (tea.Model, tea.Cmd) {
 			selected := m.List.SelectedItem().(MenuItem)
 			providerID := selected.Title()
 			m.SelectedProvider = providerID
-			return *m, loadModelsForProvider(providerID, m.Config)
+			return *m, loadModelsForProvider(providerID, m.ModelType, m.Config)
 		}
 	}
 
@@ -169,6 +191,8 @@ func (m *Model) updateModelSelect(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return *m, nil
 			}
 
+			keepModelSettings(newModel, m.currentModel())
+
 			switch m.ModelType {
 			case ModelTypeChat:
 				m.Config.Model.ChatModel = newModel
@@ -223,9 +247,22 @@ func (m *Model) updateMemoryConfig(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return *m, nil
 			}
 
+			maxTokens, err := strconv.Atoi(m.TextInputs[3].Value())
+			if err != nil || maxTokens < 1 {
+				m.Err = fmt.Errorf("max_injected_tokens must be a positive integer")
+				return *m, nil
+			}
+
+			language := strings.TrimSpace(m.TextInputs[4].Value())
+			if language == "" {
+				language = utils.MemoryLanguageAuto
+			}
+
 			m.Config.Memory.MinSimilarity = minSim
 			m.Config.Memory.MemoryTopK = memTopK
 			m.Config.Memory.HistoryTopK = histTopK
+			m.Config.Memory.MaxInjectedTokens = maxTokens
+			m.Config.Memory.Language = language
 
 			return *m, saveConfig(m.Config)
 		}
@@ -298,11 +335,22 @@ func (m *Model) renderView() string {
 		s.WriteString("\n\n")
 		for i, input := range m.TextInputs {
 			label := ""
-			switch i {
-			case 0:
+			switch {
+			case provider == consts.ProviderOllama && i == 0:
+				label = "Base URL (optional, default: " + ollamaprov.DefaultBaseURL + ")"
+			case provider == consts.ProviderOllama && i == 1:
+				label = "Default Model (optional, answers when a request names none)"
+			case i == 0:
 				label = "API Key (required)"
-			case 1:
+				if provider == consts.ProviderGoogle {
+					label = "API Key (required unless a Vertex AI project is set)"
+				}
+			case i == 1:
 				label = "Base URL (optional, default: Provider Default)"
+			case i == 2:
+				label = "Vertex AI Project (optional)"
+			case i == 3:
+				label = "Vertex AI Location (optional, default: " + googleprov.DefaultVertexLocation + ")"
 			}
 			s.WriteString(InputLabelStyle.Render(label))
 			s.WriteString("\n")
@@ -354,6 +402,8 @@ func (m *Model) renderView() string {
 			"Min Similarity (0.0-1.0, default: 0.80)",
 			"Memory Top K (default: 10)",
 			"History Top K (default: 10)",
+			"Max Injected Tokens (default: 1000)",
+			"Memory Language (auto, off, or e.g. Chinese; default: auto)",
 		}
 		for i, input := range m.TextInputs {
 			s.WriteString(InputLabelStyle.Render(labels[i]))
@@ -382,6 +432,10 @@ func (m *Model) renderView() string {
 	if m.Err != nil {
 		s.WriteString("\n\n")
 		s.WriteString(ErrorStyle.Render(fmt.Sprintf("Error: %v", m.Err)))
+		if hint := client.Guidance(m.Err); hint != "" {
+			s.WriteString("\n")
+			s.WriteString(HelpStyle.Render("Hint: " + hint))
+		}
 	}
 
 	return s.String()
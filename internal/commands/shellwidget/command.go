@@ -0,0 +1,171 @@
+package shellwidget
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/chat"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/pricing"
+	"github.com/austiecodes/gomor/internal/provider"
+	"github.com/austiecodes/gomor/internal/shellwidget"
+	"github.com/austiecodes/gomor/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// suggestFn turns request into a command for shell with the cheapest
+// configured model, showing it the user's past corrections.
+var suggestFn = func(ctx context.Context, shell, request string, errOut io.Writer) (string, error) {
+	config, err := utils.LoadConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+	model, role, ok := shellwidget.CheapestModel(config)
+	if !ok {
+		return "", utils.ConfigErrorf("no chat model configured. Run 'gomor set' to configure")
+	}
+
+	queryClient, err := provider.NewRoleQueryClient(config, role, model, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create chat client: %w", err)
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		return "", fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	// Corrections only sharpen the answer, so a failed lookup is not fatal.
+	var corrections []string
+	if memories, err := memStore.GetAllMemories(); err == nil {
+		corrections = shellwidget.Corrections(memories, request)
+	}
+
+	budget := pricing.NewBudget(memStore, config.Budget, false, errOut)
+	answer, err := chat.Ask(ctx, queryClient, model, shellwidget.Prompt(shell, request, corrections), budget, io.Discard)
+	if err != nil {
+		return "", err
+	}
+	return shellwidget.Clean(answer), nil
+}
+
+// learnFn records that the user ran a different command than was suggested.
+var learnFn = func(ctx context.Context, request, suggested, ran string) error {
+	_, err := memoryservice.Save(ctx, memoryservice.SaveInput{
+		Text:     shellwidget.CorrectionText(request, suggested, ran),
+		Tags:     []string{shellwidget.CorrectionTag},
+		Kind:     memtypes.KindPreference,
+		Deferred: true,
+	})
+	return err
+}
+
+var ShellWidgetCmd = newShellWidgetCommand()
+
+func newShellWidgetCommand() *cobra.Command {
+	var key string
+
+	cmd := &cobra.Command{
+		Use:   "shell-widget <zsh|bash>",
+		Short: "Print a keybinding that turns the command line into a command",
+		Long: `Print a zsh or bash widget that binds Ctrl-G (or --key) to gomor. Type a
+request in plain words at the prompt, press the key, and the line is replaced
+with a command that does it, ready to edit or run. A half-written command is
+fixed or completed the same way.
+
+The cheapest configured model answers, so the widget stays quick. When you
+edit a suggestion before running it, the correction is saved as a memory
+tagged shell-correction and shown to the model on later requests.
+
+Install it by adding one line to your shell's startup file:
+  zsh:  eval "$(gomor shell-widget zsh)"    in ~/.zshrc
+  bash: eval "$(gomor shell-widget bash)"   in ~/.bashrc`,
+		Args:         cobra.ExactArgs(1),
+		ValidArgs:    []string{shellwidget.ShellZsh, shellwidget.ShellBash},
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			script, err := shellwidget.Script(args[0], key)
+			if err != nil {
+				return err
+			}
+			_, err = io.WriteString(cmd.OutOrStdout(), script)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&key, "key", "", `key to bind, in the shell's notation (default "^G" for zsh, "\C-g" for bash)`)
+	cmd.AddCommand(newSuggestCommand())
+	cmd.AddCommand(newLearnCommand())
+	return cmd
+}
+
+func newSuggestCommand() *cobra.Command {
+	var shell string
+
+	cmd := &cobra.Command{
+		Use:          "suggest <request>",
+		Short:        "Print a command for a request (used by the widget)",
+		Hidden:       true,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			request := strings.TrimSpace(strings.Join(args, " "))
+			if request == "" {
+				return fmt.Errorf("request is required")
+			}
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			command, err := suggestFn(ctx, shell, request, cmd.ErrOrStderr())
+			if err != nil {
+				return err
+			}
+			if command == "" {
+				return fmt.Errorf("the model suggested no command")
+			}
+			_, err = fmt.Fprint(cmd.OutOrStdout(), command)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&shell, "shell", shellwidget.ShellZsh, "shell the command is for")
+	return cmd
+}
+
+func newLearnCommand() *cobra.Command {
+	var request, suggested, ran string
+
+	cmd := &cobra.Command{
+		Use:          "learn",
+		Short:        "Remember a corrected suggestion (used by the widget)",
+		Hidden:       true,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			request, suggested, ran = strings.TrimSpace(request), strings.TrimSpace(suggested), strings.TrimSpace(ran)
+			if request == "" || suggested == "" || ran == "" {
+				return fmt.Errorf("--request, --suggested, and --ran are required")
+			}
+			if ran == suggested {
+				return nil
+			}
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			return learnFn(ctx, request, suggested, ran)
+		},
+	}
+
+	cmd.Flags().StringVar(&request, "request", "", "the request the suggestion answered")
+	cmd.Flags().StringVar(&suggested, "suggested", "", "the suggested command")
+	cmd.Flags().StringVar(&ran, "ran", "", "the command the user ran instead")
+	return cmd
+}
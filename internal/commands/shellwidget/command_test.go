@@ -0,0 +1,83 @@
+package shellwidget
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestShellWidgetPrintsScript(t *testing.T) {
+	cmd := newShellWidgetCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"bash", "--key", `\C-t`})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if !strings.Contains(out.String(), `bind -x '"\C-t": _gomor_widget'`) {
+		t.Fatalf("expected a bash widget bound to the key, got:\n%s", out.String())
+	}
+}
+
+func TestShellWidgetSuggest(t *testing.T) {
+	oldSuggest := suggestFn
+	defer func() { suggestFn = oldSuggest }()
+
+	var gotShell, gotRequest string
+	suggestFn = func(ctx context.Context, shell, request string, errOut io.Writer) (string, error) {
+		gotShell, gotRequest = shell, request
+		return "du -ah . | sort -h | tail", nil
+	}
+
+	cmd := newShellWidgetCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"suggest", "--shell", "bash", "--", "list", "big files"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if gotShell != "bash" || gotRequest != "list big files" {
+		t.Fatalf("unexpected suggest call: %q %q", gotShell, gotRequest)
+	}
+	// The widget puts the output straight into the command line, so no newline.
+	if out.String() != "du -ah . | sort -h | tail" {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+}
+
+func TestShellWidgetLearn(t *testing.T) {
+	oldLearn := learnFn
+	defer func() { learnFn = oldLearn }()
+
+	var calls [][3]string
+	learnFn = func(ctx context.Context, request, suggested, ran string) error {
+		calls = append(calls, [3]string{request, suggested, ran})
+		return nil
+	}
+
+	run := func(args ...string) error {
+		cmd := newShellWidgetCommand()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(append([]string{"learn"}, args...))
+		return cmd.Execute()
+	}
+
+	if err := run("--request", "find go files", "--suggested", "find . -name '*.go'", "--ran", "fd -e go"); err != nil {
+		t.Fatalf("learn: %v", err)
+	}
+	if err := run("--request", "find go files", "--suggested", "fd -e go", "--ran", " fd -e go "); err != nil {
+		t.Fatalf("learn unchanged: %v", err)
+	}
+	if len(calls) != 1 || calls[0][2] != "fd -e go" {
+		t.Fatalf("expected only the edited suggestion remembered, got %v", calls)
+	}
+
+	if err := run("--request", "find go files"); err == nil {
+		t.Fatal("expected an error without --suggested and --ran")
+	}
+}
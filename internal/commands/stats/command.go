@@ -0,0 +1,196 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/spf13/cobra"
+)
+
+var statsFn = memoryservice.Stats
+
+// maxGroupsShown is how many sets of duplicates the text output lists.
+const maxGroupsShown = 10
+
+type statsCommandOptions struct {
+	health     bool
+	jsonOutput bool
+}
+
+type statsOutput struct {
+	Memories         int           `json:"memories"`
+	Archived         int           `json:"archived"`
+	History          int           `json:"history"`
+	NeedsReembedding int           `json:"needs_reembedding,omitempty"`
+	EmbeddingDims    map[int]int   `json:"embedding_dims,omitempty"` // embedded memories by dimension
+	Health           *healthOutput `json:"health,omitempty"`
+}
+
+type healthOutput struct {
+	Score                int        `json:"score"` // 0-100
+	Duplicates           int        `json:"duplicates"`
+	DuplicateRatio       float64    `json:"duplicate_ratio"`
+	DuplicateGroups      [][]string `json:"duplicate_groups,omitempty"`
+	Stale                int        `json:"stale"`
+	StaleRatio           float64    `json:"stale_ratio"`
+	StaleAfterDays       int        `json:"stale_after_days"`
+	StaleBelowConfidence float64    `json:"stale_below_confidence"`
+	FTSOrphaned          int        `json:"fts_orphaned"`
+	FTSMissing           int        `json:"fts_missing"`
+	Dim                  int        `json:"dim,omitempty"`
+	DimMismatches        []string   `json:"dim_mismatches,omitempty"`
+	Actions              []string   `json:"actions,omitempty"`
+}
+
+var StatsCmd = newStatsCommand()
+
+func newStatsCommand() *cobra.Command {
+	opts := &statsCommandOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show what the memory store holds and how healthy it is",
+		Long: `Count the memories, archived memories, and history turns, and the embedded
+memories by dimension.
+
+With --health, also measure the garbage that builds up in the store and
+score it from 0 to 100:
+
+  duplicates       memories repeating an older memory's text
+  stale            memories the archival policy would archive (by default,
+                   not used in 90 days with confidence below 0.5)
+  full-text index  rows pointing at no memory, and memories missing from it
+  dimensions       memories embedded at another dimension than the
+                   embedding model's, which vector search skips
+
+and suggest the maintenance each problem calls for.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStatsCommand(cmd, opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.health, "health", false, "score the store's health and suggest maintenance")
+	cmd.Flags().BoolVar(&opts.jsonOutput, "json", false, "emit structured JSON output")
+
+	return cmd
+}
+
+func runStatsCommand(cmd *cobra.Command, opts *statsCommandOptions) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	result, err := statsFn(ctx, memoryservice.StatsInput{Health: opts.health})
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if opts.jsonOutput {
+		return writeJSON(out, newStatsOutput(result))
+	}
+	return writeText(out, result)
+}
+
+func newStatsOutput(result *memoryservice.StatsResult) statsOutput {
+	output := statsOutput{
+		Memories:         result.Memories,
+		Archived:         result.Archived,
+		History:          result.History,
+		NeedsReembedding: result.NeedsReembedding,
+		EmbeddingDims:    result.EmbeddingDims,
+	}
+	if h := result.Health; h != nil {
+		output.Health = &healthOutput{
+			Score:                h.Score,
+			Duplicates:           h.Duplicates,
+			DuplicateRatio:       share(h.Duplicates, result.Memories),
+			DuplicateGroups:      h.DuplicateGroups,
+			Stale:                h.Stale,
+			StaleRatio:           share(h.Stale, result.Memories),
+			StaleAfterDays:       h.StaleAfterDays,
+			StaleBelowConfidence: h.StaleBelowConfidence,
+			FTSOrphaned:          h.FTSOrphaned,
+			FTSMissing:           h.FTSMissing,
+			Dim:                  h.Dim,
+			DimMismatches:        h.DimMismatches,
+			Actions:              h.Actions,
+		}
+	}
+	return output
+}
+
+func writeText(out io.Writer, result *memoryservice.StatsResult) error {
+	fmt.Fprintf(out, "Memories: %d (%d archived)\n", result.Memories, result.Archived)
+	fmt.Fprintf(out, "History turns: %d\n", result.History)
+	if len(result.EmbeddingDims) > 0 {
+		dims := make([]int, 0, len(result.EmbeddingDims))
+		for dim := range result.EmbeddingDims {
+			dims = append(dims, dim)
+		}
+		slices.Sort(dims)
+		parts := make([]string, len(dims))
+		for i, dim := range dims {
+			parts[i] = fmt.Sprintf("%d at %d dimensions", result.EmbeddingDims[dim], dim)
+		}
+		fmt.Fprintf(out, "Embeddings: %s\n", strings.Join(parts, ", "))
+	}
+	if result.NeedsReembedding > 0 {
+		fmt.Fprintf(out, "Awaiting re-embedding: %d\n", result.NeedsReembedding)
+	}
+
+	h := result.Health
+	if h == nil {
+		return nil
+	}
+	fmt.Fprintf(out, "\nHealth: %d/100\n", h.Score)
+	fmt.Fprintf(out, "  Duplicates: %d (%s)\n", h.Duplicates, percent(h.Duplicates, result.Memories))
+	for i, group := range h.DuplicateGroups {
+		if i == maxGroupsShown {
+			fmt.Fprintf(out, "    and %d more\n", len(h.DuplicateGroups)-i)
+			break
+		}
+		fmt.Fprintf(out, "    %s repeated by %s\n", group[0], strings.Join(group[1:], ", "))
+	}
+	fmt.Fprintf(out, "  Stale: %d (%s) not used in %d days with confidence below %g\n", h.Stale, percent(h.Stale, result.Memories), h.StaleAfterDays, h.StaleBelowConfidence)
+	fmt.Fprintf(out, "  Full-text index: %d orphaned rows, %d memories missing\n", h.FTSOrphaned, h.FTSMissing)
+	if h.Dim > 0 {
+		fmt.Fprintf(out, "  Embedding dimensions: %d memories not at %d dimensions\n", len(h.DimMismatches), h.Dim)
+	}
+
+	if len(h.Actions) == 0 {
+		_, err := fmt.Fprintln(out, "No maintenance needed.")
+		return err
+	}
+	fmt.Fprintln(out, "Suggested maintenance:")
+	for _, action := range h.Actions {
+		fmt.Fprintf(out, "  - %s\n", action)
+	}
+	return nil
+}
+
+// share returns n/total, or 0 when total is 0.
+func share(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
+
+func percent(n, total int) string {
+	return fmt.Sprintf("%.1f%%", 100*share(n, total))
+}
+
+func writeJSON(out io.Writer, value any) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
@@ -0,0 +1,90 @@
+package stats
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+)
+
+func fakeStats(t *testing.T, fn func(input memoryservice.StatsInput) *memoryservice.StatsResult) {
+	t.Helper()
+	old := statsFn
+	t.Cleanup(func() { statsFn = old })
+	statsFn = func(ctx context.Context, input memoryservice.StatsInput) (*memoryservice.StatsResult, error) {
+		return fn(input), nil
+	}
+}
+
+func execute(args ...string) (string, error) {
+	cmd := newStatsCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func sampleStats(input memoryservice.StatsInput) *memoryservice.StatsResult {
+	result := &memoryservice.StatsResult{Memories: 8, Archived: 2, History: 40, EmbeddingDims: map[int]int{1536: 7, 768: 1}}
+	if input.Health {
+		result.Health = &memoryservice.HealthReport{
+			Score:                84,
+			Duplicates:           2,
+			DuplicateGroups:      [][]string{{"mem-1", "mem-4", "mem-6"}},
+			StaleAfterDays:       90,
+			StaleBelowConfidence: 0.5,
+			Dim:                  1536,
+			DimMismatches:        []string{"mem-3"},
+			Actions:              []string{"1 memories are embedded at another dimension", "2 memories repeat an older memory's text"},
+		}
+	}
+	return result
+}
+
+func TestStatsShowsCounts(t *testing.T) {
+	fakeStats(t, sampleStats)
+
+	out, err := execute()
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	want := "Memories: 8 (2 archived)\nHistory turns: 40\nEmbeddings: 1 at 768 dimensions, 7 at 1536 dimensions\n"
+	if out != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestStatsHealth(t *testing.T) {
+	fakeStats(t, sampleStats)
+
+	out, err := execute("--health")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	for _, want := range []string{
+		"Health: 84/100\n",
+		"  Duplicates: 2 (25.0%)\n    mem-1 repeated by mem-4, mem-6\n",
+		"  Embedding dimensions: 1 memories not at 1536 dimensions\n",
+		"Suggested maintenance:\n  - 1 memories are embedded at another dimension\n  - 2 memories repeat",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in:\n%s", want, out)
+		}
+	}
+
+	out, err = execute("--health", "--json")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	var payload statsOutput
+	if err := json.Unmarshal([]byte(out), &payload); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if payload.Health == nil || payload.Health.Score != 84 || payload.Health.DuplicateRatio != 0.25 || len(payload.Health.Actions) != 2 {
+		t.Fatalf("unexpected payload: %+v", payload.Health)
+	}
+}
@@ -0,0 +1,132 @@
+package stdinfilter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/chat"
+	"github.com/austiecodes/gomor/internal/markdown"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/pricing"
+	"github.com/austiecodes/gomor/internal/provider"
+	"github.com/austiecodes/gomor/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// askFn sends prompt to the chat model and returns the whole answer. Nothing
+// is written while it runs: editors read the filter's output as the new text.
+var askFn = func(ctx context.Context, prompt string, enforceBudget bool) (string, error) {
+	config, err := utils.LoadConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+	if config.Model.ChatModel == nil {
+		return "", utils.ConfigErrorf("chat model not configured. Run 'gomor set' to configure")
+	}
+	chatModel := *config.Model.ChatModel
+
+	queryClient, err := provider.NewRoleQueryClient(config, utils.RoleChat, chatModel, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create chat client: %w", err)
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		return "", fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	budget := pricing.NewBudget(memStore, config.Budget, enforceBudget, io.Discard)
+	return chat.Ask(ctx, queryClient, chatModel, prompt, budget, io.Discard)
+}
+
+type stdinFilterOptions struct {
+	instruct      string
+	enforceBudget bool
+}
+
+var StdinFilterCmd = newStdinFilterCommand()
+
+func newStdinFilterCommand() *cobra.Command {
+	opts := &stdinFilterOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "stdin-filter",
+		Short: "Transform text from stdin for editor filters",
+		Long: `Read text from stdin, apply the --instruct instruction with the chat model,
+and write only the transformed text to stdout: no banners, notes, or code
+fences. A trailing newline on the input is kept on the output.
+
+Use it as an editor filter, for example:
+  vim:    :'<,'>!gomor stdin-filter --instruct "convert to a markdown table"
+  emacs:  C-u M-| gomor stdin-filter --instruct "fix the grammar"
+
+Budget warnings are not shown; --enforce-budget still refuses to send once the
+budget is used up. Errors go to stderr with a non-zero exit status.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStdinFilter(cmd, opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.instruct, "instruct", "i", "", "what to do with the text (required)")
+	cmd.Flags().BoolVar(&opts.enforceBudget, "enforce-budget", false, "refuse to send the text when the budget is used up")
+	_ = cmd.MarkFlagRequired("instruct")
+	return cmd
+}
+
+func runStdinFilter(cmd *cobra.Command, opts *stdinFilterOptions) error {
+	instruct := strings.TrimSpace(opts.instruct)
+	if instruct == "" {
+		return fmt.Errorf("--instruct must not be empty")
+	}
+
+	input, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return fmt.Errorf("failed to read stdin: %w", err)
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	answer, err := askFn(ctx, filterPrompt(instruct, string(input)), opts.enforceBudget)
+	if err != nil {
+		return err
+	}
+
+	output := unfence(answer, string(input))
+	if strings.HasSuffix(string(input), "\n") {
+		output += "\n"
+	}
+	_, err = io.WriteString(cmd.OutOrStdout(), output)
+	return err
+}
+
+// filterPrompt asks for instruct applied to text, and for nothing else.
+func filterPrompt(instruct, text string) string {
+	return "Apply the instruction to the text between the markers. " +
+		"Reply with the resulting text only: no introduction, explanation, or code fence around it.\n\n" +
+		"Instruction: " + instruct + "\n\n" +
+		"<<<TEXT\n" + text + "\nTEXT>>>"
+}
+
+// unfence returns answer without surrounding blank lines, and without the
+// code fence a model may wrap it in despite being asked not to. Fences are
+// kept when the input was fenced itself.
+func unfence(answer, input string) string {
+	trimmed := strings.Trim(answer, "\n")
+	if strings.HasPrefix(strings.TrimSpace(input), "```") {
+		return trimmed
+	}
+	if strings.HasPrefix(strings.TrimSpace(trimmed), "```") && strings.HasSuffix(strings.TrimSpace(trimmed), "```") {
+		if blocks := markdown.CodeBlocks(trimmed); len(blocks) == 1 {
+			return strings.Trim(blocks[0].Code, "\n")
+		}
+	}
+	return trimmed
+}
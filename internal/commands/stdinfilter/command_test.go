@@ -0,0 +1,65 @@
+package stdinfilter
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func runFilter(t *testing.T, input string, answer string, args ...string) (string, string, error) {
+	t.Helper()
+	oldAsk := askFn
+	t.Cleanup(func() { askFn = oldAsk })
+
+	var gotPrompt string
+	askFn = func(ctx context.Context, prompt string, enforceBudget bool) (string, error) {
+		gotPrompt = prompt
+		return answer, nil
+	}
+
+	cmd := newStdinFilterCommand()
+	var out, errOut bytes.Buffer
+	cmd.SetIn(strings.NewReader(input))
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), gotPrompt, err
+}
+
+func TestStdinFilterWritesOnlyTransformedText(t *testing.T) {
+	out, prompt, err := runFilter(t, "a,1\nb,2\n", "```markdown\n| x | n |\n|---|---|\n| a | 1 |\n| b | 2 |\n```\n",
+		"--instruct", "convert to table")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if !strings.Contains(prompt, "Instruction: convert to table") || !strings.Contains(prompt, "a,1\nb,2\n") {
+		t.Fatalf("unexpected prompt:\n%s", prompt)
+	}
+	want := "| x | n |\n|---|---|\n| a | 1 |\n| b | 2 |\n"
+	if out != want {
+		t.Fatalf("output = %q, want %q", out, want)
+	}
+}
+
+func TestStdinFilterKeepsFencesForFencedInput(t *testing.T) {
+	answer := "```go\nfmt.Println(\"hi\")\n```"
+	out, _, err := runFilter(t, "```go\nprint(\"hi\")\n```", answer, "-i", "port to Go")
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	// No trailing newline on the input, so none is added.
+	if out != answer {
+		t.Fatalf("output = %q, want %q", out, answer)
+	}
+}
+
+func TestStdinFilterRequiresInstruction(t *testing.T) {
+	if _, _, err := runFilter(t, "text", "", "--instruct", "  "); err == nil {
+		t.Fatal("expected an error for an empty instruction")
+	}
+	if _, _, err := runFilter(t, "text", ""); err == nil {
+		t.Fatal("expected an error without --instruct")
+	}
+}
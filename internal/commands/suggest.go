@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// maxSuggestArgs bounds the prompts checked for a mistyped command: a typo
+// comes with at most one argument ("gomor memroy review"), while longer
+// prompts are questions even when their first word looks like a command.
+const maxSuggestArgs = 2
+
+// unknownCommandError reports that the first word of a short prompt looks like
+// a mistyped subcommand of cmd, or nil if it does not.
+func unknownCommandError(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 || len(args) > maxSuggestArgs || cmd.ArgsLenAtDash() == 0 {
+		return nil
+	}
+	word := args[0]
+	if strings.ContainsAny(word, " \t\n") {
+		return nil
+	}
+	suggestions := suggestCommands(cmd, word)
+	if len(suggestions) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "unknown command %q for %q\n\nDid you mean this?\n", word, cmd.CommandPath())
+	for _, name := range suggestions {
+		fmt.Fprintf(&sb, "\t%s\n", name)
+	}
+	prompt := strings.Join(args, " ")
+	fmt.Fprintf(&sb, "\nTo send it as a prompt, run: %s --prompt %q (or %s -- %s)", cmd.CommandPath(), prompt, cmd.CommandPath(), prompt)
+	return fmt.Errorf("%s", sb.String())
+}
+
+// suggestCommands lists the subcommands of cmd that word is one or two edits
+// away from. Names of four letters or fewer allow one edit, so short words
+// like "sort" are not taken for "set".
+func suggestCommands(cmd *cobra.Command, word string) []string {
+	word = strings.ToLower(word)
+	var suggestions []string
+	for _, sub := range cmd.Commands() {
+		if !sub.IsAvailableCommand() && sub.Name() != "help" {
+			continue
+		}
+		for _, name := range append([]string{sub.Name()}, sub.Aliases...) {
+			maxEdits := 2
+			if len(name) <= 4 {
+				maxEdits = 1
+			}
+			if d := editDistance(word, name); d > 0 && d <= maxEdits {
+				suggestions = append(suggestions, sub.Name())
+				break
+			}
+		}
+	}
+	return suggestions
+}
+
+// editDistance is the optimal string alignment distance between a and b:
+// insertions, deletions, substitutions, and swaps of adjacent letters each
+// cost one edit, so "memroy" is one edit from "memory".
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestEditDistance(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want int
+	}{
+		{"memroy", "memory", 1},
+		{"serach", "search", 1},
+		{"chta", "chat", 1},
+		{"sort", "set", 2},
+		{"", "set", 3},
+		{"memory", "memory", 0},
+	} {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestQuerySuggestsMistypedCommands(t *testing.T) {
+	newCommand := func() (*cobra.Command, *string) {
+		cmd, _, prompt := newTestQueryCommand("answer")
+		cmd.AddCommand(&cobra.Command{Use: "memory", Run: func(*cobra.Command, []string) {}})
+		cmd.AddCommand(&cobra.Command{Use: "set", Run: func(*cobra.Command, []string) {}})
+		return cmd, prompt
+	}
+
+	cmd, prompt := newCommand()
+	cmd.SetArgs([]string{"memroy", "review"})
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "Did you mean this?\n\tmemory") {
+		t.Fatalf("expected a suggestion, got %v", err)
+	}
+	if *prompt != "" {
+		t.Fatalf("expected nothing to be sent, got %q", *prompt)
+	}
+
+	for _, args := range [][]string{
+		{"--", "memroy"},
+		{"--prompt", "memroy"},
+		{"sort", "these"},
+		{"memroy", "is", "misspelled"},
+	} {
+		cmd, prompt := newCommand()
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%v: execute: %v", args, err)
+		}
+		if *prompt == "" {
+			t.Fatalf("%v: expected the prompt to be sent", args)
+		}
+	}
+}
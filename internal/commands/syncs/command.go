@@ -0,0 +1,108 @@
+package syncs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/spf13/cobra"
+)
+
+var syncObsidianFn = memoryservice.SyncObsidian
+
+type obsidianCommandOptions struct {
+	vault      string
+	readBack   bool
+	jsonOutput bool
+}
+
+type obsidianOutput struct {
+	Message string `json:"message"`
+	Dir     string `json:"dir"`
+	Written int    `json:"written"`
+	Updated int    `json:"updated"`
+	Removed int    `json:"removed"`
+	Pending bool   `json:"pending,omitempty"`
+}
+
+var SyncCmd = newSyncCommand()
+
+func newSyncCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Sync memories with other tools",
+	}
+	cmd.AddCommand(newObsidianCommand())
+	return cmd
+}
+
+func newObsidianCommand() *cobra.Command {
+	opts := &obsidianCommandOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "obsidian",
+		Short: "Mirror memories into an Obsidian vault",
+		Long: `Write one markdown note per memory into the configured Obsidian vault
+folder (obsidian.vault_path / obsidian.folder), with tags and kind in the
+frontmatter. Notes whose memory was deleted are removed.
+
+With --read-back, edits to a note's text, tags, or kind are applied to the
+memory first (edited text is re-embedded). Without it, the store wins and
+edits in the vault are overwritten.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runObsidianCommand(cmd, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.vault, "vault", "", "vault path (overrides obsidian.vault_path)")
+	cmd.Flags().BoolVar(&opts.readBack, "read-back", false, "apply edits made in the vault before mirroring")
+	cmd.Flags().BoolVar(&opts.jsonOutput, "json", false, "emit structured JSON output")
+
+	return cmd
+}
+
+func runObsidianCommand(cmd *cobra.Command, opts *obsidianCommandOptions) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	result, err := syncObsidianFn(ctx, memoryservice.SyncObsidianInput{
+		VaultPath: opts.vault,
+		ReadBack:  opts.readBack,
+	})
+	if err != nil {
+		return err
+	}
+
+	output := obsidianOutput{
+		Message: fmt.Sprintf("Synced %s: %d notes written, %d memories updated, %d notes removed",
+			result.Dir, result.Written, result.Updated, result.Removed),
+		Dir:     result.Dir,
+		Written: result.Written,
+		Updated: result.Updated,
+		Removed: result.Removed,
+		Pending: result.Pending,
+	}
+	if result.Pending {
+		output.Message += "; embeddings were queued for retry"
+	}
+
+	out := cmd.OutOrStdout()
+	if opts.jsonOutput {
+		return writeJSON(out, output)
+	}
+
+	_, err = fmt.Fprintln(out, output.Message)
+	return err
+}
+
+func writeJSON(out io.Writer, value any) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
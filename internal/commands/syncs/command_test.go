@@ -0,0 +1,44 @@
+package syncs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/obsidian"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+)
+
+func TestSyncObsidianCommandJSONOutput(t *testing.T) {
+	oldSync := syncObsidianFn
+	defer func() { syncObsidianFn = oldSync }()
+
+	var gotInput memoryservice.SyncObsidianInput
+	syncObsidianFn = func(ctx context.Context, input memoryservice.SyncObsidianInput) (*memoryservice.SyncObsidianResult, error) {
+		gotInput = input
+		return &memoryservice.SyncObsidianResult{
+			Result: obsidian.Result{Dir: "/vault/gomor", Written: 3, Updated: 1, Removed: 2},
+		}, nil
+	}
+
+	cmd := newSyncCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"obsidian", "--vault", "/vault", "--read-back", "--json"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if gotInput.VaultPath != "/vault" || !gotInput.ReadBack {
+		t.Fatalf("unexpected input: %+v", gotInput)
+	}
+
+	var payload obsidianOutput
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if payload.Dir != "/vault/gomor" || payload.Written != 3 || payload.Updated != 1 || payload.Removed != 2 {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+}
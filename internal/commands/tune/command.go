@@ -0,0 +1,85 @@
+package tune
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/retrieval"
+	memoryservice "github.com/austiecodes/gomor/internal/memory/service"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// tuner searches memories once per query and ranks the results under the
+// settings being tuned.
+type tuner interface {
+	Candidates(ctx context.Context, query string) (*retrieval.Candidates, error)
+	SearchFTS(ctx context.Context, memory utils.MemoryConfig, c *retrieval.Candidates)
+	Fuse(memory utils.MemoryConfig, c *retrieval.Candidates) []memtypes.UnifiedResult
+	Close() error
+}
+
+var (
+	loadConfigFn = utils.LoadConfig
+	saveConfigFn = utils.SaveConfig
+	newTunerFn   = func(config *utils.Config) (tuner, error) {
+		return memoryservice.NewRetriever(config)
+	}
+	runProgramFn = func(m Model) (Model, error) {
+		final, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+		if err != nil {
+			return m, fmt.Errorf("error running tuner: %w", err)
+		}
+		return final.(Model), nil
+	}
+)
+
+var TuneCmd = &cobra.Command{
+	Use:   "tune [query]",
+	Short: "Try retrieval settings on live queries and save the ones that work",
+	Long: `Open a playground that shows, side by side, what vector search, full-text
+search, and their fusion retrieve for a query, and re-ranks them as
+retrieval settings change:
+
+  /         edit the query; Enter searches
+  [ ]       lower or raise memory.min_similarity by 0.05 ({ } by 0.01)
+  - +       shift memory.fusion_vector_weight between full-text and vector
+  s         switch memory.fts_strategy: auto, direct, summary
+  r         go back to the saved settings
+  w         save the settings to the config
+  q         quit
+
+Each query is searched once; changing a setting re-ranks the results without
+calling a model, except switching the FTS strategy, which searches again.
+Tuning does not count results as retrieved.`,
+	Args:         cobra.ArbitraryArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTuneCommand(cmd, strings.Join(args, " "))
+	},
+}
+
+func runTuneCommand(cmd *cobra.Command, query string) error {
+	config, err := loadConfigFn()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	t, err := newTunerFn(config)
+	if err != nil {
+		return err
+	}
+	defer t.Close()
+
+	final, err := runProgramFn(initialModel(config, t, strings.TrimSpace(query)))
+	if err != nil {
+		return err
+	}
+	if final.dirty() {
+		fmt.Fprintln(cmd.ErrOrStderr(), "Quit without saving the tuned settings.")
+	}
+	return nil
+}
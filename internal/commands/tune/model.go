@@ -0,0 +1,389 @@
+package tune
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/retrieval"
+	"github.com/austiecodes/gomor/internal/termcap"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+const (
+	similarityStep     = 0.05
+	fineSimilarityStep = 0.01
+	weightStep         = 0.05
+
+	defaultWidth = 120
+	columnGap    = 3
+)
+
+// Model is the tuning playground. memory holds the settings being tried;
+// config holds the saved ones.
+type Model struct {
+	config *utils.Config
+	tuner  tuner
+	memory utils.MemoryConfig
+
+	input      textinput.Model
+	editing    bool
+	searching  bool
+	candidates *retrieval.Candidates
+	fused      []memtypes.UnifiedResult
+	status     string
+	err        error
+	width      int
+}
+
+// searchedMsg carries the results of searching query.
+type searchedMsg struct {
+	query      string
+	candidates *retrieval.Candidates
+	err        error
+}
+
+// ftsSearchedMsg carries candidates searched again with FTS strategy strategy.
+type ftsSearchedMsg struct {
+	strategy   string
+	candidates *retrieval.Candidates
+}
+
+// savedMsg reports saving memory to the config.
+type savedMsg struct {
+	memory utils.MemoryConfig
+	err    error
+}
+
+func initialModel(config *utils.Config, t tuner, query string) Model {
+	input := textinput.New()
+	input.Placeholder = "what would you ask your memories?"
+	input.CharLimit = 500
+	input.Width = 60
+	input.SetValue(query)
+
+	m := Model{config: config, tuner: t, memory: config.Memory, input: input}
+	if query == "" {
+		m.editing = true
+		m.input.Focus()
+	} else {
+		m.searching = true
+	}
+	return m
+}
+
+func (m Model) Init() tea.Cmd {
+	if m.searching {
+		return m.search(m.input.Value())
+	}
+	return textinput.Blink
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.input.Width = min(msg.Width-10, 80)
+		return m, nil
+
+	case searchedMsg:
+		m.searching = false
+		m.err = msg.err
+		if msg.err == nil {
+			m.candidates = msg.candidates
+			m.status = ""
+			m.refuse()
+		}
+		return m, nil
+
+	case ftsSearchedMsg:
+		if m.candidates == nil || msg.candidates.Query != m.candidates.Query || msg.strategy != m.memory.FTSStrategy {
+			return m, nil // a newer query or strategy replaced it
+		}
+		m.searching = false
+		m.candidates = msg.candidates
+		m.refuse()
+		return m, nil
+
+	case savedMsg:
+		m.err = msg.err
+		if msg.err == nil {
+			m.config.Memory = msg.memory
+			m.status = "Saved to the config."
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		if m.editing {
+			return m.updateQuery(msg)
+		}
+		return m.updateSettings(msg)
+	}
+
+	if m.editing {
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// updateQuery handles keys while the query is edited.
+func (m Model) updateQuery(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		query := strings.TrimSpace(m.input.Value())
+		if query == "" {
+			return m, nil
+		}
+		m.editing = false
+		m.input.Blur()
+		m.searching = true
+		m.err = nil
+		return m, m.search(query)
+	case "esc":
+		if m.candidates == nil {
+			return m, tea.Quit
+		}
+		m.editing = false
+		m.input.Blur()
+		m.input.SetValue(m.candidates.Query)
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// updateSettings handles keys that change the settings.
+func (m Model) updateSettings(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	strategy := m.memory.FTSStrategy
+	switch msg.String() {
+	case "q", "esc":
+		return m, tea.Quit
+	case "/", "i":
+		m.editing = true
+		m.input.CursorEnd()
+		return m, m.input.Focus()
+	case "[":
+		m.memory.MinSimilarity = step(m.memory.MinSimilarity, -similarityStep, 0.01, 0.99)
+	case "]":
+		m.memory.MinSimilarity = step(m.memory.MinSimilarity, similarityStep, 0.01, 0.99)
+	case "{":
+		m.memory.MinSimilarity = step(m.memory.MinSimilarity, -fineSimilarityStep, 0.01, 0.99)
+	case "}":
+		m.memory.MinSimilarity = step(m.memory.MinSimilarity, fineSimilarityStep, 0.01, 0.99)
+	case "-":
+		m.memory.FusionVectorWeight = step(m.memory.FusionVectorWeight, -weightStep, 0.05, 0.95)
+	case "+", "=":
+		m.memory.FusionVectorWeight = step(m.memory.FusionVectorWeight, weightStep, 0.05, 0.95)
+	case "s":
+		m.memory.FTSStrategy = nextStrategy(m.memory.FTSStrategy)
+	case "r":
+		m.memory = m.config.Memory
+	case "w":
+		return m, m.save()
+	default:
+		return m, nil
+	}
+
+	m.status = ""
+	if m.memory.FTSStrategy != strategy && m.candidates != nil {
+		m.searching = true
+		return m, m.searchFTS()
+	}
+	m.refuse()
+	return m, nil
+}
+
+// refuse ranks the candidates under the current settings.
+func (m *Model) refuse() {
+	if m.candidates != nil {
+		m.fused = m.tuner.Fuse(m.memory, m.candidates)
+	}
+}
+
+// dirty reports whether the tuned settings differ from the saved ones.
+func (m Model) dirty() bool {
+	saved := m.config.Memory
+	return m.memory.MinSimilarity != saved.MinSimilarity ||
+		m.memory.FusionVectorWeight != saved.FusionVectorWeight ||
+		m.memory.FTSStrategy != saved.FTSStrategy
+}
+
+func (m Model) search(query string) tea.Cmd {
+	t := m.tuner
+	return func() tea.Msg {
+		candidates, err := t.Candidates(context.Background(), query)
+		return searchedMsg{query: query, candidates: candidates, err: err}
+	}
+}
+
+// searchFTS searches a copy of the candidates again with the tuned FTS
+// strategy, leaving the shown ones alone until it is done.
+func (m Model) searchFTS() tea.Cmd {
+	t, memory, candidates := m.tuner, m.memory, *m.candidates
+	return func() tea.Msg {
+		t.SearchFTS(context.Background(), memory, &candidates)
+		return ftsSearchedMsg{strategy: memory.FTSStrategy, candidates: &candidates}
+	}
+}
+
+// save writes the tuned settings to the config, keeping everything else as
+// loaded.
+func (m Model) save() tea.Cmd {
+	config, memory := *m.config, m.memory
+	return func() tea.Msg {
+		config.Memory = memory
+		return savedMsg{memory: memory, err: saveConfigFn(&config)}
+	}
+}
+
+// step moves v by delta, rounded to hundredths and kept within [lo, hi].
+func step(v, delta, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, math.Round((v+delta)*100)/100))
+}
+
+// nextStrategy returns the FTS strategy after strategy.
+func nextStrategy(strategy string) string {
+	for i, s := range utils.FTSStrategies {
+		if s == strategy {
+			return utils.FTSStrategies[(i+1)%len(utils.FTSStrategies)]
+		}
+	}
+	return utils.FTSStrategies[0]
+}
+
+func (m Model) View() string {
+	var sb strings.Builder
+	sb.WriteString(TitleStyle.Render("Retrieval tuning") + "\n")
+	if m.editing {
+		sb.WriteString("Query: " + m.input.View() + "\n")
+	} else {
+		sb.WriteString("Query: " + m.input.Value() + "\n")
+	}
+
+	settings := fmt.Sprintf("min_similarity %.2f   fusion vector %.2f / fts %.2f   fts_strategy %s",
+		m.memory.MinSimilarity, m.memory.FusionVectorWeight, 1-m.memory.FusionVectorWeight, m.memory.FTSStrategy)
+	if m.dirty() {
+		settings += "   (unsaved)"
+	}
+	sb.WriteString(InputLabelStyle.Render(settings) + "\n")
+
+	switch {
+	case m.err != nil:
+		sb.WriteString(ErrorStyle.Render("Error: "+m.err.Error()) + "\n")
+	case m.searching:
+		sb.WriteString("Searching" + termcap.Glyph("…", "...") + "\n")
+	case m.status != "":
+		sb.WriteString(m.status + "\n")
+	default:
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+
+	if m.candidates != nil {
+		sb.WriteString(m.panels() + "\n\n")
+	}
+
+	if m.editing {
+		sb.WriteString(HelpStyle.Render("enter: search • esc: back"))
+	} else {
+		sb.WriteString(HelpStyle.Render("/: query • [ ] { }: min_similarity • - +: fusion weight • s: fts strategy • r: reset • w: save • q: quit"))
+	}
+	return sb.String()
+}
+
+// panels lays out the vector, FTS, and fused results side by side.
+func (m Model) panels() string {
+	width := m.width
+	if width <= 0 {
+		width = defaultWidth
+	}
+	columnWidth := (width - 2*columnGap) / 3
+	rows := m.memory.MemoryTopK
+	if rows <= 0 {
+		rows = 10
+	}
+	c := m.candidates
+
+	var vector []string
+	above := 0
+	for _, res := range c.Vector {
+		if res.Similarity < m.memory.MinSimilarity {
+			if len(vector) < rows {
+				vector = append(vector, DimStyle.Render(row(fmt.Sprintf("%.2f", res.Similarity), res.Item.Text, columnWidth)))
+			}
+			continue
+		}
+		above++
+		if len(vector) < rows {
+			vector = append(vector, row(fmt.Sprintf("%.2f", res.Similarity), res.Item.Text, columnWidth))
+		}
+	}
+	if c.VectorErr != nil {
+		vector = []string{ErrorStyle.Render(c.VectorErr.Error())}
+	}
+
+	var fts []string
+	for _, res := range c.FTS[:min(len(c.FTS), rows)] {
+		fts = append(fts, row(fmt.Sprintf("%.2f", res.Rank), res.Item.Text, columnWidth))
+	}
+	if c.FTSErr != nil {
+		fts = []string{ErrorStyle.Render(c.FTSErr.Error())}
+	}
+
+	var fused []string
+	for _, res := range m.fused {
+		fused = append(fused, row(fmt.Sprintf("%.2f %-3s", res.Score, sourceLabel(res.Source)), res.Item.Text, columnWidth))
+	}
+
+	column := lipgloss.NewStyle().Width(columnWidth)
+	header := column.Bold(true).BorderStyle(lipgloss.NormalBorder()).BorderBottom(true)
+	gap := strings.Repeat(" ", columnGap)
+	panel := func(title string, lines []string) string {
+		if len(lines) == 0 {
+			lines = []string{HelpStyle.Render("no results")}
+		}
+		return lipgloss.JoinVertical(lipgloss.Left, header.Render(title), column.Render(strings.Join(lines, "\n")))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		panel(fmt.Sprintf("Vector (%d of %d above %.2f)", above, len(c.Vector), m.memory.MinSimilarity), vector), gap,
+		panel(fmt.Sprintf("Full-text (%s)", m.memory.FTSStrategy), fts), gap,
+		panel(fmt.Sprintf("Fused (%d)", len(m.fused)), fused),
+	)
+}
+
+// sourceLabel abbreviates the path that found a fused result.
+func sourceLabel(source string) string {
+	switch source {
+	case "both":
+		return "v+f"
+	case "vector":
+		return "v"
+	default:
+		return "f"
+	}
+}
+
+// row formats a score and a memory's text on one line of width runes.
+func row(score, text string, width int) string {
+	return clip(score+"  "+strings.Join(strings.Fields(text), " "), width)
+}
+
+// clip shortens s to width runes, ending it with an ellipsis when cut.
+func clip(s string, width int) string {
+	if r := []rune(s); width > 0 && len(r) > width {
+		return string(r[:width-1]) + termcap.Glyph("…", "~")
+	}
+	return s
+}
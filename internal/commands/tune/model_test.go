@@ -0,0 +1,126 @@
+package tune
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/retrieval"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// fakeTuner returns fixed candidates and keeps the vector results at or above
+// the tuned similarity when fusing.
+type fakeTuner struct {
+	searches    []string
+	ftsSearches []string
+}
+
+func (f *fakeTuner) Candidates(ctx context.Context, query string) (*retrieval.Candidates, error) {
+	f.searches = append(f.searches, query)
+	return &retrieval.Candidates{
+		Query: query,
+		Vector: []memtypes.SearchResult{
+			{Item: memtypes.MemoryItem{ID: "a", Text: "staging runs on port 8443"}, Similarity: 0.72},
+			{Item: memtypes.MemoryItem{ID: "b", Text: "the staging database is postgres"}, Similarity: 0.32},
+		},
+		FTSErr: errors.New("no such table"),
+	}, nil
+}
+
+func (f *fakeTuner) SearchFTS(ctx context.Context, memory utils.MemoryConfig, c *retrieval.Candidates) {
+	f.ftsSearches = append(f.ftsSearches, memory.FTSStrategy)
+	c.FTS = []memtypes.MemoryFTSResult{{Item: memtypes.MemoryItem{ID: "b", Text: "the staging database is postgres"}, Rank: -2}}
+	c.FTSErr = nil
+}
+
+func (f *fakeTuner) Fuse(memory utils.MemoryConfig, c *retrieval.Candidates) []memtypes.UnifiedResult {
+	var results []memtypes.UnifiedResult
+	for _, res := range c.Vector {
+		if res.Similarity >= memory.MinSimilarity {
+			results = append(results, memtypes.UnifiedResult{Item: res.Item, Score: res.Similarity, Source: "vector"})
+		}
+	}
+	return results
+}
+
+func (f *fakeTuner) Close() error { return nil }
+
+// press sends key to m and, unless it was typed into the query, runs the
+// command it returns and feeds its message back, as the program would.
+func press(t *testing.T, m Model, key string) Model {
+	t.Helper()
+	msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	if key == "enter" {
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+	}
+	typed := m.editing && key != "enter"
+	next, cmd := m.Update(msg)
+	m = next.(Model)
+	if cmd != nil && !typed {
+		switch out := cmd().(type) {
+		case searchedMsg, ftsSearchedMsg, savedMsg:
+			next, _ = m.Update(out)
+			m = next.(Model)
+		}
+	}
+	return m
+}
+
+func TestTuneRerankAndSave(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	config := utils.DefaultConfig()
+	fake := &fakeTuner{}
+	m := initialModel(config, fake, "")
+
+	for _, r := range "staging" {
+		m = press(t, m, string(r))
+	}
+	m = press(t, m, "enter")
+	if len(fake.searches) != 1 || fake.searches[0] != "staging" {
+		t.Fatalf("expected one search for the query, got %v", fake.searches)
+	}
+	if len(m.fused) != 1 || !strings.Contains(m.View(), "no such table") {
+		t.Fatalf("expected the match above 0.40 alone and the FTS error, got %+v\n%s", m.fused, m.View())
+	}
+
+	for range 2 {
+		m = press(t, m, "[")
+	}
+	if m.memory.MinSimilarity != 0.30 || len(m.fused) != 2 || len(fake.searches) != 1 {
+		t.Fatalf("expected lowering min_similarity to re-rank without searching, got %.2f, %d results, %d searches", m.memory.MinSimilarity, len(m.fused), len(fake.searches))
+	}
+	m = press(t, m, "+")
+	m = press(t, m, "s")
+	if m.memory.FusionVectorWeight != 0.65 || m.memory.FTSStrategy != utils.FTSStrategyDirect {
+		t.Fatalf("unexpected settings %+v", m.memory)
+	}
+	if len(fake.ftsSearches) != 1 || fake.ftsSearches[0] != utils.FTSStrategyDirect || len(m.candidates.FTS) != 1 {
+		t.Fatalf("expected switching strategy to search FTS again, got %v", fake.ftsSearches)
+	}
+	if !m.dirty() || !strings.Contains(m.View(), "unsaved") {
+		t.Fatal("expected the changed settings to be marked unsaved")
+	}
+
+	m = press(t, m, "w")
+	if m.err != nil || m.dirty() {
+		t.Fatalf("expected the settings saved, got %v", m.err)
+	}
+	saved, err := utils.LoadConfig()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if saved.Memory.MinSimilarity != 0.30 || saved.Memory.FusionVectorWeight != 0.65 || saved.Memory.FTSStrategy != utils.FTSStrategyDirect {
+		t.Fatalf("unexpected saved settings %+v", saved.Memory)
+	}
+
+	m = press(t, m, "]")
+	m = press(t, m, "r")
+	if m.dirty() {
+		t.Fatal("expected reset to go back to the saved settings")
+	}
+}
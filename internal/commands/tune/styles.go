@@ -0,0 +1,25 @@
+package tune
+
+import "github.com/charmbracelet/lipgloss"
+
+var (
+	TitleStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("205")).
+			MarginBottom(1)
+
+	ErrorStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("196")).
+			Bold(true)
+
+	HelpStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("241"))
+
+	InputLabelStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("205")).
+			Bold(true)
+
+	// DimStyle marks vector results below min_similarity, which retrieval drops.
+	DimStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("241"))
+)
@@ -5,4 +5,7 @@ const (
 	ProviderGoogle     = "google"
 	ProviderAnthropic  = "anthropic"
 	ProviderOpenRouter = "openrouter"
+	ProviderOllama     = "ollama" // models served by a local Ollama server
+	ProviderLocal      = "local"  // in-process embeddings, no API key or network
+	ProviderMock       = "mock"   // canned completions and hash embeddings for demos
 )
@@ -0,0 +1,46 @@
+// Package exitcode maps the errors commands return to gomor's exit codes, so
+// shell scripts can branch on the outcome of a command.
+package exitcode
+
+import (
+	"context"
+	"errors"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// Exit codes. Any error not covered by the others exits with Failure.
+const (
+	OK       = 0
+	Failure  = 1
+	Config   = 2 // the config is missing or invalid, e.g. no model or API key
+	Provider = 3 // the provider failed or refused the request
+	Partial  = 4 // results were printed, but a retrieval path failed
+	// Interrupted follows the shell's 128+SIGINT: Ctrl-C stopped the command.
+	Interrupted = 130
+)
+
+// ErrPartial is returned by commands that printed their results although a
+// retrieval path failed, so the results may be incomplete. The warnings
+// explaining why were printed with the results.
+var ErrPartial = errors.New("retrieval was incomplete; results may be missing")
+
+// For returns the exit code for err.
+func For(err error) int {
+	var providerErr *client.ProviderError
+	switch {
+	case err == nil:
+		return OK
+	case errors.Is(err, context.Canceled):
+		return Interrupted
+	case errors.Is(err, ErrPartial):
+		return Partial
+	case errors.Is(err, utils.ErrConfig):
+		return Config
+	case errors.As(err, &providerErr):
+		return Provider
+	default:
+		return Failure
+	}
+}
@@ -0,0 +1,33 @@
+package exitcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+func TestFor(t *testing.T) {
+	providerErr := client.WrapError("openai", 401, errors.New("bad key"))
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, OK},
+		{"other", errors.New("boom"), Failure},
+		{"config", utils.ConfigErrorf("chat model not configured"), Config},
+		{"wrapped config", fmt.Errorf("failed to create chat client: %w", utils.ConfigErrorf("no key")), Config},
+		{"provider", fmt.Errorf("chat failed: %w", providerErr), Provider},
+		{"partial", ErrPartial, Partial},
+		{"interrupted", fmt.Errorf("chat stream failed: %w", context.Canceled), Interrupted},
+	}
+	for _, tt := range tests {
+		if got := For(tt.err); got != tt.want {
+			t.Errorf("%s: For = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+// Package hooks runs the shell commands configured under "hooks" around each
+// query, so users can log prompts and answers to their own systems, rewrite
+// prompts, or send notifications.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/tokenizer"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// Hook names, also passed to each hook as GOMOR_HOOK.
+const (
+	PreQuery     = "pre_query"
+	PostResponse = "post_response"
+)
+
+// defaultTimeout bounds a hook run when the config sets none.
+const defaultTimeout = 30 * time.Second
+
+// ErrCancelled is returned when the pre_query hook exits non-zero.
+var ErrCancelled = errors.New("query cancelled by the pre_query hook")
+
+// Query is the event the pre_query hook reads on stdin.
+type Query struct {
+	Hook      string `json:"hook"`
+	Command   string `json:"command"` // the gomor command asking: "query", "chat", or "retry"
+	SessionID string `json:"session_id,omitempty"`
+	Model     string `json:"model"` // provider/model
+	Prompt    string `json:"prompt"`
+}
+
+// Rewrite is what the pre_query hook may write on stdout to replace the
+// prompt. Empty output keeps the prompt.
+type Rewrite struct {
+	Prompt string `json:"prompt"`
+}
+
+// Response is the event the post_response hook reads on stdin.
+type Response struct {
+	Hook         string `json:"hook"`
+	Command      string `json:"command"`
+	SessionID    string `json:"session_id,omitempty"`
+	Model        string `json:"model"`
+	Prompt       string `json:"prompt"`
+	Response     string `json:"response"`
+	InputTokens  int    `json:"input_tokens"` // estimated
+	OutputTokens int    `json:"output_tokens"`
+	DurationMs   int64  `json:"duration_ms"`
+}
+
+// Runner runs the configured hooks. A nil Runner runs none.
+type Runner struct {
+	config utils.HooksConfig
+}
+
+// New returns a runner for config, or nil when no hook is configured.
+func New(config utils.HooksConfig) *Runner {
+	if config.PreQuery == "" && config.PostResponse == "" {
+		return nil
+	}
+	return &Runner{config: config}
+}
+
+// ModelName names model as the hooks see it.
+func ModelName(model types.Model) string {
+	return model.Provider + "/" + model.ModelID
+}
+
+// PreQuery runs the pre_query hook on q and returns the prompt to send: the
+// hook's rewrite, or q.Prompt when it writes nothing. A non-zero exit cancels
+// the query with ErrCancelled and the hook's stderr.
+func (r *Runner) PreQuery(ctx context.Context, q Query) (string, error) {
+	if r == nil || r.config.PreQuery == "" {
+		return q.Prompt, nil
+	}
+	q.Hook = PreQuery
+	out, err := r.run(ctx, PreQuery, r.config.PreQuery, q)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if msg := strings.TrimSpace(string(exitErr.Stderr)); msg != "" {
+			return "", fmt.Errorf("%w: %s", ErrCancelled, msg)
+		}
+		return "", ErrCancelled
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if len(bytes.TrimSpace(out)) == 0 {
+		return q.Prompt, nil
+	}
+	var rewrite Rewrite
+	if err := json.Unmarshal(out, &rewrite); err != nil {
+		return "", fmt.Errorf(`pre_query hook must write nothing or {"prompt": "..."}: %w`, err)
+	}
+	if strings.TrimSpace(rewrite.Prompt) == "" {
+		return "", fmt.Errorf("pre_query hook rewrote the prompt to an empty one")
+	}
+	return rewrite.Prompt, nil
+}
+
+// PostResponse runs the post_response hook on resp. The answer has already
+// been shown, so callers report its error as a warning.
+func (r *Runner) PostResponse(ctx context.Context, resp Response) error {
+	if r == nil || r.config.PostResponse == "" {
+		return nil
+	}
+	resp.Hook = PostResponse
+	_, err := r.run(ctx, PostResponse, r.config.PostResponse, resp)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if msg := strings.TrimSpace(string(exitErr.Stderr)); msg != "" {
+			return fmt.Errorf("post_response hook failed: %w: %s", err, msg)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("post_response hook failed: %w", err)
+	}
+	return nil
+}
+
+// NewResponse fills in a post_response event for an answer to prompt that
+// took the time since start.
+func NewResponse(command, sessionID string, model types.Model, prompt, answer string, usage tokenizer.Usage, start time.Time) Response {
+	return Response{
+		Command:      command,
+		SessionID:    sessionID,
+		Model:        ModelName(model),
+		Prompt:       prompt,
+		Response:     answer,
+		InputTokens:  usage.InputTokens,
+		OutputTokens: usage.OutputTokens,
+		DurationMs:   time.Since(start).Milliseconds(),
+	}
+}
+
+// run runs command in the shell with event as JSON on stdin and returns its
+// stdout.
+func (r *Runner) run(ctx context.Context, hook, command string, event any) ([]byte, error) {
+	input, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := defaultTimeout
+	if r.config.TimeoutSeconds > 0 {
+		timeout = time.Duration(r.config.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := shell(ctx, command)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = append(os.Environ(), "GOMOR_HOOK="+hook)
+	// Don't wait on children the hook left holding its output after a timeout.
+	cmd.WaitDelay = time.Second
+	out, err := cmd.Output()
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return nil, fmt.Errorf("%s hook timed out after %s", hook, timeout)
+	case context.Canceled:
+		return nil, ctx.Err()
+	}
+	return out, err
+}
+
+func shell(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
@@ -0,0 +1,124 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/tokenizer"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+func skipWithoutShell(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands in these tests need a POSIX shell")
+	}
+}
+
+func TestNilRunnerRunsNothing(t *testing.T) {
+	runner := New(utils.HooksConfig{})
+	if runner != nil {
+		t.Fatal("New should return nil without hooks")
+	}
+
+	prompt, err := runner.PreQuery(context.Background(), Query{Prompt: "hi"})
+	if err != nil || prompt != "hi" {
+		t.Fatalf("PreQuery = %q, %v", prompt, err)
+	}
+	if err := runner.PostResponse(context.Background(), Response{}); err != nil {
+		t.Fatalf("PostResponse: %v", err)
+	}
+}
+
+func TestPreQueryRewritesPrompt(t *testing.T) {
+	skipWithoutShell(t)
+	runner := New(utils.HooksConfig{PreQuery: `test "$GOMOR_HOOK" = pre_query && grep -q '"prompt":"hi"' && echo '{"prompt": "hi, briefly"}'`})
+
+	prompt, err := runner.PreQuery(context.Background(), Query{Command: "query", Model: "openai/gpt-4o", Prompt: "hi"})
+	if err != nil {
+		t.Fatalf("PreQuery: %v", err)
+	}
+	if prompt != "hi, briefly" {
+		t.Fatalf("prompt = %q", prompt)
+	}
+}
+
+func TestPreQueryWithoutOutputKeepsPrompt(t *testing.T) {
+	skipWithoutShell(t)
+	runner := New(utils.HooksConfig{PreQuery: "cat > /dev/null"})
+
+	prompt, err := runner.PreQuery(context.Background(), Query{Prompt: "hi"})
+	if err != nil || prompt != "hi" {
+		t.Fatalf("PreQuery = %q, %v", prompt, err)
+	}
+}
+
+func TestPreQueryExitCancelsQuery(t *testing.T) {
+	skipWithoutShell(t)
+	runner := New(utils.HooksConfig{PreQuery: "echo 'no secrets, please' >&2; exit 1"})
+
+	_, err := runner.PreQuery(context.Background(), Query{Prompt: "my password is hunter2"})
+	if !errors.Is(err, ErrCancelled) {
+		t.Fatalf("expected ErrCancelled, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "no secrets, please") {
+		t.Fatalf("error %q should carry the hook's stderr", err)
+	}
+}
+
+func TestPreQueryRejectsInvalidOutput(t *testing.T) {
+	skipWithoutShell(t)
+	for _, command := range []string{"echo not json", `echo '{"prompt": " "}'`} {
+		runner := New(utils.HooksConfig{PreQuery: command})
+		if _, err := runner.PreQuery(context.Background(), Query{Prompt: "hi"}); err == nil {
+			t.Fatalf("expected an error for %q", command)
+		}
+	}
+}
+
+func TestPostResponseReceivesEvent(t *testing.T) {
+	skipWithoutShell(t)
+	path := filepath.Join(t.TempDir(), "event.json")
+	runner := New(utils.HooksConfig{PostResponse: "cat > " + path})
+
+	model := types.Model{Provider: "openai", ModelID: "gpt-4o"}
+	event := NewResponse("chat", "s1", model, "hi", "hello", tokenizer.Usage{InputTokens: 3, OutputTokens: 2}, time.Now())
+	if err := runner.PostResponse(context.Background(), event); err != nil {
+		t.Fatalf("PostResponse: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read event: %v", err)
+	}
+	var got Response
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("decode event: %v", err)
+	}
+	if got.Hook != PostResponse || got.Command != "chat" || got.SessionID != "s1" || got.Model != "openai/gpt-4o" ||
+		got.Prompt != "hi" || got.Response != "hello" || got.InputTokens != 3 || got.OutputTokens != 2 {
+		t.Fatalf("unexpected event %+v", got)
+	}
+}
+
+func TestPostResponseReportsFailureAndTimeout(t *testing.T) {
+	skipWithoutShell(t)
+	runner := New(utils.HooksConfig{PostResponse: "echo 'webhook down' >&2; exit 2"})
+	if err := runner.PostResponse(context.Background(), Response{}); err == nil || !strings.Contains(err.Error(), "webhook down") {
+		t.Fatalf("expected the hook's stderr in the error, got %v", err)
+	}
+
+	runner = New(utils.HooksConfig{PostResponse: "sleep 5", TimeoutSeconds: 1})
+	err := runner.PostResponse(context.Background(), Response{})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+// Package interop converts memories to and from the JSON formats used by other
+// agent memory systems (mem0, Letta). Converted items carry no embeddings;
+// callers re-embed them with the configured model on import.
+package interop
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Metadata keys gomor uses to round-trip its own fields through other formats.
+const (
+	metaKind   = "gomor_kind"
+	metaSource = "gomor_source"
+	metaID     = "gomor_id"
+)
+
+// parseTime accepts RFC 3339 timestamps with or without a zone, which is what
+// both mem0 and Letta emit.
+func parseTime(s string) time.Time {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999", "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// flattenMetadata converts arbitrary JSON metadata into string values, encoding
+// non-string values as JSON so they survive a round trip.
+func flattenMetadata(dst map[string]string, src map[string]any) {
+	for k, v := range src {
+		switch val := v.(type) {
+		case nil:
+			continue
+		case string:
+			dst[k] = val
+		default:
+			data, err := json.Marshal(val)
+			if err != nil {
+				continue
+			}
+			dst[k] = string(data)
+		}
+	}
+}
+
+// expandMetadata reverses flattenMetadata, decoding values that hold JSON.
+func expandMetadata(src map[string]string, skip ...string) map[string]any {
+	out := make(map[string]any)
+	for k, v := range src {
+		if contains(skip, k) {
+			continue
+		}
+		var decoded any
+		trimmed := strings.TrimSpace(v)
+		if trimmed != "" && strings.ContainsAny(trimmed[:1], "{[0123456789-tfn") && json.Unmarshal([]byte(trimmed), &decoded) == nil {
+			out[k] = decoded
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+func marshal(value any) ([]byte, error) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode export: %w", err)
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,150 @@
+package interop
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+func TestFromMem0MapsFieldsAndMetadata(t *testing.T) {
+	data := []byte(`{"results": [
+		{"id": "m-1", "memory": "Prefers dark mode", "hash": "h1",
+		 "metadata": {"app": "editor", "priority": 2}, "categories": ["preferences"],
+		 "created_at": "2024-07-20T10:00:00-07:00", "updated_at": "2024-07-21T10:00:00-07:00",
+		 "user_id": "alice"},
+		{"id": "m-2", "memory": "   "}
+	]}`)
+
+	items, err := FromMem0(data)
+	if err != nil {
+		t.Fatalf("from mem0: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(items))
+	}
+	item := items[0]
+	if item.Text != "Prefers dark mode" || item.Source != memtypes.SourceImported || item.Kind != memtypes.KindFact {
+		t.Fatalf("unexpected item: %+v", item)
+	}
+	if len(item.Tags) != 1 || item.Tags[0] != "preferences" {
+		t.Fatalf("expected categories as tags, got %v", item.Tags)
+	}
+	if !item.CreatedAt.Equal(time.Date(2024, 7, 20, 17, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected created_at: %v", item.CreatedAt)
+	}
+	want := map[string]string{
+		"mem0_id": "m-1", "mem0_hash": "h1", "mem0_updated_at": "2024-07-21T10:00:00-07:00",
+		"user_id": "alice", "app": "editor", "priority": "2",
+	}
+	for k, v := range want {
+		if item.Metadata[k] != v {
+			t.Fatalf("metadata %q = %q, want %q (all: %v)", k, item.Metadata[k], v, item.Metadata)
+		}
+	}
+}
+
+func TestMem0RoundTrip(t *testing.T) {
+	original := []memtypes.MemoryItem{{
+		ID:        "g-1",
+		Text:      "Uses Go for backend work",
+		Tags:      []string{"work"},
+		Source:    memtypes.SourceImported,
+		Kind:      memtypes.KindPreference,
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Metadata:  map[string]string{"mem0_id": "m-9", "user_id": "bob", "scores": `[1,2]`},
+	}}
+
+	data, err := ToMem0(original)
+	if err != nil {
+		t.Fatalf("to mem0: %v", err)
+	}
+
+	var raw mem0Export
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("decode export: %v", err)
+	}
+	if raw.Results[0].ID != "m-9" || raw.Results[0].UserID != "bob" || raw.Results[0].Hash == "" {
+		t.Fatalf("unexpected export: %+v", raw.Results[0])
+	}
+	if _, ok := raw.Results[0].Metadata["scores"].([]any); !ok {
+		t.Fatalf("expected JSON metadata to be decoded, got %T", raw.Results[0].Metadata["scores"])
+	}
+
+	items, err := FromMem0(data)
+	if err != nil {
+		t.Fatalf("from mem0: %v", err)
+	}
+	got := items[0]
+	if got.Text != original[0].Text || got.Kind != memtypes.KindPreference || !got.CreatedAt.Equal(original[0].CreatedAt) {
+		t.Fatalf("round trip lost fields: %+v", got)
+	}
+	if got.Metadata["mem0_id"] != "m-9" || got.Metadata["user_id"] != "bob" || got.Metadata["scores"] != "[1,2]" {
+		t.Fatalf("round trip lost metadata: %v", got.Metadata)
+	}
+	if _, ok := got.Metadata[metaKind]; ok {
+		t.Fatalf("gomor keys should not leak into metadata: %v", got.Metadata)
+	}
+}
+
+func TestFromLettaAgentFile(t *testing.T) {
+	data := []byte(`{"agents": [{
+		"id": "agent-1", "name": "helper",
+		"memory": {"blocks": [
+			{"label": "human", "value": "Name is Sam; works on compilers"},
+			{"label": "persona", "value": "Be concise"}
+		]},
+		"passages": [{"id": "p-1", "text": "Sam's build uses Bazel", "created_at": "2024-05-01T00:00:00Z", "tags": ["build"]}]
+	}]}`)
+
+	items, err := FromLetta(data)
+	if err != nil {
+		t.Fatalf("from letta: %v", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("expected 3 items, got %d: %+v", len(items), items)
+	}
+	if items[0].Kind != memtypes.KindFact || items[0].Metadata["letta_block"] != "human" {
+		t.Fatalf("unexpected human block: %+v", items[0])
+	}
+	if items[1].Kind != memtypes.KindPreference || items[1].Metadata["letta_block"] != "persona" {
+		t.Fatalf("unexpected persona block: %+v", items[1])
+	}
+	passage := items[2]
+	if passage.Metadata["letta_id"] != "p-1" || passage.Metadata["letta_agent_id"] != "agent-1" || passage.Metadata["letta_agent"] != "helper" {
+		t.Fatalf("unexpected passage metadata: %v", passage.Metadata)
+	}
+	if len(passage.Tags) != 1 || passage.Tags[0] != "build" || passage.CreatedAt.IsZero() {
+		t.Fatalf("unexpected passage: %+v", passage)
+	}
+}
+
+func TestLettaPassagesRoundTrip(t *testing.T) {
+	original := []memtypes.MemoryItem{{
+		ID:        "g-2",
+		Text:      "Deploys on Fridays are frozen",
+		Source:    memtypes.SourceExplicit,
+		Kind:      memtypes.KindFact,
+		CreatedAt: time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC),
+		Metadata:  map[string]string{"letta_id": "p-7"},
+	}}
+
+	data, err := ToLetta(original)
+	if err != nil {
+		t.Fatalf("to letta: %v", err)
+	}
+	items, err := FromLetta(data)
+	if err != nil {
+		t.Fatalf("from letta: %v", err)
+	}
+	if len(items) != 1 || items[0].Text != original[0].Text || items[0].Metadata["letta_id"] != "p-7" {
+		t.Fatalf("unexpected round trip: %+v", items)
+	}
+}
+
+func TestFromLettaRejectsUnknownShape(t *testing.T) {
+	if _, err := FromLetta([]byte(`{"foo": 1}`)); err == nil {
+		t.Fatal("expected error for unrecognized export")
+	}
+}
@@ -0,0 +1,169 @@
+package interop
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+// lettaPassage mirrors an archival memory passage in Letta's API and agent files.
+type lettaPassage struct {
+	ID        string         `json:"id,omitempty"`
+	Text      string         `json:"text"`
+	Tags      []string       `json:"tags,omitempty"`
+	CreatedAt string         `json:"created_at,omitempty"`
+	AgentID   string         `json:"agent_id,omitempty"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+}
+
+// lettaBlock mirrors a core memory block ("human", "persona", ...).
+type lettaBlock struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// lettaAgent holds the parts of a Letta agent file (.af) that map to memories.
+type lettaAgent struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Memory *struct {
+		Blocks []lettaBlock `json:"blocks"`
+	} `json:"memory,omitempty"`
+	CoreMemory []lettaBlock   `json:"core_memory,omitempty"`
+	Passages   []lettaPassage `json:"passages,omitempty"`
+	Archival   []lettaPassage `json:"archival_memory,omitempty"`
+}
+
+// Keys under which Letta fields without a gomor equivalent are kept in MemoryItem.Metadata.
+const (
+	lettaMetaID      = "letta_id"
+	lettaMetaAgentID = "letta_agent_id"
+	lettaMetaAgent   = "letta_agent"
+	lettaMetaBlock   = "letta_block"
+)
+
+// FromLetta parses Letta archival passages, given either as a bare list of
+// passages or as an agent file ({"agents": [...]}). Core memory blocks in agent
+// files are imported too: the "human" block as a fact, other blocks as preferences.
+func FromLetta(data []byte) ([]memtypes.MemoryItem, error) {
+	var passages []lettaPassage
+	if err := json.Unmarshal(data, &passages); err == nil {
+		return lettaPassageItems(passages, nil), nil
+	}
+
+	var file struct {
+		Agents []lettaAgent `json:"agents"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse Letta export: %w", err)
+	}
+	if len(file.Agents) == 0 {
+		return nil, fmt.Errorf("unrecognized Letta export: expected a list of passages or an agent file")
+	}
+
+	var items []memtypes.MemoryItem
+	for _, agent := range file.Agents {
+		agentMeta := map[string]string{}
+		if agent.ID != "" {
+			agentMeta[lettaMetaAgentID] = agent.ID
+		}
+		if agent.Name != "" {
+			agentMeta[lettaMetaAgent] = agent.Name
+		}
+
+		blocks := agent.CoreMemory
+		if agent.Memory != nil {
+			blocks = append(blocks, agent.Memory.Blocks...)
+		}
+		for _, block := range blocks {
+			text := strings.TrimSpace(block.Value)
+			if text == "" {
+				continue
+			}
+			kind := memtypes.KindPreference
+			if block.Label == "human" {
+				kind = memtypes.KindFact
+			}
+			metadata := copyMeta(agentMeta)
+			metadata[lettaMetaBlock] = block.Label
+			items = append(items, memtypes.MemoryItem{
+				Text:     text,
+				Tags:     []string{"letta"},
+				Source:   memtypes.SourceImported,
+				Kind:     kind,
+				Metadata: metadata,
+			})
+		}
+
+		items = append(items, lettaPassageItems(append(agent.Passages, agent.Archival...), agentMeta)...)
+	}
+	return items, nil
+}
+
+func lettaPassageItems(passages []lettaPassage, agentMeta map[string]string) []memtypes.MemoryItem {
+	var items []memtypes.MemoryItem
+	for _, p := range passages {
+		text := strings.TrimSpace(p.Text)
+		if text == "" {
+			continue
+		}
+
+		metadata := copyMeta(agentMeta)
+		flattenMetadata(metadata, p.Metadata)
+		if p.ID != "" {
+			metadata[lettaMetaID] = p.ID
+		}
+		if p.AgentID != "" {
+			metadata[lettaMetaAgentID] = p.AgentID
+		}
+
+		item := memtypes.MemoryItem{
+			Text:      text,
+			Tags:      append([]string(nil), p.Tags...),
+			Source:    memtypes.SourceImported,
+			Kind:      memtypes.KindFact,
+			CreatedAt: parseTime(p.CreatedAt),
+		}
+		restoreGomorFields(&item, metadata)
+		if len(metadata) > 0 {
+			item.Metadata = metadata
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// ToLetta encodes memories as a list of Letta archival passages, the shape
+// accepted by Letta's passage API. gomor's kind and source travel in metadata.
+func ToLetta(items []memtypes.MemoryItem) ([]byte, error) {
+	reserved := []string{lettaMetaID, lettaMetaAgentID}
+
+	passages := make([]lettaPassage, 0, len(items))
+	for _, item := range items {
+		metadata := expandMetadata(item.Metadata, reserved...)
+		metadata[metaKind] = string(item.Kind)
+		metadata[metaSource] = string(item.Source)
+		metadata[metaID] = item.ID
+
+		passages = append(passages, lettaPassage{
+			ID:        item.Metadata[lettaMetaID],
+			Text:      item.Text,
+			Tags:      item.Tags,
+			CreatedAt: item.CreatedAt.UTC().Format(time.RFC3339),
+			AgentID:   item.Metadata[lettaMetaAgentID],
+			Metadata:  metadata,
+		})
+	}
+	return marshal(passages)
+}
+
+func copyMeta(src map[string]string) map[string]string {
+	dst := make(map[string]string, len(src)+1)
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
@@ -0,0 +1,135 @@
+package interop
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+// mem0Memory mirrors an entry of mem0's get_all/search output.
+type mem0Memory struct {
+	ID         string         `json:"id"`
+	Memory     string         `json:"memory"`
+	Hash       string         `json:"hash,omitempty"`
+	Metadata   map[string]any `json:"metadata,omitempty"`
+	Categories []string       `json:"categories,omitempty"`
+	CreatedAt  string         `json:"created_at,omitempty"`
+	UpdatedAt  string         `json:"updated_at,omitempty"`
+	UserID     string         `json:"user_id,omitempty"`
+	AgentID    string         `json:"agent_id,omitempty"`
+	RunID      string         `json:"run_id,omitempty"`
+}
+
+// mem0Export is the {"results": [...]} envelope returned by mem0's get_all.
+type mem0Export struct {
+	Results []mem0Memory `json:"results"`
+}
+
+// Keys under which mem0 fields without a gomor equivalent are kept in MemoryItem.Metadata.
+const (
+	mem0MetaID        = "mem0_id"
+	mem0MetaHash      = "mem0_hash"
+	mem0MetaUpdatedAt = "mem0_updated_at"
+	mem0MetaUserID    = "user_id"
+	mem0MetaAgentID   = "agent_id"
+	mem0MetaRunID     = "run_id"
+)
+
+// FromMem0 parses mem0 memories, either a bare list or the {"results": [...]} envelope.
+// Categories become tags; ids, hashes, scoping ids, and metadata are kept in Metadata.
+func FromMem0(data []byte) ([]memtypes.MemoryItem, error) {
+	var memories []mem0Memory
+	if err := json.Unmarshal(data, &memories); err != nil {
+		var envelope mem0Export
+		if err := json.Unmarshal(data, &envelope); err != nil {
+			return nil, fmt.Errorf("failed to parse mem0 export: %w", err)
+		}
+		memories = envelope.Results
+	}
+
+	var items []memtypes.MemoryItem
+	for _, m := range memories {
+		text := strings.TrimSpace(m.Memory)
+		if text == "" {
+			continue
+		}
+
+		metadata := make(map[string]string)
+		flattenMetadata(metadata, m.Metadata)
+		for k, v := range map[string]string{
+			mem0MetaID:        m.ID,
+			mem0MetaHash:      m.Hash,
+			mem0MetaUpdatedAt: m.UpdatedAt,
+			mem0MetaUserID:    m.UserID,
+			mem0MetaAgentID:   m.AgentID,
+			mem0MetaRunID:     m.RunID,
+		} {
+			if v != "" {
+				metadata[k] = v
+			}
+		}
+
+		item := memtypes.MemoryItem{
+			Text:      text,
+			Tags:      append([]string(nil), m.Categories...),
+			Source:    memtypes.SourceImported,
+			Kind:      memtypes.KindFact,
+			CreatedAt: parseTime(m.CreatedAt),
+		}
+		restoreGomorFields(&item, metadata)
+		if len(metadata) > 0 {
+			item.Metadata = metadata
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// ToMem0 encodes memories in mem0's get_all format so they can be loaded back
+// with mem0's client. gomor's kind and source travel in metadata.
+func ToMem0(items []memtypes.MemoryItem) ([]byte, error) {
+	reserved := []string{mem0MetaID, mem0MetaHash, mem0MetaUpdatedAt, mem0MetaUserID, mem0MetaAgentID, mem0MetaRunID}
+
+	export := mem0Export{Results: make([]mem0Memory, 0, len(items))}
+	for _, item := range items {
+		id := item.Metadata[mem0MetaID]
+		if id == "" {
+			id = item.ID
+		}
+		hash := md5.Sum([]byte(item.Text))
+
+		metadata := expandMetadata(item.Metadata, reserved...)
+		metadata[metaKind] = string(item.Kind)
+		metadata[metaSource] = string(item.Source)
+		metadata[metaID] = item.ID
+
+		export.Results = append(export.Results, mem0Memory{
+			ID:         id,
+			Memory:     item.Text,
+			Hash:       hex.EncodeToString(hash[:]),
+			Metadata:   metadata,
+			Categories: item.Tags,
+			CreatedAt:  item.CreatedAt.UTC().Format(time.RFC3339),
+			UpdatedAt:  item.Metadata[mem0MetaUpdatedAt],
+			UserID:     item.Metadata[mem0MetaUserID],
+			AgentID:    item.Metadata[mem0MetaAgentID],
+			RunID:      item.Metadata[mem0MetaRunID],
+		})
+	}
+	return marshal(export)
+}
+
+// restoreGomorFields applies and removes gomor's own round-trip keys.
+func restoreGomorFields(item *memtypes.MemoryItem, metadata map[string]string) {
+	if kind, err := memtypes.ParseMemoryKind(metadata[metaKind]); err == nil {
+		item.Kind = kind
+	}
+	delete(metadata, metaKind)
+	delete(metadata, metaSource)
+	delete(metadata, metaID)
+}
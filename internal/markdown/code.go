@@ -0,0 +1,40 @@
+package markdown
+
+import "strings"
+
+// CodeBlock is a fenced code block found in markdown text.
+type CodeBlock struct {
+	Lang string
+	Code string
+}
+
+// CodeBlocks returns the fenced code blocks in text, in order. An unterminated
+// final fence (a truncated answer) still yields its block.
+func CodeBlocks(text string) []CodeBlock {
+	var blocks []CodeBlock
+	var current *CodeBlock
+	var fence string
+	var lines []string
+
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		m := fenceRe.FindStringSubmatch(line)
+		switch {
+		case current == nil && m != nil:
+			current = &CodeBlock{Lang: m[2]}
+			fence = m[1]
+			lines = nil
+		case current != nil && m != nil && strings.HasPrefix(m[1], fence) && m[2] == "":
+			current.Code = strings.Join(lines, "\n")
+			blocks = append(blocks, *current)
+			current = nil
+		case current != nil:
+			lines = append(lines, line)
+		}
+	}
+	if current != nil {
+		current.Code = strings.Join(lines, "\n")
+		blocks = append(blocks, *current)
+	}
+
+	return blocks
+}
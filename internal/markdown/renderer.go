@@ -0,0 +1,180 @@
+// Package markdown renders streamed model output for the terminal: headings,
+// lists, quotes, code fences, and inline emphasis are styled instead of being
+// printed as raw markdown.
+package markdown
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/termcap"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	headingRe = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	bulletRe  = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	orderedRe = regexp.MustCompile(`^(\s*)(\d+[.)])\s+(.*)$`)
+	quoteRe   = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	ruleRe    = regexp.MustCompile(`^\s*([-*_])(\s*[-*_]){2,}\s*$`)
+	fenceRe   = regexp.MustCompile("^\\s*(```+|~~~+)\\s*([\\w+#.-]*)")
+
+	codeSpanRe = regexp.MustCompile("`([^`]+)`")
+	boldRe     = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	italicRe   = regexp.MustCompile(`\*([^*\s](?:[^*]*[^*\s])?)\*`)
+	linkRe     = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+)
+
+type styles struct {
+	heading    lipgloss.Style
+	subheading lipgloss.Style
+	bullet     lipgloss.Style
+	quote      lipgloss.Style
+	rule       lipgloss.Style
+	code       lipgloss.Style
+	fenceLabel lipgloss.Style
+	bold       lipgloss.Style
+	italic     lipgloss.Style
+	link       lipgloss.Style
+}
+
+func newStyles(r *lipgloss.Renderer) styles {
+	return styles{
+		heading:    r.NewStyle().Bold(true).Underline(true).Foreground(lipgloss.Color("205")),
+		subheading: r.NewStyle().Bold(true).Foreground(lipgloss.Color("141")),
+		bullet:     r.NewStyle().Foreground(lipgloss.Color("39")),
+		quote:      r.NewStyle().Italic(true).Foreground(lipgloss.Color("245")),
+		rule:       r.NewStyle().Foreground(lipgloss.Color("241")),
+		code:       r.NewStyle().Foreground(lipgloss.Color("214")),
+		fenceLabel: r.NewStyle().Foreground(lipgloss.Color("241")),
+		bold:       r.NewStyle().Bold(true),
+		italic:     r.NewStyle().Italic(true),
+		link:       r.NewStyle().Underline(true).Foreground(lipgloss.Color("39")),
+	}
+}
+
+// Renderer is an io.Writer that renders markdown line by line as it streams
+// in. Partial lines are held until their newline arrives or Flush is called.
+// Colors follow the output's terminal profile, so non-terminal output is plain.
+type Renderer struct {
+	out     io.Writer
+	styles  styles
+	buf     []byte
+	inFence bool
+}
+
+// NewRenderer creates a renderer writing to out.
+func NewRenderer(out io.Writer) *Renderer {
+	return &Renderer{out: out, styles: newStyles(lipgloss.NewRenderer(out))}
+}
+
+// Write buffers p and renders every completed line.
+func (r *Renderer) Write(p []byte) (int, error) {
+	r.buf = append(r.buf, p...)
+	for {
+		i := bytes.IndexByte(r.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := string(r.buf[:i])
+		r.buf = r.buf[i+1:]
+		if err := r.emit(line, true); err != nil {
+			return len(p), err
+		}
+	}
+}
+
+// Flush renders a trailing partial line and resets fence state, ready for the
+// next answer.
+func (r *Renderer) Flush() error {
+	var err error
+	if len(r.buf) > 0 {
+		err = r.emit(string(r.buf), false)
+		r.buf = r.buf[:0]
+	}
+	r.inFence = false
+	return err
+}
+
+func (r *Renderer) emit(line string, newline bool) error {
+	rendered, keep := r.renderLine(strings.TrimSuffix(line, "\r"))
+	if !keep {
+		return nil
+	}
+	if newline {
+		rendered += "\n"
+	}
+	_, err := io.WriteString(r.out, rendered)
+	return err
+}
+
+// renderLine styles one line. It reports false for lines that are dropped,
+// such as the fences around a code block.
+func (r *Renderer) renderLine(line string) (string, bool) {
+	s := r.styles
+
+	if m := fenceRe.FindStringSubmatch(line); m != nil {
+		r.inFence = !r.inFence
+		if r.inFence && m[2] != "" {
+			return s.fenceLabel.Render("  " + m[2]), true
+		}
+		return "", false
+	}
+	if r.inFence {
+		return "  " + s.code.Render(line), true
+	}
+
+	if m := headingRe.FindStringSubmatch(line); m != nil {
+		if len(m[1]) <= 2 {
+			return s.heading.Render(m[2]), true
+		}
+		return s.subheading.Render(m[2]), true
+	}
+	if ruleRe.MatchString(line) {
+		return s.rule.Render(strings.Repeat(termcap.Glyph("─", "-"), 40)), true
+	}
+	if m := bulletRe.FindStringSubmatch(line); m != nil {
+		return m[1] + s.bullet.Render(termcap.Glyph("•", "*")) + " " + r.inline(m[2]), true
+	}
+	if m := orderedRe.FindStringSubmatch(line); m != nil {
+		return m[1] + s.bullet.Render(m[2]) + " " + r.inline(m[3]), true
+	}
+	if m := quoteRe.FindStringSubmatch(line); m != nil {
+		return s.rule.Render(termcap.Glyph("│ ", "| ")) + s.quote.Render(m[1]), true
+	}
+	return r.inline(line), true
+}
+
+// inline styles code spans, emphasis, and links. Text inside code spans is
+// left untouched.
+func (r *Renderer) inline(text string) string {
+	s := r.styles
+
+	var sb strings.Builder
+	last := 0
+	for _, loc := range codeSpanRe.FindAllStringSubmatchIndex(text, -1) {
+		sb.WriteString(r.emphasis(text[last:loc[0]]))
+		sb.WriteString(s.code.Render(text[loc[2]:loc[3]]))
+		last = loc[1]
+	}
+	sb.WriteString(r.emphasis(text[last:]))
+	return sb.String()
+}
+
+func (r *Renderer) emphasis(text string) string {
+	s := r.styles
+
+	text = linkRe.ReplaceAllStringFunc(text, func(m string) string {
+		parts := linkRe.FindStringSubmatch(m)
+		return s.link.Render(parts[1]) + " (" + parts[2] + ")"
+	})
+	text = boldRe.ReplaceAllStringFunc(text, func(m string) string {
+		parts := boldRe.FindStringSubmatch(m)
+		return s.bold.Render(parts[1] + parts[2])
+	})
+	return italicRe.ReplaceAllStringFunc(text, func(m string) string {
+		return s.italic.Render(italicRe.FindStringSubmatch(m)[1])
+	})
+}
@@ -0,0 +1,81 @@
+package markdown
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func render(t *testing.T, chunks ...string) string {
+	t.Helper()
+
+	var out bytes.Buffer
+	r := NewRenderer(&out)
+	for _, c := range chunks {
+		if _, err := io.WriteString(r, c); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := r.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	return out.String()
+}
+
+func TestRendererStripsMarkdownSyntax(t *testing.T) {
+	got := render(t,
+		"## Setup\n",
+		"1. Install **Go** and run `go *build*`\n",
+		"  * see [docs](https://go.dev)\n",
+		"> a *quoted* tip\n",
+		"---\n",
+	)
+	want := "Setup\n" +
+		"1. Install Go and run go *build*\n" +
+		"  • see docs (https://go.dev)\n" +
+		"│ a *quoted* tip\n" +
+		"────────────────────────────────────────\n"
+	if got != want {
+		t.Fatalf("got %q\nwant %q", got, want)
+	}
+}
+
+func TestRendererHandlesLinesSplitAcrossChunks(t *testing.T) {
+	got := render(t, "Use **bo", "ld** text\n```", "python\nprint('#", " not a heading')\n``", "`\ntail")
+	want := "Use bold text\n  python\n  print('# not a heading')\ntail"
+	if got != want {
+		t.Fatalf("got %q\nwant %q", got, want)
+	}
+}
+
+func TestFlushResetsOpenFence(t *testing.T) {
+	var out bytes.Buffer
+	r := NewRenderer(&out)
+	_, _ = io.WriteString(r, "```\nunterminated\n")
+	_ = r.Flush()
+	_, _ = io.WriteString(r, "# Next answer\n")
+
+	if got := out.String(); got != "  unterminated\nNext answer\n" {
+		t.Fatalf("unexpected output %q", got)
+	}
+}
+
+func TestCodeBlocks(t *testing.T) {
+	text := "intro\n```go\nfmt.Println(\"```\")\n```\ntext\n~~~\nplain\n~~~\n````md\n```inner\n````\n```sh\ntruncated"
+	blocks := CodeBlocks(text)
+
+	want := []CodeBlock{
+		{Lang: "go", Code: "fmt.Println(\"```\")"},
+		{Lang: "", Code: "plain"},
+		{Lang: "md", Code: "```inner"},
+		{Lang: "sh", Code: "truncated"},
+	}
+	if len(blocks) != len(want) {
+		t.Fatalf("expected %d blocks, got %+v", len(want), blocks)
+	}
+	for i := range want {
+		if blocks[i] != want[i] {
+			t.Fatalf("block %d = %+v, want %+v", i, blocks[i], want[i])
+		}
+	}
+}
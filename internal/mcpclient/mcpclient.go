@@ -0,0 +1,310 @@
+// Package mcpclient connects 'gomor chat' to the MCP servers in the config
+// and offers their tools to the chat model. Preferences the user states about
+// how tools are used are kept as memories and shown to the model in later
+// chats.
+package mcpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// PreferenceTag marks the memories that record a tool preference.
+const PreferenceTag = "tool-preference"
+
+// RememberToolName is the built-in tool the model calls to save a preference.
+const RememberToolName = "remember_tool_preference"
+
+const (
+	// maxToolName is the longest function name every provider accepts.
+	maxToolName = 64
+	// maxPreferences bounds how many tool preferences are shown to the model.
+	maxPreferences = 10
+	// maxResultChars bounds a tool result sent back to the model.
+	maxResultChars = 20000
+)
+
+// route is where a namespaced tool name is served.
+type route struct {
+	session *mcp.ClientSession
+	name    string // the server's name for the tool
+}
+
+// Toolset is the tools of the connected servers, named "<server>__<tool>".
+type Toolset struct {
+	sessions    []*mcp.ClientSession
+	tools       []client.Tool
+	routes      map[string]route
+	preferences []string
+	remember    func(ctx context.Context, text string) error
+}
+
+// Connect starts or connects to each server and lists its tools. A server that
+// fails is reported in the error without keeping the others from being used,
+// so the returned Toolset is valid either way and must be closed.
+func Connect(ctx context.Context, servers map[string]utils.MCPServerConfig) (*Toolset, error) {
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ts := &Toolset{routes: make(map[string]route)}
+	var errs []error
+	for _, name := range names {
+		transport, err := newTransport(servers[name])
+		if err == nil {
+			err = ts.add(ctx, name, transport)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("MCP server %s: %w", name, err))
+		}
+	}
+	return ts, errors.Join(errs...)
+}
+
+func newTransport(config utils.MCPServerConfig) (mcp.Transport, error) {
+	switch {
+	case config.Command != "":
+		cmd := exec.Command(config.Command, config.Args...)
+		if len(config.Env) > 0 {
+			cmd.Env = os.Environ()
+			for key, value := range config.Env {
+				cmd.Env = append(cmd.Env, key+"="+value)
+			}
+		}
+		return &mcp.CommandTransport{Command: cmd}, nil
+	case config.URL != "":
+		return &mcp.StreamableClientTransport{Endpoint: config.URL}, nil
+	default:
+		return nil, fmt.Errorf("set command or url")
+	}
+}
+
+// add connects to a server over transport and adds its tools.
+func (ts *Toolset) add(ctx context.Context, server string, transport mcp.Transport) error {
+	mcpClient := mcp.NewClient(&mcp.Implementation{Name: "gomor", Version: "0.7.0"}, nil)
+	session, err := mcpClient.Connect(ctx, transport, nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	ts.sessions = append(ts.sessions, session)
+
+	for tool, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			return fmt.Errorf("failed to list tools: %w", err)
+		}
+		name := ToolName(server, tool.Name)
+		if _, taken := ts.routes[name]; taken {
+			continue
+		}
+		ts.routes[name] = route{session: session, name: tool.Name}
+		ts.tools = append(ts.tools, client.Tool{
+			Name:        name,
+			Description: tool.Description,
+			Parameters:  objectSchema(tool.InputSchema),
+		})
+	}
+	return nil
+}
+
+// ToolName namespaces a server's tool as "<server>__<tool>", keeping only the
+// characters and length that function names may have.
+func ToolName(server, tool string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, server+"__"+tool)
+	if len(name) > maxToolName {
+		name = name[:maxToolName]
+	}
+	return name
+}
+
+// objectSchema returns a tool's input schema as a JSON object schema.
+func objectSchema(schema any) map[string]any {
+	m, ok := schema.(map[string]any)
+	if !ok {
+		m = map[string]any{}
+		if data, err := json.Marshal(schema); err == nil {
+			_ = json.Unmarshal(data, &m)
+		}
+	}
+	if _, ok := m["type"]; !ok {
+		m["type"] = "object"
+	}
+	return m
+}
+
+// RememberPreferences shows preferences to the model and offers it the
+// built-in remember_tool_preference tool, which passes what the user asked
+// for to save and shows it for the rest of the chat.
+func (ts *Toolset) RememberPreferences(preferences []string, save func(ctx context.Context, text string) error) {
+	ts.preferences = preferences
+	ts.remember = save
+}
+
+// Tools returns the tools offered to the model.
+func (ts *Toolset) Tools() []client.Tool {
+	tools := ts.tools
+	if ts.remember != nil && len(tools) > 0 {
+		tools = append(tools[:len(tools):len(tools)], client.Tool{
+			Name:        RememberToolName,
+			Description: "Save a preference the user stated about how or when to use the tools, e.g. which server to search with, so that later chats follow it.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"preference": map[string]any{"type": "string", "description": "The preference, as one sentence."},
+				},
+				"required": []string{"preference"},
+			},
+		})
+	}
+	return tools
+}
+
+// Instructions lists the user's tool preferences for the system context.
+func (ts *Toolset) Instructions() string {
+	if len(ts.preferences) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("The user's preferences for using tools:\n")
+	for _, p := range ts.preferences {
+		sb.WriteString("- " + p + "\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// Call runs a tool and returns its text output. A result the server marks as
+// an error is returned as one.
+func (ts *Toolset) Call(ctx context.Context, name, arguments string) (string, error) {
+	if name == RememberToolName && ts.remember != nil {
+		return ts.rememberPreference(ctx, arguments)
+	}
+	r, ok := ts.routes[name]
+	if !ok {
+		return "", fmt.Errorf("unknown tool %q", name)
+	}
+
+	params := &mcp.CallToolParams{Name: r.name}
+	if strings.TrimSpace(arguments) != "" {
+		if !json.Valid([]byte(arguments)) {
+			return "", fmt.Errorf("arguments are not valid JSON")
+		}
+		params.Arguments = json.RawMessage(arguments)
+	}
+	result, err := r.session.CallTool(ctx, params)
+	if err != nil {
+		return "", err
+	}
+
+	output := resultText(result)
+	if len(output) > maxResultChars {
+		output = output[:maxResultChars] + "\n[output truncated]"
+	}
+	if result.IsError {
+		return "", errors.New(output)
+	}
+	return output, nil
+}
+
+func (ts *Toolset) rememberPreference(ctx context.Context, arguments string) (string, error) {
+	var args struct {
+		Preference string `json:"preference"`
+	}
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	text := strings.TrimSpace(args.Preference)
+	if text == "" {
+		return "", fmt.Errorf("preference is required")
+	}
+	if err := ts.remember(ctx, text); err != nil {
+		return "", err
+	}
+	ts.preferences = append(ts.preferences, text)
+	return "Saved.", nil
+}
+
+// resultText joins the text content of a result, falling back to its
+// structured content.
+func resultText(result *mcp.CallToolResult) string {
+	var parts []string
+	for _, content := range result.Content {
+		switch c := content.(type) {
+		case *mcp.TextContent:
+			parts = append(parts, c.Text)
+		case *mcp.ImageContent:
+			parts = append(parts, "[image: "+c.MIMEType+"]")
+		case *mcp.AudioContent:
+			parts = append(parts, "[audio: "+c.MIMEType+"]")
+		case *mcp.ResourceLink:
+			parts = append(parts, "[resource: "+c.URI+"]")
+		case *mcp.EmbeddedResource:
+			if c.Resource != nil && c.Resource.Text != "" {
+				parts = append(parts, c.Resource.Text)
+			}
+		}
+	}
+	if len(parts) == 0 && result.StructuredContent != nil {
+		if data, err := json.Marshal(result.StructuredContent); err == nil {
+			parts = append(parts, string(data))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Close ends the sessions, stopping the servers started for them.
+func (ts *Toolset) Close() error {
+	var errs []error
+	for _, session := range ts.sessions {
+		errs = append(errs, session.Close())
+	}
+	ts.sessions = nil
+	return errors.Join(errs...)
+}
+
+// Preferences returns the texts of the newest tool preference memories.
+func Preferences(memories []memtypes.MemoryItem) []string {
+	var items []memtypes.MemoryItem
+	for _, m := range memories {
+		if hasTag(m, PreferenceTag) && (memtypes.MemoryFilter{}).Matches(m) {
+			items = append(items, m)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].CreatedAt.After(items[j].CreatedAt)
+	})
+
+	var texts []string
+	for _, m := range items[:min(len(items), maxPreferences)] {
+		texts = append(texts, m.Text)
+	}
+	return texts
+}
+
+func hasTag(item memtypes.MemoryItem, tag string) bool {
+	for _, t := range item.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package mcpclient
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+type searchInput struct {
+	Query string `json:"query"`
+}
+
+// newTestToolset connects a Toolset to an in-memory server named "web" with
+// a search tool and a tool that always fails.
+func newTestToolset(t *testing.T) *Toolset {
+	t.Helper()
+	ctx := context.Background()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "web", Version: "test"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "search", Description: "Search the web."},
+		func(_ context.Context, _ *mcp.CallToolRequest, in searchInput) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "results for " + in.Query}}}, nil, nil
+		})
+	mcp.AddTool(server, &mcp.Tool{Name: "broken.tool"},
+		func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+			return nil, nil, errors.New("quota exceeded")
+		})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("connect server: %v", err)
+	}
+
+	ts := &Toolset{routes: make(map[string]route)}
+	if err := ts.add(ctx, "web", clientTransport); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	t.Cleanup(func() { _ = ts.Close() })
+	return ts
+}
+
+func TestToolsetListsAndCallsServerTools(t *testing.T) {
+	ts := newTestToolset(t)
+
+	var names []string
+	for _, tool := range ts.Tools() {
+		names = append(names, tool.Name)
+	}
+	if strings.Join(names, ",") != "web__broken_tool,web__search" {
+		t.Fatalf("expected namespaced tools, got %v", names)
+	}
+	search := ts.Tools()[1]
+	if search.Parameters["type"] != "object" || search.Description != "Search the web." {
+		t.Fatalf("unexpected search tool: %+v", search)
+	}
+
+	out, err := ts.Call(context.Background(), "web__search", `{"query":"gomor"}`)
+	if err != nil || out != "results for gomor" {
+		t.Fatalf("expected search results, got %q, %v", out, err)
+	}
+
+	if _, err := ts.Call(context.Background(), "web__broken_tool", `{}`); err == nil || !strings.Contains(err.Error(), "quota exceeded") {
+		t.Fatalf("expected the tool's error, got %v", err)
+	}
+	if _, err := ts.Call(context.Background(), "web__missing", `{}`); err == nil {
+		t.Fatal("expected an error for an unknown tool")
+	}
+}
+
+func TestRememberPreferenceSavesAndInstructs(t *testing.T) {
+	ts := newTestToolset(t)
+	if ts.Instructions() != "" {
+		t.Fatalf("expected no instructions without preferences, got %q", ts.Instructions())
+	}
+
+	var saved []string
+	ts.RememberPreferences([]string{"Search with the web server."}, func(_ context.Context, text string) error {
+		saved = append(saved, text)
+		return nil
+	})
+	tools := ts.Tools()
+	if tools[len(tools)-1].Name != RememberToolName {
+		t.Fatalf("expected the remember tool to be offered, got %+v", tools)
+	}
+
+	if _, err := ts.Call(context.Background(), RememberToolName, `{"preference":"Never read files outside ~/notes."}`); err != nil {
+		t.Fatalf("remember: %v", err)
+	}
+	if len(saved) != 1 || saved[0] != "Never read files outside ~/notes." {
+		t.Fatalf("expected the preference to be saved, got %v", saved)
+	}
+	want := "The user's preferences for using tools:\n- Search with the web server.\n- Never read files outside ~/notes."
+	if got := ts.Instructions(); got != want {
+		t.Fatalf("expected instructions %q, got %q", want, got)
+	}
+}
+
+func TestToolName(t *testing.T) {
+	if got := ToolName("my files", "read.file"); got != "my_files__read_file" {
+		t.Fatalf("expected sanitized name, got %q", got)
+	}
+	if got := ToolName(strings.Repeat("s", 40), strings.Repeat("t", 40)); len(got) != maxToolName {
+		t.Fatalf("expected names cut to %d characters, got %d", maxToolName, len(got))
+	}
+}
+
+func TestPreferencesNewestTaggedFirst(t *testing.T) {
+	now := time.Now()
+	memories := []memtypes.MemoryItem{
+		{Text: "old", Tags: []string{PreferenceTag}, CreatedAt: now.Add(-time.Hour)},
+		{Text: "untagged", CreatedAt: now},
+		{Text: "new", Tags: []string{"Tool-Preference"}, CreatedAt: now},
+		{Text: "suppressed", Tags: []string{PreferenceTag}, CreatedAt: now, Suppressed: true},
+	}
+	if got := strings.Join(Preferences(memories), ","); got != "new,old" {
+		t.Fatalf("expected new,old, got %s", got)
+	}
+}
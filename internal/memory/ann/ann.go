@@ -0,0 +1,299 @@
+// Package ann is an approximate nearest-neighbor index over embeddings: a
+// hierarchical navigable small world (HNSW) graph. It finds the vectors most
+// similar to a query, by cosine similarity, in time that grows with the log of
+// the number of vectors rather than linearly, at the price of now and then
+// missing one of the true nearest.
+package ann
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+const (
+	// maxLinks is how many neighbors a node keeps on the upper layers, and
+	// half of what it keeps on layer 0.
+	maxLinks = 12
+	// efConstruction is how many candidates are weighed when linking a node.
+	efConstruction = 64
+)
+
+// Hit is a vector found by Search.
+type Hit struct {
+	ID         string
+	Similarity float64
+}
+
+// Index is an HNSW graph over vectors of one dimension. Vectors are stored
+// normalized, so similarity is their dot product. It is safe for concurrent
+// use.
+type Index struct {
+	mu      sync.RWMutex
+	dim     int
+	nodes   []*node
+	byID    map[string]int32
+	entry   int32 // -1 while the index is empty
+	top     int   // the entry node's level
+	deleted int
+	rng     *rand.Rand
+}
+
+type node struct {
+	id      string
+	vec     []float32
+	links   [][]int32 // neighbors on each layer, 0 first
+	deleted bool
+}
+
+// New returns an empty index for vectors of dim dimensions.
+func New(dim int) *Index {
+	return &Index{dim: dim, byID: make(map[string]int32), entry: -1, rng: rand.New(rand.NewSource(1))}
+}
+
+// Dim returns the dimension of the index's vectors.
+func (x *Index) Dim() int {
+	return x.dim
+}
+
+// Len returns how many vectors the index holds.
+func (x *Index) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.byID)
+}
+
+// Stale reports whether so many vectors were removed that the index should
+// be rebuilt: removed vectors stay in the graph to keep it connected, and
+// slow searches down.
+func (x *Index) Stale() bool {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return x.deleted > 1000 && x.deleted > len(x.byID)/2
+}
+
+// Add indexes vec under id, replacing the vector id had. Vectors of another
+// dimension are ignored.
+func (x *Index) Add(id string, vec []float32) {
+	if len(vec) != x.dim {
+		return
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.remove(id)
+
+	level := int(-math.Log(1-x.rng.Float64()) / math.Log(maxLinks))
+	n := &node{id: id, vec: normalize(vec), links: make([][]int32, level+1)}
+	idx := int32(len(x.nodes))
+	x.nodes = append(x.nodes, n)
+	x.byID[id] = idx
+	if x.entry < 0 {
+		x.entry, x.top = idx, level
+		return
+	}
+
+	ep := x.entry
+	for l := x.top; l > level; l-- {
+		ep = x.greedy(n.vec, ep, l)
+	}
+	for l := min(level, x.top); l >= 0; l-- {
+		candidates := x.searchLayer(n.vec, ep, efConstruction, l)
+		n.links[l] = x.selectNeighbors(candidates, linksAt(l))
+		for _, nb := range n.links[l] {
+			x.link(nb, idx, l)
+		}
+		ep = candidates[0].idx
+	}
+	if level > x.top {
+		x.entry, x.top = idx, level
+	}
+}
+
+// Remove drops the vector indexed under id, if any.
+func (x *Index) Remove(id string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.remove(id)
+}
+
+func (x *Index) remove(id string) {
+	idx, ok := x.byID[id]
+	if !ok {
+		return
+	}
+	delete(x.byID, id)
+	x.nodes[idx].deleted = true
+	x.deleted++
+}
+
+// Search returns up to k of the vectors most similar to query, best first,
+// weighing ef candidates; a larger ef finds more of the true nearest, more
+// slowly.
+func (x *Index) Search(query []float32, k, ef int) []Hit {
+	if len(query) != x.dim || k <= 0 {
+		return nil
+	}
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	if x.entry < 0 {
+		return nil
+	}
+
+	q := normalize(query)
+	ep := x.entry
+	for l := x.top; l > 0; l-- {
+		ep = x.greedy(q, ep, l)
+	}
+	var hits []Hit
+	for _, c := range x.searchLayer(q, ep, max(ef, k), 0) {
+		if n := x.nodes[c.idx]; !n.deleted {
+			hits = append(hits, Hit{ID: n.id, Similarity: c.sim})
+			if len(hits) == k {
+				break
+			}
+		}
+	}
+	return hits
+}
+
+// greedy walks layer l from ep towards q and returns the closest node found.
+func (x *Index) greedy(q []float32, ep int32, l int) int32 {
+	best := dot(q, x.nodes[ep].vec)
+	for changed := true; changed; {
+		changed = false
+		for _, nb := range x.nodes[ep].links[l] {
+			if sim := dot(q, x.nodes[nb].vec); sim > best {
+				best, ep, changed = sim, nb, true
+			}
+		}
+	}
+	return ep
+}
+
+// searchLayer returns the ef nodes closest to q on layer l found from ep,
+// best first.
+func (x *Index) searchLayer(q []float32, ep int32, ef, l int) []candidate {
+	start := candidate{idx: ep, sim: dot(q, x.nodes[ep].vec)}
+	visited := make([]bool, len(x.nodes))
+	visited[ep] = true
+	frontier := &maxHeap{start}
+	found := &minHeap{start}
+	for frontier.Len() > 0 {
+		c := heap.Pop(frontier).(candidate)
+		if found.Len() >= ef && c.sim < (*found)[0].sim {
+			break
+		}
+		for _, nb := range x.nodes[c.idx].links[l] {
+			if visited[nb] {
+				continue
+			}
+			visited[nb] = true
+			sim := dot(q, x.nodes[nb].vec)
+			if found.Len() < ef || sim > (*found)[0].sim {
+				heap.Push(frontier, candidate{idx: nb, sim: sim})
+				heap.Push(found, candidate{idx: nb, sim: sim})
+				if found.Len() > ef {
+					heap.Pop(found)
+				}
+			}
+		}
+	}
+
+	result := make([]candidate, found.Len())
+	for i := len(result) - 1; i >= 0; i-- {
+		result[i] = heap.Pop(found).(candidate)
+	}
+	return result
+}
+
+// link adds to as a neighbor of from on layer l, reselecting from's neighbors
+// when it has too many.
+func (x *Index) link(from, to int32, l int) {
+	n := x.nodes[from]
+	n.links[l] = append(n.links[l], to)
+	if len(n.links[l]) <= linksAt(l) {
+		return
+	}
+	candidates := make([]candidate, len(n.links[l]))
+	for i, nb := range n.links[l] {
+		candidates[i] = candidate{idx: nb, sim: dot(n.vec, x.nodes[nb].vec)}
+	}
+	sortCandidates(candidates)
+	n.links[l] = x.selectNeighbors(candidates, linksAt(l))
+}
+
+// selectNeighbors picks up to m neighbors from candidates, best first. A
+// candidate closer to a neighbor already picked than to the node itself is
+// skipped while others remain, so links reach out in every direction rather
+// than all into the nearest cluster, which keeps clusters connected.
+func (x *Index) selectNeighbors(candidates []candidate, m int) []int32 {
+	selected := make([]int32, 0, m)
+	var skipped []int32
+	for _, c := range candidates {
+		if len(selected) == m {
+			return selected
+		}
+		diverse := true
+		for _, s := range selected {
+			if dot(x.nodes[c.idx].vec, x.nodes[s].vec) > c.sim {
+				diverse = false
+				break
+			}
+		}
+		if diverse {
+			selected = append(selected, c.idx)
+		} else {
+			skipped = append(skipped, c.idx)
+		}
+	}
+	for _, idx := range skipped {
+		if len(selected) == m {
+			break
+		}
+		selected = append(selected, idx)
+	}
+	return selected
+}
+
+// linksAt is how many neighbors a node keeps on layer l.
+func linksAt(l int) int {
+	if l == 0 {
+		return 2 * maxLinks
+	}
+	return maxLinks
+}
+
+func normalize(v []float32) []float32 {
+	var sum float64
+	for _, f := range v {
+		sum += float64(f) * float64(f)
+	}
+	out := make([]float32, len(v))
+	if sum == 0 {
+		return out
+	}
+	norm := math.Sqrt(sum)
+	for i, f := range v {
+		out[i] = float32(float64(f) / norm)
+	}
+	return out
+}
+
+// dot is the dot product of two vectors of the same length, summed four
+// ways at once, which the compiler turns into faster code than one sum.
+func dot(a, b []float32) float64 {
+	b = b[:len(a)]
+	var s0, s1, s2, s3 float32
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		s0 += a[i] * b[i]
+		s1 += a[i+1] * b[i+1]
+		s2 += a[i+2] * b[i+2]
+		s3 += a[i+3] * b[i+3]
+	}
+	for ; i < len(a); i++ {
+		s0 += a[i] * b[i]
+	}
+	return float64(s0 + s1 + s2 + s3)
+}
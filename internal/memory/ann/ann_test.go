@@ -0,0 +1,131 @@
+package ann
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func randomVectors(n, dim int, seed int64) map[string][]float32 {
+	rng := rand.New(rand.NewSource(seed))
+	vectors := make(map[string][]float32, n)
+	for i := 0; i < n; i++ {
+		v := make([]float32, dim)
+		for j := range v {
+			v[j] = float32(rng.NormFloat64())
+		}
+		vectors[fmt.Sprintf("m%d", i)] = v
+	}
+	return vectors
+}
+
+// exact returns the IDs of the k vectors most similar to query.
+func exact(vectors map[string][]float32, query []float32, k int) []string {
+	q := normalize(query)
+	ids := make([]string, 0, len(vectors))
+	sims := make(map[string]float64, len(vectors))
+	for id, v := range vectors {
+		ids = append(ids, id)
+		sims[id] = dot(q, normalize(v))
+	}
+	sort.Slice(ids, func(i, j int) bool { return sims[ids[i]] > sims[ids[j]] })
+	return ids[:k]
+}
+
+func TestSearchFindsNearestNeighbors(t *testing.T) {
+	vectors := randomVectors(3000, 32, 1)
+	x := New(32)
+	for id, v := range vectors {
+		x.Add(id, v)
+	}
+	if x.Len() != len(vectors) {
+		t.Fatalf("expected %d vectors, got %d", len(vectors), x.Len())
+	}
+
+	found, total := 0, 0
+	for id, query := range randomVectors(50, 32, 2) {
+		want := exact(vectors, query, 10)
+		hits := x.Search(query, 10, 100)
+		if len(hits) != 10 {
+			t.Fatalf("%s: expected 10 hits, got %d", id, len(hits))
+		}
+		got := make(map[string]bool)
+		for i, hit := range hits {
+			got[hit.ID] = true
+			if i > 0 && hit.Similarity > hits[i-1].Similarity {
+				t.Fatalf("%s: hits are not sorted: %+v", id, hits)
+			}
+		}
+		for _, id := range want {
+			if got[id] {
+				found++
+			}
+			total++
+		}
+	}
+	if recall := float64(found) / float64(total); recall < 0.9 {
+		t.Fatalf("expected a recall of at least 0.9, got %.2f", recall)
+	}
+}
+
+func TestRemoveAndReplace(t *testing.T) {
+	x := New(3)
+	x.Add("a", []float32{1, 0, 0})
+	x.Add("b", []float32{0, 1, 0})
+	x.Add("c", []float32{0, 0, 1})
+
+	x.Remove("a")
+	if hits := x.Search([]float32{1, 0, 0}, 3, 10); len(hits) != 2 || hits[0].ID == "a" {
+		t.Fatalf("expected the removed vector to be gone, got %+v", hits)
+	}
+	x.Add("b", []float32{1, 0.1, 0})
+	if hits := x.Search([]float32{1, 0, 0}, 1, 10); len(hits) != 1 || hits[0].ID != "b" {
+		t.Fatalf("expected the replaced vector to match, got %+v", hits)
+	}
+	if x.Len() != 2 {
+		t.Fatalf("expected 2 vectors, got %d", x.Len())
+	}
+	x.Add("d", []float32{1, 0})
+	if x.Len() != 2 {
+		t.Fatal("expected a vector of another dimension to be ignored")
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	vectors := randomVectors(500, 16, 3)
+	x := New(16)
+	for id, v := range vectors {
+		x.Add(id, v)
+	}
+	x.Remove("m7")
+
+	var buf bytes.Buffer
+	if err := x.Write(&buf); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	restored, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if restored.Len() != x.Len() || restored.Dim() != 16 {
+		t.Fatalf("expected %d vectors of 16 dimensions, got %d of %d", x.Len(), restored.Len(), restored.Dim())
+	}
+	query := vectors["m8"]
+	want, got := x.Search(query, 5, 50), restored.Search(query, 5, 50)
+	if fmt.Sprint(want) != fmt.Sprint(got) {
+		t.Fatalf("expected the same hits after a round trip, got %v and %v", want, got)
+	}
+
+	if _, err := Read(strings.NewReader("not an index")); !errors.Is(err, ErrFormat) {
+		t.Fatalf("expected ErrFormat, got %v", err)
+	}
+	var truncated bytes.Buffer
+	_ = x.Write(&truncated)
+	if _, err := Read(bytes.NewReader(truncated.Bytes()[:truncated.Len()/2])); err == nil {
+		t.Fatal("expected an error reading a truncated index")
+	}
+}
@@ -0,0 +1,204 @@
+package ann
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// magic starts every saved index, naming the format and its version.
+const magic = "gomor-ann 1\n"
+
+// ErrFormat is returned by Read for data that is not a saved index.
+var ErrFormat = errors.New("not a gomor vector index")
+
+// Write saves the index to w in a form Read restores.
+func (x *Index) Write(w io.Writer) error {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	e := encoder{w: bw}
+	e.bytes([]byte(magic))
+	e.uvarint(uint64(x.dim))
+	e.uvarint(uint64(len(x.nodes)))
+	e.varint(int64(x.entry))
+	e.uvarint(uint64(x.top))
+	for _, n := range x.nodes {
+		e.uvarint(uint64(len(n.id)))
+		e.bytes([]byte(n.id))
+		if n.deleted {
+			e.bytes([]byte{1})
+		} else {
+			e.bytes([]byte{0})
+		}
+		for _, f := range n.vec {
+			e.uint32(math.Float32bits(f))
+		}
+		e.uvarint(uint64(len(n.links)))
+		for _, links := range n.links {
+			e.uvarint(uint64(len(links)))
+			for _, nb := range links {
+				e.uvarint(uint64(nb))
+			}
+		}
+	}
+	if e.err != nil {
+		return e.err
+	}
+	return bw.Flush()
+}
+
+// Read restores an index saved by Write.
+func Read(r io.Reader) (*Index, error) {
+	d := decoder{r: bufio.NewReader(r)}
+	if string(d.bytes(len(magic))) != magic {
+		if d.err != nil && !errors.Is(d.err, io.ErrUnexpectedEOF) && !errors.Is(d.err, io.EOF) {
+			return nil, d.err
+		}
+		return nil, ErrFormat
+	}
+
+	x := New(int(d.uvarint()))
+	count := d.uvarint()
+	x.entry = int32(d.varint())
+	x.top = int(d.uvarint())
+	if d.err == nil && (count > math.MaxInt32 || x.dim <= 0 || x.dim > maxIDLength || x.entry < -1 || int64(x.entry) >= int64(count)) {
+		return nil, ErrFormat
+	}
+	for i := uint64(0); i < count && d.err == nil; i++ {
+		n := &node{id: string(d.bytes(int(d.uvarint())))}
+		n.deleted = d.bytes(1)[0] == 1
+		n.vec = make([]float32, x.dim)
+		for j := range n.vec {
+			n.vec[j] = math.Float32frombits(d.uint32())
+		}
+		levels := d.uvarint()
+		if levels > maxLevels {
+			return nil, ErrFormat
+		}
+		n.links = make([][]int32, levels)
+		for l := range n.links {
+			links := d.uvarint()
+			if links > uint64(linksAt(0)) {
+				return nil, ErrFormat
+			}
+			n.links[l] = make([]int32, links)
+			for j := range n.links[l] {
+				nb := d.uvarint()
+				if nb >= count {
+					return nil, ErrFormat
+				}
+				n.links[l][j] = int32(nb)
+			}
+		}
+		x.nodes = append(x.nodes, n)
+		if n.deleted {
+			x.deleted++
+		} else {
+			x.byID[n.id] = int32(i)
+		}
+	}
+	if d.err != nil {
+		return nil, fmt.Errorf("failed to read vector index: %w", d.err)
+	}
+	if !x.valid() {
+		return nil, ErrFormat
+	}
+	return x, nil
+}
+
+// valid reports whether every link of a restored index leads to a node on
+// that layer, so searches cannot run off the graph.
+func (x *Index) valid() bool {
+	if x.entry >= 0 && len(x.nodes[x.entry].links) != x.top+1 {
+		return false
+	}
+	for _, n := range x.nodes {
+		for l, links := range n.links {
+			for _, nb := range links {
+				if len(x.nodes[nb].links) <= l {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// encoder writes binary values, keeping the first error.
+type encoder struct {
+	w   *bufio.Writer
+	buf [binary.MaxVarintLen64]byte
+	err error
+}
+
+func (e *encoder) bytes(b []byte) {
+	if e.err == nil {
+		_, e.err = e.w.Write(b)
+	}
+}
+
+func (e *encoder) uvarint(v uint64) { e.bytes(e.buf[:binary.PutUvarint(e.buf[:], v)]) }
+func (e *encoder) varint(v int64)   { e.bytes(e.buf[:binary.PutVarint(e.buf[:], v)]) }
+
+func (e *encoder) uint32(v uint32) {
+	binary.LittleEndian.PutUint32(e.buf[:4], v)
+	e.bytes(e.buf[:4])
+}
+
+// decoder reads binary values, keeping the first error and returning zeros
+// after it.
+type decoder struct {
+	r   *bufio.Reader
+	err error
+}
+
+// maxIDLength and maxLevels bound the length of a stored ID or vector and the
+// layers of a node, so a corrupt file cannot ask for a huge allocation.
+const (
+	maxIDLength = 1 << 16
+	maxLevels   = 64
+)
+
+func (d *decoder) bytes(n int) []byte {
+	if d.err == nil && n > maxIDLength {
+		d.err = ErrFormat
+	}
+	if d.err != nil {
+		return make([]byte, 1)
+	}
+	b := make([]byte, n)
+	_, d.err = io.ReadFull(d.r, b)
+	return b
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(d.r)
+	d.err = err
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(d.r)
+	d.err = err
+	return v
+}
+
+func (d *decoder) uint32() uint32 {
+	if d.err != nil {
+		return 0
+	}
+	var b [4]byte
+	_, d.err = io.ReadFull(d.r, b[:])
+	return binary.LittleEndian.Uint32(b[:])
+}
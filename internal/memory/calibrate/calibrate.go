@@ -0,0 +1,232 @@
+// Package calibrate measures how similar an embedding model finds pairs of
+// stored memories, to suggest a min_similarity that suits the model.
+package calibrate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/memutils"
+)
+
+// DefaultPairs is how many memory pairs are sampled when none is given.
+const DefaultPairs = 2000
+
+// minPairs is the fewest pairs a recommendation is based on.
+const minPairs = 10
+
+// recommendPercentile is the share of sampled pairs that fall below the
+// recommended threshold. Random pairs are nearly all unrelated, so a hit
+// above it stands out from the model's background similarity.
+const recommendPercentile = 0.95
+
+// binWidth is the width of a histogram bar, and barLength the longest bar.
+const (
+	binWidth  = 0.05
+	barLength = 40
+)
+
+// ErrTooFewMemories is returned when the store has too few embedded memories
+// to sample from.
+var ErrTooFewMemories = errors.New("too few memories to calibrate")
+
+// Bin counts the sampled pairs whose similarity is in [Low, High).
+type Bin struct {
+	Low   float64 `json:"low"`
+	High  float64 `json:"high"`
+	Count int     `json:"count"`
+}
+
+// Report is the similarity distribution of sampled memory pairs.
+type Report struct {
+	Provider    string  `json:"provider"`
+	ModelID     string  `json:"model_id"`
+	Memories    int     `json:"memories"`
+	Pairs       int     `json:"pairs"`
+	Min         float64 `json:"min"`
+	Mean        float64 `json:"mean"`
+	Median      float64 `json:"median"`
+	P90         float64 `json:"p90"`
+	P95         float64 `json:"p95"`
+	P99         float64 `json:"p99"`
+	Max         float64 `json:"max"`
+	Histogram   []Bin   `json:"histogram"`
+	Current     float64 `json:"current_min_similarity"`
+	Recommended float64 `json:"recommended_min_similarity"`
+}
+
+// Run samples up to pairs distinct pairs of the memories embedded by
+// provider/modelID and reports their similarity distribution. current is the
+// configured min_similarity, for comparison.
+func Run(memories []memtypes.MemoryItem, provider, modelID string, pairs int, current float64, rng *rand.Rand) (*Report, error) {
+	if pairs <= 0 {
+		pairs = DefaultPairs
+	}
+
+	var embeddings [][]float32
+	for _, m := range memories {
+		if m.Provider != provider || m.ModelID != modelID || len(m.Embedding) == 0 {
+			continue
+		}
+		if len(embeddings) > 0 && len(m.Embedding) != len(embeddings[0]) {
+			continue
+		}
+		embeddings = append(embeddings, m.Embedding)
+	}
+
+	sims := sample(embeddings, pairs, rng)
+	if len(sims) < minPairs {
+		return nil, fmt.Errorf("%w: %d memories embedded by %s/%s give %d pairs, need %d",
+			ErrTooFewMemories, len(embeddings), provider, modelID, len(sims), minPairs)
+	}
+	sort.Float64s(sims)
+
+	var sum float64
+	for _, s := range sims {
+		sum += s
+	}
+	report := &Report{
+		Provider: provider,
+		ModelID:  modelID,
+		Memories: len(embeddings),
+		Pairs:    len(sims),
+		Min:      sims[0],
+		Mean:     sum / float64(len(sims)),
+		Median:   percentile(sims, 0.5),
+		P90:      percentile(sims, 0.9),
+		P95:      percentile(sims, 0.95),
+		P99:      percentile(sims, 0.99),
+		Max:      sims[len(sims)-1],
+		Current:  current,
+	}
+	report.Histogram = histogram(sims)
+	report.Recommended = math.Min(1, math.Ceil(percentile(sims, recommendPercentile)*100)/100)
+	return report, nil
+}
+
+// sample returns the similarities of up to pairs distinct pairs of
+// embeddings. All pairs are used when there are no more than pairs of them.
+func sample(embeddings [][]float32, pairs int, rng *rand.Rand) []float64 {
+	n := len(embeddings)
+	if n < 2 {
+		return nil
+	}
+
+	similarity := func(i, j int) float64 {
+		// Embeddings are stored normalized, so the dot product is the cosine.
+		return memutils.DotProduct(embeddings[i], embeddings[j])
+	}
+
+	total := n * (n - 1) / 2
+	var sims []float64
+	if total <= pairs {
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				sims = append(sims, similarity(i, j))
+			}
+		}
+		return sims
+	}
+
+	seen := make(map[[2]int]bool, pairs)
+	for len(sims) < pairs {
+		i, j := rng.IntN(n), rng.IntN(n)
+		if i == j {
+			continue
+		}
+		if i > j {
+			i, j = j, i
+		}
+		if seen[[2]int{i, j}] {
+			continue
+		}
+		seen[[2]int{i, j}] = true
+		sims = append(sims, similarity(i, j))
+	}
+	return sims
+}
+
+// percentile returns the p-th percentile of sorted, by nearest rank.
+func percentile(sorted []float64, p float64) float64 {
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(0, min(i, len(sorted)-1))]
+}
+
+// histogram buckets sorted into bins of binWidth covering its range.
+func histogram(sorted []float64) []Bin {
+	low := math.Floor(sorted[0]/binWidth) * binWidth
+	count := int(math.Floor((sorted[len(sorted)-1]-low)/binWidth)) + 1
+
+	bins := make([]Bin, count)
+	for i := range bins {
+		bins[i].Low = round(low + float64(i)*binWidth)
+		bins[i].High = round(low + float64(i+1)*binWidth)
+	}
+	for _, s := range sorted {
+		i := int(math.Floor((s - low) / binWidth))
+		bins[max(0, min(i, count-1))].Count++
+	}
+	return bins
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// WriteText writes r as a summary and a histogram of bars, marking the bins
+// holding the current and recommended thresholds.
+func WriteText(w io.Writer, r *Report) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Embedding model: %s/%s\n", r.Provider, r.ModelID)
+	fmt.Fprintf(&sb, "Sampled %d pairs of %d memories\n\n", r.Pairs, r.Memories)
+	fmt.Fprintf(&sb, "  min %.3f  median %.3f  mean %.3f  p90 %.3f  p95 %.3f  p99 %.3f  max %.3f\n\n",
+		r.Min, r.Median, r.Mean, r.P90, r.P95, r.P99, r.Max)
+
+	most := 0
+	for _, b := range r.Histogram {
+		most = max(most, b.Count)
+	}
+	for i, b := range r.Histogram {
+		last := i == len(r.Histogram)-1
+		bar := 0
+		if most > 0 {
+			bar = (b.Count*barLength + most - 1) / most
+		}
+		var marks []string
+		if inBin(b, r.Current, last) {
+			marks = append(marks, "current")
+		}
+		if inBin(b, r.Recommended, last) {
+			marks = append(marks, "recommended")
+		}
+		line := fmt.Sprintf("  %5.2f-%5.2f | %-*s %d", b.Low, b.High, barLength, strings.Repeat("#", bar), b.Count)
+		if len(marks) > 0 {
+			line += "  <- " + strings.Join(marks, ", ")
+		}
+		sb.WriteString(line + "\n")
+	}
+
+	fmt.Fprintf(&sb, "\nCurrent min_similarity: %.2f\n", r.Current)
+	fmt.Fprintf(&sb, "Recommended min_similarity: %.2f (%.0f%% of sampled pairs score below it)\n",
+		r.Recommended, recommendPercentile*100)
+	switch {
+	case r.Current > r.P99:
+		sb.WriteString("The current value is above almost every pair, so related memories may be missed.\n")
+	case r.Current < r.Median:
+		sb.WriteString("The current value is below most pairs, so unrelated memories will be retrieved.\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// inBin reports whether v falls in b. The last bin also holds its upper bound.
+func inBin(b Bin, v float64, last bool) bool {
+	return v >= b.Low && (v < b.High || last && v == b.High)
+}
@@ -0,0 +1,98 @@
+package calibrate
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/memutils"
+)
+
+// memoriesAt returns memories whose embeddings sit at the given angles on the
+// unit circle, so the similarity of a pair is the cosine of their difference.
+func memoriesAt(angles ...float64) []memtypes.MemoryItem {
+	memories := make([]memtypes.MemoryItem, len(angles))
+	for i, a := range angles {
+		memories[i] = memtypes.MemoryItem{
+			Provider:  "fake",
+			ModelID:   "fake-embedding",
+			Embedding: memutils.NormalizeVector([]float32{float32(math.Cos(a)), float32(math.Sin(a))}),
+		}
+	}
+	return memories
+}
+
+func TestRunUsesEveryPairWhenFew(t *testing.T) {
+	memories := memoriesAt(0, 0.1, 0.2, 0.3, 0.4, 0.5)
+	// Memories from another model are never compared.
+	memories = append(memories, memtypes.MemoryItem{Provider: "other", ModelID: "x", Embedding: []float32{1, 0}})
+
+	report, err := Run(memories, "fake", "fake-embedding", 100, 0.8, rand.New(rand.NewPCG(1, 1)))
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if report.Memories != 6 || report.Pairs != 15 {
+		t.Fatalf("expected 15 pairs of 6 memories, got %d of %d", report.Pairs, report.Memories)
+	}
+	if math.Abs(report.Max-math.Cos(0.1)) > 1e-6 || math.Abs(report.Min-math.Cos(0.5)) > 1e-6 {
+		t.Fatalf("unexpected range %.4f-%.4f", report.Min, report.Max)
+	}
+	if report.Recommended < report.P90 || report.Recommended > 1 {
+		t.Fatalf("recommended %.2f outside [p90 %.3f, 1]", report.Recommended, report.P90)
+	}
+
+	total := 0
+	for _, b := range report.Histogram {
+		total += b.Count
+	}
+	if total != report.Pairs {
+		t.Fatalf("histogram holds %d pairs, want %d", total, report.Pairs)
+	}
+}
+
+func TestRunSamplesDistinctPairs(t *testing.T) {
+	angles := make([]float64, 100)
+	for i := range angles {
+		angles[i] = float64(i) * math.Pi / 100
+	}
+
+	report, err := Run(memoriesAt(angles...), "fake", "fake-embedding", 500, 0.4, rand.New(rand.NewPCG(7, 7)))
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if report.Pairs != 500 {
+		t.Fatalf("expected 500 sampled pairs, got %d", report.Pairs)
+	}
+	if report.Min > report.Median || report.Median > report.P95 || report.P95 > report.Max {
+		t.Fatalf("percentiles out of order: %+v", report)
+	}
+}
+
+func TestRunNeedsEnoughMemories(t *testing.T) {
+	_, err := Run(memoriesAt(0, 0.1, 0.2), "fake", "fake-embedding", 0, 0.4, rand.New(rand.NewPCG(1, 1)))
+	if !errors.Is(err, ErrTooFewMemories) {
+		t.Fatalf("expected ErrTooFewMemories, got %v", err)
+	}
+}
+
+func TestWriteTextMarksThresholds(t *testing.T) {
+	report, err := Run(memoriesAt(0, 0.1, 0.2, 0.3, 0.4, 0.5), "fake", "fake-embedding", 0, 0.9, rand.New(rand.NewPCG(1, 1)))
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := WriteText(&out, report); err != nil {
+		t.Fatalf("write text: %v", err)
+	}
+	text := out.String()
+	for _, want := range []string{"fake/fake-embedding", "<- current", "recommended", "Recommended min_similarity:", "#"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("output missing %q:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Package citation labels the memories injected into a prompt as [M1], [M2],
+// ... so the model can cite them, and turns the labels an answer cites into
+// footnotes naming each memory, which the user can then check or correct.
+package citation
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+// Instruction tells the model how to cite labeled memories.
+const Instruction = "Each memory is labeled [M1], [M2], and so on. When the answer relies on a memory, cite its label right after the claim."
+
+// maxFootnoteChars bounds the memory text shown in a footnote.
+const maxFootnoteChars = 120
+
+// Label returns the label of the i-th memory (0-based).
+func Label(i int) string {
+	return fmt.Sprintf("[M%d]", i+1)
+}
+
+// Memories lists the memories with their labels, after Instruction.
+func Memories(items []memtypes.MemoryItem) string {
+	if len(items) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(Instruction + "\n")
+	for i, item := range items {
+		sb.WriteString(Label(i) + " " + strings.Join(strings.Fields(item.Text), " ") + "\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// Prompt asks prompt with the labeled memories as context.
+func Prompt(prompt string, items []memtypes.MemoryItem) string {
+	if len(items) == 0 {
+		return prompt
+	}
+	var sb strings.Builder
+	sb.WriteString("Answer the prompt at the end. The memories below are what you know about the user; use them when they help.\n")
+	sb.WriteString("\nMemories:\n" + Memories(items) + "\n")
+	sb.WriteString("\nPrompt: " + prompt)
+	return sb.String()
+}
+
+var label = regexp.MustCompile(`\[M(\d+)\]`)
+
+// Cited returns the indexes of the memories answer cites, in the order they
+// are first cited. Labels beyond count are ignored.
+func Cited(answer string, count int) []int {
+	seen := make(map[int]bool)
+	var cited []int
+	for _, m := range label.FindAllStringSubmatch(answer, -1) {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n < 1 || n > count || seen[n] {
+			continue
+		}
+		seen[n] = true
+		cited = append(cited, n-1)
+	}
+	return cited
+}
+
+// Footnotes renders the memories answer cites, each with its label, text, and
+// ID, or "" when it cites none.
+func Footnotes(answer string, items []memtypes.MemoryItem) string {
+	cited := Cited(answer, len(items))
+	if len(cited) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("Memories:\n")
+	for _, i := range cited {
+		text := strings.Join(strings.Fields(items[i].Text), " ")
+		if runes := []rune(text); len(runes) > maxFootnoteChars {
+			text = string(runes[:maxFootnoteChars-3]) + "..."
+		}
+		fmt.Fprintf(&sb, "%s %s (%s)\n", Label(i), text, items[i].ID)
+	}
+	return sb.String()
+}
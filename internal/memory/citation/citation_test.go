@@ -0,0 +1,43 @@
+package citation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+func TestCitedKeepsFirstCitationOrder(t *testing.T) {
+	got := Cited("Per [M3] and [M1], again [M3]; not [M9] or [1].", 3)
+	if len(got) != 2 || got[0] != 2 || got[1] != 0 {
+		t.Fatalf("expected [2 0], got %v", got)
+	}
+}
+
+func TestFootnotes(t *testing.T) {
+	items := []memtypes.MemoryItem{
+		{ID: "a", Text: "The user prefers table-driven tests."},
+		{ID: "b", Text: strings.Repeat("long ", 40)},
+	}
+	got := Footnotes("Use subtests [M1]. Also [M2].", items)
+	lines := strings.Split(strings.TrimSpace(got), "\n")
+	if len(lines) != 3 || lines[1] != "[M1] The user prefers table-driven tests. (a)" {
+		t.Fatalf("unexpected footnotes:\n%s", got)
+	}
+	if !strings.HasSuffix(lines[2], "... (b)") || len(lines[2]) > maxFootnoteChars+len("[M2]  (b)") {
+		t.Fatalf("expected a truncated footnote, got %q", lines[2])
+	}
+	if Footnotes("no citations", items) != "" {
+		t.Fatal("expected no footnotes when nothing is cited")
+	}
+}
+
+func TestPromptLabelsMemories(t *testing.T) {
+	prompt := Prompt("how should I test?", []memtypes.MemoryItem{{Text: "Prefers\ntable-driven tests."}})
+	if !strings.Contains(prompt, Instruction+"\n[M1] Prefers table-driven tests.") || !strings.HasSuffix(prompt, "Prompt: how should I test?") {
+		t.Fatalf("unexpected prompt:\n%s", prompt)
+	}
+	if Prompt("hi", nil) != "hi" {
+		t.Fatal("expected the prompt unchanged without memories")
+	}
+}
@@ -17,6 +17,16 @@ const (
 	reinforcementThreshold  = 0.55
 	reinforcementFactor     = 1.05
 	maxStabilityDays        = 180.0
+
+	// Feedback moves confidence a quarter of the way to 1 when a memory was
+	// useful and cuts it by 40% when it was wrong, so a few wrong votes sink a
+	// memory below fresh ones while a single vote is easy to undo.
+	usefulConfidenceStep  = 0.25
+	wrongConfidenceFactor = 0.60
+	minConfidence         = 0.05
+	usefulStabilityFactor = 1.25
+	wrongStabilityFactor  = 0.50
+	minStabilityDays      = 1.0
 )
 
 func DefaultConfidence(source memtypes.MemorySource) float64 {
@@ -70,3 +80,23 @@ func ReinforcedStability(stabilityDays float64) float64 {
 	}
 	return math.Min(stabilityDays*reinforcementFactor, maxStabilityDays)
 }
+
+// ApplyFeedback returns a memory's confidence and stability after a user marks
+// it useful or wrong. Confidence stays within [minConfidence, 1].
+func ApplyFeedback(confidence, stabilityDays float64, useful bool) (float64, float64) {
+	if confidence <= 0 {
+		confidence = explicitConfidence
+	}
+	if stabilityDays <= 0 {
+		stabilityDays = explicitStabilityDays
+	}
+
+	if useful {
+		confidence += (1 - confidence) * usefulConfidenceStep
+		stabilityDays = math.Min(stabilityDays*usefulStabilityFactor, maxStabilityDays)
+	} else {
+		confidence = math.Max(confidence*wrongConfidenceFactor, minConfidence)
+		stabilityDays = math.Max(stabilityDays*wrongStabilityFactor, minStabilityDays)
+	}
+	return math.Min(confidence, 1), stabilityDays
+}
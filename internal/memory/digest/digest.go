@@ -0,0 +1,210 @@
+// Package digest reviews a period: the memories added and the conversations
+// held since a time, summarized by the tool model into a short report that can
+// be printed or delivered by email or webhook.
+package digest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+const (
+	// maxMemories bounds how many memories are shown to the tool model.
+	maxMemories = 100
+	// maxConversations bounds how many conversations a digest covers.
+	maxConversations = 10
+	// maxPromptChars bounds the opening prompt quoted for a conversation.
+	maxPromptChars = 160
+)
+
+const dateLayout = "2006-01-02"
+
+// Conversation is a session that had turns during the period.
+type Conversation struct {
+	SessionID   string    `json:"session_id"`
+	FirstPrompt string    `json:"first_prompt"`
+	Turns       int       `json:"turns"`
+	LastAt      time.Time `json:"last_at"`
+}
+
+// Digest is what happened between Since and Until.
+type Digest struct {
+	Since         time.Time             `json:"since"`
+	Until         time.Time             `json:"until"`
+	Memories      []memtypes.MemoryItem `json:"memories"`
+	Conversations []Conversation        `json:"conversations"`
+}
+
+// New builds the digest of the memories and history turns created in
+// [since, until). The busiest conversations are kept, most turns first.
+func New(since, until time.Time, memories []memtypes.MemoryItem, history []memtypes.HistoryItem) Digest {
+	d := Digest{Since: since, Until: until}
+	for _, m := range memories {
+		if !m.CreatedAt.Before(since) && m.CreatedAt.Before(until) {
+			d.Memories = append(d.Memories, m)
+		}
+	}
+	sort.SliceStable(d.Memories, func(i, j int) bool {
+		return d.Memories[i].CreatedAt.Before(d.Memories[j].CreatedAt)
+	})
+
+	bySession := make(map[string]*Conversation)
+	var order []string
+	for _, turn := range history {
+		if turn.CreatedAt.Before(since) || !turn.CreatedAt.Before(until) {
+			continue
+		}
+		c, ok := bySession[turn.SessionID]
+		if !ok {
+			c = &Conversation{SessionID: turn.SessionID}
+			bySession[turn.SessionID] = c
+			order = append(order, turn.SessionID)
+		}
+		if c.FirstPrompt == "" && turn.Role == "user" {
+			c.FirstPrompt = oneLine(turn.Content, maxPromptChars)
+		}
+		c.Turns++
+		if turn.CreatedAt.After(c.LastAt) {
+			c.LastAt = turn.CreatedAt
+		}
+	}
+	for _, id := range order {
+		d.Conversations = append(d.Conversations, *bySession[id])
+	}
+	sort.SliceStable(d.Conversations, func(i, j int) bool {
+		if d.Conversations[i].Turns != d.Conversations[j].Turns {
+			return d.Conversations[i].Turns > d.Conversations[j].Turns
+		}
+		return d.Conversations[i].LastAt.After(d.Conversations[j].LastAt)
+	})
+	if len(d.Conversations) > maxConversations {
+		d.Conversations = d.Conversations[:maxConversations]
+	}
+	return d
+}
+
+// Empty reports whether nothing was learned or discussed in the period.
+func (d Digest) Empty() bool {
+	return len(d.Memories) == 0 && len(d.Conversations) == 0
+}
+
+// Subject is the title of the digest, naming its period.
+func (d Digest) Subject() string {
+	return fmt.Sprintf("gomor digest: %s to %s", d.Since.Format(dateLayout), d.Until.Format(dateLayout))
+}
+
+// Prompt asks the tool model to review the period.
+func (d Digest) Prompt() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Write a short review of what an assistant learned about its user between %s and %s. ", d.Since.Format(dateLayout), d.Until.Format(dateLayout))
+	sb.WriteString("Use Markdown with three sections: \"Learned\" grouping the new memories by theme, ")
+	sb.WriteString("\"Conversations\" naming the notable ones in a line each, and \"To review\" listing memories waiting for approval or that look wrong or duplicated. ")
+	sb.WriteString("Leave out empty sections. Be concise and do not invent anything that is not below.\n")
+
+	sb.WriteString("\nNew memories:\n")
+	if len(d.Memories) == 0 {
+		sb.WriteString("(none)\n")
+	}
+	for _, m := range d.Memories[:min(len(d.Memories), maxMemories)] {
+		sb.WriteString("- " + memoryLine(m) + "\n")
+	}
+	if len(d.Memories) > maxMemories {
+		fmt.Fprintf(&sb, "- ... and %d more\n", len(d.Memories)-maxMemories)
+	}
+
+	sb.WriteString("\nConversations (busiest first):\n")
+	if len(d.Conversations) == 0 {
+		sb.WriteString("(none)\n")
+	}
+	for _, c := range d.Conversations {
+		fmt.Fprintf(&sb, "- %d turns, last %s: %s\n", c.Turns, c.LastAt.Format(dateLayout), c.FirstPrompt)
+	}
+	return sb.String()
+}
+
+// Plain lists the period's memories and conversations without a model.
+func (d Digest) Plain() string {
+	var sb strings.Builder
+	sb.WriteString("# " + d.Subject() + "\n")
+	if d.Empty() {
+		sb.WriteString("\nNo new memories or conversations.\n")
+		return sb.String()
+	}
+	if len(d.Memories) > 0 {
+		fmt.Fprintf(&sb, "\n## Learned (%d)\n\n", len(d.Memories))
+		for _, m := range d.Memories {
+			sb.WriteString("- " + memoryLine(m) + "\n")
+		}
+	}
+	if len(d.Conversations) > 0 {
+		sb.WriteString("\n## Conversations\n\n")
+		for _, c := range d.Conversations {
+			fmt.Fprintf(&sb, "- %s (%d turns, session %s)\n", c.FirstPrompt, c.Turns, c.SessionID)
+		}
+	}
+	return sb.String()
+}
+
+// memoryLine describes a memory on one line, with how it was saved.
+func memoryLine(m memtypes.MemoryItem) string {
+	line := oneLine(m.Text, 200)
+	var notes []string
+	if m.Kind != "" {
+		notes = append(notes, string(m.Kind))
+	}
+	if m.Source != "" {
+		notes = append(notes, string(m.Source))
+	}
+	if m.PendingReview {
+		notes = append(notes, "pending review")
+	}
+	if len(m.Tags) > 0 {
+		notes = append(notes, "tags: "+strings.Join(m.Tags, ", "))
+	}
+	if len(notes) > 0 {
+		line += " (" + strings.Join(notes, "; ") + ")"
+	}
+	return line
+}
+
+func oneLine(text string, limit int) string {
+	line := strings.Join(strings.Fields(text), " ")
+	if runes := []rune(line); len(runes) > limit {
+		return string(runes[:limit-1]) + "…"
+	}
+	return line
+}
+
+// Summarize asks the tool model to write the digest, titled with its subject.
+func Summarize(ctx context.Context, queryClient client.QueryClient, model types.Model, d Digest) (string, error) {
+	if queryClient == nil {
+		return "", utils.ConfigErrorf("tool model not configured")
+	}
+
+	stream, err := queryClient.ChatStream(ctx, model, d.Prompt())
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+
+	var sb strings.Builder
+	for stream.Next() {
+		sb.WriteString(stream.GetChunk())
+	}
+	if err := stream.Err(); err != nil {
+		return "", err
+	}
+	summary := strings.TrimSpace(sb.String())
+	if summary == "" {
+		return "", fmt.Errorf("the tool model returned an empty digest")
+	}
+	return "# " + d.Subject() + "\n\n" + summary + "\n", nil
+}
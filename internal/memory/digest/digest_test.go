@@ -0,0 +1,151 @@
+package digest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+type fakeStream struct {
+	chunks []string
+	idx    int
+}
+
+func (s *fakeStream) Next() bool {
+	if s.idx < len(s.chunks) {
+		s.idx++
+		return true
+	}
+	return false
+}
+
+func (s *fakeStream) GetChunk() string { return s.chunks[s.idx-1] }
+func (s *fakeStream) Err() error       { return nil }
+func (s *fakeStream) Close() error     { return nil }
+
+// fakeQueryClient answers with answer and records the prompt.
+type fakeQueryClient struct {
+	answer string
+	prompt string
+}
+
+func (c *fakeQueryClient) ChatStream(_ context.Context, _ types.Model, query string) (client.StreamResponse, error) {
+	c.prompt = query
+	return &fakeStream{chunks: []string{c.answer}}, nil
+}
+
+func (c *fakeQueryClient) ChatStreamWithContext(ctx context.Context, model types.Model, _, query string) (client.StreamResponse, error) {
+	return c.ChatStream(ctx, model, query)
+}
+
+func (c *fakeQueryClient) ListModels(context.Context) ([]string, error) { return nil, nil }
+
+func testDigest() Digest {
+	until := time.Date(2026, 3, 8, 9, 0, 0, 0, time.UTC)
+	since := until.AddDate(0, 0, -7)
+	memories := []memtypes.MemoryItem{
+		{ID: "old", Text: "before the period", CreatedAt: since.Add(-time.Hour)},
+		{ID: "m2", Text: "Moved the Atlas launch to March", Kind: memtypes.KindFact, Source: memtypes.SourceExtracted, PendingReview: true, CreatedAt: since.Add(48 * time.Hour)},
+		{ID: "m1", Text: "Prefers table-driven tests", Kind: memtypes.KindPreference, Source: memtypes.SourceExplicit, Tags: []string{"go"}, CreatedAt: since.Add(24 * time.Hour)},
+	}
+	history := []memtypes.HistoryItem{
+		{SessionID: "short", Role: "user", Content: "quick question", CreatedAt: since.Add(time.Hour)},
+		{SessionID: "long", Role: "user", Content: "plan the\nAtlas launch", CreatedAt: since.Add(2 * time.Hour)},
+		{SessionID: "long", Role: "assistant", Content: "sure", CreatedAt: since.Add(3 * time.Hour)},
+		{SessionID: "long", Role: "user", Content: "and the docs?", CreatedAt: since.Add(4 * time.Hour)},
+	}
+	return New(since, until, memories, history)
+}
+
+func TestNewKeepsThePeriodBusiestFirst(t *testing.T) {
+	d := testDigest()
+	if len(d.Memories) != 2 || d.Memories[0].ID != "m1" || d.Memories[1].ID != "m2" {
+		t.Fatalf("expected the period's memories oldest first, got %+v", d.Memories)
+	}
+	if len(d.Conversations) != 2 || d.Conversations[0].SessionID != "long" || d.Conversations[0].Turns != 3 {
+		t.Fatalf("expected the busiest conversation first, got %+v", d.Conversations)
+	}
+	if d.Conversations[0].FirstPrompt != "plan the Atlas launch" {
+		t.Fatalf("expected the opening prompt on one line, got %q", d.Conversations[0].FirstPrompt)
+	}
+}
+
+func TestSummarizeTitlesTheToolModelReview(t *testing.T) {
+	d := testDigest()
+	qc := &fakeQueryClient{answer: "## Learned\n- Testing style\n"}
+	text, err := Summarize(context.Background(), qc, types.Model{}, d)
+	if err != nil {
+		t.Fatalf("Summarize: %v", err)
+	}
+	if text != "# gomor digest: 2026-03-01 to 2026-03-08\n\n## Learned\n- Testing style\n" {
+		t.Fatalf("unexpected digest %q", text)
+	}
+	for _, want := range []string{
+		"- Moved the Atlas launch to March (fact; extracted; pending review)",
+		"- Prefers table-driven tests (preference; explicit; tags: go)",
+		"- 3 turns, last 2026-03-01: plan the Atlas launch",
+	} {
+		if !strings.Contains(qc.prompt, want) {
+			t.Fatalf("expected prompt to contain %q, got:\n%s", want, qc.prompt)
+		}
+	}
+
+	if _, err := Summarize(context.Background(), nil, types.Model{}, d); err == nil {
+		t.Fatal("expected an error without a tool model")
+	}
+}
+
+func TestPlainListsThePeriod(t *testing.T) {
+	plain := testDigest().Plain()
+	if !strings.Contains(plain, "## Learned (2)") || !strings.Contains(plain, "- plan the Atlas launch (3 turns, session long)") {
+		t.Fatalf("unexpected plain digest:\n%s", plain)
+	}
+	if empty := New(time.Now(), time.Now(), nil, nil); !strings.Contains(empty.Plain(), "No new memories or conversations.") {
+		t.Fatalf("unexpected empty digest:\n%s", empty.Plain())
+	}
+}
+
+func TestSendPostsWebhookAndMails(t *testing.T) {
+	var posted map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&posted)
+	}))
+	defer server.Close()
+
+	oldSendMail := sendMail
+	defer func() { sendMail = oldSendMail }()
+	var mailAddr string
+	var mail []byte
+	sendMail = func(addr string, _ smtp.Auth, _ string, _ []string, msg []byte) error {
+		mailAddr, mail = addr, msg
+		return nil
+	}
+
+	config := utils.DigestConfig{
+		WebhookURL: server.URL,
+		SMTP:       utils.SMTPConfig{Host: "smtp.example.com", From: "gomor@example.com", To: []string{"me@example.com"}},
+	}
+	if err := Send(context.Background(), config, "weekly", "line one\nline two"); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if posted["subject"] != "weekly" || posted["text"] != "line one\nline two" {
+		t.Fatalf("unexpected webhook payload: %v", posted)
+	}
+	if mailAddr != "smtp.example.com:587" || !strings.Contains(string(mail), "Subject: weekly\r\n") || !strings.HasSuffix(string(mail), "line one\r\nline two") {
+		t.Fatalf("unexpected mail to %s:\n%q", mailAddr, mail)
+	}
+
+	if err := Send(context.Background(), utils.DigestConfig{}, "weekly", "text"); err == nil {
+		t.Fatal("expected an error without a destination")
+	}
+}
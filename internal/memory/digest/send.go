@@ -0,0 +1,123 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// defaultSMTPPort is the mail submission port.
+const defaultSMTPPort = 587
+
+// webhookTimeout bounds the webhook request.
+const webhookTimeout = 15 * time.Second
+
+// sendMail is smtp.SendMail, replaced in tests.
+var sendMail = smtp.SendMail
+
+// Destinations names where config delivers digests, e.g. "webhook, email".
+func Destinations(config utils.DigestConfig) []string {
+	var names []string
+	if config.WebhookURL != "" {
+		names = append(names, "webhook")
+	}
+	if config.SMTP.Host != "" {
+		names = append(names, "email")
+	}
+	return names
+}
+
+// Send delivers a digest to the webhook and by email, whichever are
+// configured. Both are attempted even if one fails.
+func Send(ctx context.Context, config utils.DigestConfig, subject, text string) error {
+	if len(Destinations(config)) == 0 {
+		return fmt.Errorf("no digest destination configured: set digest.webhook_url or digest.smtp in the config")
+	}
+
+	var errs []string
+	if config.WebhookURL != "" {
+		if err := postWebhook(ctx, config.WebhookURL, subject, text); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if config.SMTP.Host != "" {
+		if err := sendEmail(config.SMTP, subject, text); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to send digest: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// postWebhook posts {"subject", "text"}; chat webhooks such as Slack's show
+// the text field.
+func postWebhook(ctx context.Context, url, subject, text string) error {
+	body, err := json.Marshal(map[string]string{"subject": subject, "text": text})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook: %s %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+func sendEmail(config utils.SMTPConfig, subject, text string) error {
+	if config.From == "" || len(config.To) == 0 {
+		return fmt.Errorf("email: digest.smtp needs from and to")
+	}
+	port := config.Port
+	if port == 0 {
+		port = defaultSMTPPort
+	}
+	addr := net.JoinHostPort(config.Host, strconv.Itoa(port))
+
+	var auth smtp.Auth
+	if config.Username != "" {
+		auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	}
+	if err := sendMail(addr, auth, config.From, config.To, message(config, subject, text)); err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+	return nil
+}
+
+// message is a plain-text email with CRLF line endings.
+func message(config utils.SMTPConfig, subject, text string) []byte {
+	var sb strings.Builder
+	sb.WriteString("From: " + config.From + "\r\n")
+	sb.WriteString("To: " + strings.Join(config.To, ", ") + "\r\n")
+	sb.WriteString("Subject: " + subject + "\r\n")
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	sb.WriteString("\r\n")
+	sb.WriteString(strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(sb.String())
+}
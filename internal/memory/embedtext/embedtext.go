@@ -0,0 +1,73 @@
+// Package embedtext renders the text embedded for a memory. By default that is
+// the memory's text alone; memory.embedding_template can add its tags, kind,
+// or the title of the document a chunk came from, which helps short memories
+// with informative tags match the queries they answer.
+package embedtext
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+// Data is what an embedding template can reference.
+type Data struct {
+	Text   string
+	Tags   string // comma-separated, e.g. "go, testing"
+	Kind   string
+	Source string
+	// Title is the file name, without extension, of the document an ingested
+	// chunk came from; empty for other memories.
+	Title string
+}
+
+// Template renders memories for embedding. A nil Template embeds their text
+// alone.
+type Template struct {
+	tmpl *template.Template
+}
+
+// Parse parses a Go text/template over Data. An empty text returns a nil
+// Template.
+func Parse(text string) (*Template, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New("embedding_template").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid memory embedding_template: %w", err)
+	}
+	// Fields that Data lacks only fail on execution, so try it once here.
+	if err := tmpl.Execute(&strings.Builder{}, Data{}); err != nil {
+		return nil, fmt.Errorf("invalid memory embedding_template: %w", err)
+	}
+	return &Template{tmpl: tmpl}, nil
+}
+
+// Render returns the text to embed for item. It falls back to item.Text when
+// t is nil or renders nothing.
+func (t *Template) Render(item memtypes.MemoryItem) string {
+	if t == nil {
+		return item.Text
+	}
+
+	data := Data{
+		Text:   item.Text,
+		Tags:   strings.Join(item.Tags, ", "),
+		Kind:   string(item.Kind),
+		Source: string(item.Source),
+	}
+	if item.SourcePath != "" {
+		base := filepath.Base(item.SourcePath)
+		data.Title = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+
+	var b strings.Builder
+	if err := t.tmpl.Execute(&b, data); err != nil || strings.TrimSpace(b.String()) == "" {
+		return item.Text
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,47 @@
+package embedtext
+
+import (
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+func TestRender(t *testing.T) {
+	tmpl, err := Parse("{{.Text}}\n{{if .Tags}}Tags: {{.Tags}}{{end}}")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	tagged := memtypes.MemoryItem{Text: "prefers tabs", Tags: []string{"go", "style"}}
+	if got, want := tmpl.Render(tagged), "prefers tabs\nTags: go, style"; got != want {
+		t.Fatalf("Render() = %q, want %q", got, want)
+	}
+	if got := tmpl.Render(memtypes.MemoryItem{Text: "prefers tabs"}); got != "prefers tabs" {
+		t.Fatalf("Render() of an untagged memory = %q", got)
+	}
+
+	doc, err := Parse("{{.Title}}: {{.Text}}")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	chunk := memtypes.MemoryItem{Text: "Deploys need two approvals.", SourcePath: "/notes/release-process.md"}
+	if got, want := doc.Render(chunk), "release-process: Deploys need two approvals."; got != want {
+		t.Fatalf("Render() = %q, want %q", got, want)
+	}
+
+	var none *Template
+	if got := none.Render(tagged); got != "prefers tabs" {
+		t.Fatalf("nil template rendered %q", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	if tmpl, err := Parse("  "); tmpl != nil || err != nil {
+		t.Fatalf("expected an empty template to parse as nil, got %v (%v)", tmpl, err)
+	}
+	for _, bad := range []string{"{{.Text", "{{.Body}}"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q): expected an error", bad)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package entities
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// Kinds lists the entity kinds the extractor is allowed to return.
+var Kinds = []string{"person", "project", "tool", "organization", "place"}
+
+// Extract asks tool_model for the named entities mentioned in text.
+func Extract(ctx context.Context, queryClient client.QueryClient, model types.Model, text string) ([]memtypes.Entity, error) {
+	if queryClient == nil {
+		return nil, utils.ConfigErrorf("tool model not configured")
+	}
+
+	prompt := fmt.Sprintf(`List the named entities mentioned in this note.
+Only include specific people, projects, tools, organizations, or places.
+
+Note: %s
+
+Respond with one entity per line in this exact format (no other text):
+KIND: name
+where KIND is one of: %s
+If there are no entities, respond with NONE.`, text, strings.Join(Kinds, ", "))
+
+	stream, err := queryClient.ChatStream(ctx, model, prompt)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	var sb strings.Builder
+	for stream.Next() {
+		sb.WriteString(stream.GetChunk())
+	}
+	if err := stream.Err(); err != nil {
+		return nil, err
+	}
+
+	return parseEntities(sb.String()), nil
+}
+
+// Link stores the given entities and links them to a memory.
+func Link(s *store.Store, memoryID string, entities []memtypes.Entity) error {
+	for _, e := range entities {
+		entity, err := s.UpsertEntity(e.Name, e.Kind)
+		if err != nil {
+			return err
+		}
+		if err := s.LinkMemoryEntity(memoryID, entity.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MentionedIn returns the known entities whose names appear in query as whole words.
+func MentionedIn(query string, known []memtypes.Entity) []memtypes.Entity {
+	normalized := " " + strings.Join(strings.FieldsFunc(strings.ToLower(query), isSeparator), " ") + " "
+
+	var mentioned []memtypes.Entity
+	for _, e := range known {
+		name := strings.Join(strings.FieldsFunc(strings.ToLower(e.Name), isSeparator), " ")
+		if name == "" {
+			continue
+		}
+		if strings.Contains(normalized, " "+name+" ") {
+			mentioned = append(mentioned, e)
+		}
+	}
+	return mentioned
+}
+
+// parseEntities reads "KIND: name" lines, skipping unknown kinds and duplicates.
+func parseEntities(response string) []memtypes.Entity {
+	var result []memtypes.Entity
+	seen := make(map[string]bool)
+
+	for _, line := range strings.Split(response, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "-*"))
+		kind, name, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		name = strings.Trim(strings.TrimSpace(name), "\"'")
+		if name == "" || !isKnownKind(kind) {
+			continue
+		}
+
+		key := kind + "\x00" + store.NormalizeEntityName(name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, memtypes.Entity{Name: name, Kind: kind})
+	}
+
+	return result
+}
+
+func isKnownKind(kind string) bool {
+	for _, k := range Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// isSeparator splits names and queries into comparable words. '+' and '#' are
+// kept so that "C++" and "C#" don't collapse to "c".
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#'
+}
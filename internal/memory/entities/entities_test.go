@@ -0,0 +1,54 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+func TestParseEntities(t *testing.T) {
+	response := `PROJECT: Atlas
+- person: Jane Doe
+tool: "kubectl"
+feeling: happy
+project: atlas
+NONE`
+
+	got := parseEntities(response)
+	want := []memtypes.Entity{
+		{Name: "Atlas", Kind: "project"},
+		{Name: "Jane Doe", Kind: "person"},
+		{Name: "kubectl", Kind: "tool"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d entities, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("entity %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestMentionedIn(t *testing.T) {
+	known := []memtypes.Entity{
+		{ID: "1", Name: "Atlas", Kind: "project"},
+		{ID: "2", Name: "Jane Doe", Kind: "person"},
+		{ID: "3", Name: "C++", Kind: "tool"},
+		{ID: "4", Name: "Go", Kind: "tool"},
+	}
+
+	got := MentionedIn("Show everything about project Atlas, and what Jane  Doe said about C++.", known)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 mentioned entities, got %+v", got)
+	}
+	for i, id := range []string{"1", "2", "3"} {
+		if got[i].ID != id {
+			t.Fatalf("mention %d: expected id %s, got %+v", i, id, got[i])
+		}
+	}
+
+	if got := MentionedIn("going forward", known); len(got) != 0 {
+		t.Fatalf("expected no partial-word matches, got %+v", got)
+	}
+}
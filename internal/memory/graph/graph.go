@@ -0,0 +1,184 @@
+// Package graph turns stored memories into a graph of similarity edges and
+// tag clusters for visualization in Graphviz or a web viewer.
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/memutils"
+)
+
+// Output formats.
+const (
+	FormatDOT  = "dot"
+	FormatJSON = "json"
+)
+
+// DefaultThreshold is the cosine similarity above which two memories are linked.
+const DefaultThreshold = 0.75
+
+// labelLength bounds node labels so large memories stay readable.
+const labelLength = 60
+
+// Node is a memory in the graph.
+type Node struct {
+	ID         string   `json:"id"`
+	Label      string   `json:"label"`
+	Text       string   `json:"text"`
+	Kind       string   `json:"kind"`
+	Tags       []string `json:"tags,omitempty"`
+	Pinned     bool     `json:"pinned,omitempty"`
+	Suppressed bool     `json:"suppressed,omitempty"`
+}
+
+// Edge links two memories whose embeddings are similar.
+type Edge struct {
+	Source     string  `json:"source"`
+	Target     string  `json:"target"`
+	Similarity float64 `json:"similarity"`
+}
+
+// Cluster groups the memories that carry a tag.
+type Cluster struct {
+	Tag     string   `json:"tag"`
+	Members []string `json:"members"`
+}
+
+// Graph is the memory base as nodes, similarity edges, and tag clusters.
+type Graph struct {
+	Threshold float64   `json:"threshold"`
+	Nodes     []Node    `json:"nodes"`
+	Edges     []Edge    `json:"edges"`
+	Clusters  []Cluster `json:"clusters"`
+}
+
+// Build links every pair of memories whose embedding similarity is at least
+// threshold. Memories embedded by different models are never compared, and
+// memories without an embedding have no edges.
+func Build(memories []memtypes.MemoryItem, threshold float64) *Graph {
+	g := &Graph{Threshold: threshold, Nodes: []Node{}, Edges: []Edge{}, Clusters: []Cluster{}}
+
+	members := make(map[string][]string)
+	for _, m := range memories {
+		g.Nodes = append(g.Nodes, Node{
+			ID:         m.ID,
+			Label:      label(m.Text),
+			Text:       m.Text,
+			Kind:       string(m.Kind),
+			Tags:       m.Tags,
+			Pinned:     m.Pinned,
+			Suppressed: m.Suppressed,
+		})
+		for _, tag := range m.Tags {
+			members[tag] = append(members[tag], m.ID)
+		}
+	}
+
+	for i := range memories {
+		for j := i + 1; j < len(memories); j++ {
+			a, b := memories[i], memories[j]
+			if len(a.Embedding) == 0 || len(a.Embedding) != len(b.Embedding) || a.ModelID != b.ModelID {
+				continue
+			}
+			// Embeddings are stored normalized, so the dot product is the cosine.
+			if sim := memutils.DotProduct(a.Embedding, b.Embedding); sim >= threshold {
+				g.Edges = append(g.Edges, Edge{Source: a.ID, Target: b.ID, Similarity: sim})
+			}
+		}
+	}
+	sort.SliceStable(g.Edges, func(i, j int) bool { return g.Edges[i].Similarity > g.Edges[j].Similarity })
+
+	tags := make([]string, 0, len(members))
+	for tag := range members {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		g.Clusters = append(g.Clusters, Cluster{Tag: tag, Members: members[tag]})
+	}
+
+	return g
+}
+
+// WriteJSON writes g as indented JSON.
+func WriteJSON(w io.Writer, g *Graph) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(g)
+}
+
+// WriteDOT writes g in Graphviz DOT. Clusters cannot overlap in DOT, so each
+// memory is drawn in the cluster of its first tag.
+func WriteDOT(w io.Writer, g *Graph) error {
+	var sb strings.Builder
+	sb.WriteString("graph memories {\n")
+	sb.WriteString("  node [shape=box, style=rounded];\n")
+
+	drawn := make(map[string]bool)
+	byID := make(map[string]Node, len(g.Nodes))
+	for _, n := range g.Nodes {
+		byID[n.ID] = n
+	}
+	for i, c := range g.Clusters {
+		var inCluster []Node
+		for _, id := range c.Members {
+			if !drawn[id] {
+				drawn[id] = true
+				inCluster = append(inCluster, byID[id])
+			}
+		}
+		if len(inCluster) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "  subgraph cluster_%d {\n    label=%s;\n", i, quote(c.Tag))
+		for _, n := range inCluster {
+			sb.WriteString("    " + dotNode(n) + "\n")
+		}
+		sb.WriteString("  }\n")
+	}
+	for _, n := range g.Nodes {
+		if !drawn[n.ID] {
+			sb.WriteString("  " + dotNode(n) + "\n")
+		}
+	}
+
+	for _, e := range g.Edges {
+		fmt.Fprintf(&sb, "  %s -- %s [label=\"%.2f\"];\n", quote(e.Source), quote(e.Target), e.Similarity)
+	}
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func dotNode(n Node) string {
+	attrs := "label=" + quote(n.Label) + ", tooltip=" + quote(n.Text)
+	if n.Pinned {
+		attrs += ", penwidth=2"
+	}
+	if n.Suppressed {
+		attrs += ", style=\"rounded,dashed\", fontcolor=gray"
+	}
+	return quote(n.ID) + " [" + attrs + "];"
+}
+
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+func label(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= labelLength {
+		return text
+	}
+	return string(runes[:labelLength-1]) + "…"
+}
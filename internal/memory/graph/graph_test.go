@@ -0,0 +1,76 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+func testMemories() []memtypes.MemoryItem {
+	return []memtypes.MemoryItem{
+		{ID: "a", Text: "Deploys go through Argo", Tags: []string{"ops"}, ModelID: "m", Embedding: []float32{1, 0}},
+		{ID: "b", Text: "Argo syncs \"prod\" hourly", Tags: []string{"ops", "prod"}, ModelID: "m", Embedding: []float32{0.8, 0.6}},
+		{ID: "c", Text: "Prefers tabs", ModelID: "m", Embedding: []float32{0, 1}},
+		{ID: "d", Text: "Other model", ModelID: "other", Embedding: []float32{1, 0}},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	g := Build(testMemories(), 0.75)
+
+	if len(g.Nodes) != 4 {
+		t.Fatalf("expected 4 nodes, got %d", len(g.Nodes))
+	}
+	// a-b is 0.8; b-c is 0.6; a-d share a vector but not a model.
+	if len(g.Edges) != 1 || g.Edges[0].Source != "a" || g.Edges[0].Target != "b" {
+		t.Fatalf("unexpected edges: %+v", g.Edges)
+	}
+	if len(g.Clusters) != 2 || g.Clusters[0].Tag != "ops" || len(g.Clusters[0].Members) != 2 || g.Clusters[1].Tag != "prod" {
+		t.Fatalf("unexpected clusters: %+v", g.Clusters)
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteDOT(&buf, Build(testMemories(), 0.75)); err != nil {
+		t.Fatalf("WriteDOT: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"graph memories {",
+		`subgraph cluster_0 {`,
+		`label="ops";`,
+		`"b" [label="Argo syncs \"prod\" hourly"`,
+		`"a" -- "b" [label="0.80"];`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("DOT output missing %q:\n%s", want, out)
+		}
+	}
+	// b is drawn once, in its first tag's cluster, so the prod cluster is empty.
+	if strings.Count(out, `tooltip="Argo syncs`) != 1 || strings.Contains(out, `label="prod";`) {
+		t.Fatalf("expected b only in the ops cluster:\n%s", out)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, Build(testMemories(), 0.5)); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+
+	var g Graph
+	if err := json.Unmarshal(buf.Bytes(), &g); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if g.Threshold != 0.5 || len(g.Nodes) != 4 || len(g.Edges) != 2 {
+		t.Fatalf("unexpected graph: %+v", g)
+	}
+	if g.Edges[0].Similarity < g.Edges[1].Similarity {
+		t.Fatalf("expected edges sorted by similarity: %+v", g.Edges)
+	}
+}
@@ -0,0 +1,73 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+// ParseChatGPT reads ChatGPT saved memories. ChatGPT has no structured memory
+// export, so this accepts what users actually end up with:
+//   - text copied from Settings > Personalization > Manage memories, one memory per line
+//   - a JSON array of strings
+//   - a JSON array (or {"memories": [...]}) of objects with a content/text/memory
+//     field and optional created_at/updated_at
+func ParseChatGPT(data []byte) ([]memtypes.MemoryItem, error) {
+	trimmed := strings.TrimSpace(string(data))
+	if trimmed == "" {
+		return nil, nil
+	}
+
+	if trimmed[0] != '[' && trimmed[0] != '{' {
+		var items []memtypes.MemoryItem
+		for _, line := range splitLines(trimmed) {
+			items = append(items, chatGPTItem(line, nil))
+		}
+		return items, nil
+	}
+
+	var raw any
+	if err := json.Unmarshal([]byte(trimmed), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse ChatGPT export: %w", err)
+	}
+	if obj, ok := raw.(map[string]any); ok {
+		raw = obj["memories"]
+	}
+	entries, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("unrecognized ChatGPT export: expected a list of memories")
+	}
+
+	var items []memtypes.MemoryItem
+	for _, entry := range entries {
+		switch e := entry.(type) {
+		case string:
+			if text := strings.TrimSpace(e); text != "" {
+				items = append(items, chatGPTItem(text, nil))
+			}
+		case map[string]any:
+			if text := firstString(e, "content", "text", "memory"); text != "" {
+				items = append(items, chatGPTItem(text, e))
+			}
+		}
+	}
+	return items, nil
+}
+
+func chatGPTItem(text string, obj map[string]any) memtypes.MemoryItem {
+	item := memtypes.MemoryItem{
+		Text:   text,
+		Tags:   []string{"chatgpt"},
+		Source: memtypes.SourceImported,
+		Kind:   memtypes.KindFact,
+	}
+	if obj != nil {
+		item.CreatedAt = parseTimestamp(obj["created_at"])
+		if item.CreatedAt.IsZero() {
+			item.CreatedAt = parseTimestamp(obj["updated_at"])
+		}
+	}
+	return item
+}
@@ -0,0 +1,126 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/austiecodes/gomor/internal/memory/ingest"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+// Chunking used for Claude project knowledge documents.
+const (
+	claudeDocChunkSize    = 1200
+	claudeDocChunkOverlap = 200
+)
+
+// ParseClaude reads the JSON files from a Claude data export:
+//   - projects.json: project instructions become preferences and project
+//     knowledge documents are chunked into document-chunk memories
+//   - memories.json: each line of the conversation and project memory
+//     summaries becomes a fact
+func ParseClaude(data []byte) ([]memtypes.MemoryItem, error) {
+	var entries []map[string]any
+	if err := json.Unmarshal(data, &entries); err != nil {
+		var single map[string]any
+		if err := json.Unmarshal(data, &single); err != nil {
+			return nil, fmt.Errorf("failed to parse Claude export: %w", err)
+		}
+		entries = []map[string]any{single}
+	}
+
+	var items []memtypes.MemoryItem
+	for _, entry := range entries {
+		switch {
+		case entry["docs"] != nil || entry["prompt_template"] != nil:
+			items = append(items, claudeProjectItems(entry)...)
+		case entry["conversations_memory"] != nil || entry["project_memories"] != nil:
+			items = append(items, claudeMemoryItems(entry)...)
+		}
+	}
+	if len(entries) > 0 && len(items) == 0 {
+		return nil, fmt.Errorf("unrecognized Claude export: expected projects.json or memories.json")
+	}
+	return items, nil
+}
+
+func claudeProjectItems(project map[string]any) []memtypes.MemoryItem {
+	name := firstString(project, "name")
+	tags := []string{"claude"}
+	if name != "" {
+		tags = append(tags, "project:"+name)
+	}
+	createdAt := parseTimestamp(project["created_at"])
+
+	var items []memtypes.MemoryItem
+	if instructions := firstString(project, "prompt_template"); instructions != "" {
+		items = append(items, memtypes.MemoryItem{
+			Text:      instructions,
+			Tags:      tags,
+			Source:    memtypes.SourceImported,
+			Kind:      memtypes.KindPreference,
+			CreatedAt: createdAt,
+		})
+	}
+
+	docs, _ := project["docs"].([]any)
+	for _, d := range docs {
+		doc, ok := d.(map[string]any)
+		if !ok {
+			continue
+		}
+		content := firstString(doc, "content")
+		if content == "" {
+			continue
+		}
+		path := fmt.Sprintf("claude-project:%s/%s", name, firstString(doc, "filename"))
+		docCreatedAt := parseTimestamp(doc["created_at"])
+		if docCreatedAt.IsZero() {
+			docCreatedAt = createdAt
+		}
+		for i, chunk := range ingest.Chunk(content, claudeDocChunkSize, claudeDocChunkOverlap) {
+			items = append(items, memtypes.MemoryItem{
+				Text:       chunk,
+				Tags:       tags,
+				Source:     memtypes.SourceDocument,
+				Kind:       memtypes.KindDocumentChunk,
+				CreatedAt:  docCreatedAt,
+				SourcePath: path,
+				ChunkIndex: i,
+			})
+		}
+	}
+	return items
+}
+
+func claudeMemoryItems(entry map[string]any) []memtypes.MemoryItem {
+	var items []memtypes.MemoryItem
+	add := func(summary string, tags []string) {
+		for _, line := range splitLines(summary) {
+			items = append(items, memtypes.MemoryItem{
+				Text:   line,
+				Tags:   tags,
+				Source: memtypes.SourceImported,
+				Kind:   memtypes.KindFact,
+			})
+		}
+	}
+
+	if summary, ok := entry["conversations_memory"].(string); ok {
+		add(summary, []string{"claude"})
+	}
+	if projects, ok := entry["project_memories"].(map[string]any); ok {
+		projectIDs := make([]string, 0, len(projects))
+		for projectID := range projects {
+			projectIDs = append(projectIDs, projectID)
+		}
+		sort.Strings(projectIDs)
+		for _, projectID := range projectIDs {
+			if s, ok := projects[projectID].(string); ok {
+				add(s, []string{"claude", "project:" + projectID})
+			}
+		}
+	}
+	return items
+}
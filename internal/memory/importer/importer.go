@@ -0,0 +1,157 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/interop"
+	"github.com/austiecodes/gomor/internal/memory/embedtext"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/memutils"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+// Format names a supported export format.
+type Format string
+
+const (
+	FormatChatGPT Format = "chatgpt"
+	FormatClaude  Format = "claude"
+	FormatMem0    Format = "mem0"
+	FormatLetta   Format = "letta"
+)
+
+// Parse converts an export file's contents into memory items (without embeddings).
+func Parse(format Format, data []byte) ([]memtypes.MemoryItem, error) {
+	switch format {
+	case FormatChatGPT:
+		return ParseChatGPT(data)
+	case FormatClaude:
+		return ParseClaude(data)
+	case FormatMem0:
+		return interop.FromMem0(data)
+	case FormatLetta:
+		return interop.FromLetta(data)
+	default:
+		return nil, fmt.Errorf("unknown import format %q (valid: chatgpt, claude, mem0, letta)", format)
+	}
+}
+
+// Result reports the outcome of an import.
+type Result struct {
+	Imported   int  `json:"imported"`
+	Duplicates int  `json:"duplicates"`
+	Pending    bool `json:"pending,omitempty"` // embeddings were queued instead of computed
+}
+
+// Importer embeds and stores parsed items, skipping ones already in memory.
+type Importer struct {
+	store           *store.Store
+	embeddingClient client.EmbeddingClient
+	model           types.Model
+	template        *embedtext.Template
+	batchSize       int
+}
+
+// NewImporter creates an importer that embeds items with the given model,
+// rendered with tmpl.
+func NewImporter(s *store.Store, embeddingClient client.EmbeddingClient, model types.Model, tmpl *embedtext.Template, batchSize int) *Importer {
+	if batchSize <= 0 {
+		batchSize = 32
+	}
+	return &Importer{
+		store:           s,
+		embeddingClient: embeddingClient,
+		model:           model,
+		template:        tmpl,
+		batchSize:       batchSize,
+	}
+}
+
+// Import stores items whose normalized text isn't already in memory. If the
+// embedding provider fails, items are still stored and their embeddings are
+// queued for the background worker.
+func (im *Importer) Import(ctx context.Context, items []memtypes.MemoryItem, tags []string) (Result, error) {
+	var result Result
+
+	existing, err := im.store.GetAllMemories()
+	if err != nil {
+		return result, err
+	}
+	seen := make(map[string]bool, len(existing))
+	for _, m := range existing {
+		seen[memutils.NormalizeText(m.Text)] = true
+	}
+
+	var fresh []memtypes.MemoryItem
+	for _, item := range items {
+		key := memutils.NormalizeText(item.Text)
+		if key == "" {
+			continue
+		}
+		if seen[key] {
+			result.Duplicates++
+			continue
+		}
+		seen[key] = true
+		item.Tags = mergeTags(item.Tags, tags)
+		fresh = append(fresh, item)
+	}
+
+	for start := 0; start < len(fresh); start += im.batchSize {
+		batch := fresh[start:min(start+im.batchSize, len(fresh))]
+
+		texts := make([]string, len(batch))
+		for i, item := range batch {
+			texts[i] = im.template.Render(item)
+		}
+		embeddings, embedErr := im.embeddingClient.EmbedBatch(ctx, im.model, texts)
+		if embedErr == nil && len(embeddings) != len(batch) {
+			embedErr = fmt.Errorf("embedding provider returned %d vectors for %d items", len(embeddings), len(batch))
+		}
+		if embedErr != nil {
+			result.Pending = true
+		}
+
+		for i := range batch {
+			item := batch[i]
+			item.Provider = im.model.Provider
+			item.ModelID = im.model.ModelID
+			if embedErr == nil {
+				item.Embedding = memutils.NormalizeVector(embeddings[i])
+				item.Dim = len(item.Embedding)
+			}
+
+			if err := im.store.SaveMemory(&item); err != nil {
+				return result, err
+			}
+			if embedErr != nil {
+				if err := im.store.EnqueueEmbedding(memtypes.EmbeddingTargetMemory, item.ID); err != nil {
+					return result, err
+				}
+			}
+			result.Imported++
+		}
+	}
+
+	return result, nil
+}
+
+func mergeTags(base, extra []string) []string {
+	merged := append([]string(nil), base...)
+	for _, t := range extra {
+		found := false
+		for _, b := range merged {
+			if b == t {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, t)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,170 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/types"
+	_ "modernc.org/sqlite"
+)
+
+type fakeEmbeddingClient struct {
+	batches int
+}
+
+func (f *fakeEmbeddingClient) Embed(ctx context.Context, model types.Model, text string) ([]float32, error) {
+	return []float32{1, 0}, nil
+}
+
+func (f *fakeEmbeddingClient) EmbedBatch(ctx context.Context, model types.Model, texts []string) ([][]float32, error) {
+	f.batches++
+	vectors := make([][]float32, len(texts))
+	for i := range texts {
+		vectors[i] = []float32{1, 0}
+	}
+	return vectors, nil
+}
+
+func (f *fakeEmbeddingClient) Dimensions(model types.Model) int {
+	return 2
+}
+
+func newTestStore(t *testing.T) *store.Store {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+
+	memStore, err := store.NewStoreWithDB(db)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = memStore.Close()
+	})
+
+	return memStore
+}
+
+func TestParseChatGPTPlainText(t *testing.T) {
+	items, err := ParseChatGPT([]byte("- Prefers Go over Python\n\n• Lives in Berlin\n2. Has a dog named Rex\n"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	var texts []string
+	for _, item := range items {
+		texts = append(texts, item.Text)
+		if item.Source != memtypes.SourceImported || item.Tags[0] != "chatgpt" {
+			t.Fatalf("unexpected metadata: %+v", item)
+		}
+	}
+	if strings.Join(texts, "|") != "Prefers Go over Python|Lives in Berlin|Has a dog named Rex" {
+		t.Fatalf("unexpected texts: %v", texts)
+	}
+}
+
+func TestParseChatGPTJSON(t *testing.T) {
+	data := `{"memories": [
+		{"content": "Works on project Atlas", "created_at": "2024-05-01T10:00:00Z"},
+		"Prefers dark mode",
+		{"id": "no text"}
+	]}`
+
+	items, err := ParseChatGPT([]byte(data))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %+v", items)
+	}
+	if !items[0].CreatedAt.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected created_at to be preserved, got %v", items[0].CreatedAt)
+	}
+	if items[1].Text != "Prefers dark mode" {
+		t.Fatalf("unexpected second item: %+v", items[1])
+	}
+}
+
+func TestParseClaudeProjectsAndMemories(t *testing.T) {
+	projects := `[{
+		"name": "Atlas",
+		"prompt_template": "Answer in British English.",
+		"created_at": "2024-06-01T00:00:00Z",
+		"docs": [{"filename": "spec.md", "content": "The plugin API is versioned."}]
+	}]`
+
+	items, err := ParseClaude([]byte(projects))
+	if err != nil {
+		t.Fatalf("parse projects: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected instructions and one chunk, got %+v", items)
+	}
+	if items[0].Kind != memtypes.KindPreference || items[0].Tags[1] != "project:Atlas" {
+		t.Fatalf("unexpected instructions item: %+v", items[0])
+	}
+	if items[1].Kind != memtypes.KindDocumentChunk || items[1].SourcePath != "claude-project:Atlas/spec.md" {
+		t.Fatalf("unexpected document item: %+v", items[1])
+	}
+
+	memories := `[{"conversations_memory": "## Work\n- Uses Neovim\n- Deploys on Fridays", "project_memories": {"p1": "- Atlas ships in March"}}]`
+	items, err = ParseClaude([]byte(memories))
+	if err != nil {
+		t.Fatalf("parse memories: %v", err)
+	}
+	if len(items) != 3 || items[2].Tags[1] != "project:p1" {
+		t.Fatalf("unexpected memory items: %+v", items)
+	}
+
+	if _, err := ParseClaude([]byte(`[{"unrelated": true}]`)); err == nil {
+		t.Fatal("expected unrecognized export error")
+	}
+}
+
+func TestImportSkipsDuplicates(t *testing.T) {
+	memStore := newTestStore(t)
+	existing := &memtypes.MemoryItem{Text: "Prefers  GO over python", Source: memtypes.SourceExplicit, Provider: "fake", ModelID: "fake"}
+	if err := memStore.SaveMemory(existing); err != nil {
+		t.Fatalf("save memory: %v", err)
+	}
+
+	items, err := ParseChatGPT([]byte("Prefers Go over Python\nLives in Berlin\nlives in berlin\nHas a dog"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	embClient := &fakeEmbeddingClient{}
+	im := NewImporter(memStore, embClient, types.Model{Provider: "fake", ModelID: "fake-embedding"}, nil, 1)
+	result, err := im.Import(context.Background(), items, []string{"migrated"})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if result.Imported != 2 || result.Duplicates != 2 {
+		t.Fatalf("expected 2 imported and 2 duplicates, got %+v", result)
+	}
+	if embClient.batches != 2 {
+		t.Fatalf("expected one embedding batch per item, got %d", embClient.batches)
+	}
+
+	memories, err := memStore.GetAllMemories()
+	if err != nil {
+		t.Fatalf("get all memories: %v", err)
+	}
+	for _, m := range memories {
+		if m.ID == existing.ID {
+			continue
+		}
+		if strings.Join(m.Tags, ",") != "chatgpt,migrated" || m.Dim != 2 {
+			t.Fatalf("unexpected imported memory: %+v", m)
+		}
+	}
+}
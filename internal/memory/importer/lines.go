@@ -0,0 +1,55 @@
+package importer
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// bulletPrefix matches list markers ("- ", "* ", "• ", "1. ", "2) ") at the start of a line.
+var bulletPrefix = regexp.MustCompile(`^(?:[-*•]|\d+[.)])\s+`)
+
+// splitLines turns a plain-text or markdown list into one entry per line,
+// dropping list markers, headings, and blank lines.
+func splitLines(text string) []string {
+	var entries []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(bulletPrefix.ReplaceAllString(line, ""))
+		if line != "" {
+			entries = append(entries, line)
+		}
+	}
+	return entries
+}
+
+// parseTimestamp accepts RFC 3339 strings and unix seconds; anything else yields the zero time.
+func parseTimestamp(v any) time.Time {
+	switch t := v.(type) {
+	case string:
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"} {
+			if parsed, err := time.Parse(layout, t); err == nil {
+				return parsed
+			}
+		}
+	case float64:
+		if t > 0 {
+			sec := int64(t)
+			return time.Unix(sec, int64((t-float64(sec))*1e9))
+		}
+	}
+	return time.Time{}
+}
+
+// firstString returns the first non-empty string value among keys.
+func firstString(obj map[string]any, keys ...string) string {
+	for _, k := range keys {
+		if s, ok := obj[k].(string); ok && strings.TrimSpace(s) != "" {
+			return strings.TrimSpace(s)
+		}
+	}
+	return ""
+}
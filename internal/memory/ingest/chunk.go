@@ -0,0 +1,110 @@
+package ingest
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Chunk splits text into chunks of at most size characters, preferring
+// paragraph and then word boundaries. Adjacent chunks share up to overlap
+// trailing characters so that context spanning a boundary is not lost.
+func Chunk(text string, size, overlap int) []string {
+	if size <= 0 {
+		size = 1200
+	}
+	if overlap < 0 || overlap >= size {
+		overlap = 0
+	}
+
+	var pieces []string
+	for _, para := range splitParagraphs(text) {
+		pieces = append(pieces, splitLong(para, size)...)
+	}
+
+	var chunks []string
+	var current strings.Builder
+	for _, piece := range pieces {
+		if current.Len() > 0 && current.Len()+2+len(piece) > size {
+			prev := current.String()
+			chunks = append(chunks, prev)
+			current.Reset()
+			if tail := overlapTail(prev, overlap); tail != "" && len(tail)+2+len(piece) <= size {
+				current.WriteString(tail)
+			}
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n\n")
+		}
+		current.WriteString(piece)
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+
+	return chunks
+}
+
+// splitParagraphs splits text on blank lines and drops empty paragraphs.
+func splitParagraphs(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	var paragraphs []string
+	for _, para := range strings.Split(text, "\n\n") {
+		if para = strings.TrimSpace(para); para != "" {
+			paragraphs = append(paragraphs, para)
+		}
+	}
+	return paragraphs
+}
+
+// splitLong breaks a paragraph longer than size on word boundaries. A single
+// word longer than size is cut at rune boundaries.
+func splitLong(para string, size int) []string {
+	if len(para) <= size {
+		return []string{para}
+	}
+
+	var parts []string
+	var current strings.Builder
+	for _, word := range strings.Fields(para) {
+		for len(word) > size {
+			if current.Len() > 0 {
+				parts = append(parts, current.String())
+				current.Reset()
+			}
+			cut := size
+			for cut > 0 && !utf8.RuneStart(word[cut]) {
+				cut--
+			}
+			parts = append(parts, word[:cut])
+			word = word[cut:]
+		}
+		if current.Len() > 0 && current.Len()+1+len(word) > size {
+			parts = append(parts, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteByte(' ')
+		}
+		current.WriteString(word)
+	}
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+
+	return parts
+}
+
+// overlapTail returns roughly the last n characters of s, starting at a word
+// boundary. Returns "" if no boundary is found.
+func overlapTail(s string, n int) string {
+	if n <= 0 || len(s) <= n {
+		return ""
+	}
+	tail := s[len(s)-n:]
+	idx := strings.IndexAny(tail, " \n\t")
+	if idx < 0 {
+		return ""
+	}
+	return strings.TrimSpace(tail[idx:])
+}
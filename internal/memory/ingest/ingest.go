@@ -0,0 +1,244 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/memory/embedtext"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/memutils"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/plugin"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// supportedExtensions lists the file types ingest understands by itself.
+var supportedExtensions = map[string]bool{
+	".md":       true,
+	".markdown": true,
+	".txt":      true,
+	".pdf":      true,
+}
+
+// supported reports whether path is a file type ingest understands, by itself
+// or with a gomor-ingest-<ext> plugin.
+func supported(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	if supportedExtensions[ext] {
+		return true
+	}
+	_, ok := plugin.Find(plugin.KindIngest, strings.TrimPrefix(ext, "."))
+	return ok
+}
+
+// FileResult reports the outcome of ingesting a single file.
+type FileResult struct {
+	Path    string `json:"path"`
+	Chunks  int    `json:"chunks"`
+	Pending bool   `json:"pending,omitempty"` // embeddings were queued instead of computed
+	Err     error  `json:"-"`
+}
+
+// Ingester chunks documents and stores the chunks as document memories.
+type Ingester struct {
+	store           *store.Store
+	embeddingClient client.EmbeddingClient
+	model           types.Model
+	template        *embedtext.Template
+	config          utils.IngestConfig
+}
+
+// NewIngester creates an ingester that embeds chunks with the given model,
+// rendered with tmpl.
+func NewIngester(
+	s *store.Store,
+	embeddingClient client.EmbeddingClient,
+	model types.Model,
+	tmpl *embedtext.Template,
+	config utils.IngestConfig,
+) *Ingester {
+	return &Ingester{
+		store:           s,
+		embeddingClient: embeddingClient,
+		model:           model,
+		template:        tmpl,
+		config:          config,
+	}
+}
+
+// CollectFiles expands paths into the supported files they contain. Directories
+// are walked recursively, skipping hidden entries. Paths are returned absolute
+// and sorted so that re-ingesting the same tree is deterministic.
+func CollectFiles(paths []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var files []string
+
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+
+	for _, p := range paths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", p, err)
+		}
+		info, err := os.Stat(abs)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			if !supported(abs) {
+				return nil, fmt.Errorf("unsupported file type: %s", p)
+			}
+			add(abs)
+			continue
+		}
+
+		err = filepath.WalkDir(abs, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if path != abs && strings.HasPrefix(d.Name(), ".") {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.IsDir() && supported(path) {
+				add(path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk %s: %w", p, err)
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// ReadText returns the plain text of a file. PDFs are converted with the
+// configured external command, and other types with their gomor-ingest-<ext>
+// plugin; both must write the text to stdout.
+func (in *Ingester) ReadText(ctx context.Context, path string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".pdf" && !supportedExtensions[ext] {
+		if pluginPath, ok := plugin.Find(plugin.KindIngest, strings.TrimPrefix(ext, ".")); ok {
+			out, err := plugin.Command(ctx, pluginPath, path).Output()
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+				err = fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+			}
+			if err != nil {
+				return "", fmt.Errorf("failed to convert %s with %s: %w", filepath.Base(path), filepath.Base(pluginPath), err)
+			}
+			return string(out), nil
+		}
+	}
+	if ext != ".pdf" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+
+	command := in.config.PDFToText
+	if command == "" {
+		command = "pdftotext"
+	}
+	out, err := exec.CommandContext(ctx, command, path, "-").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to convert PDF with %s: %w", command, err)
+	}
+	return string(out), nil
+}
+
+// IngestFile replaces any chunks previously ingested from path with freshly
+// chunked and embedded ones, all at once: if storing them fails, the previous
+// chunks are kept. If the embedding provider fails, chunks are still stored
+// and their embeddings are queued for the background worker.
+func (in *Ingester) IngestFile(ctx context.Context, path string, tags []string) FileResult {
+	result := FileResult{Path: path}
+
+	text, err := in.ReadText(ctx, path)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	chunks := Chunk(text, in.config.ChunkSize, in.config.ChunkOverlap)
+	if len(chunks) == 0 {
+		return result
+	}
+
+	items := make([]memtypes.MemoryItem, len(chunks))
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		items[i] = memtypes.MemoryItem{
+			Text:       chunk,
+			Tags:       tags,
+			Source:     memtypes.SourceDocument,
+			Kind:       memtypes.KindDocumentChunk,
+			Provider:   in.model.Provider,
+			ModelID:    in.model.ModelID,
+			SourcePath: path,
+			ChunkIndex: i,
+		}
+		texts[i] = in.template.Render(items[i])
+	}
+
+	embeddings, embedErr := in.embedChunks(ctx, texts)
+	result.Pending = embedErr != nil
+	if embedErr == nil {
+		for i := range items {
+			items[i].Embedding = embeddings[i]
+			items[i].Dim = len(embeddings[i])
+		}
+	}
+
+	if err := in.store.ReplaceSourceMemories(path, items, result.Pending); err != nil {
+		result.Err = err
+		return result
+	}
+	result.Chunks = len(items)
+	return result
+}
+
+// embedChunks embeds the rendered chunks in batches of the configured size.
+func (in *Ingester) embedChunks(ctx context.Context, chunks []string) ([][]float32, error) {
+	batchSize := in.config.BatchSize
+	if batchSize <= 0 {
+		batchSize = 32
+	}
+
+	embeddings := make([][]float32, 0, len(chunks))
+	for start := 0; start < len(chunks); start += batchSize {
+		end := min(start+batchSize, len(chunks))
+		vectors, err := in.embeddingClient.EmbedBatch(ctx, in.model, chunks[start:end])
+		if err != nil {
+			return nil, err
+		}
+		if len(vectors) != end-start {
+			return nil, fmt.Errorf("embedding provider returned %d vectors for %d chunks", len(vectors), end-start)
+		}
+		for _, v := range vectors {
+			embeddings = append(embeddings, memutils.NormalizeVector(v))
+		}
+	}
+	return embeddings, nil
+}
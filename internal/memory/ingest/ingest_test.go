@@ -0,0 +1,236 @@
+package ingest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+	_ "modernc.org/sqlite"
+)
+
+// fakeEmbeddingClient counts batch calls and can be made to fail.
+type fakeEmbeddingClient struct {
+	fail    bool
+	batches int
+}
+
+func (f *fakeEmbeddingClient) Embed(ctx context.Context, model types.Model, text string) ([]float32, error) {
+	return []float32{1, 0}, nil
+}
+
+func (f *fakeEmbeddingClient) EmbedBatch(ctx context.Context, model types.Model, texts []string) ([][]float32, error) {
+	if f.fail {
+		return nil, errors.New("embedding provider unavailable")
+	}
+	f.batches++
+	vectors := make([][]float32, len(texts))
+	for i := range texts {
+		vectors[i] = []float32{3, 4}
+	}
+	return vectors, nil
+}
+
+func (f *fakeEmbeddingClient) Dimensions(model types.Model) int {
+	return 2
+}
+
+func newTestStore(t *testing.T) *store.Store {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+
+	memStore, err := store.NewStoreWithDB(db)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = memStore.Close()
+	})
+
+	return memStore
+}
+
+func TestChunkRespectsSizeAndOverlap(t *testing.T) {
+	text := strings.Repeat("alpha beta gamma delta. ", 20) + "\n\n" + strings.Repeat("epsilon zeta eta theta. ", 20)
+
+	chunks := Chunk(text, 200, 40)
+	if len(chunks) < 3 {
+		t.Fatalf("expected text to be split into several chunks, got %d", len(chunks))
+	}
+	for i, c := range chunks {
+		if len(c) > 200 {
+			t.Fatalf("chunk %d exceeds size: %d", i, len(c))
+		}
+	}
+
+	// The start of each chunk repeats words from the end of the previous one.
+	first := strings.Fields(chunks[1])[0]
+	if !strings.Contains(chunks[0][len(chunks[0])-40:], first) {
+		t.Fatalf("expected chunk 1 to overlap chunk 0, got %q after %q", chunks[1][:20], chunks[0][len(chunks[0])-40:])
+	}
+}
+
+func TestChunkKeepsShortParagraphsTogether(t *testing.T) {
+	chunks := Chunk("# Title\n\nFirst paragraph.\n\n\n\nSecond paragraph.", 1000, 100)
+	if len(chunks) != 1 {
+		t.Fatalf("expected one chunk, got %d: %q", len(chunks), chunks)
+	}
+	if chunks[0] != "# Title\n\nFirst paragraph.\n\nSecond paragraph." {
+		t.Fatalf("unexpected chunk: %q", chunks[0])
+	}
+}
+
+func TestCollectFilesWalksDirectories(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.md", "b.txt", "c.go", ".hidden/d.md", "sub/e.markdown"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("text"), 0644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	files, err := CollectFiles([]string{dir})
+	if err != nil {
+		t.Fatalf("collect files: %v", err)
+	}
+
+	var names []string
+	for _, f := range files {
+		rel, _ := filepath.Rel(dir, f)
+		names = append(names, rel)
+	}
+	if strings.Join(names, ",") != "a.md,b.txt,sub/e.markdown" {
+		t.Fatalf("unexpected files: %v", names)
+	}
+
+	if _, err := CollectFiles([]string{filepath.Join(dir, "c.go")}); err == nil {
+		t.Fatal("expected unsupported file type error")
+	}
+}
+
+func TestIngestFileStoresAndReplacesChunks(t *testing.T) {
+	memStore := newTestStore(t)
+	embClient := &fakeEmbeddingClient{}
+	config := utils.IngestConfig{ChunkSize: 100, ChunkOverlap: 0, BatchSize: 2}
+	ingester := NewIngester(memStore, embClient, types.Model{Provider: "fake", ModelID: "fake-embedding"}, nil, config)
+
+	path := filepath.Join(t.TempDir(), "notes.md")
+	content := strings.Repeat("Atlas release notes describe the plugin system.\n\n", 5)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	result := ingester.IngestFile(context.Background(), path, []string{"docs"})
+	if result.Err != nil {
+		t.Fatalf("ingest file: %v", result.Err)
+	}
+	if result.Chunks != 3 || embClient.batches != 2 {
+		t.Fatalf("expected 3 chunks in 2 batches, got %d chunks in %d batches", result.Chunks, embClient.batches)
+	}
+
+	// Re-ingesting replaces the previous chunks instead of duplicating them.
+	if result := ingester.IngestFile(context.Background(), path, nil); result.Err != nil {
+		t.Fatalf("re-ingest file: %v", result.Err)
+	}
+
+	memories, err := memStore.GetAllMemories()
+	if err != nil {
+		t.Fatalf("get all memories: %v", err)
+	}
+	if len(memories) != 3 {
+		t.Fatalf("expected 3 memories after re-ingest, got %d", len(memories))
+	}
+	seen := make(map[int]bool)
+	for _, m := range memories {
+		if m.Source != memtypes.SourceDocument || m.SourcePath != path {
+			t.Fatalf("unexpected document metadata: source=%s path=%s", m.Source, m.SourcePath)
+		}
+		if m.Dim != 2 {
+			t.Fatalf("expected embedded chunk, got dim %d", m.Dim)
+		}
+		seen[m.ChunkIndex] = true
+	}
+	if !seen[0] || !seen[1] || !seen[2] {
+		t.Fatalf("expected chunk indexes 0-2, got %v", seen)
+	}
+}
+
+func TestIngestFileQueuesEmbeddingsOnProviderFailure(t *testing.T) {
+	memStore := newTestStore(t)
+	ingester := NewIngester(memStore, &fakeEmbeddingClient{fail: true}, types.Model{Provider: "fake", ModelID: "fake-embedding"}, nil, utils.IngestConfig{})
+
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("a short note"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	result := ingester.IngestFile(context.Background(), path, nil)
+	if result.Err != nil {
+		t.Fatalf("ingest file: %v", result.Err)
+	}
+	if !result.Pending || result.Chunks != 1 {
+		t.Fatalf("expected 1 pending chunk, got %+v", result)
+	}
+
+	jobs, err := memStore.PendingEmbeddings(5, 10)
+	if err != nil {
+		t.Fatalf("pending embeddings: %v", err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("expected 1 queued embedding, got %d", len(jobs))
+	}
+}
+
+func TestIngestPluginConvertsOtherFileTypes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts need a POSIX shell")
+	}
+	bin := t.TempDir()
+	script := "#!/bin/sh\necho \"converted $1\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "gomor-ingest-csv"), []byte(script), 0755); err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+	t.Setenv("PATH", bin)
+	t.Setenv("HOME", t.TempDir())
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "table.csv")
+	for _, name := range []string{"table.csv", "main.go"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("a,b"), 0644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	files, err := CollectFiles([]string{dir})
+	if err != nil {
+		t.Fatalf("collect files: %v", err)
+	}
+	if len(files) != 1 || files[0] != path {
+		t.Fatalf("expected only the csv file, got %v", files)
+	}
+
+	ingester := NewIngester(newTestStore(t), &fakeEmbeddingClient{}, types.Model{}, nil, utils.IngestConfig{})
+	text, err := ingester.ReadText(context.Background(), path)
+	if err != nil {
+		t.Fatalf("read text: %v", err)
+	}
+	if text != "converted "+path+"\n" {
+		t.Fatalf("unexpected text %q", text)
+	}
+}
@@ -0,0 +1,162 @@
+// Package memquery parses the search syntax used to slice the memory base:
+//
+//	tag:golang -tag:draft source:explicit kind:fact after:2024-01 before:2024-06 "unit test" flaky
+//
+// Qualifiers become a memtypes.MemoryFilter; the remaining words and quoted
+// phrases must all appear in a memory's text.
+package memquery
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+// Syntax summarizes the qualifiers for help texts.
+const Syntax = `tag:NAME, -tag:NAME, source:explicit|extracted|document|imported,
+kind:fact|preference|document-chunk|episodic, before:DATE, after:DATE (DATE is
+YYYY, YYYY-MM, or YYYY-MM-DD), and "quoted phrases"`
+
+// Query is a parsed search.
+type Query struct {
+	Filter memtypes.MemoryFilter
+	// Terms are the words and phrases a memory's text must all contain.
+	Terms []string
+}
+
+// Parse reads input relative to now, whose location dates are read in.
+// Words that look like qualifiers but use an unknown key ("http://...") are
+// searched as text.
+func Parse(input string, now time.Time) (Query, error) {
+	var q Query
+	for _, token := range split(input) {
+		key, value, ok := strings.Cut(token.text, ":")
+		if token.quoted || !ok || value == "" {
+			if token.text != "" {
+				q.Terms = append(q.Terms, token.text)
+			}
+			continue
+		}
+		value = strings.Trim(value, `"`)
+
+		switch strings.ToLower(key) {
+		case "tag":
+			q.Filter.Tags = append(q.Filter.Tags, value)
+		case "-tag":
+			q.Filter.ExcludeTags = append(q.Filter.ExcludeTags, value)
+		case "source":
+			source, err := memtypes.ParseMemorySource(value)
+			if err != nil {
+				return Query{}, err
+			}
+			q.Filter.Sources = append(q.Filter.Sources, source)
+		case "kind":
+			kind, err := memtypes.ParseMemoryKind(value)
+			if err != nil {
+				return Query{}, err
+			}
+			q.Filter.Kinds = append(q.Filter.Kinds, kind)
+		case "before", "after":
+			start, end, err := parsePeriod(value, now.Location())
+			if err != nil {
+				return Query{}, fmt.Errorf("invalid %s: %w", key, err)
+			}
+			// Neither bound includes the named period itself.
+			if strings.EqualFold(key, "before") {
+				q.Filter.Created.End = start
+			} else {
+				q.Filter.Created.Start = end
+			}
+		default:
+			q.Terms = append(q.Terms, token.text)
+		}
+	}
+	return q, nil
+}
+
+// Matches reports whether item passes the filter and contains every term,
+// ignoring case.
+func (q Query) Matches(item memtypes.MemoryItem) bool {
+	if !q.Filter.Matches(item) {
+		return false
+	}
+	text := strings.ToLower(item.Text)
+	for _, term := range q.Terms {
+		if !strings.Contains(text, strings.ToLower(term)) {
+			return false
+		}
+	}
+	return true
+}
+
+// FTS returns the terms as an FTS5 query matching memories that contain all
+// of them, or "" when there are none.
+func (q Query) FTS() string {
+	quoted := make([]string, 0, len(q.Terms))
+	for _, term := range q.Terms {
+		quoted = append(quoted, `"`+strings.ReplaceAll(term, `"`, `""`)+`"`)
+	}
+	return strings.Join(quoted, " ")
+}
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+// split breaks input at spaces outside double quotes. A quoted phrase becomes
+// one quoted token; quotes inside a word (tag:"machine learning") are kept
+// for Parse to strip. An unterminated quote runs to the end of input.
+func split(input string) []token {
+	var tokens []token
+	var current strings.Builder
+	inQuotes, quoted := false, false
+	flush := func() {
+		if current.Len() > 0 || quoted {
+			tokens = append(tokens, token{text: strings.TrimSpace(current.String()), quoted: quoted})
+		}
+		current.Reset()
+		quoted = false
+	}
+
+	for _, r := range input {
+		switch {
+		case r == '"' && current.Len() == 0 && !inQuotes:
+			inQuotes, quoted = true, true
+		case r == '"' && inQuotes && quoted:
+			inQuotes = false
+			flush()
+		case r == '"':
+			inQuotes = !inQuotes
+			current.WriteRune(r)
+		case unicode.IsSpace(r) && !inQuotes:
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+// parsePeriod reads a year, month, or day and returns its bounds.
+func parsePeriod(value string, loc *time.Location) (time.Time, time.Time, error) {
+	for _, p := range []struct {
+		layout string
+		years  int
+		months int
+		days   int
+	}{
+		{"2006-01-02", 0, 0, 1},
+		{"2006-01", 0, 1, 0},
+		{"2006", 1, 0, 0},
+	} {
+		if start, err := time.ParseInLocation(p.layout, value, loc); err == nil {
+			return start, start.AddDate(p.years, p.months, p.days), nil
+		}
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("%q is not a date; use YYYY, YYYY-MM, or YYYY-MM-DD", value)
+}
@@ -0,0 +1,76 @@
+package memquery
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+func TestParse(t *testing.T) {
+	now := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+	q, err := Parse(`tag:golang -tag:draft source:explicit kind:fact after:2024-01 before:2024-06-15 "unit test" flaky http://example.com tag:"machine learning"`, now)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	want := memtypes.MemoryFilter{
+		Created: memtypes.TimeRange{
+			Start: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC),
+		},
+		ExcludeTags: []string{"draft"},
+		Tags:        []string{"golang", "machine learning"},
+		Sources:     []memtypes.MemorySource{memtypes.SourceExplicit},
+		Kinds:       []memtypes.MemoryKind{memtypes.KindFact},
+	}
+	if !reflect.DeepEqual(q.Filter, want) {
+		t.Fatalf("filter = %+v, want %+v", q.Filter, want)
+	}
+	if !reflect.DeepEqual(q.Terms, []string{"unit test", "flaky", "http://example.com"}) {
+		t.Fatalf("terms = %q", q.Terms)
+	}
+	if fts := q.FTS(); fts != `"unit test" "flaky" "http://example.com"` {
+		t.Fatalf("FTS() = %s", fts)
+	}
+
+	for _, bad := range []string{"kind:rumor", "source:email", "before:last-week"} {
+		if _, err := Parse(bad, now); err == nil {
+			t.Errorf("Parse(%q): expected an error", bad)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	q, err := Parse(`tag:Go after:2023 "Table Tests"`, time.Now())
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	item := memtypes.MemoryItem{
+		Text:      "Handlers use table tests",
+		Tags:      []string{"go", "testing"},
+		CreatedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local),
+	}
+	if !q.Matches(item) {
+		t.Fatalf("expected %+v to match", item)
+	}
+
+	old := item
+	old.CreatedAt = time.Date(2023, 12, 31, 0, 0, 0, 0, time.Local)
+	untagged := item
+	untagged.Tags = []string{"testing"}
+	rephrased := item
+	rephrased.Text = "Handlers are tested with tables"
+	for _, miss := range []memtypes.MemoryItem{old, untagged, rephrased} {
+		if q.Matches(miss) {
+			t.Errorf("expected %+v not to match", miss)
+		}
+	}
+
+	if q, _ := Parse(`say "hi"`, time.Now()); !strings.Contains(q.FTS(), `"hi"`) || len(q.Terms) != 2 {
+		t.Fatalf("expected a word and a phrase, got %q", q.Terms)
+	}
+}
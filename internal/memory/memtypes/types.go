@@ -1,6 +1,11 @@
 package memtypes
 
-import "time"
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
 
 // MemorySource indicates how a memory was created.
 type MemorySource string
@@ -10,22 +15,97 @@ const (
 	SourceExplicit MemorySource = "explicit"
 	// SourceExtracted means the memory was automatically extracted from conversation.
 	SourceExtracted MemorySource = "extracted"
+	// SourceDocument means the memory is a chunk of an ingested document.
+	SourceDocument MemorySource = "document"
+	// SourceImported means the memory was imported from another assistant's export.
+	SourceImported MemorySource = "imported"
+)
+
+// MemorySources lists all valid memory sources.
+var MemorySources = []MemorySource{SourceExplicit, SourceExtracted, SourceDocument, SourceImported}
+
+// ParseMemorySource validates a source name.
+func ParseMemorySource(name string) (MemorySource, error) {
+	for _, s := range MemorySources {
+		if string(s) == name {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("unknown memory source %q (valid: explicit, extracted, document, imported)", name)
+}
+
+// MemoryKind classifies what a memory holds. Retrieval weights and result
+// budgets are configured per kind.
+type MemoryKind string
+
+const (
+	// KindFact is a statement about the user, their work, or the world.
+	KindFact MemoryKind = "fact"
+	// KindPreference is how the user likes things done.
+	KindPreference MemoryKind = "preference"
+	// KindDocumentChunk is a chunk of an ingested document.
+	KindDocumentChunk MemoryKind = "document-chunk"
+	// KindEpisodic records something that happened at a point in time.
+	KindEpisodic MemoryKind = "episodic"
+)
+
+// MemoryKinds lists all valid memory kinds.
+var MemoryKinds = []MemoryKind{KindFact, KindPreference, KindDocumentChunk, KindEpisodic}
+
+// ParseMemoryKind validates a kind name. An empty name defaults to KindFact.
+func ParseMemoryKind(name string) (MemoryKind, error) {
+	if name == "" {
+		return KindFact, nil
+	}
+	for _, k := range MemoryKinds {
+		if string(k) == name {
+			return k, nil
+		}
+	}
+	return "", fmt.Errorf("unknown memory kind %q (valid: fact, preference, document-chunk, episodic)", name)
+}
+
+// EmbeddingTarget identifies which table a queued embedding job belongs to.
+type EmbeddingTarget string
+
+const (
+	// EmbeddingTargetMemory queues the embedding of a memory row.
+	EmbeddingTargetMemory EmbeddingTarget = "memory"
+	// EmbeddingTargetHistory queues the embedding of a history row.
+	EmbeddingTargetHistory EmbeddingTarget = "history"
 )
 
 // MemoryItem represents a single preference/fact stored in memory.
 type MemoryItem struct {
-	ID              string       `json:"id"`
-	Text            string       `json:"text"`
-	Tags            []string     `json:"tags,omitempty"`
-	Source          MemorySource `json:"source"`
-	CreatedAt       time.Time    `json:"created_at"`
-	Confidence      float64      `json:"confidence"`
-	StabilityDays   float64      `json:"stability_days"`
-	LastRetrievedAt *time.Time   `json:"last_retrieved_at,omitempty"`
-	Provider        string       `json:"provider"`
-	ModelID         string       `json:"model_id"`
-	Dim             int          `json:"dim"`
-	Embedding       []float32    `json:"-"` // stored as blob, not JSON
+	ID               string            `json:"id"`
+	Text             string            `json:"text"`
+	Tags             []string          `json:"tags,omitempty"`
+	Source           MemorySource      `json:"source"`
+	Kind             MemoryKind        `json:"kind"`
+	CreatedAt        time.Time         `json:"created_at"`
+	Confidence       float64           `json:"confidence"`
+	StabilityDays    float64           `json:"stability_days"`
+	LastRetrievedAt  *time.Time        `json:"last_retrieved_at,omitempty"`
+	TimesRetrieved   int               `json:"times_retrieved,omitempty"` // how many retrievals returned it
+	Provider         string            `json:"provider"`
+	ModelID          string            `json:"model_id"`
+	Dim              int               `json:"dim"`
+	Embedding        []float32         `json:"-"`                           // stored as blob, not JSON
+	SourcePath       string            `json:"source_path,omitempty"`       // file a document chunk was ingested from
+	ChunkIndex       int               `json:"chunk_index,omitempty"`       // position of the chunk within SourcePath or its parent memory
+	ParentID         string            `json:"parent_id,omitempty"`         // long memory this is a chunk of; chunks are searched in its place
+	Metadata         map[string]string `json:"metadata,omitempty"`          // fields carried over from imported memories
+	Pinned           bool              `json:"pinned,omitempty"`            // always injected into context, regardless of relevance
+	Suppressed       bool              `json:"suppressed,omitempty"`        // kept for the record but never retrieved
+	PendingReview    bool              `json:"pending_review,omitempty"`    // extracted and not yet approved, so never retrieved
+	NeedsReembedding bool              `json:"needs_reembedding,omitempty"` // stored embedding is malformed, so vector search skips it
+}
+
+// Entity is a named person, project, or tool that memories can be linked to.
+type Entity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Kind string `json:"kind"`
 }
 
 // HistoryItem represents a conversation turn stored in history.
@@ -35,6 +115,166 @@ type HistoryItem struct {
 	Content   string    `json:"content"`
 	CreatedAt time.Time `json:"created_at"`
 	SessionID string    `json:"session_id,omitempty"`
+	// ParentID links an assistant turn to the user turn it answers; retried
+	// answers share a parent.
+	ParentID string `json:"parent_id,omitempty"`
+	// Model is the "provider/model" that wrote an assistant turn.
+	Model string `json:"model,omitempty"`
+	// Interrupted marks an assistant turn cut short by Ctrl-C; Content holds
+	// the part of the answer streamed before it.
+	Interrupted bool `json:"interrupted,omitempty"`
+}
+
+// Session is a conversation. A forked session continues its parent's
+// conversation from ForkTurnID onwards without copying the earlier turns.
+type Session struct {
+	ID         string    `json:"id"`
+	ParentID   string    `json:"parent_id,omitempty"`
+	ForkTurnID string    `json:"fork_turn_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	// Title is a short name the title model gave the session after its first
+	// exchange, empty until then.
+	Title string `json:"title,omitempty"`
+}
+
+// SpendEntry records the estimated token usage and cost of one chat request.
+type SpendEntry struct {
+	Provider     string    `json:"provider"`
+	ModelID      string    `json:"model_id"`
+	InputTokens  int       `json:"input_tokens"`
+	OutputTokens int       `json:"output_tokens"`
+	CostUSD      float64   `json:"cost_usd"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// MemoryFeedback is a memory's calibration after a useful / wrong vote.
+type MemoryFeedback struct {
+	MemoryID           string  `json:"memory_id"`
+	PreviousConfidence float64 `json:"previous_confidence"`
+	Confidence         float64 `json:"confidence"`
+	StabilityDays      float64 `json:"stability_days"`
+	Useful             int     `json:"useful"` // useful votes so far, including this one
+	Wrong              int     `json:"wrong"`  // wrong votes so far, including this one
+}
+
+// RelationKind is how one memory relates to another.
+type RelationKind string
+
+const (
+	// RelationSupersedes marks a memory that replaces an outdated or
+	// contradicted one.
+	RelationSupersedes RelationKind = "supersedes"
+	// RelationRelatesTo links memories about the same subject.
+	RelationRelatesTo RelationKind = "relates-to"
+	// RelationDerivedFrom marks a memory that was worked out from another.
+	RelationDerivedFrom RelationKind = "derived-from"
+)
+
+// RelationKinds lists all valid relation kinds.
+var RelationKinds = []RelationKind{RelationSupersedes, RelationRelatesTo, RelationDerivedFrom}
+
+// ParseRelationKind validates a relation kind name.
+func ParseRelationKind(name string) (RelationKind, error) {
+	for _, k := range RelationKinds {
+		if string(k) == name {
+			return k, nil
+		}
+	}
+	return "", fmt.Errorf("unknown relation %q (valid: supersedes, relates-to, derived-from)", name)
+}
+
+// MemoryRelation is a directed edge between two memories: FromID supersedes,
+// relates to, or is derived from ToID.
+type MemoryRelation struct {
+	FromID    string       `json:"from_id"`
+	ToID      string       `json:"to_id"`
+	Kind      RelationKind `json:"kind"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// EmbeddingJob represents a pending entry in the embedding queue.
+type EmbeddingJob struct {
+	ID         int64           `json:"id"`
+	TargetKind EmbeddingTarget `json:"target_kind"`
+	TargetID   string          `json:"target_id"`
+	Attempts   int             `json:"attempts"`
+	Text       string          `json:"text"` // text of the target row, empty if the row is gone
+	// Tags, Kind, Source, and SourcePath of a memory target, for the
+	// embedding template; empty for history.
+	Tags       []string     `json:"tags,omitempty"`
+	Kind       MemoryKind   `json:"kind,omitempty"`
+	Source     MemorySource `json:"source,omitempty"`
+	SourcePath string       `json:"source_path,omitempty"`
+}
+
+// EmbeddingProblem describes a stored embedding that cannot be used for search.
+type EmbeddingProblem struct {
+	TargetKind EmbeddingTarget `json:"target_kind"`
+	TargetID   string          `json:"target_id"`
+	Reason     string          `json:"reason"`
+}
+
+// TimeRange is a half-open [Start, End) interval. A zero bound is unbounded.
+type TimeRange struct {
+	Start time.Time `json:"start,omitempty"`
+	End   time.Time `json:"end,omitempty"`
+}
+
+// Contains reports whether t falls within the range.
+func (r TimeRange) Contains(t time.Time) bool {
+	if !r.Start.IsZero() && t.Before(r.Start) {
+		return false
+	}
+	if !r.End.IsZero() && !t.Before(r.End) {
+		return false
+	}
+	return true
+}
+
+// MemoryFilter restricts which memories a search may return.
+// The zero value matches every memory that is neither suppressed nor pending review.
+type MemoryFilter struct {
+	Created TimeRange
+	// ExcludeTags drops memories carrying any of these tags (case-insensitive).
+	ExcludeTags []string
+	// Tags keeps only memories carrying all of these tags (case-insensitive).
+	Tags []string
+	// Sources and Kinds, when set, keep only memories of one of them.
+	Sources []MemorySource
+	Kinds   []MemoryKind
+}
+
+// Matches reports whether item passes the filter.
+func (f MemoryFilter) Matches(item MemoryItem) bool {
+	if item.Suppressed || item.PendingReview || !f.Created.Contains(item.CreatedAt) {
+		return false
+	}
+	for _, excluded := range f.ExcludeTags {
+		if hasTag(item, excluded) {
+			return false
+		}
+	}
+	for _, required := range f.Tags {
+		if !hasTag(item, required) {
+			return false
+		}
+	}
+	if len(f.Sources) > 0 && !slices.Contains(f.Sources, item.Source) {
+		return false
+	}
+	if len(f.Kinds) > 0 && !slices.Contains(f.Kinds, item.Kind) {
+		return false
+	}
+	return true
+}
+
+func hasTag(item MemoryItem, tag string) bool {
+	for _, t := range item.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
 }
 
 // SearchResult represents a memory search result with similarity score (vector search).
@@ -57,17 +297,40 @@ type HistorySearchResult struct {
 	Rank    float64     `json:"rank"`    // FTS rank score
 }
 
+// SearchHitKind says whether a SearchHit is a memory or a history turn.
+type SearchHitKind string
+
+const (
+	SearchHitMemory  SearchHitKind = "memory"
+	SearchHitHistory SearchHitKind = "history"
+)
+
+// SearchHit is a full-text match in either memories or history.
+type SearchHit struct {
+	Kind      SearchHitKind `json:"kind"`
+	ID        string        `json:"id"`
+	Text      string        `json:"text"`
+	Snippet   string        `json:"snippet"`
+	Rank      float64       `json:"rank"` // FTS rank score (lower is better)
+	CreatedAt time.Time     `json:"created_at"`
+	Tags      []string      `json:"tags,omitempty"`       // memories only
+	Role      string        `json:"role,omitempty"`       // history only
+	SessionID string        `json:"session_id,omitempty"` // history only
+}
+
 // UnifiedResult represents a unified retrieval result from any source.
 // Used for fusion and ranking across different retrieval methods.
 type UnifiedResult struct {
-	Item        MemoryItem `json:"item"`
-	Score       float64    `json:"score"`        // final score after applying freshness + confidence
-	BaseScore   float64    `json:"base_score"`   // hybrid relevance score before decay adjustments
-	Freshness   float64    `json:"freshness"`    // recency factor derived from last retrieval time
-	Source      string     `json:"source"`       // "vector", "fts", or "both"
-	VectorScore float64    `json:"vector_score"` // original vector similarity
-	FTSRank     float64    `json:"fts_rank"`     // original FTS rank
-	Snippet     string     `json:"snippet"`      // FTS snippet if available
+	Item         MemoryItem `json:"item"`
+	Score        float64    `json:"score"`                   // final score after applying freshness + confidence
+	BaseScore    float64    `json:"base_score"`              // hybrid relevance score before decay adjustments
+	Freshness    float64    `json:"freshness"`               // recency factor derived from last retrieval time
+	Source       string     `json:"source"`                  // "vector", "fts", "both", or "pinned"
+	VectorScore  float64    `json:"vector_score"`            // original vector similarity
+	FTSRank      float64    `json:"fts_rank"`                // original FTS rank
+	Snippet      string     `json:"snippet"`                 // FTS snippet if available
+	EntityMatch  bool       `json:"entity_match,omitempty"`  // linked to an entity named in the query
+	DuplicateIDs []string   `json:"duplicate_ids,omitempty"` // other memories with the same text, merged into this one
 }
 
 // InjectedContext represents the fused retrieval context to inject into prompts.
@@ -78,6 +341,15 @@ type InjectedContext struct {
 
 // RetrievalResponse represents the response from the unified memory retrieve operation.
 type RetrievalResponse struct {
-	Results []UnifiedResult `json:"results"`
-	Query   string          `json:"query"`
+	Results         []UnifiedResult `json:"results"`
+	Query           string          `json:"query"`
+	RewrittenQuery  string          `json:"rewritten_query,omitempty"`  // standalone query derived from conversation history
+	TranslatedQuery string          `json:"translated_query,omitempty"` // query translated into the memories' language
+	TimeRange       *TimeRange      `json:"time_range,omitempty"`       // date filter parsed from the query
+	Degraded        bool            `json:"degraded,omitempty"`         // a retrieval path failed; results come from the rest
+	Warnings        []string        `json:"warnings,omitempty"`         // why each failed path failed
+	FTSOnly         bool            `json:"fts_only,omitempty"`         // vector search was skipped (offline mode)
+	Omitted         int             `json:"omitted,omitempty"`          // lowest-ranked results dropped to fit the token budget
+	Summary         string          `json:"summary,omitempty"`          // tool-model summary that stands in for the results to fit the token budget
+	Class           string          `json:"class,omitempty"`            // how memory.query_classifier labeled the query
 }
@@ -0,0 +1,79 @@
+package memutils
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/austiecodes/gomor/internal/tokenizer"
+)
+
+// NormalizeText returns the key under which memory texts count as duplicates:
+// lowercased, with runs of whitespace collapsed to a single space.
+func NormalizeText(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// SplitSentences splits text after sentence-ending punctuation followed by
+// whitespace, and at line breaks.
+func SplitSentences(text string) []string {
+	var sentences []string
+	var current strings.Builder
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" {
+			sentences = append(sentences, s)
+		}
+		current.Reset()
+	}
+
+	runes := []rune(text)
+	for i, c := range runes {
+		if c == '\n' {
+			flush()
+			continue
+		}
+		current.WriteRune(c)
+		if strings.ContainsRune(".!?。！？", c) && (i+1 == len(runes) || unicode.IsSpace(runes[i+1]) || c > unicode.MaxASCII) {
+			flush()
+		}
+	}
+	flush()
+	return sentences
+}
+
+// ChunkSentences groups the sentences of text into chunks of at most
+// maxTokens tokens as counted by counter. A sentence over the budget is split
+// between words; a single word over it becomes a chunk of its own.
+func ChunkSentences(text string, maxTokens int, counter tokenizer.Counter) []string {
+	var pieces []string
+	for _, sentence := range SplitSentences(text) {
+		if counter.Count(sentence) <= maxTokens {
+			pieces = append(pieces, sentence)
+			continue
+		}
+		pieces = append(pieces, packWords(strings.Fields(sentence), maxTokens, counter)...)
+	}
+	return packWords(pieces, maxTokens, counter)
+}
+
+// packWords joins consecutive parts with spaces for as long as the result
+// stays within maxTokens.
+func packWords(parts []string, maxTokens int, counter tokenizer.Counter) []string {
+	var chunks []string
+	current := ""
+	for _, part := range parts {
+		if current == "" {
+			current = part
+			continue
+		}
+		if joined := current + " " + part; counter.Count(joined) <= maxTokens {
+			current = joined
+			continue
+		}
+		chunks = append(chunks, current)
+		current = part
+	}
+	if current != "" {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
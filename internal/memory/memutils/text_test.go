@@ -0,0 +1,47 @@
+package memutils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// wordCounter counts one token per word.
+type wordCounter struct{}
+
+func (wordCounter) Count(text string) int { return len(strings.Fields(text)) }
+
+func TestSplitSentences(t *testing.T) {
+	cases := map[string][]string{
+		"One. Two! Three?":             {"One.", "Two!", "Three?"},
+		"Use v1.2 of the API. Done.":   {"Use v1.2 of the API.", "Done."},
+		"first line\nsecond line":      {"first line", "second line"},
+		"数据库迁移已完成。下周上线。":               {"数据库迁移已完成。", "下周上线。"},
+		"no terminal punctuation here": {"no terminal punctuation here"},
+		"  ":                           nil,
+	}
+	for text, want := range cases {
+		if got := SplitSentences(text); !reflect.DeepEqual(got, want) {
+			t.Errorf("SplitSentences(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestChunkSentences(t *testing.T) {
+	cases := []struct {
+		text      string
+		maxTokens int
+		want      []string
+	}{
+		{"One two. Three four. Five.", 10, []string{"One two. Three four. Five."}},
+		{"One two. Three four. Five.", 4, []string{"One two. Three four.", "Five."}},
+		{"One two three four five six.", 4, []string{"One two three four", "five six."}},
+		{"Short. One two three four five six.", 4, []string{"Short.", "One two three four", "five six."}},
+		{"", 4, nil},
+	}
+	for _, tc := range cases {
+		if got := ChunkSentences(tc.text, tc.maxTokens, wordCounter{}); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("ChunkSentences(%q, %d) = %q, want %q", tc.text, tc.maxTokens, got, tc.want)
+		}
+	}
+}
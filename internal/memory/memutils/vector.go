@@ -2,6 +2,8 @@ package memutils
 
 import (
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"math"
 )
 
@@ -75,6 +77,19 @@ func VectorToBytes(v []float32) []byte {
 	return buf
 }
 
+// ErrMalformedEmbedding reports a stored embedding that is not a whole number
+// of float32 values, usually a truncated write.
+var ErrMalformedEmbedding = errors.New("malformed embedding")
+
+// DecodeVector is BytesToVector that reports a malformed blob instead of
+// returning nil for it.
+func DecodeVector(b []byte) ([]float32, error) {
+	if len(b)%4 != 0 {
+		return nil, fmt.Errorf("%w: %d bytes is not a whole number of float32 values", ErrMalformedEmbedding, len(b))
+	}
+	return BytesToVector(b), nil
+}
+
 // BytesToVector converts bytes (little-endian) to a float32 slice.
 func BytesToVector(b []byte) []float32 {
 	if len(b)%4 != 0 {
@@ -0,0 +1,125 @@
+package obsidian
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+// Note is the editable part of a memory as it appears in the vault.
+type Note struct {
+	ID   string
+	Text string
+	Tags []string
+	Kind memtypes.MemoryKind
+}
+
+// Render formats a memory as a markdown note with YAML frontmatter. Only
+// tags and kind (and the body text) are read back; the other fields are informational.
+func Render(item memtypes.MemoryItem) []byte {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "gomor_id: %s\n", item.ID)
+	kind := item.Kind
+	if kind == "" {
+		kind = memtypes.KindFact
+	}
+	fmt.Fprintf(&b, "kind: %s\n", kind)
+	fmt.Fprintf(&b, "source: %s\n", item.Source)
+	fmt.Fprintf(&b, "created: %s\n", item.CreatedAt.UTC().Format(time.RFC3339))
+	if len(item.Tags) > 0 {
+		b.WriteString("tags:\n")
+		for _, t := range item.Tags {
+			fmt.Fprintf(&b, "  - %s\n", yamlString(t))
+		}
+	}
+	b.WriteString("---\n\n")
+	b.WriteString(strings.TrimSpace(item.Text))
+	b.WriteString("\n")
+	return []byte(b.String())
+}
+
+// Parse reads a note written by Render, tolerating the edits Obsidian makes:
+// inline tag lists ("tags: [a, b]"), "#" prefixes, and quoted values.
+func Parse(data []byte) (Note, error) {
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if !strings.HasPrefix(content, "---\n") {
+		return Note{}, fmt.Errorf("missing frontmatter")
+	}
+	rest := content[len("---\n"):]
+	end := strings.Index(rest, "\n---")
+	if end < 0 {
+		return Note{}, fmt.Errorf("unterminated frontmatter")
+	}
+	frontmatter := rest[:end]
+	body := rest[end+len("\n---"):]
+
+	var note Note
+	inTags := false
+	scanner := bufio.NewScanner(strings.NewReader(frontmatter))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if inTags && strings.HasPrefix(trimmed, "- ") {
+			note.Tags = appendTag(note.Tags, trimmed[2:])
+			continue
+		}
+		inTags = false
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "gomor_id":
+			note.ID = unquote(value)
+		case "kind":
+			kind, err := memtypes.ParseMemoryKind(unquote(value))
+			if err != nil {
+				return Note{}, err
+			}
+			note.Kind = kind
+		case "tags":
+			if value == "" {
+				inTags = true
+				continue
+			}
+			for _, t := range strings.Split(strings.Trim(value, "[]"), ",") {
+				note.Tags = appendTag(note.Tags, t)
+			}
+		}
+	}
+
+	if note.ID == "" {
+		return Note{}, fmt.Errorf("frontmatter has no gomor_id")
+	}
+	note.Text = strings.TrimSpace(body)
+	return note, nil
+}
+
+func appendTag(tags []string, raw string) []string {
+	tag := strings.TrimPrefix(unquote(strings.TrimSpace(raw)), "#")
+	if tag == "" {
+		return tags
+	}
+	return append(tags, tag)
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' && s[len(s)-1] == '"' || s[0] == '\'' && s[len(s)-1] == '\'') {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// yamlString quotes values that YAML would otherwise misread.
+func yamlString(s string) string {
+	if s == "" || strings.ContainsAny(s, ":#[]{},&*!|>'\"%@`") || strings.TrimSpace(s) != s {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
@@ -0,0 +1,233 @@
+package obsidian
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/types"
+	_ "modernc.org/sqlite"
+)
+
+type fakeEmbeddingClient struct {
+	calls int
+}
+
+func (f *fakeEmbeddingClient) Embed(ctx context.Context, model types.Model, text string) ([]float32, error) {
+	f.calls++
+	return []float32{0, 1}, nil
+}
+
+func (f *fakeEmbeddingClient) EmbedBatch(ctx context.Context, model types.Model, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i := range texts {
+		vectors[i], _ = f.Embed(ctx, model, texts[i])
+	}
+	return vectors, nil
+}
+
+func (f *fakeEmbeddingClient) Dimensions(model types.Model) int {
+	return 2
+}
+
+func newTestStore(t *testing.T) *store.Store {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	memStore, err := store.NewStoreWithDB(db)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = memStore.Close()
+	})
+	return memStore
+}
+
+func saveMemory(t *testing.T, memStore *store.Store, text string, tags ...string) *memtypes.MemoryItem {
+	t.Helper()
+
+	item := &memtypes.MemoryItem{
+		Text:      text,
+		Tags:      tags,
+		Source:    memtypes.SourceExplicit,
+		Provider:  "fake",
+		ModelID:   "fake-embedding",
+		Dim:       2,
+		Embedding: []float32{1, 0},
+	}
+	if err := memStore.SaveMemory(item); err != nil {
+		t.Fatalf("save memory: %v", err)
+	}
+	return item
+}
+
+func TestRenderParseRoundTrip(t *testing.T) {
+	item := memtypes.MemoryItem{
+		ID:        "abc",
+		Text:      "Prefers tabs over spaces",
+		Tags:      []string{"editor", "style: code"},
+		Source:    memtypes.SourceExplicit,
+		Kind:      memtypes.KindPreference,
+		CreatedAt: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	note, err := Parse(Render(item))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if note.ID != "abc" || note.Text != item.Text || note.Kind != memtypes.KindPreference {
+		t.Fatalf("unexpected note: %+v", note)
+	}
+	if len(note.Tags) != 2 || note.Tags[0] != "editor" || note.Tags[1] != "style: code" {
+		t.Fatalf("unexpected tags: %v", note.Tags)
+	}
+}
+
+func TestParseAcceptsInlineTags(t *testing.T) {
+	note, err := Parse([]byte("---\ngomor_id: x1\ntags: [\"#work\", go]\n---\nbody text\n"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(note.Tags) != 2 || note.Tags[0] != "work" || note.Tags[1] != "go" {
+		t.Fatalf("unexpected tags: %v", note.Tags)
+	}
+	if note.Text != "body text" {
+		t.Fatalf("unexpected text: %q", note.Text)
+	}
+
+	if _, err := Parse([]byte("# just a note\n")); err == nil {
+		t.Fatal("expected error for note without frontmatter")
+	}
+}
+
+func TestSyncMirrorsAndRemovesStaleNotes(t *testing.T) {
+	memStore := newTestStore(t)
+	item := saveMemory(t, memStore, "Works on the Atlas project", "work")
+	dir := filepath.Join(t.TempDir(), "gomor")
+
+	syncer := NewSyncer(memStore, nil, types.Model{}, nil, dir)
+	result, err := syncer.Sync(context.Background(), false)
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if result.Written != 1 {
+		t.Fatalf("expected 1 note written, got %+v", result)
+	}
+	data, err := os.ReadFile(NotePath(dir, item.ID))
+	if err != nil {
+		t.Fatalf("read note: %v", err)
+	}
+	if !strings.Contains(string(data), "Works on the Atlas project") || !strings.Contains(string(data), "  - work") {
+		t.Fatalf("unexpected note:\n%s", data)
+	}
+
+	userNote := filepath.Join(dir, "my-own-note.md")
+	if err := os.WriteFile(userNote, []byte("# mine\n"), 0o644); err != nil {
+		t.Fatalf("write user note: %v", err)
+	}
+	if err := memStore.DeleteMemory(item.ID); err != nil {
+		t.Fatalf("delete memory: %v", err)
+	}
+
+	result, err = syncer.Sync(context.Background(), false)
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if result.Removed != 1 || result.Written != 0 {
+		t.Fatalf("expected the stale note to be removed, got %+v", result)
+	}
+	if _, err := os.Stat(NotePath(dir, item.ID)); !os.IsNotExist(err) {
+		t.Fatal("expected stale note to be deleted")
+	}
+	if _, err := os.Stat(userNote); err != nil {
+		t.Fatalf("user note should be left alone: %v", err)
+	}
+}
+
+func TestSyncReadBackAppliesEdits(t *testing.T) {
+	memStore := newTestStore(t)
+	edited := saveMemory(t, memStore, "Uses vim", "editor")
+	retagged := saveMemory(t, memStore, "Lives in Berlin")
+	dir := t.TempDir()
+
+	embedder := &fakeEmbeddingClient{}
+	syncer := NewSyncer(memStore, embedder, types.Model{Provider: "fake", ModelID: "fake-embedding"}, nil, dir)
+	if _, err := syncer.Sync(context.Background(), false); err != nil {
+		t.Fatalf("initial sync: %v", err)
+	}
+
+	editNote(t, NotePath(dir, edited.ID), "Uses vim", "Uses neovim")
+	editNote(t, NotePath(dir, retagged.ID), "created:", "tags: [home]\ncreated:")
+
+	result, err := syncer.Sync(context.Background(), true)
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if result.Updated != 2 {
+		t.Fatalf("expected 2 updated memories, got %+v", result)
+	}
+	if embedder.calls != 1 {
+		t.Fatalf("expected only the edited text to be re-embedded, got %d calls", embedder.calls)
+	}
+
+	memories, err := memStore.GetAllMemories()
+	if err != nil {
+		t.Fatalf("get all memories: %v", err)
+	}
+	byID := make(map[string]memtypes.MemoryItem)
+	for _, m := range memories {
+		byID[m.ID] = m
+	}
+	if got := byID[edited.ID]; got.Text != "Uses neovim" || got.Embedding[1] != 1 {
+		t.Fatalf("edit not applied: %+v", got)
+	}
+	if got := byID[retagged.ID]; len(got.Tags) != 1 || got.Tags[0] != "home" {
+		t.Fatalf("tags not applied: %+v", got.Tags)
+	}
+}
+
+func TestSyncWithoutReadBackOverwritesEdits(t *testing.T) {
+	memStore := newTestStore(t)
+	item := saveMemory(t, memStore, "Uses vim")
+	dir := t.TempDir()
+
+	syncer := NewSyncer(memStore, nil, types.Model{}, nil, dir)
+	if _, err := syncer.Sync(context.Background(), false); err != nil {
+		t.Fatalf("initial sync: %v", err)
+	}
+	editNote(t, NotePath(dir, item.ID), "Uses vim", "Uses emacs")
+
+	result, err := syncer.Sync(context.Background(), false)
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if result.Updated != 0 || result.Written != 1 {
+		t.Fatalf("expected note to be rewritten from the store, got %+v", result)
+	}
+	data, _ := os.ReadFile(NotePath(dir, item.ID))
+	if !strings.Contains(string(data), "Uses vim") {
+		t.Fatalf("expected store text to win:\n%s", data)
+	}
+}
+
+func editNote(t *testing.T, path, old, replacement string) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read note: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Replace(string(data), old, replacement, 1)), 0o644); err != nil {
+		t.Fatalf("write note: %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package obsidian
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/memory/embedtext"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/memutils"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+// Result reports the outcome of a sync.
+type Result struct {
+	Dir     string `json:"dir"`
+	Written int    `json:"written"`
+	Updated int    `json:"updated"` // memories changed from edited notes
+	Removed int    `json:"removed"` // notes whose memory no longer exists
+	Pending bool   `json:"pending,omitempty"`
+}
+
+// Syncer mirrors memories into a folder of an Obsidian vault.
+type Syncer struct {
+	store           *store.Store
+	embeddingClient client.EmbeddingClient
+	model           types.Model
+	template        *embedtext.Template
+	dir             string
+}
+
+// NewSyncer creates a syncer for dir. The embedding client is only used to
+// re-embed memories edited in the vault, rendered with tmpl.
+func NewSyncer(s *store.Store, embeddingClient client.EmbeddingClient, model types.Model, tmpl *embedtext.Template, dir string) *Syncer {
+	return &Syncer{
+		store:           s,
+		embeddingClient: embeddingClient,
+		model:           model,
+		template:        tmpl,
+		dir:             dir,
+	}
+}
+
+// NotePath returns the file that mirrors the memory with the given ID.
+func NotePath(dir, id string) string {
+	return filepath.Join(dir, id+".md")
+}
+
+// WriteNote writes a single memory's note, creating dir if needed.
+func WriteNote(dir string, item memtypes.MemoryItem) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	if err := os.WriteFile(NotePath(dir, item.ID), Render(item), 0o644); err != nil {
+		return fmt.Errorf("failed to write note: %w", err)
+	}
+	return nil
+}
+
+// Sync writes a note for every memory and removes notes whose memory was
+// deleted. With readBack, edits made to notes in the vault are applied to the
+// matching memories first; otherwise the store wins and edits are overwritten.
+// Deleting a note never deletes its memory: the note is recreated.
+func (sy *Syncer) Sync(ctx context.Context, readBack bool) (Result, error) {
+	result := Result{Dir: sy.dir}
+
+	memories, err := sy.store.GetAllMemories()
+	if err != nil {
+		return result, err
+	}
+	byID := make(map[string]*memtypes.MemoryItem, len(memories))
+	for i := range memories {
+		byID[memories[i].ID] = &memories[i]
+	}
+
+	notes, err := sy.readNotes()
+	if err != nil {
+		return result, err
+	}
+
+	for path, note := range notes {
+		item, ok := byID[note.ID]
+		if !ok {
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return result, fmt.Errorf("failed to remove %s: %w", path, err)
+			}
+			result.Removed++
+			continue
+		}
+		if !readBack || !changed(*item, note) {
+			continue
+		}
+
+		pending, err := sy.apply(ctx, item, note)
+		if err != nil {
+			return result, err
+		}
+		result.Updated++
+		result.Pending = result.Pending || pending
+	}
+
+	for _, item := range memories {
+		path := NotePath(sy.dir, item.ID)
+		rendered := Render(item)
+		if existing, err := os.ReadFile(path); err == nil && string(existing) == string(rendered) {
+			continue
+		}
+		if err := WriteNote(sy.dir, item); err != nil {
+			return result, err
+		}
+		result.Written++
+	}
+
+	return result, nil
+}
+
+// readNotes parses every gomor note in the folder, keyed by path. Files
+// without a gomor_id (the user's own notes) are left alone.
+func (sy *Syncer) readNotes() (map[string]Note, error) {
+	entries, err := os.ReadDir(sy.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", sy.dir, err)
+	}
+
+	notes := make(map[string]Note)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".md") {
+			continue
+		}
+		path := filepath.Join(sy.dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		note, err := Parse(data)
+		if err != nil {
+			continue
+		}
+		notes[path] = note
+	}
+	return notes, nil
+}
+
+// apply stores a note's edits on item, re-embedding when the text it embeds
+// changed.
+// It reports whether the embedding was queued instead of computed.
+func (sy *Syncer) apply(ctx context.Context, item *memtypes.MemoryItem, note Note) (bool, error) {
+	kind := note.Kind
+	if kind == "" {
+		kind = item.Kind
+	}
+	if err := sy.store.UpdateMemoryContent(item.ID, note.Text, note.Tags, kind); err != nil {
+		return false, err
+	}
+
+	before := strings.TrimSpace(sy.template.Render(*item))
+	item.Text = note.Text
+	item.Tags = note.Tags
+	item.Kind = kind
+	text := sy.template.Render(*item)
+	if before == text {
+		return false, nil
+	}
+
+	embedding, err := sy.embeddingClient.Embed(ctx, sy.model, text)
+	if err != nil {
+		if err := sy.store.EnqueueEmbedding(memtypes.EmbeddingTargetMemory, item.ID); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	embedding = memutils.NormalizeVector(embedding)
+	if err := sy.store.UpdateMemoryEmbedding(item.ID, embedding, sy.model.ModelID, len(embedding), sy.model.Provider); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+func changed(item memtypes.MemoryItem, note Note) bool {
+	if note.Text == "" {
+		return false
+	}
+	if note.Kind != "" && note.Kind != item.Kind {
+		return true
+	}
+	return note.Text != strings.TrimSpace(item.Text) || !slices.Equal(note.Tags, item.Tags)
+}
@@ -0,0 +1,34 @@
+package retrieval
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRetrieveExcludeTagsMatchesAliases(t *testing.T) {
+	memStore := newTestStore(t)
+	for _, item := range []*MemoryItem{
+		{Text: "C++ virtual functions enable polymorphism in the old js bindings", Tags: []string{"js"}},
+		{Text: "C++ virtual functions enable polymorphism", Tags: []string{"cpp"}},
+	} {
+		item.Source = SourceExplicit
+		item.Provider = "fake"
+		item.ModelID = "fake-embedding"
+		item.Dim = 2
+		item.Embedding = NormalizeVector([]float32{1, 0})
+		if err := memStore.SaveMemory(item); err != nil {
+			t.Fatalf("save memory: %v", err)
+		}
+	}
+
+	retriever := newTestRetriever(memStore)
+	retriever.config.TagAliases = map[string]string{"js": "javascript"}
+
+	resp, err := retriever.RetrieveWithOptions(context.Background(), "C++ virtual functions", RetrieveOptions{ExcludeTags: []string{"JavaScript"}})
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].Item.Tags[0] != "cpp" {
+		t.Fatalf("expected the alias-tagged memory excluded, got %+v", resp.Results)
+	}
+}
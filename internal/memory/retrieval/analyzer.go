@@ -0,0 +1,107 @@
+package retrieval
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// stopWords are the built-in full-text stop words by language. Languages
+// without a list keep every term.
+var stopWords = map[string][]string{
+	"English": {
+		"a", "about", "an", "and", "are", "as", "at", "be", "by", "can", "do", "does",
+		"for", "from", "how", "i", "in", "is", "it", "my", "of", "on", "or", "our",
+		"should", "that", "the", "this", "to", "was", "we", "what", "when", "where",
+		"which", "who", "why", "will", "with", "you", "your",
+	},
+	"Spanish": {
+		"a", "al", "como", "con", "de", "del", "el", "en", "es", "la", "las", "lo",
+		"los", "mi", "para", "por", "que", "qué", "se", "su", "un", "una", "y",
+	},
+	"French": {
+		"à", "au", "aux", "ce", "comment", "dans", "de", "des", "du", "est", "et",
+		"il", "je", "la", "le", "les", "mon", "ou", "par", "pour", "que", "quel",
+		"qui", "sur", "un", "une",
+	},
+	"German": {
+		"als", "am", "auf", "das", "dem", "den", "der", "die", "ein", "eine", "für",
+		"ich", "im", "in", "ist", "mit", "oder", "und", "von", "was", "wie", "wir",
+		"zu",
+	},
+}
+
+// ftsAnalyzer decides which query terms go into a full-text search.
+type ftsAnalyzer struct {
+	minLength int
+	stopWords map[string]bool
+}
+
+// analyzer returns the analyzer for query: config.FTSStopWords when set,
+// otherwise the built-in stop words of config.Language when it names a
+// language, or of the language query is written in.
+func analyzer(config utils.MemoryConfig, query string) ftsAnalyzer {
+	words := config.FTSStopWords
+	if words == nil {
+		language := config.Language
+		if language == "" || language == utils.MemoryLanguageAuto || language == utils.MemoryLanguageOff {
+			language = DetectLanguage(query)
+		}
+		for name, list := range stopWords {
+			if strings.EqualFold(name, language) {
+				words = list
+				break
+			}
+		}
+	}
+
+	a := ftsAnalyzer{minLength: max(config.FTSMinTokenLength, 1), stopWords: make(map[string]bool, len(words))}
+	for _, w := range words {
+		a.stopWords[strings.ToLower(w)] = true
+	}
+	return a
+}
+
+// tokenizeForFTS converts a query string to an FTS-safe query.
+func tokenizeForFTS(query string, a ftsAnalyzer) string {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return ""
+	}
+
+	// Split into words and filter
+	words := strings.Fields(query)
+	var tokens, stopped []string
+	for _, w := range words {
+		// Remove FTS special characters (FTS5 operators: AND OR NOT NEAR + - * ^ : " ')
+		w = strings.ReplaceAll(w, "\"", "")
+		w = strings.ReplaceAll(w, "'", "")
+		w = strings.ReplaceAll(w, "*", "")
+		w = strings.ReplaceAll(w, "-", " ")
+		w = strings.ReplaceAll(w, "+", "")
+		w = strings.ReplaceAll(w, "^", "")
+		w = strings.ReplaceAll(w, ":", "")
+		w = strings.ReplaceAll(w, "(", "")
+		w = strings.ReplaceAll(w, ")", "")
+		w = strings.TrimSpace(w)
+		switch {
+		case w == "" || utf8.RuneCountInString(w) < a.minLength:
+		case a.stopWords[strings.ToLower(w)]:
+			stopped = append(stopped, w)
+		default:
+			tokens = append(tokens, w)
+		}
+	}
+
+	// A query made only of stop words ("the who") is searched as written.
+	if len(tokens) == 0 {
+		tokens = stopped
+	}
+	if len(tokens) == 0 {
+		return ""
+	}
+
+	// Join with OR for broader matching
+	return strings.Join(tokens, " OR ")
+}
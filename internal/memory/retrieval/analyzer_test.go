@@ -0,0 +1,51 @@
+package retrieval
+
+import (
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+func TestTokenizeForFTS(t *testing.T) {
+	defaults := utils.DefaultConfig().Memory
+	cases := []struct {
+		name   string
+		config func(c *utils.MemoryConfig)
+		query  string
+		want   string
+	}{
+		{"single letters are kept", nil, "how do I profile C code", "profile OR C OR code"},
+		{"operators are stripped", nil, `"deploy" pipeline*`, "deploy OR pipeline"},
+		{"only stop words", nil, "The Who", "The OR Who"},
+		{"language of the query", nil, "стоп слова", "стоп OR слова"},
+		{"minimum length", func(c *utils.MemoryConfig) { c.FTSMinTokenLength = 2 }, "R plots in ggplot", "plots OR ggplot"},
+		{"custom stop words", func(c *utils.MemoryConfig) { c.FTSStopWords = []string{"Plots"} }, "R plots in ggplot", "R OR in OR ggplot"},
+		{"stop words off", func(c *utils.MemoryConfig) { c.FTSStopWords = []string{} }, "what is R", "what OR is OR R"},
+		{"configured language", func(c *utils.MemoryConfig) { c.Language = "german" }, "wie ist der Build", "Build"},
+	}
+	for _, c := range cases {
+		config := defaults
+		if c.config != nil {
+			c.config(&config)
+		}
+		if got := tokenizeForFTS(c.query, analyzer(config, c.query)); got != c.want {
+			t.Errorf("%s: tokenizeForFTS(%q) = %q, want %q", c.name, c.query, got, c.want)
+		}
+	}
+}
+
+func TestFTSSearchFindsSingleLetterTerms(t *testing.T) {
+	memStore := newTestStore(t)
+	saveTextMemory(t, memStore, "Statistics scripts are written in R")
+	saveTextMemory(t, memStore, "The build uses Bazel")
+
+	retriever := NewRetriever(memStore, nil, nil, types.Model{}, types.Model{}, utils.DefaultConfig().Memory)
+	results, err := retriever.ftsSearchDirect("R", MemoryFilter{})
+	if err != nil {
+		t.Fatalf("fts search: %v", err)
+	}
+	if len(results) != 1 || results[0].Item.Text != "Statistics scripts are written in R" {
+		t.Fatalf("expected the memory mentioning R, got %+v", results)
+	}
+}
@@ -0,0 +1,70 @@
+package retrieval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/austiecodes/gomor/internal/memory/store"
+)
+
+// RetrieveAll retrieves memories for each of queries with opts, returning the
+// responses in the order of queries. The stored memories are read once and
+// shared by every vector search. A memory found by several queries is kept
+// only in the response of the query it scores highest for, the earliest on a
+// tie, so each memory is returned once.
+func (r *Retriever) RetrieveAll(ctx context.Context, queries []string, opts RetrieveOptions) ([]*RetrievalResponse, error) {
+	if r.embeddingClient != nil {
+		memories, err := r.store.SearchableMemories()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read memories: %w", err)
+		}
+		// An empty store still shares its (empty) read.
+		if memories == nil {
+			memories = []MemoryItem{}
+		}
+		opts.memories = memories
+
+		// Flagging is best effort: every response warns about these memories.
+		var malformed *store.MalformedEmbeddingsError
+		if errors.As(store.MalformedEmbeddings(memories), &malformed) {
+			_ = r.store.MarkNeedsReembedding(malformed.IDs)
+		}
+	}
+
+	responses := make([]*RetrievalResponse, len(queries))
+	for i, query := range queries {
+		resp, err := r.RetrieveWithOptions(ctx, query, opts)
+		if err != nil {
+			return nil, fmt.Errorf("query %q: %w", query, err)
+		}
+		responses[i] = resp
+	}
+	dedupeAcross(responses)
+	return responses, nil
+}
+
+// dedupeAcross keeps each memory only in the response it scores highest in.
+func dedupeAcross(responses []*RetrievalResponse) {
+	type placement struct {
+		response int
+		score    float64
+	}
+	best := make(map[string]placement)
+	for i, resp := range responses {
+		for _, res := range resp.Results {
+			if p, seen := best[res.Item.ID]; !seen || res.Score > p.score {
+				best[res.Item.ID] = placement{response: i, score: res.Score}
+			}
+		}
+	}
+	for i, resp := range responses {
+		kept := resp.Results[:0]
+		for _, res := range resp.Results {
+			if best[res.Item.ID].response == i {
+				kept = append(kept, res)
+			}
+		}
+		resp.Results = kept
+	}
+}
@@ -0,0 +1,30 @@
+package retrieval
+
+import "testing"
+
+func TestDedupeAcrossKeepsBestScoringQuery(t *testing.T) {
+	result := func(id string, score float64) UnifiedResult {
+		return UnifiedResult{Item: MemoryItem{ID: id}, Score: score}
+	}
+	responses := []*RetrievalResponse{
+		{Query: "a", Results: []UnifiedResult{result("m1", 0.9), result("m2", 0.4), result("m3", 0.5)}},
+		{Query: "b", Results: []UnifiedResult{result("m2", 0.8), result("m3", 0.5), result("m4", 0.3)}},
+	}
+
+	dedupeAcross(responses)
+
+	got := func(resp *RetrievalResponse) []string {
+		var ids []string
+		for _, r := range resp.Results {
+			ids = append(ids, r.Item.ID)
+		}
+		return ids
+	}
+	a, b := got(responses[0]), got(responses[1])
+	if len(a) != 2 || a[0] != "m1" || a[1] != "m3" {
+		t.Fatalf("query a: expected m1 and the tied m3, got %v", a)
+	}
+	if len(b) != 2 || b[0] != "m2" || b[1] != "m4" {
+		t.Fatalf("query b: expected m2 and m4, got %v", b)
+	}
+}
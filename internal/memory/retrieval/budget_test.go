@@ -0,0 +1,49 @@
+package retrieval
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/consts"
+	"github.com/austiecodes/gomor/internal/tokenizer"
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+func TestFitTokensDropsLowestRankedResults(t *testing.T) {
+	counter := tokenizer.ForModel(types.Model{Provider: consts.ProviderOpenAI, ModelID: "gpt-4o-mini"})
+
+	resp := &RetrievalResponse{Query: "q"}
+	for i := 0; i < 10; i++ {
+		resp.Results = append(resp.Results, UnifiedResult{
+			Item:   MemoryItem{Text: fmt.Sprintf("memory %d %s", i, strings.Repeat("word ", 20))},
+			Score:  1 - float64(i)/10,
+			Source: "both",
+		})
+	}
+
+	FitTokens(resp, counter, 100)
+	if len(resp.Results) == 0 || len(resp.Results) == 10 {
+		t.Fatalf("expected some results to be dropped, got %d", len(resp.Results))
+	}
+	if resp.Omitted != 10-len(resp.Results) {
+		t.Fatalf("expected omitted to count dropped results, got %d", resp.Omitted)
+	}
+	if !strings.HasPrefix(resp.Results[0].Item.Text, "memory 0 ") {
+		t.Fatalf("expected the top result to be kept, got %q", resp.Results[0].Item.Text)
+	}
+
+	text := FormatAsText(resp)
+	if tokens := counter.Count(text); tokens > 100 {
+		t.Fatalf("formatted results use %d tokens, over the budget of 100", tokens)
+	}
+	if !strings.Contains(text, "omitted to fit the token budget") {
+		t.Fatalf("expected the omission to be reported, got %q", text)
+	}
+
+	// The top result is kept even when it alone is over budget.
+	FitTokens(resp, counter, 1)
+	if len(resp.Results) != 1 {
+		t.Fatalf("expected the top result to remain, got %d", len(resp.Results))
+	}
+}
@@ -0,0 +1,135 @@
+package retrieval
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/temporal"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// QueryClass labels how a query is worded, which decides how it is retrieved.
+type QueryClass string
+
+const (
+	// QueryKeyword is a few search terms, e.g. "postgres version".
+	QueryKeyword QueryClass = "keyword"
+	// QueryQuestion is a question or a follow-up to the conversation.
+	QueryQuestion QueryClass = "question"
+	// QueryTemporal asks about a time, e.g. "what did I fix yesterday".
+	QueryTemporal QueryClass = "temporal"
+	// QueryPreference asks about the user's own tastes or habits.
+	QueryPreference QueryClass = "preference"
+)
+
+// queryPlan is how queries of a class are retrieved.
+type queryPlan struct {
+	ftsStrategy string
+	// expansions is how many tool-model transformations of the query are
+	// searched besides the query itself: 0, 1 (a rephrasing), or 2 (a
+	// rephrasing and a hypothetical answer).
+	expansions int
+	// useHistory lets conversation history rewrite the query.
+	useHistory bool
+}
+
+// defaultExpansions is how many transformations unclassified queries search.
+const defaultExpansions = 2
+
+var queryPlans = map[QueryClass]queryPlan{
+	// Terms are best searched as typed.
+	QueryKeyword:  {ftsStrategy: utils.FTSStrategyDirect, expansions: 0},
+	QueryQuestion: {ftsStrategy: utils.FTSStrategyAuto, expansions: 2, useHistory: true},
+	// The time range is filtered on; what is left is usually a few terms.
+	QueryTemporal: {ftsStrategy: utils.FTSStrategyDirect, expansions: 1},
+	// A made-up answer would guess at the preference, so only rephrase.
+	QueryPreference: {ftsStrategy: utils.FTSStrategyAuto, expansions: 1, useHistory: true},
+}
+
+var (
+	questionStart = regexp.MustCompile(`^(what|how|why|when|where|who|whom|whose|which|is|are|was|were|do|does|did|can|could|should|would|will|have|has|had)\b`)
+	// followUp marks queries that refer back to the conversation.
+	followUp    = regexp.MustCompile(`^(and|but|also|what about|how about)\b|\b(it|its|that|this|those|these|they|them|one|same|above|previous|former|latter)\b`)
+	firstPerson = regexp.MustCompile(`\b(i|my|me|mine|i'm|i've)\b`)
+	preferring  = regexp.MustCompile(`\b(prefer\w*|favou?rite|like|likes|love|hate|dislike|usually|always|never|style|habit\w*|taste)\b`)
+)
+
+// keywordWords is the most words a query without question wording may have
+// to be read as search terms.
+const keywordWords = 4
+
+// classifyWording labels query by its wording alone. sure is false when the
+// wording leaves the label unclear: a statement too long to be search terms.
+func classifyWording(query string, now time.Time) (class QueryClass, sure bool) {
+	if _, _, ok := temporal.Parse(query, now); ok {
+		return QueryTemporal, true
+	}
+	q := strings.ToLower(strings.TrimSpace(query))
+	if firstPerson.MatchString(q) && preferring.MatchString(q) {
+		return QueryPreference, true
+	}
+	if strings.HasSuffix(q, "?") || questionStart.MatchString(q) || followUp.MatchString(q) {
+		return QueryQuestion, true
+	}
+	if len(strings.Fields(q)) <= keywordWords {
+		return QueryKeyword, true
+	}
+	return QueryQuestion, false
+}
+
+// classifyQuery labels query as configured by memory.query_classifier, or
+// returns "" when classification is off.
+func (r *Retriever) classifyQuery(ctx context.Context, query string) QueryClass {
+	switch r.config.QueryClassifier {
+	case utils.QueryClassifierHeuristic, utils.QueryClassifierModel:
+	default:
+		return ""
+	}
+	class, sure := classifyWording(query, time.Now())
+	if sure || r.config.QueryClassifier != utils.QueryClassifierModel || r.queryClient == nil {
+		return class
+	}
+	if asked := r.askQueryClass(ctx, query); asked != "" {
+		return asked
+	}
+	return class
+}
+
+// askQueryClass asks the tool model to label query, returning "" when it
+// fails or answers with something else.
+func (r *Retriever) askQueryClass(ctx context.Context, query string) QueryClass {
+	prompt := fmt.Sprintf(`Classify this memory search query as one of:
+keyword - a few search terms
+question - a question, or a follow-up to a conversation
+temporal - about what happened at some time
+preference - about the user's own tastes, habits, or preferences
+
+Query: %s
+
+Respond with ONLY the label, no other text.`, query)
+
+	ctx, cancel := context.WithTimeout(ctx, r.transformTimeout())
+	defer cancel()
+	stream, err := r.queryClient.ChatStream(ctx, r.toolModel, prompt)
+	if err != nil {
+		return ""
+	}
+	defer stream.Close()
+
+	var sb strings.Builder
+	for stream.Next() {
+		sb.WriteString(stream.GetChunk())
+	}
+	if stream.Err() != nil {
+		return ""
+	}
+
+	class := QueryClass(strings.ToLower(strings.Trim(strings.TrimSpace(sb.String()), "\"'.")))
+	if _, ok := queryPlans[class]; !ok {
+		return ""
+	}
+	return class
+}
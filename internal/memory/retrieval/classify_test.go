@@ -0,0 +1,77 @@
+package retrieval
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+func TestClassifyWording(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		query string
+		want  QueryClass
+		sure  bool
+	}{
+		{query: "postgres version", want: QueryKeyword, sure: true},
+		{query: "what did I fix yesterday", want: QueryTemporal, sure: true},
+		{query: "which editor do I prefer", want: QueryPreference, sure: true},
+		{query: "my favourite font", want: QueryPreference, sure: true},
+		{query: "how do we deploy to staging", want: QueryQuestion, sure: true},
+		{query: "staging deploys?", want: QueryQuestion, sure: true},
+		{query: "and the second one", want: QueryQuestion, sure: true},
+		{query: "deploys go out after the weekly planning meeting", want: QueryQuestion, sure: false},
+	}
+	for _, tt := range tests {
+		got, sure := classifyWording(tt.query, now)
+		if got != tt.want || sure != tt.sure {
+			t.Errorf("classifyWording(%q) = %s, %v; want %s, %v", tt.query, got, sure, tt.want, tt.sure)
+		}
+	}
+}
+
+func TestRetrievePlansByQueryClass(t *testing.T) {
+	memStore := newTestStore(t)
+	saveTextMemory(t, memStore, "The deploy pipeline runs on Argo")
+
+	queryClient := &recordingQueryClient{answers: map[string]string{"Classify this memory search query": "preference"}}
+	r := newTestRetriever(memStore)
+	r.queryClient = queryClient
+	r.config.QueryClassifier = utils.QueryClassifierHeuristic
+	history := []HistoryItem{{Role: "user", Content: "How do we ship releases?"}}
+
+	resp, err := r.RetrieveWithOptions(context.Background(), "deploy pipeline", RetrieveOptions{History: history})
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if resp.Class != string(QueryKeyword) || len(queryClient.recorded()) != 0 {
+		t.Fatalf("expected keywords searched as typed without the tool model, got %s and %d prompts", resp.Class, len(queryClient.recorded()))
+	}
+
+	resp, err = r.RetrieveWithOptions(context.Background(), "what about the deploy pipeline?", RetrieveOptions{History: history})
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if resp.Class != string(QueryQuestion) || queryClient.asked("standalone search query") != 1 || queryClient.asked("two transformations") != 1 {
+		t.Fatalf("expected a question rewritten from history and expanded, got %s and prompts %q", resp.Class, queryClient.recorded())
+	}
+	if queryClient.asked("Classify") != 0 {
+		t.Fatal("expected the heuristic classifier not to ask the tool model")
+	}
+
+	r.config.QueryClassifier = utils.QueryClassifierModel
+	resp, err = r.RetrieveWithOptions(context.Background(), "deploys go out after the weekly planning meeting", RetrieveOptions{})
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if resp.Class != string(QueryPreference) || queryClient.asked("Classify") != 1 {
+		t.Fatalf("expected the tool model to label the unclear query, got %s", resp.Class)
+	}
+
+	r.config.QueryClassifier = utils.QueryClassifierOff
+	if resp, err = r.RetrieveWithOptions(context.Background(), "deploy pipeline", RetrieveOptions{}); err != nil || resp.Class != "" {
+		t.Fatalf("expected no class with the classifier off, got %q (%v)", resp.Class, err)
+	}
+}
@@ -0,0 +1,144 @@
+package retrieval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/tokenizer"
+)
+
+const (
+	// compressBatchTokens bounds the memories summarized by one tool-model call.
+	compressBatchTokens = 4000
+	// maxReduceRounds bounds how many times summaries are merged to fit.
+	maxReduceRounds = 3
+	// compressTimeout bounds all the tool-model calls of one compression.
+	compressTimeout = 60 * time.Second
+	// minSummaryTokens is the smallest budget worth summarizing into.
+	minSummaryTokens = 32
+)
+
+// Compress fits resp into maxTokens by summarizing its results with the tool
+// model rather than dropping the lowest-ranked ones as FitTokens does. The
+// results are summarized in batches (map) and the batch summaries merged
+// until they fit (reduce); each fact keeps the IDs of the memories it came
+// from. The summary is stored in resp.Summary, which FormatAsText shows in
+// place of the results; the results themselves are kept. Results that already
+// fit are left as they are.
+func (r *Retriever) Compress(ctx context.Context, resp *RetrievalResponse, counter tokenizer.Counter, maxTokens int) error {
+	if resp == nil || maxTokens <= 0 || len(resp.Results) == 0 || counter.Count(FormatAsText(resp)) <= maxTokens {
+		return nil
+	}
+	if r.queryClient == nil {
+		return errors.New("compression needs a tool model")
+	}
+
+	// The summary shares the budget with the header FormatAsText puts above it.
+	resp.Summary = " "
+	budget := maxTokens - counter.Count(FormatAsText(resp))
+	resp.Summary = ""
+	if budget < minSummaryTokens {
+		return fmt.Errorf("token budget of %d is too small to summarize into", maxTokens)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, compressTimeout)
+	defer cancel()
+
+	query := resp.Query
+	if resp.RewrittenQuery != "" {
+		query = resp.RewrittenQuery
+	}
+
+	lines := make([]string, 0, len(resp.Results))
+	for _, result := range resp.Results {
+		lines = append(lines, fmt.Sprintf("[%s] %s", result.Item.ID, strings.Join(strings.Fields(result.Item.Text), " ")))
+	}
+	batches := batchLines(lines, counter, compressBatchTokens)
+
+	// Each batch gets its share of the budget, so that the summaries together
+	// usually fit without a reduce round.
+	share := max(budget/len(batches), minSummaryTokens)
+	summaries := make([]string, 0, len(batches))
+	for _, batch := range batches {
+		summary, err := r.summarize(ctx, query, "Memories", batch, share)
+		if err != nil {
+			return err
+		}
+		summaries = append(summaries, summary)
+	}
+
+	summary := strings.Join(summaries, "\n")
+	for round := 0; counter.Count(summary) > budget; round++ {
+		if round == maxReduceRounds {
+			return fmt.Errorf("summary still exceeds the token budget of %d after %d rounds", maxTokens, maxReduceRounds)
+		}
+		var err error
+		if summary, err = r.summarize(ctx, query, "Summaries of memories", summary, budget); err != nil {
+			return err
+		}
+	}
+
+	resp.Summary = summary
+	return nil
+}
+
+// batchLines groups lines, in order, into batches of at most maxTokens. A
+// line over the limit gets a batch of its own.
+func batchLines(lines []string, counter tokenizer.Counter, maxTokens int) []string {
+	var batches []string
+	var batch strings.Builder
+	tokens := 0
+	for _, line := range lines {
+		n := counter.Count(line) + 1
+		if batch.Len() > 0 && tokens+n > maxTokens {
+			batches = append(batches, batch.String())
+			batch.Reset()
+			tokens = 0
+		}
+		batch.WriteString(line)
+		batch.WriteString("\n")
+		tokens += n
+	}
+	if batch.Len() > 0 {
+		batches = append(batches, batch.String())
+	}
+	return batches
+}
+
+// summarize asks the tool model to condense material, labeled by what it is,
+// to what is relevant to query in at most maxTokens.
+func (r *Retriever) summarize(ctx context.Context, query, label, material string, maxTokens int) (string, error) {
+	prompt := fmt.Sprintf(`Condense the memories below into notes for answering the query, in at most %d tokens.
+Keep every fact relevant to the query, drop the rest, and merge duplicates.
+End each fact with the IDs of the memories it came from in square brackets,
+exactly as given, e.g. [id-1, id-2].
+
+Query: %s
+
+%s:
+%s
+Respond with ONLY the notes, one fact per line.`, maxTokens, query, label, material)
+
+	stream, err := r.queryClient.ChatStream(ctx, r.toolModel, prompt)
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize memories: %w", err)
+	}
+	defer stream.Close()
+
+	var sb strings.Builder
+	for stream.Next() {
+		sb.WriteString(stream.GetChunk())
+	}
+	if err := stream.Err(); err != nil {
+		return "", fmt.Errorf("failed to summarize memories: %w", err)
+	}
+
+	summary := strings.TrimSpace(sb.String())
+	if summary == "" {
+		return "", errors.New("the tool model returned an empty summary")
+	}
+	return summary, nil
+}
@@ -0,0 +1,121 @@
+package retrieval
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/consts"
+	"github.com/austiecodes/gomor/internal/tokenizer"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// compressQueryClient answers map calls with mapped and reduce calls, which
+// summarize earlier summaries, with reduced.
+type compressQueryClient struct {
+	mapped  string
+	reduced string
+	prompts []string
+}
+
+func (c *compressQueryClient) ChatStream(ctx context.Context, model types.Model, query string) (client.StreamResponse, error) {
+	c.prompts = append(c.prompts, query)
+	if strings.Contains(query, "\nSummaries of memories:\n") {
+		return &fakeStream{chunks: []string{c.reduced}}, nil
+	}
+	return &fakeStream{chunks: []string{c.mapped}}, nil
+}
+
+func (c *compressQueryClient) ChatStreamWithContext(ctx context.Context, model types.Model, systemContext, query string) (client.StreamResponse, error) {
+	return c.ChatStream(ctx, model, query)
+}
+
+func (c *compressQueryClient) ListModels(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func longResponse(n int) *RetrievalResponse {
+	resp := &RetrievalResponse{Query: "how do we deploy?"}
+	for i := 0; i < n; i++ {
+		resp.Results = append(resp.Results, UnifiedResult{
+			Item:   MemoryItem{ID: fmt.Sprintf("mem-%d", i), Text: fmt.Sprintf("deploy note %d %s", i, strings.Repeat("detail ", 200))},
+			Score:  1 - float64(i)/float64(n),
+			Source: "both",
+		})
+	}
+	return resp
+}
+
+func TestCompressSummarizesInBatchesKeepingIDs(t *testing.T) {
+	counter := tokenizer.ForModel(types.Model{Provider: consts.ProviderOpenAI, ModelID: "gpt-4o-mini"})
+	queryClient := &compressQueryClient{mapped: "Deploys run on Argo [mem-0, mem-3]"}
+	retriever := NewRetriever(nil, nil, queryClient, types.Model{}, types.Model{}, utils.MemoryConfig{})
+
+	resp := longResponse(30)
+	if err := retriever.Compress(context.Background(), resp, counter, 300); err != nil {
+		t.Fatalf("compress: %v", err)
+	}
+	if len(queryClient.prompts) < 2 {
+		t.Fatalf("expected the memories to be summarized in several batches, got %d calls", len(queryClient.prompts))
+	}
+	if !strings.Contains(queryClient.prompts[0], "[mem-0] deploy note 0") || !strings.Contains(queryClient.prompts[0], "Query: how do we deploy?") {
+		t.Fatalf("expected memories labeled with their IDs and the query in the prompt, got:\n%.300s", queryClient.prompts[0])
+	}
+	if len(resp.Results) != 30 || resp.Omitted != 0 {
+		t.Fatalf("expected the results to be kept, got %d (omitted %d)", len(resp.Results), resp.Omitted)
+	}
+
+	text := FormatAsText(resp)
+	if !strings.Contains(text, "Found 30 memories, summarized to fit the token budget:") || !strings.Contains(text, "[mem-0, mem-3]") {
+		t.Fatalf("expected the summary in place of the results, got:\n%s", text)
+	}
+	if tokens := counter.Count(text); tokens > 300 {
+		t.Fatalf("summary uses %d tokens, over the budget of 300", tokens)
+	}
+}
+
+func TestCompressMergesSummariesOverBudget(t *testing.T) {
+	counter := tokenizer.ForModel(types.Model{Provider: consts.ProviderOpenAI, ModelID: "gpt-4o-mini"})
+	queryClient := &compressQueryClient{
+		mapped:  strings.Repeat("a long partial summary [mem-1]\n", 20),
+		reduced: "merged [mem-1]",
+	}
+	retriever := NewRetriever(nil, nil, queryClient, types.Model{}, types.Model{}, utils.MemoryConfig{})
+
+	resp := longResponse(30)
+	if err := retriever.Compress(context.Background(), resp, counter, 300); err != nil {
+		t.Fatalf("compress: %v", err)
+	}
+	last := queryClient.prompts[len(queryClient.prompts)-1]
+	if !strings.Contains(last, "Summaries of memories:\na long partial summary") || resp.Summary != "merged [mem-1]" {
+		t.Fatalf("expected the batch summaries to be merged, got summary %q", resp.Summary)
+	}
+
+	queryClient.reduced = strings.Repeat("still too long [mem-1]\n", 100)
+	resp = longResponse(30)
+	if err := retriever.Compress(context.Background(), resp, counter, 300); err == nil || resp.Summary != "" {
+		t.Fatalf("expected an error when the summary never fits, got %v / %q", err, resp.Summary)
+	}
+}
+
+func TestCompressLeavesFittingResultsAlone(t *testing.T) {
+	counter := tokenizer.ForModel(types.Model{Provider: consts.ProviderOpenAI, ModelID: "gpt-4o-mini"})
+	queryClient := &compressQueryClient{mapped: "unused"}
+	retriever := NewRetriever(nil, nil, queryClient, types.Model{}, types.Model{}, utils.MemoryConfig{})
+
+	resp := longResponse(1)
+	if err := retriever.Compress(context.Background(), resp, counter, 10000); err != nil {
+		t.Fatalf("compress: %v", err)
+	}
+	if len(queryClient.prompts) != 0 || resp.Summary != "" {
+		t.Fatalf("expected no summary for results within the budget, got %d calls", len(queryClient.prompts))
+	}
+
+	noTool := NewRetriever(nil, nil, nil, types.Model{}, types.Model{}, utils.MemoryConfig{})
+	if err := noTool.Compress(context.Background(), longResponse(30), counter, 300); err == nil {
+		t.Fatal("expected an error without a tool model")
+	}
+}
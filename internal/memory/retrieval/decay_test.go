@@ -19,6 +19,9 @@ func newTestStore(t *testing.T) *store.Store {
 	if err != nil {
 		t.Fatalf("open sqlite: %v", err)
 	}
+	// Every connection to ":memory:" opens a database of its own, so the
+	// concurrent search paths must share one.
+	db.SetMaxOpenConns(1)
 
 	memStore, err := store.NewStoreWithDB(db)
 	if err != nil {
@@ -82,14 +85,16 @@ func TestRetrievePrefersFresherMemory(t *testing.T) {
 	if err != nil {
 		t.Fatalf("retrieve: %v", err)
 	}
-	if len(resp.Results) < 2 {
-		t.Fatalf("expected 2 results, got %d", len(resp.Results))
+	// The memories share their text, so they merge into one result; freshness
+	// decides which of them it shows.
+	if len(resp.Results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(resp.Results))
 	}
 	if resp.Results[0].Item.ID != fresher.ID {
 		t.Fatalf("expected fresher memory first, got %s want %s", resp.Results[0].Item.ID, fresher.ID)
 	}
-	if resp.Results[0].Freshness <= resp.Results[1].Freshness {
-		t.Fatalf("expected fresher memory to have higher freshness, got %.4f <= %.4f", resp.Results[0].Freshness, resp.Results[1].Freshness)
+	if ids := resp.Results[0].DuplicateIDs; len(ids) != 1 || ids[0] != older.ID {
+		t.Fatalf("expected the older memory merged into the fresher one, got %v", ids)
 	}
 }
 
@@ -0,0 +1,117 @@
+package retrieval
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRetrieveMergesDuplicateTexts(t *testing.T) {
+	memStore := newTestStore(t)
+	var ids []string
+	for _, text := range []string{
+		"C++ virtual functions enable polymorphism",
+		"c++  Virtual functions enable\npolymorphism",
+	} {
+		item := &MemoryItem{
+			Text:      text,
+			Source:    SourceExplicit,
+			Provider:  "fake",
+			ModelID:   "fake-embedding",
+			Dim:       2,
+			Embedding: NormalizeVector([]float32{1, 0}),
+		}
+		if err := memStore.SaveMemory(item); err != nil {
+			t.Fatalf("save memory: %v", err)
+		}
+		ids = append(ids, item.ID)
+	}
+
+	resp, err := newTestRetriever(memStore).Retrieve(context.Background(), "virtual functions")
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if len(resp.Results) != 1 {
+		t.Fatalf("expected duplicates merged into one result, got %d", len(resp.Results))
+	}
+	got := resp.Results[0]
+	if len(got.DuplicateIDs) != 1 {
+		t.Fatalf("expected one duplicate ID, got %v", got.DuplicateIDs)
+	}
+	merged := map[string]bool{got.Item.ID: true, got.DuplicateIDs[0]: true}
+	if !merged[ids[0]] || !merged[ids[1]] {
+		t.Fatalf("merged IDs %v, want %v", merged, ids)
+	}
+	if got.Source != "both" {
+		t.Fatalf("source = %q, want both", got.Source)
+	}
+}
+
+func TestRetrieveRanksNearDuplicateTextsByFreshness(t *testing.T) {
+	memStore := newTestStore(t)
+	now := time.Now().UTC()
+
+	// The texts differ only in punctuation, so they match equally well but
+	// are not merged.
+	var items []*MemoryItem
+	for _, mem := range []struct {
+		text string
+		age  time.Duration
+	}{
+		{"C++ virtual functions enable polymorphism.", 60 * 24 * time.Hour},
+		{"C++ virtual functions enable polymorphism", 2 * 24 * time.Hour},
+	} {
+		item := &MemoryItem{
+			Text:      mem.text,
+			Source:    SourceExplicit,
+			CreatedAt: now.Add(-mem.age),
+			Provider:  "fake",
+			ModelID:   "fake-embedding",
+			Dim:       2,
+			Embedding: NormalizeVector([]float32{1, 0}),
+		}
+		if err := memStore.SaveMemory(item); err != nil {
+			t.Fatalf("save memory: %v", err)
+		}
+		items = append(items, item)
+	}
+
+	resp, err := newTestRetriever(memStore).Retrieve(context.Background(), "C++ virtual functions polymorphism")
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if len(resp.Results) != 2 {
+		t.Fatalf("expected near-duplicates kept apart, got %d results", len(resp.Results))
+	}
+	if resp.Results[0].Item.ID != items[1].ID {
+		t.Fatalf("expected fresher memory first, got %s want %s", resp.Results[0].Item.ID, items[1].ID)
+	}
+	if resp.Results[0].Freshness <= resp.Results[1].Freshness {
+		t.Fatalf("expected fresher memory to have higher freshness, got %.4f <= %.4f", resp.Results[0].Freshness, resp.Results[1].Freshness)
+	}
+}
+
+func TestDedupeResultsKeepsBestAndAggregatesSources(t *testing.T) {
+	results := []UnifiedResult{
+		{Item: MemoryItem{ID: "a", Text: "Prefers dark mode"}, Score: 0.9, Source: "vector"},
+		{Item: MemoryItem{ID: "b", Text: "Uses Postgres"}, Score: 0.8, Source: "vector"},
+		{Item: MemoryItem{ID: "c", Text: "prefers  DARK mode"}, Score: 0.5, Source: "fts", Snippet: ">>>dark<<< mode"},
+	}
+
+	got := dedupeResults(results)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(got))
+	}
+	if got[0].Item.ID != "a" || got[0].Score != 0.9 {
+		t.Fatalf("expected best-scoring duplicate kept, got %+v", got[0])
+	}
+	if got[0].Source != "both" || got[0].Snippet != ">>>dark<<< mode" {
+		t.Fatalf("expected sources and snippet aggregated, got source %q snippet %q", got[0].Source, got[0].Snippet)
+	}
+	if len(got[0].DuplicateIDs) != 1 || got[0].DuplicateIDs[0] != "c" {
+		t.Fatalf("duplicate IDs = %v, want [c]", got[0].DuplicateIDs)
+	}
+	if got[1].Item.ID != "b" {
+		t.Fatalf("expected unrelated result kept, got %+v", got[1])
+	}
+}
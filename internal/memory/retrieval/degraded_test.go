@@ -0,0 +1,211 @@
+package retrieval
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// failingEmbeddingClient simulates an embedding provider outage.
+type failingEmbeddingClient struct{}
+
+func (f *failingEmbeddingClient) Embed(ctx context.Context, model types.Model, text string) ([]float32, error) {
+	return nil, errors.New("provider unavailable")
+}
+
+func (f *failingEmbeddingClient) EmbedBatch(ctx context.Context, model types.Model, texts []string) ([][]float32, error) {
+	return nil, errors.New("provider unavailable")
+}
+
+func (f *failingEmbeddingClient) Dimensions(model types.Model) int {
+	return 2
+}
+
+func TestRetrieveFlagsDegradedVectorPath(t *testing.T) {
+	memStore := newTestStore(t)
+	config := utils.DefaultConfig()
+	config.Memory.MinSimilarity = 0.1
+	retriever := NewRetriever(
+		memStore,
+		&failingEmbeddingClient{},
+		nil,
+		types.Model{Provider: "fake", ModelID: "fake-embedding"},
+		types.Model{},
+		config.Memory,
+	)
+
+	item := &MemoryItem{
+		Text:      "C++ virtual functions enable polymorphism",
+		Source:    SourceExplicit,
+		Provider:  "fake",
+		ModelID:   "fake-embedding",
+		Dim:       2,
+		Embedding: NormalizeVector([]float32{1, 0}),
+	}
+	if err := memStore.SaveMemory(item); err != nil {
+		t.Fatalf("save memory: %v", err)
+	}
+
+	resp, err := retriever.Retrieve(context.Background(), "virtual functions")
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if !resp.Degraded {
+		t.Fatal("expected response to be flagged as degraded")
+	}
+	if len(resp.Warnings) != 1 || !strings.Contains(resp.Warnings[0], "vector search failed") || !strings.Contains(resp.Warnings[0], "provider unavailable") {
+		t.Fatalf("unexpected warnings: %v", resp.Warnings)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].Source != "fts" {
+		t.Fatalf("expected FTS results to still be returned, got %+v", resp.Results)
+	}
+
+	if text := FormatAsText(resp); !strings.HasPrefix(text, "Warning: vector search failed") {
+		t.Fatalf("expected warning in text output, got %q", text)
+	}
+}
+
+func TestRetrieveStrictModeFailsOnPartialFailure(t *testing.T) {
+	memStore := newTestStore(t)
+	config := utils.DefaultConfig()
+	config.Memory.StrictRetrieval = true
+	retriever := NewRetriever(
+		memStore,
+		&failingEmbeddingClient{},
+		nil,
+		types.Model{Provider: "fake", ModelID: "fake-embedding"},
+		types.Model{},
+		config.Memory,
+	)
+
+	_, err := retriever.Retrieve(context.Background(), "virtual functions")
+	if !errors.Is(err, ErrPartialFailure) {
+		t.Fatalf("expected ErrPartialFailure, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "provider unavailable") {
+		t.Fatalf("expected the underlying cause in the error, got %v", err)
+	}
+
+	// A per-call override restores best-effort results.
+	bestEffort := false
+	resp, err := retriever.RetrieveWithOptions(context.Background(), "virtual functions", RetrieveOptions{Strict: &bestEffort})
+	if err != nil {
+		t.Fatalf("retrieve with best-effort override: %v", err)
+	}
+	if !resp.Degraded {
+		t.Fatal("expected best-effort response to be flagged as degraded")
+	}
+}
+
+func TestRetrieveHealthyPathsAreNotDegraded(t *testing.T) {
+	memStore := newTestStore(t)
+	retriever := newTestRetriever(memStore)
+
+	resp, err := retriever.Retrieve(context.Background(), "virtual functions")
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if resp.Degraded || len(resp.Warnings) != 0 {
+		t.Fatalf("expected healthy response, got degraded=%v warnings=%v", resp.Degraded, resp.Warnings)
+	}
+}
+
+func TestRetrieveWithoutEmbeddingClientIsFTSOnly(t *testing.T) {
+	memStore := newTestStore(t)
+	config := utils.DefaultConfig()
+	config.Memory.StrictRetrieval = true
+	retriever := NewRetriever(
+		memStore,
+		nil,
+		nil,
+		types.Model{Provider: "fake", ModelID: "fake-embedding"},
+		types.Model{},
+		config.Memory,
+	)
+
+	item := &MemoryItem{
+		Text:     "saved while offline about kiwis",
+		Source:   SourceExplicit,
+		Provider: "fake",
+		ModelID:  "fake-embedding",
+	}
+	if err := memStore.SaveMemory(item); err != nil {
+		t.Fatalf("save memory: %v", err)
+	}
+
+	resp, err := retriever.Retrieve(context.Background(), "kiwis")
+	if err != nil {
+		t.Fatalf("expected offline retrieval to succeed even in strict mode: %v", err)
+	}
+	if !resp.FTSOnly || resp.Degraded {
+		t.Fatalf("expected an FTS-only, non-degraded response, got %+v", resp)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].Item.ID != item.ID {
+		t.Fatalf("expected the FTS match, got %+v", resp.Results)
+	}
+	if text := FormatAsText(resp); !strings.HasPrefix(text, "Note: full-text search only") {
+		t.Fatalf("expected an offline note in text output, got %q", text)
+	}
+}
+
+func TestRetrieveWarnsAboutMalformedEmbeddings(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	// Every connection to :memory: is a separate database; the test corrupts
+	// the one the store uses.
+	db.SetMaxOpenConns(1)
+	memStore, err := store.NewStoreWithDB(db)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	defer memStore.Close()
+
+	config := utils.DefaultConfig()
+	config.Memory.MinSimilarity = 0.1
+	retriever := NewRetriever(memStore, &fakeEmbeddingClient{}, nil, types.Model{Provider: "fake", ModelID: "fake-embedding"}, types.Model{}, config.Memory)
+
+	for _, id := range []string{"intact", "truncated"} {
+		item := &MemoryItem{ID: id, Text: "C++ virtual functions " + id, Source: SourceExplicit, Dim: 2, Embedding: NormalizeVector([]float32{1, 0})}
+		if err := memStore.SaveMemory(item); err != nil {
+			t.Fatalf("save memory: %v", err)
+		}
+	}
+	if _, err := db.Exec(`UPDATE memories SET embedding = substr(embedding, 1, 5) WHERE id = 'truncated'`); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+
+	if _, err := memStore.SearchMemories([]float32{1, 0}, 5, 0.1); !errors.Is(err, ErrMalformedEmbedding) {
+		t.Fatalf("expected search to report the malformed embedding, got %v", err)
+	}
+
+	resp, err := retriever.Retrieve(context.Background(), "virtual")
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if !resp.Degraded || len(resp.Warnings) != 1 || !strings.Contains(resp.Warnings[0], "vector search incomplete") || !strings.Contains(resp.Warnings[0], "(truncated)") {
+		t.Fatalf("expected a warning naming the truncated memory, got %v", resp.Warnings)
+	}
+	for _, result := range resp.Results {
+		if result.Item.ID == "intact" && result.Source == "fts" {
+			t.Fatalf("expected the intact memory to still be found by vector search, got %+v", resp.Results)
+		}
+	}
+
+	if count, err := memStore.CountNeedsReembedding(); err != nil || count != 1 {
+		t.Fatalf("expected the truncated memory flagged for re-embedding, got %d (%v)", count, err)
+	}
+	if err := memStore.UpdateMemoryEmbedding("truncated", NormalizeVector([]float32{1, 0}), "fake-embedding", 2, "fake"); err != nil {
+		t.Fatalf("update embedding: %v", err)
+	}
+	if count, _ := memStore.CountNeedsReembedding(); count != 0 {
+		t.Fatalf("expected a new embedding to clear the flag, got %d flagged", count)
+	}
+}
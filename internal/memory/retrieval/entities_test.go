@@ -0,0 +1,56 @@
+package retrieval
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRetrieveBoostsMemoriesLinkedToQueryEntities(t *testing.T) {
+	memStore := newTestStore(t)
+	retriever := newTestRetriever(memStore)
+	retriever.config.EntityLinking = true
+
+	unlinked := &MemoryItem{
+		Text:      "C++ virtual functions enable polymorphism",
+		Source:    SourceExplicit,
+		Provider:  "fake",
+		ModelID:   "fake-embedding",
+		Dim:       2,
+		Embedding: NormalizeVector([]float32{1, 0}),
+	}
+	linked := &MemoryItem{
+		Text:      "Atlas uses C++ virtual functions for plugins",
+		Source:    SourceExplicit,
+		Provider:  "fake",
+		ModelID:   "fake-embedding",
+		Dim:       2,
+		Embedding: NormalizeVector([]float32{0.9, 0.1}),
+	}
+	for _, item := range []*MemoryItem{unlinked, linked} {
+		if err := memStore.SaveMemory(item); err != nil {
+			t.Fatalf("save memory: %v", err)
+		}
+	}
+
+	atlas, err := memStore.UpsertEntity("Atlas", "project")
+	if err != nil {
+		t.Fatalf("upsert entity: %v", err)
+	}
+	if err := memStore.LinkMemoryEntity(linked.ID, atlas.ID); err != nil {
+		t.Fatalf("link entity: %v", err)
+	}
+
+	resp, err := retriever.Retrieve(context.Background(), "how does project atlas use virtual functions")
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if len(resp.Results) < 2 {
+		t.Fatalf("expected both memories, got %+v", resp.Results)
+	}
+	if resp.Results[0].Item.ID != linked.ID || !resp.Results[0].EntityMatch {
+		t.Fatalf("expected entity-linked memory first, got %+v", resp.Results[0])
+	}
+	if resp.Results[1].EntityMatch {
+		t.Fatal("expected unlinked memory to have no entity match")
+	}
+}
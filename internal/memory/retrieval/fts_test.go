@@ -0,0 +1,155 @@
+package retrieval
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// summaryQueryClient answers every prompt with summary after delay, or fails
+// the stream when the context ends first.
+type summaryQueryClient struct {
+	summary string
+	delay   time.Duration
+}
+
+func (c *summaryQueryClient) ChatStream(ctx context.Context, model types.Model, query string) (client.StreamResponse, error) {
+	select {
+	case <-time.After(c.delay):
+		return &fakeStream{chunks: []string{c.summary}}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *summaryQueryClient) ChatStreamWithContext(ctx context.Context, model types.Model, systemContext, query string) (client.StreamResponse, error) {
+	return c.ChatStream(ctx, model, query)
+}
+
+func (c *summaryQueryClient) ListModels(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func TestFTSSearchAutoMergesSummaryResults(t *testing.T) {
+	memStore := newTestStore(t)
+	saveTextMemory(t, memStore, "The deploy pipeline runs on Argo")
+	saveTextMemory(t, memStore, "Kubernetes clusters are managed with Terraform")
+
+	r := newTestRetriever(memStore)
+	r.queryClient = &summaryQueryClient{summary: "kubernetes terraform"}
+
+	results, err := r.ftsSearchAuto(context.Background(), "deploy pipeline", MemoryFilter{})
+	if err != nil {
+		t.Fatalf("fts: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected direct and summary results merged, got %d", len(results))
+	}
+}
+
+func TestFTSSearchAutoStopsWaitingAtLatencyBudget(t *testing.T) {
+	memStore := newTestStore(t)
+	saveTextMemory(t, memStore, "The deploy pipeline runs on Argo")
+
+	r := newTestRetriever(memStore)
+	r.config.FTSLatencyBudgetMs = 50
+	r.queryClient = &summaryQueryClient{summary: "deploy", delay: 10 * time.Second}
+
+	start := time.Now()
+	results, err := r.ftsSearchAuto(context.Background(), "deploy pipeline", MemoryFilter{})
+	if err != nil {
+		t.Fatalf("fts: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected to stop waiting after the budget, took %v", elapsed)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected the direct result, got %d", len(results))
+	}
+}
+
+func TestFTSSearchSummaryFallsBackAtTransformTimeout(t *testing.T) {
+	memStore := newTestStore(t)
+	saveTextMemory(t, memStore, "The deploy pipeline runs on Argo")
+
+	r := newTestRetriever(memStore)
+	r.config.TransformTimeoutMs = 50
+	r.queryClient = &summaryQueryClient{summary: "kubernetes", delay: 10 * time.Second}
+
+	start := time.Now()
+	results, err := r.ftsSearchSummary(context.Background(), "deploy pipeline", MemoryFilter{})
+	if err != nil {
+		t.Fatalf("fts: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected the summary to time out, took %v", elapsed)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected the raw query's result, got %d", len(results))
+	}
+}
+
+func TestFTSSearchAutoSkipsSummaryWhenDirectIsEnough(t *testing.T) {
+	memStore := newTestStore(t)
+	for _, text := range []string{"deploy with Argo", "deploy on Fridays", "deploy from main"} {
+		saveTextMemory(t, memStore, text)
+	}
+
+	r := newTestRetriever(memStore)
+	r.config.MemoryTopK = 6 // three direct results are enough
+	r.queryClient = &summaryQueryClient{summary: "deploy", delay: 10 * time.Second}
+
+	start := time.Now()
+	results, err := r.ftsSearchAuto(context.Background(), "deploy", MemoryFilter{})
+	if err != nil {
+		t.Fatalf("fts: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected not to wait for the summary, took %v", elapsed)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 direct results, got %d", len(results))
+	}
+}
+
+func TestRetrieveOverridesSettingsPerCall(t *testing.T) {
+	memStore := newTestStore(t)
+	saveTextMemory(t, memStore, "The deploy pipeline runs on Argo")
+	saveTextMemory(t, memStore, "Kubernetes clusters are managed with Terraform")
+
+	r := newTestRetriever(memStore)
+	r.config.FTSStrategy = utils.FTSStrategyDirect
+	r.queryClient = &summaryQueryClient{summary: "kubernetes terraform"}
+
+	sources := func(opts RetrieveOptions) map[string]string {
+		t.Helper()
+		resp, err := r.RetrieveWithOptions(context.Background(), "deploy pipeline", opts)
+		if err != nil {
+			t.Fatalf("retrieve: %v", err)
+		}
+		source := map[string]string{}
+		for _, res := range resp.Results {
+			source[res.Item.Text[:4]] = res.Source
+		}
+		return source
+	}
+
+	source := sources(RetrieveOptions{})
+	if source["The "] != "both" || source["Kube"] != "vector" {
+		t.Fatalf("expected direct search to match the query's own words, got %v", source)
+	}
+	source = sources(RetrieveOptions{FTSStrategy: utils.FTSStrategySummary})
+	if source["Kube"] != "both" || source["The "] != "vector" {
+		t.Fatalf("expected the summary strategy for this call, got %v", source)
+	}
+	if w := r.withOverrides("", RetrieveOptions{FusionVectorWeight: 0.9}).fusionVectorWeight(); w != 0.9 {
+		t.Fatalf("expected the fusion weight for this call, got %.2f", w)
+	}
+	if r.config.FTSStrategy != utils.FTSStrategyDirect || r.config.FusionVectorWeight != 0.6 {
+		t.Fatalf("expected the overrides to leave the retriever's settings alone, got %+v", r.config)
+	}
+}
@@ -0,0 +1,124 @@
+package retrieval
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// fusionNow is when the fixture is fused, so freshness never depends on the
+// day the test runs.
+var fusionNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// fusionFixture is a fixed set of search results covering each source,
+// kinds, entity matches, duplicates, and memories of different ages and
+// confidence.
+func fusionFixture() ([]SearchResult, []MemoryFTSResult, map[string]bool) {
+	daysAgo := func(days int) time.Time { return fusionNow.AddDate(0, 0, -days) }
+	retrieved := daysAgo(2)
+	memory := func(id, text string, kind MemoryKind, created time.Time) MemoryItem {
+		return MemoryItem{ID: id, Text: text, Kind: kind, CreatedAt: created, Confidence: 0.9, StabilityDays: 30}
+	}
+
+	vtable := memory("both-strong", "C++ virtual functions dispatch through a vtable", KindFact, daysAgo(1))
+	prefers := memory("both-preference", "The user prefers composition over inheritance in C++", KindPreference, daysAgo(40))
+	chunk := memory("both-chunk", "Chapter 12: virtual functions and dynamic dispatch", KindDocumentChunk, daysAgo(3))
+	chunk2 := memory("vector-chunk", "Chapter 13: abstract base classes", KindDocumentChunk, daysAgo(3))
+	chunk3 := memory("fts-chunk", "Appendix B: virtual inheritance", KindDocumentChunk, daysAgo(3))
+	chunk4 := memory("vector-chunk-2", "Chapter 14: the override specifier", KindDocumentChunk, daysAgo(3))
+	old := memory("vector-old", "Virtual calls cannot be inlined unless devirtualized", KindFact, daysAgo(365))
+	old.LastRetrievedAt = &retrieved
+	doubt := memory("vector-doubtful", "Virtual functions are always slow", KindFact, daysAgo(5))
+	doubt.Confidence = 0.3
+	episode := memory("fts-episode", "Debugged a virtual destructor leak with Ana last week", KindEpisodic, daysAgo(8))
+	entity := memory("fts-entity", "Ana maintains the rendering engine's class hierarchy", KindFact, daysAgo(20))
+	dup := memory("vector-duplicate", "c++  virtual functions dispatch through a VTABLE", KindFact, daysAgo(60))
+
+	vector := []SearchResult{
+		{Item: vtable, Similarity: 0.91},
+		{Item: prefers, Similarity: 0.62},
+		{Item: chunk, Similarity: 0.83},
+		{Item: chunk2, Similarity: 0.71},
+		{Item: chunk4, Similarity: 0.69},
+		{Item: old, Similarity: 0.77},
+		{Item: doubt, Similarity: 0.80},
+		{Item: dup, Similarity: 0.88},
+	}
+	fts := []MemoryFTSResult{
+		{Item: vtable, Rank: -9.5, Snippet: "C++ >>>virtual<<< functions"},
+		{Item: chunk, Rank: -6.1, Snippet: ">>>virtual<<< functions and dynamic dispatch"},
+		{Item: prefers, Rank: -2.4, Snippet: "composition over >>>inheritance<<<"},
+		{Item: chunk3, Rank: -5.0, Snippet: ">>>virtual<<< inheritance"},
+		{Item: episode, Rank: -4.2, Snippet: "a >>>virtual<<< destructor leak"},
+		{Item: entity, Rank: -1.3, Snippet: "class hierarchy"},
+	}
+	return vector, fts, map[string]bool{"fts-entity": true, "fts-episode": true}
+}
+
+// formatFusion renders fused results one per line, best first.
+func formatFusion(results []UnifiedResult) string {
+	var sb strings.Builder
+	for i, r := range results {
+		fmt.Fprintf(&sb, "%2d %-16s %-6s base=%.4f fresh=%.4f score=%.4f", i+1, r.Item.ID, r.Source, r.BaseScore, r.Freshness, r.Score)
+		if r.EntityMatch {
+			sb.WriteString(" entity")
+		}
+		if len(r.DuplicateIDs) > 0 {
+			sb.WriteString(" duplicates=" + strings.Join(r.DuplicateIDs, ","))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// TestFusionGolden pins the order and scores of fused results, so a change to
+// scoring shows up as a diff of the golden file. Run with -update to accept it.
+func TestFusionGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		adjust func(*utils.MemoryConfig)
+	}{
+		{name: "default", adjust: func(*utils.MemoryConfig) {}},
+		{name: "fts_weighted", adjust: func(c *utils.MemoryConfig) { c.FusionVectorWeight = 0.3 }},
+		{name: "kind_budgets", adjust: func(c *utils.MemoryConfig) {
+			c.MemoryTopK = 5
+			c.KindTopK = map[string]int{"document-chunk": 1}
+			c.KindWeights = map[string]float64{"episodic": 1.5}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := utils.DefaultConfig().Memory
+			tt.adjust(&config)
+			r := NewRetriever(nil, nil, nil, types.Model{}, types.Model{}, config)
+			vector, fts, entityIDs := fusionFixture()
+
+			got := formatFusion(r.fuseResults(vector, fts, entityIDs, fusionNow))
+			path := filepath.Join("testdata", "fusion_"+tt.name+".golden")
+			if *update {
+				if err := os.MkdirAll("testdata", 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read golden file (run with -update to create it): %v", err)
+			}
+			if got != string(want) {
+				t.Fatalf("fused results differ from %s (run with -update if the change is intended):\ngot:\n%swant:\n%s", path, got, want)
+			}
+		})
+	}
+}
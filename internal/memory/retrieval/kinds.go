@@ -0,0 +1,49 @@
+package retrieval
+
+// kindWeight returns the configured relevance multiplier for a memory kind.
+func (r *Retriever) kindWeight(kind MemoryKind) float64 {
+	if kind == "" {
+		kind = KindFact
+	}
+	if w, ok := r.config.KindWeights[string(kind)]; ok && w > 0 {
+		return w
+	}
+	return 1.0
+}
+
+// candidateTopK is how many results each search path returns. When per-kind
+// budgets are set, twice MemoryTopK candidates are fetched so that results
+// dropped by a budget can be replaced by other kinds.
+func (r *Retriever) candidateTopK() int {
+	for _, budget := range r.config.KindTopK {
+		if budget > 0 {
+			return r.config.MemoryTopK * 2
+		}
+	}
+	return r.config.MemoryTopK
+}
+
+// applyKindBudgets builds the final result list from score-sorted results,
+// skipping results whose kind has used up its KindTopK budget, so that e.g.
+// document chunks don't crowd out user preferences.
+func (r *Retriever) applyKindBudgets(results []UnifiedResult) []UnifiedResult {
+	selected := make([]UnifiedResult, 0, min(len(results), r.config.MemoryTopK))
+	counts := make(map[MemoryKind]int)
+
+	for _, res := range results {
+		if len(selected) >= r.config.MemoryTopK {
+			break
+		}
+		kind := res.Item.Kind
+		if kind == "" {
+			kind = KindFact
+		}
+		if budget := r.config.KindTopK[string(kind)]; budget > 0 && counts[kind] >= budget {
+			continue
+		}
+		counts[kind]++
+		selected = append(selected, res)
+	}
+
+	return selected
+}
@@ -0,0 +1,83 @@
+package retrieval
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestRetrieveAppliesPerKindBudgets(t *testing.T) {
+	memStore := newTestStore(t)
+	retriever := newTestRetriever(memStore)
+	retriever.config.MemoryTopK = 3
+	retriever.config.KindTopK = map[string]int{"document-chunk": 2}
+
+	for i := 0; i < 5; i++ {
+		chunk := &MemoryItem{
+			Text:       fmt.Sprintf("C++ virtual functions chapter %d", i),
+			Source:     SourceDocument,
+			Kind:       KindDocumentChunk,
+			Provider:   "fake",
+			ModelID:    "fake-embedding",
+			Dim:        2,
+			Embedding:  NormalizeVector([]float32{1, 0}),
+			SourcePath: "/docs/cpp.md",
+			ChunkIndex: i,
+		}
+		if err := memStore.SaveMemory(chunk); err != nil {
+			t.Fatalf("save chunk: %v", err)
+		}
+	}
+	preference := &MemoryItem{
+		Text:      "The user prefers short examples when explaining virtual functions",
+		Source:    SourceExplicit,
+		Kind:      KindPreference,
+		Provider:  "fake",
+		ModelID:   "fake-embedding",
+		Dim:       2,
+		Embedding: NormalizeVector([]float32{0.7, 0.3}),
+	}
+	if err := memStore.SaveMemory(preference); err != nil {
+		t.Fatalf("save preference: %v", err)
+	}
+
+	resp, err := retriever.Retrieve(context.Background(), "C++ virtual functions")
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if len(resp.Results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(resp.Results))
+	}
+
+	chunks := 0
+	foundPreference := false
+	for _, r := range resp.Results {
+		switch r.Item.Kind {
+		case KindDocumentChunk:
+			chunks++
+		case KindPreference:
+			foundPreference = true
+		}
+	}
+	if chunks != 2 {
+		t.Fatalf("expected document chunks capped at 2, got %d", chunks)
+	}
+	if !foundPreference {
+		t.Fatal("expected the preference to survive instead of being crowded out")
+	}
+}
+
+func TestKindWeightDefaultsToOne(t *testing.T) {
+	retriever := newTestRetriever(nil)
+	retriever.config.KindWeights = map[string]float64{"episodic": 0.5}
+
+	if w := retriever.kindWeight(KindEpisodic); w != 0.5 {
+		t.Fatalf("expected configured weight 0.5, got %v", w)
+	}
+	if w := retriever.kindWeight(KindPreference); w != 1.0 {
+		t.Fatalf("expected unlisted kind to weigh 1.0, got %v", w)
+	}
+	if w := retriever.kindWeight(""); w != 1.0 {
+		t.Fatalf("expected empty kind to weigh as fact, got %v", w)
+	}
+}
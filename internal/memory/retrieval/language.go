@@ -0,0 +1,163 @@
+package retrieval
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// languageSampleSize is how many recent memories decide their dominant language.
+const languageSampleSize = 200
+
+// scriptLanguages maps writing systems to the language searched for in them.
+// Latin script is assumed to be English; set memory.language for others.
+var scriptLanguages = []struct {
+	table    *unicode.RangeTable
+	language string
+}{
+	{unicode.Hiragana, "Japanese"},
+	{unicode.Katakana, "Japanese"},
+	{unicode.Hangul, "Korean"},
+	{unicode.Han, "Chinese"},
+	{unicode.Cyrillic, "Russian"},
+	{unicode.Arabic, "Arabic"},
+	{unicode.Hebrew, "Hebrew"},
+	{unicode.Greek, "Greek"},
+	{unicode.Thai, "Thai"},
+	{unicode.Devanagari, "Hindi"},
+	{unicode.Latin, "English"},
+}
+
+// DetectLanguage guesses the language of text from its writing system. It
+// returns "" when text has no letters.
+func DetectLanguage(text string) string {
+	counts := make(map[string]int)
+	kana := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		for _, s := range scriptLanguages {
+			if unicode.Is(s.table, r) {
+				counts[s.language]++
+				if s.language == "Japanese" {
+					kana++
+				}
+				break
+			}
+		}
+	}
+	// Japanese mixes kana with Han characters; any kana marks the text as Japanese.
+	if kana > 0 {
+		return "Japanese"
+	}
+	return mostCommon(counts)
+}
+
+// dominantLanguage returns the language most of texts are written in.
+func dominantLanguage(texts []string) string {
+	counts := make(map[string]int)
+	for _, t := range texts {
+		if lang := DetectLanguage(t); lang != "" {
+			counts[lang]++
+		}
+	}
+	return mostCommon(counts)
+}
+
+func mostCommon(counts map[string]int) string {
+	best, bestCount := "", 0
+	for _, s := range scriptLanguages {
+		if counts[s.language] > bestCount {
+			best, bestCount = s.language, counts[s.language]
+		}
+	}
+	return best
+}
+
+// memoryLanguage resolves the configured memory language, detecting it from
+// stored memories in auto mode. It returns "" when queries are not translated.
+func (r *Retriever) memoryLanguage() string {
+	switch strings.ToLower(r.config.Language) {
+	case utils.MemoryLanguageOff:
+		return ""
+	case "", utils.MemoryLanguageAuto:
+		texts, err := r.store.RecentMemoryTexts(languageSampleSize)
+		if err != nil {
+			return ""
+		}
+		return dominantLanguage(texts)
+	default:
+		return r.config.Language
+	}
+}
+
+// translateQuery uses tool_model to translate query into the language memories
+// are written in, so full-text search can match them. It returns "" when the
+// query is already in that language or translation is unavailable or fails.
+func (r *Retriever) translateQuery(ctx context.Context, query string) string {
+	if r.queryClient == nil {
+		return ""
+	}
+	target := r.memoryLanguage()
+	if target == "" || strings.EqualFold(DetectLanguage(query), target) {
+		return ""
+	}
+
+	prompt := fmt.Sprintf(`Translate this search query into %s. Keep names, code, and technical terms as they are.
+
+Query: %s
+
+Respond with ONLY the translated query, no other text.`, target, query)
+
+	ctx, cancel := context.WithTimeout(ctx, r.transformTimeout())
+	defer cancel()
+	stream, err := r.queryClient.ChatStream(ctx, r.toolModel, prompt)
+	if err != nil {
+		return ""
+	}
+	defer stream.Close()
+
+	var sb strings.Builder
+	for stream.Next() {
+		sb.WriteString(stream.GetChunk())
+	}
+	if stream.Err() != nil {
+		return ""
+	}
+
+	translated := strings.Trim(strings.TrimSpace(sb.String()), "\"'")
+	if strings.EqualFold(translated, query) {
+		return ""
+	}
+	return translated
+}
+
+// multilingualEmbeddingPrefixes are embedding models that place text in
+// different languages in a shared space.
+var multilingualEmbeddingPrefixes = []string{
+	"text-embedding-3",
+	"gemini-embedding",
+	"text-multilingual-embedding",
+	"embed-multilingual",
+	"multilingual-e5",
+	"bge-m3",
+}
+
+// IsMultilingualEmbeddingModel reports whether modelID is an embedding model
+// known to match queries against memories written in another language.
+func IsMultilingualEmbeddingModel(modelID string) bool {
+	id := strings.ToLower(modelID)
+	if i := strings.LastIndex(id, "/"); i >= 0 {
+		id = id[i+1:]
+	}
+	for _, prefix := range multilingualEmbeddingPrefixes {
+		if strings.HasPrefix(id, prefix) {
+			return true
+		}
+	}
+	return false
+}
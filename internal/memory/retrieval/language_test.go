@@ -0,0 +1,134 @@
+package retrieval
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// translatePrompt marks the prompt asking the tool model for a translation.
+const translatePrompt = "Translate this search query"
+
+func TestDetectLanguage(t *testing.T) {
+	cases := map[string]string{
+		"deploy with docker":   "English",
+		"上周完成了数据库迁移":           "Chinese",
+		"データベースの移行":            "Japanese",
+		"데이터베이스 마이그레이션":        "Korean",
+		"миграция базы данных": "Russian",
+		"Postgres 数据库迁移已完成":    "Chinese",
+		"42 -- 7":              "",
+	}
+	for text, want := range cases {
+		if got := DetectLanguage(text); got != want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func saveTextMemory(t *testing.T, memStore *Store, text string) *MemoryItem {
+	t.Helper()
+	item := &MemoryItem{
+		Text:      text,
+		Source:    SourceExplicit,
+		Provider:  "fake",
+		ModelID:   "fake-embedding",
+		Dim:       2,
+		Embedding: NormalizeVector([]float32{0, 1}),
+	}
+	if err := memStore.SaveMemory(item); err != nil {
+		t.Fatalf("save memory: %v", err)
+	}
+	return item
+}
+
+func TestRetrieveTranslatesQueryIntoMemoryLanguage(t *testing.T) {
+	memStore := newTestStore(t)
+	item := saveTextMemory(t, memStore, "上周 数据库迁移 已完成")
+	saveTextMemory(t, memStore, "喜欢 深色 主题")
+
+	config := utils.DefaultConfig()
+	config.Memory.MinSimilarity = 0.99
+	queryClient := &recordingQueryClient{answers: map[string]string{translatePrompt: "数据库迁移"}}
+	retriever := NewRetriever(
+		memStore,
+		&fakeEmbeddingClient{},
+		queryClient,
+		types.Model{Provider: "fake", ModelID: "fake-embedding"},
+		types.Model{Provider: "fake", ModelID: "fake-tool"},
+		config.Memory,
+	)
+
+	resp, err := retriever.Retrieve(context.Background(), "database migration")
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if resp.TranslatedQuery != "数据库迁移" {
+		t.Fatalf("unexpected translated query: %q", resp.TranslatedQuery)
+	}
+	if prompt := queryClient.recorded()[0]; !strings.Contains(prompt, "into Chinese") {
+		t.Fatalf("expected translation into the memories' language, got %q", prompt)
+	}
+	found := false
+	for _, r := range resp.Results {
+		if r.Item.ID == item.ID && r.Source != "vector" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected full-text search to find the Chinese memory, got %+v", resp.Results)
+	}
+	if !strings.Contains(FormatAsText(resp), "Also searched as: 数据库迁移") {
+		t.Fatal("expected the translation to be shown")
+	}
+}
+
+func TestRetrieveSkipsTranslationInSameLanguageOrWhenOff(t *testing.T) {
+	memStore := newTestStore(t)
+	saveTextMemory(t, memStore, "the database migration finished last week")
+
+	for _, language := range []string{utils.MemoryLanguageAuto, utils.MemoryLanguageOff} {
+		config := utils.DefaultConfig()
+		config.Memory.Language = language
+		queryClient := &recordingQueryClient{answers: map[string]string{translatePrompt: "unused"}}
+		retriever := NewRetriever(
+			memStore,
+			&fakeEmbeddingClient{},
+			queryClient,
+			types.Model{Provider: "fake", ModelID: "fake-embedding"},
+			types.Model{Provider: "fake", ModelID: "fake-tool"},
+			config.Memory,
+		)
+
+		query := "database migration"
+		if language == utils.MemoryLanguageOff {
+			query = "数据库迁移"
+		}
+		resp, err := retriever.Retrieve(context.Background(), query)
+		if err != nil {
+			t.Fatalf("retrieve: %v", err)
+		}
+		if resp.TranslatedQuery != "" {
+			t.Fatalf("language %s: expected no translation, got %q", language, resp.TranslatedQuery)
+		}
+		if queryClient.asked(translatePrompt) != 0 {
+			t.Fatalf("language %s: translation prompt should not be sent", language)
+		}
+	}
+}
+
+func TestIsMultilingualEmbeddingModel(t *testing.T) {
+	for _, id := range []string{"text-embedding-3-small", "gemini-embedding-001", "openai/text-embedding-3-large"} {
+		if !IsMultilingualEmbeddingModel(id) {
+			t.Errorf("expected %s to be multilingual", id)
+		}
+	}
+	for _, id := range []string{"text-embedding-004", "ngram-hash-512"} {
+		if IsMultilingualEmbeddingModel(id) {
+			t.Errorf("expected %s not to be multilingual", id)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package retrieval
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+// slowEmbeddingClient blocks until the context ends.
+type slowEmbeddingClient struct {
+	fakeEmbeddingClient
+}
+
+func (s *slowEmbeddingClient) Embed(ctx context.Context, model types.Model, text string) ([]float32, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestRetrieveSkipsSlowStepsWithinDeadlines(t *testing.T) {
+	memStore := newTestStore(t)
+	saveTextMemory(t, memStore, "The deploy pipeline runs on Argo")
+
+	r := newTestRetriever(memStore)
+	r.embeddingClient = &slowEmbeddingClient{}
+	r.queryClient = &summaryQueryClient{summary: "deploy", delay: 10 * time.Second}
+	r.config.TransformTimeoutMs = 30
+	r.config.EmbeddingTimeoutMs = 30
+	r.config.FTSLatencyBudgetMs = 30
+
+	start := time.Now()
+	resp, err := r.RetrieveWithOptions(context.Background(), "deploy pipeline", RetrieveOptions{
+		History: []HistoryItem{{Role: "user", Content: "how do we ship?"}},
+	})
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected slow steps to be cut off, took %v", elapsed)
+	}
+	if resp.RewrittenQuery != "" {
+		t.Fatalf("expected the timed-out rewrite to be skipped, got %q", resp.RewrittenQuery)
+	}
+	if !resp.Degraded || len(resp.Results) != 1 || resp.Results[0].Source != "fts" {
+		t.Fatalf("expected a degraded full-text result, got degraded=%v results=%+v", resp.Degraded, resp.Results)
+	}
+}
+
+func TestMsOrDefault(t *testing.T) {
+	if got := msOrDefault(0, time.Second); got != time.Second {
+		t.Fatalf("expected the default for 0, got %v", got)
+	}
+	if got := msOrDefault(250, time.Second); got != 250*time.Millisecond {
+		t.Fatalf("expected 250ms, got %v", got)
+	}
+}
@@ -0,0 +1,76 @@
+package retrieval
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+func TestRetrieveAlwaysIncludesPinnedMemories(t *testing.T) {
+	memStore := newTestStore(t)
+	config := utils.DefaultConfig()
+	config.Memory.MinSimilarity = 0.5
+	retriever := NewRetriever(
+		memStore,
+		&fakeEmbeddingClient{},
+		nil,
+		types.Model{Provider: "fake", ModelID: "fake-embedding"},
+		types.Model{},
+		config.Memory,
+	)
+
+	matching := &MemoryItem{
+		Text:      "C++ virtual functions enable polymorphism",
+		Source:    SourceExplicit,
+		Provider:  "fake",
+		ModelID:   "fake-embedding",
+		Dim:       2,
+		Embedding: NormalizeVector([]float32{1, 0}),
+	}
+	pinned := &MemoryItem{
+		Text:      "Always answer in British English",
+		Source:    SourceExplicit,
+		Provider:  "fake",
+		ModelID:   "fake-embedding",
+		Dim:       2,
+		Embedding: NormalizeVector([]float32{0, 1}),
+		Pinned:    true,
+	}
+	for _, item := range []*MemoryItem{matching, pinned} {
+		if err := memStore.SaveMemory(item); err != nil {
+			t.Fatalf("save memory: %v", err)
+		}
+	}
+
+	resp, err := retriever.Retrieve(context.Background(), "virtual functions")
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if len(resp.Results) != 2 {
+		t.Fatalf("expected the match and the pinned memory, got %+v", resp.Results)
+	}
+	if resp.Results[0].Item.ID != pinned.ID || resp.Results[0].Source != "pinned" {
+		t.Fatalf("expected the pinned memory first, got %+v", resp.Results[0])
+	}
+	if resp.Results[1].Item.ID != matching.ID {
+		t.Fatalf("expected the matching memory second, got %+v", resp.Results[1])
+	}
+	if !strings.Contains(FormatAsText(resp), "1. [pinned] Always answer in British English") {
+		t.Fatalf("expected the pinned memory to be marked, got %q", FormatAsText(resp))
+	}
+
+	found, err := memStore.SetMemoryPinned(pinned.ID, false)
+	if err != nil || !found {
+		t.Fatalf("unpin: found=%v err=%v", found, err)
+	}
+	resp, err = retriever.Retrieve(context.Background(), "virtual functions")
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].Item.ID != matching.ID {
+		t.Fatalf("expected only the match after unpinning, got %+v", resp.Results)
+	}
+}
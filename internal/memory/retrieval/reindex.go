@@ -9,14 +9,16 @@ import (
 	"time"
 
 	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/memory/embedtext"
 	"github.com/austiecodes/gomor/internal/memory/store"
 	"github.com/austiecodes/gomor/internal/types"
 )
 
-// ReindexMemories re-calculates embeddings for all memories using the new model.
-func ReindexMemories(ctx context.Context, s *store.Store, embeddingClient client.EmbeddingClient, model types.Model) error {
+// ReindexMemories re-calculates embeddings for all memories using the new
+// model, rendering each with tmpl.
+func ReindexMemories(ctx context.Context, s *store.Store, embeddingClient client.EmbeddingClient, model types.Model, tmpl *embedtext.Template) error {
 	// 1. Fetch all memories
-	memories, err := s.GetAllMemories()
+	memories, err := s.SearchableMemories()
 	if err != nil {
 		return fmt.Errorf("failed to fetch memories for reindexing: %w", err)
 	}
@@ -57,7 +59,7 @@ func ReindexMemories(ctx context.Context, s *store.Store, embeddingClient client
 				return
 			case job := <-jobsCh:
 				// Call embedding client
-				emb, err := embeddingClient.Embed(ctx, model, job.item.Text)
+				emb, err := embeddingClient.Embed(ctx, model, tmpl.Render(job.item))
 				job.embedding = emb
 				job.err = err
 
@@ -7,8 +7,8 @@ import (
 	"time"
 
 	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/mockprovider"
 	"github.com/austiecodes/gomor/internal/provider"
-	"github.com/austiecodes/gomor/internal/utils"
 	"github.com/google/uuid"
 	_ "modernc.org/sqlite"
 )
@@ -27,21 +27,16 @@ func setupTestStore(t *testing.T) *store.Store {
 	return s
 }
 
-// TestReindexMemories_Integration uses REAL configuration and API.
+// TestReindexMemories_Integration reindexes through the OpenAI provider,
+// served by the mock API.
 // Run with: go test -v ./internal/memory/retrieval -run TestReindexMemories_Integration
 func TestReindexMemories_Integration(t *testing.T) {
-	// 1. Load real config
-	cfg, err := utils.LoadConfig()
-	if err != nil {
-		t.Fatalf("failed to load config: %v", err)
-	}
+	// 1. Point the config at the mock API
+	cfg := mockprovider.New(t).Config()
 
-	// 2. Setup real provider
+	// 2. Setup the provider
 	// We use the configured EmbeddingModel
 	embeddingModel := cfg.Model.EmbeddingModel
-	if embeddingModel == nil {
-		t.Skip("skipping integration test: no embedding model configured")
-	}
 
 	t.Logf("Using embedding model: %s / %s", embeddingModel.Provider, embeddingModel.ModelID)
 
@@ -84,7 +79,7 @@ func TestReindexMemories_Integration(t *testing.T) {
 
 	// 5. Run Reindex
 	t.Log("Starting reindex...")
-	err = ReindexMemories(context.Background(), storeInstance, client, *embeddingModel)
+	err = ReindexMemories(context.Background(), storeInstance, client, *embeddingModel, nil)
 	if err != nil {
 		t.Fatalf("ReindexMemories failed: %v", err)
 	}
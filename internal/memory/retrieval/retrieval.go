@@ -2,6 +2,7 @@ package retrieval
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -10,9 +11,13 @@ import (
 
 	"github.com/austiecodes/gomor/internal/client"
 	"github.com/austiecodes/gomor/internal/memory/decay"
+	"github.com/austiecodes/gomor/internal/memory/entities"
 	"github.com/austiecodes/gomor/internal/memory/memtypes"
 	"github.com/austiecodes/gomor/internal/memory/memutils"
 	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/memory/tagging"
+	"github.com/austiecodes/gomor/internal/memory/temporal"
+	"github.com/austiecodes/gomor/internal/tokenizer"
 	"github.com/austiecodes/gomor/internal/types"
 	"github.com/austiecodes/gomor/internal/utils"
 )
@@ -21,20 +26,33 @@ import (
 type Store = store.Store
 type MemoryItem = memtypes.MemoryItem
 type MemorySource = memtypes.MemorySource
+type MemoryKind = memtypes.MemoryKind
 type SearchResult = memtypes.SearchResult
 type MemoryFTSResult = memtypes.MemoryFTSResult
 type UnifiedResult = memtypes.UnifiedResult
 type RetrievalResponse = memtypes.RetrievalResponse
+type HistoryItem = memtypes.HistoryItem
+type MemoryFilter = memtypes.MemoryFilter
+type TimeRange = memtypes.TimeRange
 
 const (
 	SourceExplicit  = memtypes.SourceExplicit
 	SourceExtracted = memtypes.SourceExtracted
+	SourceDocument  = memtypes.SourceDocument
+	SourceImported  = memtypes.SourceImported
+
+	KindFact          = memtypes.KindFact
+	KindPreference    = memtypes.KindPreference
+	KindDocumentChunk = memtypes.KindDocumentChunk
+	KindEpisodic      = memtypes.KindEpisodic
 )
 
 // Re-export store and vector functions for convenience
 var (
 	NewStore        = store.NewStore
 	NormalizeVector = memutils.NormalizeVector
+
+	ErrMalformedEmbedding = memutils.ErrMalformedEmbedding
 )
 
 // Retriever performs hybrid retrieval from memory using vector search and FTS.
@@ -45,6 +63,10 @@ type Retriever struct {
 	embeddingModel  types.Model
 	toolModel       types.Model
 	config          utils.MemoryConfig
+
+	// plan, when set, is how the query being retrieved is searched by its
+	// class.
+	plan *queryPlan
 }
 
 // NewRetriever creates a new retriever with the given dependencies.
@@ -66,75 +88,296 @@ func NewRetriever(
 	}
 }
 
+// RetrieveOptions carries per-call retrieval settings.
+type RetrieveOptions struct {
+	// History holds recent conversation turns, oldest first. When set, the query
+	// is rewritten into a standalone query before transformation.
+	History []HistoryItem
+	// TimeRange restricts results to memories created within it. When nil and
+	// memory.temporal_filter is on, a range is parsed from temporal phrases in
+	// the query ("yesterday"), and dropped again if it matches nothing.
+	TimeRange *TimeRange
+	// Strict overrides the configured StrictRetrieval for this call. In strict
+	// mode a failed search path returns ErrPartialFailure instead of degraded results.
+	Strict *bool
+	// ExcludeTags drops memories carrying any of these tags, pinned ones included.
+	ExcludeTags []string
+	// FTSStrategy overrides the configured FTSStrategy for this call when set.
+	FTSStrategy string
+	// FusionVectorWeight overrides the configured FusionVectorWeight for this
+	// call when it is between 0 and 1.
+	FusionVectorWeight float64
+
+	// memories, when set, are the stored memories vector search ranks
+	// instead of reading the store, shared by the queries of RetrieveAll.
+	memories []MemoryItem
+}
+
+// ErrPartialFailure is returned in strict mode when one retrieval path failed.
+var ErrPartialFailure = errors.New("partial retrieval failure")
+
 // Retrieve performs unified memory retrieval using both vector search and FTS.
 // 1. Uses tool_model to transform the query (answer + rephrase)
 // 2. Embeds transformed queries and performs vector search
 // 3. Performs FTS based on configured strategy
 // 4. Fuses and ranks results
 func (r *Retriever) Retrieve(ctx context.Context, query string) (*RetrievalResponse, error) {
-	var (
-		vectorResults []SearchResult
-		ftsResults    []MemoryFTSResult
-		vectorErr     error
-		ftsErr        error
-		wg            sync.WaitGroup
-	)
-
-	// Run vector search path in parallel
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		vectorResults, vectorErr = r.vectorSearch(ctx, query)
-	}()
+	return r.RetrieveWithOptions(ctx, query, RetrieveOptions{})
+}
 
-	// Run FTS search path in parallel
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		ftsResults, ftsErr = r.ftsSearch(ctx, query)
-	}()
+// RetrieveWithOptions performs unified memory retrieval with per-call options.
+func (r *Retriever) RetrieveWithOptions(ctx context.Context, query string, opts RetrieveOptions) (*RetrievalResponse, error) {
+	class := r.classifyQuery(ctx, query)
+	r = r.withOverrides(class, opts)
+	originalQuery := query
+	// Memories saved before an alias was configured still carry the alias.
+	opts.ExcludeTags = tagging.Expand(opts.ExcludeTags, r.config.TagAliases)
+
+	var rewrittenQuery string
+	if len(opts.History) > 0 && (r.plan == nil || r.plan.useHistory) {
+		if rewritten := r.rewriteWithHistory(ctx, query, opts.History); rewritten != query {
+			rewrittenQuery = rewritten
+			query = rewritten
+		}
+	}
 
-	wg.Wait()
+	filter := MemoryFilter{ExcludeTags: opts.ExcludeTags}
+	timeRange := opts.TimeRange
+	unfiltered := query
+	parsed := false
+	if timeRange == nil && r.config.TemporalFilter {
+		if tr, stripped, ok := temporal.Parse(query, time.Now()); ok {
+			timeRange = &tr
+			parsed = true
+			if stripped != "" {
+				query = stripped
+			}
+		}
+	}
+	if timeRange != nil {
+		filter.Created = *timeRange
+	}
+
+	// A query in another language than the memories finds nothing with FTS,
+	// and little with embedding models trained on a single language.
+	translatedQuery := r.translateQuery(ctx, query)
+	var vectorAlternates []string
+	if translatedQuery != "" && !IsMultilingualEmbeddingModel(r.embeddingModel.ModelID) {
+		vectorAlternates = append(vectorAlternates, translatedQuery)
+	}
 
-	// Log errors but continue if at least one path succeeded
-	if vectorErr != nil && ftsErr != nil {
+	ftsOnly := r.embeddingClient == nil
+	found := r.searchPaths(ctx, query, translatedQuery, filter, opts, vectorAlternates)
+	if parsed && found.empty() {
+		// The phrase may be part of what the memory says ("standups are on
+		// Monday") rather than when it was saved, so search without it.
+		filter.Created = TimeRange{}
+		timeRange = nil
+		query = unfiltered
+		found = r.searchPaths(ctx, query, translatedQuery, filter, opts, vectorAlternates)
+	}
+	vectorResults, queryEmbedding, ftsResults := found.vectorResults, found.queryEmbedding, found.ftsResults
+	vectorErr, ftsErr := found.vectorErr, found.ftsErr
+
+	// Fail only if both paths failed; otherwise flag the response as degraded
+	vectorFailed := vectorErr != nil && !errors.Is(vectorErr, ErrMalformedEmbedding)
+	if vectorFailed && ftsErr != nil {
 		return nil, fmt.Errorf("retrieval failed: vector: %v, fts: %v", vectorErr, ftsErr)
 	}
+	var warnings []string
+	if vectorFailed {
+		warnings = append(warnings, fmt.Sprintf("vector search failed: %v", vectorErr))
+	} else if vectorErr != nil {
+		warnings = append(warnings, fmt.Sprintf("vector search incomplete: %v", vectorErr))
+	}
+	if ftsErr != nil {
+		warnings = append(warnings, fmt.Sprintf("full-text search failed: %v", ftsErr))
+	}
+	strict := r.config.StrictRetrieval
+	if opts.Strict != nil {
+		strict = *opts.Strict
+	}
+	if strict && len(warnings) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrPartialFailure, strings.Join(warnings, "; "))
+	}
 
 	// Fuse results
 	now := time.Now().UTC()
-	unified := r.fuseResults(vectorResults, ftsResults, now)
+	unified := r.fuseResults(vectorResults, ftsResults, r.entityMemoryIDs(query), now)
+	r.addVectorSnippets(ctx, queryEmbedding, unified)
 	r.reinforceTopResult(unified, now)
+	r.recordRetrievals(unified, now)
+	unified = r.withPinned(unified, opts.ExcludeTags)
 
 	return &RetrievalResponse{
-		Results: unified,
-		Query:   query,
+		Results:         unified,
+		Query:           originalQuery,
+		RewrittenQuery:  rewrittenQuery,
+		TranslatedQuery: translatedQuery,
+		TimeRange:       timeRange,
+		Degraded:        len(warnings) > 0,
+		Warnings:        warnings,
+		FTSOnly:         ftsOnly,
+		Class:           string(class),
 	}, nil
 }
 
+// pathResults is what the vector and FTS search paths found for a query.
+type pathResults struct {
+	vectorResults  []SearchResult
+	queryEmbedding []float32
+	ftsResults     []MemoryFTSResult
+	vectorErr      error
+	ftsErr         error
+}
+
+// empty reports whether both paths succeeded and found nothing.
+func (p pathResults) empty() bool {
+	return len(p.vectorResults) == 0 && len(p.ftsResults) == 0 && p.vectorErr == nil && p.ftsErr == nil
+}
+
+// searchPaths runs vector search and FTS for query in parallel. FTS also
+// searches translatedQuery when it is set. Without an embedding client
+// (offline mode) only FTS runs.
+func (r *Retriever) searchPaths(ctx context.Context, query, translatedQuery string, filter MemoryFilter, opts RetrieveOptions, vectorAlternates []string) pathResults {
+	ftsQuery := query
+	if translatedQuery != "" {
+		ftsQuery = query + " " + translatedQuery
+	}
+
+	var p pathResults
+	var wg sync.WaitGroup
+	if r.embeddingClient != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.vectorResults, p.queryEmbedding, p.vectorErr = r.vectorSearch(ctx, query, filter, opts.memories, vectorAlternates...)
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		p.ftsResults, p.ftsErr = r.ftsSearch(ctx, ftsQuery, filter)
+	}()
+	wg.Wait()
+	return p
+}
+
+// withOverrides returns r, or a copy of r that searches as the plan for
+// class says, with the settings opts overrides.
+func (r *Retriever) withOverrides(class QueryClass, opts RetrieveOptions) *Retriever {
+	w := opts.FusionVectorWeight
+	if class == "" && opts.FTSStrategy == "" && (w <= 0 || w >= 1) {
+		return r
+	}
+	overridden := *r
+	if plan, ok := queryPlans[class]; ok {
+		overridden.plan = &plan
+		overridden.config.FTSStrategy = plan.ftsStrategy
+	}
+	if opts.FTSStrategy != "" {
+		overridden.config.FTSStrategy = opts.FTSStrategy
+	}
+	if w > 0 && w < 1 {
+		overridden.config.FusionVectorWeight = w
+	}
+	return &overridden
+}
+
+// rewriteWithHistory uses tool_model to resolve references in a follow-up query
+// ("what about the second option?") against recent conversation turns.
+// Returns the original query if rewriting is unavailable or fails.
+func (r *Retriever) rewriteWithHistory(ctx context.Context, query string, history []HistoryItem) string {
+	if r.queryClient == nil {
+		return query
+	}
+
+	turns := r.config.RewriteHistoryTurns
+	if turns <= 0 {
+		turns = 4
+	}
+	if len(history) > turns {
+		history = history[len(history)-turns:]
+	}
+
+	var conversation strings.Builder
+	for _, h := range history {
+		conversation.WriteString(fmt.Sprintf("%s: %s\n", h.Role, strings.TrimSpace(h.Content)))
+	}
+
+	prompt := fmt.Sprintf(`Rewrite the latest user message as a standalone search query.
+Resolve pronouns and references using the conversation. Keep it short.
+
+Conversation:
+%s
+Latest user message: %s
+
+Respond with ONLY the rewritten query, no other text.`, conversation.String(), query)
+
+	ctx, cancel := context.WithTimeout(ctx, r.transformTimeout())
+	defer cancel()
+	stream, err := r.queryClient.ChatStream(ctx, r.toolModel, prompt)
+	if err != nil {
+		return query
+	}
+	defer stream.Close()
+
+	var sb strings.Builder
+	for stream.Next() {
+		sb.WriteString(stream.GetChunk())
+	}
+	if stream.Err() != nil {
+		return query
+	}
+
+	rewritten := strings.Trim(strings.TrimSpace(sb.String()), "\"'")
+	if rewritten == "" {
+		return query
+	}
+	return rewritten
+}
+
 // vectorSearch performs vector similarity search with LLM query transformation.
-func (r *Retriever) vectorSearch(ctx context.Context, query string) ([]SearchResult, error) {
+// alternates, such as a translation of query, are searched as they are. It
+// also returns the embedding of query itself, when it could be embedded.
+func (r *Retriever) vectorSearch(ctx context.Context, query string, filter MemoryFilter, memories []MemoryItem, alternates ...string) ([]SearchResult, []float32, error) {
 	// Transform query using tool_model: get brief answer and rephrased query
 	transformedQueries, err := r.transformQueryForVector(ctx, query)
 	if err != nil {
 		// Fallback to original query if transformation fails
 		transformedQueries = []string{query}
 	}
+	transformedQueries = append(transformedQueries, alternates...)
 
 	// Embed all transformed queries and collect results
 	var allResults []SearchResult
 	seenIDs := make(map[string]bool)
+	var lastErr error
+	var queryEmbedding []float32
+	var malformed error
+	succeeded := 0
 
-	for _, q := range transformedQueries {
-		embedding, err := r.embeddingClient.Embed(ctx, r.embeddingModel, q)
+	for i, q := range transformedQueries {
+		embedding, err := r.embed(ctx, q)
 		if err != nil {
+			lastErr = fmt.Errorf("embedding failed: %w", err)
 			continue // skip failed embeddings
 		}
+		if i == 0 {
+			queryEmbedding = embedding
+		}
 
-		results, err := r.store.SearchMemories(embedding, r.config.MemoryTopK, r.config.MinSimilarity)
-		if err != nil {
+		var results []SearchResult
+		if memories != nil {
+			results = store.RankBySimilarity(memories, embedding, r.candidateTopK(), r.config.MinSimilarity, filter)
+			malformed = store.MalformedEmbeddings(memories)
+		} else if results, err = r.store.SearchMemoriesFiltered(embedding, r.candidateTopK(), r.config.MinSimilarity, filter); errors.Is(err, ErrMalformedEmbedding) {
+			malformed = err
+		} else if err != nil {
+			lastErr = err
 			continue
 		}
+		succeeded++
 
 		// Deduplicate
 		for _, res := range results {
@@ -145,22 +388,39 @@ func (r *Retriever) vectorSearch(ctx context.Context, query string) ([]SearchRes
 		}
 	}
 
+	// The path only fails if no query variant could be searched
+	if succeeded == 0 && lastErr != nil {
+		return nil, nil, lastErr
+	}
+
 	// Re-sort by similarity and limit
 	sort.Slice(allResults, func(i, j int) bool {
 		return allResults[i].Similarity > allResults[j].Similarity
 	})
 
-	if len(allResults) > r.config.MemoryTopK {
-		allResults = allResults[:r.config.MemoryTopK]
+	if len(allResults) > r.candidateTopK() {
+		allResults = allResults[:r.candidateTopK()]
 	}
 
-	return allResults, nil
+	// The other memories were searched; report the skipped ones alongside.
+	return allResults, queryEmbedding, malformed
+}
+
+// embed embeds q within the embedding timeout.
+func (r *Retriever) embed(ctx context.Context, q string) ([]float32, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.embeddingTimeout())
+	defer cancel()
+	return r.embeddingClient.Embed(ctx, r.embeddingModel, q)
 }
 
 // transformQueryForVector uses tool_model to generate transformed queries for better embedding.
 // Returns: [brief answer, rephrased query for search]
 func (r *Retriever) transformQueryForVector(ctx context.Context, query string) ([]string, error) {
-	if r.queryClient == nil {
+	expansions := defaultExpansions
+	if r.plan != nil {
+		expansions = r.plan.expansions
+	}
+	if r.queryClient == nil || expansions == 0 {
 		return []string{query}, nil
 	}
 
@@ -174,6 +434,8 @@ Respond in this exact format (no other text):
 ANSWER: <brief answer>
 REPHRASE: <rephrased query>`, query)
 
+	ctx, cancel := context.WithTimeout(ctx, r.transformTimeout())
+	defer cancel()
 	stream, err := r.queryClient.ChatStream(ctx, r.toolModel, prompt)
 	if err != nil {
 		return nil, err
@@ -189,17 +451,18 @@ REPHRASE: <rephrased query>`, query)
 	}
 
 	response := sb.String()
-	return parseTransformResponse(response, query), nil
+	return parseTransformResponse(response, query, expansions > 1), nil
 }
 
-// parseTransformResponse extracts transformed queries from LLM response.
-func parseTransformResponse(response, originalQuery string) []string {
+// parseTransformResponse extracts transformed queries from LLM response,
+// leaving out the hypothetical answer unless withAnswer is set.
+func parseTransformResponse(response, originalQuery string, withAnswer bool) []string {
 	results := []string{originalQuery} // always include original
 
 	lines := strings.Split(response, "\n")
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "ANSWER:") {
+		if strings.HasPrefix(line, "ANSWER:") && withAnswer {
 			answer := strings.TrimSpace(strings.TrimPrefix(line, "ANSWER:"))
 			if answer != "" {
 				results = append(results, answer)
@@ -215,25 +478,33 @@ func parseTransformResponse(response, originalQuery string) []string {
 	return results
 }
 
-// ftsSearch performs FTS based on the configured strategy.
-func (r *Retriever) ftsSearch(ctx context.Context, query string) ([]MemoryFTSResult, error) {
-	// Always use auto strategy as it's the only supported mode now
-	return r.ftsSearchAuto(ctx, query)
+// ftsSearch performs FTS based on the configured strategy. Unknown strategies
+// search as auto does.
+func (r *Retriever) ftsSearch(ctx context.Context, query string, filter MemoryFilter) ([]MemoryFTSResult, error) {
+	switch r.config.FTSStrategy {
+	case utils.FTSStrategyDirect:
+		return r.ftsSearchDirect(query, filter)
+	case utils.FTSStrategySummary:
+		return r.ftsSearchSummary(ctx, query, filter)
+	default:
+		return r.ftsSearchAuto(ctx, query, filter)
+	}
 }
 
 // ftsSearchDirect tokenizes the raw query and performs FTS.
-func (r *Retriever) ftsSearchDirect(query string) ([]MemoryFTSResult, error) {
-	ftsQuery := tokenizeForFTS(query)
+func (r *Retriever) ftsSearchDirect(query string, filter MemoryFilter) ([]MemoryFTSResult, error) {
+	ftsQuery := tokenizeForFTS(query, analyzer(r.config, query))
 	if ftsQuery == "" {
 		return nil, nil
 	}
-	return r.store.SearchMemoriesFTS(ftsQuery, r.config.MemoryTopK)
+	return r.store.SearchMemoriesFTSFiltered(ftsQuery, r.candidateTopK(), filter)
 }
 
 // ftsSearchSummary uses tool_model to summarize the query, then performs FTS.
-func (r *Retriever) ftsSearchSummary(ctx context.Context, query string) ([]MemoryFTSResult, error) {
+// It falls back to the raw query when the summary fails or times out.
+func (r *Retriever) ftsSearchSummary(ctx context.Context, query string, filter MemoryFilter) ([]MemoryFTSResult, error) {
 	if r.queryClient == nil {
-		return r.ftsSearchDirect(query)
+		return r.ftsSearchDirect(query, filter)
 	}
 
 	prompt := fmt.Sprintf(`Summarize this query in one short sentence for text search:
@@ -241,9 +512,11 @@ Query: %s
 
 Respond with ONLY the summary, no other text.`, query)
 
+	ctx, cancel := context.WithTimeout(ctx, r.transformTimeout())
+	defer cancel()
 	stream, err := r.queryClient.ChatStream(ctx, r.toolModel, prompt)
 	if err != nil {
-		return r.ftsSearchDirect(query) // fallback
+		return r.ftsSearchDirect(query, filter) // fallback
 	}
 	defer stream.Close()
 
@@ -253,20 +526,39 @@ Respond with ONLY the summary, no other text.`, query)
 	}
 
 	summary := strings.TrimSpace(sb.String())
-	if summary == "" {
-		return r.ftsSearchDirect(query)
+	if summary == "" || stream.Err() != nil {
+		return r.ftsSearchDirect(query, filter)
 	}
 
-	ftsQuery := tokenizeForFTS(summary)
+	ftsQuery := tokenizeForFTS(summary, analyzer(r.config, summary))
 	if ftsQuery == "" {
 		return nil, nil
 	}
-	return r.store.SearchMemoriesFTS(ftsQuery, r.config.MemoryTopK)
+	return r.store.SearchMemoriesFTSFiltered(ftsQuery, r.candidateTopK(), filter)
 }
 
-// ftsSearchAuto tries direct first, falls back to summary if few results.
-func (r *Retriever) ftsSearchAuto(ctx context.Context, query string) ([]MemoryFTSResult, error) {
-	results, err := r.ftsSearchDirect(query)
+// ftsSearchAuto runs the direct and summary strategies concurrently. Direct
+// results are used alone when there are enough of them; otherwise the summary
+// results are merged in if they arrive within the FTS latency budget.
+func (r *Retriever) ftsSearchAuto(ctx context.Context, query string, filter MemoryFilter) ([]MemoryFTSResult, error) {
+	if r.queryClient == nil {
+		return r.ftsSearchDirect(query, filter)
+	}
+
+	summaryCtx, cancel := context.WithTimeout(ctx, r.ftsLatencyBudget())
+	defer cancel()
+
+	type ftsOutcome struct {
+		results []MemoryFTSResult
+		err     error
+	}
+	summaryDone := make(chan ftsOutcome, 1)
+	go func() {
+		results, err := r.ftsSearchSummary(summaryCtx, query, filter)
+		summaryDone <- ftsOutcome{results: results, err: err}
+	}()
+
+	results, err := r.ftsSearchDirect(query, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -280,10 +572,14 @@ func (r *Retriever) ftsSearchAuto(ctx context.Context, query string) ([]MemoryFT
 		return results, nil
 	}
 
-	// Otherwise, try summary-based search
-	summaryResults, err := r.ftsSearchSummary(ctx, query)
-	if err != nil {
-		return results, nil // return what we have
+	var summary ftsOutcome
+	select {
+	case summary = <-summaryDone:
+	case <-summaryCtx.Done():
+		return results, nil // the summary is too slow; return what we have
+	}
+	if summary.err != nil {
+		return results, nil
 	}
 
 	// Merge and deduplicate
@@ -291,7 +587,7 @@ func (r *Retriever) ftsSearchAuto(ctx context.Context, query string) ([]MemoryFT
 	for _, r := range results {
 		seenIDs[r.Item.ID] = true
 	}
-	for _, r := range summaryResults {
+	for _, r := range summary.results {
 		if !seenIDs[r.Item.ID] {
 			seenIDs[r.Item.ID] = true
 			results = append(results, r)
@@ -301,43 +597,41 @@ func (r *Retriever) ftsSearchAuto(ctx context.Context, query string) ([]MemoryFT
 	return results, nil
 }
 
-// tokenizeForFTS converts a query string to an FTS-safe query.
-func tokenizeForFTS(query string) string {
-	query = strings.TrimSpace(query)
-	if query == "" {
-		return ""
-	}
-
-	// Split into words and filter
-	words := strings.Fields(query)
-	var tokens []string
-	for _, w := range words {
-		// Remove FTS special characters (FTS5 operators: AND OR NOT NEAR + - * ^ : " ')
-		w = strings.ReplaceAll(w, "\"", "")
-		w = strings.ReplaceAll(w, "'", "")
-		w = strings.ReplaceAll(w, "*", "")
-		w = strings.ReplaceAll(w, "-", " ")
-		w = strings.ReplaceAll(w, "+", "")
-		w = strings.ReplaceAll(w, "^", "")
-		w = strings.ReplaceAll(w, ":", "")
-		w = strings.ReplaceAll(w, "(", "")
-		w = strings.ReplaceAll(w, ")", "")
-		w = strings.TrimSpace(w)
-		if len(w) > 1 { // skip single characters
-			tokens = append(tokens, w)
-		}
-	}
+// transformTimeout bounds each tool-model step that prepares the query:
+// rewriting, translation, and transformation. A step that runs out of time is
+// skipped and the query is used as it is.
+func (r *Retriever) transformTimeout() time.Duration {
+	return msOrDefault(r.config.TransformTimeoutMs, defaultTransformTimeout)
+}
 
-	if len(tokens) == 0 {
-		return ""
-	}
+// embeddingTimeout bounds each query embedding. When every embedding times
+// out, the vector path fails and retrieval falls back to full-text search.
+func (r *Retriever) embeddingTimeout() time.Duration {
+	return msOrDefault(r.config.EmbeddingTimeoutMs, defaultEmbeddingTimeout)
+}
 
-	// Join with OR for broader matching
-	return strings.Join(tokens, " OR ")
+// ftsLatencyBudget bounds how long FTS waits for the summary strategy.
+func (r *Retriever) ftsLatencyBudget() time.Duration {
+	return msOrDefault(r.config.FTSLatencyBudgetMs, defaultFTSLatencyBudget)
+}
+
+// Step deadlines used when the config sets none.
+const (
+	defaultTransformTimeout = 2 * time.Second
+	defaultEmbeddingTimeout = 1500 * time.Millisecond
+	defaultFTSLatencyBudget = 3 * time.Second
+)
+
+func msOrDefault(ms int, fallback time.Duration) time.Duration {
+	if ms <= 0 {
+		return fallback
+	}
+	return time.Duration(ms) * time.Millisecond
 }
 
 // fuseResults combines vector and FTS results into a unified ranked list.
-func (r *Retriever) fuseResults(vectorResults []SearchResult, ftsResults []MemoryFTSResult, now time.Time) []UnifiedResult {
+// Memories linked to an entity in entityIDs get their base score boosted.
+func (r *Retriever) fuseResults(vectorResults []SearchResult, ftsResults []MemoryFTSResult, entityIDs map[string]bool, now time.Time) []UnifiedResult {
 	// Build a map of results by ID
 	resultMap := make(map[string]*UnifiedResult)
 
@@ -370,7 +664,13 @@ func (r *Retriever) fuseResults(vectorResults []SearchResult, ftsResults []Memor
 	// Calculate unified scores and convert to slice
 	var results []UnifiedResult
 	for _, ur := range resultMap {
-		ur.BaseScore = calculateUnifiedScore(ur)
+		ur.BaseScore = calculateUnifiedScore(ur, r.fusionVectorWeight())
+		if entityIDs[ur.Item.ID] {
+			// Not capped at 1 so entity matches still rank above saturated non-matches.
+			ur.EntityMatch = true
+			ur.BaseScore *= entityBoost
+		}
+		ur.BaseScore *= r.kindWeight(ur.Item.Kind)
 		ur.Freshness = decay.Freshness(now, decay.EffectiveLastRetrievedAt(ur.Item), ur.Item.StabilityDays)
 		ur.Score = decay.FinalScore(ur.BaseScore, ur.Freshness, ur.Item.Confidence)
 		results = append(results, *ur)
@@ -381,12 +681,71 @@ func (r *Retriever) fuseResults(vectorResults []SearchResult, ftsResults []Memor
 		return results[i].Score > results[j].Score
 	})
 
-	// Limit to top K
-	if len(results) > r.config.MemoryTopK {
-		results = results[:r.config.MemoryTopK]
+	return r.applyKindBudgets(dedupeResults(results))
+}
+
+// dedupeResults merges results whose texts are the same once normalized,
+// keeping the best-scoring one. results must be sorted by score, best first.
+// The kept result takes on the sources and snippet the duplicates were found by.
+func dedupeResults(results []UnifiedResult) []UnifiedResult {
+	kept := make(map[string]int, len(results))
+	deduped := results[:0]
+	for _, res := range results {
+		key := memutils.NormalizeText(res.Item.Text)
+		i, ok := kept[key]
+		if !ok {
+			kept[key] = len(deduped)
+			deduped = append(deduped, res)
+			continue
+		}
+		best := &deduped[i]
+		best.DuplicateIDs = append(best.DuplicateIDs, res.Item.ID)
+		best.Source = mergeSources(best.Source, res.Source)
+		if best.Snippet == "" {
+			best.Snippet = res.Snippet
+		}
 	}
+	return deduped
+}
 
-	return results
+// mergeSources combines the retrieval sources of two results.
+func mergeSources(a, b string) string {
+	if a == b {
+		return a
+	}
+	return "both"
+}
+
+// entityBoost multiplies the base score of memories linked to an entity named in the query.
+const entityBoost = 1.15
+
+// entityMemoryIDs returns the IDs of memories linked to entities mentioned in
+// the query. Returns nil when entity linking is disabled or lookup fails.
+func (r *Retriever) entityMemoryIDs(query string) map[string]bool {
+	if !r.config.EntityLinking {
+		return nil
+	}
+
+	known, err := r.store.GetAllEntities()
+	if err != nil || len(known) == 0 {
+		return nil
+	}
+
+	mentioned := entities.MentionedIn(query, known)
+	if len(mentioned) == 0 {
+		return nil
+	}
+
+	entityIDs := make([]string, len(mentioned))
+	for i, e := range mentioned {
+		entityIDs[i] = e.ID
+	}
+
+	ids, err := r.store.EntityMemoryIDs(entityIDs)
+	if err != nil {
+		return nil
+	}
+	return ids
 }
 
 func (r *Retriever) reinforceTopResult(results []UnifiedResult, now time.Time) {
@@ -409,9 +768,98 @@ func (r *Retriever) reinforceTopResult(results []UnifiedResult, now time.Time) {
 	top.Item.StabilityDays = stabilityDays
 }
 
+// RecordRetrieval counts a retrieval answered without searching, e.g. from a
+// cache of responses, the way a search counts it: the best-scoring result is
+// reinforced and each result that matched the query is stamped. Pinned
+// memories added without matching are not counted, and results is left as is.
+func (r *Retriever) RecordRetrieval(results []UnifiedResult) {
+	matched := make([]UnifiedResult, 0, len(results))
+	for _, res := range results {
+		if res.Source == "pinned" {
+			continue
+		}
+		matched = append(matched, res)
+		// Matching pinned memories come first regardless of score.
+		if last := len(matched) - 1; res.Score > matched[0].Score {
+			matched[0], matched[last] = matched[last], matched[0]
+		}
+	}
+	now := time.Now().UTC()
+	r.reinforceTopResult(matched, now)
+	r.recordRetrievals(matched, now)
+}
+
+// recordRetrievals counts a retrieval of each result and stamps it with now.
+// Pinned memories added afterwards without matching the query are not counted.
+func (r *Retriever) recordRetrievals(results []UnifiedResult, now time.Time) {
+	ids := make([]string, len(results))
+	for i, res := range results {
+		ids[i] = res.Item.ID
+	}
+	retrievedAt := now.UTC()
+	if err := r.store.RecordMemoryRetrievals(ids, retrievedAt); err != nil {
+		return
+	}
+
+	for i := range results {
+		results[i].Item.TimesRetrieved++
+		results[i].Item.LastRetrievedAt = &retrievedAt
+	}
+}
+
+// withPinned puts pinned memories ahead of results, whether or not the query
+// matched them, so they are the last to be dropped by the token budget.
+// Pinned memories carrying an excluded tag are left out.
+func (r *Retriever) withPinned(results []UnifiedResult, excludeTags []string) []UnifiedResult {
+	pinned, err := r.store.PinnedMemories()
+	if err != nil || len(pinned) == 0 {
+		return results
+	}
+	filter := MemoryFilter{ExcludeTags: excludeTags}
+
+	matched := make(map[string]UnifiedResult)
+	for _, res := range results {
+		if res.Item.Pinned {
+			matched[res.Item.ID] = res
+		}
+	}
+
+	combined := make([]UnifiedResult, 0, len(pinned)+len(results))
+	for _, item := range pinned {
+		if !filter.Matches(item) {
+			continue
+		}
+		if res, ok := matched[item.ID]; ok {
+			combined = append(combined, res)
+		} else {
+			combined = append(combined, UnifiedResult{Item: item, Source: "pinned"})
+		}
+	}
+	for _, res := range results {
+		if !res.Item.Pinned {
+			combined = append(combined, res)
+		}
+	}
+	return combined
+}
+
+// defaultFusionVectorWeight is the share of vector similarity in the score of
+// memories found by both paths when the config sets none.
+const defaultFusionVectorWeight = 0.6
+
+// fusionVectorWeight returns the configured share of vector similarity in the
+// score of memories found by both paths.
+func (r *Retriever) fusionVectorWeight() float64 {
+	if w := r.config.FusionVectorWeight; w > 0 && w < 1 {
+		return w
+	}
+	return defaultFusionVectorWeight
+}
+
 // calculateUnifiedScore computes a normalized score for ranking.
-// Memories found in both vector and FTS get a boost.
-func calculateUnifiedScore(ur *UnifiedResult) float64 {
+// Memories found in both vector and FTS get a boost; vectorWeight is the share
+// of vector similarity in their score, and FTS gets the rest.
+func calculateUnifiedScore(ur *UnifiedResult, vectorWeight float64) float64 {
 	var score float64
 
 	switch ur.Source {
@@ -439,7 +887,7 @@ func calculateUnifiedScore(ur *UnifiedResult) float64 {
 			ftsScore = 1
 		}
 		// Weighted combination with boost
-		score = (vectorScore*0.6 + ftsScore*0.4) * 1.2
+		score = (vectorScore*vectorWeight + ftsScore*(1-vectorWeight)) * 1.2
 		if score > 1 {
 			score = 1
 		}
@@ -450,20 +898,88 @@ func calculateUnifiedScore(ur *UnifiedResult) float64 {
 
 // FormatAsText formats the retrieval results as readable text.
 func FormatAsText(resp *RetrievalResponse) string {
-	if resp == nil || len(resp.Results) == 0 {
+	if resp == nil {
 		return "No memories found."
 	}
 
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("Found %d memories:\n\n", len(resp.Results)))
+	for _, w := range resp.Warnings {
+		sb.WriteString(fmt.Sprintf("Warning: %s\n", w))
+	}
+	if resp.FTSOnly {
+		sb.WriteString("Note: full-text search only (offline mode)\n")
+	}
+	if len(resp.Warnings) > 0 || resp.FTSOnly {
+		sb.WriteString("\n")
+	}
+	if len(resp.Results) == 0 {
+		sb.WriteString("No memories found.")
+		return sb.String()
+	}
+
+	if resp.Summary != "" {
+		sb.WriteString(fmt.Sprintf("Found %d memories, summarized to fit the token budget:\n\n", len(resp.Results)))
+	} else {
+		sb.WriteString(fmt.Sprintf("Found %d memories:\n\n", len(resp.Results)))
+	}
+	if resp.TranslatedQuery != "" {
+		sb.WriteString(fmt.Sprintf("Also searched as: %s\n\n", resp.TranslatedQuery))
+	}
+	if resp.TimeRange != nil {
+		sb.WriteString(fmt.Sprintf("Time range: %s\n\n", formatTimeRange(*resp.TimeRange)))
+	}
+
+	if resp.Summary != "" {
+		sb.WriteString(resp.Summary)
+		sb.WriteString("\n")
+		return sb.String()
+	}
 
 	for i, r := range resp.Results {
-		sb.WriteString(fmt.Sprintf("%d. [%.2f] %s\n", i+1, r.Score, r.Item.Text))
+		if r.Item.Pinned {
+			sb.WriteString(fmt.Sprintf("%d. [pinned] %s\n", i+1, r.Item.Text))
+		} else {
+			sb.WriteString(fmt.Sprintf("%d. [%.2f] %s\n", i+1, r.Score, r.Item.Text))
+		}
 		if len(r.Item.Tags) > 0 {
 			sb.WriteString(fmt.Sprintf("   Tags: %s\n", strings.Join(r.Item.Tags, ", ")))
 		}
 		sb.WriteString(fmt.Sprintf("   Source: %s\n", r.Source))
+		if r.Snippet != "" {
+			sb.WriteString(fmt.Sprintf("   Match: %s\n", r.Snippet))
+		}
+		if r.Item.Kind != "" && r.Item.Kind != KindFact {
+			sb.WriteString(fmt.Sprintf("   Kind: %s\n", r.Item.Kind))
+		}
+	}
+	if resp.Omitted > 0 {
+		sb.WriteString(fmt.Sprintf("\n(%d lower-ranked memories omitted to fit the token budget)\n", resp.Omitted))
 	}
 
 	return sb.String()
 }
+
+// FitTokens drops the lowest-ranked results until FormatAsText(resp) fits in
+// maxTokens as counted by counter, recording how many were dropped. The top
+// result is always kept. maxTokens <= 0 disables the limit.
+func FitTokens(resp *RetrievalResponse, counter tokenizer.Counter, maxTokens int) {
+	if resp == nil || maxTokens <= 0 {
+		return
+	}
+	for len(resp.Results) > 1 && counter.Count(FormatAsText(resp)) > maxTokens {
+		resp.Results = resp.Results[:len(resp.Results)-1]
+		resp.Omitted++
+	}
+}
+
+func formatTimeRange(tr TimeRange) string {
+	const layout = "2006-01-02 15:04"
+	start, end := "beginning", "now"
+	if !tr.Start.IsZero() {
+		start = tr.Start.Format(layout)
+	}
+	if !tr.End.IsZero() {
+		end = tr.End.Format(layout)
+	}
+	return start + " to " + end
+}
@@ -3,11 +3,14 @@ package retrieval
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/mockprovider"
 	"github.com/austiecodes/gomor/internal/provider"
 	"github.com/austiecodes/gomor/internal/types"
 	"github.com/austiecodes/gomor/internal/utils"
@@ -78,6 +81,54 @@ func (f *fakeQueryClient) ListModels(ctx context.Context) ([]string, error) {
 	return []string{"fake-model"}, nil
 }
 
+// recordingQueryClient answers a prompt containing one of the keys of answers
+// with its value, and any other prompt with nothing. It records every prompt
+// it receives and is safe for the concurrent vector and full-text paths.
+type recordingQueryClient struct {
+	answers map[string]string
+
+	mu      sync.Mutex
+	prompts []string
+}
+
+func (c *recordingQueryClient) ChatStream(ctx context.Context, model types.Model, query string) (client.StreamResponse, error) {
+	c.mu.Lock()
+	c.prompts = append(c.prompts, query)
+	c.mu.Unlock()
+	for marker, answer := range c.answers {
+		if strings.Contains(query, marker) {
+			return &fakeStream{chunks: []string{answer}}, nil
+		}
+	}
+	return &fakeStream{}, nil
+}
+
+func (c *recordingQueryClient) ChatStreamWithContext(ctx context.Context, model types.Model, systemContext, query string) (client.StreamResponse, error) {
+	return c.ChatStream(ctx, model, query)
+}
+
+func (c *recordingQueryClient) ListModels(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+// recorded returns the prompts received so far, oldest first.
+func (c *recordingQueryClient) recorded() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.prompts)
+}
+
+// asked counts the prompts containing marker.
+func (c *recordingQueryClient) asked(marker string) int {
+	n := 0
+	for _, p := range c.recorded() {
+		if strings.Contains(p, marker) {
+			n++
+		}
+	}
+	return n
+}
+
 // containsAny reports whether text contains any of the needles (case-insensitive).
 func containsAny(text string, needles []string) bool {
 	lower := strings.ToLower(text)
@@ -171,13 +222,15 @@ func TestRetriever_EndToEnd_FakeClients(t *testing.T) {
 	}
 }
 
-// TestRetriever_RealClients_Debug uses real embedding/query clients from config
-// and prints all intermediate results for debugging.
-// Run with: go test ./internal/memory -run TestRetriever_RealClients_Debug -v
+// TestRetriever_RealClients_Debug uses the real OpenAI embedding/query clients,
+// served by the mock API, and prints all intermediate results for debugging.
+// Run with: go test ./internal/memory/retrieval -run TestRetriever_RealClients_Debug -v
 func TestRetriever_RealClients_Debug(t *testing.T) {
 	ctx := context.Background()
 
-	// Load real config
+	// Point the config and store at the mock API and a temporary home
+	server := mockprovider.New(t)
+	mockprovider.Install(t, server.Config())
 	cfg, err := utils.LoadConfig()
 	if err != nil {
 		t.Fatalf("load config: %v", err)
@@ -292,7 +345,7 @@ func TestRetriever_RealClients_Debug(t *testing.T) {
 
 	// Step 2: Vector search
 	fmt.Println("========== STEP 2: VECTOR SEARCH ==========")
-	vectorResults, err := retriever.vectorSearch(ctx, query)
+	vectorResults, _, err := retriever.vectorSearch(ctx, query, MemoryFilter{}, nil)
 	if err != nil {
 		fmt.Printf("Vector search error: %v\n", err)
 	} else {
@@ -305,7 +358,7 @@ func TestRetriever_RealClients_Debug(t *testing.T) {
 
 	// Step 3: FTS search
 	fmt.Println("========== STEP 3: FTS SEARCH ==========")
-	ftsResults, err := retriever.ftsSearch(ctx, query)
+	ftsResults, err := retriever.ftsSearch(ctx, query, MemoryFilter{})
 	if err != nil {
 		fmt.Printf("FTS search error: %v\n", err)
 	} else {
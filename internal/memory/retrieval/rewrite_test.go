@@ -0,0 +1,97 @@
+package retrieval
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// rewritePrompt marks the prompt asking the tool model for a standalone
+// search query.
+const rewritePrompt = "standalone search query"
+
+func TestRetrieveWithHistoryRewritesFollowUpQuery(t *testing.T) {
+	memStore := newTestStore(t)
+	config := utils.DefaultConfig()
+	config.Memory.MinSimilarity = 0.1
+	config.Memory.RewriteHistoryTurns = 2
+
+	queryClient := &recordingQueryClient{answers: map[string]string{rewritePrompt: "C++ virtual functions polymorphism"}}
+	retriever := NewRetriever(
+		memStore,
+		&fakeEmbeddingClient{},
+		queryClient,
+		types.Model{Provider: "fake", ModelID: "fake-embedding"},
+		types.Model{Provider: "fake", ModelID: "fake-tool"},
+		config.Memory,
+	)
+
+	item := &MemoryItem{
+		Text:      "C++ virtual functions enable polymorphism",
+		Source:    SourceExplicit,
+		Provider:  "fake",
+		ModelID:   "fake-embedding",
+		Dim:       2,
+		Embedding: NormalizeVector([]float32{1, 0}),
+	}
+	if err := memStore.SaveMemory(item); err != nil {
+		t.Fatalf("save memory: %v", err)
+	}
+
+	now := time.Now()
+	history := []HistoryItem{
+		{Role: "user", Content: "tell me about Go channels", CreatedAt: now.Add(-3 * time.Minute)},
+		{Role: "user", Content: "how do C++ virtual functions work?", CreatedAt: now.Add(-2 * time.Minute)},
+		{Role: "assistant", Content: "They dispatch through a vtable.", CreatedAt: now.Add(-time.Minute)},
+	}
+
+	resp, err := retriever.RetrieveWithOptions(context.Background(), "and what about that?", RetrieveOptions{History: history})
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if resp.Query != "and what about that?" {
+		t.Fatalf("expected original query to be preserved, got %q", resp.Query)
+	}
+	if resp.RewrittenQuery != "C++ virtual functions polymorphism" {
+		t.Fatalf("unexpected rewritten query: %q", resp.RewrittenQuery)
+	}
+	if len(resp.Results) == 0 || resp.Results[0].Item.ID != item.ID {
+		t.Fatalf("expected rewritten query to find the memory, got %+v", resp.Results)
+	}
+
+	prompt := queryClient.recorded()[0]
+	if strings.Contains(prompt, "Go channels") {
+		t.Fatal("expected history to be limited to the configured number of turns")
+	}
+	if !strings.Contains(prompt, "vtable") {
+		t.Fatal("expected the most recent turns in the rewrite prompt")
+	}
+}
+
+func TestRetrieveWithoutHistorySkipsRewrite(t *testing.T) {
+	memStore := newTestStore(t)
+	queryClient := &recordingQueryClient{answers: map[string]string{rewritePrompt: "should not be used"}}
+	retriever := NewRetriever(
+		memStore,
+		&fakeEmbeddingClient{},
+		queryClient,
+		types.Model{Provider: "fake", ModelID: "fake-embedding"},
+		types.Model{Provider: "fake", ModelID: "fake-tool"},
+		utils.DefaultConfig().Memory,
+	)
+
+	resp, err := retriever.Retrieve(context.Background(), "standalone question")
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if resp.RewrittenQuery != "" {
+		t.Fatalf("expected no rewrite, got %q", resp.RewrittenQuery)
+	}
+	if queryClient.asked(rewritePrompt) != 0 {
+		t.Fatal("rewrite prompt should not be sent without history")
+	}
+}
@@ -0,0 +1,83 @@
+package retrieval
+
+import (
+	"context"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/memory/memutils"
+)
+
+// Snippet markers, matching the ones SQLite's snippet() adds to FTS results.
+const (
+	snippetOpen     = ">>>"
+	snippetClose    = "<<<"
+	snippetEllipsis = "..."
+)
+
+// addVectorSnippets gives vector-only results a snippet like the ones FTS
+// results carry: the sentence whose embedding is nearest to the query's.
+// Single-sentence memories are left without one. All sentences are embedded in
+// one batch within the embedding timeout; on failure the results are unchanged.
+func (r *Retriever) addVectorSnippets(ctx context.Context, queryEmbedding []float32, results []UnifiedResult) {
+	if r.embeddingClient == nil || len(queryEmbedding) == 0 {
+		return
+	}
+
+	type candidate struct {
+		result    int
+		sentences []string
+		first     int // index of the first sentence in the batch
+	}
+	var candidates []candidate
+	var batch []string
+	for i, res := range results {
+		if res.Source != "vector" || res.Snippet != "" {
+			continue
+		}
+		sentences := memutils.SplitSentences(res.Item.Text)
+		if len(sentences) < 2 {
+			continue
+		}
+		candidates = append(candidates, candidate{result: i, sentences: sentences, first: len(batch)})
+		batch = append(batch, sentences...)
+	}
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.embeddingTimeout())
+	defer cancel()
+	embeddings, err := r.embeddingClient.EmbedBatch(ctx, r.embeddingModel, batch)
+	if err != nil || len(embeddings) != len(batch) {
+		return
+	}
+
+	query := memutils.NormalizeVector(queryEmbedding)
+	for _, c := range candidates {
+		best, bestSim := 0, -2.0
+		for j := range c.sentences {
+			emb := memutils.NormalizeVector(embeddings[c.first+j])
+			if len(emb) != len(query) {
+				continue
+			}
+			if sim := memutils.DotProduct(query, emb); sim > bestSim {
+				best, bestSim = j, sim
+			}
+		}
+		results[c.result].Snippet = sentenceSnippet(c.sentences, best)
+	}
+}
+
+// sentenceSnippet marks sentence i of sentences, with ellipses where
+// sentences were left out.
+func sentenceSnippet(sentences []string, i int) string {
+	var sb strings.Builder
+	if i > 0 {
+		sb.WriteString(snippetEllipsis)
+	}
+	sb.WriteString(snippetOpen + sentences[i] + snippetClose)
+	if i < len(sentences)-1 {
+		sb.WriteString(snippetEllipsis)
+	}
+	return sb.String()
+}
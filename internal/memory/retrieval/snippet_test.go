@@ -0,0 +1,79 @@
+package retrieval
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSentenceSnippet(t *testing.T) {
+	sentences := []string{"A.", "B.", "C."}
+	cases := map[int]string{
+		0: ">>>A.<<<...",
+		1: "...>>>B.<<<...",
+		2: "...>>>C.<<<",
+	}
+	for i, want := range cases {
+		if got := sentenceSnippet(sentences, i); got != want {
+			t.Errorf("sentenceSnippet(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestRetrieveGivesVectorHitsNearestSentenceSnippet(t *testing.T) {
+	memStore := newTestStore(t)
+	item := &MemoryItem{
+		Text:      "Dogs bark loudly. Calls go through virtual dispatch at runtime. Cats purr.",
+		Source:    SourceExplicit,
+		Provider:  "fake",
+		ModelID:   "fake-embedding",
+		Dim:       2,
+		Embedding: NormalizeVector([]float32{1, 0}),
+	}
+	if err := memStore.SaveMemory(item); err != nil {
+		t.Fatalf("save memory: %v", err)
+	}
+
+	// "C++" tokenizes to nothing for FTS, so the memory is a vector-only hit.
+	resp, err := newTestRetriever(memStore).Retrieve(context.Background(), "C++")
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].Source != "vector" {
+		t.Fatalf("expected one vector result, got %+v", resp.Results)
+	}
+
+	want := "...>>>Calls go through virtual dispatch at runtime.<<<..."
+	if got := resp.Results[0].Snippet; got != want {
+		t.Fatalf("snippet = %q, want %q", got, want)
+	}
+	if text := FormatAsText(resp); !strings.Contains(text, "Match: "+want) {
+		t.Fatalf("FormatAsText missing snippet:\n%s", text)
+	}
+}
+
+func TestRetrieveLeavesSingleSentenceVectorHitsWithoutSnippet(t *testing.T) {
+	memStore := newTestStore(t)
+	item := &MemoryItem{
+		Text:      "Calls go through virtual dispatch at runtime.",
+		Source:    SourceExplicit,
+		Provider:  "fake",
+		ModelID:   "fake-embedding",
+		Dim:       2,
+		Embedding: NormalizeVector([]float32{1, 0}),
+	}
+	if err := memStore.SaveMemory(item); err != nil {
+		t.Fatalf("save memory: %v", err)
+	}
+
+	resp, err := newTestRetriever(memStore).Retrieve(context.Background(), "C++")
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if len(resp.Results) != 1 {
+		t.Fatalf("expected one result, got %d", len(resp.Results))
+	}
+	if got := resp.Results[0].Snippet; got != "" {
+		t.Fatalf("snippet = %q, want none", got)
+	}
+}
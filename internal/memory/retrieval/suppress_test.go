@@ -0,0 +1,79 @@
+package retrieval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+func TestRetrieveSkipsSuppressedAndExcludedTags(t *testing.T) {
+	memStore := newTestStore(t)
+	config := utils.DefaultConfig()
+	config.Memory.MinSimilarity = 0.1
+	retriever := NewRetriever(
+		memStore,
+		&fakeEmbeddingClient{},
+		nil,
+		types.Model{Provider: "fake", ModelID: "fake-embedding"},
+		types.Model{},
+		config.Memory,
+	)
+
+	newItem := func(text string, tags []string, pinned bool) *MemoryItem {
+		item := &MemoryItem{
+			Text:      text,
+			Tags:      tags,
+			Source:    SourceExplicit,
+			Provider:  "fake",
+			ModelID:   "fake-embedding",
+			Dim:       2,
+			Embedding: NormalizeVector([]float32{1, 0}),
+			Pinned:    pinned,
+		}
+		if err := memStore.SaveMemory(item); err != nil {
+			t.Fatalf("save memory: %v", err)
+		}
+		return item
+	}
+	current := newItem("C++ virtual functions use a vtable", []string{"cpp"}, false)
+	stale := newItem("C++ virtual functions are slow, avoid them", []string{"cpp"}, false)
+	work := newItem("C++ virtual functions in the work codebase", []string{"work"}, false)
+	pinnedWork := newItem("Work answers must cite the style guide", []string{"Work"}, true)
+
+	if found, err := memStore.SetMemorySuppressed(stale.ID, true); err != nil || !found {
+		t.Fatalf("suppress: found=%v err=%v", found, err)
+	}
+
+	resp, err := retriever.RetrieveWithOptions(context.Background(), "virtual functions", RetrieveOptions{ExcludeTags: []string{"work"}})
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].Item.ID != current.ID {
+		t.Fatalf("expected only the unsuppressed, untagged memory, got %+v", resp.Results)
+	}
+
+	resp, err = retriever.Retrieve(context.Background(), "virtual functions")
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	ids := make(map[string]bool)
+	for _, r := range resp.Results {
+		ids[r.Item.ID] = true
+	}
+	if ids[stale.ID] {
+		t.Fatal("suppressed memory must never be retrieved")
+	}
+	if !ids[current.ID] || !ids[work.ID] || !ids[pinnedWork.ID] {
+		t.Fatalf("expected the other memories without exclusions, got %+v", resp.Results)
+	}
+
+	memories, err := memStore.GetAllMemories()
+	if err != nil {
+		t.Fatalf("get all memories: %v", err)
+	}
+	if len(memories) != 4 {
+		t.Fatalf("expected suppressed memory to be kept, got %d memories", len(memories))
+	}
+}
@@ -0,0 +1,132 @@
+package retrieval
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRetrieveAppliesTemporalFilterFromQuery(t *testing.T) {
+	memStore := newTestStore(t)
+	retriever := newTestRetriever(memStore)
+	retriever.config.TemporalFilter = true
+	now := time.Now()
+
+	recent := &MemoryItem{
+		Text:      "C++ virtual functions enable polymorphism",
+		Source:    SourceExplicit,
+		CreatedAt: now.Add(-time.Hour),
+		Provider:  "fake",
+		ModelID:   "fake-embedding",
+		Dim:       2,
+		Embedding: NormalizeVector([]float32{1, 0}),
+	}
+	old := &MemoryItem{
+		Text:      "C++ virtual functions enable polymorphism",
+		Source:    SourceExplicit,
+		CreatedAt: now.AddDate(0, 0, -30),
+		Provider:  "fake",
+		ModelID:   "fake-embedding",
+		Dim:       2,
+		Embedding: NormalizeVector([]float32{1, 0}),
+	}
+	for _, item := range []*MemoryItem{recent, old} {
+		if err := memStore.SaveMemory(item); err != nil {
+			t.Fatalf("save memory: %v", err)
+		}
+	}
+
+	resp, err := retriever.Retrieve(context.Background(), "C++ virtual functions polymorphism in the last 2 days")
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if resp.TimeRange == nil {
+		t.Fatal("expected a parsed time range")
+	}
+	if len(resp.Results) != 1 || resp.Results[0].Item.ID != recent.ID {
+		t.Fatalf("expected only the recent memory, got %+v", resp.Results)
+	}
+}
+
+func TestRetrieveWithExplicitTimeRange(t *testing.T) {
+	memStore := newTestStore(t)
+	retriever := newTestRetriever(memStore)
+	now := time.Now()
+
+	item := &MemoryItem{
+		Text:      "C++ virtual functions enable polymorphism",
+		Source:    SourceExplicit,
+		CreatedAt: now.AddDate(0, 0, -30),
+		Provider:  "fake",
+		ModelID:   "fake-embedding",
+		Dim:       2,
+		Embedding: NormalizeVector([]float32{1, 0}),
+	}
+	if err := memStore.SaveMemory(item); err != nil {
+		t.Fatalf("save memory: %v", err)
+	}
+
+	resp, err := retriever.RetrieveWithOptions(context.Background(), "C++ virtual functions", RetrieveOptions{
+		TimeRange: &TimeRange{Start: now.AddDate(0, 0, -7)},
+	})
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if len(resp.Results) != 0 {
+		t.Fatalf("expected memory outside range to be excluded, got %d results", len(resp.Results))
+	}
+}
+
+func TestRetrieveIgnoresTemporalPhrasesUnlessEnabled(t *testing.T) {
+	memStore := newTestStore(t)
+	item := &MemoryItem{
+		Text:      "Standups are on Monday at 10",
+		Source:    SourceExplicit,
+		CreatedAt: time.Now().AddDate(0, 0, -30),
+		Provider:  "fake",
+		ModelID:   "fake-embedding",
+		Dim:       2,
+		Embedding: NormalizeVector([]float32{1, 0}),
+	}
+	if err := memStore.SaveMemory(item); err != nil {
+		t.Fatalf("save memory: %v", err)
+	}
+
+	retriever := newTestRetriever(memStore)
+	resp, err := retriever.Retrieve(context.Background(), "what happens on Monday")
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if resp.TimeRange != nil || len(resp.Results) != 1 {
+		t.Fatalf("expected no time filter by default, got range %v and %d results", resp.TimeRange, len(resp.Results))
+	}
+}
+
+func TestRetrieveFallsBackWhenTemporalFilterMatchesNothing(t *testing.T) {
+	memStore := newTestStore(t)
+	item := &MemoryItem{
+		Text:      "Standups are on Monday at 10",
+		Source:    SourceExplicit,
+		CreatedAt: time.Now().AddDate(0, 0, -30),
+		Provider:  "fake",
+		ModelID:   "fake-embedding",
+		Dim:       2,
+		Embedding: NormalizeVector([]float32{1, 0}),
+	}
+	if err := memStore.SaveMemory(item); err != nil {
+		t.Fatalf("save memory: %v", err)
+	}
+
+	retriever := newTestRetriever(memStore)
+	retriever.config.TemporalFilter = true
+	resp, err := retriever.Retrieve(context.Background(), "what happens on Monday")
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if resp.TimeRange != nil {
+		t.Fatalf("expected the empty time range dropped, got %v", resp.TimeRange)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].Item.ID != item.ID {
+		t.Fatalf("expected the unfiltered results, got %+v", resp.Results)
+	}
+}
@@ -0,0 +1,73 @@
+package retrieval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Candidates are what each search path found for a query before fusion, so
+// that fusion settings can be tried on them without searching again.
+type Candidates struct {
+	Query string
+	// Vector holds the vector search results at any similarity; Fuse drops
+	// those below MinSimilarity.
+	Vector []SearchResult
+	FTS    []MemoryFTSResult
+	// VectorErr and FTSErr are why a search path failed, nil when it did not.
+	VectorErr error
+	FTSErr    error
+
+	ftsQuery  string
+	entityIDs map[string]bool
+}
+
+// SearchCandidates runs both search paths for query as Retrieve does, with no
+// similarity threshold. It fails only when both paths fail.
+func (r *Retriever) SearchCandidates(ctx context.Context, query string) (*Candidates, error) {
+	c := &Candidates{Query: query, ftsQuery: query, entityIDs: r.entityMemoryIDs(query)}
+	var alternates []string
+	if translated := r.translateQuery(ctx, query); translated != "" {
+		c.ftsQuery = query + " " + translated
+		if !IsMultilingualEmbeddingModel(r.embeddingModel.ModelID) {
+			alternates = append(alternates, translated)
+		}
+	}
+
+	if r.embeddingClient == nil {
+		c.VectorErr = errors.New("no embedding model available offline")
+	} else {
+		unfiltered := *r
+		unfiltered.config.MinSimilarity = 0
+		// Memories with malformed embeddings are skipped; the rest still count.
+		var err error
+		if c.Vector, _, err = unfiltered.vectorSearch(ctx, query, MemoryFilter{}, nil, alternates...); !errors.Is(err, ErrMalformedEmbedding) {
+			c.VectorErr = err
+		}
+	}
+
+	r.SearchFTS(ctx, c)
+	if c.VectorErr != nil && c.FTSErr != nil {
+		return nil, fmt.Errorf("retrieval failed: vector: %v, fts: %v", c.VectorErr, c.FTSErr)
+	}
+	return c, nil
+}
+
+// SearchFTS runs full-text search for c's query again with the configured
+// strategy, replacing c's FTS results.
+func (r *Retriever) SearchFTS(ctx context.Context, c *Candidates) {
+	c.FTS, c.FTSErr = r.ftsSearch(ctx, c.ftsQuery, MemoryFilter{})
+}
+
+// Fuse ranks c under the configured MinSimilarity and fusion settings, as
+// Retrieve would, without counting the results as retrieved.
+func (r *Retriever) Fuse(c *Candidates) []UnifiedResult {
+	var vector []SearchResult
+	for _, res := range c.Vector {
+		if res.Similarity >= r.config.MinSimilarity {
+			vector = append(vector, res)
+		}
+	}
+	return r.fuseResults(vector, c.FTS, c.entityIDs, time.Now().UTC())
+}
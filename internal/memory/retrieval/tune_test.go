@@ -0,0 +1,61 @@
+package retrieval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+func TestFuseCandidatesUnderOtherSettings(t *testing.T) {
+	memStore := newTestStore(t)
+	config := utils.DefaultConfig()
+	newRetriever := func(memory utils.MemoryConfig) *Retriever {
+		return NewRetriever(memStore, &fakeEmbeddingClient{}, nil, types.Model{Provider: "fake", ModelID: "fake-embedding"}, types.Model{}, memory)
+	}
+	save := func(text string, embedding []float32) *MemoryItem {
+		item := &MemoryItem{Text: text, Source: SourceExplicit, Provider: "fake", ModelID: "fake-embedding", Dim: 2, Embedding: NormalizeVector(embedding)}
+		if err := memStore.SaveMemory(item); err != nil {
+			t.Fatalf("save memory: %v", err)
+		}
+		return item
+	}
+	exact := save("virtual functions use a vtable", []float32{1, 0})
+	loose := save("virtual dispatch has a cost", []float32{0.6, 0.8})
+
+	candidates, err := newRetriever(config.Memory).SearchCandidates(context.Background(), "virtual functions")
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(candidates.Vector) != 2 || len(candidates.FTS) != 2 {
+		t.Fatalf("expected both memories from both paths regardless of min_similarity, got %d vector and %d FTS results", len(candidates.Vector), len(candidates.FTS))
+	}
+
+	score := func(memory utils.MemoryConfig, id string) (float64, bool) {
+		for _, res := range newRetriever(memory).Fuse(candidates) {
+			if res.Item.ID == id {
+				return res.BaseScore, res.Source == "both"
+			}
+		}
+		return 0, false
+	}
+
+	strict := config.Memory
+	strict.MinSimilarity = 0.9
+	if _, both := score(strict, loose.ID); both {
+		t.Fatal("expected the loose match to lose its vector result above its similarity")
+	}
+	if _, both := score(strict, exact.ID); !both {
+		t.Fatal("expected the exact match from both paths")
+	}
+
+	ftsHeavy, vectorHeavy := config.Memory, config.Memory
+	ftsHeavy.MinSimilarity, ftsHeavy.FusionVectorWeight = 0.5, 0.1
+	vectorHeavy.MinSimilarity, vectorHeavy.FusionVectorWeight = 0.5, 0.9
+	low, _ := score(vectorHeavy, loose.ID)
+	high, _ := score(ftsHeavy, loose.ID)
+	if low >= high {
+		t.Fatalf("expected weighting vector similarity to lower the loose match, got %.3f and %.3f", low, high)
+	}
+}
@@ -0,0 +1,48 @@
+package retrieval
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRetrieveCountsEachReturnedMemory(t *testing.T) {
+	memStore := newTestStore(t)
+	for _, item := range []*MemoryItem{
+		{Text: "C++ virtual functions enable polymorphism", Embedding: NormalizeVector([]float32{1, 0})},
+		{Text: "Rust traits enable polymorphism", Embedding: NormalizeVector([]float32{0.6, 0.8})},
+		{Text: "Sourdough needs a mature starter", Embedding: NormalizeVector([]float32{0, 1}), Pinned: true},
+	} {
+		item.Source = SourceExplicit
+		item.Provider = "fake"
+		item.ModelID = "fake-embedding"
+		item.Dim = 2
+		if err := memStore.SaveMemory(item); err != nil {
+			t.Fatalf("save memory: %v", err)
+		}
+	}
+
+	retriever := newTestRetriever(memStore)
+	for range 2 {
+		if _, err := retriever.Retrieve(context.Background(), "polymorphism"); err != nil {
+			t.Fatalf("retrieve: %v", err)
+		}
+	}
+
+	memories, err := memStore.GetAllMemories()
+	if err != nil {
+		t.Fatalf("get all memories: %v", err)
+	}
+	for _, m := range memories {
+		want := 2
+		if m.Pinned {
+			// Pinned memories ride along without matching the query.
+			want = 0
+		}
+		if m.TimesRetrieved != want {
+			t.Errorf("%q retrieved %d times, want %d", m.Text, m.TimesRetrieved, want)
+		}
+		if (m.LastRetrievedAt != nil) != (want > 0) {
+			t.Errorf("%q last retrieved at %v", m.Text, m.LastRetrievedAt)
+		}
+	}
+}
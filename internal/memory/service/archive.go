@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+type ArchiveInput struct {
+	// AfterDays and BelowConfidence override memory.archive_after_days and
+	// memory.archive_below_confidence when positive.
+	AfterDays       int
+	BelowConfidence float64
+	// DryRun reports what would be archived without moving anything.
+	DryRun bool
+}
+
+type ArchiveResult struct {
+	AfterDays       int
+	BelowConfidence float64
+	Memories        []memtypes.MemoryItem
+	Archived        int
+}
+
+type RestoreInput struct {
+	ID string
+}
+
+type RestoreResult struct {
+	ID       string
+	Restored bool
+}
+
+// Archive moves memories not retrieved for AfterDays days and with confidence
+// below BelowConfidence out of the active set. Archived memories are not
+// searched but are still exported with ExportInput.IncludeArchived.
+func Archive(ctx context.Context, input ArchiveInput) (*ArchiveResult, error) {
+	_ = ctx
+
+	if input.AfterDays < 0 {
+		return nil, fmt.Errorf("days must not be negative, got %d", input.AfterDays)
+	}
+	if input.BelowConfidence < 0 || input.BelowConfidence > 1 {
+		return nil, fmt.Errorf("confidence must be between 0 and 1, got %g", input.BelowConfidence)
+	}
+
+	config, err := utils.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	result := &ArchiveResult{
+		AfterDays:       config.Memory.ArchiveAfterDays,
+		BelowConfidence: config.Memory.ArchiveBelowConfidence,
+	}
+	if input.AfterDays > 0 {
+		result.AfterDays = input.AfterDays
+	}
+	if input.BelowConfidence > 0 {
+		result.BelowConfidence = input.BelowConfidence
+	}
+	if result.AfterDays <= 0 || result.BelowConfidence <= 0 {
+		return nil, fmt.Errorf("no archival policy: set memory.archive_after_days and memory.archive_below_confidence, or pass --days and --below-confidence")
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	now := time.Now()
+	result.Memories, err = memStore.ArchiveCandidates(now.AddDate(0, 0, -result.AfterDays), result.BelowConfidence)
+	if err != nil {
+		return nil, err
+	}
+	if input.DryRun || len(result.Memories) == 0 {
+		return result, nil
+	}
+
+	ids := make([]string, len(result.Memories))
+	for i, m := range result.Memories {
+		ids[i] = m.ID
+	}
+	if result.Archived, err = memStore.ArchiveMemories(ids, now); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Restore moves an archived memory back into the active set.
+func Restore(ctx context.Context, input RestoreInput) (*RestoreResult, error) {
+	_ = ctx
+
+	id := strings.TrimSpace(input.ID)
+	if id == "" {
+		return nil, fmt.Errorf("parameter 'id' must be a non-empty string")
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	restored, err := memStore.RestoreMemory(id)
+	if err != nil {
+		return nil, err
+	}
+	return &RestoreResult{ID: id, Restored: restored}, nil
+}
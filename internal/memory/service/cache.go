@@ -0,0 +1,82 @@
+package service
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// responseCache keeps recent retrieval results, least recently used first
+// out. Entries are valid for one memory revision: a lookup or store at
+// another revision empties the cache.
+type responseCache struct {
+	size int
+	ttl  time.Duration
+
+	mu       sync.Mutex
+	revision int64
+	order    *list.List // of *cacheEntry, most recently used first
+	entries  map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key     string
+	result  RetrieveResult
+	expires time.Time
+}
+
+func newResponseCache(size int, ttl time.Duration) *responseCache {
+	return &responseCache{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// get returns the result cached for key at revision, if it has not expired.
+func (c *responseCache) get(key string, revision int64) (RetrieveResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sync(revision)
+	elem, ok := c.entries[key]
+	if !ok {
+		return RetrieveResult{}, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return RetrieveResult{}, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.result, true
+}
+
+// put caches result for key, computed at revision, evicting the least
+// recently used entry when the cache is full.
+func (c *responseCache) put(key string, revision int64, result RetrieveResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sync(revision)
+	if elem, ok := c.entries[key]; ok {
+		c.order.Remove(elem)
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, result: result, expires: time.Now().Add(c.ttl)})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// sync empties the cache when its entries were computed at another revision.
+func (c *responseCache) sync(revision int64) {
+	if revision != c.revision {
+		c.order.Init()
+		c.entries = make(map[string]*list.Element)
+		c.revision = revision
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/austiecodes/gomor/internal/memory/calibrate"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+type CalibrateInput struct {
+	// Pairs is how many memory pairs to sample; 0 means calibrate.DefaultPairs.
+	Pairs int
+	// Seed makes the sample reproducible; 0 picks a random one.
+	Seed uint64
+}
+
+// Calibrate reports how similar the configured embedding model finds pairs of
+// stored memories, and recommends a min_similarity for it.
+func Calibrate(ctx context.Context, input CalibrateInput) (*calibrate.Report, error) {
+	_ = ctx
+
+	if input.Pairs < 0 {
+		return nil, fmt.Errorf("pairs must not be negative, got %d", input.Pairs)
+	}
+
+	config, err := utils.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if config.Model.EmbeddingModel == nil {
+		return nil, utils.ConfigErrorf("embedding model not configured. Run 'gomor set' to configure")
+	}
+	embeddingModel := *config.Model.EmbeddingModel
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	memories, err := memStore.GetAllMemories()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load memories: %w", err)
+	}
+
+	seed := input.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	rng := rand.New(rand.NewPCG(seed, seed))
+	return calibrate.Run(memories, embeddingModel.Provider, embeddingModel.ModelID, input.Pairs, config.Memory.MinSimilarity, rng)
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// ClearTarget selects which tables a clear operation empties.
+type ClearTarget string
+
+const (
+	ClearMemories ClearTarget = "memories"
+	ClearHistory  ClearTarget = "history"
+	ClearAll      ClearTarget = "all"
+)
+
+type ClearInput struct {
+	Target ClearTarget
+	// DryRun reports what would be removed without backing up or deleting anything.
+	DryRun bool
+}
+
+type ClearResult struct {
+	Target     ClearTarget
+	Memories   int
+	History    int
+	BackupPath string
+	Cleared    bool
+}
+
+// ParseClearTarget validates a clear target name.
+func ParseClearTarget(name string) (ClearTarget, error) {
+	switch target := ClearTarget(name); target {
+	case ClearMemories, ClearHistory, ClearAll:
+		return target, nil
+	}
+	return "", fmt.Errorf("unknown clear target %q (valid: memories, history, all)", name)
+}
+
+// Clear empties memories and/or history. Unless DryRun is set, a timestamped
+// copy of the database is written to the backup directory first, and nothing
+// is deleted if the backup fails.
+func Clear(ctx context.Context, input ClearInput) (*ClearResult, error) {
+	_ = ctx
+
+	target, err := ParseClearTarget(string(input.Target))
+	if err != nil {
+		return nil, err
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	result := &ClearResult{Target: target}
+	if target != ClearHistory {
+		if result.Memories, err = memStore.CountMemories(); err != nil {
+			return nil, err
+		}
+		archived, err := memStore.CountArchivedMemories()
+		if err != nil {
+			return nil, err
+		}
+		result.Memories += archived
+	}
+	if target != ClearMemories {
+		if result.History, err = memStore.CountHistory(); err != nil {
+			return nil, err
+		}
+	}
+	if input.DryRun {
+		return result, nil
+	}
+
+	backupDir, err := utils.GetBackupDir()
+	if err != nil {
+		return nil, err
+	}
+	result.BackupPath = filepath.Join(backupDir, fmt.Sprintf("memory-%s.db", time.Now().Format("20060102-150405.000000")))
+	if err := memStore.Backup(result.BackupPath); err != nil {
+		return nil, err
+	}
+
+	if target != ClearHistory {
+		if err := memStore.ClearMemories(); err != nil {
+			return nil, fmt.Errorf("failed to clear memories: %w", err)
+		}
+		if err := memStore.ClearArchivedMemories(); err != nil {
+			return nil, fmt.Errorf("failed to clear archived memories: %w", err)
+		}
+	}
+	if target != ClearMemories {
+		if err := memStore.ClearHistory(); err != nil {
+			return nil, fmt.Errorf("failed to clear history: %w", err)
+		}
+	}
+	result.Cleared = true
+
+	return result, nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/digest"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+type DigestInput struct {
+	Since time.Time
+	// Send delivers the digest to the configured webhook and email.
+	Send bool
+}
+
+type DigestResult struct {
+	Digest digest.Digest
+	Text   string
+	// Summarized is false when the tool model could not write the digest and
+	// Text is a plain listing instead; SummaryErr says why.
+	Summarized bool
+	SummaryErr error
+	// SentTo names where the digest was delivered.
+	SentTo []string
+}
+
+// Digest reviews the memories added and the conversations held since
+// input.Since. The tool model writes the review; without it, the digest is a
+// plain listing.
+func Digest(ctx context.Context, input DigestInput) (*DigestResult, error) {
+	config, err := utils.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	memories, err := memStore.GetAllMemories()
+	if err != nil {
+		return nil, err
+	}
+	history, err := memStore.HistorySince(input.Since)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DigestResult{Digest: digest.New(input.Since, time.Now(), memories, history)}
+	if result.Digest.Empty() {
+		result.Text = result.Digest.Plain()
+	} else {
+		queryClient, toolModel := buildQueryClient(config)
+		result.Text, result.SummaryErr = digest.Summarize(ctx, queryClient, toolModel, result.Digest)
+		result.Summarized = result.SummaryErr == nil
+		if !result.Summarized {
+			result.Text = result.Digest.Plain()
+		}
+	}
+
+	if input.Send {
+		if err := digest.Send(ctx, config.Digest, result.Digest.Subject(), result.Text); err != nil {
+			return result, err
+		}
+		result.SentTo = digest.Destinations(config.Digest)
+	}
+	return result, nil
+}
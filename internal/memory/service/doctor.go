@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/austiecodes/gomor/internal/memory/embedtext"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/memory/worker"
+	"github.com/austiecodes/gomor/internal/provider"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+type DoctorInput struct {
+	// Fix drops the unusable embeddings and re-embeds their rows.
+	Fix bool
+}
+
+type DoctorResult struct {
+	// Integrity lists what SQLite's integrity check reported; empty when the
+	// database is sound.
+	Integrity []string
+	// Embeddings lists the stored embeddings that cannot be searched.
+	Embeddings []memtypes.EmbeddingProblem
+	// Requeued counts the rows queued for re-embedding by Fix.
+	Requeued int
+	// Reembedded counts the queued rows embedded before returning, including
+	// any queued earlier.
+	Reembedded int
+	// ReembedErr is why the requeued rows could not be embedded right away;
+	// they stay queued for the embedding worker of the next MCP session.
+	ReembedErr error
+	// NeedsReembedding counts the memories flagged, by a search or by this
+	// check, as waiting for a new embedding.
+	NeedsReembedding int
+	// FTSOrphaned counts full-text rows pointing at no memory and FTSMissing
+	// the memories full-text search cannot find. Fix rebuilds the index and
+	// sets FTSRebuilt.
+	FTSOrphaned int
+	FTSMissing  int
+	FTSRebuilt  bool
+	// DimMismatches lists the memories embedded at another dimension than
+	// Dim, which vector search skips. Fix queues them for re-embedding.
+	Dim           int
+	DimMismatches []string
+}
+
+// Doctor checks the memory store for corruption: the SQLite integrity check,
+// a full-text index out of sync with the memories, and embeddings that were
+// truncated, otherwise damaged, or made at another dimension, which search
+// would silently skip. With Fix, the full-text index is rebuilt and the
+// unusable embeddings are queued for re-embedding and embedded with the
+// configured embedding model when there is one.
+func Doctor(ctx context.Context, input DoctorInput) (*DoctorResult, error) {
+	config, err := utils.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	memStore, err := store.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	result := &DoctorResult{}
+	if result.Integrity, err = memStore.IntegrityCheck(); err != nil {
+		return nil, err
+	}
+	if result.Embeddings, err = memStore.CheckEmbeddings(); err != nil {
+		return nil, err
+	}
+	var damaged []string
+	for _, problem := range result.Embeddings {
+		if problem.TargetKind == memtypes.EmbeddingTargetMemory {
+			damaged = append(damaged, problem.TargetID)
+		}
+	}
+	if err := memStore.MarkNeedsReembedding(damaged); err != nil {
+		return nil, err
+	}
+	if result.FTSOrphaned, result.FTSMissing, err = memStore.FTSDrift(); err != nil {
+		return nil, err
+	}
+	dims, err := memStore.EmbeddingDims()
+	if err != nil {
+		return nil, err
+	}
+	if result.Dim, result.DimMismatches, err = dimMismatches(memStore, config, dims); err != nil {
+		return nil, err
+	}
+
+	if input.Fix && (result.FTSOrphaned > 0 || result.FTSMissing > 0) {
+		if err := memStore.RebuildFTS(); err != nil {
+			return result, err
+		}
+		result.FTSRebuilt = true
+	}
+	if input.Fix && (len(result.Embeddings) > 0 || len(result.DimMismatches) > 0) {
+		for _, problem := range result.Embeddings {
+			if err := memStore.RequeueEmbedding(problem.TargetKind, problem.TargetID); err != nil {
+				return result, err
+			}
+			result.Requeued++
+		}
+		for _, id := range result.DimMismatches {
+			if err := memStore.RequeueEmbedding(memtypes.EmbeddingTargetMemory, id); err != nil {
+				return result, err
+			}
+			result.Requeued++
+		}
+		result.Reembedded, result.ReembedErr = drainEmbeddingQueue(ctx, memStore)
+	}
+
+	if result.NeedsReembedding, err = memStore.CountNeedsReembedding(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// drainEmbeddingQueue embeds queued rows with the configured embedding model
+// until the queue is empty or a batch makes no progress, and returns how many
+// rows were embedded.
+func drainEmbeddingQueue(ctx context.Context, memStore *store.Store) (int, error) {
+	config, err := utils.LoadConfig()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load config: %w", err)
+	}
+	if config.Model.EmbeddingModel == nil {
+		return 0, utils.ConfigErrorf("embedding model not configured. Run 'gomor set' to configure")
+	}
+	embeddingModel := *config.Model.EmbeddingModel
+	embClient, err := provider.NewEmbeddingClient(config, embeddingModel.Provider)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create embedding client: %w", err)
+	}
+
+	tmpl, err := embedtext.Parse(config.Memory.EmbeddingTemplate)
+	if err != nil {
+		return 0, err
+	}
+
+	w := worker.NewEmbeddingWorker(memStore, embClient, embeddingModel, tmpl, config.EmbeddingQueue)
+	total := 0
+	for {
+		embedded, err := w.RunOnce(ctx)
+		total += embedded
+		if err != nil {
+			return total, err
+		}
+		if embedded == 0 {
+			break
+		}
+	}
+
+	pending, err := memStore.PendingEmbeddings(1<<30, 1)
+	if err != nil {
+		return total, err
+	}
+	if len(pending) > 0 {
+		return total, errors.New("some rows could not be embedded and stay queued")
+	}
+	return total, nil
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memquery"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/store"
+)
+
+// defaultFindLimit caps Find results when FindInput.Limit is unset.
+const defaultFindLimit = 50
+
+type FindInput struct {
+	// Query uses the memquery syntax, e.g. `tag:golang before:2024-06 "unit test"`.
+	Query string
+	Limit int
+}
+
+type FindResult struct {
+	Query memquery.Query
+	// Matches are ranked by full-text relevance when the query has text
+	// terms, and newest first otherwise.
+	Matches []memtypes.MemoryFTSResult
+}
+
+// Find lists the memories matching an advanced search query. Qualifiers
+// filter the memory base directly; text terms are matched with full-text
+// search. Unlike Retrieve it needs no embedding or tool model.
+func Find(ctx context.Context, input FindInput) (*FindResult, error) {
+	_ = ctx
+
+	query, err := memquery.Parse(input.Query, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultFindLimit
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	result := &FindResult{Query: query}
+	if fts := query.FTS(); fts != "" {
+		// Filters apply after the full-text limit, so search every memory.
+		total, err := memStore.CountMemories()
+		if err != nil {
+			return nil, err
+		}
+		if result.Matches, err = memStore.SearchMemoriesFTSFiltered(fts, total, query.Filter); err != nil {
+			return nil, err
+		}
+	} else {
+		memories, err := memStore.GetAllMemories()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read memories: %w", err)
+		}
+		for _, item := range memories {
+			if query.Filter.Matches(item) {
+				result.Matches = append(result.Matches, memtypes.MemoryFTSResult{Item: item})
+			}
+		}
+		sort.SliceStable(result.Matches, func(i, j int) bool {
+			return result.Matches[i].Item.CreatedAt.After(result.Matches[j].Item.CreatedAt)
+		})
+	}
+
+	if len(result.Matches) > limit {
+		result.Matches = result.Matches[:limit]
+	}
+	return result, nil
+}
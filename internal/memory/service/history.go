@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/moderation"
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+// SaveTurn stores turn in history after screening its content with moderator,
+// which may be nil. Blocked content is stored as moderation's placeholder.
+func SaveTurn(ctx context.Context, memStore *store.Store, moderator *moderation.Moderator, turn *memtypes.HistoryItem) error {
+	verdict, err := moderator.Check(ctx, turn.Content)
+	if err != nil {
+		return fmt.Errorf("failed to moderate turn: %w", err)
+	}
+	if verdict.Blocked() {
+		turn.Content = verdict.Placeholder()
+	}
+	return memStore.SaveHistory(turn)
+}
+
+// RecordInterrupted handles err, the failure of streaming answer. When ctx
+// was cancelled, as by Ctrl-C, after part of the answer arrived, record saves
+// that part as an interrupted turn, with a context that is not cancelled. err
+// is returned either way.
+func RecordInterrupted(ctx context.Context, answer string, err error, record func(context.Context) error) error {
+	if ctx.Err() == nil || answer == "" {
+		return err
+	}
+	if rerr := record(context.WithoutCancel(ctx)); rerr != nil {
+		return errors.Join(err, fmt.Errorf("failed to save the interrupted answer: %w", rerr))
+	}
+	return err
+}
+
+// TurnModel names model the way history records which model answered a turn,
+// or returns "" when model is unset.
+func TurnModel(model types.Model) string {
+	if model.ModelID == "" {
+		return ""
+	}
+	return model.Provider + "/" + model.ModelID
+}
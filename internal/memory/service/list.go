@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/memory/tagging"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+const (
+	// defaultListLimit is the page size when ListInput.Limit is unset.
+	defaultListLimit = 50
+	// maxListLimit bounds the page size.
+	maxListLimit = 500
+)
+
+type ListInput struct {
+	// Tag lists only the memories carrying it, or one of its aliases; empty
+	// lists every memory.
+	Tag    string
+	Limit  int
+	Offset int
+}
+
+type ListResult struct {
+	// Memories are newest first.
+	Memories []memtypes.MemoryItem
+	// Total counts the memories on every page.
+	Total int
+	// NextOffset is the offset of the next page, 0 on the last one.
+	NextOffset int
+}
+
+// List returns a page of the stored memories, newest first, without the
+// chunks of long memories.
+func List(ctx context.Context, input ListInput) (*ListResult, error) {
+	_ = ctx
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		return nil, fmt.Errorf("parameter 'limit' may be at most %d, got %d", maxListLimit, limit)
+	}
+	if input.Offset < 0 {
+		return nil, fmt.Errorf("parameter 'offset' must not be negative, got %d", input.Offset)
+	}
+
+	var tags []string
+	if tag := strings.TrimSpace(input.Tag); tag != "" {
+		config, err := utils.LoadConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+		// Memories saved before an alias was configured still carry the alias.
+		tags = tagging.Expand([]string{tag}, config.Memory.TagAliases)
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	memories, total, err := memStore.ListMemories(tags, limit, input.Offset)
+	if err != nil {
+		return nil, err
+	}
+	result := &ListResult{Memories: memories, Total: total}
+	if next := input.Offset + len(memories); len(memories) > 0 && next < total {
+		result.NextOffset = next
+	}
+	return result, nil
+}
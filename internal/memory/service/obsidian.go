@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/memory/embedtext"
+	"github.com/austiecodes/gomor/internal/memory/obsidian"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/provider"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+type SyncObsidianInput struct {
+	// VaultPath overrides the configured vault.
+	VaultPath string
+	// ReadBack applies edits made to notes in the vault before mirroring.
+	ReadBack bool
+}
+
+type SyncObsidianResult struct {
+	obsidian.Result
+}
+
+// SyncObsidian mirrors every memory into the configured Obsidian vault folder.
+func SyncObsidian(ctx context.Context, input SyncObsidianInput) (*SyncObsidianResult, error) {
+	config, err := utils.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	dir, err := obsidianDir(config, input.VaultPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only edited notes need re-embedding, so a plain mirror works without an embedding model.
+	var embClient client.EmbeddingClient
+	var embeddingModel types.Model
+	if input.ReadBack {
+		if config.Model.EmbeddingModel == nil {
+			return nil, utils.ConfigErrorf("embedding model not configured. Run 'gomor set' to configure")
+		}
+		embeddingModel = *config.Model.EmbeddingModel
+		embClient, err = provider.NewEmbeddingClient(config, embeddingModel.Provider)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create embedding client: %w", err)
+		}
+	}
+
+	tmpl, err := embedtext.Parse(config.Memory.EmbeddingTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	result, err := obsidian.NewSyncer(memStore, embClient, embeddingModel, tmpl, dir).Sync(ctx, input.ReadBack)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sync obsidian vault: %w", err)
+	}
+
+	return &SyncObsidianResult{Result: result}, nil
+}
+
+// obsidianDir resolves the folder notes are written to, expanding a leading "~".
+func obsidianDir(config *utils.Config, vaultPath string) (string, error) {
+	vault := strings.TrimSpace(vaultPath)
+	if vault == "" {
+		vault = config.Obsidian.VaultPath
+	}
+	if vault == "" {
+		return "", utils.ConfigErrorf("obsidian vault not configured. Set obsidian.vault_path in the config or pass --vault")
+	}
+	vault, err := utils.ExpandHome(vault)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(vault); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("obsidian vault %s is not a directory", vault)
+	}
+	return filepath.Join(vault, config.Obsidian.Folder), nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/store"
+)
+
+type RelateInput struct {
+	// FromID supersedes, relates to, or is derived from ToID.
+	FromID string
+	ToID   string
+	Kind   memtypes.RelationKind
+	// Remove deletes the relation instead of adding it.
+	Remove bool
+}
+
+type RelateResult struct {
+	Relation memtypes.MemoryRelation
+	Removed  bool
+	// Found reports, when removing, whether the relation existed.
+	Found bool
+}
+
+// Relate adds or removes a relation between two memories. A superseded
+// memory is suppressed so that retrieval returns only the memory replacing
+// it; removing the relation leaves it suppressed.
+func Relate(ctx context.Context, input RelateInput) (*RelateResult, error) {
+	_ = ctx
+
+	fromID, toID := strings.TrimSpace(input.FromID), strings.TrimSpace(input.ToID)
+	if fromID == "" || toID == "" {
+		return nil, fmt.Errorf("both memory ids must be non-empty strings")
+	}
+	kind, err := memtypes.ParseRelationKind(string(input.Kind))
+	if err != nil {
+		return nil, err
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	result := &RelateResult{
+		Relation: memtypes.MemoryRelation{FromID: fromID, ToID: toID, Kind: kind},
+		Removed:  input.Remove,
+	}
+	if input.Remove {
+		result.Found, err = memStore.RemoveRelation(fromID, toID, kind)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	if kind == memtypes.RelationSupersedes {
+		err = supersede(memStore, fromID, toID)
+	} else {
+		err = memStore.AddRelation(fromID, toID, kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Relations returns the relations from and to a memory, oldest first.
+func Relations(ctx context.Context, id string) ([]memtypes.MemoryRelation, error) {
+	_ = ctx
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	return memStore.MemoryRelations(id)
+}
+
+// supersede records that newID replaces oldID and suppresses oldID.
+func supersede(memStore *store.Store, newID, oldID string) error {
+	if err := memStore.AddRelation(newID, oldID, memtypes.RelationSupersedes); err != nil {
+		return err
+	}
+	if _, err := memStore.SetMemorySuppressed(oldID, true); err != nil {
+		return err
+	}
+	return nil
+}
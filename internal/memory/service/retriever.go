@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/memory/memutils"
+	"github.com/austiecodes/gomor/internal/memory/retrieval"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/provider"
+	"github.com/austiecodes/gomor/internal/tokenizer"
+	"github.com/austiecodes/gomor/internal/trace"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// Retriever serves retrieval for long-running server modes. It keeps the
+// memory store open and reuses its provider clients, and with them their HTTP
+// connections, across calls. It is safe for concurrent use.
+type Retriever struct {
+	store *store.Store
+
+	mu          sync.RWMutex
+	config      *utils.Config
+	clientKey   string
+	embClient   client.EmbeddingClient
+	queryClient client.QueryClient
+	toolModel   types.Model
+	retriever   *retrieval.Retriever
+
+	// cache holds recent responses, nil when memory.cache_size turns it off;
+	// cacheConfig identifies the settings they were retrieved with.
+	cache       *responseCache
+	cacheConfig string
+}
+
+// NewRetriever opens the memory store and builds the clients config names.
+func NewRetriever(config *utils.Config) (*Retriever, error) {
+	memStore, err := store.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
+	}
+
+	r := &Retriever{store: memStore}
+	if err := r.Refresh(config); err != nil {
+		memStore.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Refresh switches to config. Provider clients are rebuilt only when the
+// providers or models they are built from changed.
+func (r *Retriever) Refresh(config *utils.Config) error {
+	if config.Model.EmbeddingModel == nil {
+		return utils.ConfigErrorf("embedding model not configured. Run 'gomor set' to configure")
+	}
+	embeddingModel := *config.Model.EmbeddingModel
+	key := clientKey(config)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if key != r.clientKey {
+		// Offline, the retriever runs without an embedding client and
+		// searches with FTS only.
+		embClient, err := provider.NewEmbeddingClient(config, embeddingModel.Provider)
+		if err != nil && !errors.Is(err, provider.ErrOffline) {
+			return fmt.Errorf("failed to create embedding client: %w", err)
+		}
+		r.embClient = embClient
+		r.queryClient, r.toolModel = buildQueryClient(config)
+		r.clientKey = key
+	}
+
+	r.config = config
+	r.retriever = retrieval.NewRetriever(r.store, r.embClient, r.queryClient, embeddingModel, r.toolModel, config.Memory)
+
+	size, ttl := config.Memory.CacheSize, time.Duration(config.Memory.CacheTTLSecs)*time.Second
+	switch {
+	case size <= 0:
+		r.cache = nil
+	case r.cache == nil || r.cache.size != size || r.cache.ttl != ttl:
+		r.cache = newResponseCache(size, ttl)
+	}
+	r.cacheConfig = cacheConfig(config)
+	return nil
+}
+
+// cacheConfig hashes the settings a retrieval response depends on, so that
+// responses cached under other settings are not reused.
+func cacheConfig(config *utils.Config) string {
+	data, _ := json.Marshal(struct {
+		Memory         utils.MemoryConfig
+		EmbeddingModel *types.Model
+		ToolModel      *types.Model
+		ChatModel      *types.Model
+		Offline        bool
+	}{config.Memory, config.Model.EmbeddingModel, config.Model.ToolModel, config.Model.ChatModel, config.Offline})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// responseKey is the cache key of a retrieval of input under the settings
+// hashed as configHash. Queries differing only in case and spacing share it.
+func responseKey(configHash string, input RetrieveInput) string {
+	data, _ := json.Marshal(struct {
+		Config             string
+		Query              string
+		Strict             *bool
+		ExcludeTags        []string
+		FTSStrategy        string
+		FusionVectorWeight float64
+		Command            string
+	}{configHash, memutils.NormalizeText(input.Query), input.Strict, input.ExcludeTags, input.FTSStrategy, input.FusionVectorWeight, input.Command})
+	return string(data)
+}
+
+// clientKey identifies the settings the provider clients are built from.
+func clientKey(config *utils.Config) string {
+	data, _ := json.Marshal(struct {
+		Providers      utils.ProviderConfigs
+		EmbeddingModel *types.Model
+		ToolModel      *types.Model
+		Fallbacks      map[string][]string
+		Offline        bool
+	}{config.Providers, config.Model.EmbeddingModel, config.Model.ToolModel, config.Model.Fallbacks, config.Offline})
+	return string(data)
+}
+
+// Retrieve searches memories for input.Query with the current config.
+func (r *Retriever) Retrieve(ctx context.Context, input RetrieveInput) (*RetrieveResult, error) {
+	query := strings.TrimSpace(input.Query)
+	if query == "" {
+		return nil, fmt.Errorf("parameter 'query' must be a non-empty string")
+	}
+	if err := checkOverrides(input.FTSStrategy, input.FusionVectorWeight); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	config, ret, cache, configHash := r.config, r.retriever, r.cache, r.cacheConfig
+	r.mu.RUnlock()
+
+	history := input.History
+	if len(history) == 0 && input.SessionID != "" {
+		var err error
+		history, err = r.store.GetConversation(input.SessionID, config.Memory.RewriteHistoryTurns)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load session history: %w", err)
+		}
+	}
+
+	// Follow-up queries are rewritten from the conversation, so only
+	// standalone queries are cached.
+	start := time.Now()
+	var key string
+	var revision int64
+	if cache != nil && len(history) == 0 {
+		if rev, err := r.store.MemoryRevision(); err == nil {
+			key, revision = responseKey(configHash, input), rev
+			if result, ok := cache.get(key, revision); ok {
+				trace.Printf(trace.Info, "retrieval: cached response for %q in %s", query, trace.Since(start))
+				// Counting retrievals leaves the revision alone, so the
+				// response stays cached.
+				ret.RecordRetrieval(result.Response.Results)
+				return &result, nil
+			}
+		}
+	}
+
+	response, err := ret.RetrieveWithOptions(ctx, query, retrieval.RetrieveOptions{
+		History:            history,
+		Strict:             input.Strict,
+		ExcludeTags:        input.ExcludeTags,
+		FTSStrategy:        input.FTSStrategy,
+		FusionVectorWeight: input.FusionVectorWeight,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("retrieval failed: %w", err)
+	}
+
+	fit(ctx, config, ret, response, input.Command, config.Memory.MaxInjectedTokens)
+	traceRetrieval(response, start)
+
+	result := &RetrieveResult{
+		Response: response,
+		Text:     retrieval.FormatAsText(response),
+	}
+	// Degraded responses are not cached, so the next call retries the
+	// search that failed.
+	if key != "" && len(response.Warnings) == 0 {
+		cache.put(key, revision, *result)
+	}
+	return result, nil
+}
+
+// checkOverrides validates the retrieval settings a call overrides.
+func checkOverrides(ftsStrategy string, fusionVectorWeight float64) error {
+	if ftsStrategy != "" {
+		if err := utils.CheckFTSStrategy(ftsStrategy); err != nil {
+			return err
+		}
+	}
+	if fusionVectorWeight < 0 || fusionVectorWeight >= 1 {
+		return fmt.Errorf("fusion vector weight must be between 0 and 1, got %g", fusionVectorWeight)
+	}
+	return nil
+}
+
+// maxBatchQueries bounds the queries of one RetrieveAll call.
+const maxBatchQueries = 10
+
+// RetrieveAll searches memories for each of input.Queries, reading the
+// stored memories once for all of them. Each memory is returned once, for the
+// query it is most relevant to. The queries share the injected token budget.
+func (r *Retriever) RetrieveAll(ctx context.Context, input RetrieveBatchInput) (*RetrieveBatchResult, error) {
+	var queries []string
+	for _, query := range input.Queries {
+		if query = strings.TrimSpace(query); query != "" {
+			queries = append(queries, query)
+		}
+	}
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("parameter 'queries' must contain a non-empty query")
+	}
+	if len(queries) > maxBatchQueries {
+		return nil, fmt.Errorf("parameter 'queries' may contain at most %d queries, got %d", maxBatchQueries, len(queries))
+	}
+	if err := checkOverrides(input.FTSStrategy, input.FusionVectorWeight); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	config, ret := r.config, r.retriever
+	r.mu.RUnlock()
+
+	history := input.History
+	if len(history) == 0 && input.SessionID != "" {
+		var err error
+		history, err = r.store.GetConversation(input.SessionID, config.Memory.RewriteHistoryTurns)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load session history: %w", err)
+		}
+	}
+
+	start := time.Now()
+	responses, err := ret.RetrieveAll(ctx, queries, retrieval.RetrieveOptions{
+		History:            history,
+		Strict:             input.Strict,
+		ExcludeTags:        input.ExcludeTags,
+		FTSStrategy:        input.FTSStrategy,
+		FusionVectorWeight: input.FusionVectorWeight,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("retrieval failed: %w", err)
+	}
+
+	budget := config.Memory.MaxInjectedTokens / len(responses)
+	result := &RetrieveBatchResult{Results: make([]RetrieveResult, len(responses))}
+	for i, response := range responses {
+		fit(ctx, config, ret, response, input.Command, budget)
+		traceRetrieval(response, start)
+		result.Results[i] = RetrieveResult{Response: response, Text: retrieval.FormatAsText(response)}
+	}
+	return result, nil
+}
+
+// fit brings response within maxTokens, summarizing it when memory
+// compression is configured for command and dropping the lowest-ranked
+// memories otherwise.
+func fit(ctx context.Context, config *utils.Config, ret *retrieval.Retriever, response *retrieval.RetrievalResponse, command string, maxTokens int) {
+	var chatModel types.Model
+	if config.Model.ChatModel != nil {
+		chatModel = *config.Model.ChatModel
+	}
+	counter := tokenizer.ForModel(chatModel)
+	if config.Memory.Compresses(command) {
+		// Truncating is the fallback, so a failed summary still answers.
+		if err := ret.Compress(ctx, response, counter, maxTokens); err != nil {
+			response.Warnings = append(response.Warnings, fmt.Sprintf("compression failed, dropped lower-ranked memories instead: %v", err))
+		}
+	}
+	if response.Summary == "" {
+		retrieval.FitTokens(response, counter, maxTokens)
+	}
+}
+
+// traceRetrieval reports how long retrieval took and what it found.
+func traceRetrieval(response *retrieval.RetrievalResponse, start time.Time) {
+	trace.Printf(trace.Info, "retrieval: %d memories for %q in %s", len(response.Results), response.Query, trace.Since(start))
+	if response.Class != "" {
+		trace.Printf(trace.Info, "retrieval: classified as %s", response.Class)
+	}
+	if response.RewrittenQuery != "" {
+		trace.Printf(trace.Info, "retrieval: rewritten to %q", response.RewrittenQuery)
+	}
+	if response.Omitted > 0 {
+		trace.Printf(trace.Info, "retrieval: %d memories omitted to fit the token budget", response.Omitted)
+	}
+	if response.Summary != "" {
+		trace.Printf(trace.Info, "retrieval: %d memories summarized to fit the token budget", len(response.Results))
+	}
+	for _, w := range response.Warnings {
+		trace.Printf(trace.Info, "retrieval: degraded: %s", w)
+	}
+	for _, r := range response.Results {
+		trace.Printf(trace.Debug, "retrieval: %.3f %s [%s] %s", r.Score, r.Item.ID, r.Source, r.Item.Text)
+	}
+}
+
+// Close closes the memory store.
+func (r *Retriever) Close() error {
+	return r.store.Close()
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/consts"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	localprov "github.com/austiecodes/gomor/internal/provider/local"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+func localConfig() *utils.Config {
+	config := utils.DefaultConfig()
+	config.Model.EmbeddingModel = &types.Model{Provider: consts.ProviderLocal, ModelID: localprov.ModelNgramHash512}
+	config.Memory.MinSimilarity = 0.1
+	return config
+}
+
+func TestRetrieverRefreshReusesClients(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	r, err := NewRetriever(localConfig())
+	if err != nil {
+		t.Fatalf("NewRetriever: %v", err)
+	}
+	defer r.Close()
+
+	first := r.embClient
+	config := localConfig()
+	config.Memory.MemoryTopK = 3
+	if err := r.Refresh(config); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if r.embClient != first {
+		t.Fatal("expected a memory-only change to keep the embedding client")
+	}
+
+	key := r.clientKey
+	config = localConfig()
+	config.Offline = true
+	if err := r.Refresh(config); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if r.clientKey == key || r.config != config {
+		t.Fatal("expected going offline to rebuild the clients")
+	}
+}
+
+func TestRetrieverRetrieve(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	r, err := NewRetriever(localConfig())
+	if err != nil {
+		t.Fatalf("NewRetriever: %v", err)
+	}
+	defer r.Close()
+
+	item := memtypes.MemoryItem{Text: "The deploy pipeline runs on Argo", Source: memtypes.SourceExplicit}
+	if err := r.store.SaveMemory(&item); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		result, err := r.Retrieve(context.Background(), RetrieveInput{Query: "deploy pipeline"})
+		if err != nil {
+			t.Fatalf("Retrieve: %v", err)
+		}
+		if len(result.Response.Results) != 1 || result.Response.Results[0].Item.ID != item.ID {
+			t.Fatalf("expected the saved memory, got %+v", result.Response.Results)
+		}
+	}
+
+	if _, err := r.Retrieve(context.Background(), RetrieveInput{Query: "  "}); err == nil {
+		t.Fatal("expected an empty query to fail")
+	}
+}
+
+func TestRetrieverCachesUntilMemoriesChange(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	r, err := NewRetriever(localConfig())
+	if err != nil {
+		t.Fatalf("NewRetriever: %v", err)
+	}
+	defer r.Close()
+
+	first := memtypes.MemoryItem{Text: "The deploy pipeline runs on Argo", Source: memtypes.SourceExplicit}
+	if err := r.store.SaveMemory(&first); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	ctx := context.Background()
+	result, err := r.Retrieve(ctx, RetrieveInput{Query: "deploy pipeline"})
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	cached, err := r.Retrieve(ctx, RetrieveInput{Query: "  Deploy   Pipeline "})
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if cached.Response != result.Response {
+		t.Fatal("expected a repeated query to be answered from the cache")
+	}
+	stored, err := r.store.GetMemory(first.ID)
+	if err != nil {
+		t.Fatalf("get memory: %v", err)
+	}
+	if stored.TimesRetrieved != 2 || stored.LastRetrievedAt == nil {
+		t.Fatalf("expected the cached response counted as a retrieval, got %d retrievals", stored.TimesRetrieved)
+	}
+	if other, err := r.Retrieve(ctx, RetrieveInput{Query: "deploy pipeline", Command: "query"}); err != nil || other.Response == result.Response {
+		t.Fatalf("expected other options to miss the cache (%v)", err)
+	}
+
+	second := memtypes.MemoryItem{Text: "The deploy pipeline needs approval from ops", Source: memtypes.SourceExplicit}
+	if err := r.store.SaveMemory(&second); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	fresh, err := r.Retrieve(ctx, RetrieveInput{Query: "deploy pipeline"})
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if fresh.Response == result.Response || len(fresh.Response.Results) != 2 {
+		t.Fatalf("expected saving a memory to invalidate the cache, got %+v", fresh.Response.Results)
+	}
+
+	config := localConfig()
+	config.Memory.CacheSize = -1
+	if err := r.Refresh(config); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if r.cache != nil {
+		t.Fatal("expected a negative cache_size to turn the cache off")
+	}
+}
+
+func TestResponseCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newResponseCache(2, time.Minute)
+	cache.put("a", 1, RetrieveResult{Text: "a"})
+	cache.put("b", 1, RetrieveResult{Text: "b"})
+	if _, ok := cache.get("a", 1); !ok {
+		t.Fatal("expected a to be cached")
+	}
+	cache.put("c", 1, RetrieveResult{Text: "c"})
+	if _, ok := cache.get("b", 1); ok {
+		t.Fatal("expected the least recently used entry to be evicted")
+	}
+	if _, ok := cache.get("a", 2); ok {
+		t.Fatal("expected a new revision to empty the cache")
+	}
+
+	expiring := newResponseCache(2, -time.Second)
+	expiring.put("a", 1, RetrieveResult{Text: "a"})
+	if _, ok := expiring.get("a", 1); ok {
+		t.Fatal("expected an expired entry to be dropped")
+	}
+}
@@ -2,15 +2,29 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"os"
+	"slices"
 	"strings"
 
 	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/interop"
+	"github.com/austiecodes/gomor/internal/memory/embedtext"
+	"github.com/austiecodes/gomor/internal/memory/entities"
+	"github.com/austiecodes/gomor/internal/memory/graph"
+	"github.com/austiecodes/gomor/internal/memory/importer"
+	"github.com/austiecodes/gomor/internal/memory/ingest"
 	"github.com/austiecodes/gomor/internal/memory/memtypes"
 	"github.com/austiecodes/gomor/internal/memory/memutils"
+	"github.com/austiecodes/gomor/internal/memory/obsidian"
 	"github.com/austiecodes/gomor/internal/memory/retrieval"
 	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/memory/tagging"
+	"github.com/austiecodes/gomor/internal/moderation"
 	"github.com/austiecodes/gomor/internal/provider"
+	"github.com/austiecodes/gomor/internal/tokenizer"
 	"github.com/austiecodes/gomor/internal/types"
 	"github.com/austiecodes/gomor/internal/utils"
 )
@@ -19,14 +33,52 @@ type SaveInput struct {
 	Text   string
 	Tags   []string
 	Source memtypes.MemorySource
+	// Kind classifies the memory; empty means fact.
+	Kind memtypes.MemoryKind
+	// Deferred stores the memory without embedding it and queues the embedding
+	// for the background worker.
+	Deferred bool
+	// Pinned memories are returned by every retrieval, within the token budget.
+	Pinned bool
+	// AutoTag overrides the configured auto_tag setting when set. Auto-tagging
+	// asks the tool model for tags when Tags is empty; it is best-effort, so
+	// the memory is saved untagged if it fails.
+	AutoTag *bool
+	// Supersedes lists memories the new one replaces, such as facts it
+	// contradicts. They are related to it and suppressed.
+	Supersedes []string
 }
 
 type SaveResult struct {
 	Item memtypes.MemoryItem
+	// Pending reports that the embedding was queued instead of computed inline.
+	Pending bool
+	// PendingErr holds the embedding error that caused the memory to be queued, if any.
+	PendingErr error
+	// AutoTagged reports that the memory's tags were proposed by the tool model.
+	AutoTagged bool
 }
 
 type RetrieveInput struct {
 	Query string
+	// SessionID selects the conversation whose recent turns are used to
+	// rewrite follow-up queries. Ignored when History is set.
+	SessionID string
+	// History holds recent conversation turns, oldest first.
+	History []memtypes.HistoryItem
+	// Strict overrides the configured strict_retrieval setting when set.
+	Strict *bool
+	// ExcludeTags leaves out memories carrying any of these tags.
+	ExcludeTags []string
+	// FTSStrategy overrides memory.fts_strategy for this retrieval when set.
+	FTSStrategy string
+	// FusionVectorWeight overrides memory.fusion_vector_weight for this
+	// retrieval when non-zero; it must be between 0 and 1.
+	FusionVectorWeight float64
+	// Command names the command retrieving, e.g. "query", to look up in
+	// memory.compress_commands whether results over the token budget are
+	// summarized instead of truncated.
+	Command string
 }
 
 type RetrieveResult struct {
@@ -34,6 +86,69 @@ type RetrieveResult struct {
 	Text     string
 }
 
+// RetrieveBatchInput asks for the memories relevant to each of Queries at
+// once. The other fields apply to every query as in RetrieveInput.
+type RetrieveBatchInput struct {
+	Queries            []string
+	SessionID          string
+	History            []memtypes.HistoryItem
+	Strict             *bool
+	ExcludeTags        []string
+	FTSStrategy        string
+	FusionVectorWeight float64
+	Command            string
+}
+
+// RetrieveBatchResult holds one result per query, in the order of the queries.
+type RetrieveBatchResult struct {
+	Results []RetrieveResult
+}
+
+type EntityInput struct {
+	Name string
+}
+
+type EntityResult struct {
+	Name     string
+	Memories []memtypes.MemoryItem
+}
+
+type IngestInput struct {
+	Paths []string
+	Tags  []string
+}
+
+type IngestResult struct {
+	Files  []ingest.FileResult
+	Chunks int
+}
+
+type ImportInput struct {
+	Format importer.Format
+	Path   string
+	Tags   []string
+}
+
+type ImportResult struct {
+	Parsed int
+	importer.Result
+}
+
+type ExportInput struct {
+	Format importer.Format
+	// Path is the file to write; empty returns the data without writing it.
+	Path string
+	// IncludeArchived adds the memories moved out of search by Archive.
+	IncludeArchived bool
+}
+
+type ExportResult struct {
+	Format   importer.Format
+	Path     string
+	Memories int
+	Data     []byte
+}
+
 type DeleteInput struct {
 	ID string
 }
@@ -43,29 +158,116 @@ type DeleteResult struct {
 	Deleted bool
 }
 
+type UpdateInput struct {
+	ID string
+	// Text replaces the memory's text; empty keeps it.
+	Text string
+	// Tags replace the memory's tags unless nil; empty clears them.
+	Tags []string
+}
+
+type UpdateResult struct {
+	ID   string
+	Text string
+	Tags []string
+	// Updated is false when neither the text nor the tags changed.
+	Updated bool
+	// Pending reports that the new embedding was queued.
+	Pending bool
+}
+
+type PinInput struct {
+	ID     string
+	Pinned bool // false unpins the memory
+}
+
+type PinResult struct {
+	ID     string
+	Pinned bool
+	Found  bool
+}
+
+type SuppressInput struct {
+	ID         string
+	Suppressed bool // false lets the memory be retrieved again
+}
+
+type SuppressResult struct {
+	ID         string
+	Suppressed bool
+	Found      bool
+}
+
+type FeedbackInput struct {
+	ID     string
+	Useful bool // false marks the memory as wrong
+}
+
+type FeedbackResult = memtypes.MemoryFeedback
+
+// ReviewDecision is what a reviewer decided about a memory pending review.
+type ReviewDecision string
+
+const (
+	ReviewApprove ReviewDecision = "approve"
+	ReviewReject  ReviewDecision = "reject"
+)
+
+type ReviewInput struct {
+	ID       string
+	Decision ReviewDecision
+	// Text replaces the memory's text before it is approved; empty keeps it.
+	Text string
+}
+
+type ReviewResult struct {
+	ID       string
+	Decision ReviewDecision
+	Edited   bool
+	// Found is false when no memory with ID is pending review.
+	Found bool
+	// Pending reports that the edited text's embedding was queued.
+	Pending bool
+}
+
+type GraphInput struct {
+	// Threshold is the minimum cosine similarity for an edge.
+	Threshold         float64
+	IncludeSuppressed bool
+}
+
 func Save(ctx context.Context, input SaveInput) (*SaveResult, error) {
 	text := strings.TrimSpace(input.Text)
 	if text == "" {
 		return nil, fmt.Errorf("parameter 'text' must be a non-empty string")
 	}
+	kind, err := memtypes.ParseMemoryKind(string(input.Kind))
+	if err != nil {
+		return nil, err
+	}
 
 	config, err := utils.LoadConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 	if config.Model.EmbeddingModel == nil {
-		return nil, fmt.Errorf("embedding model not configured. Run 'gomor set' to configure")
+		return nil, utils.ConfigErrorf("embedding model not configured. Run 'gomor set' to configure")
+	}
+	if input.Source == memtypes.SourceExtracted {
+		if err := moderateExtracted(ctx, config, text); err != nil {
+			return nil, err
+		}
 	}
 
 	embeddingModel := *config.Model.EmbeddingModel
-	embClient, err := provider.NewEmbeddingClient(config, embeddingModel.Provider)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create embedding client: %w", err)
+	embClient, clientErr := provider.NewEmbeddingClient(config, embeddingModel.Provider)
+	if clientErr != nil && !errors.Is(clientErr, provider.ErrOffline) {
+		return nil, fmt.Errorf("failed to create embedding client: %w", clientErr)
 	}
 
-	embedding, err := embClient.Embed(ctx, embeddingModel, text)
+	tmpl, err := embedtext.Parse(config.Memory.EmbeddingTemplate)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate embedding: %w", err)
+		return nil, err
 	}
 
 	memStore, err := store.NewStore()
@@ -74,40 +276,187 @@ func Save(ctx context.Context, input SaveInput) (*SaveResult, error) {
 	}
 	defer memStore.Close()
 
+	for _, id := range input.Supersedes {
+		if err := memStore.CheckMemoryExists(id); err != nil {
+			return nil, err
+		}
+	}
+
 	source := input.Source
 	if source == "" {
 		source = memtypes.SourceExplicit
 	}
 
+	tags := input.Tags
+	autoTagged := false
+	autoTag := config.Memory.AutoTag
+	if input.AutoTag != nil {
+		autoTag = *input.AutoTag
+	}
+	if autoTag && len(tags) == 0 {
+		tags = proposeTags(ctx, config, memStore, text)
+		autoTagged = len(tags) > 0
+	}
+	tags = tagging.Canonicalize(tags, config.Memory.TagAliases, config.Memory.TagVocabulary)
+
 	item := memtypes.MemoryItem{
-		Text:      text,
-		Tags:      input.Tags,
-		Source:    source,
-		Provider:  embeddingModel.Provider,
-		ModelID:   embeddingModel.ModelID,
-		Dim:       len(embedding),
-		Embedding: memutils.NormalizeVector(embedding),
+		Text:   text,
+		Tags:   tags,
+		Source: source,
+		Kind:   kind,
+		Pinned: input.Pinned,
+		// Extracted memories wait for a human to approve them before retrieval.
+		PendingReview: source == memtypes.SourceExtracted && !config.Memory.AutoApproveExtracted,
+		Provider:      embeddingModel.Provider,
+		ModelID:       embeddingModel.ModelID,
+	}
+
+	// Embedding failures don't lose the memory: it is stored for FTS and the
+	// embedding is queued for the background worker. Offline saves are queued
+	// the same way and embedded once back online.
+	var embedding []float32
+	var embedErr error
+	pending := input.Deferred
+	switch {
+	case pending:
+	case embClient == nil:
+		pending, embedErr = true, clientErr
+	default:
+		embedding, embedErr = embClient.Embed(ctx, embeddingModel, tmpl.Render(item))
+		if embedErr != nil {
+			embedding = nil
+			pending = true
+		}
 	}
+	item.Embedding = memutils.NormalizeVector(embedding)
+	item.Dim = len(embedding)
 
 	if err := memStore.SaveMemory(&item); err != nil {
 		return nil, fmt.Errorf("failed to save memory: %w", err)
 	}
+	if pending {
+		if err := memStore.EnqueueEmbedding(memtypes.EmbeddingTargetMemory, item.ID); err != nil {
+			return nil, err
+		}
+	}
+	if err := saveChunks(ctx, memStore, embClient, embeddingModel, tmpl, item, config.Memory.ChunkTokens, pending); err != nil {
+		return nil, err
+	}
+	for _, id := range input.Supersedes {
+		if err := supersede(memStore, item.ID, id); err != nil {
+			return nil, err
+		}
+	}
+
+	// Entity linking is best-effort: a failed extraction never fails the save.
+	if config.Memory.EntityLinking {
+		if queryClient, toolModel := buildQueryClient(config); queryClient != nil {
+			if found, err := entities.Extract(ctx, queryClient, toolModel, text); err == nil {
+				_ = entities.Link(memStore, item.ID, found)
+			}
+		}
+	}
 
-	return &SaveResult{Item: item}, nil
+	// Mirroring to the vault is best-effort too; `gomor sync obsidian` catches up.
+	if config.Obsidian.AutoSync {
+		if dir, err := obsidianDir(config, ""); err == nil {
+			_ = obsidian.WriteNote(dir, item)
+		}
+	}
+
+	return &SaveResult{Item: item, Pending: pending, PendingErr: embedErr, AutoTagged: autoTagged}, nil
 }
 
 func Retrieve(ctx context.Context, input RetrieveInput) (*RetrieveResult, error) {
-	query := strings.TrimSpace(input.Query)
-	if query == "" {
+	if strings.TrimSpace(input.Query) == "" {
 		return nil, fmt.Errorf("parameter 'query' must be a non-empty string")
 	}
 
+	config, err := utils.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	r, err := NewRetriever(config)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return r.Retrieve(ctx, input)
+}
+
+// RetrieveAll retrieves memories for several queries at once; see
+// Retriever.RetrieveAll.
+func RetrieveAll(ctx context.Context, input RetrieveBatchInput) (*RetrieveBatchResult, error) {
+	config, err := utils.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	r, err := NewRetriever(config)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return r.RetrieveAll(ctx, input)
+}
+
+// Entity returns every memory linked to the named entity ("project Atlas" -> "Atlas").
+func Entity(ctx context.Context, input EntityInput) (*EntityResult, error) {
+	_ = ctx
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("parameter 'name' must be a non-empty string")
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	memories, err := memStore.GetMemoriesByEntity(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load entity memories: %w", err)
+	}
+
+	return &EntityResult{Name: name, Memories: memories}, nil
+}
+
+// Ingest chunks and embeds the supported files under paths as document memories.
+// Per-file failures are reported in the result instead of aborting the run.
+func Ingest(ctx context.Context, input IngestInput) (*IngestResult, error) {
+	if len(input.Paths) == 0 {
+		return nil, fmt.Errorf("at least one path is required")
+	}
+
+	files, err := ingest.CollectFiles(input.Paths)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no supported files found (.md, .markdown, .txt, .pdf, or an extension with a gomor-ingest-<ext> plugin)")
+	}
+
 	config, err := utils.LoadConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 	if config.Model.EmbeddingModel == nil {
-		return nil, fmt.Errorf("embedding model not configured. Run 'gomor set' to configure")
+		return nil, utils.ConfigErrorf("embedding model not configured. Run 'gomor set' to configure")
+	}
+
+	embeddingModel := *config.Model.EmbeddingModel
+	embClient, err := provider.NewEmbeddingClient(config, embeddingModel.Provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedding client: %w", err)
+	}
+	tmpl, err := embedtext.Parse(config.Memory.EmbeddingTemplate)
+	if err != nil {
+		return nil, err
 	}
 
 	memStore, err := store.NewStore()
@@ -116,32 +465,115 @@ func Retrieve(ctx context.Context, input RetrieveInput) (*RetrieveResult, error)
 	}
 	defer memStore.Close()
 
+	ingester := ingest.NewIngester(memStore, embClient, embeddingModel, tmpl, config.Ingest)
+
+	result := &IngestResult{}
+	for _, path := range files {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		fileResult := ingester.IngestFile(ctx, path, input.Tags)
+		result.Files = append(result.Files, fileResult)
+		result.Chunks += fileResult.Chunks
+	}
+
+	return result, nil
+}
+
+// Import converts another assistant's memory export into memories, skipping
+// entries whose text is already stored.
+func Import(ctx context.Context, input ImportInput) (*ImportResult, error) {
+	data, err := os.ReadFile(input.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export: %w", err)
+	}
+
+	items, err := importer.Parse(input.Format, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no memories found in %s", input.Path)
+	}
+
+	config, err := utils.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if config.Model.EmbeddingModel == nil {
+		return nil, utils.ConfigErrorf("embedding model not configured. Run 'gomor set' to configure")
+	}
+
 	embeddingModel := *config.Model.EmbeddingModel
 	embClient, err := provider.NewEmbeddingClient(config, embeddingModel.Provider)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create embedding client: %w", err)
 	}
+	tmpl, err := embedtext.Parse(config.Memory.EmbeddingTemplate)
+	if err != nil {
+		return nil, err
+	}
 
-	queryClient, toolModel := buildQueryClient(config)
+	memStore, err := store.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	result, err := importer.NewImporter(memStore, embClient, embeddingModel, tmpl, config.Ingest.BatchSize).Import(ctx, items, input.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import memories: %w", err)
+	}
+
+	return &ImportResult{Parsed: len(items), Result: result}, nil
+}
+
+// Export writes every stored memory in another system's format. Embeddings are
+// not exported; the receiving system re-embeds on import. Archived memories
+// are left out unless IncludeArchived is set.
+func Export(ctx context.Context, input ExportInput) (*ExportResult, error) {
+	_ = ctx
 
-	ret := retrieval.NewRetriever(
-		memStore,
-		embClient,
-		queryClient,
-		embeddingModel,
-		toolModel,
-		config.Memory,
-	)
+	var encode func([]memtypes.MemoryItem) ([]byte, error)
+	switch input.Format {
+	case importer.FormatMem0:
+		encode = interop.ToMem0
+	case importer.FormatLetta:
+		encode = interop.ToLetta
+	default:
+		return nil, fmt.Errorf("unknown export format %q (valid: mem0, letta)", input.Format)
+	}
 
-	response, err := ret.Retrieve(ctx, query)
+	memStore, err := store.NewStore()
 	if err != nil {
-		return nil, fmt.Errorf("retrieval failed: %w", err)
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
 	}
+	defer memStore.Close()
 
-	return &RetrieveResult{
-		Response: response,
-		Text:     retrieval.FormatAsText(response),
-	}, nil
+	memories, err := memStore.GetAllMemories()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load memories: %w", err)
+	}
+	if input.IncludeArchived {
+		archived, err := memStore.ArchivedMemories()
+		if err != nil {
+			return nil, err
+		}
+		memories = append(memories, archived...)
+	}
+
+	data, err := encode(memories)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Path != "" {
+		if err := os.WriteFile(input.Path, data, 0o600); err != nil {
+			return nil, fmt.Errorf("failed to write export: %w", err)
+		}
+	}
+
+	return &ExportResult{Format: input.Format, Path: input.Path, Memories: len(memories), Data: data}, nil
 }
 
 func Delete(ctx context.Context, input DeleteInput) (*DeleteResult, error) {
@@ -166,13 +598,379 @@ func Delete(ctx context.Context, input DeleteInput) (*DeleteResult, error) {
 	return &DeleteResult{ID: id, Deleted: deleted}, nil
 }
 
+// Update replaces a memory's text, tags, or both, and re-embeds and
+// re-chunks it. New text for an extracted memory is moderated like a save.
+func Update(ctx context.Context, input UpdateInput) (*UpdateResult, error) {
+	id := strings.TrimSpace(input.ID)
+	if id == "" {
+		return nil, fmt.Errorf("parameter 'id' must be a non-empty string")
+	}
+
+	config, err := utils.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	item, err := memStore.GetMemory(id)
+	if err != nil {
+		return nil, err
+	}
+	if item.ParentID != "" {
+		return nil, fmt.Errorf("memory %s is a chunk of memory %s; update that one instead", id, item.ParentID)
+	}
+
+	text := strings.TrimSpace(input.Text)
+	if text == "" {
+		text = item.Text
+	}
+	tags := item.Tags
+	if input.Tags != nil {
+		tags = tagging.Canonicalize(input.Tags, config.Memory.TagAliases, config.Memory.TagVocabulary)
+	}
+	result := &UpdateResult{ID: id, Text: text, Tags: tags}
+	if text == item.Text && slices.Equal(tags, item.Tags) {
+		return result, nil
+	}
+
+	if text != item.Text && item.Source == memtypes.SourceExtracted {
+		if err := moderateExtracted(ctx, config, text); err != nil {
+			return nil, err
+		}
+	}
+	// Changing the text drops the chunks; changing only the tags must too,
+	// since the chunks carry them.
+	if err := memStore.DeleteMemoryChunks(id); err != nil {
+		return nil, err
+	}
+	item.Tags = tags
+	result.Pending, err = replaceMemoryText(ctx, memStore, *item, text)
+	if err != nil {
+		return nil, err
+	}
+	result.Updated = true
+	return result, nil
+}
+
+// Pin pins or unpins a memory. Pinned memories are included in every
+// retrieval regardless of relevance, subject to the token budget.
+func Pin(ctx context.Context, input PinInput) (*PinResult, error) {
+	_ = ctx
+
+	id := strings.TrimSpace(input.ID)
+	if id == "" {
+		return nil, fmt.Errorf("parameter 'id' must be a non-empty string")
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	found, err := memStore.SetMemoryPinned(id, input.Pinned)
+	if err != nil {
+		return nil, err
+	}
+	return &PinResult{ID: id, Pinned: input.Pinned, Found: found}, nil
+}
+
+// Suppress excludes a memory from retrieval without deleting it, or lets a
+// suppressed memory be retrieved again.
+func Suppress(ctx context.Context, input SuppressInput) (*SuppressResult, error) {
+	_ = ctx
+
+	id := strings.TrimSpace(input.ID)
+	if id == "" {
+		return nil, fmt.Errorf("parameter 'id' must be a non-empty string")
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	found, err := memStore.SetMemorySuppressed(id, input.Suppressed)
+	if err != nil {
+		return nil, err
+	}
+	return &SuppressResult{ID: id, Suppressed: input.Suppressed, Found: found}, nil
+}
+
+// Feedback records whether a retrieved memory was useful or wrong, raising or
+// lowering its confidence so unhelpful memories rank lower over time.
+func Feedback(ctx context.Context, input FeedbackInput) (*FeedbackResult, error) {
+	_ = ctx
+
+	id := strings.TrimSpace(input.ID)
+	if id == "" {
+		return nil, fmt.Errorf("parameter 'id' must be a non-empty string")
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	return memStore.RecordFeedback(id, input.Useful)
+}
+
+// ReviewQueue returns the extracted memories awaiting review, oldest first.
+func ReviewQueue(ctx context.Context) ([]memtypes.MemoryItem, error) {
+	_ = ctx
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	return memStore.PendingReviewMemories()
+}
+
+// Review approves or rejects a memory pending review. Approved memories are
+// retrieved from then on, after replacing their text with input.Text if set;
+// rejected ones are deleted.
+func Review(ctx context.Context, input ReviewInput) (*ReviewResult, error) {
+	id := strings.TrimSpace(input.ID)
+	if id == "" {
+		return nil, fmt.Errorf("parameter 'id' must be a non-empty string")
+	}
+	if input.Decision != ReviewApprove && input.Decision != ReviewReject {
+		return nil, fmt.Errorf("unknown review decision %q (valid: approve, reject)", input.Decision)
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	pending, err := memStore.PendingReviewMemories()
+	if err != nil {
+		return nil, err
+	}
+	var item *memtypes.MemoryItem
+	for i := range pending {
+		if pending[i].ID == id {
+			item = &pending[i]
+			break
+		}
+	}
+	result := &ReviewResult{ID: id, Decision: input.Decision}
+	if item == nil {
+		return result, nil
+	}
+
+	if input.Decision == ReviewReject {
+		result.Found, err = memStore.DeleteMemoryByID(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete memory: %w", err)
+		}
+		return result, nil
+	}
+
+	if text := strings.TrimSpace(input.Text); text != "" && text != strings.TrimSpace(item.Text) {
+		result.Pending, err = replaceMemoryText(ctx, memStore, *item, text)
+		if err != nil {
+			return nil, err
+		}
+		result.Edited = true
+	}
+
+	result.Found, err = memStore.ApproveMemory(id)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// replaceMemoryText stores new text for item and re-embeds it, queueing the
+// embedding when it cannot be computed now. It reports whether it was queued.
+func replaceMemoryText(ctx context.Context, memStore *store.Store, item memtypes.MemoryItem, text string) (bool, error) {
+	if err := memStore.UpdateMemoryContent(item.ID, text, item.Tags, item.Kind); err != nil {
+		return false, err
+	}
+
+	config, err := utils.LoadConfig()
+	if err != nil {
+		return false, fmt.Errorf("failed to load config: %w", err)
+	}
+	tmpl, err := embedtext.Parse(config.Memory.EmbeddingTemplate)
+	if err != nil {
+		return false, err
+	}
+	item.Text = text
+	if config.Model.EmbeddingModel == nil {
+		return true, memStore.EnqueueEmbedding(memtypes.EmbeddingTargetMemory, item.ID)
+	}
+
+	embeddingModel := *config.Model.EmbeddingModel
+	embClient, err := provider.NewEmbeddingClient(config, embeddingModel.Provider)
+	if err != nil {
+		embClient = nil
+	}
+	pending := true
+	if embClient != nil {
+		if embedding, err := embClient.Embed(ctx, embeddingModel, tmpl.Render(item)); err == nil {
+			embedding = memutils.NormalizeVector(embedding)
+			if err := memStore.UpdateMemoryEmbedding(item.ID, embedding, embeddingModel.ModelID, len(embedding), embeddingModel.Provider); err != nil {
+				return false, err
+			}
+			pending = false
+		}
+	}
+	if pending {
+		if err := memStore.EnqueueEmbedding(memtypes.EmbeddingTargetMemory, item.ID); err != nil {
+			return true, err
+		}
+	}
+
+	// The chunks of the old text went with it; chunk the new one.
+	return pending, saveChunks(ctx, memStore, embClient, embeddingModel, tmpl, item, config.Memory.ChunkTokens, pending)
+}
+
+// saveChunks stores parent, when it is longer than chunkTokens tokens, as
+// sentence-aligned chunks saved as its child memories, so that a query
+// matching one part of a long memory still finds it. The chunks are embedded
+// in one batch, or queued for the background worker like a deferred parent.
+func saveChunks(ctx context.Context, memStore *store.Store, embClient client.EmbeddingClient, model types.Model, tmpl *embedtext.Template, parent memtypes.MemoryItem, chunkTokens int, deferred bool) error {
+	if chunkTokens <= 0 {
+		return nil
+	}
+	counter := tokenizer.ForModel(model)
+	if counter.Count(parent.Text) <= chunkTokens {
+		return nil
+	}
+	texts := memutils.ChunkSentences(parent.Text, chunkTokens, counter)
+	if len(texts) < 2 {
+		return nil
+	}
+
+	chunks := make([]memtypes.MemoryItem, len(texts))
+	rendered := make([]string, len(texts))
+	for i, text := range texts {
+		chunks[i] = memtypes.MemoryItem{
+			Text:       text,
+			Tags:       parent.Tags,
+			Source:     parent.Source,
+			Kind:       parent.Kind,
+			CreatedAt:  parent.CreatedAt,
+			Provider:   model.Provider,
+			ModelID:    model.ModelID,
+			ParentID:   parent.ID,
+			ChunkIndex: i,
+		}
+		rendered[i] = tmpl.Render(chunks[i])
+	}
+
+	var embeddings [][]float32
+	if !deferred && embClient != nil {
+		if vectors, err := embClient.EmbedBatch(ctx, model, rendered); err == nil && len(vectors) == len(chunks) {
+			embeddings = vectors
+		}
+	}
+	for i := range chunks {
+		if embeddings != nil {
+			chunks[i].Embedding = memutils.NormalizeVector(embeddings[i])
+			chunks[i].Dim = len(embeddings[i])
+		}
+		if err := memStore.SaveMemory(&chunks[i]); err != nil {
+			return fmt.Errorf("failed to save memory chunk: %w", err)
+		}
+		if embeddings == nil {
+			if err := memStore.EnqueueEmbedding(memtypes.EmbeddingTargetMemory, chunks[i].ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Graph builds the memory base as a graph of similarity edges and tag clusters.
+// Suppressed memories are left out unless input.IncludeSuppressed is set.
+func Graph(ctx context.Context, input GraphInput) (*graph.Graph, error) {
+	_ = ctx
+
+	if input.Threshold < -1 || input.Threshold > 1 {
+		return nil, fmt.Errorf("threshold must be between -1 and 1, got %g", input.Threshold)
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	memories, err := memStore.GetAllMemories()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load memories: %w", err)
+	}
+	if !input.IncludeSuppressed {
+		kept := memories[:0]
+		for _, m := range memories {
+			if !m.Suppressed {
+				kept = append(kept, m)
+			}
+		}
+		memories = kept
+	}
+
+	return graph.Build(memories, input.Threshold), nil
+}
+
+// moderateExtracted refuses memories extracted by agents that moderation blocks.
+func moderateExtracted(ctx context.Context, config *utils.Config, text string) error {
+	moderator, err := moderation.FromConfig(config)
+	if err != nil {
+		return err
+	}
+	verdict, err := moderator.Check(ctx, text)
+	if err != nil {
+		return fmt.Errorf("failed to moderate memory: %w", err)
+	}
+	return verdict.Err()
+}
+
+// knownTagLimit bounds how many existing tags are offered to the tool model.
+const knownTagLimit = 50
+
+// proposeTags asks the tool model for tags for text, from the configured
+// vocabulary if there is one and otherwise preferring the most used existing
+// tags. It returns nil when there is no tool model or it fails.
+func proposeTags(ctx context.Context, config *utils.Config, memStore *store.Store, text string) []string {
+	queryClient, toolModel := buildQueryClient(config)
+	if queryClient == nil {
+		return nil
+	}
+	vocabulary := config.Memory.TagVocabulary
+	var known []string
+	if len(vocabulary) == 0 {
+		known, _ = memStore.TopTags(knownTagLimit)
+	}
+	tags, err := tagging.Propose(ctx, queryClient, toolModel, text, known, vocabulary)
+	if err != nil {
+		return nil
+	}
+	return tags
+}
+
 func buildQueryClient(config *utils.Config) (client.QueryClient, types.Model) {
 	if config.Model.ToolModel == nil {
 		return nil, types.Model{}
 	}
 
 	toolModel := *config.Model.ToolModel
-	queryClient, err := provider.NewQueryClient(config, toolModel.Provider)
+	queryClient, err := provider.NewRoleQueryClient(config, utils.RoleTool, toolModel, func(from, to types.Model, err error) {
+		log.Printf("tool model %s/%s failed (%v); falling back to %s/%s", from.Provider, from.ModelID, err, to.Provider, to.ModelID)
+	})
 	if err != nil {
 		return nil, toolModel
 	}
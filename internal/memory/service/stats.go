@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memutils"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// The staleness policy health reports use when memory.archive_after_days and
+// memory.archive_below_confidence are not set.
+const (
+	defaultStaleAfterDays       = 90
+	defaultStaleBelowConfidence = 0.5
+)
+
+// How much each problem can take off the health score of 100, reached when
+// every memory has it.
+const (
+	duplicatePenalty = 30
+	stalePenalty     = 20
+	ftsPenalty       = 20
+	mismatchPenalty  = 30
+)
+
+type StatsInput struct {
+	// Health adds a HealthReport.
+	Health bool
+}
+
+type StatsResult struct {
+	Memories         int
+	Archived         int
+	History          int
+	NeedsReembedding int
+	// EmbeddingDims counts the embedded memories, chunks included, by
+	// dimension.
+	EmbeddingDims map[int]int
+	Health        *HealthReport
+}
+
+// HealthReport measures the garbage in the memory store and scores it.
+type HealthReport struct {
+	// Score is 100 for a clean store, less the penalty of each problem
+	// weighted by the share of memories it affects.
+	Score int
+	// Duplicates counts the memories repeating the text of an older one.
+	// DuplicateGroups lists the IDs of each set of memories sharing a text,
+	// oldest first.
+	Duplicates      int
+	DuplicateGroups [][]string
+	// Stale counts the memories the archival policy would archive: not
+	// retrieved for StaleAfterDays days, with confidence below
+	// StaleBelowConfidence.
+	Stale                int
+	StaleAfterDays       int
+	StaleBelowConfidence float64
+	// FTSOrphaned counts full-text rows pointing at no memory; FTSMissing the
+	// memories full-text search cannot find.
+	FTSOrphaned int
+	FTSMissing  int
+	// Dim is the dimension of the configured embedding model's embeddings,
+	// or of most embeddings when it made none. DimMismatches lists the
+	// memories embedded at another dimension, which vector search skips.
+	Dim           int
+	DimMismatches []string
+	// Actions suggests the maintenance each problem found calls for.
+	Actions []string
+}
+
+// Stats counts what the memory store holds and, with Health, measures its
+// duplicates, stale memories, full-text index drift, and embedding dimension
+// mismatches.
+func Stats(ctx context.Context, input StatsInput) (*StatsResult, error) {
+	_ = ctx
+
+	config, err := utils.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	memStore, err := store.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory store: %w", err)
+	}
+	defer memStore.Close()
+
+	result := &StatsResult{}
+	if result.Memories, err = memStore.CountMemories(); err != nil {
+		return nil, err
+	}
+	if result.Archived, err = memStore.CountArchivedMemories(); err != nil {
+		return nil, err
+	}
+	if result.History, err = memStore.CountHistory(); err != nil {
+		return nil, err
+	}
+	if result.NeedsReembedding, err = memStore.CountNeedsReembedding(); err != nil {
+		return nil, err
+	}
+	if result.EmbeddingDims, err = memStore.EmbeddingDims(); err != nil {
+		return nil, err
+	}
+	if input.Health {
+		if result.Health, err = checkHealth(memStore, config, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func checkHealth(memStore *store.Store, config *utils.Config, stats *StatsResult) (*HealthReport, error) {
+	report := &HealthReport{
+		StaleAfterDays:       config.Memory.ArchiveAfterDays,
+		StaleBelowConfidence: config.Memory.ArchiveBelowConfidence,
+	}
+	if report.StaleAfterDays <= 0 || report.StaleBelowConfidence <= 0 {
+		report.StaleAfterDays = defaultStaleAfterDays
+		report.StaleBelowConfidence = defaultStaleBelowConfidence
+	}
+
+	memories, err := memStore.GetAllMemories()
+	if err != nil {
+		return nil, err
+	}
+	report.DuplicateGroups = duplicateGroups(memories)
+	for _, group := range report.DuplicateGroups {
+		report.Duplicates += len(group) - 1
+	}
+
+	stale, err := memStore.ArchiveCandidates(time.Now().AddDate(0, 0, -report.StaleAfterDays), report.StaleBelowConfidence)
+	if err != nil {
+		return nil, err
+	}
+	report.Stale = len(stale)
+
+	if report.FTSOrphaned, report.FTSMissing, err = memStore.FTSDrift(); err != nil {
+		return nil, err
+	}
+	if report.Dim, report.DimMismatches, err = dimMismatches(memStore, config, stats.EmbeddingDims); err != nil {
+		return nil, err
+	}
+
+	embedded := 0
+	for _, count := range stats.EmbeddingDims {
+		embedded += count
+	}
+	penalty := duplicatePenalty*ratio(report.Duplicates, stats.Memories) +
+		stalePenalty*ratio(report.Stale, stats.Memories) +
+		ftsPenalty*ratio(report.FTSOrphaned+report.FTSMissing, stats.Memories) +
+		mismatchPenalty*ratio(len(report.DimMismatches), embedded)
+	report.Score = int(math.Round(100 - penalty))
+	report.Actions = healthActions(report)
+	return report, nil
+}
+
+// duplicateGroups returns the IDs of each set of memories whose texts are the
+// same ignoring case and spacing, oldest first.
+func duplicateGroups(memories []store.MemoryItem) [][]string {
+	byText := make(map[string][]store.MemoryItem)
+	var texts []string
+	for _, mem := range memories {
+		key := memutils.NormalizeText(mem.Text)
+		if _, seen := byText[key]; !seen {
+			texts = append(texts, key)
+		}
+		byText[key] = append(byText[key], mem)
+	}
+
+	var groups [][]string
+	for _, text := range texts {
+		group := byText[text]
+		if len(group) < 2 {
+			continue
+		}
+		slices.SortStableFunc(group, func(a, b store.MemoryItem) int { return a.CreatedAt.Compare(b.CreatedAt) })
+		ids := make([]string, len(group))
+		for i, mem := range group {
+			ids[i] = mem.ID
+		}
+		groups = append(groups, ids)
+	}
+	slices.SortStableFunc(groups, func(a, b []string) int { return len(b) - len(a) })
+	return groups
+}
+
+// dimMismatches returns the dimension memories should be embedded at and
+// the memories embedded at another one. The dimension is that of the
+// configured embedding model's embeddings, or of most embeddings when the
+// model made none; dims counts the embeddings by dimension.
+func dimMismatches(memStore *store.Store, config *utils.Config, dims map[int]int) (int, []string, error) {
+	dim := 0
+	if model := config.Model.EmbeddingModel; model != nil {
+		var err error
+		if dim, err = memStore.ModelEmbeddingDim(model.Provider, model.ModelID); err != nil {
+			return 0, nil, err
+		}
+	}
+	if dim == 0 {
+		for d, count := range dims {
+			if count > dims[dim] || (count == dims[dim] && d < dim) {
+				dim = d
+			}
+		}
+	}
+	if dim == 0 {
+		return 0, nil, nil
+	}
+	ids, err := memStore.MemoriesWithOtherDim(dim)
+	return dim, ids, err
+}
+
+// healthActions suggests the maintenance for each problem in report, most
+// harmful first.
+func healthActions(report *HealthReport) []string {
+	var actions []string
+	if n := len(report.DimMismatches); n > 0 {
+		actions = append(actions, fmt.Sprintf("%d memories are embedded at another dimension than %d and vector search skips them; run 'gomor doctor --fix' to re-embed them", n, report.Dim))
+	}
+	if report.FTSOrphaned > 0 || report.FTSMissing > 0 {
+		actions = append(actions, fmt.Sprintf("the full-text index has %d orphaned rows and misses %d memories; run 'gomor doctor --fix' to rebuild it", report.FTSOrphaned, report.FTSMissing))
+	}
+	if report.Duplicates > 0 {
+		actions = append(actions, fmt.Sprintf("%d memories repeat an older memory's text; delete the extra copies with 'gomor memory --delete <id>'", report.Duplicates))
+	}
+	if report.Stale > 0 {
+		actions = append(actions, fmt.Sprintf("%d memories went unused for %d days with confidence below %g; move them out of search with 'gomor memory archive --days %d --below-confidence %g'",
+			report.Stale, report.StaleAfterDays, report.StaleBelowConfidence, report.StaleAfterDays, report.StaleBelowConfidence))
+	}
+	return actions
+}
+
+// ratio returns n/total capped at 1, or 0 when total is 0.
+func ratio(n, total int) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return math.Min(1, float64(n)/float64(total))
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/consts"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	localprov "github.com/austiecodes/gomor/internal/provider/local"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+func TestStatsHealth(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := utils.SaveConfig(localConfig()); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	memStore, err := store.NewStore()
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	embedding := func(dim int) []float32 {
+		v := make([]float32, dim)
+		v[0] = 1
+		return v
+	}
+	now := time.Now()
+	memories := []*memtypes.MemoryItem{
+		{ID: "tabs", Text: "The user indents with tabs", CreatedAt: now.Add(-time.Hour)},
+		{ID: "tabs-again", Text: "the user  indents with TABS", CreatedAt: now},
+		{ID: "old", Text: "The user tried Nix once", CreatedAt: now.AddDate(0, 0, -200), Confidence: 0.2},
+		{ID: "small", Text: "The user deploys with Argo", CreatedAt: now, Embedding: embedding(3)},
+	}
+	for _, item := range memories {
+		item.Source = memtypes.SourceExplicit
+		if item.Embedding == nil {
+			item.Embedding = embedding(512)
+		}
+		item.Dim = len(item.Embedding)
+		item.Provider, item.ModelID = consts.ProviderLocal, localprov.ModelNgramHash512
+		if err := memStore.SaveMemory(item); err != nil {
+			t.Fatalf("save memory: %v", err)
+		}
+	}
+	memStore.Close()
+
+	result, err := Stats(context.Background(), StatsInput{Health: true})
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if result.Memories != 4 || result.EmbeddingDims[512] != 3 || result.EmbeddingDims[3] != 1 {
+		t.Fatalf("unexpected counts: %+v", result)
+	}
+	h := result.Health
+	if h.Duplicates != 1 || !slices.Equal(h.DuplicateGroups[0], []string{"tabs", "tabs-again"}) {
+		t.Fatalf("expected the newer tabs memory as the duplicate, got %v", h.DuplicateGroups)
+	}
+	if h.Stale != 1 || h.StaleAfterDays != defaultStaleAfterDays {
+		t.Fatalf("expected the old memory stale under the default policy, got %d over %d days", h.Stale, h.StaleAfterDays)
+	}
+	if h.Dim != 512 || !slices.Equal(h.DimMismatches, []string{"small"}) {
+		t.Fatalf("expected the 3-dimensional memory mismatched, got %v at %d", h.DimMismatches, h.Dim)
+	}
+	// 30*1/4 + 20*1/4 + 30*1/4 off 100.
+	if h.Score != 80 {
+		t.Fatalf("expected a score of 80, got %d", h.Score)
+	}
+	if len(h.Actions) != 3 || !strings.Contains(h.Actions[0], "gomor doctor --fix") || !strings.Contains(h.Actions[2], "gomor memory archive --days 90 --below-confidence 0.5") {
+		t.Fatalf("unexpected actions: %q", h.Actions)
+	}
+
+	plain, err := Stats(context.Background(), StatsInput{})
+	if err != nil || plain.Health != nil {
+		t.Fatalf("expected no health report without Health, got %+v (%v)", plain.Health, err)
+	}
+}
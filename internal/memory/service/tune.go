@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/retrieval"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// Candidates searches both retrieval paths for query with no similarity
+// threshold, for trying retrieval settings on the results with Fuse.
+func (r *Retriever) Candidates(ctx context.Context, query string) (*retrieval.Candidates, error) {
+	r.mu.RLock()
+	ret := r.retriever
+	r.mu.RUnlock()
+	return ret.SearchCandidates(ctx, query)
+}
+
+// SearchFTS searches c's query again with the FTS strategy memory sets.
+func (r *Retriever) SearchFTS(ctx context.Context, memory utils.MemoryConfig, c *retrieval.Candidates) {
+	r.tuned(memory).SearchFTS(ctx, c)
+}
+
+// Fuse ranks c as retrieval would under memory. The results are not counted
+// as retrieved.
+func (r *Retriever) Fuse(memory utils.MemoryConfig, c *retrieval.Candidates) []memtypes.UnifiedResult {
+	return r.tuned(memory).Fuse(c)
+}
+
+// tuned returns a retriever using r's clients with the memory settings memory.
+func (r *Retriever) tuned(memory utils.MemoryConfig) *retrieval.Retriever {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return retrieval.NewRetriever(r.store, r.embClient, r.queryClient, *r.config.Model.EmbeddingModel, r.toolModel, memory)
+}
@@ -0,0 +1,109 @@
+package store
+
+import (
+	"fmt"
+	"time"
+)
+
+// ArchiveCandidates returns the memories the archival policy would archive:
+// those not retrieved (or, if never retrieved, not created) since before and
+// with confidence below maxConfidence, least recently used first. Pinned
+// memories and memories awaiting review are never candidates.
+func (s *Store) ArchiveCandidates(before time.Time, maxConfidence float64) ([]MemoryItem, error) {
+	rows, err := s.db.Query(selectArchiveCandidatesSQL, maxConfidence, before.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query archive candidates: %w", err)
+	}
+	defer rows.Close()
+
+	return scanMemories(rows)
+}
+
+// ArchiveMemories moves the memories in ids to the archive, where they are no
+// longer searched, and returns how many were moved. The chunks of a long
+// memory are archived with it; its entity links are dropped.
+func (s *Store) ArchiveMemories(ids []string, at time.Time) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	archived := 0
+	for _, id := range ids {
+		result, err := tx.Exec(archiveMemorySQL, at.Unix(), id, id)
+		if err != nil {
+			return 0, fmt.Errorf("failed to archive memory: %w", err)
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return 0, err
+		} else if n == 0 {
+			continue
+		}
+		if _, err := tx.Exec(deleteMemorySQL, id); err != nil {
+			return 0, fmt.Errorf("failed to remove archived memory: %w", err)
+		}
+		archived++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit archive: %w", err)
+	}
+	return archived, nil
+}
+
+// ArchivedMemories returns all archived memories, newest first.
+func (s *Store) ArchivedMemories() ([]MemoryItem, error) {
+	rows, err := s.db.Query(selectArchivedMemoriesSQL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query archived memories: %w", err)
+	}
+	defer rows.Close()
+
+	return scanMemories(rows)
+}
+
+// RestoreMemory moves an archived memory, with its chunks, back into the
+// active set and reports whether it was archived.
+func (s *Store) RestoreMemory(id string) (bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(restoreMemorySQL, id, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to restore memory: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if n == 0 {
+		return false, nil
+	}
+	if _, err := tx.Exec(deleteArchivedMemorySQL, id, id); err != nil {
+		return false, fmt.Errorf("failed to remove restored memory from the archive: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit restore: %w", err)
+	}
+	return true, nil
+}
+
+// CountArchivedMemories returns the number of archived memories.
+func (s *Store) CountArchivedMemories() (int, error) {
+	var n int
+	if err := s.db.QueryRow(countArchivedMemoriesSQL).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count archived memories: %w", err)
+	}
+	return n, nil
+}
+
+// ClearArchivedMemories deletes all archived memories.
+func (s *Store) ClearArchivedMemories() error {
+	_, err := s.db.Exec(clearArchivedMemoriesSQL)
+	return err
+}
@@ -0,0 +1,141 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+func TestArchiveMovesStaleLowConfidenceMemoriesOutOfSearch(t *testing.T) {
+	s := newTestStore(t)
+
+	now := time.Now()
+	longAgo := now.AddDate(0, 0, -120)
+	recently := now.AddDate(0, 0, -3)
+	memories := map[string]*memtypes.MemoryItem{
+		"stale":     {Text: "the user once tried nix flakes", Confidence: 0.4, CreatedAt: longAgo},
+		"used":      {Text: "the user builds with nix", Confidence: 0.4, CreatedAt: longAgo, LastRetrievedAt: &recently, TimesRetrieved: 3},
+		"confident": {Text: "the user prefers nix shells", Confidence: 0.9, CreatedAt: longAgo},
+		"pinned":    {Text: "the user pins nixpkgs", Confidence: 0.4, CreatedAt: longAgo, Pinned: true},
+	}
+	for name, item := range memories {
+		item.ID = name
+		item.Source = memtypes.SourceExplicit
+		item.Embedding = []float32{1, 0}
+		item.Dim = 2
+		if err := s.SaveMemory(item); err != nil {
+			t.Fatalf("save memory: %v", err)
+		}
+	}
+
+	candidates, err := s.ArchiveCandidates(now.AddDate(0, 0, -90), 0.5)
+	if err != nil {
+		t.Fatalf("archive candidates: %v", err)
+	}
+	if len(candidates) != 1 || candidates[0].ID != "stale" {
+		t.Fatalf("expected only the stale memory as a candidate, got %+v", candidates)
+	}
+
+	archived, err := s.ArchiveMemories([]string{"stale", "missing"}, now)
+	if err != nil {
+		t.Fatalf("archive memories: %v", err)
+	}
+	if archived != 1 {
+		t.Fatalf("archived %d memories, want 1", archived)
+	}
+
+	active, err := s.GetAllMemories()
+	if err != nil {
+		t.Fatalf("get all memories: %v", err)
+	}
+	if len(active) != 3 {
+		t.Fatalf("expected 3 active memories, got %d", len(active))
+	}
+	hits, err := s.SearchMemoriesFTS("flakes", 10)
+	if err != nil {
+		t.Fatalf("search memories: %v", err)
+	}
+	if len(hits) != 0 {
+		t.Fatalf("expected archived memory to be excluded from search, got %+v", hits)
+	}
+
+	inArchive, err := s.ArchivedMemories()
+	if err != nil {
+		t.Fatalf("archived memories: %v", err)
+	}
+	if len(inArchive) != 1 || inArchive[0].Text != "the user once tried nix flakes" || inArchive[0].Confidence != 0.4 {
+		t.Fatalf("unexpected archive: %+v", inArchive)
+	}
+	if n, err := s.CountArchivedMemories(); err != nil || n != 1 {
+		t.Fatalf("count archived = %d, %v; want 1", n, err)
+	}
+
+	restored, err := s.RestoreMemory("stale")
+	if err != nil || !restored {
+		t.Fatalf("restore = %v, %v; want true", restored, err)
+	}
+	if restored, err := s.RestoreMemory("stale"); err != nil || restored {
+		t.Fatalf("second restore = %v, %v; want false", restored, err)
+	}
+	hits, err = s.SearchMemoriesFTS("flakes", 10)
+	if err != nil {
+		t.Fatalf("search memories: %v", err)
+	}
+	if len(hits) != 1 || hits[0].Item.ID != "stale" {
+		t.Fatalf("expected the restored memory to be searched again, got %+v", hits)
+	}
+}
+
+func TestArchiveAndRestoreKeepChunks(t *testing.T) {
+	s := newTestStore(t)
+
+	parent := &memtypes.MemoryItem{ID: "long", Text: "deploys go out on Tuesdays. The staging cluster runs postgres.", Source: memtypes.SourceExplicit, Embedding: []float32{1, 0}}
+	if err := s.SaveMemory(parent); err != nil {
+		t.Fatalf("save parent: %v", err)
+	}
+	for i, chunk := range []struct {
+		text      string
+		embedding []float32
+	}{
+		{"deploys go out on Tuesdays.", []float32{1, 0}},
+		{"The staging cluster runs postgres.", []float32{0, 1}},
+	} {
+		item := &memtypes.MemoryItem{Text: chunk.text, Source: memtypes.SourceExplicit, Embedding: chunk.embedding, ParentID: parent.ID, ChunkIndex: i}
+		if err := s.SaveMemory(item); err != nil {
+			t.Fatalf("save chunk: %v", err)
+		}
+	}
+
+	if archived, err := s.ArchiveMemories([]string{parent.ID}, time.Now()); err != nil || archived != 1 {
+		t.Fatalf("archive = %d, %v; want 1", archived, err)
+	}
+	if searchable, err := s.SearchableMemories(); err != nil || len(searchable) != 0 {
+		t.Fatalf("expected the memory and its chunks out of search, got %d rows (%v)", len(searchable), err)
+	}
+	inArchive, err := s.ArchivedMemories()
+	if err != nil || len(inArchive) != 1 || inArchive[0].ID != parent.ID {
+		t.Fatalf("expected only the parent listed in the archive, got %+v (%v)", inArchive, err)
+	}
+	if n, err := s.CountArchivedMemories(); err != nil || n != 1 {
+		t.Fatalf("count archived = %d, %v; want 1", n, err)
+	}
+
+	if restored, err := s.RestoreMemory(parent.ID); err != nil || !restored {
+		t.Fatalf("restore = %v, %v; want true", restored, err)
+	}
+	if searchable, err := s.SearchableMemories(); err != nil || len(searchable) != 3 {
+		t.Fatalf("expected the memory and both chunks restored, got %d rows (%v)", len(searchable), err)
+	}
+	// Only the second chunk matches this embedding.
+	results, err := s.SearchMemories([]float32{0, 1}, 5, 0.5)
+	if err != nil {
+		t.Fatalf("search memories: %v", err)
+	}
+	if len(results) != 1 || results[0].Item.ID != parent.ID {
+		t.Fatalf("expected chunk-level recall of the restored memory, got %+v", results)
+	}
+	if n, err := s.CountArchivedMemories(); err != nil || n != 0 {
+		t.Fatalf("count archived = %d, %v; want 0", n, err)
+	}
+}
@@ -0,0 +1,47 @@
+package store
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	_ "modernc.org/sqlite"
+)
+
+func TestBackupPreservesDataBeforeClear(t *testing.T) {
+	s := newTestStore(t)
+
+	item := &memtypes.MemoryItem{Text: "keep me", Source: memtypes.SourceExplicit, Provider: "fake", ModelID: "fake"}
+	if err := s.SaveMemory(item); err != nil {
+		t.Fatalf("save memory: %v", err)
+	}
+
+	backupPath := filepath.Join(t.TempDir(), "backup.db")
+	if err := s.Backup(backupPath); err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+	if err := s.ClearMemories(); err != nil {
+		t.Fatalf("clear memories: %v", err)
+	}
+	if n, err := s.CountMemories(); err != nil || n != 0 {
+		t.Fatalf("expected 0 memories after clear, got %d (%v)", n, err)
+	}
+
+	backupDB, err := sql.Open("sqlite", backupPath)
+	if err != nil {
+		t.Fatalf("open backup: %v", err)
+	}
+	backup, err := NewStoreWithDB(backupDB)
+	if err != nil {
+		t.Fatalf("open backup store: %v", err)
+	}
+	defer backup.Close()
+
+	if n, err := backup.CountMemories(); err != nil || n != 1 {
+		t.Fatalf("expected 1 memory in backup, got %d (%v)", n, err)
+	}
+	if err := s.Backup(backupPath); err == nil {
+		t.Fatal("expected backup to refuse overwriting an existing file")
+	}
+}
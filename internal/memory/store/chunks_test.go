@@ -0,0 +1,81 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+func TestMemoryChunks(t *testing.T) {
+	s := newTestStore(t)
+
+	parent := &memtypes.MemoryItem{Text: "deploys go out on Tuesdays. The staging cluster runs postgres.", Source: memtypes.SourceExplicit, Embedding: []float32{1, 0}}
+	if err := s.SaveMemory(parent); err != nil {
+		t.Fatalf("save parent: %v", err)
+	}
+	for i, chunk := range []struct {
+		text      string
+		embedding []float32
+	}{
+		{"deploys go out on Tuesdays.", []float32{1, 0}},
+		{"The staging cluster runs postgres.", []float32{0, 1}},
+	} {
+		item := &memtypes.MemoryItem{Text: chunk.text, Source: memtypes.SourceExplicit, Embedding: chunk.embedding, ParentID: parent.ID, ChunkIndex: i}
+		if err := s.SaveMemory(item); err != nil {
+			t.Fatalf("save chunk: %v", err)
+		}
+	}
+
+	memories, err := s.GetAllMemories()
+	if err != nil {
+		t.Fatalf("get all memories: %v", err)
+	}
+	if len(memories) != 1 || memories[0].ID != parent.ID {
+		t.Fatalf("expected only the parent to be listed, got %+v", memories)
+	}
+	if n, err := s.CountMemories(); err != nil || n != 1 {
+		t.Fatalf("expected 1 memory counted, got %d (%v)", n, err)
+	}
+
+	// The second chunk matches where the parent's own embedding does not.
+	results, err := s.SearchMemories([]float32{0, 1}, 5, 0.5)
+	if err != nil {
+		t.Fatalf("search memories: %v", err)
+	}
+	if len(results) != 1 || results[0].Item.ID != parent.ID || results[0].Similarity < 0.99 {
+		t.Fatalf("expected the chunk to be merged into its parent, got %+v", results)
+	}
+
+	fts, err := s.SearchMemoriesFTS("postgres", 5)
+	if err != nil {
+		t.Fatalf("search memories fts: %v", err)
+	}
+	if len(fts) != 1 || fts[0].Item.ID != parent.ID {
+		t.Fatalf("expected FTS to find only the parent, got %+v", fts)
+	}
+
+	if err := s.UpdateMemoryContent(parent.ID, "deploys go out on Tuesdays.", nil, memtypes.KindFact); err != nil {
+		t.Fatalf("update memory content: %v", err)
+	}
+	searchable, err := s.SearchableMemories()
+	if err != nil {
+		t.Fatalf("searchable memories: %v", err)
+	}
+	if len(searchable) != 1 {
+		t.Fatalf("expected chunks to be dropped when the text changes, got %d rows", len(searchable))
+	}
+
+	chunk := &memtypes.MemoryItem{Text: "deploys go out on Tuesdays.", Source: memtypes.SourceExplicit, ParentID: parent.ID}
+	if err := s.SaveMemory(chunk); err != nil {
+		t.Fatalf("save chunk: %v", err)
+	}
+	if err := s.DeleteMemory(parent.ID); err != nil {
+		t.Fatalf("delete memory: %v", err)
+	}
+	if searchable, err = s.SearchableMemories(); err != nil || len(searchable) != 0 {
+		t.Fatalf("expected chunks to be deleted with their parent, got %d rows (%v)", len(searchable), err)
+	}
+	if _, err := s.db.Exec(`INSERT INTO memories_fts(memories_fts) VALUES('integrity-check')`); err != nil {
+		t.Fatalf("expected deleted chunks to leave the FTS index: %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+type Entity = memtypes.Entity
+
+// NormalizeEntityName lowercases and collapses whitespace so that "Project  Atlas"
+// and "project atlas" resolve to the same entity.
+func NormalizeEntityName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// UpsertEntity returns the entity with the given name and kind, creating it if needed.
+func (s *Store) UpsertEntity(name, kind string) (Entity, error) {
+	name = strings.TrimSpace(name)
+	normalized := NormalizeEntityName(name)
+	if normalized == "" {
+		return Entity{}, fmt.Errorf("entity name must be non-empty")
+	}
+
+	if _, err := s.db.Exec(insertEntitySQL, uuid.New().String(), name, kind, normalized, time.Now().Unix()); err != nil {
+		return Entity{}, fmt.Errorf("failed to save entity: %w", err)
+	}
+
+	var entity Entity
+	if err := s.db.QueryRow(selectEntitySQL, normalized, kind).Scan(&entity.ID, &entity.Name, &entity.Kind); err != nil {
+		return Entity{}, fmt.Errorf("failed to load entity: %w", err)
+	}
+	return entity, nil
+}
+
+// LinkMemoryEntity records that a memory mentions an entity.
+func (s *Store) LinkMemoryEntity(memoryID, entityID string) error {
+	if _, err := s.db.Exec(linkMemoryEntitySQL, memoryID, entityID); err != nil {
+		return fmt.Errorf("failed to link memory entity: %w", err)
+	}
+	return nil
+}
+
+// GetAllEntities returns every known entity ordered by name.
+func (s *Store) GetAllEntities() ([]Entity, error) {
+	rows, err := s.db.Query(selectAllEntitiesSQL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query entities: %w", err)
+	}
+	defer rows.Close()
+
+	return scanEntities(rows)
+}
+
+// GetMemoryEntities returns the entities linked to a memory.
+func (s *Store) GetMemoryEntities(memoryID string) ([]Entity, error) {
+	rows, err := s.db.Query(selectMemoryEntitiesSQL, memoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query memory entities: %w", err)
+	}
+	defer rows.Close()
+
+	return scanEntities(rows)
+}
+
+// GetMemoriesByEntity returns all memories linked to entities with the given name, newest first.
+func (s *Store) GetMemoriesByEntity(name string) ([]MemoryItem, error) {
+	rows, err := s.db.Query(selectMemoriesByEntitySQL, NormalizeEntityName(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query memories by entity: %w", err)
+	}
+	defer rows.Close()
+
+	return scanMemories(rows)
+}
+
+// EntityMemoryIDs returns the IDs of memories linked to any of the given entities.
+func (s *Store) EntityMemoryIDs(entityIDs []string) (map[string]bool, error) {
+	ids := make(map[string]bool)
+	for _, entityID := range entityIDs {
+		rows, err := s.db.Query(selectEntityMemoryIDsSQL, entityID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query entity memories: %w", err)
+		}
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan entity memory id: %w", err)
+			}
+			ids[id] = true
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+func scanEntities(rows interface {
+	Next() bool
+	Scan(dest ...any) error
+	Err() error
+}) ([]Entity, error) {
+	var entities []Entity
+	for rows.Next() {
+		var entity Entity
+		if err := rows.Scan(&entity.ID, &entity.Name, &entity.Kind); err != nil {
+			return nil, fmt.Errorf("failed to scan entity row: %w", err)
+		}
+		entities = append(entities, entity)
+	}
+	return entities, rows.Err()
+}
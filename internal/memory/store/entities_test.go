@@ -0,0 +1,56 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+func TestEntityLinksAndTraversal(t *testing.T) {
+	s := newTestStore(t)
+
+	first := &memtypes.MemoryItem{Text: "Atlas ships in March", Source: memtypes.SourceExplicit, Provider: "fake", ModelID: "fake"}
+	second := &memtypes.MemoryItem{Text: "Jane leads Atlas", Source: memtypes.SourceExplicit, Provider: "fake", ModelID: "fake"}
+	for _, item := range []*memtypes.MemoryItem{first, second} {
+		if err := s.SaveMemory(item); err != nil {
+			t.Fatalf("save memory: %v", err)
+		}
+	}
+
+	atlas, err := s.UpsertEntity("Atlas", "project")
+	if err != nil {
+		t.Fatalf("upsert entity: %v", err)
+	}
+	again, err := s.UpsertEntity("  atlas ", "project")
+	if err != nil {
+		t.Fatalf("upsert entity again: %v", err)
+	}
+	if again.ID != atlas.ID {
+		t.Fatalf("expected normalized names to resolve to one entity, got %s and %s", atlas.ID, again.ID)
+	}
+
+	for _, item := range []*memtypes.MemoryItem{first, second} {
+		if err := s.LinkMemoryEntity(item.ID, atlas.ID); err != nil {
+			t.Fatalf("link entity: %v", err)
+		}
+	}
+
+	memories, err := s.GetMemoriesByEntity("ATLAS")
+	if err != nil {
+		t.Fatalf("memories by entity: %v", err)
+	}
+	if len(memories) != 2 {
+		t.Fatalf("expected 2 linked memories, got %d", len(memories))
+	}
+
+	if err := s.DeleteMemory(first.ID); err != nil {
+		t.Fatalf("delete memory: %v", err)
+	}
+	ids, err := s.EntityMemoryIDs([]string{atlas.ID})
+	if err != nil {
+		t.Fatalf("entity memory ids: %v", err)
+	}
+	if len(ids) != 1 || !ids[second.ID] {
+		t.Fatalf("expected only the remaining memory to stay linked, got %v", ids)
+	}
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/decay"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+type MemoryFeedback = memtypes.MemoryFeedback
+
+// ErrMemoryNotFound is returned when a memory id does not exist.
+var ErrMemoryNotFound = errors.New("memory not found")
+
+// RecordFeedback records a useful or wrong vote on a memory and recalibrates
+// its confidence and stability accordingly.
+func (s *Store) RecordFeedback(memoryID string, useful bool) (*MemoryFeedback, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var confidence, stabilityDays float64
+	err = tx.QueryRow(selectMemoryDecaySQL, memoryID).Scan(&confidence, &stabilityDays)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrMemoryNotFound, memoryID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memory: %w", err)
+	}
+
+	feedback := &MemoryFeedback{MemoryID: memoryID, PreviousConfidence: confidence}
+	feedback.Confidence, feedback.StabilityDays = decay.ApplyFeedback(confidence, stabilityDays, useful)
+
+	if _, err := tx.Exec(updateMemoryConfidenceSQL, feedback.Confidence, feedback.StabilityDays, memoryID); err != nil {
+		return nil, fmt.Errorf("failed to update memory confidence: %w", err)
+	}
+	vote := 0
+	if useful {
+		vote = 1
+	}
+	if _, err := tx.Exec(insertMemoryFeedbackSQL, memoryID, vote, time.Now().Unix()); err != nil {
+		return nil, fmt.Errorf("failed to record feedback: %w", err)
+	}
+	if err := tx.QueryRow(countMemoryFeedbackSQL, memoryID).Scan(&feedback.Useful, &feedback.Wrong); err != nil {
+		return nil, fmt.Errorf("failed to count feedback: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit feedback: %w", err)
+	}
+	return feedback, nil
+}
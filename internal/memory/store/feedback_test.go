@@ -0,0 +1,50 @@
+package store
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+func TestRecordFeedbackCalibratesConfidence(t *testing.T) {
+	s := newTestStore(t)
+
+	item := &memtypes.MemoryItem{Text: "deploys go through staging", Source: memtypes.SourceExplicit}
+	if err := s.SaveMemory(item); err != nil {
+		t.Fatalf("save memory: %v", err)
+	}
+
+	useful, err := s.RecordFeedback(item.ID, true)
+	if err != nil {
+		t.Fatalf("useful feedback: %v", err)
+	}
+	if useful.Confidence <= useful.PreviousConfidence || useful.Confidence > 1 {
+		t.Fatalf("expected useful feedback to raise confidence, got %.3f -> %.3f", useful.PreviousConfidence, useful.Confidence)
+	}
+
+	var last *MemoryFeedback
+	for i := 0; i < 3; i++ {
+		if last, err = s.RecordFeedback(item.ID, false); err != nil {
+			t.Fatalf("wrong feedback: %v", err)
+		}
+	}
+	if last.Confidence >= 0.5 {
+		t.Fatalf("expected repeated wrong feedback to sink confidence, got %.3f", last.Confidence)
+	}
+	if last.Useful != 1 || last.Wrong != 3 {
+		t.Fatalf("expected 1 useful and 3 wrong votes, got %d and %d", last.Useful, last.Wrong)
+	}
+
+	memories, err := s.GetAllMemories()
+	if err != nil {
+		t.Fatalf("get all memories: %v", err)
+	}
+	if memories[0].Confidence != last.Confidence || memories[0].StabilityDays != last.StabilityDays {
+		t.Fatalf("expected stored calibration to match, got %+v", memories[0])
+	}
+
+	if _, err := s.RecordFeedback("missing", true); !errors.Is(err, ErrMemoryNotFound) {
+		t.Fatalf("expected ErrMemoryNotFound, got %v", err)
+	}
+}
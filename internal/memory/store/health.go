@@ -0,0 +1,85 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// FTSDrift compares the full-text index of memories with the memories table
+// and returns how many indexed rows point at no memory (orphaned) and how
+// many memories are missing from the index. Both stay zero while the triggers
+// keep the index in sync; rows changed around them, e.g. by hand or by an
+// interrupted restore, make full-text search miss memories or match deleted
+// ones.
+func (s *Store) FTSDrift() (orphaned, missing int, err error) {
+	if err := s.db.QueryRow(countMemoriesFTSOrphansSQL).Scan(&orphaned); err != nil {
+		return 0, 0, fmt.Errorf("failed to count orphaned full-text rows: %w", err)
+	}
+	if err := s.db.QueryRow(countMemoriesFTSMissingSQL).Scan(&missing); err != nil {
+		return 0, 0, fmt.Errorf("failed to count memories missing from full-text search: %w", err)
+	}
+	return orphaned, missing, nil
+}
+
+// RebuildFTS rebuilds the full-text index of memories from their text.
+func (s *Store) RebuildFTS() error {
+	if _, err := s.db.Exec(rebuildMemoriesFTSSQL); err != nil {
+		return fmt.Errorf("failed to rebuild the full-text index: %w", err)
+	}
+	return nil
+}
+
+// EmbeddingDims counts the embedded memories, chunks included, by dimension.
+// Memories waiting in the embedding queue are left out.
+func (s *Store) EmbeddingDims() (map[int]int, error) {
+	rows, err := s.db.Query(countEmbeddingDimsSQL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count embedding dimensions: %w", err)
+	}
+	defer rows.Close()
+
+	dims := make(map[int]int)
+	for rows.Next() {
+		var dim, count int
+		if err := rows.Scan(&dim, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan embedding dimension: %w", err)
+		}
+		dims[dim] = count
+	}
+	return dims, rows.Err()
+}
+
+// ModelEmbeddingDim returns the dimension of most memory embeddings made by
+// the model, or 0 when it embedded none.
+func (s *Store) ModelEmbeddingDim(provider, modelID string) (int, error) {
+	var dim int
+	err := s.db.QueryRow(selectModelEmbeddingDimSQL, provider, modelID).Scan(&dim)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to query the model's embedding dimension: %w", err)
+	}
+	return dim, nil
+}
+
+// MemoriesWithOtherDim returns the IDs of the embedded memories, chunks
+// included, whose dimension is not dim, oldest first.
+func (s *Store) MemoriesWithOtherDim(dim int) ([]string, error) {
+	rows, err := s.db.Query(selectMemoriesWithOtherDimSQL, dim)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query memories by dimension: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan memory id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,72 @@
+package store
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+func TestFTSDriftAndRebuild(t *testing.T) {
+	s := newTestStore(t)
+	for _, id := range []string{"kept", "unindexed"} {
+		item := &memtypes.MemoryItem{ID: id, Text: "memory " + id, Source: memtypes.SourceExplicit, Embedding: []float32{1, 0}, Dim: 2}
+		if err := s.SaveMemory(item); err != nil {
+			t.Fatalf("save memory: %v", err)
+		}
+	}
+	if orphaned, missing, err := s.FTSDrift(); err != nil || orphaned != 0 || missing != 0 {
+		t.Fatalf("expected the index in sync, got %d orphaned, %d missing (%v)", orphaned, missing, err)
+	}
+
+	if _, err := s.db.Exec(`INSERT INTO memories_fts(memories_fts, rowid, text) SELECT 'delete', rowid, text FROM memories WHERE id = 'unindexed'`); err != nil {
+		t.Fatalf("drop from index: %v", err)
+	}
+	if _, err := s.db.Exec(`INSERT INTO memories_fts(rowid, text) VALUES (9999, 'ghost memory')`); err != nil {
+		t.Fatalf("add orphan: %v", err)
+	}
+	if orphaned, missing, err := s.FTSDrift(); err != nil || orphaned != 1 || missing != 1 {
+		t.Fatalf("expected 1 orphaned and 1 missing row, got %d and %d (%v)", orphaned, missing, err)
+	}
+
+	if err := s.RebuildFTS(); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	if orphaned, missing, err := s.FTSDrift(); err != nil || orphaned != 0 || missing != 0 {
+		t.Fatalf("expected the rebuilt index in sync, got %d orphaned, %d missing (%v)", orphaned, missing, err)
+	}
+	results, err := s.SearchMemoriesFTS("unindexed", 5)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("expected the rebuilt index to find the memory, got %+v (%v)", results, err)
+	}
+}
+
+func TestEmbeddingDims(t *testing.T) {
+	s := newTestStore(t)
+	memories := []*memtypes.MemoryItem{
+		{ID: "a", Embedding: []float32{1, 0, 0}, Provider: "openai", ModelID: "small"},
+		{ID: "b", Embedding: []float32{0, 1, 0}, Provider: "openai", ModelID: "small"},
+		{ID: "c", Embedding: []float32{1, 0}, Provider: "ollama", ModelID: "tiny"},
+		{ID: "queued"},
+	}
+	for _, item := range memories {
+		item.Text, item.Source, item.Dim = "memory "+item.ID, memtypes.SourceExplicit, len(item.Embedding)
+		if err := s.SaveMemory(item); err != nil {
+			t.Fatalf("save memory: %v", err)
+		}
+	}
+
+	dims, err := s.EmbeddingDims()
+	if err != nil || len(dims) != 2 || dims[3] != 2 || dims[2] != 1 {
+		t.Fatalf("expected 2 memories of 3 dimensions and 1 of 2, got %v (%v)", dims, err)
+	}
+	if dim, err := s.ModelEmbeddingDim("ollama", "tiny"); err != nil || dim != 2 {
+		t.Fatalf("expected the model's dimension, got %d (%v)", dim, err)
+	}
+	if dim, err := s.ModelEmbeddingDim("openai", "large"); err != nil || dim != 0 {
+		t.Fatalf("expected no dimension for an unused model, got %d (%v)", dim, err)
+	}
+	if ids, err := s.MemoriesWithOtherDim(3); err != nil || !slices.Equal(ids, []string{"c"}) {
+		t.Fatalf("expected only c, got %v (%v)", ids, err)
+	}
+}
@@ -0,0 +1,98 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+func TestHistorySinceReturnsRecentTurnsOldestFirst(t *testing.T) {
+	s := newTestStore(t)
+
+	now := time.Now()
+	turns := []*memtypes.HistoryItem{
+		{Role: "user", Content: "last month", SessionID: "old", CreatedAt: now.AddDate(0, -1, 0)},
+		{Role: "user", Content: "hello", SessionID: "s1", CreatedAt: now.Add(-time.Hour)},
+		{Role: "assistant", Content: "hi", SessionID: "s1", Model: "openai/gpt-5-nano", CreatedAt: now.Add(-time.Hour)},
+	}
+	for _, turn := range turns {
+		if err := s.SaveHistory(turn); err != nil {
+			t.Fatalf("save history: %v", err)
+		}
+	}
+
+	items, err := s.HistorySince(now.AddDate(0, 0, -7))
+	if err != nil {
+		t.Fatalf("history since: %v", err)
+	}
+	if len(items) != 2 || items[0].Content != "hello" || items[1].Content != "hi" {
+		t.Fatalf("expected this week's turns in order, got %+v", items)
+	}
+	if items[0].Model != "" || items[1].Model != "openai/gpt-5-nano" {
+		t.Fatalf("expected the answer's model to round-trip, got %q and %q", items[0].Model, items[1].Model)
+	}
+}
+
+func TestSaveHistorySkipsRepeatedTurns(t *testing.T) {
+	s := newTestStore(t)
+
+	first := &memtypes.HistoryItem{Role: "user", Content: "You are a coding agent.", SessionID: "s1"}
+	repeat := &memtypes.HistoryItem{Role: "user", Content: "You are a coding agent.", SessionID: "s1"}
+	for _, turn := range []*memtypes.HistoryItem{first, repeat} {
+		if err := s.SaveHistory(turn); err != nil {
+			t.Fatalf("save history: %v", err)
+		}
+	}
+	if repeat.ID != first.ID {
+		t.Fatalf("expected the repeated turn to take the stored turn's ID, got %s and %s", repeat.ID, first.ID)
+	}
+
+	// The same content is stored again once another turn comes between,
+	// and in other sessions.
+	turns := []*memtypes.HistoryItem{
+		{Role: "assistant", Content: "ok", SessionID: "s1", ParentID: first.ID},
+		{Role: "user", Content: "You are a coding agent.", SessionID: "s1"},
+		{Role: "user", Content: "You are a coding agent.", SessionID: "s2"},
+	}
+	for _, turn := range turns {
+		if err := s.SaveHistory(turn); err != nil {
+			t.Fatalf("save history: %v", err)
+		}
+	}
+	if n, err := s.CountHistory(); err != nil || n != 4 {
+		t.Fatalf("expected 4 stored turns, got %d (%v)", n, err)
+	}
+}
+
+func TestRecentSessionsWithTitles(t *testing.T) {
+	s := newTestStore(t)
+
+	now := time.Now()
+	for _, turn := range []*memtypes.HistoryItem{
+		{Role: "user", Content: "old question", SessionID: "s1", CreatedAt: now.Add(-time.Hour)},
+		{Role: "user", Content: "new question", SessionID: "s2", CreatedAt: now},
+	} {
+		if err := s.EnsureSession(turn.SessionID); err != nil {
+			t.Fatalf("ensure session: %v", err)
+		}
+		if err := s.SaveHistory(turn); err != nil {
+			t.Fatalf("save history: %v", err)
+		}
+	}
+	if err := s.SetSessionTitle("s1", "Old question"); err != nil {
+		t.Fatalf("set title: %v", err)
+	}
+
+	sessions, err := s.RecentSessions(10)
+	if err != nil {
+		t.Fatalf("recent sessions: %v", err)
+	}
+	if len(sessions) != 2 || sessions[0].ID != "s2" || sessions[0].Title != "" || sessions[1].Title != "Old question" {
+		t.Fatalf("expected the most recently active session first, got %+v", sessions)
+	}
+	session, err := s.GetSession("s1")
+	if err != nil || session == nil || session.Title != "Old question" {
+		t.Fatalf("expected the title on the session, got %+v (%v)", session, err)
+	}
+}
@@ -0,0 +1,159 @@
+package store
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memutils"
+)
+
+// IntegrityCheck runs SQLite's integrity check over the whole database and
+// returns the problems it reports, or nil when the database is sound.
+func (s *Store) IntegrityCheck() ([]string, error) {
+	rows, err := s.db.Query(integrityCheckSQL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check database integrity: %w", err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, fmt.Errorf("failed to scan integrity check: %w", err)
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	return problems, rows.Err()
+}
+
+// CheckEmbeddings returns the stored memory and history embeddings that
+// cannot be used for search: truncated blobs, which BytesToVector reads as no
+// embedding at all, blobs whose length disagrees with their recorded
+// dimension, and vectors holding NaN or infinite values. Rows still waiting
+// in the embedding queue have no embedding yet and are not reported.
+func (s *Store) CheckEmbeddings() ([]EmbeddingProblem, error) {
+	rows, err := s.db.Query(selectStoredEmbeddingsSQL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	var problems []EmbeddingProblem
+	for rows.Next() {
+		var kind, id string
+		var dim int
+		var blob []byte
+		if err := rows.Scan(&kind, &id, &dim, &blob); err != nil {
+			return nil, fmt.Errorf("failed to scan embedding: %w", err)
+		}
+		if reason := embeddingProblem(blob, dim); reason != "" {
+			problems = append(problems, EmbeddingProblem{TargetKind: EmbeddingTarget(kind), TargetID: id, Reason: reason})
+		}
+	}
+	return problems, rows.Err()
+}
+
+// embeddingProblem describes what is wrong with an embedding blob of the
+// given recorded dimension, or returns "" when it is usable.
+func embeddingProblem(blob []byte, dim int) string {
+	if len(blob)%4 != 0 {
+		return fmt.Sprintf("truncated: %d bytes is not a whole number of float32 values", len(blob))
+	}
+	if n := len(blob) / 4; n != dim {
+		return fmt.Sprintf("has %d values but dimension %d", n, dim)
+	}
+	for i := 0; i < len(blob); i += 4 {
+		v := float64(math.Float32frombits(binary.LittleEndian.Uint32(blob[i:])))
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "contains NaN or infinite values"
+		}
+	}
+	return ""
+}
+
+// RequeueEmbedding drops the stored embedding of a memory or history row and
+// queues the row to be embedded again.
+func (s *Store) RequeueEmbedding(kind EmbeddingTarget, targetID string) error {
+	var clearSQL string
+	switch kind {
+	case EmbeddingTargetMemory:
+		clearSQL = clearMemoryEmbeddingSQL
+	case EmbeddingTargetHistory:
+		clearSQL = clearHistoryEmbeddingSQL
+	default:
+		return fmt.Errorf("unknown embedding target kind: %s", kind)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(clearSQL, targetID); err != nil {
+		return fmt.Errorf("failed to clear embedding: %w", err)
+	}
+	if _, err := tx.Exec(enqueueEmbeddingSQL, string(kind), targetID, time.Now().Unix()); err != nil {
+		return fmt.Errorf("failed to enqueue embedding: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit requeue: %w", err)
+	}
+	return nil
+}
+
+// MalformedEmbeddingsError reports memories a search skipped because their
+// stored embeddings are malformed. It matches memutils.ErrMalformedEmbedding.
+type MalformedEmbeddingsError struct {
+	IDs []string
+}
+
+func (e *MalformedEmbeddingsError) Error() string {
+	return fmt.Sprintf("%d memories have malformed embeddings and were skipped (%s); run 'gomor doctor --fix' to re-embed them",
+		len(e.IDs), strings.Join(e.IDs, ", "))
+}
+
+func (e *MalformedEmbeddingsError) Unwrap() error {
+	return memutils.ErrMalformedEmbedding
+}
+
+// MalformedEmbeddings returns a *MalformedEmbeddingsError naming the memories
+// whose stored embeddings could not be decoded, or nil when there are none.
+func MalformedEmbeddings(memories []MemoryItem) error {
+	var ids []string
+	for _, mem := range memories {
+		if mem.NeedsReembedding {
+			ids = append(ids, mem.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return &MalformedEmbeddingsError{IDs: ids}
+}
+
+// MarkNeedsReembedding flags memories whose embeddings must be rebuilt. The
+// flag is cleared when UpdateMemoryEmbedding stores a new embedding.
+func (s *Store) MarkNeedsReembedding(ids []string) error {
+	for _, id := range ids {
+		if _, err := s.db.Exec(markMemoryNeedsReembeddingSQL, id); err != nil {
+			return fmt.Errorf("failed to flag memory for re-embedding: %w", err)
+		}
+	}
+	return nil
+}
+
+// CountNeedsReembedding returns how many memories are flagged for re-embedding.
+func (s *Store) CountNeedsReembedding() (int, error) {
+	var count int
+	if err := s.db.QueryRow(countMemoriesNeedingReembeddingSQL).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count memories needing re-embedding: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+func TestCheckEmbeddingsFindsCorruptRowsAndRequeuesThem(t *testing.T) {
+	s := newTestStore(t)
+
+	memories := map[string][]float32{
+		"good":      {1, 0, 0},
+		"truncated": {1, 0, 0},
+		"short":     {1, 0, 0},
+		"nan":       {float32(math.NaN()), 0, 0},
+		"queued":    nil,
+	}
+	for id, embedding := range memories {
+		item := &memtypes.MemoryItem{ID: id, Text: "memory " + id, Source: memtypes.SourceExplicit, Embedding: embedding, Dim: len(embedding)}
+		if err := s.SaveMemory(item); err != nil {
+			t.Fatalf("save memory: %v", err)
+		}
+	}
+	if _, err := s.db.Exec(`UPDATE memories SET embedding = substr(embedding, 1, 10) WHERE id = 'truncated'`); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+	if _, err := s.db.Exec(`UPDATE memories SET dim = 4 WHERE id = 'short'`); err != nil {
+		t.Fatalf("set dim: %v", err)
+	}
+	turn := &memtypes.HistoryItem{Role: "user", Content: "hello"}
+	if err := s.SaveHistory(turn); err != nil {
+		t.Fatalf("save history: %v", err)
+	}
+	if _, err := s.db.Exec(`UPDATE history SET embedding = X'0000', dim = 1 WHERE id = ?`, turn.ID); err != nil {
+		t.Fatalf("corrupt history: %v", err)
+	}
+
+	problems, err := s.CheckEmbeddings()
+	if err != nil {
+		t.Fatalf("check embeddings: %v", err)
+	}
+	reasons := make(map[string]string)
+	for _, p := range problems {
+		reasons[string(p.TargetKind)+"/"+p.TargetID] = p.Reason
+	}
+	if len(problems) != 4 ||
+		!strings.HasPrefix(reasons["memory/truncated"], "truncated") ||
+		!strings.Contains(reasons["memory/short"], "dimension 4") ||
+		!strings.Contains(reasons["memory/nan"], "NaN") ||
+		!strings.HasPrefix(reasons["history/"+turn.ID], "truncated") {
+		t.Fatalf("expected the truncated, mismatched, NaN, and history embeddings, got %+v", problems)
+	}
+
+	if broken, err := s.IntegrityCheck(); err != nil || len(broken) != 0 {
+		t.Fatalf("expected a sound database, got %v (%v)", broken, err)
+	}
+
+	for _, p := range problems {
+		if err := s.RequeueEmbedding(p.TargetKind, p.TargetID); err != nil {
+			t.Fatalf("requeue: %v", err)
+		}
+	}
+	if problems, err := s.CheckEmbeddings(); err != nil || len(problems) != 0 {
+		t.Fatalf("expected no problems after requeueing, got %+v (%v)", problems, err)
+	}
+	jobs, err := s.PendingEmbeddings(5, 10)
+	if err != nil {
+		t.Fatalf("pending embeddings: %v", err)
+	}
+	if len(jobs) != 4 {
+		t.Fatalf("expected the 4 rows queued for re-embedding, got %+v", jobs)
+	}
+}
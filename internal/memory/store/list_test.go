@@ -0,0 +1,58 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+func TestListMemoriesPagesNewestFirst(t *testing.T) {
+	s := newTestStore(t)
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, item := range []*memtypes.MemoryItem{
+		{Text: "uses postgres 16", Tags: []string{"Database"}},
+		{Text: "prefers dark mode", Tags: []string{"editor"}},
+		{Text: "atlas db is sharded", Tags: []string{"database", "atlas"}},
+		{Text: "untagged"},
+	} {
+		item.Source = memtypes.SourceExplicit
+		item.CreatedAt = start.AddDate(0, 0, i)
+		if err := s.SaveMemory(item); err != nil {
+			t.Fatalf("save memory: %v", err)
+		}
+	}
+	if err := s.SaveMemory(&memtypes.MemoryItem{Text: "a chunk", Tags: []string{"database"}, Source: memtypes.SourceExplicit, ParentID: "x"}); err != nil {
+		t.Fatalf("save chunk: %v", err)
+	}
+
+	texts := func(memories []memtypes.MemoryItem) []string {
+		var out []string
+		for _, m := range memories {
+			out = append(out, m.Text)
+		}
+		return out
+	}
+
+	page, total, err := s.ListMemories(nil, 2, 1)
+	if err != nil {
+		t.Fatalf("list memories: %v", err)
+	}
+	if total != 4 || len(page) != 2 || page[0].Text != "atlas db is sharded" || page[1].Text != "prefers dark mode" {
+		t.Fatalf("expected the second and third newest of 4, got %v of %d", texts(page), total)
+	}
+
+	page, total, err = s.ListMemories([]string{"DATABASE"}, 10, 0)
+	if err != nil {
+		t.Fatalf("list memories: %v", err)
+	}
+	if total != 2 || len(page) != 2 || page[0].Text != "atlas db is sharded" || page[1].Text != "uses postgres 16" {
+		t.Fatalf("expected the database memories, ignoring case and chunks, got %v of %d", texts(page), total)
+	}
+
+	page, total, err = s.ListMemories([]string{"database"}, 10, 5)
+	if err != nil || total != 2 || len(page) != 0 {
+		t.Fatalf("expected an empty page past the end, got %v of %d (%v)", texts(page), total, err)
+	}
+}
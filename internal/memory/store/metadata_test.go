@@ -0,0 +1,38 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+func TestMemoryMetadataRoundTrips(t *testing.T) {
+	s := newTestStore(t)
+
+	with := &memtypes.MemoryItem{
+		Text:     "prefers tabs",
+		Source:   memtypes.SourceImported,
+		Metadata: map[string]string{"mem0_id": "abc", "user_id": "alice"},
+	}
+	without := &memtypes.MemoryItem{Text: "no metadata", Source: memtypes.SourceExplicit}
+	for _, item := range []*memtypes.MemoryItem{with, without} {
+		if err := s.SaveMemory(item); err != nil {
+			t.Fatalf("save memory: %v", err)
+		}
+	}
+
+	memories, err := s.GetAllMemories()
+	if err != nil {
+		t.Fatalf("get all memories: %v", err)
+	}
+	byID := make(map[string]memtypes.MemoryItem)
+	for _, m := range memories {
+		byID[m.ID] = m
+	}
+	if got := byID[with.ID].Metadata; got["mem0_id"] != "abc" || got["user_id"] != "alice" {
+		t.Fatalf("metadata not preserved: %+v", got)
+	}
+	if got := byID[without.ID].Metadata; got != nil {
+		t.Fatalf("expected nil metadata, got %+v", got)
+	}
+}
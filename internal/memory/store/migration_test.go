@@ -64,4 +64,60 @@ func TestNewStoreWithDB_MigratesDecayColumns(t *testing.T) {
 	if memory.LastRetrievedAt != nil {
 		t.Fatalf("expected nil last retrieved at for legacy memory, got %v", memory.LastRetrievedAt)
 	}
+	if memory.TimesRetrieved != 0 {
+		t.Fatalf("expected legacy memory never retrieved, got %d", memory.TimesRetrieved)
+	}
+	if memory.Kind != memtypes.KindFact {
+		t.Fatalf("expected legacy memory to default to fact kind, got %q", memory.Kind)
+	}
+}
+
+func TestNewStoreWithDB_BackfillsDocumentChunkKind(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(`
+		CREATE TABLE memories (
+			id TEXT PRIMARY KEY,
+			text TEXT NOT NULL,
+			tags TEXT,
+			source TEXT NOT NULL,
+			created_at INTEGER NOT NULL,
+			confidence REAL NOT NULL,
+			stability_days REAL NOT NULL,
+			last_retrieved_at INTEGER,
+			provider TEXT NOT NULL,
+			model_id TEXT NOT NULL,
+			dim INTEGER NOT NULL,
+			embedding BLOB NOT NULL,
+			source_path TEXT,
+			chunk_index INTEGER
+		);`); err != nil {
+		t.Fatalf("create pre-kind schema: %v", err)
+	}
+	if _, err := db.Exec(
+		`INSERT INTO memories (id, text, tags, source, created_at, confidence, stability_days, provider, model_id, dim, embedding, source_path, chunk_index) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		"chunk-1", "ingested chunk", "[]", string(memtypes.SourceDocument), time.Now().Unix(), 0.9, 30, "openai", "test-model", 2, memutils.VectorToBytes([]float32{1, 0}), "/docs/a.md", 0,
+	); err != nil {
+		t.Fatalf("insert document chunk: %v", err)
+	}
+
+	memStore, err := NewStoreWithDB(db)
+	if err != nil {
+		t.Fatalf("new store with db: %v", err)
+	}
+
+	memories, err := memStore.GetAllMemories()
+	if err != nil {
+		t.Fatalf("get all memories: %v", err)
+	}
+	if len(memories) != 1 || memories[0].Kind != memtypes.KindDocumentChunk {
+		t.Fatalf("expected ingested chunk to be backfilled as document-chunk, got %+v", memories)
+	}
+	if memories[0].SourcePath != "/docs/a.md" {
+		t.Fatalf("unexpected source path: %q", memories[0].SourcePath)
+	}
 }
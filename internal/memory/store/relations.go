@@ -0,0 +1,76 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+type MemoryRelation = memtypes.MemoryRelation
+
+// AddRelation records that the memory fromID relates to toID as kind.
+// Adding a relation that already exists is a no-op.
+func (s *Store) AddRelation(fromID, toID string, kind memtypes.RelationKind) error {
+	if fromID == toID {
+		return fmt.Errorf("a memory cannot relate to itself")
+	}
+	for _, id := range []string{fromID, toID} {
+		if err := s.CheckMemoryExists(id); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.db.Exec(insertMemoryRelationSQL, fromID, toID, string(kind), time.Now().Unix()); err != nil {
+		return fmt.Errorf("failed to save memory relation: %w", err)
+	}
+	return nil
+}
+
+// RemoveRelation deletes a relation and reports whether it existed.
+func (s *Store) RemoveRelation(fromID, toID string, kind memtypes.RelationKind) (bool, error) {
+	result, err := s.db.Exec(deleteMemoryRelationSQL, fromID, toID, string(kind))
+	if err != nil {
+		return false, fmt.Errorf("failed to delete memory relation: %w", err)
+	}
+	removed, err := result.RowsAffected()
+	return removed > 0, err
+}
+
+// CheckMemoryExists returns ErrMemoryNotFound unless memory id exists.
+func (s *Store) CheckMemoryExists(id string) error {
+	var confidence, stabilityDays float64
+	err := s.db.QueryRow(selectMemoryDecaySQL, id).Scan(&confidence, &stabilityDays)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %s", ErrMemoryNotFound, id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read memory: %w", err)
+	}
+	return nil
+}
+
+// MemoryRelations returns the relations from and to a memory, oldest first.
+func (s *Store) MemoryRelations(memoryID string) ([]MemoryRelation, error) {
+	rows, err := s.db.Query(selectMemoryRelationsSQL, memoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query memory relations: %w", err)
+	}
+	defer rows.Close()
+
+	var relations []MemoryRelation
+	for rows.Next() {
+		var relation MemoryRelation
+		var kind string
+		var createdAtUnix int64
+		if err := rows.Scan(&relation.FromID, &relation.ToID, &kind, &createdAtUnix); err != nil {
+			return nil, fmt.Errorf("failed to scan memory relation row: %w", err)
+		}
+		relation.Kind = memtypes.RelationKind(kind)
+		relation.CreatedAt = time.Unix(createdAtUnix, 0)
+		relations = append(relations, relation)
+	}
+	return relations, rows.Err()
+}
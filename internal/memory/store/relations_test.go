@@ -0,0 +1,55 @@
+package store
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+func TestMemoryRelations(t *testing.T) {
+	s := newTestStore(t)
+
+	old := &memtypes.MemoryItem{Text: "deploys go out on Fridays", Source: memtypes.SourceExplicit}
+	current := &memtypes.MemoryItem{Text: "deploys go out on Tuesdays", Source: memtypes.SourceExplicit}
+	for _, item := range []*memtypes.MemoryItem{old, current} {
+		if err := s.SaveMemory(item); err != nil {
+			t.Fatalf("save memory: %v", err)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := s.AddRelation(current.ID, old.ID, memtypes.RelationSupersedes); err != nil {
+			t.Fatalf("add relation: %v", err)
+		}
+	}
+	for _, id := range []string{old.ID, current.ID} {
+		relations, err := s.MemoryRelations(id)
+		if err != nil {
+			t.Fatalf("memory relations: %v", err)
+		}
+		if len(relations) != 1 || relations[0].FromID != current.ID || relations[0].ToID != old.ID || relations[0].Kind != memtypes.RelationSupersedes {
+			t.Fatalf("expected one supersedes relation for %s, got %+v", id, relations)
+		}
+	}
+
+	if err := s.AddRelation(current.ID, "missing", memtypes.RelationRelatesTo); !errors.Is(err, ErrMemoryNotFound) {
+		t.Fatalf("expected ErrMemoryNotFound, got %v", err)
+	}
+	if err := s.AddRelation(current.ID, current.ID, memtypes.RelationRelatesTo); err == nil {
+		t.Fatal("expected an error relating a memory to itself")
+	}
+
+	if removed, err := s.RemoveRelation(current.ID, old.ID, memtypes.RelationRelatesTo); err != nil || removed {
+		t.Fatalf("expected no relates-to relation to remove, got %v (%v)", removed, err)
+	}
+	if err := s.AddRelation(old.ID, current.ID, memtypes.RelationRelatesTo); err != nil {
+		t.Fatalf("add relation: %v", err)
+	}
+	if err := s.DeleteMemory(old.ID); err != nil {
+		t.Fatalf("delete memory: %v", err)
+	}
+	if relations, err := s.MemoryRelations(current.ID); err != nil || len(relations) != 0 {
+		t.Fatalf("expected relations to be dropped with the memory, got %+v (%v)", relations, err)
+	}
+}
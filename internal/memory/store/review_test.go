@@ -0,0 +1,64 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+func TestPendingReviewMemoriesAreNotSearchedUntilApproved(t *testing.T) {
+	s := newTestStore(t)
+
+	item := &memtypes.MemoryItem{
+		Text:          "the user deploys with argo",
+		Source:        memtypes.SourceExtracted,
+		PendingReview: true,
+		Pinned:        true,
+		Embedding:     []float32{1, 0},
+		Dim:           2,
+	}
+	if err := s.SaveMemory(item); err != nil {
+		t.Fatalf("save memory: %v", err)
+	}
+
+	pending, err := s.PendingReviewMemories()
+	if err != nil {
+		t.Fatalf("pending review: %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != item.ID || !pending[0].PendingReview {
+		t.Fatalf("expected the memory in the review queue, got %+v", pending)
+	}
+
+	assertSearchable := func(want bool) {
+		t.Helper()
+		fts, err := s.SearchMemoriesFTS("argo", 10)
+		if err != nil {
+			t.Fatalf("fts search: %v", err)
+		}
+		vector, err := s.SearchMemories([]float32{1, 0}, 10, 0.1)
+		if err != nil {
+			t.Fatalf("vector search: %v", err)
+		}
+		pinned, err := s.PinnedMemories()
+		if err != nil {
+			t.Fatalf("pinned memories: %v", err)
+		}
+		if got := len(fts) == 1 && len(vector) == 1 && len(pinned) == 1; got != want {
+			t.Fatalf("searchable = %v, want %v (fts %d, vector %d, pinned %d)", got, want, len(fts), len(vector), len(pinned))
+		}
+	}
+	assertSearchable(false)
+
+	approved, err := s.ApproveMemory(item.ID)
+	if err != nil || !approved {
+		t.Fatalf("approve: %v, %v", approved, err)
+	}
+	assertSearchable(true)
+
+	if again, err := s.ApproveMemory(item.ID); err != nil || again {
+		t.Fatalf("expected a second approval to find nothing pending, got %v, %v", again, err)
+	}
+	if pending, _ := s.PendingReviewMemories(); len(pending) != 0 {
+		t.Fatalf("expected an empty review queue, got %+v", pending)
+	}
+}
@@ -0,0 +1,56 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+func TestMemoryRevision(t *testing.T) {
+	s := newTestStore(t)
+
+	revision := func() int64 {
+		t.Helper()
+		r, err := s.MemoryRevision()
+		if err != nil {
+			t.Fatalf("memory revision: %v", err)
+		}
+		return r
+	}
+
+	before := revision()
+	item := &memtypes.MemoryItem{Text: "deploys go out on Tuesdays", Source: memtypes.SourceExplicit}
+	if err := s.SaveMemory(item); err != nil {
+		t.Fatalf("save memory: %v", err)
+	}
+	saved := revision()
+	if saved == before {
+		t.Fatal("expected saving a memory to change the revision")
+	}
+
+	now := time.Now()
+	if err := s.RecordMemoryRetrievals([]string{item.ID}, now); err != nil {
+		t.Fatalf("record retrievals: %v", err)
+	}
+	if err := s.UpdateMemoryDecay(item.ID, item.Confidence, item.StabilityDays*2, &now); err != nil {
+		t.Fatalf("update decay: %v", err)
+	}
+	if got := revision(); got != saved {
+		t.Fatalf("expected retrievals to leave the revision alone, got %d, want %d", got, saved)
+	}
+
+	if _, err := s.SetMemorySuppressed(item.ID, true); err != nil {
+		t.Fatalf("suppress: %v", err)
+	}
+	suppressed := revision()
+	if suppressed == saved {
+		t.Fatal("expected suppressing a memory to change the revision")
+	}
+	if err := s.DeleteMemory(item.ID); err != nil {
+		t.Fatalf("delete memory: %v", err)
+	}
+	if revision() == suppressed {
+		t.Fatal("expected deleting a memory to change the revision")
+	}
+}
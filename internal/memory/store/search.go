@@ -0,0 +1,76 @@
+package store
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+// SearchAll runs a full-text search over memories and history together and
+// returns the top K hits of both, best first. query is plain text: each word
+// is matched as a literal term, and a hit needs any one of them. Suppressed
+// memories and memories pending review are left out.
+func (s *Store) SearchAll(query string, topK int) ([]SearchHit, error) {
+	match := ftsMatchQuery(query)
+	if match == "" || topK <= 0 {
+		return nil, nil
+	}
+
+	memories, err := s.SearchMemoriesFTS(match, topK)
+	if err != nil {
+		return nil, err
+	}
+	history, err := s.SearchHistory(match, topK)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]SearchHit, 0, len(memories)+len(history))
+	for _, m := range memories {
+		hits = append(hits, SearchHit{
+			Kind:      memtypes.SearchHitMemory,
+			ID:        m.Item.ID,
+			Text:      m.Item.Text,
+			Snippet:   m.Snippet,
+			Rank:      m.Rank,
+			CreatedAt: m.Item.CreatedAt,
+			Tags:      m.Item.Tags,
+		})
+	}
+	for _, h := range history {
+		hits = append(hits, SearchHit{
+			Kind:      memtypes.SearchHitHistory,
+			ID:        h.Item.ID,
+			Text:      h.Item.Content,
+			Snippet:   h.Snippet,
+			Rank:      h.Rank,
+			CreatedAt: h.Item.CreatedAt,
+			Role:      h.Item.Role,
+			SessionID: h.Item.SessionID,
+		})
+	}
+
+	// Both tables rank with bm25, so their ranks interleave directly; newer
+	// hits break ties.
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Rank != hits[j].Rank {
+			return hits[i].Rank < hits[j].Rank
+		}
+		return hits[i].CreatedAt.After(hits[j].CreatedAt)
+	})
+	if len(hits) > topK {
+		hits = hits[:topK]
+	}
+	return hits, nil
+}
+
+// ftsMatchQuery quotes each word of text as an FTS5 string, so operators and
+// punctuation in it are matched literally, and joins them with OR.
+func ftsMatchQuery(text string) string {
+	var terms []string
+	for _, word := range strings.Fields(text) {
+		terms = append(terms, `"`+strings.ReplaceAll(word, `"`, `""`)+`"`)
+	}
+	return strings.Join(terms, " OR ")
+}
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+func TestSearchAllInterleavesMemoriesAndHistory(t *testing.T) {
+	s := newTestStore(t)
+
+	memory := &memtypes.MemoryItem{Text: "The Atlas launch moved to March", Tags: []string{"atlas"}, Source: memtypes.SourceExplicit, Provider: "fake", ModelID: "fake"}
+	hidden := &memtypes.MemoryItem{Text: "Atlas launch gossip", Source: memtypes.SourceExplicit, Provider: "fake", ModelID: "fake", Suppressed: true}
+	unrelated := &memtypes.MemoryItem{Text: "Prefers dark mode", Source: memtypes.SourceExplicit, Provider: "fake", ModelID: "fake"}
+	for _, item := range []*memtypes.MemoryItem{memory, hidden, unrelated} {
+		if err := s.SaveMemory(item); err != nil {
+			t.Fatalf("save memory: %v", err)
+		}
+	}
+	turn := &memtypes.HistoryItem{Role: "user", Content: "when is the atlas launch?", SessionID: "s1"}
+	if err := s.SaveHistory(turn); err != nil {
+		t.Fatalf("save history: %v", err)
+	}
+
+	// Quotes and FTS operators in the query are matched literally.
+	hits, err := s.SearchAll(`atlas" launch NOT*`, 10)
+	if err != nil {
+		t.Fatalf("search all: %v", err)
+	}
+	if len(hits) != 2 {
+		t.Fatalf("expected a memory and a history hit, got %+v", hits)
+	}
+
+	byKind := make(map[memtypes.SearchHitKind]SearchHit)
+	for i, hit := range hits {
+		byKind[hit.Kind] = hit
+		if i > 0 && hit.Rank < hits[i-1].Rank {
+			t.Fatalf("hits not ordered by rank: %+v", hits)
+		}
+	}
+	if got := byKind[memtypes.SearchHitMemory]; got.ID != memory.ID || got.Snippet == "" || len(got.Tags) != 1 {
+		t.Fatalf("unexpected memory hit: %+v", got)
+	}
+	if got := byKind[memtypes.SearchHitHistory]; got.ID != turn.ID || got.Role != "user" || got.SessionID != "s1" {
+		t.Fatalf("unexpected history hit: %+v", got)
+	}
+
+	top, err := s.SearchAll("atlas launch", 1)
+	if err != nil {
+		t.Fatalf("search all: %v", err)
+	}
+	if len(top) != 1 || top[0].ID != hits[0].ID {
+		t.Fatalf("expected top K to keep the best hit, got %+v", top)
+	}
+
+	if empty, err := s.SearchAll("   ", 10); err != nil || empty != nil {
+		t.Fatalf("expected no hits for a blank query, got %v, %v", empty, err)
+	}
+}
@@ -0,0 +1,166 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+type Session = memtypes.Session
+
+// EnsureSession records a session if it isn't known yet.
+func (s *Store) EnsureSession(id string) error {
+	if _, err := s.db.Exec(insertSessionSQL, id, nil, nil, time.Now().Unix()); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+// GetSession returns the session with the given ID, or nil if it is unknown.
+func (s *Store) GetSession(id string) (*Session, error) {
+	rows, err := s.db.Query(selectSessionSQL, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
+	defer rows.Close()
+
+	sessions, err := scanSessions(rows)
+	if err != nil || len(sessions) == 0 {
+		return nil, err
+	}
+	return &sessions[0], nil
+}
+
+// RecentSessions returns up to limit sessions, the most recently active first.
+func (s *Store) RecentSessions(limit int) ([]Session, error) {
+	rows, err := s.db.Query(selectRecentSessionsSQL, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer rows.Close()
+	return scanSessions(rows)
+}
+
+// SetSessionTitle names a session, recording it first if it isn't known yet.
+func (s *Store) SetSessionTitle(id, title string) error {
+	if err := s.EnsureSession(id); err != nil {
+		return err
+	}
+	if _, err := s.db.Exec(updateSessionTitleSQL, title, id); err != nil {
+		return fmt.Errorf("failed to save session title: %w", err)
+	}
+	return nil
+}
+
+// scanSessions reads rows of (id, parent_id, fork_turn_id, created_at, title).
+func scanSessions(rows *sql.Rows) ([]Session, error) {
+	var sessions []Session
+	for rows.Next() {
+		var session Session
+		var parentID, forkTurnID, title sql.NullString
+		var createdAtUnix int64
+		if err := rows.Scan(&session.ID, &parentID, &forkTurnID, &createdAtUnix, &title); err != nil {
+			return nil, fmt.Errorf("failed to scan session row: %w", err)
+		}
+		session.ParentID = parentID.String
+		session.ForkTurnID = forkTurnID.String
+		session.CreatedAt = time.Unix(createdAtUnix, 0)
+		session.Title = title.String
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// GetHistoryItem returns the history turn with the given ID, or nil if it is unknown.
+func (s *Store) GetHistoryItem(id string) (*HistoryItem, error) {
+	rows, err := s.db.Query(selectHistoryItemSQL, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history item: %w", err)
+	}
+	defer rows.Close()
+
+	items, err := scanHistory(rows)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+// ForkSession creates a new session that branches off after the given turn.
+// The branch's parent is the session that owns the turn.
+func (s *Store) ForkSession(turnID string) (Session, error) {
+	turn, err := s.GetHistoryItem(turnID)
+	if err != nil {
+		return Session{}, err
+	}
+	if turn == nil {
+		return Session{}, fmt.Errorf("turn %s not found", turnID)
+	}
+	if turn.SessionID == "" {
+		return Session{}, fmt.Errorf("turn %s does not belong to a session", turnID)
+	}
+
+	session := Session{
+		ID:         uuid.New().String(),
+		ParentID:   turn.SessionID,
+		ForkTurnID: turn.ID,
+		CreatedAt:  time.Now(),
+	}
+	if _, err := s.db.Exec(insertSessionSQL, session.ID, session.ParentID, session.ForkTurnID, session.CreatedAt.Unix()); err != nil {
+		return Session{}, fmt.Errorf("failed to save session: %w", err)
+	}
+	return session, nil
+}
+
+// GetConversation returns up to limit of the most recent turns of a session,
+// oldest first. For a forked session the parent's turns up to the fork point
+// come first, following the chain of forks.
+func (s *Store) GetConversation(sessionID string, limit int) ([]HistoryItem, error) {
+	items, err := s.GetSessionHistory(sessionID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{sessionID: true}
+	for len(items) < limit {
+		session, err := s.GetSession(sessionID)
+		if err != nil {
+			return nil, err
+		}
+		if session == nil || session.ParentID == "" || seen[session.ParentID] {
+			break
+		}
+		seen[session.ParentID] = true
+
+		earlier, err := s.sessionHistoryUntil(session.ParentID, session.ForkTurnID, limit-len(items))
+		if err != nil {
+			return nil, err
+		}
+		items = append(earlier, items...)
+		sessionID = session.ParentID
+	}
+
+	return items, nil
+}
+
+// sessionHistoryUntil returns up to limit turns of a session ending with turnID, oldest first.
+func (s *Store) sessionHistoryUntil(sessionID, turnID string, limit int) ([]HistoryItem, error) {
+	rows, err := s.db.Query(selectSessionHistoryUntilSQL, sessionID, turnID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query session history: %w", err)
+	}
+	defer rows.Close()
+
+	items, err := scanHistory(rows)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+		items[i], items[j] = items[j], items[i]
+	}
+	return items, nil
+}
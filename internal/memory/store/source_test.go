@@ -0,0 +1,55 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+func TestReplaceSourceMemoriesKeepsOldChunksOnFailure(t *testing.T) {
+	s := newTestStore(t)
+	chunk := func(id, text string, i int) MemoryItem {
+		return MemoryItem{ID: id, Text: text, Source: memtypes.SourceDocument, Kind: memtypes.KindDocumentChunk, SourcePath: "/notes.md", ChunkIndex: i}
+	}
+
+	if err := s.ReplaceSourceMemories("/notes.md", []MemoryItem{chunk("old-0", "first version", 0), chunk("old-1", "first version, continued", 1)}, false); err != nil {
+		t.Fatalf("ingest: %v", err)
+	}
+
+	// The second chunk reuses an ID, so its insert fails after the delete.
+	err := s.ReplaceSourceMemories("/notes.md", []MemoryItem{chunk("new-0", "second version", 0), chunk("new-0", "second version, continued", 1)}, true)
+	if err == nil {
+		t.Fatal("expected the duplicate chunk to fail")
+	}
+
+	memories, err := s.GetAllMemories()
+	if err != nil {
+		t.Fatalf("get all memories: %v", err)
+	}
+	if len(memories) != 2 {
+		t.Fatalf("expected the 2 old chunks to be kept, got %d memories", len(memories))
+	}
+	for _, m := range memories {
+		if m.ID != "old-0" && m.ID != "old-1" {
+			t.Fatalf("expected only old chunks, got %s", m.ID)
+		}
+	}
+	jobs, err := s.PendingEmbeddings(5, 10)
+	if err != nil {
+		t.Fatalf("pending embeddings: %v", err)
+	}
+	if len(jobs) != 0 {
+		t.Fatalf("expected no queued embeddings, got %d", len(jobs))
+	}
+
+	if err := s.ReplaceSourceMemories("/notes.md", []MemoryItem{chunk("new-0", "second version", 0)}, true); err != nil {
+		t.Fatalf("re-ingest: %v", err)
+	}
+	memories, err = s.GetAllMemories()
+	if err != nil || len(memories) != 1 || memories[0].ID != "new-0" {
+		t.Fatalf("expected the new chunk alone, got %+v (%v)", memories, err)
+	}
+	if jobs, err = s.PendingEmbeddings(5, 10); err != nil || len(jobs) != 1 {
+		t.Fatalf("expected the new chunk's embedding queued, got %d (%v)", len(jobs), err)
+	}
+}
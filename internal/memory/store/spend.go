@@ -0,0 +1,30 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+type SpendEntry = memtypes.SpendEntry
+
+// RecordSpend saves the usage and cost of a chat request.
+func (s *Store) RecordSpend(entry SpendEntry) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	if _, err := s.db.Exec(insertSpendSQL, entry.Provider, entry.ModelID, entry.InputTokens, entry.OutputTokens, entry.CostUSD, entry.CreatedAt.Unix()); err != nil {
+		return fmt.Errorf("failed to record spend: %w", err)
+	}
+	return nil
+}
+
+// SpendSince returns the total cost in USD of requests made at or after since.
+func (s *Store) SpendSince(since time.Time) (float64, error) {
+	var total float64
+	if err := s.db.QueryRow(sumSpendSinceSQL, since.Unix()).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to sum spend: %w", err)
+	}
+	return total, nil
+}
@@ -13,22 +13,168 @@ var (
 	insertMemorySQL string
 	//go:embed sql/queries/select_all_memories.sql
 	selectAllMemoriesSQL string
+	//go:embed sql/queries/select_searchable_memories.sql
+	selectSearchableMemoriesSQL string
 	//go:embed sql/queries/delete_memory.sql
 	deleteMemorySQL string
+	//go:embed sql/queries/delete_memory_chunks.sql
+	deleteMemoryChunksSQL string
+	//go:embed sql/queries/select_archive_candidates.sql
+	selectArchiveCandidatesSQL string
+	//go:embed sql/queries/archive_memory.sql
+	archiveMemorySQL string
+	//go:embed sql/queries/select_archived_memories.sql
+	selectArchivedMemoriesSQL string
+	//go:embed sql/queries/restore_memory.sql
+	restoreMemorySQL string
+	//go:embed sql/queries/delete_archived_memory.sql
+	deleteArchivedMemorySQL string
+	//go:embed sql/queries/count_archived_memories.sql
+	countArchivedMemoriesSQL string
+	//go:embed sql/queries/clear_archived_memories.sql
+	clearArchivedMemoriesSQL string
+	//go:embed sql/queries/delete_memories_by_source_path.sql
+	deleteMemoriesBySourcePathSQL string
 	//go:embed sql/queries/update_memory_embedding.sql
 	updateMemoryEmbeddingSQL string
 	//go:embed sql/queries/update_memory_decay.sql
 	updateMemoryDecaySQL string
+	//go:embed sql/queries/record_memory_retrieval.sql
+	recordMemoryRetrievalSQL string
+	//go:embed sql/queries/update_memory_confidence.sql
+	updateMemoryConfidenceSQL string
+	//go:embed sql/queries/select_memory_decay.sql
+	selectMemoryDecaySQL string
+	//go:embed sql/queries/insert_memory_feedback.sql
+	insertMemoryFeedbackSQL string
+	//go:embed sql/queries/count_memory_feedback.sql
+	countMemoryFeedbackSQL string
+	//go:embed sql/queries/update_memory_pinned.sql
+	updateMemoryPinnedSQL string
+	//go:embed sql/queries/update_memory_suppressed.sql
+	updateMemorySuppressedSQL string
+	//go:embed sql/queries/select_pinned_memories.sql
+	selectPinnedMemoriesSQL string
+	//go:embed sql/queries/select_pending_review_memories.sql
+	selectPendingReviewMemoriesSQL string
+	//go:embed sql/queries/approve_memory.sql
+	approveMemorySQL string
+	//go:embed sql/queries/update_memory_content.sql
+	updateMemoryContentSQL string
 	//go:embed sql/queries/search_memories_fts.sql
 	searchMemoriesFTSSQL string
 	//go:embed sql/queries/clear_memories.sql
 	clearMemoriesSQL string
+	//go:embed sql/queries/count_memories.sql
+	countMemoriesSQL string
+	//go:embed sql/queries/select_memories_page.sql
+	selectMemoriesPageSQL string
+	//go:embed sql/queries/count_memories_tagged.sql
+	countMemoriesTaggedSQL string
+	//go:embed sql/queries/select_recent_memory_texts.sql
+	selectRecentMemoryTextsSQL string
+	//go:embed sql/queries/select_top_tags.sql
+	selectTopTagsSQL string
 	//go:embed sql/queries/insert_history.sql
 	insertHistorySQL string
 	//go:embed sql/queries/search_history_fts.sql
 	searchHistoryFTSSQL string
 	//go:embed sql/queries/select_recent_history.sql
 	selectRecentHistorySQL string
+	//go:embed sql/queries/select_session_history.sql
+	selectSessionHistorySQL string
+	//go:embed sql/queries/select_history_since.sql
+	selectHistorySinceSQL string
+	//go:embed sql/queries/select_recent_prompts.sql
+	selectRecentPromptsSQL string
+	//go:embed sql/queries/select_last_prompt.sql
+	selectLastPromptSQL string
+	//go:embed sql/queries/select_history_item.sql
+	selectHistoryItemSQL string
+	//go:embed sql/queries/select_session_history_until.sql
+	selectSessionHistoryUntilSQL string
+	//go:embed sql/queries/insert_session.sql
+	insertSessionSQL string
+	//go:embed sql/queries/select_session.sql
+	selectSessionSQL string
+	//go:embed sql/queries/select_recent_sessions.sql
+	selectRecentSessionsSQL string
+	//go:embed sql/queries/update_session_title.sql
+	updateSessionTitleSQL string
 	//go:embed sql/queries/clear_history.sql
 	clearHistorySQL string
+	//go:embed sql/queries/count_history.sql
+	countHistorySQL string
+	//go:embed sql/queries/update_history_embedding.sql
+	updateHistoryEmbeddingSQL string
+	//go:embed sql/queries/insert_entity.sql
+	insertEntitySQL string
+	//go:embed sql/queries/select_entity.sql
+	selectEntitySQL string
+	//go:embed sql/queries/select_all_entities.sql
+	selectAllEntitiesSQL string
+	//go:embed sql/queries/link_memory_entity.sql
+	linkMemoryEntitySQL string
+	//go:embed sql/queries/select_memory_entities.sql
+	selectMemoryEntitiesSQL string
+	//go:embed sql/queries/select_entity_memory_ids.sql
+	selectEntityMemoryIDsSQL string
+	//go:embed sql/queries/select_memories_by_entity.sql
+	selectMemoriesByEntitySQL string
+	//go:embed sql/queries/enqueue_embedding.sql
+	enqueueEmbeddingSQL string
+	//go:embed sql/queries/select_pending_embeddings.sql
+	selectPendingEmbeddingsSQL string
+	//go:embed sql/queries/delete_embedding_job.sql
+	deleteEmbeddingJobSQL string
+	//go:embed sql/queries/fail_embedding_job.sql
+	failEmbeddingJobSQL string
+	//go:embed sql/queries/select_stored_embeddings.sql
+	selectStoredEmbeddingsSQL string
+	//go:embed sql/queries/clear_memory_embedding.sql
+	clearMemoryEmbeddingSQL string
+	//go:embed sql/queries/clear_history_embedding.sql
+	clearHistoryEmbeddingSQL string
+	//go:embed sql/queries/integrity_check.sql
+	integrityCheckSQL string
+	//go:embed sql/queries/mark_memory_needs_reembedding.sql
+	markMemoryNeedsReembeddingSQL string
+	//go:embed sql/queries/count_memories_needing_reembedding.sql
+	countMemoriesNeedingReembeddingSQL string
+	//go:embed sql/queries/count_memories_fts_orphans.sql
+	countMemoriesFTSOrphansSQL string
+	//go:embed sql/queries/count_memories_fts_missing.sql
+	countMemoriesFTSMissingSQL string
+	//go:embed sql/queries/rebuild_memories_fts.sql
+	rebuildMemoriesFTSSQL string
+	//go:embed sql/queries/count_embedding_dims.sql
+	countEmbeddingDimsSQL string
+	//go:embed sql/queries/select_model_embedding_dim.sql
+	selectModelEmbeddingDimSQL string
+	//go:embed sql/queries/select_memories_with_other_dim.sql
+	selectMemoriesWithOtherDimSQL string
+	//go:embed sql/queries/insert_spend.sql
+	insertSpendSQL string
+	//go:embed sql/queries/sum_spend_since.sql
+	sumSpendSinceSQL string
+	//go:embed sql/queries/insert_memory_relation.sql
+	insertMemoryRelationSQL string
+	//go:embed sql/queries/delete_memory_relation.sql
+	deleteMemoryRelationSQL string
+	//go:embed sql/queries/select_memory_relations.sql
+	selectMemoryRelationsSQL string
+	//go:embed sql/queries/select_memory_revision.sql
+	selectMemoryRevisionSQL string
+	//go:embed sql/queries/select_vector_index_state.sql
+	selectVectorIndexStateSQL string
+	//go:embed sql/queries/select_vector_log_embeddings.sql
+	selectVectorLogEmbeddingsSQL string
+	//go:embed sql/queries/select_memory_embeddings.sql
+	selectMemoryEmbeddingsSQL string
+	//go:embed sql/queries/count_embedded_memories.sql
+	countEmbeddedMemoriesSQL string
+	//go:embed sql/queries/delete_vector_log.sql
+	deleteVectorLogSQL string
+	//go:embed sql/queries/select_memories_by_ids.sql
+	selectMemoriesByIDsSQL string
 )
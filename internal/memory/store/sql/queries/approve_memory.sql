@@ -0,0 +1,3 @@
+UPDATE memories
+SET pending_review = 0
+WHERE id = ? AND pending_review = 1;
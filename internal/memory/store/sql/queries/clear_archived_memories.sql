@@ -0,0 +1 @@
+DELETE FROM archived_memories;
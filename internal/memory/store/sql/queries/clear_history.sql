@@ -1 +1,2 @@
 DELETE FROM history;
+DELETE FROM sessions;
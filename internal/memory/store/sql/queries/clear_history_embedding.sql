@@ -0,0 +1,3 @@
+UPDATE history
+SET embedding = NULL, dim = NULL
+WHERE id = ?;
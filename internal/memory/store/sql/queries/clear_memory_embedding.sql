@@ -0,0 +1,3 @@
+UPDATE memories
+SET embedding = X'', dim = 0
+WHERE id = ?;
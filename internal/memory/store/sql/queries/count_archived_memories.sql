@@ -0,0 +1 @@
+SELECT COUNT(*) FROM archived_memories WHERE parent_id IS NULL;
@@ -0,0 +1 @@
+SELECT COUNT(*) FROM memories WHERE embedding IS NOT NULL;
@@ -0,0 +1,4 @@
+SELECT dim, COUNT(*)
+FROM memories
+WHERE dim > 0 AND length(embedding) > 0
+GROUP BY dim;
@@ -0,0 +1 @@
+SELECT COUNT(*) FROM memories WHERE parent_id IS NULL;
@@ -0,0 +1 @@
+SELECT COUNT(*) FROM memories WHERE rowid NOT IN (SELECT id FROM memories_fts_docsize);
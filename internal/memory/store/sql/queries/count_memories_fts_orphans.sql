@@ -0,0 +1 @@
+SELECT COUNT(*) FROM memories_fts_docsize WHERE id NOT IN (SELECT rowid FROM memories);
@@ -0,0 +1 @@
+SELECT COUNT(*) FROM memories WHERE needs_reembedding = 1;
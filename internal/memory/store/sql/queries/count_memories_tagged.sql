@@ -0,0 +1,6 @@
+SELECT COUNT(*)
+FROM memories
+WHERE parent_id IS NULL
+  AND (json_array_length(?1) = 0 OR EXISTS (
+      SELECT 1 FROM json_each(memories.tags) tag
+      WHERE lower(tag.value) IN (SELECT lower(value) FROM json_each(?1))));
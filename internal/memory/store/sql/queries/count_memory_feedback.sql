@@ -0,0 +1,3 @@
+SELECT COALESCE(SUM(useful), 0), COALESCE(SUM(1 - useful), 0)
+FROM memory_feedback
+WHERE memory_id = ?;
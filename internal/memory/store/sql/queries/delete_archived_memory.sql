@@ -0,0 +1 @@
+DELETE FROM archived_memories WHERE id = ? OR parent_id = ?;
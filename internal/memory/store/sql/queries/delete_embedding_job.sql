@@ -0,0 +1 @@
+DELETE FROM embedding_queue WHERE id = ?;
@@ -0,0 +1 @@
+DELETE FROM memories WHERE source_path = ?;
@@ -0,0 +1 @@
+DELETE FROM memories WHERE parent_id = ?;
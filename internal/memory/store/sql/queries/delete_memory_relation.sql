@@ -0,0 +1 @@
+DELETE FROM memory_relations WHERE from_id = ? AND to_id = ? AND kind = ?;
@@ -0,0 +1 @@
+DELETE FROM memory_vector_log WHERE seq <= ?;
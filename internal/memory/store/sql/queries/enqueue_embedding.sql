@@ -0,0 +1,3 @@
+INSERT INTO embedding_queue (target_kind, target_id, attempts, last_error, enqueued_at, next_attempt_at)
+VALUES (?, ?, 0, NULL, ?, 0)
+ON CONFLICT(target_kind, target_id) DO UPDATE SET attempts = 0, last_error = NULL, next_attempt_at = 0;
@@ -0,0 +1,3 @@
+UPDATE embedding_queue
+SET attempts = attempts + 1, last_error = ?, next_attempt_at = ?
+WHERE id = ?;
@@ -0,0 +1,3 @@
+INSERT INTO entities (id, name, kind, normalized, created_at)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT(normalized, kind) DO NOTHING;
@@ -1,2 +1,2 @@
-INSERT INTO history (id, role, content, created_at, session_id)
-VALUES (?, ?, ?, ?, ?);
+INSERT INTO history (id, role, content, created_at, session_id, parent_id, chat_model, interrupted)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?);
@@ -1,2 +1,2 @@
-INSERT INTO memories (id, text, tags, source, created_at, confidence, stability_days, last_retrieved_at, provider, model_id, dim, embedding)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+INSERT INTO memories (id, text, tags, source, created_at, confidence, stability_days, last_retrieved_at, provider, model_id, dim, embedding, source_path, chunk_index, kind, metadata, pinned, suppressed, pending_review, times_retrieved, parent_id)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
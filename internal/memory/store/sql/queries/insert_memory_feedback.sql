@@ -0,0 +1,2 @@
+INSERT INTO memory_feedback (memory_id, useful, created_at)
+VALUES (?, ?, ?);
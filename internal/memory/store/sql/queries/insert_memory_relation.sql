@@ -0,0 +1,2 @@
+INSERT OR IGNORE INTO memory_relations (from_id, to_id, kind, created_at)
+VALUES (?, ?, ?, ?);
@@ -0,0 +1,2 @@
+INSERT OR IGNORE INTO sessions (id, parent_id, fork_turn_id, created_at)
+VALUES (?, ?, ?, ?);
@@ -0,0 +1,2 @@
+INSERT INTO spend (provider, model_id, input_tokens, output_tokens, cost_usd, created_at)
+VALUES (?, ?, ?, ?, ?, ?);
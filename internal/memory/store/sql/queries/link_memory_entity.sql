@@ -0,0 +1,2 @@
+INSERT OR IGNORE INTO memory_entities (memory_id, entity_id)
+VALUES (?, ?);
@@ -0,0 +1,3 @@
+UPDATE memories
+SET needs_reembedding = 1
+WHERE id = ?;
@@ -0,0 +1,3 @@
+UPDATE memories
+SET times_retrieved = times_retrieved + 1, last_retrieved_at = ?
+WHERE id = ?;
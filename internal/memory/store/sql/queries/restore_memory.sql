@@ -0,0 +1,5 @@
+INSERT INTO memories (id, text, tags, source, created_at, confidence, stability_days, last_retrieved_at, provider, model_id, dim, embedding, source_path, chunk_index, kind, metadata, pinned, suppressed, pending_review, times_retrieved, parent_id)
+SELECT id, text, tags, source, created_at, confidence, stability_days, last_retrieved_at, provider, model_id, dim, embedding, source_path, chunk_index, kind, metadata, pinned, suppressed, pending_review, times_retrieved, parent_id
+FROM archived_memories
+WHERE (id = ? AND parent_id IS NULL) OR parent_id = ?
+ORDER BY parent_id IS NOT NULL, chunk_index;
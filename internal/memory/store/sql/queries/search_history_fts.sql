@@ -4,5 +4,6 @@ SELECT h.id, h.role, h.content, h.created_at, h.session_id,
 FROM history h
 JOIN history_fts fts ON h.rowid = fts.rowid
 WHERE history_fts MATCH ?
+  AND h.created_at >= ? AND h.created_at < ?
 ORDER BY rank
 LIMIT ?;
@@ -1,10 +1,13 @@
 SELECT m.id, m.text, m.tags, m.source, m.created_at,
        m.confidence, m.stability_days, m.last_retrieved_at,
        m.provider, m.model_id, m.dim, m.embedding,
+       m.source_path, m.chunk_index, m.kind, m.metadata, m.pinned, m.suppressed, m.pending_review, m.times_retrieved, m.parent_id,
        snippet(memories_fts, 0, '>>>', '<<<', '...', 32) as snippet,
        rank
 FROM memories m
 JOIN memories_fts fts ON m.rowid = fts.rowid
 WHERE memories_fts MATCH ?
+  AND m.parent_id IS NULL AND m.suppressed = 0 AND m.pending_review = 0
+  AND m.created_at >= ? AND m.created_at < ?
 ORDER BY rank
 LIMIT ?;
@@ -0,0 +1,6 @@
+SELECT id, text, tags, source, created_at, confidence, stability_days, last_retrieved_at, provider, model_id, dim, embedding, source_path, chunk_index, kind, metadata, pinned, suppressed, pending_review, times_retrieved, parent_id
+FROM memories
+WHERE parent_id IS NULL AND pinned = 0 AND pending_review = 0
+  AND confidence < ?
+  AND COALESCE(last_retrieved_at, created_at) < ?
+ORDER BY COALESCE(last_retrieved_at, created_at);
@@ -0,0 +1,3 @@
+SELECT id, name, kind
+FROM entities
+WHERE normalized = ? AND kind = ?;
@@ -0,0 +1 @@
+SELECT memory_id FROM memory_entities WHERE entity_id = ?;
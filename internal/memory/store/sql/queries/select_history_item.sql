@@ -0,0 +1,3 @@
+SELECT id, role, content, created_at, session_id, parent_id, chat_model, interrupted
+FROM history
+WHERE id = ?;
@@ -0,0 +1,4 @@
+SELECT id, role, content, created_at, session_id, parent_id, chat_model, interrupted
+FROM history
+WHERE created_at >= ?
+ORDER BY created_at ASC, rowid ASC;
@@ -0,0 +1,6 @@
+SELECT DISTINCT m.id, m.text, m.tags, m.source, m.created_at, m.confidence, m.stability_days, m.last_retrieved_at, m.provider, m.model_id, m.dim, m.embedding, m.source_path, m.chunk_index, m.kind, m.metadata, m.pinned, m.suppressed, m.pending_review, m.times_retrieved, m.parent_id
+FROM memories m
+JOIN memory_entities me ON me.memory_id = m.id
+JOIN entities e ON e.id = me.entity_id
+WHERE e.normalized = ?
+ORDER BY m.created_at DESC;
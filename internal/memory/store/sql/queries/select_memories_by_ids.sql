@@ -0,0 +1,4 @@
+SELECT id, text, tags, source, created_at, confidence, stability_days, last_retrieved_at, provider, model_id, dim, embedding, source_path, chunk_index, kind, metadata, pinned, suppressed, pending_review, times_retrieved, parent_id
+FROM memories
+WHERE id IN (SELECT value FROM json_each(?1))
+   OR id IN (SELECT parent_id FROM memories WHERE id IN (SELECT value FROM json_each(?1)));
@@ -0,0 +1,8 @@
+SELECT id, text, tags, source, created_at, confidence, stability_days, last_retrieved_at, provider, model_id, dim, embedding, source_path, chunk_index, kind, metadata, pinned, suppressed, pending_review, times_retrieved, parent_id
+FROM memories
+WHERE parent_id IS NULL
+  AND (json_array_length(?1) = 0 OR EXISTS (
+      SELECT 1 FROM json_each(memories.tags) tag
+      WHERE lower(tag.value) IN (SELECT lower(value) FROM json_each(?1))))
+ORDER BY created_at DESC, id
+LIMIT ?2 OFFSET ?3;
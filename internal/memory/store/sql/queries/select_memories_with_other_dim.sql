@@ -0,0 +1,4 @@
+SELECT id
+FROM memories
+WHERE dim > 0 AND length(embedding) > 0 AND dim <> ?
+ORDER BY created_at, id;
@@ -0,0 +1,3 @@
+SELECT confidence, stability_days
+FROM memories
+WHERE id = ?;
@@ -0,0 +1,3 @@
+SELECT id, embedding
+FROM memories
+WHERE embedding IS NOT NULL;
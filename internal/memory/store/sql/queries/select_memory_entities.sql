@@ -0,0 +1,5 @@
+SELECT e.id, e.name, e.kind
+FROM entities e
+JOIN memory_entities me ON me.entity_id = e.id
+WHERE me.memory_id = ?
+ORDER BY e.name;
@@ -0,0 +1,4 @@
+SELECT from_id, to_id, kind, created_at
+FROM memory_relations
+WHERE from_id = ?1 OR to_id = ?1
+ORDER BY created_at, kind;
@@ -0,0 +1 @@
+SELECT revision FROM memory_revision WHERE id = 1;
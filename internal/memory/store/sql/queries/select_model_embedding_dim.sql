@@ -0,0 +1,6 @@
+SELECT dim
+FROM memories
+WHERE dim > 0 AND length(embedding) > 0 AND provider = ? AND model_id = ?
+GROUP BY dim
+ORDER BY COUNT(*) DESC, dim
+LIMIT 1;
@@ -0,0 +1,9 @@
+SELECT q.id, q.target_kind, q.target_id, q.attempts,
+       COALESCE(m.text, h.content, '') AS text,
+       COALESCE(m.tags, ''), COALESCE(m.kind, ''), COALESCE(m.source, ''), COALESCE(m.source_path, '')
+FROM embedding_queue q
+LEFT JOIN memories m ON q.target_kind = 'memory' AND m.id = q.target_id
+LEFT JOIN history h ON q.target_kind = 'history' AND h.id = q.target_id
+WHERE q.attempts < ? AND q.next_attempt_at <= ?
+ORDER BY q.enqueued_at, q.id
+LIMIT ?;
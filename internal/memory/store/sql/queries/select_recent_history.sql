@@ -1,4 +1,4 @@
-SELECT id, role, content, created_at, session_id
+SELECT id, role, content, created_at, session_id, parent_id, chat_model, interrupted
 FROM history
 ORDER BY created_at DESC
 LIMIT ?;
@@ -0,0 +1,5 @@
+SELECT text
+FROM memories
+WHERE parent_id IS NULL
+ORDER BY created_at DESC, rowid DESC
+LIMIT ?;
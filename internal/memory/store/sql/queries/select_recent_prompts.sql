@@ -0,0 +1,6 @@
+SELECT content
+FROM history
+WHERE role = 'user'
+GROUP BY content
+ORDER BY MAX(created_at) DESC, MAX(rowid) DESC
+LIMIT ?;
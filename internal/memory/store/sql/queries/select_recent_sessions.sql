@@ -0,0 +1,5 @@
+SELECT s.id, s.parent_id, s.fork_turn_id, s.created_at, s.title
+FROM sessions s
+ORDER BY COALESCE((SELECT MAX(h.created_at) FROM history h WHERE h.session_id = s.id), s.created_at) DESC,
+         s.rowid DESC
+LIMIT ?;
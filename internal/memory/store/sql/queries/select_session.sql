@@ -0,0 +1,3 @@
+SELECT id, parent_id, fork_turn_id, created_at, title
+FROM sessions
+WHERE id = ?;
@@ -0,0 +1,5 @@
+SELECT id, role, content, created_at, session_id, parent_id, chat_model, interrupted
+FROM history
+WHERE session_id = ?
+ORDER BY created_at DESC, rowid DESC
+LIMIT ?;
@@ -0,0 +1,6 @@
+SELECT id, role, content, created_at, session_id, parent_id, chat_model, interrupted
+FROM history
+WHERE session_id = ?
+  AND rowid <= (SELECT rowid FROM history WHERE id = ?)
+ORDER BY created_at DESC, rowid DESC
+LIMIT ?;
@@ -0,0 +1,7 @@
+SELECT 'memory' AS target_kind, id, dim, embedding
+FROM memories
+WHERE length(embedding) > 0
+UNION ALL
+SELECT 'history' AS target_kind, id, COALESCE(dim, 0), embedding
+FROM history
+WHERE embedding IS NOT NULL AND length(embedding) > 0;
@@ -0,0 +1,6 @@
+SELECT tag.value, COUNT(*) AS uses
+FROM memories m, json_each(m.tags) tag
+WHERE m.parent_id IS NULL AND m.suppressed = 0 AND tag.type = 'text'
+GROUP BY tag.value
+ORDER BY uses DESC, tag.value
+LIMIT ?;
@@ -0,0 +1,5 @@
+SELECT token,
+       COALESCE((SELECT seq FROM sqlite_sequence WHERE name = 'memory_vector_log'), 0),
+       (SELECT COUNT(*) FROM memory_vector_log WHERE seq > ?)
+FROM memory_vector_index
+WHERE id = 1;
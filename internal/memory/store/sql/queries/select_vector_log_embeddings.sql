@@ -0,0 +1,3 @@
+SELECT l.memory_id, m.embedding
+FROM (SELECT DISTINCT memory_id FROM memory_vector_log WHERE seq > ?) l
+LEFT JOIN memories m ON m.id = l.memory_id;
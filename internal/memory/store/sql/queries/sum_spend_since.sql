@@ -0,0 +1,3 @@
+SELECT COALESCE(SUM(cost_usd), 0)
+FROM spend
+WHERE created_at >= ?;
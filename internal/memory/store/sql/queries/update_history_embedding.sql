@@ -0,0 +1,3 @@
+UPDATE history
+SET embedding = ?, model_id = ?, dim = ?, provider = ?
+WHERE id = ?;
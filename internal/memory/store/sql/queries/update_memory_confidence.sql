@@ -0,0 +1,3 @@
+UPDATE memories
+SET confidence = ?, stability_days = ?
+WHERE id = ?;
@@ -0,0 +1,3 @@
+UPDATE memories
+SET text = ?, tags = ?, kind = ?
+WHERE id = ?;
@@ -1,3 +1,3 @@
 UPDATE memories
-SET embedding = ?, model_id = ?, dim = ?, provider = ?
+SET embedding = ?, model_id = ?, dim = ?, provider = ?, needs_reembedding = 0
 WHERE id = ?;
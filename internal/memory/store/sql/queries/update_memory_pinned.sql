@@ -0,0 +1,3 @@
+UPDATE memories
+SET pinned = ?
+WHERE id = ?;
@@ -0,0 +1,3 @@
+UPDATE memories
+SET suppressed = ?
+WHERE id = ?;
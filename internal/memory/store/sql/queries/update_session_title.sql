@@ -0,0 +1,3 @@
+UPDATE sessions
+SET title = ?
+WHERE id = ?;
@@ -18,11 +18,62 @@ CREATE TABLE IF NOT EXISTS memories (
     provider TEXT NOT NULL,
     model_id TEXT NOT NULL,
     dim INTEGER NOT NULL,
-    embedding BLOB NOT NULL
+    embedding BLOB NOT NULL,
+    source_path TEXT,
+    chunk_index INTEGER,
+    kind TEXT NOT NULL DEFAULT 'fact',
+    metadata TEXT,
+    pinned INTEGER NOT NULL DEFAULT 0,
+    suppressed INTEGER NOT NULL DEFAULT 0,
+    pending_review INTEGER NOT NULL DEFAULT 0,
+    times_retrieved INTEGER NOT NULL DEFAULT 0,
+    needs_reembedding INTEGER NOT NULL DEFAULT 0,
+    parent_id TEXT
 );
 
 CREATE INDEX IF NOT EXISTS idx_memories_created_at ON memories(created_at);
 
+-- Chunks of a long memory are rows of their own pointing at it through
+-- parent_id. Drop them together with their parent, and when its text changes
+CREATE TRIGGER IF NOT EXISTS memories_chunks_ad AFTER DELETE ON memories BEGIN
+    DELETE FROM memories WHERE parent_id = OLD.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS memories_chunks_au AFTER UPDATE OF text ON memories WHEN NEW.text <> OLD.text BEGIN
+    DELETE FROM memories WHERE parent_id = OLD.id;
+END;
+
+-- ============================================================================
+-- ARCHIVED MEMORIES
+-- Memories moved out of the active set by the archival policy. Same columns
+-- as memories plus archived_at; never searched, but still exported
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS archived_memories (
+    id TEXT PRIMARY KEY,
+    text TEXT NOT NULL,
+    tags TEXT,
+    source TEXT NOT NULL,
+    created_at INTEGER NOT NULL,
+    confidence REAL NOT NULL,
+    stability_days REAL NOT NULL,
+    last_retrieved_at INTEGER,
+    provider TEXT NOT NULL,
+    model_id TEXT NOT NULL,
+    dim INTEGER NOT NULL,
+    embedding BLOB NOT NULL,
+    source_path TEXT,
+    chunk_index INTEGER,
+    kind TEXT NOT NULL DEFAULT 'fact',
+    metadata TEXT,
+    pinned INTEGER NOT NULL DEFAULT 0,
+    suppressed INTEGER NOT NULL DEFAULT 0,
+    pending_review INTEGER NOT NULL DEFAULT 0,
+    times_retrieved INTEGER NOT NULL DEFAULT 0,
+    archived_at INTEGER NOT NULL,
+    parent_id TEXT
+);
+
 -- ============================================================================
 -- HISTORY TABLE
 -- Stores conversation turns for context retrieval
@@ -33,7 +84,10 @@ CREATE TABLE IF NOT EXISTS history (
     role TEXT NOT NULL,
     content TEXT NOT NULL,
     created_at INTEGER NOT NULL,
-    session_id TEXT
+    session_id TEXT,
+    parent_id TEXT,
+    chat_model TEXT,
+    interrupted INTEGER NOT NULL DEFAULT 0
 );
 
 CREATE INDEX IF NOT EXISTS idx_history_created_at ON history(created_at);
@@ -88,3 +142,192 @@ CREATE TRIGGER IF NOT EXISTS memories_au AFTER UPDATE ON memories BEGIN
     INSERT INTO memories_fts(memories_fts, rowid, text) VALUES('delete', OLD.rowid, OLD.text);
     INSERT INTO memories_fts(rowid, text) VALUES (NEW.rowid, NEW.text);
 END;
+
+-- ============================================================================
+-- EMBEDDING QUEUE
+-- Rows waiting for an embedding, drained by the background worker
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS embedding_queue (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    target_kind TEXT NOT NULL,
+    target_id TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    enqueued_at INTEGER NOT NULL,
+    next_attempt_at INTEGER NOT NULL DEFAULT 0,
+    UNIQUE(target_kind, target_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_embedding_queue_enqueued_at ON embedding_queue(enqueued_at);
+
+-- ============================================================================
+-- ENTITIES
+-- Named people, projects, and tools extracted from memories, with edges
+-- linking each memory to the entities it mentions
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS entities (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    normalized TEXT NOT NULL,
+    created_at INTEGER NOT NULL,
+    UNIQUE(normalized, kind)
+);
+
+CREATE TABLE IF NOT EXISTS memory_entities (
+    memory_id TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    PRIMARY KEY (memory_id, entity_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_memory_entities_entity ON memory_entities(entity_id);
+
+-- Drop edges together with their memory
+CREATE TRIGGER IF NOT EXISTS memories_entities_ad AFTER DELETE ON memories BEGIN
+    DELETE FROM memory_entities WHERE memory_id = OLD.id;
+END;
+
+-- ============================================================================
+-- SESSIONS
+-- Conversations, including branches forked from another session at a turn,
+-- titled by the title model after their first exchange
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id TEXT PRIMARY KEY,
+    parent_id TEXT,
+    fork_turn_id TEXT,
+    created_at INTEGER NOT NULL,
+    title TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_parent ON sessions(parent_id);
+
+-- ============================================================================
+-- SPEND
+-- Estimated token usage and cost of each chat request, summed per day and
+-- month for budget alerts
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS spend (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    provider TEXT NOT NULL,
+    model_id TEXT NOT NULL,
+    input_tokens INTEGER NOT NULL,
+    output_tokens INTEGER NOT NULL,
+    cost_usd REAL NOT NULL,
+    created_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_spend_created_at ON spend(created_at);
+
+-- ============================================================================
+-- MEMORY FEEDBACK
+-- Useful / wrong votes on retrieved memories; each vote also adjusts the
+-- memory's confidence and stability
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS memory_feedback (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    memory_id TEXT NOT NULL,
+    useful INTEGER NOT NULL,
+    created_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_memory_feedback_memory ON memory_feedback(memory_id);
+
+-- ============================================================================
+-- MEMORY RELATIONS
+-- Directed edges between memories: from_id supersedes, relates to, or is
+-- derived from to_id
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS memory_relations (
+    from_id TEXT NOT NULL,
+    to_id TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    created_at INTEGER NOT NULL,
+    PRIMARY KEY (from_id, to_id, kind)
+);
+
+CREATE INDEX IF NOT EXISTS idx_memory_relations_to ON memory_relations(to_id);
+
+-- Drop relations together with either of their memories
+CREATE TRIGGER IF NOT EXISTS memories_relations_ad AFTER DELETE ON memories BEGIN
+    DELETE FROM memory_relations WHERE from_id = OLD.id OR to_id = OLD.id;
+END;
+
+-- ============================================================================
+-- MEMORY REVISION
+-- A counter bumped whenever memories change in a way that can change what
+-- retrieval returns, so cached retrieval responses know when they are stale.
+-- Counting retrievals and reinforcing memories leave it alone
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS memory_revision (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    revision INTEGER NOT NULL
+);
+
+INSERT OR IGNORE INTO memory_revision (id, revision) VALUES (1, 0);
+
+CREATE TRIGGER IF NOT EXISTS memories_revision_ai AFTER INSERT ON memories BEGIN
+    UPDATE memory_revision SET revision = revision + 1;
+END;
+
+CREATE TRIGGER IF NOT EXISTS memories_revision_ad AFTER DELETE ON memories BEGIN
+    UPDATE memory_revision SET revision = revision + 1;
+END;
+
+CREATE TRIGGER IF NOT EXISTS memories_revision_au AFTER UPDATE ON memories
+WHEN NEW.text IS NOT OLD.text OR NEW.tags IS NOT OLD.tags OR NEW.kind IS NOT OLD.kind
+    OR NEW.embedding IS NOT OLD.embedding OR NEW.confidence IS NOT OLD.confidence
+    OR NEW.pinned IS NOT OLD.pinned OR NEW.suppressed IS NOT OLD.suppressed
+    OR NEW.pending_review IS NOT OLD.pending_review
+BEGIN
+    UPDATE memory_revision SET revision = revision + 1;
+END;
+
+CREATE TRIGGER IF NOT EXISTS memory_entities_revision_ai AFTER INSERT ON memory_entities BEGIN
+    UPDATE memory_revision SET revision = revision + 1;
+END;
+
+-- ============================================================================
+-- VECTOR INDEX
+-- The nearest-neighbor index over memory embeddings is kept in a file next to
+-- the database. token names this database, so an index built for another one
+-- is never used, and memory_vector_log lists the memories whose embedding
+-- changed, so the index catches up without being rebuilt
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS memory_vector_index (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    token TEXT NOT NULL
+);
+
+INSERT OR IGNORE INTO memory_vector_index (id, token) VALUES (1, lower(hex(randomblob(16))));
+
+CREATE TABLE IF NOT EXISTS memory_vector_log (
+    seq INTEGER PRIMARY KEY AUTOINCREMENT,
+    memory_id TEXT NOT NULL
+);
+
+CREATE TRIGGER IF NOT EXISTS memories_vector_ai AFTER INSERT ON memories
+WHEN NEW.embedding IS NOT NULL
+BEGIN
+    INSERT INTO memory_vector_log (memory_id) VALUES (NEW.id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS memories_vector_ad AFTER DELETE ON memories
+WHEN OLD.embedding IS NOT NULL
+BEGIN
+    INSERT INTO memory_vector_log (memory_id) VALUES (OLD.id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS memories_vector_au AFTER UPDATE OF embedding ON memories
+WHEN NEW.embedding IS NOT OLD.embedding
+BEGIN
+    INSERT INTO memory_vector_log (memory_id) VALUES (NEW.id);
+END;
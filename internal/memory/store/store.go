@@ -4,13 +4,18 @@ import (
 	"database/sql"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	_ "modernc.org/sqlite"
 
+	"github.com/austiecodes/gomor/internal/chaos"
 	"github.com/austiecodes/gomor/internal/memory/decay"
 	"github.com/austiecodes/gomor/internal/memory/memtypes"
 	"github.com/austiecodes/gomor/internal/memory/memutils"
@@ -20,15 +25,32 @@ import (
 // Re-export types from memtypes for convenience
 type MemoryItem = memtypes.MemoryItem
 type MemorySource = memtypes.MemorySource
+type MemoryKind = memtypes.MemoryKind
 type HistoryItem = memtypes.HistoryItem
 type SearchResult = memtypes.SearchResult
 type MemoryFTSResult = memtypes.MemoryFTSResult
 type HistorySearchResult = memtypes.HistorySearchResult
+type SearchHit = memtypes.SearchHit
+type EmbeddingJob = memtypes.EmbeddingJob
+type EmbeddingProblem = memtypes.EmbeddingProblem
+type EmbeddingTarget = memtypes.EmbeddingTarget
+type MemoryFilter = memtypes.MemoryFilter
+type TimeRange = memtypes.TimeRange
 
 // Re-export constants from memtypes for convenience
 const (
 	SourceExplicit  = memtypes.SourceExplicit
 	SourceExtracted = memtypes.SourceExtracted
+	SourceDocument  = memtypes.SourceDocument
+	SourceImported  = memtypes.SourceImported
+
+	KindFact          = memtypes.KindFact
+	KindPreference    = memtypes.KindPreference
+	KindDocumentChunk = memtypes.KindDocumentChunk
+	KindEpisodic      = memtypes.KindEpisodic
+
+	EmbeddingTargetMemory  = memtypes.EmbeddingTargetMemory
+	EmbeddingTargetHistory = memtypes.EmbeddingTargetHistory
 )
 
 // Re-export vector utils from memutils for convenience
@@ -42,6 +64,12 @@ var (
 // Store manages memory and history persistence in SQLite.
 type Store struct {
 	db *sql.DB
+
+	// vectorPath is where the vector index is saved, empty to keep it in
+	// memory only.
+	vectorPath string
+	vectors    *vectorIndex
+	vectorsMu  sync.Mutex
 }
 
 // NewStore creates a new memory store, initializing the database if needed.
@@ -51,12 +79,12 @@ func NewStore() (*Store, error) {
 		return nil, err
 	}
 
-	db, err := sql.Open("sqlite", dbPath)
+	db, err := openDB(dbPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open memory database: %w", err)
 	}
 
-	store := &Store{db: db}
+	store := &Store{db: db, vectorPath: vectorIndexPath(dbPath)}
 	if err := store.initSchema(); err != nil {
 		db.Close()
 		return nil, err
@@ -65,6 +93,31 @@ func NewStore() (*Store, error) {
 	return store, nil
 }
 
+// openDB opens the memory database. In debug mode, the chaos config may route
+// it through a connector that injects failures.
+func openDB(dbPath string) (*sql.DB, error) {
+	// Retrieval reads with full-text search while the vector path writes;
+	// wait for the lock rather than failing with SQLITE_BUSY.
+	if !strings.Contains(dbPath, "?") {
+		dbPath += "?_pragma=busy_timeout(5000)"
+	}
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	config, err := utils.LoadConfig()
+	if err != nil {
+		return db, nil
+	}
+	injector := chaos.For(config, chaos.TargetStore)
+	if injector == nil {
+		return db, nil
+	}
+	chaosDB := sql.OpenDB(injector.Connector(db.Driver(), dbPath))
+	db.Close()
+	return chaosDB, nil
+}
+
 // NewStoreWithDB creates a new memory store with a provided database connection.
 // This is primarily used for testing.
 func NewStoreWithDB(db *sql.DB) (*Store, error) {
@@ -91,17 +144,32 @@ func (s *Store) initSchema() error {
 	if err := s.ensureMemoryColumns(); err != nil {
 		return err
 	}
+	if err := s.ensureHistoryColumns(); err != nil {
+		return err
+	}
+	if err := s.ensureSessionColumns(); err != nil {
+		return err
+	}
+	if err := s.ensureEmbeddingQueueColumns(); err != nil {
+		return err
+	}
+	if err := s.ensureArchivedMemoryColumns(); err != nil {
+		return err
+	}
 	if err := s.rebuildFTSIndexes(); err != nil {
 		return err
 	}
 	if err := s.backfillMemoryDecayFields(); err != nil {
 		return err
 	}
+	if err := s.backfillMemoryKinds(); err != nil {
+		return err
+	}
 	return nil
 }
 
 func (s *Store) ensureMemoryColumns() error {
-	columns, err := s.memoryColumns()
+	columns, err := s.tableColumns("memories")
 	if err != nil {
 		return fmt.Errorf("failed to inspect memory schema: %w", err)
 	}
@@ -121,12 +189,142 @@ func (s *Store) ensureMemoryColumns() error {
 			return fmt.Errorf("failed to add memories.last_retrieved_at column: %w", err)
 		}
 	}
+	if !columns["source_path"] {
+		if _, err := s.db.Exec(`ALTER TABLE memories ADD COLUMN source_path TEXT;`); err != nil {
+			return fmt.Errorf("failed to add memories.source_path column: %w", err)
+		}
+	}
+	if !columns["chunk_index"] {
+		if _, err := s.db.Exec(`ALTER TABLE memories ADD COLUMN chunk_index INTEGER;`); err != nil {
+			return fmt.Errorf("failed to add memories.chunk_index column: %w", err)
+		}
+	}
+	if !columns["metadata"] {
+		if _, err := s.db.Exec(`ALTER TABLE memories ADD COLUMN metadata TEXT;`); err != nil {
+			return fmt.Errorf("failed to add memories.metadata column: %w", err)
+		}
+	}
+	if !columns["kind"] {
+		if _, err := s.db.Exec(`ALTER TABLE memories ADD COLUMN kind TEXT NOT NULL DEFAULT 'fact';`); err != nil {
+			return fmt.Errorf("failed to add memories.kind column: %w", err)
+		}
+	}
+	if !columns["pinned"] {
+		if _, err := s.db.Exec(`ALTER TABLE memories ADD COLUMN pinned INTEGER NOT NULL DEFAULT 0;`); err != nil {
+			return fmt.Errorf("failed to add memories.pinned column: %w", err)
+		}
+	}
+	if !columns["suppressed"] {
+		if _, err := s.db.Exec(`ALTER TABLE memories ADD COLUMN suppressed INTEGER NOT NULL DEFAULT 0;`); err != nil {
+			return fmt.Errorf("failed to add memories.suppressed column: %w", err)
+		}
+	}
+	if !columns["pending_review"] {
+		if _, err := s.db.Exec(`ALTER TABLE memories ADD COLUMN pending_review INTEGER NOT NULL DEFAULT 0;`); err != nil {
+			return fmt.Errorf("failed to add memories.pending_review column: %w", err)
+		}
+	}
+	if !columns["times_retrieved"] {
+		if _, err := s.db.Exec(`ALTER TABLE memories ADD COLUMN times_retrieved INTEGER NOT NULL DEFAULT 0;`); err != nil {
+			return fmt.Errorf("failed to add memories.times_retrieved column: %w", err)
+		}
+	}
+	if !columns["needs_reembedding"] {
+		if _, err := s.db.Exec(`ALTER TABLE memories ADD COLUMN needs_reembedding INTEGER NOT NULL DEFAULT 0;`); err != nil {
+			return fmt.Errorf("failed to add memories.needs_reembedding column: %w", err)
+		}
+	}
+	if !columns["parent_id"] {
+		if _, err := s.db.Exec(`ALTER TABLE memories ADD COLUMN parent_id TEXT;`); err != nil {
+			return fmt.Errorf("failed to add memories.parent_id column: %w", err)
+		}
+	}
+	if _, err := s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_memories_parent ON memories(parent_id);`); err != nil {
+		return fmt.Errorf("failed to index memories.parent_id: %w", err)
+	}
+
+	return nil
+}
+
+// ensureHistoryColumns adds the optional embedding columns used by the
+// background embedding worker to history tables created before they existed.
+func (s *Store) ensureHistoryColumns() error {
+	columns, err := s.tableColumns("history")
+	if err != nil {
+		return fmt.Errorf("failed to inspect history schema: %w", err)
+	}
+
+	optional := []struct {
+		name string
+		ddl  string
+	}{
+		{"provider", `ALTER TABLE history ADD COLUMN provider TEXT;`},
+		{"model_id", `ALTER TABLE history ADD COLUMN model_id TEXT;`},
+		{"dim", `ALTER TABLE history ADD COLUMN dim INTEGER;`},
+		{"embedding", `ALTER TABLE history ADD COLUMN embedding BLOB;`},
+		{"parent_id", `ALTER TABLE history ADD COLUMN parent_id TEXT;`},
+		{"chat_model", `ALTER TABLE history ADD COLUMN chat_model TEXT;`},
+		{"interrupted", `ALTER TABLE history ADD COLUMN interrupted INTEGER NOT NULL DEFAULT 0;`},
+	}
+	for _, col := range optional {
+		if columns[col.name] {
+			continue
+		}
+		if _, err := s.db.Exec(col.ddl); err != nil {
+			return fmt.Errorf("failed to add history.%s column: %w", col.name, err)
+		}
+	}
 
 	return nil
 }
 
-func (s *Store) memoryColumns() (map[string]bool, error) {
-	rows, err := s.db.Query(`PRAGMA table_info(memories)`)
+// ensureSessionColumns adds the title column to sessions tables created before
+// it existed.
+func (s *Store) ensureSessionColumns() error {
+	columns, err := s.tableColumns("sessions")
+	if err != nil {
+		return fmt.Errorf("failed to inspect sessions schema: %w", err)
+	}
+	if !columns["title"] {
+		if _, err := s.db.Exec(`ALTER TABLE sessions ADD COLUMN title TEXT;`); err != nil {
+			return fmt.Errorf("failed to add sessions.title column: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureEmbeddingQueueColumns adds the retry backoff column to embedding_queue
+// tables created before it existed.
+func (s *Store) ensureEmbeddingQueueColumns() error {
+	columns, err := s.tableColumns("embedding_queue")
+	if err != nil {
+		return fmt.Errorf("failed to inspect embedding_queue schema: %w", err)
+	}
+	if !columns["next_attempt_at"] {
+		if _, err := s.db.Exec(`ALTER TABLE embedding_queue ADD COLUMN next_attempt_at INTEGER NOT NULL DEFAULT 0;`); err != nil {
+			return fmt.Errorf("failed to add embedding_queue.next_attempt_at column: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureArchivedMemoryColumns adds the parent_id column, which keeps the
+// chunks of an archived memory with it, to archives created before it existed.
+func (s *Store) ensureArchivedMemoryColumns() error {
+	columns, err := s.tableColumns("archived_memories")
+	if err != nil {
+		return fmt.Errorf("failed to inspect archived_memories schema: %w", err)
+	}
+	if !columns["parent_id"] {
+		if _, err := s.db.Exec(`ALTER TABLE archived_memories ADD COLUMN parent_id TEXT;`); err != nil {
+			return fmt.Errorf("failed to add archived_memories.parent_id column: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *Store) tableColumns(table string) (map[string]bool, error) {
+	rows, err := s.db.Query(fmt.Sprintf(`PRAGMA table_info(%s)`, table))
 	if err != nil {
 		return nil, err
 	}
@@ -183,6 +381,17 @@ func (s *Store) backfillMemoryDecayFields() error {
 	return nil
 }
 
+// backfillMemoryKinds marks document chunks ingested before kinds existed.
+func (s *Store) backfillMemoryKinds() error {
+	if _, err := s.db.Exec(
+		`UPDATE memories SET kind = ? WHERE source = ? AND kind = ?`,
+		string(KindDocumentChunk), string(SourceDocument), string(KindFact),
+	); err != nil {
+		return fmt.Errorf("failed to backfill memory kinds: %w", err)
+	}
+	return nil
+}
+
 func (s *Store) rebuildFTSIndexes() error {
 	if _, err := s.db.Exec(`INSERT INTO memories_fts(memories_fts) VALUES('rebuild');`); err != nil {
 		return fmt.Errorf("failed to rebuild memories FTS index: %w", err)
@@ -193,8 +402,17 @@ func (s *Store) rebuildFTSIndexes() error {
 	return nil
 }
 
+// execer runs statements on the database, or within a transaction.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
 // SaveMemory saves a new memory item with its embedding.
 func (s *Store) SaveMemory(item *MemoryItem) error {
+	return saveMemory(s.db, item)
+}
+
+func saveMemory(db execer, item *MemoryItem) error {
 	if item.ID == "" {
 		item.ID = uuid.New().String()
 	}
@@ -218,11 +436,32 @@ func (s *Store) SaveMemory(item *MemoryItem) error {
 	if item.LastRetrievedAt != nil {
 		lastRetrievedAt = item.LastRetrievedAt.Unix()
 	}
+	if item.Kind == "" {
+		item.Kind = KindFact
+	}
+	var metadataJSON any
+	if len(item.Metadata) > 0 {
+		data, err := json.Marshal(item.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+		metadataJSON = string(data)
+	}
+	var sourcePath, chunkIndex, parentID any
+	if item.SourcePath != "" {
+		sourcePath = item.SourcePath
+		chunkIndex = item.ChunkIndex
+	}
+	if item.ParentID != "" {
+		parentID = item.ParentID
+		chunkIndex = item.ChunkIndex
+	}
 
-	_, err = s.db.Exec(insertMemorySQL,
+	_, err = db.Exec(insertMemorySQL,
 		item.ID, item.Text, string(tagsJSON), string(item.Source),
 		item.CreatedAt.Unix(), item.Confidence, item.StabilityDays, lastRetrievedAt,
-		item.Provider, item.ModelID, item.Dim, embeddingBytes)
+		item.Provider, item.ModelID, item.Dim, embeddingBytes,
+		sourcePath, chunkIndex, string(item.Kind), metadataJSON, item.Pinned, item.Suppressed, item.PendingReview, item.TimesRetrieved, parentID)
 
 	if err != nil {
 		return fmt.Errorf("failed to save memory: %w", err)
@@ -241,7 +480,8 @@ func (s *Store) UpdateMemoryEmbedding(id string, embedding []float32, modelID st
 	return nil
 }
 
-// GetAllMemories returns all memory items (for vector search).
+// GetAllMemories returns all memory items, leaving out the chunks of long
+// memories.
 func (s *Store) GetAllMemories() ([]MemoryItem, error) {
 	rows, err := s.db.Query(selectAllMemoriesSQL)
 	if err != nil {
@@ -249,6 +489,116 @@ func (s *Store) GetAllMemories() ([]MemoryItem, error) {
 	}
 	defer rows.Close()
 
+	return scanMemories(rows)
+}
+
+// ListMemories returns a page of memories, newest first: up to limit of them
+// after skipping offset. When tags is not empty, only memories carrying one
+// of them, ignoring case, are listed. It also returns how many memories there
+// are in all pages. Chunks of long memories are left out.
+func (s *Store) ListMemories(tags []string, limit, offset int) ([]MemoryItem, int, error) {
+	if tags == nil {
+		tags = []string{}
+	}
+	tagsJSON, err := json.Marshal(tags)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to marshal tags: %w", err)
+	}
+
+	var total int
+	if err := s.db.QueryRow(countMemoriesTaggedSQL, string(tagsJSON)).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count memories: %w", err)
+	}
+	rows, err := s.db.Query(selectMemoriesPageSQL, string(tagsJSON), limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query memories: %w", err)
+	}
+	defer rows.Close()
+
+	memories, err := scanMemories(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return memories, total, nil
+}
+
+// SearchableMemories returns all memory items together with the chunks of
+// long memories (for vector search and reindexing).
+func (s *Store) SearchableMemories() ([]MemoryItem, error) {
+	rows, err := s.db.Query(selectSearchableMemoriesSQL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query memories: %w", err)
+	}
+	defer rows.Close()
+
+	return scanMemories(rows)
+}
+
+// PinnedMemories returns all pinned memories, oldest first.
+func (s *Store) PinnedMemories() ([]MemoryItem, error) {
+	rows, err := s.db.Query(selectPinnedMemoriesSQL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pinned memories: %w", err)
+	}
+	defer rows.Close()
+
+	return scanMemories(rows)
+}
+
+// SetMemoryPinned pins or unpins a memory and reports whether it exists.
+func (s *Store) SetMemoryPinned(id string, pinned bool) (bool, error) {
+	result, err := s.db.Exec(updateMemoryPinnedSQL, pinned, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to update memory pin: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// SetMemorySuppressed excludes a memory from retrieval, or lets it back in,
+// and reports whether it exists. Suppressed memories are kept for the record.
+func (s *Store) SetMemorySuppressed(id string, suppressed bool) (bool, error) {
+	result, err := s.db.Exec(updateMemorySuppressedSQL, suppressed, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to update memory suppression: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// PendingReviewMemories returns the memories awaiting review, oldest first.
+func (s *Store) PendingReviewMemories() ([]MemoryItem, error) {
+	rows, err := s.db.Query(selectPendingReviewMemoriesSQL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query memories pending review: %w", err)
+	}
+	defer rows.Close()
+
+	return scanMemories(rows)
+}
+
+// ApproveMemory lets a memory pending review be retrieved and reports whether
+// a memory pending review with that ID existed.
+func (s *Store) ApproveMemory(id string) (bool, error) {
+	result, err := s.db.Exec(approveMemorySQL, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to approve memory: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// scanMemories reads memory rows selected with the standard memory column list.
+func scanMemories(rows *sql.Rows) ([]MemoryItem, error) {
 	var memories []MemoryItem
 	for rows.Next() {
 		var item MemoryItem
@@ -257,10 +607,16 @@ func (s *Store) GetAllMemories() ([]MemoryItem, error) {
 		var lastRetrievedAtUnix sql.NullInt64
 		var embeddingBytes []byte
 		var source string
+		var sourcePath sql.NullString
+		var chunkIndex sql.NullInt64
+		var kind string
+		var metadataJSON sql.NullString
+		var parentID sql.NullString
 
 		err := rows.Scan(&item.ID, &item.Text, &tagsJSON, &source,
 			&createdAtUnix, &item.Confidence, &item.StabilityDays, &lastRetrievedAtUnix,
-			&item.Provider, &item.ModelID, &item.Dim, &embeddingBytes)
+			&item.Provider, &item.ModelID, &item.Dim, &embeddingBytes,
+			&sourcePath, &chunkIndex, &kind, &metadataJSON, &item.Pinned, &item.Suppressed, &item.PendingReview, &item.TimesRetrieved, &parentID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan memory row: %w", err)
 		}
@@ -271,7 +627,18 @@ func (s *Store) GetAllMemories() ([]MemoryItem, error) {
 			lastRetrievedAt := time.Unix(lastRetrievedAtUnix.Int64, 0)
 			item.LastRetrievedAt = &lastRetrievedAt
 		}
-		item.Embedding = BytesToVector(embeddingBytes)
+		if item.Embedding, err = memutils.DecodeVector(embeddingBytes); err != nil {
+			item.NeedsReembedding = true
+		}
+		item.SourcePath = sourcePath.String
+		item.ChunkIndex = int(chunkIndex.Int64)
+		item.ParentID = parentID.String
+		item.Kind = MemoryKind(kind)
+		if metadataJSON.Valid && metadataJSON.String != "" {
+			if err := json.Unmarshal([]byte(metadataJSON.String), &item.Metadata); err != nil {
+				item.Metadata = nil // ignore malformed metadata
+			}
+		}
 
 		if err := json.Unmarshal([]byte(tagsJSON), &item.Tags); err != nil {
 			item.Tags = nil // ignore malformed tags
@@ -286,20 +653,64 @@ func (s *Store) GetAllMemories() ([]MemoryItem, error) {
 // SearchMemories performs vector similarity search on memories.
 // Returns top K results with similarity >= minSimilarity.
 func (s *Store) SearchMemories(queryEmbedding []float32, topK int, minSimilarity float64) ([]SearchResult, error) {
-	memories, err := s.GetAllMemories()
+	return s.SearchMemoriesFiltered(queryEmbedding, topK, minSimilarity, MemoryFilter{})
+}
+
+// SearchMemoriesFiltered performs vector similarity search on the memories
+// matching filter. Returns top K results with similarity >= minSimilarity.
+// Memories with malformed embeddings are flagged for re-embedding and the
+// results are returned along with a *MalformedEmbeddingsError naming them.
+//
+// Stores with many memories are searched through an approximate
+// nearest-neighbor index, saved next to the database and kept up to date as
+// embeddings change; smaller ones, and searches the index cannot answer, scan
+// every memory.
+func (s *Store) SearchMemoriesFiltered(queryEmbedding []float32, topK int, minSimilarity float64, filter MemoryFilter) ([]SearchResult, error) {
+	if results, ok, err := s.searchVectorIndex(queryEmbedding, topK, minSimilarity, filter); ok {
+		return results, err
+	}
+
+	memories, err := s.SearchableMemories()
 	if err != nil {
 		return nil, err
 	}
+	results := RankBySimilarity(memories, queryEmbedding, topK, minSimilarity, filter)
 
+	var malformed *MalformedEmbeddingsError
+	if err := MalformedEmbeddings(memories); errors.As(err, &malformed) {
+		return results, errors.Join(malformed, s.MarkNeedsReembedding(malformed.IDs))
+	}
+	return results, nil
+}
+
+// RankBySimilarity is SearchMemoriesFiltered over memories already read from
+// the store, so that several searches can share one read. A chunk of a long
+// memory is never returned itself: its similarity counts for its parent,
+// which scores as its best-matching chunk or its own embedding.
+func RankBySimilarity(memories []MemoryItem, queryEmbedding []float32, topK int, minSimilarity float64, filter MemoryFilter) []SearchResult {
 	// Normalize query embedding for cosine similarity via dot product
 	normalizedQuery := NormalizeVector(queryEmbedding)
 
-	// Calculate similarities
-	var results []SearchResult
+	// Calculate similarities, merging chunks into their parent
+	best := make(map[string]float64, len(memories))
 	for _, mem := range memories {
+		id := mem.ID
+		if mem.ParentID != "" {
+			id = mem.ParentID
+		}
 		// Embeddings are stored normalized, so dot product = cosine similarity
 		similarity := DotProduct(normalizedQuery, mem.Embedding)
-		if similarity >= minSimilarity {
+		if prev, ok := best[id]; !ok || similarity > prev {
+			best[id] = similarity
+		}
+	}
+
+	var results []SearchResult
+	for _, mem := range memories {
+		if mem.ParentID != "" || !filter.Matches(mem) {
+			continue
+		}
+		if similarity := best[mem.ID]; similarity >= minSimilarity {
 			results = append(results, SearchResult{
 				Item:       mem,
 				Similarity: similarity,
@@ -317,7 +728,7 @@ func (s *Store) SearchMemories(queryEmbedding []float32, topK int, minSimilarity
 		results = results[:topK]
 	}
 
-	return results, nil
+	return results
 }
 
 // UpdateMemoryDecay updates confidence, stability, and retrieval time for a memory.
@@ -334,6 +745,72 @@ func (s *Store) UpdateMemoryDecay(id string, confidence float64, stabilityDays f
 	return nil
 }
 
+// RecordMemoryRetrievals counts one retrieval of each memory in ids and sets
+// their last retrieval time to at.
+func (s *Store) RecordMemoryRetrievals(ids []string, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, id := range ids {
+		if _, err := tx.Exec(recordMemoryRetrievalSQL, at.Unix(), id); err != nil {
+			return fmt.Errorf("failed to record memory retrieval: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit memory retrievals: %w", err)
+	}
+	return nil
+}
+
+// UpdateMemoryContent replaces a memory's text, tags, and kind. The caller is
+// responsible for re-embedding, and re-chunking, when the text changes: the
+// chunks of the old text are dropped.
+func (s *Store) UpdateMemoryContent(id, text string, tags []string, kind MemoryKind) error {
+	tagsJSON, err := json.Marshal(tags)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tags: %w", err)
+	}
+	if kind == "" {
+		kind = KindFact
+	}
+
+	_, err = s.db.Exec(updateMemoryContentSQL, text, string(tagsJSON), string(kind), id)
+	if err != nil {
+		return fmt.Errorf("failed to update memory: %w", err)
+	}
+	return nil
+}
+
+// GetMemory returns the memory with id, or ErrMemoryNotFound.
+func (s *Store) GetMemory(id string) (*MemoryItem, error) {
+	memories, err := s.memoriesByIDs([]string{id})
+	if err != nil {
+		return nil, err
+	}
+	for i := range memories {
+		if memories[i].ID == id {
+			return &memories[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrMemoryNotFound, id)
+}
+
+// DeleteMemoryChunks deletes the chunks of a memory, so they can be saved
+// again when it changes in a way that does not change its text.
+func (s *Store) DeleteMemoryChunks(parentID string) error {
+	if _, err := s.db.Exec(deleteMemoryChunksSQL, parentID); err != nil {
+		return fmt.Errorf("failed to delete memory chunks: %w", err)
+	}
+	return nil
+}
+
 // DeleteMemory deletes a memory by ID.
 func (s *Store) DeleteMemory(id string) error {
 	_, err := s.DeleteMemoryByID(id)
@@ -353,10 +830,47 @@ func (s *Store) DeleteMemoryByID(id string) (bool, error) {
 	return rowsAffected > 0, nil
 }
 
+// ReplaceSourceMemories replaces the memories ingested from path with items,
+// saving each as SaveMemory does, in one transaction: when it fails, the
+// previous memories are left in place. When enqueue is set, the embedding of
+// each item is queued too.
+func (s *Store) ReplaceSourceMemories(path string, items []MemoryItem, enqueue bool) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(deleteMemoriesBySourcePathSQL, path); err != nil {
+		return fmt.Errorf("failed to delete memories for %s: %w", path, err)
+	}
+	for i := range items {
+		if err := saveMemory(tx, &items[i]); err != nil {
+			return err
+		}
+		if enqueue {
+			if err := enqueueEmbedding(tx, EmbeddingTargetMemory, items[i].ID); err != nil {
+				return err
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit memories for %s: %w", path, err)
+	}
+	return nil
+}
+
 // SearchMemoriesFTS performs full-text search on memory text.
 // Returns top K results ordered by FTS rank.
 func (s *Store) SearchMemoriesFTS(query string, topK int) ([]MemoryFTSResult, error) {
-	rows, err := s.db.Query(searchMemoriesFTSSQL, query, topK)
+	return s.SearchMemoriesFTSFiltered(query, topK, MemoryFilter{})
+}
+
+// SearchMemoriesFTSFiltered performs full-text search on the memories matching filter.
+// Returns top K results ordered by FTS rank.
+func (s *Store) SearchMemoriesFTSFiltered(query string, topK int, filter MemoryFilter) ([]MemoryFTSResult, error) {
+	after, before := unixBounds(filter.Created)
+	rows, err := s.db.Query(searchMemoriesFTSSQL, query, after, before, topK)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search memories FTS: %w", err)
 	}
@@ -371,10 +885,16 @@ func (s *Store) SearchMemoriesFTS(query string, topK int) ([]MemoryFTSResult, er
 		var lastRetrievedAtUnix sql.NullInt64
 		var embeddingBytes []byte
 		var source string
+		var sourcePath sql.NullString
+		var chunkIndex sql.NullInt64
+		var kind string
+		var metadataJSON sql.NullString
+		var parentID sql.NullString
 
 		err := rows.Scan(&item.ID, &item.Text, &tagsJSON, &source,
 			&createdAtUnix, &item.Confidence, &item.StabilityDays, &lastRetrievedAtUnix,
 			&item.Provider, &item.ModelID, &item.Dim, &embeddingBytes,
+			&sourcePath, &chunkIndex, &kind, &metadataJSON, &item.Pinned, &item.Suppressed, &item.PendingReview, &item.TimesRetrieved, &parentID,
 			&result.Snippet, &result.Rank)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan memory FTS row: %w", err)
@@ -386,12 +906,26 @@ func (s *Store) SearchMemoriesFTS(query string, topK int) ([]MemoryFTSResult, er
 			lastRetrievedAt := time.Unix(lastRetrievedAtUnix.Int64, 0)
 			item.LastRetrievedAt = &lastRetrievedAt
 		}
-		item.Embedding = BytesToVector(embeddingBytes)
+		if item.Embedding, err = memutils.DecodeVector(embeddingBytes); err != nil {
+			item.NeedsReembedding = true
+		}
+		item.SourcePath = sourcePath.String
+		item.ChunkIndex = int(chunkIndex.Int64)
+		item.ParentID = parentID.String
+		item.Kind = MemoryKind(kind)
+		if metadataJSON.Valid && metadataJSON.String != "" {
+			if err := json.Unmarshal([]byte(metadataJSON.String), &item.Metadata); err != nil {
+				item.Metadata = nil // ignore malformed metadata
+			}
+		}
 
 		if err := json.Unmarshal([]byte(tagsJSON), &item.Tags); err != nil {
 			item.Tags = nil // ignore malformed tags
 		}
 
+		if !filter.Matches(item) {
+			continue
+		}
 		result.Item = item
 		results = append(results, result)
 	}
@@ -399,8 +933,24 @@ func (s *Store) SearchMemoriesFTS(query string, topK int) ([]MemoryFTSResult, er
 	return results, rows.Err()
 }
 
-// SaveHistory saves a new history item.
+// SaveHistory saves a new history item. An item repeating the last turn of
+// its session, with the same role, content, parent, and model, is not stored
+// again: item takes the ID and creation time of that turn instead, so agents
+// resending the same message do not bloat history and its index.
 func (s *Store) SaveHistory(item *HistoryItem) error {
+	if item.SessionID != "" {
+		last, err := s.lastSessionTurn(item.SessionID)
+		if err != nil {
+			return err
+		}
+		if last != nil && last.Role == item.Role && last.Content == item.Content &&
+			last.ParentID == item.ParentID && last.Model == item.Model && last.Interrupted == item.Interrupted {
+			item.ID = last.ID
+			item.CreatedAt = last.CreatedAt
+			return nil
+		}
+	}
+
 	if item.ID == "" {
 		item.ID = uuid.New().String()
 	}
@@ -408,8 +958,16 @@ func (s *Store) SaveHistory(item *HistoryItem) error {
 		item.CreatedAt = time.Now()
 	}
 
+	var parentID, chatModel any
+	if item.ParentID != "" {
+		parentID = item.ParentID
+	}
+	if item.Model != "" {
+		chatModel = item.Model
+	}
+
 	_, err := s.db.Exec(insertHistorySQL,
-		item.ID, item.Role, item.Content, item.CreatedAt.Unix(), item.SessionID)
+		item.ID, item.Role, item.Content, item.CreatedAt.Unix(), item.SessionID, parentID, chatModel, item.Interrupted)
 
 	if err != nil {
 		return fmt.Errorf("failed to save history: %w", err)
@@ -418,10 +976,32 @@ func (s *Store) SaveHistory(item *HistoryItem) error {
 	return nil
 }
 
+// lastSessionTurn returns the most recent turn of a session, or nil if it has none.
+func (s *Store) lastSessionTurn(sessionID string) (*HistoryItem, error) {
+	rows, err := s.db.Query(selectSessionHistorySQL, sessionID, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query session history: %w", err)
+	}
+	defer rows.Close()
+
+	items, err := scanHistory(rows)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
 // SearchHistory performs full-text search on history content.
 // Returns top K results ordered by FTS rank.
 func (s *Store) SearchHistory(query string, topK int) ([]HistorySearchResult, error) {
-	rows, err := s.db.Query(searchHistoryFTSSQL, query, topK)
+	return s.SearchHistoryInRange(query, topK, TimeRange{})
+}
+
+// SearchHistoryInRange performs full-text search on history created within tr.
+// Returns top K results ordered by FTS rank.
+func (s *Store) SearchHistoryInRange(query string, topK int, tr TimeRange) ([]HistorySearchResult, error) {
+	after, before := unixBounds(tr)
+	rows, err := s.db.Query(searchHistoryFTSSQL, query, after, before, topK)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search history: %w", err)
 	}
@@ -460,21 +1040,37 @@ func (s *Store) GetRecentHistory(limit int) ([]HistoryItem, error) {
 	}
 	defer rows.Close()
 
+	return scanHistory(rows)
+}
+
+// HistorySince returns the history items created at or after since, oldest first.
+func (s *Store) HistorySince(since time.Time) ([]HistoryItem, error) {
+	rows, err := s.db.Query(selectHistorySinceSQL, since.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+	defer rows.Close()
+
+	return scanHistory(rows)
+}
+
+// scanHistory reads rows of (id, role, content, created_at, session_id, parent_id, chat_model, interrupted).
+func scanHistory(rows *sql.Rows) ([]HistoryItem, error) {
 	var items []HistoryItem
 	for rows.Next() {
 		var item HistoryItem
 		var createdAtUnix int64
-		var sessionID sql.NullString
+		var sessionID, parentID, chatModel sql.NullString
 
-		err := rows.Scan(&item.ID, &item.Role, &item.Content, &createdAtUnix, &sessionID)
+		err := rows.Scan(&item.ID, &item.Role, &item.Content, &createdAtUnix, &sessionID, &parentID, &chatModel, &item.Interrupted)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan history row: %w", err)
 		}
 
 		item.CreatedAt = time.Unix(createdAtUnix, 0)
-		if sessionID.Valid {
-			item.SessionID = sessionID.String
-		}
+		item.SessionID = sessionID.String
+		item.ParentID = parentID.String
+		item.Model = chatModel.String
 
 		items = append(items, item)
 	}
@@ -482,6 +1078,226 @@ func (s *Store) GetRecentHistory(limit int) ([]HistoryItem, error) {
 	return items, rows.Err()
 }
 
+// UpdateHistoryEmbedding stores the embedding for a specific history item.
+func (s *Store) UpdateHistoryEmbedding(id string, embedding []float32, modelID string, dim int, provider string) error {
+	embeddingBytes := VectorToBytes(embedding)
+	_, err := s.db.Exec(updateHistoryEmbeddingSQL, embeddingBytes, modelID, dim, provider, id)
+	if err != nil {
+		return fmt.Errorf("failed to update history embedding: %w", err)
+	}
+	return nil
+}
+
+// EnqueueEmbedding adds a row to the embedding queue. Re-enqueueing an existing
+// target resets its attempt counter.
+func (s *Store) EnqueueEmbedding(kind EmbeddingTarget, targetID string) error {
+	return enqueueEmbedding(s.db, kind, targetID)
+}
+
+func enqueueEmbedding(db execer, kind EmbeddingTarget, targetID string) error {
+	_, err := db.Exec(enqueueEmbeddingSQL, string(kind), targetID, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("failed to enqueue embedding: %w", err)
+	}
+	return nil
+}
+
+// PendingEmbeddings returns up to limit queued jobs with fewer than maxAttempts
+// attempts, oldest first, including jobs waiting out a retry backoff.
+func (s *Store) PendingEmbeddings(maxAttempts, limit int) ([]EmbeddingJob, error) {
+	return s.queuedEmbeddings(maxAttempts, limit, math.MaxInt64)
+}
+
+// DueEmbeddings returns up to limit queued jobs with fewer than maxAttempts
+// attempts whose retry backoff has passed by now, oldest first.
+func (s *Store) DueEmbeddings(maxAttempts, limit int, now time.Time) ([]EmbeddingJob, error) {
+	return s.queuedEmbeddings(maxAttempts, limit, now.Unix())
+}
+
+func (s *Store) queuedEmbeddings(maxAttempts, limit int, dueBy int64) ([]EmbeddingJob, error) {
+	rows, err := s.db.Query(selectPendingEmbeddingsSQL, maxAttempts, dueBy, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query embedding queue: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []EmbeddingJob
+	for rows.Next() {
+		var job EmbeddingJob
+		var kind, tagsJSON, memoryKind, source string
+		if err := rows.Scan(&job.ID, &kind, &job.TargetID, &job.Attempts, &job.Text,
+			&tagsJSON, &memoryKind, &source, &job.SourcePath); err != nil {
+			return nil, fmt.Errorf("failed to scan embedding job: %w", err)
+		}
+		job.TargetKind = EmbeddingTarget(kind)
+		job.Kind = MemoryKind(memoryKind)
+		job.Source = MemorySource(source)
+		if tagsJSON != "" {
+			if err := json.Unmarshal([]byte(tagsJSON), &job.Tags); err != nil {
+				job.Tags = nil // ignore malformed tags
+			}
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// CompleteEmbeddingJob removes a job from the embedding queue.
+func (s *Store) CompleteEmbeddingJob(id int64) error {
+	_, err := s.db.Exec(deleteEmbeddingJobSQL, id)
+	return err
+}
+
+// FailEmbeddingJob records a failed attempt for a queued job and holds it back
+// until retryAt.
+func (s *Store) FailEmbeddingJob(id int64, reason string, retryAt time.Time) error {
+	_, err := s.db.Exec(failEmbeddingJobSQL, reason, retryAt.Unix(), id)
+	return err
+}
+
+// GetSessionHistory returns the most recent history items of a session, oldest first.
+func (s *Store) GetSessionHistory(sessionID string, limit int) ([]HistoryItem, error) {
+	rows, err := s.db.Query(selectSessionHistorySQL, sessionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query session history: %w", err)
+	}
+	defer rows.Close()
+
+	items, err := scanHistory(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rows come back newest first; callers want conversation order.
+	for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+		items[i], items[j] = items[j], items[i]
+	}
+
+	return items, nil
+}
+
+// LastPrompt returns the most recent user turn, limited to sessionID unless it
+// is empty. It returns nil when there is none.
+func (s *Store) LastPrompt(sessionID string) (*HistoryItem, error) {
+	rows, err := s.db.Query(selectLastPromptSQL, sessionID, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query last prompt: %w", err)
+	}
+	defer rows.Close()
+
+	items, err := scanHistory(rows)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+// RecentPrompts returns up to limit distinct user prompts from history, oldest
+// first, for prompt recall in the chat REPL.
+func (s *Store) RecentPrompts(limit int) ([]string, error) {
+	rows, err := s.db.Query(selectRecentPromptsSQL, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent prompts: %w", err)
+	}
+	defer rows.Close()
+
+	var prompts []string
+	for rows.Next() {
+		var content string
+		if err := rows.Scan(&content); err != nil {
+			return nil, fmt.Errorf("failed to scan prompt row: %w", err)
+		}
+		prompts = append(prompts, content)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(prompts)-1; i < j; i, j = i+1, j-1 {
+		prompts[i], prompts[j] = prompts[j], prompts[i]
+	}
+
+	return prompts, nil
+}
+
+// RecentMemoryTexts returns the text of up to limit most recently created
+// memories, newest first.
+func (s *Store) RecentMemoryTexts(limit int) ([]string, error) {
+	rows, err := s.db.Query(selectRecentMemoryTextsSQL, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query memory texts: %w", err)
+	}
+	defer rows.Close()
+
+	var texts []string
+	for rows.Next() {
+		var text string
+		if err := rows.Scan(&text); err != nil {
+			return nil, fmt.Errorf("failed to scan memory text: %w", err)
+		}
+		texts = append(texts, text)
+	}
+	return texts, rows.Err()
+}
+
+// TopTags returns up to limit tags of unsuppressed memories, most used first.
+func (s *Store) TopTags(limit int) ([]string, error) {
+	rows, err := s.db.Query(selectTopTagsSQL, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var tag string
+		var uses int
+		if err := rows.Scan(&tag, &uses); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// CountMemories returns the number of stored memories.
+func (s *Store) CountMemories() (int, error) {
+	var n int
+	if err := s.db.QueryRow(countMemoriesSQL).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count memories: %w", err)
+	}
+	return n, nil
+}
+
+// MemoryRevision returns a counter that changes whenever memories are saved,
+// edited, re-embedded, suppressed, approved, or deleted, by this process or
+// any other. Recording retrievals does not change it.
+func (s *Store) MemoryRevision() (int64, error) {
+	var revision int64
+	if err := s.db.QueryRow(selectMemoryRevisionSQL).Scan(&revision); err != nil {
+		return 0, fmt.Errorf("failed to read memory revision: %w", err)
+	}
+	return revision, nil
+}
+
+// CountHistory returns the number of stored history items.
+func (s *Store) CountHistory() (int, error) {
+	var n int
+	if err := s.db.QueryRow(countHistorySQL).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count history: %w", err)
+	}
+	return n, nil
+}
+
+// Backup writes a consistent copy of the whole database to path, which must not exist.
+func (s *Store) Backup(path string) error {
+	if _, err := s.db.Exec(`VACUUM INTO ?`, path); err != nil {
+		return fmt.Errorf("failed to back up memory database: %w", err)
+	}
+	return nil
+}
+
 // ClearHistory deletes all history items.
 func (s *Store) ClearHistory() error {
 	_, err := s.db.Exec(clearHistorySQL)
@@ -493,3 +1309,16 @@ func (s *Store) ClearMemories() error {
 	_, err := s.db.Exec(clearMemoriesSQL)
 	return err
 }
+
+// unixBounds converts a time range into inclusive/exclusive unix-second bounds
+// suitable for created_at comparisons.
+func unixBounds(tr TimeRange) (int64, int64) {
+	after, before := int64(math.MinInt64), int64(math.MaxInt64)
+	if !tr.Start.IsZero() {
+		after = tr.Start.Unix()
+	}
+	if !tr.End.IsZero() {
+		before = tr.End.Unix()
+	}
+	return after, before
+}
@@ -0,0 +1,33 @@
+package store
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+func TestTopTagsOrdersByUse(t *testing.T) {
+	s := newTestStore(t)
+
+	for _, item := range []*memtypes.MemoryItem{
+		{Text: "uses postgres 16", Tags: []string{"database", "work"}},
+		{Text: "prefers dark mode", Tags: []string{"editor"}},
+		{Text: "atlas db is sharded", Tags: []string{"database", "atlas"}},
+		{Text: "old gossip", Tags: []string{"gossip"}, Suppressed: true},
+		{Text: "untagged"},
+	} {
+		item.Source = memtypes.SourceExplicit
+		if err := s.SaveMemory(item); err != nil {
+			t.Fatalf("save memory: %v", err)
+		}
+	}
+
+	tags, err := s.TopTags(3)
+	if err != nil {
+		t.Fatalf("top tags: %v", err)
+	}
+	if want := []string{"database", "atlas", "editor"}; !reflect.DeepEqual(tags, want) {
+		t.Fatalf("TopTags(3) = %v, want %v", tags, want)
+	}
+}
@@ -0,0 +1,335 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/memory/ann"
+	"github.com/austiecodes/gomor/internal/memory/memutils"
+	"github.com/austiecodes/gomor/internal/trace"
+)
+
+// vectorIndexMinMemories is how many embedded memories a store needs before
+// vector search goes through the index. Below it a full scan is fast, and
+// exact.
+var vectorIndexMinMemories = 2000
+
+// vectorLogCompactEntries is how far the saved index may fall behind the
+// vector log before it is rewritten. Until then the log keeps the changes,
+// and loading the saved index replays them.
+var vectorLogCompactEntries int64 = 1000
+
+const (
+	// vectorIndexFile names the index file, next to the database.
+	vectorIndexFile = "vectors.idx"
+	// vectorCandidates is how many index hits are ranked per result wanted,
+	// leaving room for chunks of one memory and for filtered-out memories.
+	vectorCandidates = 4
+	// vectorMinCandidates is the fewest index hits ranked for a search.
+	vectorMinCandidates = 100
+	// vectorSearchEf is how many candidates the index weighs for a search.
+	vectorSearchEf = 200
+)
+
+// vectorIndex is the nearest-neighbor index over memory embeddings, with the
+// database state it reflects.
+type vectorIndex struct {
+	index *ann.Index
+	token string // the memory_vector_index token of its database
+	seq   int64  // the last memory_vector_log entry it reflects
+	saved int64  // the last memory_vector_log entry the saved index reflects
+}
+
+// vectorIndexState is the database's side of the index: its token, the last
+// memory_vector_log entry, and how many entries are newer than some seq.
+type vectorIndexState struct {
+	token   string
+	seq     int64
+	pending int64
+}
+
+// reflects reports whether x is for this database and every change since it
+// was built is still in the log.
+func (state vectorIndexState) reflects(x *vectorIndex) bool {
+	return x != nil && x.token == state.token && x.seq <= state.seq && state.pending == state.seq-x.seq
+}
+
+// searchVectorIndex is SearchMemoriesFiltered through the vector index. It
+// reports false when the search should scan every memory instead: the store
+// is too small for an index, the index could not be brought up to date, or
+// filter dropped so many candidates that the index cannot fill topK.
+func (s *Store) searchVectorIndex(queryEmbedding []float32, topK int, minSimilarity float64, filter MemoryFilter) ([]SearchResult, bool, error) {
+	s.vectorsMu.Lock()
+	x, malformed, err := s.syncVectorIndex(len(queryEmbedding))
+	s.vectorsMu.Unlock()
+	if err != nil {
+		trace.Printf(trace.Info, "vector index: %v; scanning every memory", err)
+		return nil, false, nil
+	}
+	if x == nil {
+		return nil, false, nil
+	}
+
+	k := max(topK*vectorCandidates, vectorMinCandidates)
+	hits := x.index.Search(queryEmbedding, k, max(k, vectorSearchEf))
+	ids := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		if hit.Similarity >= minSimilarity {
+			ids = append(ids, hit.ID)
+		}
+	}
+	// When the hits run out or fall below minSimilarity, no memory the index
+	// left out could make the results.
+	exhausted := len(hits) < k || len(ids) < len(hits)
+
+	memories, err := s.memoriesByIDs(ids)
+	if err != nil {
+		return nil, true, err
+	}
+	results := RankBySimilarity(memories, queryEmbedding, topK, minSimilarity, filter)
+	if len(results) < topK && !exhausted {
+		return nil, false, nil
+	}
+	if len(malformed) > 0 {
+		return results, true, errors.Join(&MalformedEmbeddingsError{IDs: malformed}, s.MarkNeedsReembedding(malformed))
+	}
+	return results, true, nil
+}
+
+// syncVectorIndex brings the index for dim-dimensional embeddings up to date
+// with the database, loading it from disk or building it when needed, and
+// returns it with the IDs of memories whose embeddings it had to leave out as
+// malformed. It returns a nil index when the store is too small for one.
+// s.vectorsMu must be held.
+func (s *Store) syncVectorIndex(dim int) (*vectorIndex, []string, error) {
+	x := s.vectors
+	if x == nil || x.index.Dim() != dim {
+		x = s.loadVectorIndex(dim)
+	}
+	state, err := s.vectorIndexState(x)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var malformed []string
+	changed := false
+	if !state.reflects(x) {
+		var count int
+		if err := s.db.QueryRow(countEmbeddedMemoriesSQL).Scan(&count); err != nil {
+			return nil, nil, fmt.Errorf("failed to count embedded memories: %w", err)
+		}
+		if count < vectorIndexMinMemories {
+			return nil, nil, s.dropVectorIndex(state.seq)
+		}
+		if x, malformed, err = s.buildVectorIndex(dim, state); err != nil {
+			return nil, nil, err
+		}
+		changed = true
+	} else if state.seq > x.seq {
+		if malformed, err = s.applyVectorLog(x, state.seq); err != nil {
+			return nil, nil, err
+		}
+		changed = x.seq-x.saved >= vectorLogCompactEntries
+	}
+	if x.index.Stale() {
+		if x, malformed, err = s.buildVectorIndex(dim, state); err != nil {
+			return nil, nil, err
+		}
+		changed = true
+	}
+
+	s.vectors = x
+	if changed {
+		if err := s.saveVectorIndex(x); err != nil {
+			return nil, nil, err
+		}
+	}
+	return x, malformed, nil
+}
+
+// vectorIndexState reads the database's side of x, or of no index when x is nil.
+func (s *Store) vectorIndexState(x *vectorIndex) (vectorIndexState, error) {
+	var since int64
+	if x != nil {
+		since = x.seq
+	}
+	var state vectorIndexState
+	if err := s.db.QueryRow(selectVectorIndexStateSQL, since).Scan(&state.token, &state.seq, &state.pending); err != nil {
+		return state, fmt.Errorf("failed to read vector index state: %w", err)
+	}
+	return state, nil
+}
+
+// buildVectorIndex indexes every embedding of dim dimensions. Changes made
+// while it reads are logged after state.seq, so the next search applies them.
+func (s *Store) buildVectorIndex(dim int, state vectorIndexState) (*vectorIndex, []string, error) {
+	trace.Printf(trace.Info, "vector index: building")
+	rows, err := s.db.Query(selectMemoryEmbeddingsSQL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	x := &vectorIndex{index: ann.New(dim), token: state.token, seq: state.seq}
+	var malformed []string
+	for rows.Next() {
+		var id string
+		var embeddingBytes []byte
+		if err := rows.Scan(&id, &embeddingBytes); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan embedding row: %w", err)
+		}
+		embedding, err := memutils.DecodeVector(embeddingBytes)
+		if err != nil {
+			malformed = append(malformed, id)
+			continue
+		}
+		x.index.Add(id, embedding)
+	}
+	return x, malformed, rows.Err()
+}
+
+// applyVectorLog updates x with the embeddings logged after x.seq, up to seq.
+func (s *Store) applyVectorLog(x *vectorIndex, seq int64) ([]string, error) {
+	rows, err := s.db.Query(selectVectorLogEmbeddingsSQL, x.seq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read changed embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	var malformed []string
+	for rows.Next() {
+		var id string
+		var embeddingBytes []byte
+		if err := rows.Scan(&id, &embeddingBytes); err != nil {
+			return nil, fmt.Errorf("failed to scan embedding row: %w", err)
+		}
+		x.index.Remove(id)
+		if embeddingBytes == nil {
+			continue
+		}
+		embedding, err := memutils.DecodeVector(embeddingBytes)
+		if err != nil {
+			malformed = append(malformed, id)
+			continue
+		}
+		x.index.Add(id, embedding)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	x.seq = seq
+	return malformed, nil
+}
+
+// loadVectorIndex reads the saved index, or returns nil when there is none
+// for dim-dimensional embeddings.
+func (s *Store) loadVectorIndex(dim int) *vectorIndex {
+	if s.vectorPath == "" {
+		return nil
+	}
+	f, err := os.Open(s.vectorPath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	header, err := r.ReadString('\n')
+	if err != nil {
+		return nil
+	}
+	x := &vectorIndex{}
+	if _, err := fmt.Sscanf(header, "%s %d\n", &x.token, &x.seq); err != nil {
+		return nil
+	}
+	x.saved = x.seq
+	if x.index, err = ann.Read(r); err != nil {
+		trace.Printf(trace.Info, "vector index: %v; rebuilding", err)
+		return nil
+	}
+	if x.index.Dim() != dim {
+		return nil
+	}
+	return x
+}
+
+// saveVectorIndex writes x next to the database, replacing the saved index
+// in one step, and drops the log entries it reflects. Small changes are left
+// to the log instead; see vectorLogCompactEntries.
+func (s *Store) saveVectorIndex(x *vectorIndex) error {
+	if s.vectorPath != "" {
+		tmp, err := os.CreateTemp(filepath.Dir(s.vectorPath), vectorIndexFile+".*")
+		if err != nil {
+			return fmt.Errorf("failed to save vector index: %w", err)
+		}
+		defer os.Remove(tmp.Name())
+
+		w := bufio.NewWriter(tmp)
+		fmt.Fprintf(w, "%s %d\n", x.token, x.seq)
+		err = x.index.Write(w)
+		if err == nil {
+			err = w.Flush()
+		}
+		if cerr := tmp.Close(); err == nil {
+			err = cerr
+		}
+		if err == nil {
+			err = os.Rename(tmp.Name(), s.vectorPath)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save vector index: %w", err)
+		}
+	}
+	if _, err := s.db.Exec(deleteVectorLogSQL, x.seq); err != nil {
+		return fmt.Errorf("failed to trim vector log: %w", err)
+	}
+	x.saved = x.seq
+	return nil
+}
+
+// dropVectorIndex forgets the index of a store too small for one, and the
+// log up to seq, which only an index would need.
+func (s *Store) dropVectorIndex(seq int64) error {
+	s.vectors = nil
+	if s.vectorPath != "" {
+		if err := os.Remove(s.vectorPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove vector index: %w", err)
+		}
+	}
+	if _, err := s.db.Exec(deleteVectorLogSQL, seq); err != nil {
+		return fmt.Errorf("failed to trim vector log: %w", err)
+	}
+	return nil
+}
+
+// memoriesByIDs returns the memories with the given IDs, with the parents of
+// the chunks among them.
+func (s *Store) memoriesByIDs(ids []string) ([]MemoryItem, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	idsJSON, err := json.Marshal(ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.db.Query(selectMemoriesByIDsSQL, string(idsJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query memories: %w", err)
+	}
+	defer rows.Close()
+
+	return scanMemories(rows)
+}
+
+// vectorIndexPath names the index file for the database at dbPath.
+func vectorIndexPath(dbPath string) string {
+	if strings.HasPrefix(dbPath, ":memory:") || strings.HasPrefix(dbPath, "file:") {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(dbPath), vectorIndexFile)
+}
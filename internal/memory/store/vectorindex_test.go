@@ -0,0 +1,172 @@
+package store
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+func saveVectorMemories(t *testing.T, s *Store, n int, rng *rand.Rand) []*MemoryItem {
+	t.Helper()
+	items := make([]*MemoryItem, n)
+	for i := range items {
+		v := make([]float32, 8)
+		for j := range v {
+			v[j] = float32(rng.NormFloat64())
+		}
+		items[i] = &MemoryItem{
+			Text:      fmt.Sprintf("memory %d", i),
+			Source:    memtypes.SourceExplicit,
+			Provider:  "fake",
+			ModelID:   "fake-embedding",
+			Dim:       8,
+			Embedding: NormalizeVector(v),
+		}
+		if err := s.SaveMemory(items[i]); err != nil {
+			t.Fatalf("save memory: %v", err)
+		}
+	}
+	return items
+}
+
+func TestSearchMemoriesThroughVectorIndex(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	old := vectorIndexMinMemories
+	vectorIndexMinMemories = 50
+	t.Cleanup(func() { vectorIndexMinMemories = old })
+
+	s, err := NewStore()
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	defer s.Close()
+	rng := rand.New(rand.NewSource(1))
+	items := saveVectorMemories(t, s, 200, rng)
+
+	results, err := s.SearchMemories(items[42].Embedding, 3, 0)
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(results) != 3 || results[0].Item.ID != items[42].ID {
+		t.Fatalf("expected the memory itself first, got %+v", results)
+	}
+	if s.vectors == nil || s.vectors.index.Len() != 200 {
+		t.Fatal("expected the search to build the index")
+	}
+	if _, err := os.Stat(s.vectorPath); err != nil {
+		t.Fatalf("expected the index to be saved: %v", err)
+	}
+
+	// Changes reach the index without a rebuild, in this process and from a
+	// saved index in the next.
+	if err := s.DeleteMemory(items[42].ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	added := saveVectorMemories(t, s, 1, rng)[0]
+	for _, store := range []*Store{s, reopen(t)} {
+		results, err = store.SearchMemories(items[42].Embedding, 1, 0)
+		if err != nil || len(results) != 1 || results[0].Item.ID == items[42].ID {
+			t.Fatalf("expected the deleted memory to be gone, got %+v (%v)", results, err)
+		}
+		results, err = store.SearchMemories(added.Embedding, 1, 0)
+		if err != nil || len(results) != 1 || results[0].Item.ID != added.ID {
+			t.Fatalf("expected the new memory to be found, got %+v (%v)", results, err)
+		}
+		if store.vectors.index.Len() != 200 {
+			t.Fatalf("expected 200 indexed memories, got %d", store.vectors.index.Len())
+		}
+	}
+
+	// A filter the index cannot fill falls back to scanning every memory.
+	results, err = s.SearchMemoriesFiltered(items[7].Embedding, 1, 0, MemoryFilter{Tags: []string{"rare"}})
+	if err != nil || len(results) != 0 {
+		t.Fatalf("expected no tagged memories, got %+v (%v)", results, err)
+	}
+}
+
+func TestVectorIndexLeavesSmallChangesToTheLog(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	oldMin, oldCompact := vectorIndexMinMemories, vectorLogCompactEntries
+	vectorIndexMinMemories, vectorLogCompactEntries = 10, 5
+	t.Cleanup(func() { vectorIndexMinMemories, vectorLogCompactEntries = oldMin, oldCompact })
+
+	s := reopen(t)
+	rng := rand.New(rand.NewSource(3))
+	items := saveVectorMemories(t, s, 20, rng)
+	if _, err := s.SearchMemories(items[0].Embedding, 1, 0); err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	built := s.vectors.seq
+
+	// A few changes are applied in memory and kept in the log, not saved.
+	added := saveVectorMemories(t, s, 2, rng)
+	if _, err := s.SearchMemories(added[0].Embedding, 1, 0); err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if s.vectors.saved != built || s.vectors.seq == built {
+		t.Fatalf("expected the changes applied but not saved, got seq %d saved %d", s.vectors.seq, s.vectors.saved)
+	}
+	if x := reopen(t).loadVectorIndex(8); x == nil || x.seq != built {
+		t.Fatal("expected the saved index to be left as built")
+	}
+	results, err := reopen(t).SearchMemories(added[1].Embedding, 1, 0)
+	if err != nil || len(results) != 1 || results[0].Item.ID != added[1].ID {
+		t.Fatalf("expected the next store to replay the log, got %+v (%v)", results, err)
+	}
+
+	// Once the log grows past vectorLogCompactEntries, the index is saved.
+	saveVectorMemories(t, s, 5, rng)
+	if _, err := s.SearchMemories(added[0].Embedding, 1, 0); err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if s.vectors.saved != s.vectors.seq {
+		t.Fatalf("expected the index to be saved, got seq %d saved %d", s.vectors.seq, s.vectors.saved)
+	}
+	if x := reopen(t).loadVectorIndex(8); x == nil || x.seq != s.vectors.seq {
+		t.Fatal("expected the saved index to reflect the log")
+	}
+}
+
+func TestVectorIndexFromAnotherDatabaseIsRebuilt(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	old := vectorIndexMinMemories
+	vectorIndexMinMemories = 10
+	t.Cleanup(func() { vectorIndexMinMemories = old })
+
+	s := reopen(t)
+	rng := rand.New(rand.NewSource(2))
+	items := saveVectorMemories(t, s, 20, rng)
+	if _, err := s.SearchMemories(items[0].Embedding, 1, 0); err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	s.Close()
+
+	// Replace the database, as restoring another machine's bundle would.
+	if err := os.Remove(filepath.Join(filepath.Dir(s.vectorPath), utils.DBFile)); err != nil {
+		t.Fatalf("remove database: %v", err)
+	}
+	s = reopen(t)
+	fresh := saveVectorMemories(t, s, 20, rng)
+	results, err := s.SearchMemories(fresh[3].Embedding, 1, 0)
+	if err != nil || len(results) != 1 || results[0].Item.ID != fresh[3].ID {
+		t.Fatalf("expected the new database's memory, got %+v (%v)", results, err)
+	}
+	if s.vectors.index.Len() != 20 {
+		t.Fatalf("expected the index to be rebuilt with 20 memories, got %d", s.vectors.index.Len())
+	}
+}
+
+func reopen(t *testing.T) *Store {
+	t.Helper()
+	s, err := NewStore()
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
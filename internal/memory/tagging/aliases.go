@@ -0,0 +1,82 @@
+package tagging
+
+import "strings"
+
+// Canonicalize rewrites tags to their canonical names: an alias becomes the
+// tag it stands for, and a tag in vocabulary takes the vocabulary's spelling.
+// Matching ignores case and surrounding space. Duplicates that result are
+// dropped, keeping the first.
+func Canonicalize(tags []string, aliases map[string]string, vocabulary []string) []string {
+	if len(tags) == 0 {
+		return tags
+	}
+
+	lookup := aliasLookup(aliases)
+	spelling := make(map[string]string, len(vocabulary))
+	for _, v := range vocabulary {
+		spelling[key(v)] = v
+	}
+
+	seen := make(map[string]bool, len(tags))
+	canonical := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if target, ok := lookup[key(tag)]; ok {
+			tag = target
+		}
+		if v, ok := spelling[key(tag)]; ok {
+			tag = v
+		}
+		if tag == "" || seen[key(tag)] {
+			continue
+		}
+		seen[key(tag)] = true
+		canonical = append(canonical, tag)
+	}
+	return canonical
+}
+
+// Expand returns tags together with every alias of their canonical names, so
+// a tag filter also catches memories saved under an alias.
+func Expand(tags []string, aliases map[string]string) []string {
+	if len(tags) == 0 || len(aliases) == 0 {
+		return tags
+	}
+
+	lookup := aliasLookup(aliases)
+	wanted := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		wanted[key(tag)] = true
+		if target, ok := lookup[key(tag)]; ok {
+			wanted[key(target)] = true
+		}
+	}
+
+	expanded := append([]string(nil), tags...)
+	seen := make(map[string]bool, len(wanted))
+	for _, tag := range tags {
+		seen[key(tag)] = true
+	}
+	for alias, target := range aliases {
+		for _, name := range []string{target, alias} {
+			if wanted[key(target)] && !seen[key(name)] {
+				seen[key(name)] = true
+				expanded = append(expanded, strings.TrimSpace(name))
+			}
+		}
+	}
+	return expanded
+}
+
+// aliasLookup indexes aliases by their matching key.
+func aliasLookup(aliases map[string]string) map[string]string {
+	lookup := make(map[string]string, len(aliases))
+	for alias, target := range aliases {
+		lookup[key(alias)] = strings.TrimSpace(target)
+	}
+	return lookup
+}
+
+func key(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
@@ -0,0 +1,43 @@
+package tagging
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	aliases := map[string]string{"js": "javascript", "JavaScript.": "javascript", "pg": "Postgres"}
+	vocabulary := []string{"Postgres", "javascript", "work"}
+
+	got := Canonicalize([]string{" JS ", "javascript", "pg", "WORK", "misc", ""}, aliases, vocabulary)
+	want := []string{"javascript", "Postgres", "work", "misc"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Canonicalize = %q, want %q", got, want)
+	}
+
+	if got := Canonicalize(nil, aliases, vocabulary); got != nil {
+		t.Fatalf("expected nil tags to stay nil, got %q", got)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	aliases := map[string]string{"js": "javascript", "ecmascript": "javascript", "pg": "postgres"}
+
+	cases := map[string]struct {
+		tags []string
+		want []string
+	}{
+		"canonical":  {[]string{"javascript"}, []string{"ecmascript", "javascript", "js"}},
+		"alias":      {[]string{"JS"}, []string{"JS", "ecmascript", "javascript"}},
+		"unrelated":  {[]string{"work"}, []string{"work"}},
+		"no filters": {nil, nil},
+	}
+	for name, c := range cases {
+		got := Expand(c.tags, aliases)
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: Expand(%q) = %q, want %q", name, c.tags, got, c.want)
+		}
+	}
+}
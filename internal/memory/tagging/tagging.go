@@ -0,0 +1,114 @@
+// Package tagging asks the tool model to propose tags for memories saved
+// without any, so they can be found later by tag.
+package tagging
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// MaxTags is the most tags proposed for one memory.
+const MaxTags = 4
+
+// maxTagLength drops runaway answers that are not tags.
+const maxTagLength = 32
+
+// listMarker matches the bullet or number a model may put before a tag.
+var listMarker = regexp.MustCompile(`^(?:[-*•]|\d+[.)])\s+`)
+
+// Propose asks tool_model for 2 to MaxTags tags for text. With a controlled
+// vocabulary, only tags from it are returned. Otherwise the known tags are
+// preferred when they fit, so the set of tags stays small.
+func Propose(ctx context.Context, queryClient client.QueryClient, model types.Model, text string, known, vocabulary []string) ([]string, error) {
+	if queryClient == nil {
+		return nil, utils.ConfigErrorf("tool model not configured")
+	}
+
+	var choices string
+	switch {
+	case len(vocabulary) > 0:
+		choices = fmt.Sprintf("\nChoose only from these tags: %s\nIf none fit, respond with NONE.\n", strings.Join(vocabulary, ", "))
+	case len(known) > 0:
+		choices = fmt.Sprintf("\nReuse these existing tags when they fit: %s\n", strings.Join(known, ", "))
+	}
+	prompt := fmt.Sprintf(`Suggest 2 to %d short tags for this note, so it can be found later by topic.
+Tags are lowercase single words or hyphenated phrases.
+%s
+Note: %s
+
+Respond with the tags on one line, separated by commas (no other text).`, MaxTags, choices, text)
+
+	stream, err := queryClient.ChatStream(ctx, model, prompt)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	var sb strings.Builder
+	for stream.Next() {
+		sb.WriteString(stream.GetChunk())
+	}
+	if err := stream.Err(); err != nil {
+		return nil, err
+	}
+
+	tags := Parse(sb.String())
+	if len(vocabulary) > 0 {
+		tags = Restrict(tags, vocabulary)
+	}
+	return tags, nil
+}
+
+// Restrict keeps the tags that are in vocabulary, spelled as they are there.
+func Restrict(tags, vocabulary []string) []string {
+	allowed := make(map[string]string, len(vocabulary))
+	for _, v := range vocabulary {
+		allowed[Normalize(v)] = v
+	}
+
+	var kept []string
+	for _, tag := range tags {
+		if v, ok := allowed[Normalize(tag)]; ok {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// Parse reads a comma- or line-separated tag list, normalizing each tag and
+// keeping at most MaxTags distinct ones.
+func Parse(answer string) []string {
+	fields := strings.FieldsFunc(answer, func(r rune) bool {
+		return r == ',' || r == '\n' || r == ';'
+	})
+
+	seen := make(map[string]bool)
+	var tags []string
+	for _, f := range fields {
+		tag := Normalize(f)
+		if tag == "" || tag == "none" || len(tag) > maxTagLength || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+		if len(tags) == MaxTags {
+			break
+		}
+	}
+	return tags
+}
+
+// Normalize lowercases tag, drops list markers, quotes, and a leading '#',
+// and joins its words with hyphens.
+func Normalize(tag string) string {
+	tag = strings.TrimSpace(strings.ToLower(tag))
+	tag = listMarker.ReplaceAllString(tag, "")
+	tag = strings.Trim(tag, "\"'`#. ")
+	return strings.Join(strings.Fields(tag), "-")
+}
@@ -0,0 +1,101 @@
+package tagging
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+type fakeStream struct {
+	text string
+	done bool
+}
+
+func (s *fakeStream) Next() bool {
+	if s.done {
+		return false
+	}
+	s.done = true
+	return true
+}
+func (s *fakeStream) GetChunk() string { return s.text }
+func (s *fakeStream) Err() error       { return nil }
+func (s *fakeStream) Close() error     { return nil }
+
+type fakeQueryClient struct {
+	answer string
+	prompt string
+}
+
+func (f *fakeQueryClient) ChatStream(ctx context.Context, model types.Model, query string) (client.StreamResponse, error) {
+	f.prompt = query
+	return &fakeStream{text: f.answer}, nil
+}
+
+func (f *fakeQueryClient) ChatStreamWithContext(ctx context.Context, model types.Model, systemContext, query string) (client.StreamResponse, error) {
+	return f.ChatStream(ctx, model, query)
+}
+
+func (f *fakeQueryClient) ListModels(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func TestParse(t *testing.T) {
+	cases := map[string][]string{
+		"database, postgres, Work":                           {"database", "postgres", "work"},
+		"#Dark Mode; editor\n- editor":                       {"dark-mode", "editor"},
+		"1. 3d-printing\n2) hobby":                           {"3d-printing", "hobby"},
+		`"a", "b", "c", "d", "e"`:                            {"a", "b", "c", "d"},
+		"this is far too long to be a useful tag at all, ok": {"ok"},
+		"": nil,
+	}
+	for answer, want := range cases {
+		if got := Parse(answer); !reflect.DeepEqual(got, want) {
+			t.Errorf("Parse(%q) = %q, want %q", answer, got, want)
+		}
+	}
+}
+
+func TestProposeOffersKnownTags(t *testing.T) {
+	queryClient := &fakeQueryClient{answer: "database, atlas\n"}
+
+	tags, err := Propose(context.Background(), queryClient, types.Model{}, "Atlas runs on Postgres 16", []string{"database", "editor"}, nil)
+	if err != nil {
+		t.Fatalf("propose: %v", err)
+	}
+	if want := []string{"database", "atlas"}; !reflect.DeepEqual(tags, want) {
+		t.Fatalf("tags = %q, want %q", tags, want)
+	}
+	if !strings.Contains(queryClient.prompt, "Reuse these existing tags when they fit: database, editor") {
+		t.Fatalf("known tags missing from prompt:\n%s", queryClient.prompt)
+	}
+
+	if _, err := Propose(context.Background(), nil, types.Model{}, "text", nil, nil); err == nil {
+		t.Fatal("expected an error without a tool model")
+	}
+}
+
+func TestProposeKeepsToVocabulary(t *testing.T) {
+	queryClient := &fakeQueryClient{answer: "databases, Work, postgres"}
+	vocabulary := []string{"Work", "home", "databases"}
+
+	tags, err := Propose(context.Background(), queryClient, types.Model{}, "Atlas runs on Postgres 16", []string{"ignored"}, vocabulary)
+	if err != nil {
+		t.Fatalf("propose: %v", err)
+	}
+	if want := []string{"databases", "Work"}; !reflect.DeepEqual(tags, want) {
+		t.Fatalf("tags = %q, want %q", tags, want)
+	}
+	if !strings.Contains(queryClient.prompt, "Choose only from these tags: Work, home, databases") || strings.Contains(queryClient.prompt, "ignored") {
+		t.Fatalf("unexpected prompt:\n%s", queryClient.prompt)
+	}
+
+	queryClient.answer = "NONE"
+	if tags, err := Propose(context.Background(), queryClient, types.Model{}, "lunch", nil, vocabulary); err != nil || len(tags) != 0 {
+		t.Fatalf("expected no tags when none fit, got %q, %v", tags, err)
+	}
+}
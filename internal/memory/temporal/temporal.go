@@ -0,0 +1,169 @@
+package temporal
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+)
+
+// matcher turns a matched temporal phrase into a time range.
+type matcher struct {
+	re      *regexp.Regexp
+	resolve func(m []string, now time.Time) (memtypes.TimeRange, bool)
+}
+
+// prep matches an optional preposition in front of a temporal phrase so that it
+// is stripped together with the phrase ("in the last 3 days").
+const prep = `(?:(?:from|in|during|over|on)\s+)?(?:the\s+)?`
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+var matchers = []matcher{
+	{
+		re: regexp.MustCompile(`(?i)\b` + prep + `(?:last|past)\s+(\d+)\s+(day|week|month|year)s?\b`),
+		resolve: func(m []string, now time.Time) (memtypes.TimeRange, bool) {
+			n, err := strconv.Atoi(m[1])
+			if err != nil || n <= 0 {
+				return memtypes.TimeRange{}, false
+			}
+			return memtypes.TimeRange{Start: addUnits(now, m[2], -n), End: now}, true
+		},
+	},
+	{
+		re: regexp.MustCompile(`(?i)\b(\d+)\s+(day|week|month|year)s?\s+ago\b`),
+		resolve: func(m []string, now time.Time) (memtypes.TimeRange, bool) {
+			n, err := strconv.Atoi(m[1])
+			if err != nil || n <= 0 {
+				return memtypes.TimeRange{}, false
+			}
+			start := startOfDay(addUnits(now, m[2], -n))
+			return memtypes.TimeRange{Start: start, End: addUnits(start, m[2], 1)}, true
+		},
+	},
+	{
+		re: regexp.MustCompile(`(?i)\b` + prep + `today\b`),
+		resolve: func(m []string, now time.Time) (memtypes.TimeRange, bool) {
+			start := startOfDay(now)
+			return memtypes.TimeRange{Start: start, End: start.AddDate(0, 0, 1)}, true
+		},
+	},
+	{
+		re: regexp.MustCompile(`(?i)\b` + prep + `yesterday\b`),
+		resolve: func(m []string, now time.Time) (memtypes.TimeRange, bool) {
+			end := startOfDay(now)
+			return memtypes.TimeRange{Start: end.AddDate(0, 0, -1), End: end}, true
+		},
+	},
+	{
+		re: regexp.MustCompile(`(?i)\b` + prep + `(this|last|previous)\s+(week|month|year)\b`),
+		resolve: func(m []string, now time.Time) (memtypes.TimeRange, bool) {
+			var start time.Time
+			switch strings.ToLower(m[2]) {
+			case "week":
+				start = startOfWeek(now)
+			case "month":
+				start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+			default:
+				start = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+			}
+			if strings.EqualFold(m[1], "this") {
+				return memtypes.TimeRange{Start: start, End: addUnits(start, m[2], 1)}, true
+			}
+			return memtypes.TimeRange{Start: addUnits(start, m[2], -1), End: start}, true
+		},
+	},
+	{
+		re: regexp.MustCompile(`(?i)\b(on|last)\s+(sunday|monday|tuesday|wednesday|thursday|friday|saturday)\b`),
+		resolve: func(m []string, now time.Time) (memtypes.TimeRange, bool) {
+			target := weekdays[strings.ToLower(m[2])]
+			daysBack := (int(now.Weekday()) - int(target) + 7) % 7
+			if daysBack == 0 && strings.EqualFold(m[1], "last") {
+				daysBack = 7
+			}
+			start := startOfDay(now).AddDate(0, 0, -daysBack)
+			return memtypes.TimeRange{Start: start, End: start.AddDate(0, 0, 1)}, true
+		},
+	},
+	{
+		re: regexp.MustCompile(`(?i)\b(since|after|before|on)\s+(\d{4}-\d{2}-\d{2})\b`),
+		resolve: func(m []string, now time.Time) (memtypes.TimeRange, bool) {
+			day, err := time.ParseInLocation("2006-01-02", m[2], now.Location())
+			if err != nil {
+				return memtypes.TimeRange{}, false
+			}
+			switch strings.ToLower(m[1]) {
+			case "since":
+				return memtypes.TimeRange{Start: day, End: now}, true
+			case "after":
+				return memtypes.TimeRange{Start: day.AddDate(0, 0, 1), End: now}, true
+			case "before":
+				return memtypes.TimeRange{End: day}, true
+			default:
+				return memtypes.TimeRange{Start: day, End: day.AddDate(0, 0, 1)}, true
+			}
+		},
+	},
+}
+
+// Parse looks for a temporal expression in query ("yesterday", "last 3 days",
+// "since 2024-06-01", ...) relative to now. It returns the resolved range, the
+// query with the expression removed, and whether anything was found.
+func Parse(query string, now time.Time) (memtypes.TimeRange, string, bool) {
+	for _, m := range matchers {
+		loc := m.re.FindStringSubmatchIndex(query)
+		if loc == nil {
+			continue
+		}
+
+		groups := make([]string, len(loc)/2)
+		for i := range groups {
+			if loc[2*i] >= 0 {
+				groups[i] = query[loc[2*i]:loc[2*i+1]]
+			}
+		}
+
+		tr, ok := m.resolve(groups, now)
+		if !ok {
+			continue
+		}
+
+		stripped := strings.Join(strings.Fields(query[:loc[0]]+" "+query[loc[1]:]), " ")
+		return tr, stripped, true
+	}
+
+	return memtypes.TimeRange{}, query, false
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// startOfWeek returns midnight of the Monday starting t's week.
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return startOfDay(t).AddDate(0, 0, -offset)
+}
+
+func addUnits(t time.Time, unit string, n int) time.Time {
+	switch strings.ToLower(unit) {
+	case "week":
+		return t.AddDate(0, 0, 7*n)
+	case "month":
+		return t.AddDate(0, n, 0)
+	case "year":
+		return t.AddDate(n, 0, 0)
+	default:
+		return t.AddDate(0, 0, n)
+	}
+}
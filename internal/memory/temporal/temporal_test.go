@@ -0,0 +1,56 @@
+package temporal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	// Wednesday
+	now := time.Date(2024, 6, 12, 15, 30, 0, 0, time.UTC)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	testCases := []struct {
+		name      string
+		query     string
+		wantStart time.Time
+		wantEnd   time.Time
+		wantQuery string
+	}{
+		{"yesterday", "what did I say yesterday about deployments", day(2024, 6, 11), day(2024, 6, 12), "what did I say about deployments"},
+		{"today", "notes from today", day(2024, 6, 12), day(2024, 6, 13), "notes"},
+		{"last n days", "deploy issues in the last 3 days", now.AddDate(0, 0, -3), now, "deploy issues"},
+		{"n weeks ago", "what happened 2 weeks ago", day(2024, 5, 29), day(2024, 6, 5), "what happened"},
+		{"this week", "plans this week", day(2024, 6, 10), day(2024, 6, 17), "plans"},
+		{"last month", "budget decisions last month", day(2024, 5, 1), day(2024, 6, 1), "budget decisions"},
+		{"last weekday", "standup notes last monday", day(2024, 6, 10), day(2024, 6, 11), "standup notes"},
+		{"since date", "changes since 2024-06-01", day(2024, 6, 1), now, "changes"},
+		{"before date", "decisions before 2024-01-01", time.Time{}, day(2024, 1, 1), "decisions"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tr, stripped, ok := Parse(tc.query, now)
+			if !ok {
+				t.Fatalf("expected %q to contain a temporal expression", tc.query)
+			}
+			if !tr.Start.Equal(tc.wantStart) || !tr.End.Equal(tc.wantEnd) {
+				t.Fatalf("unexpected range: got [%v, %v) want [%v, %v)", tr.Start, tr.End, tc.wantStart, tc.wantEnd)
+			}
+			if stripped != tc.wantQuery {
+				t.Fatalf("unexpected stripped query: got %q want %q", stripped, tc.wantQuery)
+			}
+		})
+	}
+}
+
+func TestParseWithoutTemporalExpression(t *testing.T) {
+	query := "prefer monday meetings"
+	_, stripped, ok := Parse(query, time.Now())
+	if ok {
+		t.Fatal("expected no temporal expression")
+	}
+	if stripped != query {
+		t.Fatalf("expected query to be unchanged, got %q", stripped)
+	}
+}
@@ -0,0 +1,219 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/memory/embedtext"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/memory/memutils"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// EmbeddingWorker drains the embedding queue in the background so foreground
+// saves never wait on embedding latency.
+type EmbeddingWorker struct {
+	store           *store.Store
+	embeddingClient client.EmbeddingClient
+	model           types.Model
+	template        *embedtext.Template
+	config          utils.EmbeddingQueueConfig
+	now             func() time.Time
+}
+
+// A failed job waits retryBackoff before its first retry, twice as long
+// before each one after, and never more than maxRetryBackoff, so a bad
+// provider key or an outage does not turn every poll into a burst of
+// failing requests.
+const (
+	retryBackoff    = 30 * time.Second
+	maxRetryBackoff = time.Hour
+)
+
+// NewEmbeddingWorker creates a worker that embeds queued rows with the given
+// model. Memories are rendered with tmpl first; history is embedded as is.
+func NewEmbeddingWorker(
+	s *store.Store,
+	embeddingClient client.EmbeddingClient,
+	model types.Model,
+	tmpl *embedtext.Template,
+	config utils.EmbeddingQueueConfig,
+) *EmbeddingWorker {
+	return &EmbeddingWorker{
+		store:           s,
+		embeddingClient: embeddingClient,
+		model:           model,
+		template:        tmpl,
+		config:          config,
+		now:             time.Now,
+	}
+}
+
+// Run drains the queue until ctx is cancelled, polling for new jobs between batches.
+func (w *EmbeddingWorker) Run(ctx context.Context) error {
+	poll := time.Duration(w.config.PollIntervalSecs) * time.Second
+	if poll <= 0 {
+		poll = 5 * time.Second
+	}
+
+	for {
+		processed, err := w.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("embedding worker: %v", err)
+		}
+
+		// Keep going without waiting while there is a full backlog.
+		if processed > 0 && processed >= w.batchSize() {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(poll):
+		}
+	}
+}
+
+// RunOnce processes a single batch of due jobs and returns how many were
+// embedded. Jobs waiting out a retry backoff are left for a later batch.
+func (w *EmbeddingWorker) RunOnce(ctx context.Context) (int, error) {
+	jobs, err := w.store.DueEmbeddings(w.maxAttempts(), w.batchSize(), w.now())
+	if err != nil {
+		return 0, err
+	}
+	if len(jobs) == 0 {
+		return 0, nil
+	}
+
+	concurrency := w.config.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	// A shared ticker spaces out provider calls across all goroutines.
+	var limiter <-chan time.Time
+	if w.config.RequestsPerSecond > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / w.config.RequestsPerSecond))
+		defer ticker.Stop()
+		limiter = ticker.C
+	}
+
+	jobsCh := make(chan memtypes.EmbeddingJob)
+	var wg sync.WaitGroup
+	var embedded atomic.Int64
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobsCh {
+				if limiter != nil {
+					select {
+					case <-ctx.Done():
+						return
+					case <-limiter:
+					}
+				}
+				if w.process(ctx, job) {
+					embedded.Add(1)
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, job := range jobs {
+		select {
+		case <-ctx.Done():
+			break feed
+		case jobsCh <- job:
+		}
+	}
+	close(jobsCh)
+	wg.Wait()
+
+	return int(embedded.Load()), ctx.Err()
+}
+
+// process embeds a single job and reports whether it succeeded.
+func (w *EmbeddingWorker) process(ctx context.Context, job memtypes.EmbeddingJob) bool {
+	// The target row was deleted after it was queued; nothing left to embed.
+	if job.Text == "" {
+		_ = w.store.CompleteEmbeddingJob(job.ID)
+		return false
+	}
+
+	text := job.Text
+	if job.TargetKind == memtypes.EmbeddingTargetMemory {
+		text = w.template.Render(memtypes.MemoryItem{
+			Text:       job.Text,
+			Tags:       job.Tags,
+			Kind:       job.Kind,
+			Source:     job.Source,
+			SourcePath: job.SourcePath,
+		})
+	}
+
+	embedding, err := w.embeddingClient.Embed(ctx, w.model, text)
+	if err != nil {
+		w.fail(job, err)
+		return false
+	}
+	embedding = memutils.NormalizeVector(embedding)
+
+	switch job.TargetKind {
+	case memtypes.EmbeddingTargetMemory:
+		err = w.store.UpdateMemoryEmbedding(job.TargetID, embedding, w.model.ModelID, len(embedding), w.model.Provider)
+	case memtypes.EmbeddingTargetHistory:
+		err = w.store.UpdateHistoryEmbedding(job.TargetID, embedding, w.model.ModelID, len(embedding), w.model.Provider)
+	default:
+		err = fmt.Errorf("unknown embedding target kind: %s", job.TargetKind)
+	}
+	if err != nil {
+		w.fail(job, err)
+		return false
+	}
+
+	if err := w.store.CompleteEmbeddingJob(job.ID); err != nil {
+		log.Printf("embedding worker: failed to dequeue job %d: %v", job.ID, err)
+	}
+	return true
+}
+
+func (w *EmbeddingWorker) fail(job memtypes.EmbeddingJob, err error) {
+	if ferr := w.store.FailEmbeddingJob(job.ID, err.Error(), w.now().Add(retryDelay(job.Attempts))); ferr != nil {
+		log.Printf("embedding worker: failed to record failure for job %d: %v", job.ID, ferr)
+	}
+}
+
+// retryDelay returns how long a job that failed after attempts earlier
+// attempts waits before its next one.
+func retryDelay(attempts int) time.Duration {
+	delay := retryBackoff
+	for i := 0; i < attempts && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryBackoff)
+}
+
+func (w *EmbeddingWorker) batchSize() int {
+	if w.config.BatchSize <= 0 {
+		return 32
+	}
+	return w.config.BatchSize
+}
+
+func (w *EmbeddingWorker) maxAttempts() int {
+	if w.config.MaxAttempts <= 0 {
+		return 5
+	}
+	return w.config.MaxAttempts
+}
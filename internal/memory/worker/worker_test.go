@@ -0,0 +1,276 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/embedtext"
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+	_ "modernc.org/sqlite"
+)
+
+// fakeEmbeddingClient returns a fixed vector, or an error when fail is set,
+// and records the texts it embedded.
+type fakeEmbeddingClient struct {
+	fail  bool
+	mu    sync.Mutex
+	texts []string
+}
+
+func (f *fakeEmbeddingClient) Embed(ctx context.Context, model types.Model, text string) ([]float32, error) {
+	f.mu.Lock()
+	f.texts = append(f.texts, text)
+	f.mu.Unlock()
+	if f.fail {
+		return nil, errors.New("embedding provider unavailable")
+	}
+	return []float32{3, 4}, nil
+}
+
+func (f *fakeEmbeddingClient) EmbedBatch(ctx context.Context, model types.Model, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, t := range texts {
+		v, err := f.Embed(ctx, model, t)
+		if err != nil {
+			return nil, err
+		}
+		vectors[i] = v
+	}
+	return vectors, nil
+}
+
+func (f *fakeEmbeddingClient) Dimensions(model types.Model) int {
+	return 2
+}
+
+func newTestStore(t *testing.T) *store.Store {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+
+	memStore, err := store.NewStoreWithDB(db)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = memStore.Close()
+	})
+
+	return memStore
+}
+
+func saveQueuedMemory(t *testing.T, memStore *store.Store, text string) *store.MemoryItem {
+	t.Helper()
+
+	item := &store.MemoryItem{
+		Text:     text,
+		Source:   store.SourceExplicit,
+		Provider: "fake",
+		ModelID:  "fake-embedding",
+	}
+	if err := memStore.SaveMemory(item); err != nil {
+		t.Fatalf("save memory: %v", err)
+	}
+	if err := memStore.EnqueueEmbedding(store.EmbeddingTargetMemory, item.ID); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	return item
+}
+
+func testQueueConfig() utils.EmbeddingQueueConfig {
+	return utils.EmbeddingQueueConfig{
+		Concurrency: 2,
+		BatchSize:   10,
+		MaxAttempts: 2,
+	}
+}
+
+func TestRunOnceEmbedsQueuedMemories(t *testing.T) {
+	memStore := newTestStore(t)
+	item := saveQueuedMemory(t, memStore, "queued memory")
+
+	w := NewEmbeddingWorker(memStore, &fakeEmbeddingClient{}, types.Model{Provider: "fake", ModelID: "fake-embedding"}, nil, testQueueConfig())
+	processed, err := w.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("run once: %v", err)
+	}
+	if processed != 1 {
+		t.Fatalf("expected 1 processed job, got %d", processed)
+	}
+
+	memories, err := memStore.GetAllMemories()
+	if err != nil {
+		t.Fatalf("get all memories: %v", err)
+	}
+	if len(memories) != 1 || memories[0].ID != item.ID {
+		t.Fatalf("unexpected memories: %+v", memories)
+	}
+	if memories[0].Dim != 2 || len(memories[0].Embedding) != 2 {
+		t.Fatalf("expected 2-dim embedding, got dim=%d len=%d", memories[0].Dim, len(memories[0].Embedding))
+	}
+	if got := memories[0].Embedding[0]; got < 0.59 || got > 0.61 {
+		t.Fatalf("expected normalized embedding, got %v", memories[0].Embedding)
+	}
+
+	jobs, err := memStore.PendingEmbeddings(10, 10)
+	if err != nil {
+		t.Fatalf("pending embeddings: %v", err)
+	}
+	if len(jobs) != 0 {
+		t.Fatalf("expected empty queue, got %d jobs", len(jobs))
+	}
+}
+
+func TestRunOnceStopsRetryingAfterMaxAttempts(t *testing.T) {
+	memStore := newTestStore(t)
+	saveQueuedMemory(t, memStore, "never embeds")
+
+	w := NewEmbeddingWorker(memStore, &fakeEmbeddingClient{fail: true}, types.Model{Provider: "fake", ModelID: "fake-embedding"}, nil, testQueueConfig())
+	now := time.Now()
+	w.now = func() time.Time { return now }
+	for i := 0; i < 3; i++ {
+		if _, err := w.RunOnce(context.Background()); err != nil {
+			t.Fatalf("run once: %v", err)
+		}
+		now = now.Add(maxRetryBackoff)
+	}
+
+	jobs, err := memStore.PendingEmbeddings(2, 10)
+	if err != nil {
+		t.Fatalf("pending embeddings: %v", err)
+	}
+	if len(jobs) != 0 {
+		t.Fatalf("expected exhausted job to be skipped, got %d", len(jobs))
+	}
+
+	jobs, err = memStore.PendingEmbeddings(10, 10)
+	if err != nil {
+		t.Fatalf("pending embeddings: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].Attempts != 2 {
+		t.Fatalf("expected job with 2 attempts to remain queued, got %+v", jobs)
+	}
+}
+
+func TestRunOnceBacksOffFailedJobs(t *testing.T) {
+	memStore := newTestStore(t)
+	saveQueuedMemory(t, memStore, "never embeds")
+
+	client := &fakeEmbeddingClient{fail: true}
+	config := testQueueConfig()
+	config.MaxAttempts = 10
+	w := NewEmbeddingWorker(memStore, client, types.Model{Provider: "fake", ModelID: "fake-embedding"}, nil, config)
+	now := time.Now()
+	w.now = func() time.Time { return now }
+
+	run := func() {
+		t.Helper()
+		if _, err := w.RunOnce(context.Background()); err != nil {
+			t.Fatalf("run once: %v", err)
+		}
+	}
+
+	run()
+	run()
+	if len(client.texts) != 1 {
+		t.Fatalf("expected the failed job to wait before its retry, got %d attempts", len(client.texts))
+	}
+
+	now = now.Add(retryBackoff)
+	run()
+	if len(client.texts) != 2 {
+		t.Fatalf("expected a retry once the backoff passed, got %d attempts", len(client.texts))
+	}
+
+	// The second failure doubles the wait.
+	now = now.Add(retryBackoff)
+	run()
+	if len(client.texts) != 2 {
+		t.Fatalf("expected the backoff to double, got %d attempts", len(client.texts))
+	}
+	now = now.Add(retryBackoff)
+	run()
+	if len(client.texts) != 3 {
+		t.Fatalf("expected a retry after the doubled backoff, got %d attempts", len(client.texts))
+	}
+
+	// Doctor still sees the job while it waits.
+	jobs, err := memStore.PendingEmbeddings(10, 10)
+	if err != nil {
+		t.Fatalf("pending embeddings: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].Attempts != 3 {
+		t.Fatalf("expected the waiting job to stay pending, got %+v", jobs)
+	}
+}
+
+func TestRetryDelayIsCapped(t *testing.T) {
+	if got := retryDelay(0); got != retryBackoff {
+		t.Fatalf("expected the first retry after %v, got %v", retryBackoff, got)
+	}
+	if got := retryDelay(2); got != 4*retryBackoff {
+		t.Fatalf("expected the third retry after %v, got %v", 4*retryBackoff, got)
+	}
+	if got := retryDelay(100); got != maxRetryBackoff {
+		t.Fatalf("expected the delay capped at %v, got %v", maxRetryBackoff, got)
+	}
+}
+
+func TestRunOnceDropsJobsForDeletedRows(t *testing.T) {
+	memStore := newTestStore(t)
+	item := saveQueuedMemory(t, memStore, "deleted before embedding")
+	if err := memStore.DeleteMemory(item.ID); err != nil {
+		t.Fatalf("delete memory: %v", err)
+	}
+
+	w := NewEmbeddingWorker(memStore, &fakeEmbeddingClient{}, types.Model{Provider: "fake", ModelID: "fake-embedding"}, nil, testQueueConfig())
+	processed, err := w.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("run once: %v", err)
+	}
+	if processed != 0 {
+		t.Fatalf("expected no embedded rows, got %d", processed)
+	}
+
+	jobs, err := memStore.PendingEmbeddings(10, 10)
+	if err != nil {
+		t.Fatalf("pending embeddings: %v", err)
+	}
+	if len(jobs) != 0 {
+		t.Fatalf("expected orphaned job to be removed, got %d", len(jobs))
+	}
+}
+
+func TestRunOnceRendersEmbeddingTemplate(t *testing.T) {
+	memStore := newTestStore(t)
+	item := &store.MemoryItem{Text: "prefers tabs", Tags: []string{"go", "style"}, Source: store.SourceExplicit}
+	if err := memStore.SaveMemory(item); err != nil {
+		t.Fatalf("save memory: %v", err)
+	}
+	if err := memStore.EnqueueEmbedding(store.EmbeddingTargetMemory, item.ID); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	tmpl, err := embedtext.Parse("{{.Text}} (tags: {{.Tags}})")
+	if err != nil {
+		t.Fatalf("parse template: %v", err)
+	}
+	embedder := &fakeEmbeddingClient{}
+	w := NewEmbeddingWorker(memStore, embedder, types.Model{Provider: "fake", ModelID: "fake-embedding"}, tmpl, testQueueConfig())
+	if _, err := w.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once: %v", err)
+	}
+	if len(embedder.texts) != 1 || embedder.texts[0] != "prefers tabs (tags: go, style)" {
+		t.Fatalf("expected the rendered template to be embedded, got %q", embedder.texts)
+	}
+}
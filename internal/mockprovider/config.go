@@ -0,0 +1,41 @@
+package mockprovider
+
+import (
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/consts"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// Model IDs the mock config uses.
+const (
+	ChatModel      = "gpt-mock"
+	EmbeddingModel = "text-embedding-3-small"
+)
+
+// Config returns the default config with every model served by the mock.
+func (s *Server) Config() *utils.Config {
+	config := utils.DefaultConfig()
+	config.Providers.OpenAI = utils.OpenAIProviderConfig{APIKey: APIKey, BaseURL: s.BaseURL()}
+	chat := types.Model{Provider: consts.ProviderOpenAI, ModelID: ChatModel}
+	config.Model.ChatModel = &chat
+	config.Model.TitleModel = &chat
+	config.Model.ThinkModel = &chat
+	config.Model.ToolModel = &chat
+	config.Model.EmbeddingModel = &types.Model{Provider: consts.ProviderOpenAI, ModelID: EmbeddingModel}
+	return config
+}
+
+// Install points HOME at a fresh directory holding config as its settings, so
+// code that loads the config and opens the memory store uses the mock and an
+// empty database. It returns the directory.
+func Install(t testing.TB, config *utils.Config) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := utils.SaveConfig(config); err != nil {
+		t.Fatalf("save mock config: %v", err)
+	}
+	return home
+}
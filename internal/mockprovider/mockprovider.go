@@ -0,0 +1,420 @@
+// Package mockprovider serves an OpenAI-compatible API from httptest, so the
+// chat, embedding, retrieval, and MCP paths can be tested end to end without
+// API keys. Chat replies are queued or computed per request, embeddings are
+// deterministic bags of words, and every request is recorded.
+package mockprovider
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode"
+)
+
+const (
+	// APIKey is the key the mock expects as a bearer token.
+	APIKey = "mock-api-key"
+	// Dimensions is the length of every embedding, matching what the OpenAI
+	// embedding client reports for text-embedding-3-small.
+	Dimensions = 1536
+)
+
+// Message is one chat message of a recorded request.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Request is a recorded API call.
+type Request struct {
+	Path     string
+	Model    string
+	Stream   bool
+	Messages []Message
+	// Tools names the functions offered to the model.
+	Tools []string
+	// Input is the text sent to the embeddings endpoint.
+	Input []string
+}
+
+// LastUserMessage returns the content of the request's last user message.
+func (r Request) LastUserMessage() string {
+	for i := len(r.Messages) - 1; i >= 0; i-- {
+		if r.Messages[i].Role == "user" {
+			return r.Messages[i].Content
+		}
+	}
+	return ""
+}
+
+// ToolCall is a function call in a chat reply.
+type ToolCall struct {
+	Name      string
+	Arguments string
+}
+
+// Reply is what the mock answers a chat request with.
+type Reply struct {
+	Content   string
+	ToolCalls []ToolCall
+}
+
+// Server is a running mock API.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	replies  []Reply
+	failures []failure
+	requests []Request
+	respond  func(Request) Reply
+}
+
+type failure struct {
+	status  int
+	message string
+}
+
+// New starts a mock API, closed when the test ends. Chat requests are answered
+// from the queue, then by echoing the last user message.
+func New(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{respond: Echo}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/chat/completions", s.handleChat)
+	mux.HandleFunc("POST /v1/embeddings", s.handleEmbeddings)
+	mux.HandleFunc("GET /v1/models", s.handleModels)
+	s.Server = httptest.NewServer(s.authorize(mux))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Echo answers with the last user message.
+func Echo(r Request) Reply {
+	return Reply{Content: r.LastUserMessage()}
+}
+
+// BaseURL is the provider base_url to configure.
+func (s *Server) BaseURL() string {
+	return s.URL + "/v1"
+}
+
+// QueueReply queues chat replies, answered in order before Respond is used.
+func (s *Server) QueueReply(replies ...Reply) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replies = append(s.replies, replies...)
+}
+
+// QueueText queues plain-text chat replies.
+func (s *Server) QueueText(texts ...string) {
+	for _, text := range texts {
+		s.QueueReply(Reply{Content: text})
+	}
+}
+
+// Respond sets how chat requests are answered once the queue is empty.
+func (s *Server) Respond(respond func(Request) Reply) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.respond = respond
+}
+
+// Fail makes the next request fail with an OpenAI-style error. The SDK retries
+// 408, 409, 429 and 5xx responses, so queue one failure per attempt for those.
+func (s *Server) Fail(status int, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, failure{status: status, message: message})
+}
+
+// Requests returns the recorded requests, oldest first.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// ChatRequests returns the recorded chat requests, oldest first.
+func (s *Server) ChatRequests() []Request {
+	var chats []Request
+	for _, r := range s.Requests() {
+		if r.Path == "/v1/chat/completions" {
+			chats = append(chats, r)
+		}
+	}
+	return chats
+}
+
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+APIKey {
+			writeError(w, http.StatusUnauthorized, "Incorrect API key provided.", "invalid_api_key")
+			return
+		}
+		s.mu.Lock()
+		var fail *failure
+		if len(s.failures) > 0 {
+			fail = &s.failures[0]
+			s.failures = s.failures[1:]
+		}
+		s.mu.Unlock()
+		if fail != nil {
+			writeError(w, fail.status, fail.message, "")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) record(r Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r)
+}
+
+// reply takes the next queued reply, or computes one.
+func (s *Server) reply(r Request) Reply {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.replies) > 0 {
+		reply := s.replies[0]
+		s.replies = s.replies[1:]
+		return reply
+	}
+	return s.respond(r)
+}
+
+type chatRequest struct {
+	Model    string `json:"model"`
+	Stream   bool   `json:"stream"`
+	Messages []struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	} `json:"messages"`
+	Tools []struct {
+		Function struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	} `json:"tools"`
+}
+
+func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
+	var body chatRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error(), "")
+		return
+	}
+	req := Request{Path: r.URL.Path, Model: body.Model, Stream: body.Stream}
+	for _, m := range body.Messages {
+		req.Messages = append(req.Messages, Message{Role: m.Role, Content: content(m.Content)})
+	}
+	for _, t := range body.Tools {
+		req.Tools = append(req.Tools, t.Function.Name)
+	}
+	s.record(req)
+
+	reply := s.reply(req)
+	id := fmt.Sprintf("chatcmpl-mock-%d", len(s.Requests()))
+	created := time.Now().Unix()
+	usage := map[string]int{
+		"prompt_tokens":     countWords(req.Messages),
+		"completion_tokens": len(strings.Fields(reply.Content)),
+	}
+	usage["total_tokens"] = usage["prompt_tokens"] + usage["completion_tokens"]
+
+	message := map[string]any{"role": "assistant", "content": reply.Content}
+	finish := "stop"
+	if len(reply.ToolCalls) > 0 {
+		var calls []map[string]any
+		for i, call := range reply.ToolCalls {
+			calls = append(calls, map[string]any{
+				"index":    i,
+				"id":       fmt.Sprintf("call_%d", i+1),
+				"type":     "function",
+				"function": map[string]string{"name": call.Name, "arguments": call.Arguments},
+			})
+		}
+		message["tool_calls"] = calls
+		finish = "tool_calls"
+	}
+
+	if !body.Stream {
+		writeJSON(w, map[string]any{
+			"id":      id,
+			"object":  "chat.completion",
+			"created": created,
+			"model":   body.Model,
+			"choices": []map[string]any{{"index": 0, "message": message, "finish_reason": finish}},
+			"usage":   usage,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	chunk := func(delta map[string]any, finishReason any, usage any) {
+		data, _ := json.Marshal(map[string]any{
+			"id":      id,
+			"object":  "chat.completion.chunk",
+			"created": created,
+			"model":   body.Model,
+			"choices": []map[string]any{{"index": 0, "delta": delta, "finish_reason": finishReason}},
+			"usage":   usage,
+		})
+		fmt.Fprintf(w, "data: %s\n\n", data)
+	}
+	chunk(map[string]any{"role": "assistant", "content": ""}, nil, nil)
+	for _, word := range splitKeep(reply.Content) {
+		chunk(map[string]any{"content": word}, nil, nil)
+	}
+	if calls, ok := message["tool_calls"]; ok {
+		chunk(map[string]any{"tool_calls": calls}, nil, nil)
+	}
+	chunk(map[string]any{}, finish, usage)
+	fmt.Fprint(w, "data: [DONE]\n\n")
+}
+
+type embeddingRequest struct {
+	Model          string          `json:"model"`
+	Input          json.RawMessage `json:"input"`
+	EncodingFormat string          `json:"encoding_format"`
+}
+
+func (s *Server) handleEmbeddings(w http.ResponseWriter, r *http.Request) {
+	var body embeddingRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error(), "")
+		return
+	}
+	var inputs []string
+	if err := json.Unmarshal(body.Input, &inputs); err != nil {
+		var input string
+		if err := json.Unmarshal(body.Input, &input); err != nil {
+			writeError(w, http.StatusBadRequest, "input must be a string or an array of strings", "")
+			return
+		}
+		inputs = []string{input}
+	}
+	s.record(Request{Path: r.URL.Path, Model: body.Model, Input: inputs})
+
+	data := make([]map[string]any, len(inputs))
+	for i, input := range inputs {
+		vector := Embed(input)
+		var embedding any = vector
+		if body.EncodingFormat == "base64" {
+			buf := make([]byte, 4*len(vector))
+			for j, v := range vector {
+				binary.LittleEndian.PutUint32(buf[4*j:], math.Float32bits(v))
+			}
+			embedding = base64.StdEncoding.EncodeToString(buf)
+		}
+		data[i] = map[string]any{"object": "embedding", "index": i, "embedding": embedding}
+	}
+	writeJSON(w, map[string]any{
+		"object": "list",
+		"model":  body.Model,
+		"data":   data,
+		"usage":  map[string]int{"prompt_tokens": len(inputs), "total_tokens": len(inputs)},
+	})
+}
+
+func (s *Server) handleModels(w http.ResponseWriter, r *http.Request) {
+	s.record(Request{Path: r.URL.Path})
+	var data []map[string]any
+	for _, id := range []string{ChatModel, EmbeddingModel} {
+		data = append(data, map[string]any{"id": id, "object": "model", "created": 0, "owned_by": "mock"})
+	}
+	writeJSON(w, map[string]any{"object": "list", "data": data})
+}
+
+// Embed is the mock's embedding of text: each word is hashed into one of
+// Dimensions buckets and the counts are normalized, so texts sharing words
+// are similar and identical texts match exactly.
+func Embed(text string) []float32 {
+	vector := make([]float32, Dimensions)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+'
+	})
+	if len(words) == 0 {
+		vector[0] = 1
+		return vector
+	}
+	for _, word := range words {
+		h := fnv.New32a()
+		h.Write([]byte(word))
+		vector[h.Sum32()%Dimensions]++
+	}
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v * v)
+	}
+	norm = math.Sqrt(norm)
+	for i := range vector {
+		vector[i] = float32(float64(vector[i]) / norm)
+	}
+	return vector
+}
+
+// content flattens a message's content, a string or a list of text parts.
+func content(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	var parts []struct {
+		Text string `json:"text"`
+	}
+	_ = json.Unmarshal(raw, &parts)
+	var sb strings.Builder
+	for _, p := range parts {
+		sb.WriteString(p.Text)
+	}
+	return sb.String()
+}
+
+func countWords(messages []Message) int {
+	n := 0
+	for _, m := range messages {
+		n += len(strings.Fields(m.Content))
+	}
+	return n
+}
+
+// splitKeep splits text into words that keep their trailing whitespace, so
+// the streamed chunks join back into text.
+func splitKeep(text string) []string {
+	var chunks []string
+	start := 0
+	for i := 1; i < len(text); i++ {
+		if unicode.IsSpace(rune(text[i-1])) && !unicode.IsSpace(rune(text[i])) {
+			chunks = append(chunks, text[start:i])
+			start = i
+		}
+	}
+	if start < len(text) {
+		chunks = append(chunks, text[start:])
+	}
+	return chunks
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]any{"message": message, "type": "invalid_request_error", "code": code},
+	})
+}
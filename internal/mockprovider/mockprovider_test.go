@@ -0,0 +1,121 @@
+package mockprovider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/memory/memutils"
+	"github.com/austiecodes/gomor/internal/provider"
+)
+
+func TestChatStreamsQueuedThenEchoedReplies(t *testing.T) {
+	server := New(t)
+	config := server.Config()
+	qc, err := provider.NewQueryClient(config, config.Model.ChatModel.Provider)
+	if err != nil {
+		t.Fatalf("NewQueryClient: %v", err)
+	}
+
+	server.QueueText("first  reply\nwith lines")
+	for _, want := range []string{"first  reply\nwith lines", "echo me"} {
+		stream, err := qc.ChatStreamWithContext(context.Background(), *config.Model.ChatModel, "be brief", "echo me")
+		if err != nil {
+			t.Fatalf("ChatStream: %v", err)
+		}
+		var sb strings.Builder
+		for stream.Next() {
+			sb.WriteString(stream.GetChunk())
+		}
+		if err := stream.Err(); err != nil {
+			t.Fatalf("stream: %v", err)
+		}
+		stream.Close()
+		if sb.String() != want {
+			t.Fatalf("expected %q, got %q", want, sb.String())
+		}
+	}
+
+	requests := server.ChatRequests()
+	if len(requests) != 2 || requests[0].Model != ChatModel || !requests[0].Stream {
+		t.Fatalf("unexpected requests %+v", requests)
+	}
+	if requests[0].Messages[0].Role != "system" || requests[0].LastUserMessage() != "echo me" {
+		t.Fatalf("unexpected messages %+v", requests[0].Messages)
+	}
+}
+
+func TestChatWithToolsReturnsToolCalls(t *testing.T) {
+	server := New(t)
+	config := server.Config()
+	qc, err := provider.NewQueryClient(config, config.Model.ChatModel.Provider)
+	if err != nil {
+		t.Fatalf("NewQueryClient: %v", err)
+	}
+
+	server.QueueReply(Reply{ToolCalls: []ToolCall{{Name: "fs__read", Arguments: `{"path":"a.txt"}`}}})
+	tools := []client.Tool{{Name: "fs__read", Parameters: map[string]any{"type": "object"}}}
+	reply, err := qc.(client.ToolClient).ChatWithTools(context.Background(), *config.Model.ChatModel, "", []client.ToolMessage{{Role: client.ToolRoleUser, Content: "read a.txt"}}, tools)
+	if err != nil {
+		t.Fatalf("ChatWithTools: %v", err)
+	}
+	if len(reply.ToolCalls) != 1 || reply.ToolCalls[0].Name != "fs__read" || reply.ToolCalls[0].Arguments != `{"path":"a.txt"}` {
+		t.Fatalf("unexpected reply %+v", reply)
+	}
+	if got := server.ChatRequests()[0].Tools; len(got) != 1 || got[0] != "fs__read" {
+		t.Fatalf("expected the tool to be offered, got %v", got)
+	}
+}
+
+func TestEmbeddingsMatchEmbed(t *testing.T) {
+	server := New(t)
+	config := server.Config()
+	model := *config.Model.EmbeddingModel
+	ec, err := provider.NewEmbeddingClient(config, model.Provider)
+	if err != nil {
+		t.Fatalf("NewEmbeddingClient: %v", err)
+	}
+
+	vectors, err := ec.EmbedBatch(context.Background(), model, []string{"Go channels and goroutines", "Baking sourdough bread"})
+	if err != nil {
+		t.Fatalf("EmbedBatch: %v", err)
+	}
+	if len(vectors) != 2 || len(vectors[0]) != ec.Dimensions(model) {
+		t.Fatalf("expected two %d-dim vectors, got %d", ec.Dimensions(model), len(vectors))
+	}
+
+	query := Embed("goroutines and channels in Go")
+	related := memutils.CosineSimilarity(query, vectors[0])
+	unrelated := memutils.CosineSimilarity(query, vectors[1])
+	if related < 0.8 || unrelated > 0.1 {
+		t.Fatalf("expected shared words to match, got related=%.2f unrelated=%.2f", related, unrelated)
+	}
+}
+
+func TestFailAndAuth(t *testing.T) {
+	server := New(t)
+	config := server.Config()
+	qc, err := provider.NewQueryClient(config, config.Model.ChatModel.Provider)
+	if err != nil {
+		t.Fatalf("NewQueryClient: %v", err)
+	}
+
+	server.Fail(http.StatusBadRequest, "context length exceeded")
+	if _, err := qc.ListModels(context.Background()); err == nil || !strings.Contains(err.Error(), "context length exceeded") {
+		t.Fatalf("expected the queued failure, got %v", err)
+	}
+	models, err := qc.ListModels(context.Background())
+	if err != nil || len(models) != 2 {
+		t.Fatalf("expected the mock models after the failure, got %v, %v", models, err)
+	}
+
+	config.Providers.OpenAI.APIKey = "wrong"
+	qc, _ = provider.NewQueryClient(config, config.Model.ChatModel.Provider)
+	_, err = qc.ListModels(context.Background())
+	if !errors.Is(err, client.ErrAuth) {
+		t.Fatalf("expected an auth error for a wrong key, got %v", err)
+	}
+}
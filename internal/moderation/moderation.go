@@ -0,0 +1,133 @@
+// Package moderation screens prompts, answers, and extracted memories with
+// local rules and, optionally, a provider's moderation endpoint before they
+// are stored.
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/provider"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// ErrBlocked is returned for text that moderation keeps out of storage.
+var ErrBlocked = errors.New("blocked by moderation")
+
+// Verdict lists the blocked categories text was flagged for.
+type Verdict struct {
+	Categories []string
+}
+
+// Blocked reports whether the text must not be stored.
+func (v Verdict) Blocked() bool {
+	return len(v.Categories) > 0
+}
+
+// Placeholder is stored in place of blocked text.
+func (v Verdict) Placeholder() string {
+	return fmt.Sprintf("[withheld by moderation: %s]", strings.Join(v.Categories, ", "))
+}
+
+// Err returns ErrBlocked naming the categories, or nil when nothing is blocked.
+func (v Verdict) Err() error {
+	if !v.Blocked() {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrBlocked, strings.Join(v.Categories, ", "))
+}
+
+type rule struct {
+	category string
+	pattern  *regexp.Regexp
+}
+
+// Moderator checks text against the configured rules and provider. A nil
+// Moderator allows everything.
+type Moderator struct {
+	client  client.ModerationClient
+	rules   []rule
+	actions map[string]string
+}
+
+// New builds a Moderator from cfg. c may be nil to use the rules alone.
+func New(cfg utils.ModerationConfig, c client.ModerationClient) (*Moderator, error) {
+	for category, action := range cfg.Categories {
+		if action != utils.ModerationBlock && action != utils.ModerationAllow {
+			return nil, fmt.Errorf("invalid moderation action %q for %s (valid: block, allow)", action, category)
+		}
+	}
+
+	m := &Moderator{client: c, actions: cfg.Categories}
+	categories := make([]string, 0, len(cfg.Rules))
+	for category := range cfg.Rules {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	for _, category := range categories {
+		for _, expr := range cfg.Rules[category] {
+			pattern, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid moderation rule for %s: %w", category, err)
+			}
+			m.rules = append(m.rules, rule{category: category, pattern: pattern})
+		}
+	}
+	return m, nil
+}
+
+// FromConfig builds the Moderator configured in cfg, or returns nil when
+// moderation is off. Offline, only the local rules are applied.
+func FromConfig(cfg *utils.Config) (*Moderator, error) {
+	modCfg := cfg.Moderation
+	if modCfg.Provider == "" && len(modCfg.Rules) == 0 {
+		return nil, nil
+	}
+
+	var c client.ModerationClient
+	if modCfg.Provider != "" && !cfg.Offline {
+		var err error
+		c, err = provider.NewModerationClient(cfg, modCfg.Provider)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create moderation client: %w", err)
+		}
+	}
+	return New(modCfg, c)
+}
+
+// Check returns the blocked categories text is flagged for.
+func (m *Moderator) Check(ctx context.Context, text string) (Verdict, error) {
+	if m == nil || strings.TrimSpace(text) == "" {
+		return Verdict{}, nil
+	}
+
+	flagged := make(map[string]bool)
+	for _, r := range m.rules {
+		if r.pattern.MatchString(text) {
+			flagged[r.category] = true
+		}
+	}
+	if m.client != nil {
+		categories, err := m.client.Moderate(ctx, text)
+		if err != nil {
+			return Verdict{}, err
+		}
+		for _, category := range categories {
+			flagged[category] = true
+		}
+	}
+
+	var v Verdict
+	for category := range flagged {
+		if m.actions[category] != utils.ModerationAllow {
+			v.Categories = append(v.Categories, category)
+		}
+	}
+	sort.Strings(v.Categories)
+	return v, nil
+}
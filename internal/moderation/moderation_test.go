@@ -0,0 +1,94 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+type fakeModerationClient struct {
+	categories []string
+	err        error
+}
+
+func (c *fakeModerationClient) Moderate(context.Context, string) ([]string, error) {
+	return c.categories, c.err
+}
+
+func TestCheckCombinesRulesAndProvider(t *testing.T) {
+	m, err := New(utils.ModerationConfig{
+		Rules:      map[string][]string{"secrets": {`(?i)password:\s*\S+`}},
+		Categories: map[string]string{"violence": utils.ModerationAllow},
+	}, &fakeModerationClient{categories: []string{"violence", "harassment"}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	v, err := m.Check(context.Background(), "Password: hunter2")
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(v.Categories) != 2 || v.Categories[0] != "harassment" || v.Categories[1] != "secrets" {
+		t.Fatalf("expected harassment and secrets blocked and violence allowed, got %v", v.Categories)
+	}
+	if !errors.Is(v.Err(), ErrBlocked) {
+		t.Fatalf("expected ErrBlocked, got %v", v.Err())
+	}
+	if v.Placeholder() != "[withheld by moderation: harassment, secrets]" {
+		t.Fatalf("unexpected placeholder %q", v.Placeholder())
+	}
+}
+
+func TestCheckAllowsCleanText(t *testing.T) {
+	m, err := New(utils.ModerationConfig{Rules: map[string][]string{"secrets": {`sk-\w+`}}}, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	v, err := m.Check(context.Background(), "I prefer tabs")
+	if err != nil || v.Blocked() || v.Err() != nil {
+		t.Fatalf("expected clean text to pass, got %v, %v", v, err)
+	}
+
+	var off *Moderator
+	if v, err := off.Check(context.Background(), "sk-abc"); err != nil || v.Blocked() {
+		t.Fatalf("expected a nil moderator to allow everything, got %v, %v", v, err)
+	}
+}
+
+func TestCheckReportsProviderErrors(t *testing.T) {
+	m, err := New(utils.ModerationConfig{Provider: "openai"}, &fakeModerationClient{err: errors.New("boom")})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := m.Check(context.Background(), "hello"); err == nil {
+		t.Fatal("expected the provider error")
+	}
+}
+
+func TestNewRejectsBadConfig(t *testing.T) {
+	if _, err := New(utils.ModerationConfig{Categories: map[string]string{"hate": "warn"}}, nil); err == nil {
+		t.Fatal("expected an unknown action to fail")
+	}
+	if _, err := New(utils.ModerationConfig{Rules: map[string][]string{"secrets": {"("}}}, nil); err == nil {
+		t.Fatal("expected an invalid pattern to fail")
+	}
+}
+
+func TestFromConfigOffline(t *testing.T) {
+	cfg := utils.DefaultConfig()
+	if m, err := FromConfig(cfg); err != nil || m != nil {
+		t.Fatalf("expected moderation off by default, got %v, %v", m, err)
+	}
+
+	cfg.Offline = true
+	cfg.Moderation = utils.ModerationConfig{Provider: "openai", Rules: map[string][]string{"secrets": {`sk-\w+`}}}
+	m, err := FromConfig(cfg)
+	if err != nil {
+		t.Fatalf("FromConfig: %v", err)
+	}
+	if m.client != nil || len(m.rules) != 1 {
+		t.Fatal("expected offline moderation to use the rules only")
+	}
+}
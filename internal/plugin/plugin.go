@@ -0,0 +1,165 @@
+// Package plugin finds and runs gomor plugins: executables on PATH named
+// gomor-<name>, in the manner of kubectl plugins, so third parties can add
+// to gomor without forking it.
+//
+//   - gomor-<command> runs as "gomor <command> [args...]", with the terminal's
+//     stdin, stdout, and stderr.
+//   - gomor-provider-<name> serves models for provider "<name>" in the config,
+//     over the protocol described on QueryClient and EmbeddingClient.
+//   - gomor-ingest-<ext> converts a ".<ext>" file, given as its only argument,
+//     to plain text on stdout for "gomor ingest".
+//
+// Every plugin runs with GOMOR_BIN, GOMOR_CONFIG, and GOMOR_DB set, so it can
+// call back into gomor or read its state.
+package plugin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// Prefix starts the name of every plugin executable.
+const Prefix = "gomor-"
+
+// Kinds of plugin, told apart by the rest of their name.
+const (
+	KindCommand  = "command"
+	KindProvider = "provider"
+	KindIngest   = "ingest"
+)
+
+// Plugin is a plugin executable found on PATH.
+type Plugin struct {
+	Name string `json:"name"` // command, provider, or extension name, e.g. "foo" for gomor-provider-foo
+	Kind string `json:"kind"`
+	Path string `json:"path"`
+}
+
+// kindPrefixes maps the name prefixes of the non-command kinds to their kind.
+var kindPrefixes = map[string]string{
+	"provider-": KindProvider,
+	"ingest-":   KindIngest,
+}
+
+// Find returns the path of the plugin of kind named name, such as
+// gomor-provider-foo for Find(KindProvider, "foo").
+func Find(kind, name string) (string, bool) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", false
+	}
+	file := Prefix + name
+	if kind != KindCommand {
+		file = Prefix + kind + "-" + name
+	}
+	path, err := exec.LookPath(file)
+	if err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// List returns the plugins on PATH, sorted by kind and name. When a name is
+// found in more than one PATH directory, the first one wins, as it does when
+// the plugin is run.
+func List() []Plugin {
+	seen := make(map[string]bool)
+	var plugins []Plugin
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			file := entry.Name()
+			if runtime.GOOS == "windows" {
+				file = strings.TrimSuffix(file, filepath.Ext(file))
+			}
+			if !strings.HasPrefix(file, Prefix) || seen[file] {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			seen[file] = true
+			plugins = append(plugins, parse(strings.TrimPrefix(file, Prefix), path))
+		}
+	}
+	sort.Slice(plugins, func(i, j int) bool {
+		if plugins[i].Kind != plugins[j].Kind {
+			return plugins[i].Kind < plugins[j].Kind
+		}
+		return plugins[i].Name < plugins[j].Name
+	})
+	return plugins
+}
+
+// parse classifies the plugin named gomor-<rest>.
+func parse(rest, path string) Plugin {
+	for prefix, kind := range kindPrefixes {
+		if name, ok := strings.CutPrefix(rest, prefix); ok && name != "" {
+			return Plugin{Name: name, Kind: kind, Path: path}
+		}
+	}
+	return Plugin{Name: rest, Kind: KindCommand, Path: path}
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	return info.Mode()&0o111 != 0
+}
+
+// Command returns a command that runs the plugin at path with args, in
+// gomor's environment.
+func Command(ctx context.Context, path string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Env = append(os.Environ(), Env()...)
+	return cmd
+}
+
+// Env returns the variables plugins are run with.
+func Env() []string {
+	var env []string
+	if bin, err := os.Executable(); err == nil {
+		env = append(env, "GOMOR_BIN="+bin)
+	}
+	if config, err := utils.GetConfigPath(); err == nil {
+		env = append(env, "GOMOR_CONFIG="+config)
+	}
+	if db, err := utils.GetDBPath(); err == nil {
+		env = append(env, "GOMOR_DB="+db)
+	}
+	return env
+}
+
+// Run runs the command plugin at path with args on the terminal and returns
+// its exit code.
+func Run(ctx context.Context, path string, args []string) (int, error) {
+	cmd := Command(ctx, path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return 1, err
+	}
+	return 0, nil
+}
@@ -0,0 +1,160 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+// installPlugins writes shell scripts named by scripts' keys into a directory
+// that becomes the whole PATH.
+func installPlugins(t *testing.T, scripts map[string]string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts need a POSIX shell")
+	}
+	dir := t.TempDir()
+	for name, body := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+body), 0755); err != nil {
+			t.Fatalf("write plugin: %v", err)
+		}
+	}
+	t.Setenv("PATH", dir)
+	t.Setenv("HOME", t.TempDir())
+	return dir
+}
+
+const fakeProvider = `case "$1" in
+chat) while read -r _; do :; done; printf 'héllo '; printf 'wörld' ;;
+embed) while read -r _; do :; done; echo '{"embeddings": [[1, 0, 0], [0, 1, 0]]}' ;;
+models) echo fake-1; echo; echo fake-2 ;;
+*) echo "quota exceeded" >&2; exit 1 ;;
+esac
+`
+
+func TestListClassifiesPlugins(t *testing.T) {
+	dir := installPlugins(t, map[string]string{
+		"gomor-hello":         "echo hi",
+		"gomor-provider-fake": fakeProvider,
+		"gomor-ingest-csv":    "cat \"$1\"",
+		"other-tool":          "true",
+	})
+	if err := os.WriteFile(filepath.Join(dir, "gomor-not-executable"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, p := range List() {
+		got = append(got, p.Kind+":"+p.Name)
+	}
+	want := "command:hello,ingest:csv,provider:fake"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("List() = %v, want %s", got, want)
+	}
+
+	if _, ok := Find(KindProvider, "fake"); !ok {
+		t.Fatal("Find(provider, fake) should find gomor-provider-fake")
+	}
+	if _, ok := Find(KindCommand, "provider-fake"); !ok {
+		t.Fatal("Find(command, provider-fake) should find gomor-provider-fake")
+	}
+	if _, ok := Find(KindCommand, "../hello"); ok {
+		t.Fatal("names with path separators should not be found")
+	}
+}
+
+func TestRunReturnsExitCodeAndSetsEnv(t *testing.T) {
+	dir := installPlugins(t, map[string]string{
+		"gomor-check": `test -n "$GOMOR_CONFIG" && test -n "$GOMOR_DB" && test "$1" = arg && exit 7`,
+	})
+
+	code, err := Run(context.Background(), filepath.Join(dir, "gomor-check"), []string{"arg"})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if code != 7 {
+		t.Fatalf("exit code = %d, want 7", code)
+	}
+}
+
+func TestQueryClientStreamsReply(t *testing.T) {
+	dir := installPlugins(t, map[string]string{"gomor-provider-fake": fakeProvider})
+	qc := NewQueryClient("fake", filepath.Join(dir, "gomor-provider-fake"))
+	model := types.Model{Provider: "fake", ModelID: "fake-1"}
+
+	stream, err := qc.ChatStreamWithContext(context.Background(), model, "be brief", "hi")
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	defer stream.Close()
+	var reply strings.Builder
+	for stream.Next() {
+		reply.WriteString(stream.GetChunk())
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	if reply.String() != "héllo wörld" {
+		t.Fatalf("reply = %q", reply.String())
+	}
+
+	models, err := qc.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels: %v", err)
+	}
+	if strings.Join(models, ",") != "fake-1,fake-2" {
+		t.Fatalf("models = %v", models)
+	}
+}
+
+func TestQueryClientReportsPluginFailure(t *testing.T) {
+	dir := installPlugins(t, map[string]string{"gomor-provider-fake": `while read -r _; do :; done; echo "quota exceeded" >&2; exit 1`})
+	qc := NewQueryClient("fake", filepath.Join(dir, "gomor-provider-fake"))
+
+	stream, err := qc.ChatStream(context.Background(), types.Model{Provider: "fake", ModelID: "fake-1"}, "hi")
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	defer stream.Close()
+	for stream.Next() {
+	}
+	err = stream.Err()
+	var providerErr *client.ProviderError
+	if !errors.As(err, &providerErr) || providerErr.Provider != "fake" {
+		t.Fatalf("expected a provider error from fake, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "quota exceeded") {
+		t.Fatalf("error %q should carry the plugin's stderr", err)
+	}
+}
+
+func TestEmbeddingClientLearnsDimensions(t *testing.T) {
+	dir := installPlugins(t, map[string]string{"gomor-provider-fake": fakeProvider})
+	ec := NewEmbeddingClient("fake", filepath.Join(dir, "gomor-provider-fake"))
+	model := types.Model{Provider: "fake", ModelID: "fake-embed"}
+
+	if dim := ec.Dimensions(model); dim != 0 {
+		t.Fatalf("Dimensions before embedding = %d, want 0", dim)
+	}
+	embeddings, err := ec.EmbedBatch(context.Background(), model, []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("EmbedBatch: %v", err)
+	}
+	if len(embeddings) != 2 || embeddings[1][1] != 1 {
+		t.Fatalf("embeddings = %v", embeddings)
+	}
+	if dim := ec.Dimensions(model); dim != 3 {
+		t.Fatalf("Dimensions = %d, want 3", dim)
+	}
+
+	if _, err := ec.EmbedBatch(context.Background(), model, []string{"only one"}); err == nil {
+		t.Fatal("expected an error when the plugin returns the wrong number of embeddings")
+	}
+}
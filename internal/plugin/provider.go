@@ -0,0 +1,271 @@
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+// ChatRequest is written to the stdin of "gomor-provider-<name> chat".
+type ChatRequest struct {
+	Model  types.Model `json:"model"`
+	System string      `json:"system,omitempty"`
+	Prompt string      `json:"prompt"`
+}
+
+// EmbedRequest is written to the stdin of "gomor-provider-<name> embed".
+type EmbedRequest struct {
+	Model types.Model `json:"model"`
+	Texts []string    `json:"texts"`
+}
+
+// EmbedResponse is read from the stdout of "gomor-provider-<name> embed".
+type EmbedResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+// QueryClient serves chat from a provider plugin:
+//
+//   - "chat" reads a ChatRequest as JSON on stdin and streams the reply as
+//     plain text on stdout.
+//   - "models" writes one model ID per line.
+//
+// A non-zero exit fails the call with the plugin's stderr as the message.
+type QueryClient struct {
+	name string
+	path string
+}
+
+// Compile-time check that QueryClient implements client.QueryClient.
+var _ client.QueryClient = (*QueryClient)(nil)
+
+// NewQueryClient creates a query client for the provider plugin name at path.
+func NewQueryClient(name, path string) *QueryClient {
+	return &QueryClient{name: name, path: path}
+}
+
+func (q *QueryClient) ChatStream(ctx context.Context, model types.Model, query string) (client.StreamResponse, error) {
+	return q.ChatStreamWithContext(ctx, model, "", query)
+}
+
+func (q *QueryClient) ChatStreamWithContext(ctx context.Context, model types.Model, systemContext, query string) (client.StreamResponse, error) {
+	input, err := json.Marshal(ChatRequest{Model: model, System: systemContext, Prompt: query})
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := Command(ctx, q.path, "chat")
+	cmd.Stdin = bytes.NewReader(input)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, client.WrapError(q.name, 0, fmt.Errorf("failed to start provider plugin: %w", err))
+	}
+	return &stream{
+		ctx:    ctx,
+		name:   q.name,
+		cmd:    cmd,
+		stdout: bufio.NewReader(stdout),
+		stderr: stderr,
+		buf:    make([]byte, 4096),
+	}, nil
+}
+
+func (q *QueryClient) ListModels(ctx context.Context) ([]string, error) {
+	out, err := run(ctx, q.name, q.path, "models", nil)
+	if err != nil {
+		return nil, err
+	}
+	var models []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			models = append(models, line)
+		}
+	}
+	return models, nil
+}
+
+// stream relays a chat plugin's stdout as it is written.
+type stream struct {
+	ctx    context.Context
+	name   string
+	cmd    *exec.Cmd
+	stdout *bufio.Reader
+	stderr *bytes.Buffer
+
+	buf     []byte
+	partial []byte // the start of a character split across reads
+	chunk   string
+	err     error
+	done    bool
+}
+
+func (s *stream) Next() bool {
+	if s.done {
+		return false
+	}
+	n, err := s.stdout.Read(s.buf)
+	if n > 0 {
+		data := append(s.partial, s.buf[:n]...)
+		cut := completeRunes(data)
+		s.chunk, s.partial = string(data[:cut]), append([]byte(nil), data[cut:]...)
+		if s.chunk != "" {
+			return true
+		}
+		return s.Next()
+	}
+	if err == nil {
+		return s.Next()
+	}
+
+	s.done = true
+	if waitErr := s.cmd.Wait(); waitErr != nil {
+		s.err = exitError(s.ctx, s.name, waitErr, s.stderr.Bytes())
+	} else if !errors.Is(err, io.EOF) {
+		s.err = client.WrapError(s.name, 0, err)
+	}
+	if len(s.partial) > 0 && s.err == nil {
+		s.chunk, s.partial = string(s.partial), nil
+		return true
+	}
+	return false
+}
+
+func (s *stream) GetChunk() string {
+	return s.chunk
+}
+
+func (s *stream) Err() error {
+	return s.err
+}
+
+// Close stops the plugin if the reply was not read to the end.
+func (s *stream) Close() error {
+	if s.done {
+		return nil
+	}
+	s.done = true
+	_ = s.cmd.Process.Kill()
+	_ = s.cmd.Wait()
+	return nil
+}
+
+// completeRunes returns how much of data holds whole UTF-8 characters, so a
+// character split across reads is not emitted in halves.
+func completeRunes(data []byte) int {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				return i
+			}
+			break
+		}
+	}
+	return len(data)
+}
+
+// EmbeddingClient serves embeddings from a provider plugin: "embed" reads an
+// EmbedRequest as JSON on stdin and writes an EmbedResponse as JSON on stdout,
+// with one embedding per text.
+type EmbeddingClient struct {
+	name string
+	path string
+
+	mu         sync.Mutex
+	dimensions map[string]int // by model ID, learned from the embeddings returned
+}
+
+// Compile-time check that EmbeddingClient implements client.EmbeddingClient.
+var _ client.EmbeddingClient = (*EmbeddingClient)(nil)
+
+// NewEmbeddingClient creates an embedding client for the provider plugin name
+// at path.
+func NewEmbeddingClient(name, path string) *EmbeddingClient {
+	return &EmbeddingClient{name: name, path: path, dimensions: make(map[string]int)}
+}
+
+// Embed returns the embedding vector for the given text.
+func (e *EmbeddingClient) Embed(ctx context.Context, model types.Model, text string) ([]float32, error) {
+	embeddings, err := e.EmbedBatch(ctx, model, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+// EmbedBatch returns embedding vectors for multiple texts.
+func (e *EmbeddingClient) EmbedBatch(ctx context.Context, model types.Model, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	input, err := json.Marshal(EmbedRequest{Model: model, Texts: texts})
+	if err != nil {
+		return nil, err
+	}
+	out, err := run(ctx, e.name, e.path, "embed", input)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp EmbedResponse
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return nil, fmt.Errorf("provider plugin %s returned invalid embeddings: %w", e.name, err)
+	}
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("provider plugin %s returned %d embeddings for %d texts", e.name, len(resp.Embeddings), len(texts))
+	}
+
+	e.mu.Lock()
+	e.dimensions[model.ModelID] = len(resp.Embeddings[0])
+	e.mu.Unlock()
+	return resp.Embeddings, nil
+}
+
+// Dimensions returns the embedding dimension of model, or 0 before the first
+// embedding from it.
+func (e *EmbeddingClient) Dimensions(model types.Model) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.dimensions[model.ModelID]
+}
+
+// run runs the provider plugin's subcommand with input on stdin and returns
+// its stdout.
+func run(ctx context.Context, name, path, subcommand string, input []byte) ([]byte, error) {
+	cmd := Command(ctx, path, subcommand)
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, exitError(ctx, name, err, stderr.Bytes())
+	}
+	return out, nil
+}
+
+// exitError classifies a failed plugin run as a provider error, with what the
+// plugin wrote to stderr as its message.
+func exitError(ctx context.Context, name string, err error, stderr []byte) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if msg := strings.TrimSpace(string(stderr)); msg != "" {
+		err = fmt.Errorf("%w: %s", err, msg)
+	}
+	return client.WrapError(name, 0, fmt.Errorf("provider plugin %s: %w", name, err))
+}
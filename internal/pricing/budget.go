@@ -0,0 +1,110 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/tokenizer"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// ErrBudgetExceeded is returned by Check when a request would go over budget
+// and the budget is enforced.
+var ErrBudgetExceeded = errors.New("spending budget exceeded")
+
+// Budget records what each request costs and checks new requests against the
+// daily and monthly limits.
+type Budget struct {
+	store   *store.Store
+	config  utils.BudgetConfig
+	enforce bool
+	warnOut io.Writer
+	warned  bool
+
+	now func() time.Time
+}
+
+// NewBudget creates a budget that records spend in s. When enforce is set,
+// requests over budget are refused; otherwise a warning is written to warnOut
+// once.
+func NewBudget(s *store.Store, config utils.BudgetConfig, enforce bool, warnOut io.Writer) *Budget {
+	return &Budget{
+		store:   s,
+		config:  config,
+		enforce: enforce,
+		warnOut: warnOut,
+		now:     time.Now,
+	}
+}
+
+// Check is called before sending a request to model with an estimated
+// inputTokens. It fails with ErrBudgetExceeded if the request would take
+// spending over a limit and the budget is enforced.
+func (b *Budget) Check(model types.Model, inputTokens int) error {
+	if b.config.DailyUSD <= 0 && b.config.MonthlyUSD <= 0 {
+		return nil
+	}
+
+	price, _ := Lookup(model, b.config.Prices)
+	next := Cost(price, tokenizer.Usage{InputTokens: inputTokens})
+
+	now := b.now()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	periods := []struct {
+		name  string
+		limit float64
+		since time.Time
+	}{
+		{"daily", b.config.DailyUSD, day},
+		{"monthly", b.config.MonthlyUSD, month},
+	}
+	for _, p := range periods {
+		if p.limit <= 0 {
+			continue
+		}
+		spent, err := b.store.SpendSince(p.since)
+		if err != nil {
+			return err
+		}
+		if spent+next <= p.limit {
+			continue
+		}
+
+		msg := fmt.Sprintf("%s budget of $%.2f reached ($%.4f spent)", p.name, p.limit, spent)
+		if b.enforce {
+			return fmt.Errorf("%w: %s", ErrBudgetExceeded, msg)
+		}
+		if !b.warned && b.warnOut != nil {
+			fmt.Fprintf(b.warnOut, "Warning: %s\n", msg)
+			b.warned = true
+		}
+		return nil
+	}
+	return nil
+}
+
+// Cost returns what usage of model costs at the configured prices. ok is
+// false for models with no known price, which are recorded as free.
+func (b *Budget) Cost(model types.Model, usage tokenizer.Usage) (cost float64, ok bool) {
+	price, ok := Lookup(model, b.config.Prices)
+	return Cost(price, usage), ok
+}
+
+// Record saves the cost of a request to model with the given usage.
+func (b *Budget) Record(model types.Model, usage tokenizer.Usage) error {
+	cost, _ := b.Cost(model, usage)
+	return b.store.RecordSpend(store.SpendEntry{
+		Provider:     model.Provider,
+		ModelID:      model.ModelID,
+		InputTokens:  usage.InputTokens,
+		OutputTokens: usage.OutputTokens,
+		CostUSD:      cost,
+		CreatedAt:    b.now(),
+	})
+}
@@ -0,0 +1,81 @@
+// Package pricing estimates what chat requests cost from their token usage
+// and keeps spending within the configured budget.
+package pricing
+
+import (
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/consts"
+	"github.com/austiecodes/gomor/internal/tokenizer"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// Price is a model's price in USD per million tokens.
+type Price = utils.ModelPrice
+
+// table holds list prices keyed by "provider/model ID prefix". The longest
+// matching prefix wins, so dated snapshots ("gpt-4o-2024-08-06") and variants
+// share their base model's price unless listed separately.
+var table = map[string]Price{
+	"openai/gpt-5":                  {Input: 1.25, Output: 10},
+	"openai/gpt-5-mini":             {Input: 0.25, Output: 2},
+	"openai/gpt-5-nano":             {Input: 0.05, Output: 0.40},
+	"openai/gpt-4.1":                {Input: 2, Output: 8},
+	"openai/gpt-4.1-mini":           {Input: 0.40, Output: 1.60},
+	"openai/gpt-4.1-nano":           {Input: 0.10, Output: 0.40},
+	"openai/gpt-4o":                 {Input: 2.50, Output: 10},
+	"openai/gpt-4o-mini":            {Input: 0.15, Output: 0.60},
+	"openai/o3":                     {Input: 2, Output: 8},
+	"openai/o4-mini":                {Input: 1.10, Output: 4.40},
+	"openai/text-embedding-3-small": {Input: 0.02},
+	"openai/text-embedding-3-large": {Input: 0.13},
+
+	"anthropic/claude-opus-4":     {Input: 15, Output: 75},
+	"anthropic/claude-opus-4-5":   {Input: 5, Output: 25},
+	"anthropic/claude-sonnet-4":   {Input: 3, Output: 15},
+	"anthropic/claude-haiku-4-5":  {Input: 1, Output: 5},
+	"anthropic/claude-3-5-haiku":  {Input: 0.80, Output: 4},
+	"anthropic/claude-3-7-sonnet": {Input: 3, Output: 15},
+
+	"google/gemini-2.5-pro":        {Input: 1.25, Output: 10},
+	"google/gemini-2.5-flash":      {Input: 0.30, Output: 2.50},
+	"google/gemini-2.5-flash-lite": {Input: 0.10, Output: 0.40},
+	"google/gemini-2.0-flash":      {Input: 0.10, Output: 0.40},
+
+	"mock/":   {},
+	"ollama/": {}, // runs on your own hardware
+}
+
+// Lookup returns the price of model, preferring entries in overrides to the
+// built-in table. OpenRouter models ("openai/gpt-4o") are priced as the
+// underlying provider's model. ok is false for models with no known price.
+func Lookup(model types.Model, overrides map[string]Price) (price Price, ok bool) {
+	key := model.Provider + "/" + model.ModelID
+	if model.Provider == consts.ProviderOpenRouter && strings.Contains(model.ModelID, "/") {
+		key = model.ModelID
+	}
+
+	if price, ok := longestPrefix(overrides, key); ok {
+		return price, true
+	}
+	return longestPrefix(table, key)
+}
+
+func longestPrefix(prices map[string]Price, key string) (Price, bool) {
+	var best string
+	for prefix := range prices {
+		if strings.HasPrefix(key, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return prices[best], true
+}
+
+// Cost returns the cost in USD of usage at price.
+func Cost(price Price, usage tokenizer.Usage) float64 {
+	return (float64(usage.InputTokens)*price.Input + float64(usage.OutputTokens)*price.Output) / 1e6
+}
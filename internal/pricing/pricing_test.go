@@ -0,0 +1,115 @@
+package pricing
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/store"
+	"github.com/austiecodes/gomor/internal/tokenizer"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+	_ "modernc.org/sqlite"
+)
+
+func newTestStore(t *testing.T) *store.Store {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	memStore, err := store.NewStoreWithDB(db)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = memStore.Close()
+	})
+	return memStore
+}
+
+func TestLookupLongestPrefix(t *testing.T) {
+	cases := []struct {
+		model types.Model
+		want  Price
+	}{
+		{types.Model{Provider: "openai", ModelID: "gpt-4o-mini-2024-07-18"}, Price{Input: 0.15, Output: 0.60}},
+		{types.Model{Provider: "openai", ModelID: "gpt-4o-2024-08-06"}, Price{Input: 2.50, Output: 10}},
+		{types.Model{Provider: "anthropic", ModelID: "claude-sonnet-4-5"}, Price{Input: 3, Output: 15}},
+		{types.Model{Provider: "openrouter", ModelID: "google/gemini-2.5-flash"}, Price{Input: 0.30, Output: 2.50}},
+	}
+	for _, c := range cases {
+		got, ok := Lookup(c.model, nil)
+		if !ok || got != c.want {
+			t.Fatalf("%s/%s: expected %+v, got %+v (ok=%v)", c.model.Provider, c.model.ModelID, c.want, got, ok)
+		}
+	}
+
+	if _, ok := Lookup(types.Model{Provider: "openai", ModelID: "unknown-model"}, nil); ok {
+		t.Fatal("expected no price for an unknown model")
+	}
+}
+
+func TestLookupPrefersOverrides(t *testing.T) {
+	overrides := map[string]Price{"openai/gpt-4o": {Input: 1, Output: 2}}
+	got, ok := Lookup(types.Model{Provider: "openai", ModelID: "gpt-4o-2024-08-06"}, overrides)
+	if !ok || got != (Price{Input: 1, Output: 2}) {
+		t.Fatalf("expected override price, got %+v", got)
+	}
+}
+
+func TestCost(t *testing.T) {
+	got := Cost(Price{Input: 2, Output: 8}, tokenizer.Usage{InputTokens: 500_000, OutputTokens: 250_000})
+	if math.Abs(got-3) > 1e-9 {
+		t.Fatalf("expected $3, got %v", got)
+	}
+}
+
+func TestBudgetWarnsOnceAndEnforces(t *testing.T) {
+	memStore := newTestStore(t)
+	model := types.Model{Provider: "openai", ModelID: "gpt-4o"}
+	config := utils.BudgetConfig{DailyUSD: 1}
+
+	var warnings bytes.Buffer
+	budget := NewBudget(memStore, config, false, &warnings)
+	now := time.Date(2025, 3, 10, 15, 0, 0, 0, time.Local)
+	budget.now = func() time.Time { return now }
+
+	if err := budget.Check(model, 1000); err != nil || warnings.Len() != 0 {
+		t.Fatalf("expected no warning under budget, got %v %q", err, warnings.String())
+	}
+
+	// Spend from yesterday does not count towards today's budget.
+	if err := memStore.RecordSpend(store.SpendEntry{Provider: "openai", ModelID: "gpt-4o", CostUSD: 5, CreatedAt: now.AddDate(0, 0, -1)}); err != nil {
+		t.Fatalf("record spend: %v", err)
+	}
+	if err := budget.Record(model, tokenizer.Usage{InputTokens: 200_000, OutputTokens: 60_000}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := budget.Check(model, 1000); err != nil {
+			t.Fatalf("check: %v", err)
+		}
+	}
+	if strings.Count(warnings.String(), "Warning: daily budget of $1.00 reached ($1.1000 spent)") != 1 {
+		t.Fatalf("expected a single daily warning, got %q", warnings.String())
+	}
+
+	enforced := NewBudget(memStore, config, true, &warnings)
+	enforced.now = budget.now
+	if err := enforced.Check(model, 1000); !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("expected ErrBudgetExceeded, got %v", err)
+	}
+
+	monthly := NewBudget(memStore, utils.BudgetConfig{MonthlyUSD: 6}, true, nil)
+	monthly.now = budget.now
+	if err := monthly.Check(model, 1000); !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("expected the monthly budget to include yesterday's spend, got %v", err)
+	}
+}
@@ -2,11 +2,13 @@ package anthropic
 
 import (
 	"context"
+	"errors"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
 	"github.com/anthropics/anthropic-sdk-go/packages/ssestream"
 	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/trace"
 	"github.com/austiecodes/gomor/internal/types"
 )
 
@@ -43,30 +45,6 @@ func AssistantMessage(content string) Message {
 	return Message(anthropic.NewAssistantMessage(anthropic.NewTextBlock(content)))
 }
 
-// SystemMessage creates a system message.
-// Note: Anthropic uses a top-level "system" parameter in requests, not a message role.
-// This helper is for compatibility, but the QueryClient should handle it separately if possible.
-// However, for strict interface compliance, we might need to handle it.
-// Anthropic's MessageParam DOES NOT support "system" role.
-// So we'll return a user message with a special prefix or handle it at the request level.
-// Ideally, we shouldn't use SystemMessage here for Anthropic in the message list.
-// But for now, let's just panic or return a placeholder if used incorrectly,
-// or better, allow it and let the request builder extract it.
-// Actually, `client.Message` interface just needs GetRole/GetContent.
-// We can define a custom struct for SystemMessage if needed, but for now let's see how it's used.
-// The `query_client.go` usually separates system context.
-// Let's implement it as a UserMessage for now to verify interface, but we might need a different approach.
-// modifying the approach: we won't strictly use Anthropic's types for *storage* if we need a system message.
-// But wait, `QueryClient` in `openai/query_client.go` separates `systemContext`.
-// So we might not need `SystemMessage` here if we use `QueryClient` correctly.
-func SystemMessage(content string) Message {
-	// Anthropic treats system prompts as a separate parameter, not a message in the messages list.
-	// We'll return a placeholder that shouldn't be sent in the messages list.
-	// Or we can just return a UserMessage and rely on the caller to not use it as system.
-	// A better way is to not support SystemMessage in the *message list* for Anthropic.
-	return Message(anthropic.NewUserMessage(anthropic.NewTextBlock("SYSTEM: " + content)))
-}
-
 // ChatRequest wraps Anthropic's MessageNewParams and implements client.ChatRequest.
 type ChatRequest struct {
 	anthropic.MessageNewParams
@@ -83,15 +61,16 @@ func (r *ChatRequest) GetModel() types.Model {
 	return types.Model{Provider: "anthropic", ModelID: string(r.Model)}
 }
 
+// DefaultMaxTokens is the response length limit used when the model does not
+// set one. The Messages API requires a limit on every request.
+const DefaultMaxTokens = 4096
+
 // NewChatRequest creates a new chat request with the given model ID.
 func NewChatRequest(modelID string) *ChatRequest {
 	params := anthropic.MessageNewParams{
-		Model: anthropic.Model(modelID),
+		Model:     anthropic.Model(modelID),
+		MaxTokens: DefaultMaxTokens,
 	}
-	// Try to set MaxTokens directly if Int() isn't working as field
-	// Based on error: cannot use anthropic.Int(1024) (value of struct type param.Opt[int64]) as int64
-	// So fields are raw types.
-	params.MaxTokens = 1024
 	return &ChatRequest{MessageNewParams: params}
 }
 
@@ -104,17 +83,30 @@ func (r *ChatRequest) WithMessages(msgs ...Message) *ChatRequest {
 	return r
 }
 
-// WithSystem sets the system prompt.
+// WithTemperature sets the request temperature.
+func (r *ChatRequest) WithTemperature(t float64) *ChatRequest {
+	r.Temperature = anthropic.Float(t)
+	return r
+}
+
+// WithMaxTokens sets the maximum number of tokens to generate.
+func (r *ChatRequest) WithMaxTokens(n int) *ChatRequest {
+	r.MaxTokens = int64(n)
+	return r
+}
+
+// WithStop sets the sequences that end the response. The Messages API has no
+// seed parameter, so Anthropic requests have no WithSeed.
+func (r *ChatRequest) WithStop(stop ...string) *ChatRequest {
+	r.StopSequences = stop
+	return r
+}
+
+// WithSystem sets the system prompt. Anthropic takes it as a top-level
+// parameter; the Messages API has no system role.
 func (r *ChatRequest) WithSystem(system string) *ChatRequest {
 	if system != "" {
-		// Manually construct TextBlockParam as we can't find helper/constant easily
-		// and System field requires []TextBlockParam.
-		r.System = []anthropic.TextBlockParam{
-			{
-				Text: system,
-				Type: "text", // Implicit cast hoping constant.Text accepts string
-			},
-		}
+		r.System = []anthropic.TextBlockParam{{Text: system}}
 	}
 	return r
 }
@@ -158,7 +150,7 @@ func (s *StreamResponse) GetChunk() string {
 
 // Err returns any error encountered during iteration
 func (s *StreamResponse) Err() error {
-	return s.stream.Err()
+	return wrapError(s.stream.Err())
 }
 
 // Close closes the stream
@@ -177,6 +169,9 @@ func NewClient(apiKey, baseURL string) *Client {
 	if baseURL != "" {
 		opts = append(opts, option.WithBaseURL(baseURL))
 	}
+	if hc := trace.HTTPClient(); hc != nil {
+		opts = append(opts, option.WithHTTPClient(hc))
+	}
 	c := anthropic.NewClient(opts...)
 	return &Client{client: &c}
 }
@@ -185,7 +180,7 @@ func NewClient(apiKey, baseURL string) *Client {
 func (c *Client) Chat(ctx context.Context, request *ChatRequest) (client.ChatResponse, error) {
 	resp, err := c.client.Messages.New(ctx, request.MessageNewParams)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 
 	return &ChatResponse{Message: resp}, nil
@@ -202,7 +197,7 @@ func (c *Client) ChatStream(ctx context.Context, request *ChatRequest) (client.S
 func (c *Client) ListModels(ctx context.Context) ([]string, error) {
 	page, err := c.client.Models.List(ctx, anthropic.ModelListParams{})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 
 	var models []string
@@ -212,3 +207,13 @@ func (c *Client) ListModels(ctx context.Context) ([]string, error) {
 
 	return models, nil
 }
+
+// wrapError classifies an SDK error into the client package's error kinds.
+func wrapError(err error) error {
+	status := 0
+	var apiErr *anthropic.Error
+	if errors.As(err, &apiErr) {
+		status = apiErr.StatusCode
+	}
+	return client.WrapError("anthropic", status, err)
+}
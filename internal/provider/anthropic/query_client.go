@@ -16,13 +16,12 @@ func NewQueryClient(apiKey, baseURL string) *QueryClient {
 }
 
 func (q *QueryClient) ChatStream(ctx context.Context, model types.Model, query string) (client.StreamResponse, error) {
-	req := NewChatRequest(model.ModelID).WithMessages(UserMessage(query))
+	req := newModelRequest(model).WithMessages(UserMessage(query))
 	return q.c.ChatStream(ctx, req)
 }
 
 func (q *QueryClient) ChatStreamWithContext(ctx context.Context, model types.Model, systemContext, query string) (client.StreamResponse, error) {
-	// Anthropic handles system prompts separately
-	req := NewChatRequest(model.ModelID).
+	req := newModelRequest(model).
 		WithSystem(systemContext).
 		WithMessages(UserMessage(query))
 	return q.c.ChatStream(ctx, req)
@@ -31,3 +30,18 @@ func (q *QueryClient) ChatStreamWithContext(ctx context.Context, model types.Mod
 func (q *QueryClient) ListModels(ctx context.Context) ([]string, error) {
 	return q.c.ListModels(ctx)
 }
+
+// newModelRequest creates a request for model, applying its sampling overrides.
+func newModelRequest(model types.Model) *ChatRequest {
+	req := NewChatRequest(model.ModelID)
+	if model.Temperature != nil {
+		req.WithTemperature(*model.Temperature)
+	}
+	if model.MaxTokens > 0 {
+		req.WithMaxTokens(model.MaxTokens)
+	}
+	if len(model.Stop) > 0 {
+		req.WithStop(model.Stop...)
+	}
+	return req
+}
@@ -0,0 +1,86 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+// sseReply is a minimal Messages API stream answering "hi".
+var sseReply = strings.Join([]string{
+	`event: message_start`,
+	`data: {"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","model":"claude-test","content":[],"usage":{"input_tokens":1,"output_tokens":0}}}`,
+	``,
+	`event: content_block_start`,
+	`data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+	``,
+	`event: content_block_delta`,
+	`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"hi"}}`,
+	``,
+	`event: message_stop`,
+	`data: {"type":"message_stop"}`,
+	``,
+	``,
+}, "\n")
+
+func TestChatStreamWithContextSendsTopLevelSystem(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, sseReply)
+	}))
+	defer server.Close()
+
+	q := NewQueryClient("test-key", server.URL)
+	model := types.Model{Provider: "anthropic", ModelID: "claude-test", MaxTokens: 8000, Stop: []string{"END"}}
+	stream, err := q.ChatStreamWithContext(context.Background(), model, "Be brief.", "hello")
+	if err != nil {
+		t.Fatalf("ChatStreamWithContext: %v", err)
+	}
+	defer stream.Close()
+
+	var answer strings.Builder
+	for stream.Next() {
+		answer.WriteString(stream.GetChunk())
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	if answer.String() != "hi" {
+		t.Fatalf("expected answer %q, got %q", "hi", answer.String())
+	}
+
+	system, _ := json.Marshal(body["system"])
+	if string(system) != `[{"text":"Be brief.","type":"text"}]` {
+		t.Fatalf("expected a top-level system block, got %s", system)
+	}
+	if body["max_tokens"] != float64(8000) {
+		t.Fatalf("expected max_tokens 8000, got %v", body["max_tokens"])
+	}
+	stop, _ := json.Marshal(body["stop_sequences"])
+	if string(stop) != `["END"]` {
+		t.Fatalf("expected stop_sequences [END], got %s", stop)
+	}
+	messages, _ := json.Marshal(body["messages"])
+	if strings.Contains(string(messages), "Be brief.") || strings.Contains(string(messages), "SYSTEM") {
+		t.Fatalf("system prompt leaked into messages: %s", messages)
+	}
+}
+
+func TestNewModelRequestDefaultsMaxTokens(t *testing.T) {
+	req := newModelRequest(types.Model{ModelID: "claude-test"})
+	if req.MaxTokens != DefaultMaxTokens {
+		t.Fatalf("expected default max tokens %d, got %d", DefaultMaxTokens, req.MaxTokens)
+	}
+}
@@ -0,0 +1,127 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+// compile time check that QueryClient can call tools
+var _ client.ToolClient = (*QueryClient)(nil)
+
+// ChatWithTools sends a tool-using exchange and returns the model's reply,
+// which either answers or asks for tool calls.
+func (q *QueryClient) ChatWithTools(ctx context.Context, model types.Model, systemContext string, messages []client.ToolMessage, tools []client.Tool) (*client.ToolReply, error) {
+	req := newModelRequest(model).
+		WithSystem(systemContext).
+		WithMessages(toolMessages(messages)...).
+		WithTools(tools...)
+
+	resp, err := q.c.Chat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := &client.ToolReply{}
+	var text strings.Builder
+	for _, block := range resp.(*ChatResponse).Content {
+		switch block.Type {
+		case "text":
+			text.WriteString(block.Text)
+		case "tool_use":
+			reply.ToolCalls = append(reply.ToolCalls, client.ToolCall{
+				ID:        block.ID,
+				Name:      block.Name,
+				Arguments: string(block.Input),
+			})
+		}
+	}
+	reply.Content = text.String()
+	return reply, nil
+}
+
+// WithTools sets the tools the model may call.
+func (r *ChatRequest) WithTools(tools ...client.Tool) *ChatRequest {
+	r.Tools = make([]anthropic.ToolUnionParam, len(tools))
+	for i, t := range tools {
+		schema := anthropic.ToolInputSchemaParam{ExtraFields: map[string]any{}}
+		for key, value := range t.Parameters {
+			switch key {
+			case "type":
+			case "properties":
+				schema.Properties = value
+			case "required":
+				schema.Required = stringList(value)
+			default:
+				schema.ExtraFields[key] = value
+			}
+		}
+		r.Tools[i] = anthropic.ToolUnionParamOfTool(schema, t.Name)
+		if t.Description != "" {
+			r.Tools[i].OfTool.Description = anthropic.String(t.Description)
+		}
+	}
+	return r
+}
+
+// toolMessages converts an exchange to Messages API messages. Tool results
+// are content blocks of a user message, so consecutive results share one.
+func toolMessages(messages []client.ToolMessage) []Message {
+	var msgs []Message
+	var results []anthropic.ContentBlockParamUnion
+	flush := func() {
+		if len(results) > 0 {
+			msgs = append(msgs, Message(anthropic.NewUserMessage(results...)))
+			results = nil
+		}
+	}
+
+	for _, m := range messages {
+		if m.Role == client.ToolRoleTool {
+			results = append(results, anthropic.NewToolResultBlock(m.ToolCallID, m.Content, false))
+			continue
+		}
+		flush()
+
+		if m.Role != client.ToolRoleAssistant {
+			msgs = append(msgs, UserMessage(m.Content))
+			continue
+		}
+		var blocks []anthropic.ContentBlockParamUnion
+		if m.Content != "" {
+			blocks = append(blocks, anthropic.NewTextBlock(m.Content))
+		}
+		for _, call := range m.ToolCalls {
+			input := json.RawMessage(call.Arguments)
+			if !json.Valid(input) {
+				input = json.RawMessage("{}")
+			}
+			blocks = append(blocks, anthropic.NewToolUseBlock(call.ID, input, call.Name))
+		}
+		msgs = append(msgs, Message(anthropic.NewAssistantMessage(blocks...)))
+	}
+	flush()
+	return msgs
+}
+
+// stringList converts a decoded JSON array of strings.
+func stringList(value any) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []any:
+		var out []string
+		for _, s := range v {
+			if str, ok := s.(string); ok {
+				out = append(out, str)
+			}
+		}
+		return out
+	}
+	return nil
+}
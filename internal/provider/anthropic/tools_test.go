@@ -0,0 +1,87 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+func TestChatWithToolsSendsToolsAndResults(t *testing.T) {
+	var body struct {
+		Tools []struct {
+			Name        string         `json:"name"`
+			InputSchema map[string]any `json:"input_schema"`
+		} `json:"tools"`
+		Messages []struct {
+			Role    string           `json:"role"`
+			Content []map[string]any `json:"content"`
+		} `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"msg_2","type":"message","role":"assistant","model":"claude-test","stop_reason":"tool_use",
+			"content":[{"type":"text","text":"Looking."},{"type":"tool_use","id":"toolu_2","name":"fs__read","input":{"path":"b.txt"}}],
+			"usage":{"input_tokens":1,"output_tokens":1}}`)
+	}))
+	defer server.Close()
+
+	tools := []client.Tool{{
+		Name: "fs__read",
+		Parameters: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"path": map[string]any{"type": "string"}},
+			"required":   []any{"path"},
+		},
+	}}
+	messages := []client.ToolMessage{
+		{Role: client.ToolRoleUser, Content: "read a.txt and b.txt"},
+		{Role: client.ToolRoleAssistant, ToolCalls: []client.ToolCall{
+			{ID: "toolu_0", Name: "fs__read", Arguments: `{"path":"a.txt"}`},
+			{ID: "toolu_1", Name: "fs__read", Arguments: `{"path":"missing.txt"}`},
+		}},
+		{Role: client.ToolRoleTool, Content: "alpha", ToolCallID: "toolu_0", ToolName: "fs__read"},
+		{Role: client.ToolRoleTool, Content: "Error: not found", ToolCallID: "toolu_1", ToolName: "fs__read"},
+	}
+
+	q := NewQueryClient("test-key", server.URL)
+	model := types.Model{Provider: "anthropic", ModelID: "claude-test"}
+	reply, err := q.ChatWithTools(context.Background(), model, "", messages, tools)
+	if err != nil {
+		t.Fatalf("ChatWithTools: %v", err)
+	}
+
+	if reply.Content != "Looking." {
+		t.Fatalf("expected text %q, got %q", "Looking.", reply.Content)
+	}
+	if len(reply.ToolCalls) != 1 || reply.ToolCalls[0].ID != "toolu_2" || reply.ToolCalls[0].Arguments != `{"path":"b.txt"}` {
+		t.Fatalf("unexpected tool calls: %+v", reply.ToolCalls)
+	}
+
+	if len(body.Tools) != 1 || body.Tools[0].Name != "fs__read" {
+		t.Fatalf("unexpected tools: %+v", body.Tools)
+	}
+	if required, _ := json.Marshal(body.Tools[0].InputSchema["required"]); string(required) != `["path"]` {
+		t.Fatalf("expected required [path], got %s", required)
+	}
+	if len(body.Messages) != 3 {
+		t.Fatalf("expected user, assistant, and one user message of results, got %+v", body.Messages)
+	}
+	results := body.Messages[2]
+	if results.Role != "user" || len(results.Content) != 2 || results.Content[1]["tool_use_id"] != "toolu_1" {
+		t.Fatalf("expected both results in one user message, got %+v", results)
+	}
+	if body.Messages[1].Content[0]["type"] != "tool_use" {
+		t.Fatalf("expected the assistant turn to replay its tool calls, got %+v", body.Messages[1])
+	}
+}
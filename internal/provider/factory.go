@@ -1,22 +1,53 @@
 package provider
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
+	"github.com/austiecodes/gomor/internal/chaos"
 	"github.com/austiecodes/gomor/internal/client"
 	"github.com/austiecodes/gomor/internal/consts"
+	"github.com/austiecodes/gomor/internal/plugin"
 	anthropicprov "github.com/austiecodes/gomor/internal/provider/anthropic"
 	googleprov "github.com/austiecodes/gomor/internal/provider/google"
+	localprov "github.com/austiecodes/gomor/internal/provider/local"
+	mockprov "github.com/austiecodes/gomor/internal/provider/mock"
+	ollamaprov "github.com/austiecodes/gomor/internal/provider/ollama"
 	openaiprov "github.com/austiecodes/gomor/internal/provider/openai"
+	"github.com/austiecodes/gomor/internal/trace"
+	"github.com/austiecodes/gomor/internal/types"
 	"github.com/austiecodes/gomor/internal/utils"
 )
 
+// ErrOffline is returned instead of a client when offline mode is on.
+var ErrOffline = errors.New(`offline mode: network calls are disabled (drop --offline or set "offline": false in the config)`)
+
+// NewQueryClient creates a query client for the specified provider. In debug
+// mode, the chaos config may wrap it to inject failures.
 func NewQueryClient(cfg *utils.Config, providerName string) (client.QueryClient, error) {
+	qc, err := newQueryClient(cfg, providerName)
+	if err != nil {
+		return nil, err
+	}
+	if injector := chaos.For(cfg, chaos.TargetChat); injector != nil {
+		return injector.QueryClient(providerName, qc), nil
+	}
+	return qc, nil
+}
+
+func newQueryClient(cfg *utils.Config, providerName string) (client.QueryClient, error) {
+	// The mock provider runs in-process, so it keeps working offline, as does
+	// an Ollama server on this machine.
+	if cfg.Offline && providerName != consts.ProviderMock && !localOllama(cfg, providerName) {
+		return nil, ErrOffline
+	}
+
 	switch providerName {
 	case consts.ProviderOpenAI:
 		openaiCfg := cfg.Providers.OpenAI
 		if openaiCfg.APIKey == "" {
-			return nil, fmt.Errorf("OpenAI API key not configured. Please configure provider first")
+			return nil, utils.ConfigErrorf("OpenAI API key not configured. Please configure provider first")
 		}
 		baseURL := openaiCfg.BaseURL
 		if baseURL == "" {
@@ -24,31 +55,97 @@ func NewQueryClient(cfg *utils.Config, providerName string) (client.QueryClient,
 		}
 		return openaiprov.NewQueryClient(openaiCfg.APIKey, baseURL), nil
 	case consts.ProviderGoogle:
-		googleCfg := cfg.Providers.Google
-		if googleCfg.APIKey == "" {
-			return nil, fmt.Errorf("Google API key not configured. Please configure provider first")
+		opts, err := googleOptions(cfg.Providers.Google)
+		if err != nil {
+			return nil, err
+		}
+		c, err := googleprov.NewQueryClient(opts)
+		if err != nil {
+			return nil, err
 		}
-		return googleprov.NewQueryClient(googleCfg.APIKey, googleCfg.BaseURL), nil
+		return c, nil
 	case consts.ProviderAnthropic:
 		anthropicCfg := cfg.Providers.Anthropic
 		if anthropicCfg.APIKey == "" {
-			return nil, fmt.Errorf("Anthropic API key not configured. Please configure provider first")
+			return nil, utils.ConfigErrorf("Anthropic API key not configured. Please configure provider first")
 		}
 		// Anthropic SDK handles base URL internally via options if provided.
 		return anthropicprov.NewQueryClient(anthropicCfg.APIKey, anthropicCfg.BaseURL), nil
+	case consts.ProviderOllama:
+		return ollamaprov.NewQueryClient(cfg.Providers.Ollama.BaseURL, cfg.Providers.Ollama.Model), nil
+	case consts.ProviderLocal:
+		return nil, fmt.Errorf("the local provider only serves embedding models")
+	case consts.ProviderMock:
+		return mockprov.NewQueryClient(), nil
 
 	default:
-		return nil, fmt.Errorf("unsupported provider: %s", providerName)
+		if path, ok := plugin.Find(plugin.KindProvider, providerName); ok {
+			return plugin.NewQueryClient(providerName, path), nil
+		}
+		return nil, fmt.Errorf("unsupported provider: %s (no built-in provider or %s%s-%s plugin on PATH)", providerName, plugin.Prefix, plugin.KindProvider, providerName)
 	}
 }
 
+// NewRoleQueryClient creates a query client for model that falls back to the
+// models configured for role under model.fallbacks. onSwitch is told about
+// each switch and may be nil.
+func NewRoleQueryClient(cfg *utils.Config, role string, model types.Model, onSwitch client.SwitchFunc) (client.QueryClient, error) {
+	primary, err := NewQueryClient(cfg, model.Provider)
+	if err != nil {
+		return nil, err
+	}
+	models, err := cfg.Model.FallbackModels(role)
+	if err != nil || len(models) == 0 {
+		trace.Printf(trace.Info, "%s model: %s", role, trace.Model(model))
+		return primary, err
+	}
+	names := make([]string, len(models))
+	for i, m := range models {
+		names[i] = trace.Model(m)
+	}
+	trace.Printf(trace.Info, "%s model: %s, falling back to %s", role, trace.Model(model), strings.Join(names, ", "))
+
+	clients := map[string]client.QueryClient{model.Provider: primary}
+	fallbacks := make([]client.Fallback, 0, len(models))
+	for _, m := range models {
+		qc, ok := clients[m.Provider]
+		if !ok {
+			qc, err = NewQueryClient(cfg, m.Provider)
+			if err != nil {
+				return nil, fmt.Errorf("%s fallback %s/%s: %w", role, m.Provider, m.ModelID, err)
+			}
+			clients[m.Provider] = qc
+		}
+		fallbacks = append(fallbacks, client.Fallback{Model: m, Client: qc})
+	}
+	return client.NewFallbackClient(primary, fallbacks, onSwitch), nil
+}
+
 // NewEmbeddingClient creates an embedding client for the specified provider.
+// In debug mode, the chaos config may wrap it to inject failures.
 func NewEmbeddingClient(cfg *utils.Config, providerName string) (client.EmbeddingClient, error) {
+	ec, err := newEmbeddingClient(cfg, providerName)
+	if err != nil {
+		return nil, err
+	}
+	if injector := chaos.For(cfg, chaos.TargetEmbedding); injector != nil {
+		return injector.EmbeddingClient(providerName, ec), nil
+	}
+	return ec, nil
+}
+
+func newEmbeddingClient(cfg *utils.Config, providerName string) (client.EmbeddingClient, error) {
+	// The local and mock providers run in-process, so they keep working
+	// offline, as does an Ollama server on this machine.
+	if cfg.Offline && providerName != consts.ProviderLocal && providerName != consts.ProviderMock && !localOllama(cfg, providerName) {
+		return nil, ErrOffline
+	}
+
 	switch providerName {
 	case consts.ProviderOpenAI:
 		openaiCfg := cfg.Providers.OpenAI
 		if openaiCfg.APIKey == "" {
-			return nil, fmt.Errorf("OpenAI API key not configured. Please configure provider first")
+			return nil, utils.ConfigErrorf("OpenAI API key not configured. Please configure provider first")
 		}
 		baseURL := openaiCfg.BaseURL
 		if baseURL == "" {
@@ -56,14 +153,69 @@ func NewEmbeddingClient(cfg *utils.Config, providerName string) (client.Embeddin
 		}
 		return openaiprov.NewEmbeddingClient(openaiCfg.APIKey, baseURL), nil
 	case consts.ProviderGoogle:
-		googleCfg := cfg.Providers.Google
-		if googleCfg.APIKey == "" {
-			return nil, fmt.Errorf("Google API key not configured. Please configure provider first")
+		opts, err := googleOptions(cfg.Providers.Google)
+		if err != nil {
+			return nil, err
 		}
-		return googleprov.NewEmbeddingClient(googleCfg.APIKey, googleCfg.BaseURL), nil
-	// Anthropic doesn't support embeddings officially in the same way or requested yet.
+		c, err := googleprov.NewEmbeddingClient(opts)
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	case consts.ProviderOllama:
+		return ollamaprov.NewEmbeddingClient(cfg.Providers.Ollama.BaseURL), nil
+	case consts.ProviderLocal:
+		return localprov.NewEmbeddingClient(), nil
+	case consts.ProviderMock:
+		return mockprov.NewEmbeddingClient(), nil
+	case consts.ProviderAnthropic:
+		return nil, utils.ConfigErrorf("Anthropic has no embedding API. Run 'gomor set' to pick an openai, google, or local embedding model")
+	default:
+		if path, ok := plugin.Find(plugin.KindProvider, providerName); ok {
+			return plugin.NewEmbeddingClient(providerName, path), nil
+		}
+		return nil, fmt.Errorf("unsupported embedding provider: %s (no built-in provider or %s%s-%s plugin on PATH)", providerName, plugin.Prefix, plugin.KindProvider, providerName)
+	}
+}
+
+// NewModerationClient creates a moderation client for the specified provider.
+func NewModerationClient(cfg *utils.Config, providerName string) (client.ModerationClient, error) {
+	if cfg.Offline {
+		return nil, ErrOffline
+	}
+
+	switch providerName {
+	case consts.ProviderOpenAI:
+		openaiCfg := cfg.Providers.OpenAI
+		if openaiCfg.APIKey == "" {
+			return nil, utils.ConfigErrorf("OpenAI API key not configured. Please configure provider first")
+		}
+		baseURL := openaiCfg.BaseURL
+		if baseURL == "" {
+			baseURL = consts.DefaultBaseURL
+		}
+		return openaiprov.NewModerationClient(openaiCfg.APIKey, baseURL), nil
 
 	default:
-		return nil, fmt.Errorf("unsupported embedding provider: %s", providerName)
+		return nil, fmt.Errorf("unsupported moderation provider: %s", providerName)
 	}
 }
+
+// googleOptions builds Google client options. A Vertex AI project stands in
+// for the API key.
+func googleOptions(googleCfg utils.GoogleProviderConfig) (googleprov.Options, error) {
+	if googleCfg.APIKey == "" && googleCfg.Project == "" {
+		return googleprov.Options{}, utils.ConfigErrorf("Google API key or Vertex AI project not configured. Please configure provider first")
+	}
+	return googleprov.Options{
+		APIKey:   googleCfg.APIKey,
+		BaseURL:  googleCfg.BaseURL,
+		Project:  googleCfg.Project,
+		Location: googleCfg.Location,
+	}, nil
+}
+
+// localOllama reports whether providerName is Ollama served from this machine.
+func localOllama(cfg *utils.Config, providerName string) bool {
+	return providerName == consts.ProviderOllama && ollamaprov.IsLocal(cfg.Providers.Ollama.BaseURL)
+}
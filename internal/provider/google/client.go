@@ -2,11 +2,13 @@ package google
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"iter"
 	"strings"
 
 	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/trace"
 	"github.com/austiecodes/gomor/internal/types"
 	"google.golang.org/genai"
 )
@@ -95,6 +97,23 @@ func (r *ChatRequest) WithTemperature(t float64) *ChatRequest {
 	return r
 }
 
+func (r *ChatRequest) WithMaxTokens(n int) *ChatRequest {
+	r.Config.MaxOutputTokens = int32(n)
+	return r
+}
+
+func (r *ChatRequest) WithStop(stop ...string) *ChatRequest {
+	r.Config.StopSequences = stop
+	return r
+}
+
+// WithSeed sets the sampling seed. Gemini seeds are 32-bit, so larger seeds wrap.
+func (r *ChatRequest) WithSeed(seed int64) *ChatRequest {
+	s32 := int32(seed)
+	r.Config.Seed = &s32
+	return r
+}
+
 // ChatResponse implements client.ChatResponse
 type ChatResponse struct {
 	*genai.GenerateContentResponse
@@ -121,7 +140,7 @@ func (s *StreamResponse) Next() bool {
 		return false
 	}
 	if err != nil {
-		s.err = err
+		s.err = wrapError(err)
 		return false
 	}
 	s.current = resp
@@ -146,23 +165,54 @@ func (s *StreamResponse) Close() error {
 	return nil
 }
 
+// DefaultVertexLocation is the Vertex AI region used when none is configured.
+const DefaultVertexLocation = "us-central1"
+
+// Options configures a Google client. Setting Project targets Vertex AI,
+// authenticated with Application Default Credentials unless APIKey is set;
+// otherwise the Gemini API is used with APIKey.
+type Options struct {
+	APIKey   string
+	BaseURL  string
+	Project  string
+	Location string
+}
+
 type Client struct {
 	client *genai.Client
 }
 
-func NewClient(apiKey, baseURL string) *Client {
-	ctx := context.Background()
+// NewClient creates a Gemini API or Vertex AI client.
+func NewClient(opts Options) (*Client, error) {
 	cfg := &genai.ClientConfig{
-		APIKey: apiKey,
+		Backend:     genai.BackendGeminiAPI,
+		APIKey:      opts.APIKey,
+		HTTPOptions: genai.HTTPOptions{BaseURL: opts.BaseURL},
+	}
+	if opts.Project != "" {
+		cfg.Backend = genai.BackendVertexAI
+		cfg.Project = opts.Project
+		cfg.Location = opts.Location
+		if cfg.Location == "" {
+			cfg.Location = DefaultVertexLocation
+		}
+		// Vertex AI takes an API key or a project, not both; the project
+		// authenticates with Application Default Credentials.
+		cfg.APIKey = ""
 	}
-	c, err := genai.NewClient(ctx, cfg)
+	// Vertex AI builds its own authenticated client.
+	if hc := trace.HTTPClient(); hc != nil && cfg.Backend == genai.BackendGeminiAPI {
+		cfg.HTTPClient = hc
+	}
+
+	c, err := genai.NewClient(context.Background(), cfg)
 	if err != nil {
-		// For consistency with OpenAI's NewClient (which doesn't return error in the struct),
-		// we might need to handle this. But factory expects NewQueryClient to handle errors.
-		// We'll see how factory uses it.
-		return nil
+		if cfg.Backend == genai.BackendVertexAI {
+			return nil, fmt.Errorf("failed to create Vertex AI client (run 'gcloud auth application-default login'?): %w", err)
+		}
+		return nil, fmt.Errorf("failed to create Google client: %w", err)
 	}
-	return &Client{client: c}
+	return &Client{client: c}, nil
 }
 
 func (c *Client) Chat(ctx context.Context, request *ChatRequest) (client.ChatResponse, error) {
@@ -177,7 +227,7 @@ func (c *Client) Chat(ctx context.Context, request *ChatRequest) (client.ChatRes
 
 	resp, err := c.client.Models.GenerateContent(ctx, request.Model, contents, request.Config)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	return &ChatResponse{GenerateContentResponse: resp}, nil
 }
@@ -224,11 +274,22 @@ func (c *Client) ListModels(ctx context.Context) ([]string, error) {
 
 	page, err := c.client.Models.List(ctx, nil)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	var models []string
 	for _, m := range page.Items {
-		models = append(models, strings.TrimPrefix(m.Name, "models/"))
+		// Gemini API names are "models/<id>"; Vertex AI's are "publishers/google/models/<id>".
+		models = append(models, m.Name[strings.LastIndex(m.Name, "/")+1:])
 	}
 	return models, nil
 }
+
+// wrapError classifies an SDK error into the client package's error kinds.
+func wrapError(err error) error {
+	status := 0
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		status = apiErr.Code
+	}
+	return client.WrapError("google", status, err)
+}
@@ -0,0 +1,79 @@
+package google
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+func TestNewClientUsesBaseURL(t *testing.T) {
+	var gotPath, gotKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotKey = r.URL.Path, r.Header.Get("x-goog-api-key")
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"models":[{"name":"models/gemini-test"},{"name":"publishers/google/models/gemini-vertex"}]}`)
+	}))
+	defer server.Close()
+
+	c, err := NewClient(Options{APIKey: "test-key", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	models, err := c.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels: %v", err)
+	}
+	if !strings.HasSuffix(gotPath, "/models") || gotKey != "test-key" {
+		t.Fatalf("expected a models request with the API key at the base URL, got path %q key %q", gotPath, gotKey)
+	}
+	if len(models) != 2 || models[0] != "gemini-test" || models[1] != "gemini-vertex" {
+		t.Fatalf("unexpected models: %v", models)
+	}
+}
+
+func TestNewClientRequiresCredentials(t *testing.T) {
+	t.Setenv("GOOGLE_API_KEY", "")
+	t.Setenv("GEMINI_API_KEY", "")
+
+	if _, err := NewClient(Options{}); err == nil {
+		t.Fatal("expected an error without an API key")
+	}
+}
+
+func TestListModelsClassifiesAuthErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":{"code":401,"message":"API key not valid","status":"UNAUTHENTICATED"}}`)
+	}))
+	defer server.Close()
+
+	c, err := NewClient(Options{APIKey: "bad-key", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	_, err = c.ListModels(context.Background())
+	if !errors.Is(err, client.ErrAuth) {
+		t.Fatalf("expected ErrAuth, got %v", err)
+	}
+}
+
+func TestNewModelRequestAppliesStopAndSeed(t *testing.T) {
+	seed := int64(42)
+	req := newModelRequest(types.Model{ModelID: "gemini-test", Stop: []string{"END"}, Seed: &seed})
+	if len(req.Config.StopSequences) != 1 || req.Config.StopSequences[0] != "END" {
+		t.Fatalf("expected stop sequences [END], got %q", req.Config.StopSequences)
+	}
+	if req.Config.Seed == nil || *req.Config.Seed != 42 {
+		t.Fatalf("expected seed 42, got %v", req.Config.Seed)
+	}
+}
@@ -18,8 +18,12 @@ type EmbeddingClient struct {
 var _ client.EmbeddingClient = (*EmbeddingClient)(nil)
 
 // NewEmbeddingClient creates a new Google embedding client.
-func NewEmbeddingClient(apiKey, baseURL string) *EmbeddingClient {
-	return &EmbeddingClient{c: NewClient(apiKey, baseURL)}
+func NewEmbeddingClient(opts Options) (*EmbeddingClient, error) {
+	c, err := NewClient(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &EmbeddingClient{c: c}, nil
 }
 
 // Embed returns the embedding vector for the given text.
@@ -38,7 +42,7 @@ func (e *EmbeddingClient) Embed(ctx context.Context, model types.Model, text str
 
 	resp, err := e.c.client.Models.EmbedContent(ctx, model.ModelID, contents, nil)
 	if err != nil {
-		return nil, fmt.Errorf("google embedding failed: %w", err)
+		return nil, wrapError(fmt.Errorf("embedding failed: %w", err))
 	}
 
 	if len(resp.Embeddings) == 0 {
@@ -68,7 +72,7 @@ func (e *EmbeddingClient) EmbedBatch(ctx context.Context, model types.Model, tex
 
 	resp, err := e.c.client.Models.EmbedContent(ctx, model.ModelID, contents, nil)
 	if err != nil {
-		return nil, fmt.Errorf("google batch embedding failed: %w", err)
+		return nil, wrapError(fmt.Errorf("batch embedding failed: %w", err))
 	}
 
 	if len(resp.Embeddings) != len(texts) {
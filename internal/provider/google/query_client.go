@@ -2,7 +2,6 @@ package google
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/austiecodes/gomor/internal/client"
 	"github.com/austiecodes/gomor/internal/types"
@@ -12,34 +11,47 @@ type QueryClient struct {
 	c *Client
 }
 
-func NewQueryClient(apiKey, baseURL string) *QueryClient {
-	return &QueryClient{c: NewClient(apiKey, baseURL)}
+func NewQueryClient(opts Options) (*QueryClient, error) {
+	c, err := NewClient(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &QueryClient{c: c}, nil
 }
 
 func (q *QueryClient) ChatStream(ctx context.Context, model types.Model, query string) (client.StreamResponse, error) {
-	if q.c == nil {
-		return nil, fmt.Errorf("google client not initialized")
-	}
-	req := NewChatRequest(model.ModelID).WithMessages(UserMessage(query))
+	req := newModelRequest(model).WithMessages(UserMessage(query))
 	return q.c.ChatStream(ctx, req)
 }
 
 func (q *QueryClient) ChatStreamWithContext(ctx context.Context, model types.Model, systemContext, query string) (client.StreamResponse, error) {
-	if q.c == nil {
-		return nil, fmt.Errorf("google client not initialized")
-	}
 	var msgs []Message
 	if systemContext != "" {
 		msgs = append(msgs, SystemMessage(systemContext))
 	}
 	msgs = append(msgs, UserMessage(query))
-	req := NewChatRequest(model.ModelID).WithMessages(msgs...)
+	req := newModelRequest(model).WithMessages(msgs...)
 	return q.c.ChatStream(ctx, req)
 }
 
 func (q *QueryClient) ListModels(ctx context.Context) ([]string, error) {
-	if q.c == nil {
-		return nil, fmt.Errorf("google client not initialized")
-	}
 	return q.c.ListModels(ctx)
 }
+
+// newModelRequest creates a request for model, applying its sampling overrides.
+func newModelRequest(model types.Model) *ChatRequest {
+	req := NewChatRequest(model.ModelID)
+	if model.Temperature != nil {
+		req.WithTemperature(*model.Temperature)
+	}
+	if model.MaxTokens > 0 {
+		req.WithMaxTokens(model.MaxTokens)
+	}
+	if len(model.Stop) > 0 {
+		req.WithStop(model.Stop...)
+	}
+	if model.Seed != nil {
+		req.WithSeed(*model.Seed)
+	}
+	return req
+}
@@ -0,0 +1,106 @@
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/genai"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+// compile time check that QueryClient can call tools
+var _ client.ToolClient = (*QueryClient)(nil)
+
+// ChatWithTools sends a tool-using exchange and returns the model's reply,
+// which either answers or asks for tool calls.
+func (q *QueryClient) ChatWithTools(ctx context.Context, model types.Model, systemContext string, messages []client.ToolMessage, tools []client.Tool) (*client.ToolReply, error) {
+	var msgs []Message
+	if systemContext != "" {
+		msgs = append(msgs, SystemMessage(systemContext))
+	}
+	msgs = append(msgs, toolMessages(messages)...)
+	req := newModelRequest(model).WithMessages(msgs...).WithTools(tools...)
+
+	resp, err := q.c.Chat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	content := resp.(*ChatResponse).GenerateContentResponse
+	reply := &client.ToolReply{Content: content.Text()}
+	for i, call := range content.FunctionCalls() {
+		args, err := json.Marshal(call.Args)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s arguments: %w", call.Name, err)
+		}
+		id := call.ID
+		if id == "" {
+			// The Gemini API leaves IDs out; results are matched by name and order.
+			id = fmt.Sprintf("call-%d", i)
+		}
+		reply.ToolCalls = append(reply.ToolCalls, client.ToolCall{ID: id, Name: call.Name, Arguments: string(args)})
+	}
+	return reply, nil
+}
+
+// WithTools sets the functions the model may call.
+func (r *ChatRequest) WithTools(tools ...client.Tool) *ChatRequest {
+	if len(tools) == 0 {
+		return r
+	}
+	declarations := make([]*genai.FunctionDeclaration, len(tools))
+	for i, t := range tools {
+		declarations[i] = &genai.FunctionDeclaration{
+			Name:                 t.Name,
+			Description:          t.Description,
+			ParametersJsonSchema: t.Parameters,
+		}
+	}
+	r.Config.Tools = []*genai.Tool{{FunctionDeclarations: declarations}}
+	return r
+}
+
+// toolMessages converts an exchange to Gemini contents. Function responses
+// are parts of a user turn, so consecutive results share one.
+func toolMessages(messages []client.ToolMessage) []Message {
+	var msgs []Message
+	var results []*genai.Part
+	flush := func() {
+		if len(results) > 0 {
+			msgs = append(msgs, Message{Role: "user", Parts: results})
+			results = nil
+		}
+	}
+
+	for _, m := range messages {
+		if m.Role == client.ToolRoleTool {
+			results = append(results, &genai.Part{FunctionResponse: &genai.FunctionResponse{
+				ID:       m.ToolCallID,
+				Name:     m.ToolName,
+				Response: map[string]any{"output": m.Content},
+			}})
+			continue
+		}
+		flush()
+
+		if m.Role != client.ToolRoleAssistant {
+			msgs = append(msgs, UserMessage(m.Content))
+			continue
+		}
+		var parts []*genai.Part
+		if m.Content != "" {
+			parts = append(parts, &genai.Part{Text: m.Content})
+		}
+		for _, call := range m.ToolCalls {
+			var args map[string]any
+			_ = json.Unmarshal([]byte(call.Arguments), &args)
+			parts = append(parts, &genai.Part{FunctionCall: &genai.FunctionCall{ID: call.ID, Name: call.Name, Args: args}})
+		}
+		msgs = append(msgs, Message{Role: "model", Parts: parts})
+	}
+	flush()
+	return msgs
+}
@@ -0,0 +1,171 @@
+// Package local provides an embedding model that runs in-process, so memories
+// can be embedded and searched without an API key or network access.
+package local
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+// Model IDs served by the local provider.
+const (
+	// ModelNgramHash512 hashes words, word pairs, and character trigrams into
+	// 512 signed buckets. It matches shared vocabulary and spelling variants,
+	// not meaning, so it ranks below cloud models on paraphrases.
+	ModelNgramHash512 = "ngram-hash-512"
+)
+
+// Models lists the local embedding models.
+func Models() []string {
+	return []string{ModelNgramHash512}
+}
+
+// EmbeddingClient embeds text with a hashed n-gram model.
+type EmbeddingClient struct{}
+
+// Compile-time check that EmbeddingClient implements client.EmbeddingClient.
+var _ client.EmbeddingClient = (*EmbeddingClient)(nil)
+
+// NewEmbeddingClient creates a local embedding client.
+func NewEmbeddingClient() *EmbeddingClient {
+	return &EmbeddingClient{}
+}
+
+// Embed returns the embedding vector for the given text.
+func (e *EmbeddingClient) Embed(ctx context.Context, model types.Model, text string) ([]float32, error) {
+	dim := e.Dimensions(model)
+	if dim == 0 {
+		return nil, fmt.Errorf("unknown local embedding model: %s", model.ModelID)
+	}
+	return embed(text, dim), nil
+}
+
+// EmbedBatch returns embedding vectors for multiple texts.
+func (e *EmbeddingClient) EmbedBatch(ctx context.Context, model types.Model, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		v, err := e.Embed(ctx, model, text)
+		if err != nil {
+			return nil, err
+		}
+		vectors[i] = v
+	}
+	return vectors, nil
+}
+
+// Dimensions returns the embedding dimension for the given model, or 0 for
+// models the local provider does not serve.
+func (e *EmbeddingClient) Dimensions(model types.Model) int {
+	switch model.ModelID {
+	case ModelNgramHash512:
+		return 512
+	default:
+		return 0
+	}
+}
+
+// Feature weights. Whole words carry most of the signal; trigrams let
+// "deploy" match "deployment" and survive typos.
+const (
+	wordWeight    = 1.0
+	bigramWeight  = 0.5
+	trigramWeight = 0.3
+)
+
+// embed builds an L2-normalized vector from hashed features of text. Runs of
+// CJK characters have no spaces, so each character counts as a word there.
+func embed(text string, dim int) []float32 {
+	vector := make([]float64, dim)
+	add := func(feature string, weight float64) {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(feature))
+		sum := h.Sum64()
+		bucket := int(sum % uint64(dim))
+		// The sign bit keeps colliding features from only ever adding up.
+		if sum>>63 == 1 {
+			weight = -weight
+		}
+		vector[bucket] += weight
+	}
+
+	words := tokenize(text)
+	for i, w := range words {
+		add("w:"+w, wordWeight)
+		if i > 0 {
+			add("b:"+words[i-1]+" "+w, bigramWeight)
+		}
+
+		runes := []rune("^" + w + "$")
+		if len(runes) <= 4 {
+			continue
+		}
+		for j := 0; j+3 <= len(runes); j++ {
+			add("t:"+string(runes[j:j+3]), trigramWeight)
+		}
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += v * v
+	}
+	norm = math.Sqrt(norm)
+
+	out := make([]float32, dim)
+	if norm == 0 {
+		return out
+	}
+	for i, v := range vector {
+		out[i] = float32(v / norm)
+	}
+	return out
+}
+
+// stopwords are common English words that match almost any text.
+var stopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "do": true, "does": true, "for": true, "from": true,
+	"how": true, "i": true, "in": true, "is": true, "it": true, "of": true,
+	"on": true, "or": true, "should": true, "that": true, "the": true,
+	"this": true, "to": true, "was": true, "what": true, "when": true,
+	"where": true, "which": true, "who": true, "why": true, "with": true,
+}
+
+// tokenize lowercases text and splits it into words of letters and digits,
+// with every CJK character as its own word. Stopwords are dropped.
+func tokenize(text string) []string {
+	var words []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			if w := current.String(); !stopwords[w] {
+				words = append(words, w)
+			}
+			current.Reset()
+		}
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case isCJK(r):
+			flush()
+			words = append(words, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			current.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
@@ -0,0 +1,84 @@
+package local
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+var testModel = types.Model{Provider: "local", ModelID: ModelNgramHash512}
+
+func cosine(a, b []float32) float64 {
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot
+}
+
+func TestEmbedIsDeterministicAndNormalized(t *testing.T) {
+	c := NewEmbeddingClient()
+	a, err := c.Embed(context.Background(), testModel, "Deploy the API with Docker")
+	if err != nil {
+		t.Fatalf("embed: %v", err)
+	}
+	b, err := c.Embed(context.Background(), testModel, "Deploy the API with Docker")
+	if err != nil {
+		t.Fatalf("embed: %v", err)
+	}
+	if len(a) != 512 {
+		t.Fatalf("expected 512 dimensions, got %d", len(a))
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatal("expected the same text to embed to the same vector")
+		}
+	}
+	if norm := cosine(a, a); math.Abs(norm-1) > 1e-5 {
+		t.Fatalf("expected unit vector, got squared norm %v", norm)
+	}
+}
+
+func TestEmbedRanksRelatedTextHigher(t *testing.T) {
+	vectors, err := NewEmbeddingClient().EmbedBatch(context.Background(), testModel, []string{
+		"how do I deploy the service",
+		"Deployment of the service runs through GitHub Actions",
+		"My favourite pasta recipe uses fresh basil",
+	})
+	if err != nil {
+		t.Fatalf("embed batch: %v", err)
+	}
+
+	related := cosine(vectors[0], vectors[1])
+	unrelated := cosine(vectors[0], vectors[2])
+	if related <= unrelated+0.2 {
+		t.Fatalf("expected related text to score clearly higher: related=%.3f unrelated=%.3f", related, unrelated)
+	}
+}
+
+func TestEmbedMatchesCJKText(t *testing.T) {
+	vectors, err := NewEmbeddingClient().EmbedBatch(context.Background(), testModel, []string{
+		"数据库迁移",
+		"上周完成了数据库迁移",
+		"今天天气很好",
+	})
+	if err != nil {
+		t.Fatalf("embed batch: %v", err)
+	}
+	if cosine(vectors[0], vectors[1]) <= cosine(vectors[0], vectors[2]) {
+		t.Fatal("expected CJK text sharing characters to score higher")
+	}
+}
+
+func TestEmbedRejectsUnknownModel(t *testing.T) {
+	c := NewEmbeddingClient()
+	model := types.Model{Provider: "local", ModelID: "text-embedding-3-small"}
+	if c.Dimensions(model) != 0 {
+		t.Fatal("expected no dimensions for an unknown model")
+	}
+	if _, err := c.Embed(context.Background(), model, "text"); err == nil {
+		t.Fatal("expected an error for an unknown model")
+	}
+}
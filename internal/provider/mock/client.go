@@ -0,0 +1,158 @@
+// Package mock provides a deterministic provider that runs in-process: canned
+// chat completions and hash-based embeddings, so gomor can be demoed and
+// tried end to end, TUIs included, without credentials or network access.
+package mock
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/provider/local"
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+// Model IDs served by the mock provider.
+const (
+	ModelChat      = "mock-chat"
+	ModelEmbedding = "mock-embedding"
+)
+
+// maxQuoteChars bounds how much of the prompt a reply quotes.
+const maxQuoteChars = 120
+
+// Models lists the mock models.
+func Models() []string {
+	return []string{ModelChat, ModelEmbedding}
+}
+
+// Reply is the canned completion for prompt: the same prompt always gets the
+// same reply, quoting its last line.
+func Reply(prompt string) string {
+	lines := strings.Split(strings.TrimSpace(prompt), "\n")
+	quote := strings.Join(strings.Fields(lines[len(lines)-1]), " ")
+	if runes := []rune(quote); len(runes) > maxQuoteChars {
+		quote = string(runes[:maxQuoteChars-3]) + "..."
+	}
+	if quote == "" {
+		return "This is a canned reply from the mock provider."
+	}
+	return fmt.Sprintf("This is a canned reply from the mock provider to %q. Configure a real provider with `gomor set` for real answers.", quote)
+}
+
+// QueryClient answers every prompt with Reply, streamed a word at a time.
+type QueryClient struct{}
+
+// Compile-time check that QueryClient implements client.QueryClient.
+var _ client.QueryClient = (*QueryClient)(nil)
+
+// NewQueryClient creates a mock query client.
+func NewQueryClient() *QueryClient {
+	return &QueryClient{}
+}
+
+func (q *QueryClient) ChatStream(ctx context.Context, model types.Model, query string) (client.StreamResponse, error) {
+	return q.ChatStreamWithContext(ctx, model, "", query)
+}
+
+func (q *QueryClient) ChatStreamWithContext(ctx context.Context, model types.Model, systemContext, query string) (client.StreamResponse, error) {
+	if model.ModelID != ModelChat {
+		return nil, client.WrapError("mock", 404, fmt.Errorf("the mock provider has no chat model %q (use %s)", model.ModelID, ModelChat))
+	}
+	return &stream{ctx: ctx, chunks: words(Reply(query))}, nil
+}
+
+func (q *QueryClient) ListModels(ctx context.Context) ([]string, error) {
+	return Models(), nil
+}
+
+// stream replays the chunks of a canned reply.
+type stream struct {
+	ctx    context.Context
+	chunks []string
+	idx    int
+	err    error
+}
+
+func (s *stream) Next() bool {
+	if s.err = s.ctx.Err(); s.err != nil || s.idx >= len(s.chunks) {
+		return false
+	}
+	s.idx++
+	return true
+}
+
+func (s *stream) GetChunk() string {
+	if s.idx == 0 {
+		return ""
+	}
+	return s.chunks[s.idx-1]
+}
+
+func (s *stream) Err() error   { return s.err }
+func (s *stream) Close() error { return nil }
+
+// words splits text into chunks that keep their trailing space, so they join
+// back into text.
+func words(text string) []string {
+	var chunks []string
+	for len(text) > 0 {
+		i := strings.IndexByte(text, ' ')
+		if i < 0 {
+			chunks = append(chunks, text)
+			break
+		}
+		chunks = append(chunks, text[:i+1])
+		text = text[i+1:]
+	}
+	return chunks
+}
+
+// EmbeddingClient embeds text with the local provider's hashed n-gram model.
+type EmbeddingClient struct {
+	local *local.EmbeddingClient
+}
+
+// Compile-time check that EmbeddingClient implements client.EmbeddingClient.
+var _ client.EmbeddingClient = (*EmbeddingClient)(nil)
+
+// NewEmbeddingClient creates a mock embedding client.
+func NewEmbeddingClient() *EmbeddingClient {
+	return &EmbeddingClient{local: local.NewEmbeddingClient()}
+}
+
+// Embed returns the embedding vector for the given text.
+func (e *EmbeddingClient) Embed(ctx context.Context, model types.Model, text string) ([]float32, error) {
+	m, err := localModel(model)
+	if err != nil {
+		return nil, err
+	}
+	return e.local.Embed(ctx, m, text)
+}
+
+// EmbedBatch returns embedding vectors for multiple texts.
+func (e *EmbeddingClient) EmbedBatch(ctx context.Context, model types.Model, texts []string) ([][]float32, error) {
+	m, err := localModel(model)
+	if err != nil {
+		return nil, err
+	}
+	return e.local.EmbedBatch(ctx, m, texts)
+}
+
+// Dimensions returns the embedding dimension, or 0 for models the mock
+// provider does not serve.
+func (e *EmbeddingClient) Dimensions(model types.Model) int {
+	m, err := localModel(model)
+	if err != nil {
+		return 0
+	}
+	return e.local.Dimensions(m)
+}
+
+func localModel(model types.Model) (types.Model, error) {
+	if model.ModelID != ModelEmbedding {
+		return types.Model{}, fmt.Errorf("unknown mock embedding model: %s", model.ModelID)
+	}
+	return types.Model{Provider: model.Provider, ModelID: local.ModelNgramHash512}, nil
+}
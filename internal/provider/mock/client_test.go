@@ -0,0 +1,67 @@
+package mock
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/memory/memutils"
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+func TestChatStreamRepliesDeterministically(t *testing.T) {
+	qc := NewQueryClient()
+	model := types.Model{Provider: "mock", ModelID: ModelChat}
+
+	read := func(prompt string) string {
+		stream, err := qc.ChatStreamWithContext(context.Background(), model, "system", prompt)
+		if err != nil {
+			t.Fatalf("ChatStream: %v", err)
+		}
+		defer stream.Close()
+		var sb strings.Builder
+		chunks := 0
+		for stream.Next() {
+			sb.WriteString(stream.GetChunk())
+			chunks++
+		}
+		if err := stream.Err(); err != nil {
+			t.Fatalf("stream: %v", err)
+		}
+		if chunks < 2 {
+			t.Fatalf("expected the reply streamed in chunks, got %d", chunks)
+		}
+		return sb.String()
+	}
+
+	first := read("Memories:\n- likes Go\n\nPrompt: what should I  learn next?")
+	if first != read("Memories:\n- likes Go\n\nPrompt: what should I  learn next?") {
+		t.Fatal("expected the same prompt to get the same reply")
+	}
+	if first != Reply("Prompt: what should I learn next?") || !strings.Contains(first, `"Prompt: what should I learn next?"`) {
+		t.Fatalf("expected the reply to quote the prompt's last line, got %q", first)
+	}
+
+	if _, err := qc.ChatStream(context.Background(), types.Model{Provider: "mock", ModelID: "gpt-4o"}, "hi"); err == nil {
+		t.Fatal("expected an error for a model the mock does not serve")
+	}
+}
+
+func TestEmbeddingsAreHashBased(t *testing.T) {
+	ec := NewEmbeddingClient()
+	model := types.Model{Provider: "mock", ModelID: ModelEmbedding}
+	if ec.Dimensions(model) != 512 || ec.Dimensions(types.Model{ModelID: ModelChat}) != 0 {
+		t.Fatalf("unexpected dimensions %d", ec.Dimensions(model))
+	}
+
+	vectors, err := ec.EmbedBatch(context.Background(), model, []string{"deploy with Argo CD", "Argo CD deploys", "sourdough starter"})
+	if err != nil {
+		t.Fatalf("EmbedBatch: %v", err)
+	}
+	if memutils.CosineSimilarity(vectors[0], vectors[1]) <= memutils.CosineSimilarity(vectors[0], vectors[2]) {
+		t.Fatal("expected texts sharing words to be closer")
+	}
+	if _, err := ec.Embed(context.Background(), types.Model{ModelID: "text-embedding-3-small"}, "x"); err == nil {
+		t.Fatal("expected an error for a model the mock does not serve")
+	}
+}
@@ -0,0 +1,150 @@
+// Package ollama talks to a local Ollama server (https://ollama.com) over its
+// HTTP API, for chat and embedding models that run on this machine.
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/consts"
+	"github.com/austiecodes/gomor/internal/trace"
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+// DefaultBaseURL is where `ollama serve` listens unless told otherwise.
+const DefaultBaseURL = "http://localhost:11434"
+
+// Client calls the Ollama HTTP API.
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+// NewClient creates a client for the Ollama server at baseURL, or at
+// DefaultBaseURL when it is empty.
+func NewClient(baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	hc := trace.HTTPClient()
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	return &Client{baseURL: strings.TrimRight(baseURL, "/"), http: hc}
+}
+
+// IsLocal reports whether baseURL, or DefaultBaseURL when it is empty, points
+// at this machine, so calls to it keep working offline.
+func IsLocal(baseURL string) bool {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// options are the sampling settings of a request.
+type options struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	NumPredict  int      `json:"num_predict,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+	Seed        *int64   `json:"seed,omitempty"`
+}
+
+// modelOptions returns model's sampling overrides, or nil when it has none.
+func modelOptions(model types.Model) *options {
+	opts := options{Temperature: model.Temperature, NumPredict: model.MaxTokens, Stop: model.Stop, Seed: model.Seed}
+	if opts.Temperature == nil && opts.NumPredict == 0 && len(opts.Stop) == 0 && opts.Seed == nil {
+		return nil
+	}
+	return &opts
+}
+
+// do sends body, when set, as JSON to path and returns the response, or a
+// classified error when the server cannot be reached or answers with one.
+func (c *Client) do(ctx context.Context, method, path string, body any) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, client.WrapError(consts.ProviderOllama, 0, fmt.Errorf("cannot reach Ollama at %s (is 'ollama serve' running?): %w", c.baseURL, err))
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		return nil, client.WrapError(consts.ProviderOllama, resp.StatusCode, errors.New(errorMessage(resp)))
+	}
+	return resp, nil
+}
+
+// errorMessage returns the message of a failed response: the "error" field
+// Ollama sends, or the status.
+func errorMessage(resp *http.Response) string {
+	var body struct {
+		Error string `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if json.Unmarshal(data, &body) == nil && body.Error != "" {
+		return body.Error
+	}
+	if msg := strings.TrimSpace(string(data)); msg != "" {
+		return resp.Status + ": " + msg
+	}
+	return resp.Status
+}
+
+// ListModels lists the models pulled to the server, e.g. "llama3.2:latest".
+func (c *Client) ListModels(ctx context.Context) ([]string, error) {
+	resp, err := c.do(ctx, http.MethodGet, "/api/tags", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, client.WrapError(consts.ProviderOllama, 0, fmt.Errorf("invalid model list: %w", err))
+	}
+	models := make([]string, 0, len(body.Models))
+	for _, m := range body.Models {
+		models = append(models, m.Name)
+	}
+	return models, nil
+}
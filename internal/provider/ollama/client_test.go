@@ -0,0 +1,160 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+// newServer fakes the parts of the Ollama API gomor calls. It serves
+// "llama3.2:latest" and "nomic-embed-text:latest", and records the last chat
+// request in got.
+func newServer(t *testing.T, got *chatRequest) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			fmt.Fprint(w, `{"models":[{"name":"llama3.2:latest"},{"name":"nomic-embed-text:latest"}]}`)
+		case "/api/chat":
+			if err := json.NewDecoder(r.Body).Decode(got); err != nil {
+				t.Errorf("decode chat request: %v", err)
+			}
+			switch got.Model {
+			case "llama3.2":
+				for _, word := range []string{"Hello", ", ", "world"} {
+					fmt.Fprintf(w, `{"message":{"role":"assistant","content":%q},"done":false}`+"\n", word)
+				}
+				fmt.Fprint(w, `{"message":{"role":"assistant","content":""},"done":true}`+"\n")
+			case "broken":
+				fmt.Fprint(w, `{"message":{"role":"assistant","content":"Hel"},"done":false}`+"\n")
+				fmt.Fprint(w, `{"error":"model runner crashed"}`+"\n")
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprintf(w, `{"error":"model '%s' not found"}`, got.Model)
+			}
+		case "/api/embed":
+			var req struct {
+				Model string   `json:"model"`
+				Input []string `json:"input"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("decode embed request: %v", err)
+			}
+			embeddings := make([][]float32, len(req.Input))
+			for i, text := range req.Input {
+				embeddings[i] = []float32{float32(len(text)), 1, 0}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"embeddings": embeddings})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func readAll(t *testing.T, stream client.StreamResponse) (string, error) {
+	t.Helper()
+	defer stream.Close()
+	var sb strings.Builder
+	for stream.Next() {
+		sb.WriteString(stream.GetChunk())
+	}
+	return sb.String(), stream.Err()
+}
+
+func TestChatStream(t *testing.T) {
+	var got chatRequest
+	server := newServer(t, &got)
+	q := NewQueryClient(server.URL, "llama3.2")
+
+	temperature := 0.2
+	stream, err := q.ChatStreamWithContext(context.Background(), types.Model{Temperature: &temperature, MaxTokens: 64}, "be brief", "hi")
+	if err != nil {
+		t.Fatalf("chat: %v", err)
+	}
+	answer, err := readAll(t, stream)
+	if err != nil || answer != "Hello, world" {
+		t.Fatalf("expected the streamed answer, got %q (%v)", answer, err)
+	}
+	if got.Model != "llama3.2" || !got.Stream || len(got.Messages) != 2 || got.Messages[0].Role != "system" || got.Messages[1].Content != "hi" {
+		t.Fatalf("unexpected request: %+v", got)
+	}
+	if got.Options == nil || *got.Options.Temperature != 0.2 || got.Options.NumPredict != 64 {
+		t.Fatalf("expected the model's sampling settings, got %+v", got.Options)
+	}
+
+	models, err := q.ListModels(context.Background())
+	if err != nil || len(models) != 2 || models[0] != "llama3.2:latest" {
+		t.Fatalf("unexpected models %v (%v)", models, err)
+	}
+}
+
+func TestChatStreamErrors(t *testing.T) {
+	var got chatRequest
+	server := newServer(t, &got)
+	q := NewQueryClient(server.URL, "")
+
+	if _, err := q.ChatStream(context.Background(), types.Model{ModelID: "missing"}, "hi"); !errors.Is(err, client.ErrModelNotFound) || !strings.Contains(err.Error(), "model 'missing' not found") {
+		t.Fatalf("expected a model-not-found error, got %v", err)
+	}
+	if _, err := q.ChatStream(context.Background(), types.Model{}, "hi"); err == nil {
+		t.Fatal("expected an error without a model or a default model")
+	}
+
+	stream, err := q.ChatStream(context.Background(), types.Model{ModelID: "broken"}, "hi")
+	if err != nil {
+		t.Fatalf("chat: %v", err)
+	}
+	if answer, err := readAll(t, stream); answer != "Hel" || err == nil || !strings.Contains(err.Error(), "model runner crashed") {
+		t.Fatalf("expected the partial answer and the stream's error, got %q (%v)", answer, err)
+	}
+
+	server.Close()
+	if _, err := q.ListModels(context.Background()); !errors.Is(err, client.ErrNetwork) || !strings.Contains(err.Error(), "ollama serve") {
+		t.Fatalf("expected a network error naming ollama serve, got %v", err)
+	}
+}
+
+func TestEmbedBatch(t *testing.T) {
+	server := newServer(t, &chatRequest{})
+	e := NewEmbeddingClient(server.URL)
+	model := types.Model{ModelID: "nomic-embed-text"}
+
+	if dim := e.Dimensions(model); dim != 0 {
+		t.Fatalf("expected no dimension before the first embedding, got %d", dim)
+	}
+	embeddings, err := e.EmbedBatch(context.Background(), model, []string{"a", "abc"})
+	if err != nil {
+		t.Fatalf("embed: %v", err)
+	}
+	if len(embeddings) != 2 || embeddings[1][0] != 3 {
+		t.Fatalf("unexpected embeddings: %v", embeddings)
+	}
+	if dim := e.Dimensions(model); dim != 3 {
+		t.Fatalf("expected 3 dimensions, got %d", dim)
+	}
+}
+
+func TestIsLocal(t *testing.T) {
+	for baseURL, want := range map[string]bool{
+		"":                           true,
+		"http://localhost:11434":     true,
+		"http://127.0.0.1:8080/":     true,
+		"http://[::1]:11434":         true,
+		"http://gpu-box.lan:11434":   false,
+		"https://ollama.example.com": false,
+	} {
+		if got := IsLocal(baseURL); got != want {
+			t.Errorf("IsLocal(%q) = %v, want %v", baseURL, got, want)
+		}
+	}
+}
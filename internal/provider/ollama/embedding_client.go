@@ -0,0 +1,74 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/consts"
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+// EmbeddingClient embeds text with Ollama's /api/embed.
+type EmbeddingClient struct {
+	c *Client
+
+	mu         sync.Mutex
+	dimensions map[string]int // by model ID, learned from the embeddings returned
+}
+
+// Compile-time check that EmbeddingClient implements client.EmbeddingClient.
+var _ client.EmbeddingClient = (*EmbeddingClient)(nil)
+
+// NewEmbeddingClient creates an embedding client for the Ollama server at
+// baseURL.
+func NewEmbeddingClient(baseURL string) *EmbeddingClient {
+	return &EmbeddingClient{c: NewClient(baseURL), dimensions: make(map[string]int)}
+}
+
+// Embed returns the embedding vector for the given text.
+func (e *EmbeddingClient) Embed(ctx context.Context, model types.Model, text string) ([]float32, error) {
+	embeddings, err := e.EmbedBatch(ctx, model, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+// EmbedBatch returns embedding vectors for multiple texts.
+func (e *EmbeddingClient) EmbedBatch(ctx context.Context, model types.Model, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	resp, err := e.c.do(ctx, http.MethodPost, "/api/embed", map[string]any{"model": model.ModelID, "input": texts})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, client.WrapError(consts.ProviderOllama, 0, fmt.Errorf("invalid embeddings: %w", err))
+	}
+	if len(body.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("ollama returned %d embeddings, expected %d", len(body.Embeddings), len(texts))
+	}
+
+	e.mu.Lock()
+	e.dimensions[model.ModelID] = len(body.Embeddings[0])
+	e.mu.Unlock()
+	return body.Embeddings, nil
+}
+
+// Dimensions returns the embedding dimension of model, or 0 before the first
+// embedding from it.
+func (e *EmbeddingClient) Dimensions(model types.Model) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.dimensions[model.ModelID]
+}
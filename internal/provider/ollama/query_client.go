@@ -0,0 +1,135 @@
+package ollama
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/consts"
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+// QueryClient streams chat replies from Ollama's /api/chat.
+type QueryClient struct {
+	c            *Client
+	defaultModel string
+}
+
+// Compile-time check that QueryClient implements client.QueryClient.
+var _ client.QueryClient = (*QueryClient)(nil)
+
+// NewQueryClient creates a query client for the Ollama server at baseURL.
+// defaultModel answers requests for a model with no ID, and may be empty.
+func NewQueryClient(baseURL, defaultModel string) *QueryClient {
+	return &QueryClient{c: NewClient(baseURL), defaultModel: defaultModel}
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+	Stream   bool          `json:"stream"`
+	Options  *options      `json:"options,omitempty"`
+}
+
+// chatChunk is one line of a streamed /api/chat reply.
+type chatChunk struct {
+	Message chatMessage `json:"message"`
+	Done    bool        `json:"done"`
+	Error   string      `json:"error"`
+}
+
+func (q *QueryClient) ChatStream(ctx context.Context, model types.Model, query string) (client.StreamResponse, error) {
+	return q.ChatStreamWithContext(ctx, model, "", query)
+}
+
+func (q *QueryClient) ChatStreamWithContext(ctx context.Context, model types.Model, systemContext, query string) (client.StreamResponse, error) {
+	modelID := model.ModelID
+	if modelID == "" {
+		modelID = q.defaultModel
+	}
+	if modelID == "" {
+		return nil, fmt.Errorf("no Ollama model given and no default model configured")
+	}
+
+	var messages []chatMessage
+	if systemContext != "" {
+		messages = append(messages, chatMessage{Role: "system", Content: systemContext})
+	}
+	messages = append(messages, chatMessage{Role: "user", Content: query})
+	resp, err := q.c.do(ctx, http.MethodPost, "/api/chat", chatRequest{
+		Model:    modelID,
+		Messages: messages,
+		Stream:   true,
+		Options:  modelOptions(model),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &stream{ctx: ctx, body: resp.Body, dec: json.NewDecoder(bufio.NewReader(resp.Body))}, nil
+}
+
+func (q *QueryClient) ListModels(ctx context.Context) ([]string, error) {
+	return q.c.ListModels(ctx)
+}
+
+// stream reads a reply streamed as one JSON object per line.
+type stream struct {
+	ctx   context.Context
+	body  io.ReadCloser
+	dec   *json.Decoder
+	chunk string
+	err   error
+	done  bool
+}
+
+func (s *stream) Next() bool {
+	for !s.done {
+		var chunk chatChunk
+		if err := s.dec.Decode(&chunk); err != nil {
+			s.done = true
+			switch {
+			case s.ctx.Err() != nil:
+				s.err = s.ctx.Err()
+			case errors.Is(err, io.EOF):
+				s.err = client.WrapError(consts.ProviderOllama, 0, io.ErrUnexpectedEOF)
+			default:
+				s.err = client.WrapError(consts.ProviderOllama, 0, err)
+			}
+			return false
+		}
+		if chunk.Error != "" {
+			s.done = true
+			s.err = client.WrapError(consts.ProviderOllama, 0, errors.New(chunk.Error))
+			return false
+		}
+		s.done = chunk.Done
+		if chunk.Message.Content != "" {
+			s.chunk = chunk.Message.Content
+			return true
+		}
+	}
+	return false
+}
+
+func (s *stream) GetChunk() string {
+	return s.chunk
+}
+
+func (s *stream) Err() error {
+	return s.err
+}
+
+func (s *stream) Close() error {
+	s.done = true
+	return s.body.Close()
+}
@@ -2,12 +2,14 @@ package openai
 
 import (
 	"context"
+	"errors"
 
 	"github.com/openai/openai-go/v3"
 	"github.com/openai/openai-go/v3/option"
 	"github.com/openai/openai-go/v3/packages/ssestream"
 
 	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/trace"
 	"github.com/austiecodes/gomor/internal/types"
 )
 
@@ -97,6 +99,30 @@ func (r *ChatRequest) WithTemperature(t float64) *ChatRequest {
 	return r
 }
 
+// WithMaxTokens sets the maximum number of tokens to generate.
+func (r *ChatRequest) WithMaxTokens(n int) *ChatRequest {
+	params := openai.ChatCompletionNewParams(*r)
+	params.MaxCompletionTokens = openai.Int(int64(n))
+	*r = ChatRequest(params)
+	return r
+}
+
+// WithStop sets the sequences that end the response.
+func (r *ChatRequest) WithStop(stop ...string) *ChatRequest {
+	params := openai.ChatCompletionNewParams(*r)
+	params.Stop = openai.ChatCompletionNewParamsStopUnion{OfStringArray: stop}
+	*r = ChatRequest(params)
+	return r
+}
+
+// WithSeed sets the sampling seed for repeatable responses.
+func (r *ChatRequest) WithSeed(seed int64) *ChatRequest {
+	params := openai.ChatCompletionNewParams(*r)
+	params.Seed = openai.Int(seed)
+	*r = ChatRequest(params)
+	return r
+}
+
 // ChatResponse embeds OpenAI response and implements client.ChatResponse
 type ChatResponse struct {
 	*openai.ChatCompletion
@@ -134,7 +160,7 @@ func (s *StreamResponse) GetChunk() string {
 
 // Err returns any error encountered during iteration
 func (s *StreamResponse) Err() error {
-	return s.stream.Err()
+	return wrapError(s.stream.Err())
 }
 
 // Close closes the stream
@@ -153,6 +179,9 @@ func NewClient(apiKey, baseURL string) *Client {
 	if baseURL != "" {
 		opts = append(opts, option.WithBaseURL(baseURL))
 	}
+	if hc := trace.HTTPClient(); hc != nil {
+		opts = append(opts, option.WithHTTPClient(hc))
+	}
 	return &Client{client: openai.NewClient(opts...)}
 }
 
@@ -161,7 +190,7 @@ func (c *Client) Chat(ctx context.Context, request *ChatRequest) (client.ChatRes
 	params := openai.ChatCompletionNewParams(*request)
 	resp, err := c.client.Chat.Completions.New(ctx, params)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 
 	return &ChatResponse{ChatCompletion: resp}, nil
@@ -179,7 +208,7 @@ func (c *Client) ChatStream(ctx context.Context, request *ChatRequest) (client.S
 func (c *Client) ListModels(ctx context.Context) ([]string, error) {
 	page, err := c.client.Models.List(ctx)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 
 	var models []string
@@ -203,3 +232,13 @@ func sortModels(models []string) {
 		}
 	}
 }
+
+// wrapError classifies an SDK error into the client package's error kinds.
+func wrapError(err error) error {
+	status := 0
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		status = apiErr.StatusCode
+	}
+	return client.WrapError("openai", status, err)
+}
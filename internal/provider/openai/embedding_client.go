@@ -33,7 +33,7 @@ func (e *EmbeddingClient) Embed(ctx context.Context, model types.Model, text str
 		},
 	})
 	if err != nil {
-		return nil, fmt.Errorf("openai embedding failed: %w", err)
+		return nil, wrapError(fmt.Errorf("embedding failed: %w", err))
 	}
 
 	if len(resp.Data) == 0 {
@@ -62,7 +62,7 @@ func (e *EmbeddingClient) EmbedBatch(ctx context.Context, model types.Model, tex
 		},
 	})
 	if err != nil {
-		return nil, fmt.Errorf("openai batch embedding failed: %w", err)
+		return nil, wrapError(fmt.Errorf("batch embedding failed: %w", err))
 	}
 
 	if len(resp.Data) != len(texts) {
@@ -0,0 +1,56 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/openai/openai-go/v3"
+
+	"github.com/austiecodes/gomor/internal/client"
+)
+
+// ModerationClient wraps the OpenAI moderation endpoint.
+type ModerationClient struct {
+	c *Client
+}
+
+// Compile-time check that ModerationClient implements client.ModerationClient.
+var _ client.ModerationClient = (*ModerationClient)(nil)
+
+// NewModerationClient creates a new OpenAI moderation client.
+func NewModerationClient(apiKey, baseURL string) *ModerationClient {
+	return &ModerationClient{c: NewClient(apiKey, baseURL)}
+}
+
+// Moderate returns the OpenAI categories text is flagged for, such as
+// "harassment" or "self-harm/intent".
+func (m *ModerationClient) Moderate(ctx context.Context, text string) ([]string, error) {
+	resp, err := m.c.client.Moderations.New(ctx, openai.ModerationNewParams{
+		Input: openai.ModerationNewParamsInputUnion{OfString: openai.String(text)},
+		Model: openai.ModerationModelOmniModerationLatest,
+	})
+	if err != nil {
+		return nil, wrapError(fmt.Errorf("moderation failed: %w", err))
+	}
+
+	var flagged []string
+	for _, result := range resp.Results {
+		if !result.Flagged {
+			continue
+		}
+		// Categories are decoded by name so new ones need no code change.
+		var categories map[string]bool
+		if err := json.Unmarshal([]byte(result.Categories.RawJSON()), &categories); err != nil {
+			return nil, fmt.Errorf("failed to parse moderation categories: %w", err)
+		}
+		for name, hit := range categories {
+			if hit {
+				flagged = append(flagged, name)
+			}
+		}
+	}
+	sort.Strings(flagged)
+	return flagged, nil
+}
@@ -16,7 +16,7 @@ func NewQueryClient(apiKey, baseURL string) *QueryClient {
 }
 
 func (q *QueryClient) ChatStream(ctx context.Context, model types.Model, query string) (client.StreamResponse, error) {
-	req := NewChatRequest(model.ModelID).WithMessages(UserMessage(query))
+	req := newModelRequest(model).WithMessages(UserMessage(query))
 	return q.c.ChatStream(ctx, req)
 }
 
@@ -26,10 +26,28 @@ func (q *QueryClient) ChatStreamWithContext(ctx context.Context, model types.Mod
 		msgs = append(msgs, SystemMessage(systemContext))
 	}
 	msgs = append(msgs, UserMessage(query))
-	req := NewChatRequest(model.ModelID).WithMessages(msgs...)
+	req := newModelRequest(model).WithMessages(msgs...)
 	return q.c.ChatStream(ctx, req)
 }
 
 func (q *QueryClient) ListModels(ctx context.Context) ([]string, error) {
 	return q.c.ListModels(ctx)
 }
+
+// newModelRequest creates a request for model, applying its sampling overrides.
+func newModelRequest(model types.Model) *ChatRequest {
+	req := NewChatRequest(model.ModelID)
+	if model.Temperature != nil {
+		req.WithTemperature(*model.Temperature)
+	}
+	if model.MaxTokens > 0 {
+		req.WithMaxTokens(model.MaxTokens)
+	}
+	if len(model.Stop) > 0 {
+		req.WithStop(model.Stop...)
+	}
+	if model.Seed != nil {
+		req.WithSeed(*model.Seed)
+	}
+	return req
+}
@@ -0,0 +1,96 @@
+package openai
+
+import (
+	"context"
+
+	"github.com/openai/openai-go/v3"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+// compile time check that QueryClient can call tools
+var _ client.ToolClient = (*QueryClient)(nil)
+
+// ChatWithTools sends a tool-using exchange and returns the model's reply,
+// which either answers or asks for tool calls.
+func (q *QueryClient) ChatWithTools(ctx context.Context, model types.Model, systemContext string, messages []client.ToolMessage, tools []client.Tool) (*client.ToolReply, error) {
+	var msgs []Message
+	if systemContext != "" {
+		msgs = append(msgs, SystemMessage(systemContext))
+	}
+	for _, m := range messages {
+		msgs = append(msgs, toolMessage(m))
+	}
+	req := newModelRequest(model).WithMessages(msgs...).WithTools(tools...)
+
+	resp, err := q.c.Chat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	completion := resp.(*ChatResponse)
+	if len(completion.Choices) == 0 {
+		return &client.ToolReply{}, nil
+	}
+
+	message := completion.Choices[0].Message
+	reply := &client.ToolReply{Content: message.Content}
+	for _, call := range message.ToolCalls {
+		if call.Type != "function" {
+			continue
+		}
+		reply.ToolCalls = append(reply.ToolCalls, client.ToolCall{
+			ID:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: call.Function.Arguments,
+		})
+	}
+	return reply, nil
+}
+
+// WithTools sets the functions the model may call.
+func (r *ChatRequest) WithTools(tools ...client.Tool) *ChatRequest {
+	params := openai.ChatCompletionNewParams(*r)
+	params.Tools = make([]openai.ChatCompletionToolUnionParam, len(tools))
+	for i, t := range tools {
+		def := openai.FunctionDefinitionParam{
+			Name:       t.Name,
+			Parameters: openai.FunctionParameters(t.Parameters),
+		}
+		if t.Description != "" {
+			def.Description = openai.String(t.Description)
+		}
+		params.Tools[i] = openai.ChatCompletionFunctionTool(def)
+	}
+	*r = ChatRequest(params)
+	return r
+}
+
+func toolMessage(m client.ToolMessage) Message {
+	switch m.Role {
+	case client.ToolRoleTool:
+		return Message(openai.ToolMessage(m.Content, m.ToolCallID))
+	case client.ToolRoleAssistant:
+		if len(m.ToolCalls) == 0 {
+			return AssistantMessage(m.Content)
+		}
+		assistant := openai.ChatCompletionAssistantMessageParam{}
+		if m.Content != "" {
+			assistant.Content.OfString = openai.String(m.Content)
+		}
+		for _, call := range m.ToolCalls {
+			assistant.ToolCalls = append(assistant.ToolCalls, openai.ChatCompletionMessageToolCallUnionParam{
+				OfFunction: &openai.ChatCompletionMessageFunctionToolCallParam{
+					ID: call.ID,
+					Function: openai.ChatCompletionMessageFunctionToolCallFunctionParam{
+						Name:      call.Name,
+						Arguments: call.Arguments,
+					},
+				},
+			})
+		}
+		return Message(openai.ChatCompletionMessageParamUnion{OfAssistant: &assistant})
+	default:
+		return UserMessage(m.Content)
+	}
+}
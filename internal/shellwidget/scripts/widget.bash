@@ -0,0 +1,29 @@
+# gomor shell widget: press the key below to turn the command line into a
+# shell command. Edit a suggestion before running it and gomor remembers the
+# correction next time.
+_gomor_widget() {
+  [[ -z "$READLINE_LINE" ]] && return
+  local request=$READLINE_LINE suggestion
+  if ! suggestion=$(gomor shell-widget suggest --shell bash -- "$request" 2>/dev/null) || [[ -z "$suggestion" ]]; then
+    return
+  fi
+  _gomor_widget_request=$request
+  _gomor_widget_suggestion=$suggestion
+  _gomor_widget_histnum=$(HISTTIMEFORMAT= history 1 | awk '{print $1}')
+  READLINE_LINE=$suggestion
+  READLINE_POINT=${#READLINE_LINE}
+}
+
+_gomor_widget_learn() {
+  [[ -z "$_gomor_widget_suggestion" ]] && return
+  local num ran
+  read -r num ran <<< "$(HISTTIMEFORMAT= history 1)"
+  # An unchanged history number means nothing was run after the suggestion.
+  if [[ "$num" != "$_gomor_widget_histnum" && -n "$ran" && "$ran" != "$_gomor_widget_suggestion" ]]; then
+    (gomor shell-widget learn --request "$_gomor_widget_request" --suggested "$_gomor_widget_suggestion" --ran "$ran" >/dev/null 2>&1 &)
+  fi
+  unset _gomor_widget_request _gomor_widget_suggestion _gomor_widget_histnum
+}
+
+bind -x '"{{KEY}}": _gomor_widget'
+PROMPT_COMMAND="_gomor_widget_learn${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
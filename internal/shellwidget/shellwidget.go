@@ -0,0 +1,182 @@
+// Package shellwidget turns a natural-language request typed at a shell
+// prompt into a command, for the zsh and bash keybindings printed by
+// 'gomor shell-widget'. Commands the user edits before running are kept as
+// correction memories and shown to the model on later requests.
+package shellwidget
+
+import (
+	_ "embed"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/austiecodes/gomor/internal/markdown"
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/pricing"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// Supported shells.
+const (
+	ShellZsh  = "zsh"
+	ShellBash = "bash"
+)
+
+// DefaultKey is the keybinding, Ctrl-G, in each shell's notation.
+var DefaultKey = map[string]string{
+	ShellZsh:  "^G",
+	ShellBash: `\C-g`,
+}
+
+// CorrectionTag marks the memories that record a corrected suggestion.
+const CorrectionTag = "shell-correction"
+
+// maxCorrections bounds how many past corrections are shown to the model.
+const maxCorrections = 5
+
+var (
+	//go:embed scripts/widget.zsh
+	zshScript string
+	//go:embed scripts/widget.bash
+	bashScript string
+)
+
+// Script returns the widget for shell, bound to key ("" for DefaultKey).
+func Script(shell, key string) (string, error) {
+	var script string
+	switch shell {
+	case ShellZsh:
+		script = zshScript
+	case ShellBash:
+		script = bashScript
+	default:
+		return "", fmt.Errorf("unsupported shell %q (valid: zsh, bash)", shell)
+	}
+	if key == "" {
+		key = DefaultKey[shell]
+	}
+	if strings.ContainsAny(key, `'"`) {
+		return "", fmt.Errorf("key %q must not contain quotes", key)
+	}
+	return strings.ReplaceAll(script, "{{KEY}}", key), nil
+}
+
+// CheapestModel returns the configured chat, title, think, or tool model with
+// the lowest known price, and its role. Models with no known price rank
+// after priced ones; ties keep the order above. ok is false when no model is
+// configured.
+func CheapestModel(config *utils.Config) (model types.Model, role string, ok bool) {
+	candidates := []struct {
+		role  string
+		model *types.Model
+	}{
+		{utils.RoleChat, config.Model.ChatModel},
+		{utils.RoleTitle, config.Model.TitleModel},
+		{utils.RoleThink, config.Model.ThinkModel},
+		{utils.RoleTool, config.Model.ToolModel},
+	}
+
+	bestCost, bestPriced := 0.0, false
+	for _, c := range candidates {
+		if c.model == nil || c.model.ModelID == "" {
+			continue
+		}
+		price, priced := pricing.Lookup(*c.model, config.Budget.Prices)
+		// Requests are short and answers shorter, so input and output weigh alike.
+		cost := price.Input + price.Output
+		if !ok || (priced && (!bestPriced || cost < bestCost)) {
+			model, role, ok = *c.model, c.role, true
+			bestCost, bestPriced = cost, priced
+		}
+	}
+	return model, role, ok
+}
+
+// Prompt asks for a single command for shell that does what request says,
+// showing the corrections the user made to earlier suggestions.
+func Prompt(shell, request string, corrections []string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Turn the request below into a single %s command for %s. ", shell, runtime.GOOS)
+	sb.WriteString("If the request is already a command, fix or complete it. ")
+	sb.WriteString("Reply with the command only, on one line, with no explanation and no code fence.\n")
+	if len(corrections) > 0 {
+		sb.WriteString("\nThe user corrected these earlier suggestions; follow their preferences:\n")
+		for _, c := range corrections {
+			sb.WriteString("- " + c + "\n")
+		}
+	}
+	sb.WriteString("\nRequest: " + request)
+	return sb.String()
+}
+
+// Clean extracts the command from a model answer: the last code block if
+// there is one, otherwise the first non-empty line, without backticks or a
+// leading prompt sign.
+func Clean(answer string) string {
+	if blocks := markdown.CodeBlocks(answer); len(blocks) > 0 {
+		answer = blocks[len(blocks)-1].Code
+	}
+	for _, line := range strings.Split(answer, "\n") {
+		line = strings.TrimSpace(strings.Trim(strings.TrimSpace(line), "`"))
+		line = strings.TrimSpace(strings.TrimPrefix(line, "$ "))
+		if line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// CorrectionText describes a suggestion the user edited before running it.
+func CorrectionText(request, suggested, ran string) string {
+	return fmt.Sprintf("For the shell request %q, the user ran `%s` instead of the suggested `%s`.", request, ran, suggested)
+}
+
+// Corrections returns the texts of the correction memories most relevant to
+// request: those sharing the most words with it, newest first among equals.
+func Corrections(memories []memtypes.MemoryItem, request string) []string {
+	words := make(map[string]bool)
+	for _, w := range strings.Fields(strings.ToLower(request)) {
+		words[w] = true
+	}
+
+	type scored struct {
+		item   memtypes.MemoryItem
+		shared int
+	}
+	var candidates []scored
+	for _, m := range memories {
+		if !hasTag(m, CorrectionTag) || !(memtypes.MemoryFilter{}).Matches(m) {
+			continue
+		}
+		shared := 0
+		for _, w := range strings.Fields(strings.ToLower(m.Text)) {
+			if words[strings.Trim(w, "\"`.,")] {
+				shared++
+			}
+		}
+		candidates = append(candidates, scored{item: m, shared: shared})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].shared != candidates[j].shared {
+			return candidates[i].shared > candidates[j].shared
+		}
+		return candidates[i].item.CreatedAt.After(candidates[j].item.CreatedAt)
+	})
+
+	var texts []string
+	for _, c := range candidates[:min(len(candidates), maxCorrections)] {
+		texts = append(texts, c.item.Text)
+	}
+	return texts
+}
+
+func hasTag(item memtypes.MemoryItem, tag string) bool {
+	for _, t := range item.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
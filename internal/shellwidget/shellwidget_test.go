@@ -0,0 +1,107 @@
+package shellwidget
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/memory/memtypes"
+	"github.com/austiecodes/gomor/internal/types"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+func TestScriptBindsKey(t *testing.T) {
+	zsh, err := Script(ShellZsh, "")
+	if err != nil {
+		t.Fatalf("zsh script: %v", err)
+	}
+	if !strings.Contains(zsh, "bindkey '^G' _gomor_widget") || strings.Contains(zsh, "{{KEY}}") {
+		t.Fatalf("zsh script not bound to Ctrl-G:\n%s", zsh)
+	}
+
+	bash, err := Script(ShellBash, `\C-t`)
+	if err != nil {
+		t.Fatalf("bash script: %v", err)
+	}
+	if !strings.Contains(bash, `bind -x '"\C-t": _gomor_widget'`) {
+		t.Fatalf("bash script not bound to --key:\n%s", bash)
+	}
+
+	if _, err := Script("fish", ""); err == nil {
+		t.Fatal("expected an error for an unsupported shell")
+	}
+	if _, err := Script(ShellZsh, `^G'; rm -rf ~'`); err == nil {
+		t.Fatal("expected an error for a key with quotes")
+	}
+}
+
+func TestCheapestModel(t *testing.T) {
+	config := &utils.Config{}
+	config.Model.ChatModel = &types.Model{Provider: "openai", ModelID: "gpt-4o"}
+	config.Model.ThinkModel = &types.Model{Provider: "openai", ModelID: "my-finetune"}
+	config.Model.ToolModel = &types.Model{Provider: "openai", ModelID: "gpt-4o-mini"}
+
+	model, role, ok := CheapestModel(config)
+	if !ok || model.ModelID != "gpt-4o-mini" || role != utils.RoleTool {
+		t.Fatalf("got %s (%s), want the tool model gpt-4o-mini", model.ModelID, role)
+	}
+
+	config.Budget.Prices = map[string]utils.ModelPrice{"openai/my-finetune": {Input: 0.01, Output: 0.01}}
+	if model, role, _ := CheapestModel(config); model.ModelID != "my-finetune" || role != utils.RoleThink {
+		t.Fatalf("got %s (%s), want the overridden think model", model.ModelID, role)
+	}
+
+	unpriced := &utils.Config{}
+	unpriced.Model.ChatModel = &types.Model{Provider: "openai", ModelID: "local-a"}
+	unpriced.Model.ToolModel = &types.Model{Provider: "openai", ModelID: "local-b"}
+	if model, _, ok := CheapestModel(unpriced); !ok || model.ModelID != "local-a" {
+		t.Fatalf("got %s, want the first configured model when none is priced", model.ModelID)
+	}
+
+	if _, _, ok := CheapestModel(&utils.Config{}); ok {
+		t.Fatal("expected no model when none is configured")
+	}
+}
+
+func TestClean(t *testing.T) {
+	cases := map[string]string{
+		"ls -la":                                 "ls -la",
+		"`git status`":                           "git status",
+		"$ du -sh *\n":                           "du -sh *",
+		"Here:\n```sh\nfind . -name '*.go'\n```": "find . -name '*.go'",
+		"\n\n  tar xzf a.tgz  \nextra":           "tar xzf a.tgz",
+		"":                                       "",
+	}
+	for answer, want := range cases {
+		if got := Clean(answer); got != want {
+			t.Errorf("Clean(%q) = %q, want %q", answer, got, want)
+		}
+	}
+}
+
+func TestCorrectionsPreferRelatedAndRecent(t *testing.T) {
+	now := time.Now()
+	memories := []memtypes.MemoryItem{
+		{Text: CorrectionText("list big folders", "ls -S", "du -ah | sort -h"), Tags: []string{CorrectionTag}, CreatedAt: now.Add(-time.Hour)},
+		{Text: CorrectionText("find go files", "find . -name '*.go'", "fd -e go"), Tags: []string{CorrectionTag}, CreatedAt: now.Add(-48 * time.Hour)},
+		{Text: CorrectionText("show disk usage", "df", "df -h"), Tags: []string{CorrectionTag}, CreatedAt: now},
+		{Text: "find files is a fact, not a correction", CreatedAt: now},
+		{Text: CorrectionText("find old files", "find .", "fd --changed-before 1y"), Tags: []string{CorrectionTag}, CreatedAt: now, Suppressed: true},
+	}
+
+	got := Corrections(memories, "find python files")
+	if len(got) != 3 {
+		t.Fatalf("expected the 3 live corrections, got %q", got)
+	}
+	if !strings.Contains(got[0], "fd -e go") {
+		t.Fatalf("expected the related correction first, got %q", got)
+	}
+	if !strings.Contains(got[1], "df -h") {
+		t.Fatalf("expected newer corrections before older ones, got %q", got)
+	}
+
+	prompt := Prompt(ShellZsh, "find python files", got)
+	if !strings.Contains(prompt, "single zsh command") || !strings.Contains(prompt, "- "+got[0]) || !strings.HasSuffix(prompt, "Request: find python files") {
+		t.Fatalf("unexpected prompt:\n%s", prompt)
+	}
+}
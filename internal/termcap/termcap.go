@@ -0,0 +1,59 @@
+// Package termcap detects what the terminal gomor draws in can show. The
+// classic Windows console host (conhost) renders box-drawing characters,
+// bullets, and other non-ASCII glyphs unreliably with its default fonts, so
+// the TUIs and rendered output fall back to ASCII there.
+package termcap
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Legacy reports whether gomor runs in the classic Windows console host.
+func Legacy() bool {
+	return legacy(runtime.GOOS, os.Getenv)
+}
+
+// legacy treats a Windows console as conhost unless a modern terminal
+// announced itself: Windows Terminal, VS Code and other TERM_PROGRAM setters,
+// ConEmu, ANSICON, and mintty (Git Bash), which sets TERM.
+func legacy(goos string, getenv func(string) string) bool {
+	if goos != "windows" {
+		return false
+	}
+	for _, name := range []string{"WT_SESSION", "TERM_PROGRAM", "ConEmuANSI", "ANSICON", "TERM"} {
+		if getenv(name) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Glyph returns fancy, or plain on a legacy console.
+func Glyph(fancy, plain string) string {
+	if Legacy() {
+		return plain
+	}
+	return fancy
+}
+
+// NewList creates a list with the default delegate, drawn in ASCII on a
+// legacy console.
+func NewList(items []list.Item, width, height int) list.Model {
+	delegate := list.NewDefaultDelegate()
+	if !Legacy() {
+		return list.New(items, delegate, width, height)
+	}
+
+	border := lipgloss.Border{Left: "|"}
+	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.BorderStyle(border)
+	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.BorderStyle(border)
+	l := list.New(items, delegate, width, height)
+	l.Styles.DividerDot = l.Styles.DividerDot.SetString(" - ")
+	l.Paginator.ActiveDot = l.Styles.ActivePaginationDot.SetString("*").String()
+	l.Paginator.InactiveDot = l.Styles.InactivePaginationDot.SetString(".").String()
+	return l
+}
@@ -0,0 +1,27 @@
+package termcap
+
+import "testing"
+
+func TestLegacy(t *testing.T) {
+	tests := []struct {
+		name string
+		goos string
+		env  map[string]string
+		want bool
+	}{
+		{name: "conhost", goos: "windows", want: true},
+		{name: "windows terminal", goos: "windows", env: map[string]string{"WT_SESSION": "b3f1"}},
+		{name: "vscode", goos: "windows", env: map[string]string{"TERM_PROGRAM": "vscode"}},
+		{name: "git bash", goos: "windows", env: map[string]string{"TERM": "xterm-256color"}},
+		{name: "conemu", goos: "windows", env: map[string]string{"ConEmuANSI": "ON"}},
+		{name: "linux console", goos: "linux"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(name string) string { return tt.env[name] }
+			if got := legacy(tt.goos, getenv); got != tt.want {
+				t.Fatalf("legacy = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
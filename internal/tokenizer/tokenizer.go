@@ -0,0 +1,124 @@
+// Package tokenizer estimates how many tokens a model will see for a piece of
+// text, so callers can fit prompts into a budget before sending them.
+package tokenizer
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/pkoukk/tiktoken-go"
+	tiktokenloader "github.com/pkoukk/tiktoken-go-loader"
+
+	"github.com/austiecodes/gomor/internal/consts"
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+// Counter counts the tokens of text for one model.
+type Counter interface {
+	Count(text string) int
+}
+
+// Usage is the token usage of one request.
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// messageOverhead approximates the tokens chat APIs add around each message
+// for roles and separators.
+const messageOverhead = 4
+
+// EstimateInput estimates the input tokens of a request sending messages
+// (system context, prompt, ...). Empty messages are not sent and not counted.
+func EstimateInput(counter Counter, messages ...string) int {
+	total := 0
+	for _, m := range messages {
+		if m == "" {
+			continue
+		}
+		total += counter.Count(m) + messageOverhead
+	}
+	return total
+}
+
+// heuristic estimates tokens from the character count. It over-counts rather
+// than under-counts so budgets are not exceeded.
+type heuristic struct {
+	charsPerToken float64
+}
+
+func (h heuristic) Count(text string) int {
+	if text == "" {
+		return 0
+	}
+	return int(math.Ceil(float64(utf8.RuneCountInString(text)) / h.charsPerToken))
+}
+
+// bpe counts tokens exactly with an OpenAI BPE encoding.
+type bpe struct {
+	encoding *tiktoken.Tiktoken
+}
+
+func (b bpe) Count(text string) int {
+	if text == "" {
+		return 0
+	}
+	return len(b.encoding.EncodeOrdinary(text))
+}
+
+// defaultEncoding is used for OpenAI models tiktoken does not know yet; every
+// current chat model uses it.
+const defaultEncoding = "o200k_base"
+
+var (
+	loaderOnce sync.Once
+	encodingMu sync.Mutex
+	encodings  = map[string]*tiktoken.Tiktoken{}
+)
+
+// openAIEncoding loads the BPE encoding for modelID from the ranks embedded in
+// the binary, so counting never needs the network.
+func openAIEncoding(modelID string) (*tiktoken.Tiktoken, error) {
+	loaderOnce.Do(func() {
+		tiktoken.SetBpeLoader(tiktokenloader.NewOfflineLoader())
+	})
+
+	encodingMu.Lock()
+	defer encodingMu.Unlock()
+
+	if enc, ok := encodings[modelID]; ok {
+		return enc, nil
+	}
+	enc, err := tiktoken.EncodingForModel(modelID)
+	if err != nil {
+		enc, err = tiktoken.GetEncoding(defaultEncoding)
+	}
+	if err != nil {
+		return nil, err
+	}
+	encodings[modelID] = enc
+	return enc, nil
+}
+
+// ForModel returns the counter for model. OpenAI models are counted with
+// their tiktoken encoding; other providers get a character-based estimate.
+func ForModel(model types.Model) Counter {
+	switch model.Provider {
+	case consts.ProviderOpenAI:
+		if enc, err := openAIEncoding(model.ModelID); err == nil {
+			return bpe{encoding: enc}
+		}
+		return heuristic{charsPerToken: 4}
+	case consts.ProviderOpenRouter:
+		if modelID, ok := strings.CutPrefix(model.ModelID, "openai/"); ok {
+			return ForModel(types.Model{Provider: consts.ProviderOpenAI, ModelID: modelID})
+		}
+		return heuristic{charsPerToken: 3.5}
+	case consts.ProviderGoogle:
+		return heuristic{charsPerToken: 4}
+	default:
+		return heuristic{charsPerToken: 3.5}
+	}
+}
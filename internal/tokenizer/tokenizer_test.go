@@ -0,0 +1,50 @@
+package tokenizer
+
+import (
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/consts"
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+func TestForModelCountsOpenAIWithTiktoken(t *testing.T) {
+	counter := ForModel(types.Model{Provider: consts.ProviderOpenAI, ModelID: "gpt-4o-mini"})
+	if _, ok := counter.(bpe); !ok {
+		t.Fatalf("expected a tiktoken counter, got %T", counter)
+	}
+	if got := counter.Count("hello world"); got != 2 {
+		t.Fatalf("expected 2 tokens, got %d", got)
+	}
+
+	// Models tiktoken does not know yet fall back to the current encoding.
+	if got := ForModel(types.Model{Provider: consts.ProviderOpenAI, ModelID: "gpt-5-nano"}).Count("hello world"); got != 2 {
+		t.Fatalf("expected 2 tokens for an unknown model, got %d", got)
+	}
+	if _, ok := ForModel(types.Model{Provider: consts.ProviderOpenRouter, ModelID: "openai/gpt-4o"}).(bpe); !ok {
+		t.Fatal("expected openrouter openai models to use tiktoken")
+	}
+}
+
+func TestForModelEstimatesOtherProviders(t *testing.T) {
+	text := "0123456789abcdef" // 16 runes
+
+	if got := ForModel(types.Model{Provider: consts.ProviderGoogle}).Count(text); got != 4 {
+		t.Fatalf("google: expected 4 tokens, got %d", got)
+	}
+	if got := ForModel(types.Model{Provider: consts.ProviderAnthropic}).Count(text); got != 5 {
+		t.Fatalf("anthropic: expected 5 tokens, got %d", got)
+	}
+	if got := ForModel(types.Model{}).Count(""); got != 0 {
+		t.Fatalf("expected 0 tokens for empty text, got %d", got)
+	}
+}
+
+func TestEstimateInputAddsMessageOverhead(t *testing.T) {
+	counter := heuristic{charsPerToken: 4}
+	if got := EstimateInput(counter, "", "12345678"); got != 2+messageOverhead {
+		t.Fatalf("expected %d tokens, got %d", 2+messageOverhead, got)
+	}
+	if got := EstimateInput(counter); got != 0 {
+		t.Fatalf("expected 0 tokens without messages, got %d", got)
+	}
+}
@@ -0,0 +1,147 @@
+// Package trace reports what gomor does on behalf of a command, on stderr: the
+// models it resolves, the context it injects, how long retrieval takes, the
+// tokens a request uses, and the HTTP requests it retries. The root command's
+// --verbose flag sets the level.
+package trace
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+// Level is how much is reported.
+type Level int
+
+const (
+	// Off reports nothing.
+	Off Level = iota
+	// Info (-v) reports models, injected context, timings, usage, and retries.
+	Info
+	// Debug (-vv) also reports every HTTP request and the full text sent.
+	Debug
+)
+
+var (
+	mu    sync.Mutex
+	level           = Off
+	out   io.Writer = os.Stderr
+)
+
+// SetLevel sets how much is reported. Levels above Debug count as Debug.
+func SetLevel(l Level) {
+	mu.Lock()
+	defer mu.Unlock()
+	level = min(max(l, Off), Debug)
+}
+
+// SetOutput sets where reports are written, stderr by default.
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	out = w
+}
+
+// Enabled reports whether l is being reported.
+func Enabled(l Level) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return l != Off && l <= level
+}
+
+// Printf reports a line at level l, prefixed with "trace: ".
+func Printf(l Level, format string, args ...any) {
+	mu.Lock()
+	defer mu.Unlock()
+	if l == Off || l > level {
+		return
+	}
+	fmt.Fprintf(out, "trace: "+strings.TrimRight(format, "\n")+"\n", args...)
+}
+
+// Block reports text at level l under a header, indented so it stands apart
+// from the lines around it.
+func Block(l Level, header, text string) {
+	if !Enabled(l) {
+		return
+	}
+	var sb strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		sb.WriteString("\n  | " + line)
+	}
+	Printf(l, "%s:%s", header, sb.String())
+}
+
+// Since formats the time elapsed since start, rounded for reading.
+func Since(start time.Time) string {
+	d := time.Since(start)
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(10 * time.Millisecond).String()
+}
+
+// HTTPClient returns a client that reports failed and retried requests, and
+// at Debug every request, or nil when nothing is reported so providers keep
+// their default client.
+func HTTPClient() *http.Client {
+	if !Enabled(Info) {
+		return nil
+	}
+	return &http.Client{Transport: transport{next: http.DefaultTransport}}
+}
+
+type transport struct {
+	next http.RoundTripper
+}
+
+// retryHeader is set by the OpenAI and Anthropic SDKs on each attempt.
+const retryHeader = "X-Stainless-Retry-Count"
+
+func (t transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	target := req.Method + " " + req.URL.Host + req.URL.Path
+	if n, _ := strconv.Atoi(req.Header.Get(retryHeader)); n > 0 {
+		Printf(Info, "retry %d: %s", n, target)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	switch {
+	case err != nil:
+		Printf(Info, "http: %s failed after %s: %v", target, Since(start), err)
+	case resp.StatusCode >= 400:
+		Printf(Info, "http: %s: %s after %s", target, resp.Status, Since(start))
+	default:
+		Printf(Debug, "http: %s: %s after %s", target, resp.Status, Since(start))
+	}
+	return resp, err
+}
+
+// Model names model as "provider/model", followed by its sampling overrides.
+func Model(model types.Model) string {
+	name := model.Provider + "/" + model.ModelID
+	var overrides []string
+	if model.Temperature != nil {
+		overrides = append(overrides, fmt.Sprintf("temperature %g", *model.Temperature))
+	}
+	if model.MaxTokens > 0 {
+		overrides = append(overrides, fmt.Sprintf("max tokens %d", model.MaxTokens))
+	}
+	if model.Seed != nil {
+		overrides = append(overrides, fmt.Sprintf("seed %d", *model.Seed))
+	}
+	if len(model.Stop) > 0 {
+		overrides = append(overrides, fmt.Sprintf("stop %q", model.Stop))
+	}
+	if len(overrides) > 0 {
+		name += " (" + strings.Join(overrides, ", ") + ")"
+	}
+	return name
+}
@@ -0,0 +1,112 @@
+package trace
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/types"
+)
+
+// capture reports at l into a buffer for the rest of the test.
+func capture(t *testing.T, l Level) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	SetLevel(l)
+	SetOutput(&buf)
+	t.Cleanup(func() {
+		SetLevel(Off)
+		SetOutput(os.Stderr)
+	})
+	return &buf
+}
+
+func TestPrintfFiltersByLevel(t *testing.T) {
+	buf := capture(t, Info)
+
+	Printf(Info, "shown %d", 1)
+	Printf(Debug, "hidden")
+
+	if got := buf.String(); got != "trace: shown 1\n" {
+		t.Fatalf("output = %q", got)
+	}
+}
+
+func TestOffReportsNothing(t *testing.T) {
+	buf := capture(t, Off)
+
+	Printf(Info, "hidden")
+	Block(Info, "prompt", "hidden")
+
+	if buf.Len() != 0 {
+		t.Fatalf("output = %q, want none", buf.String())
+	}
+	if HTTPClient() != nil {
+		t.Fatal("HTTPClient() should be nil when tracing is off")
+	}
+}
+
+func TestSetLevelClamps(t *testing.T) {
+	capture(t, Level(5))
+
+	if !Enabled(Debug) {
+		t.Fatal("levels above Debug should enable Debug")
+	}
+	if Enabled(Off) {
+		t.Fatal("Off is never reported")
+	}
+}
+
+func TestBlockIndentsText(t *testing.T) {
+	buf := capture(t, Debug)
+
+	Block(Debug, "prompt", "one\ntwo\n")
+
+	want := "trace: prompt:\n  | one\n  | two\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+}
+
+func TestModel(t *testing.T) {
+	temperature := 0.2
+	seed := int64(7)
+	got := Model(types.Model{Provider: "openai", ModelID: "gpt-4o", Temperature: &temperature, MaxTokens: 100, Seed: &seed})
+
+	want := "openai/gpt-4o (temperature 0.2, max tokens 100, seed 7)"
+	if got != want {
+		t.Fatalf("Model() = %q, want %q", got, want)
+	}
+	if got := Model(types.Model{Provider: "openai", ModelID: "gpt-4o"}); got != "openai/gpt-4o" {
+		t.Fatalf("Model() = %q", got)
+	}
+}
+
+func TestHTTPClientReportsRetriesAndFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+	buf := capture(t, Info)
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/v1/chat", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(retryHeader, "2")
+	resp, err := HTTPClient().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	got := buf.String()
+	for _, want := range []string{"trace: retry 2: POST", "/v1/chat: 429 Too Many Requests after"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output %q missing %q", got, want)
+		}
+	}
+}
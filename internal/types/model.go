@@ -3,4 +3,13 @@ package types
 type Model struct {
 	Provider string `json:"provider"`
 	ModelID  string `json:"model_id"`
+	// Temperature overrides the provider's default sampling temperature when set.
+	Temperature *float64 `json:"temperature,omitempty"`
+	// MaxTokens caps the length of a response; 0 uses the provider's default.
+	MaxTokens int `json:"max_tokens,omitempty"`
+	// Stop ends a response at the first of these sequences.
+	Stop []string `json:"stop,omitempty"`
+	// Seed asks the provider for repeatable sampling. Providers without seed
+	// support (Anthropic) ignore it.
+	Seed *int64 `json:"seed,omitempty"`
 }
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
 
 	"github.com/austiecodes/gomor/internal/consts"
 	"github.com/austiecodes/gomor/internal/types"
@@ -17,6 +20,7 @@ const (
 	DBFile      = "memory.db"
 	HistoryDir  = "history"
 	LogsDir     = "logs"
+	BackupsDir  = "backups"
 )
 
 // OpenAIProviderConfig represents the OpenAI provider configuration
@@ -25,10 +29,14 @@ type OpenAIProviderConfig struct {
 	BaseURL string `json:"base_url,omitempty"`
 }
 
-// GoogleProviderConfig represents the Google provider configuration
+// GoogleProviderConfig represents the Google provider configuration.
+// Setting Project uses Vertex AI with Application Default Credentials
+// instead of the Gemini API.
 type GoogleProviderConfig struct {
-	APIKey  string `json:"api_key"`
-	BaseURL string `json:"base_url,omitempty"`
+	APIKey   string `json:"api_key"`
+	BaseURL  string `json:"base_url,omitempty"`
+	Project  string `json:"project,omitempty"`  // Vertex AI project ID
+	Location string `json:"location,omitempty"` // Vertex AI region, default us-central1
 }
 
 // AnthropicProviderConfig represents the Anthropic provider configuration
@@ -37,11 +45,19 @@ type AnthropicProviderConfig struct {
 	BaseURL string `json:"base_url,omitempty"`
 }
 
+// OllamaProviderConfig represents the Ollama provider configuration. Ollama
+// needs no API key.
+type OllamaProviderConfig struct {
+	BaseURL string `json:"base_url,omitempty"` // default http://localhost:11434
+	Model   string `json:"model,omitempty"`    // answers chat requests that name no model
+}
+
 // ProviderConfigs holds all provider configurations
 type ProviderConfigs struct {
 	OpenAI    OpenAIProviderConfig    `json:"openai"`
 	Google    GoogleProviderConfig    `json:"google"`
 	Anthropic AnthropicProviderConfig `json:"anthropic"`
+	Ollama    OllamaProviderConfig    `json:"ollama"`
 }
 
 // ModelConfig represents the model section in config
@@ -51,28 +67,439 @@ type ModelConfig struct {
 	ThinkModel     *types.Model `json:"think_model,omitempty"`
 	ToolModel      *types.Model `json:"tool_model,omitempty"`
 	EmbeddingModel *types.Model `json:"embedding_model,omitempty"`
+
+	// Fallbacks lists, per model role, the "provider/model" IDs to answer
+	// with in order when the role's model is rate limited or unavailable.
+	Fallbacks map[string][]string `json:"fallbacks,omitempty"`
+
+	// Aliases maps friendly names, e.g. "fast" or "smart", to the
+	// "provider/model" ID or role they stand for. An alias can be used
+	// wherever a model is chosen, so workflows need not name vendor models.
+	Aliases map[string]string `json:"aliases,omitempty"`
+}
+
+// Model roles that can fall back to other models. Embedding models cannot,
+// since vectors from different models are not comparable.
+const (
+	RoleChat  = "chat"
+	RoleTitle = "title"
+	RoleThink = "think"
+	RoleTool  = "tool"
+)
+
+// FallbackModels parses the fallback chain configured for role.
+func (c ModelConfig) FallbackModels(role string) ([]types.Model, error) {
+	models := make([]types.Model, 0, len(c.Fallbacks[role]))
+	for _, ref := range c.Fallbacks[role] {
+		if _, isAlias := c.Aliases[ref]; isAlias {
+			model, err := c.ResolveModel(ref)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s fallback: %w", role, err)
+			}
+			models = append(models, model)
+			continue
+		}
+		model, ok := ParseModelRef(ref)
+		if !ok {
+			return nil, fmt.Errorf("invalid %s fallback %q: use provider/model, e.g. google/gemini-2.5-flash, or an alias", role, ref)
+		}
+		models = append(models, model)
+	}
+	return models, nil
+}
+
+// ResolveModel returns the model ref names: an alias, a role with a
+// configured model (chat, title, think, tool), or a "provider/model" ID.
+// Aliases are looked up first and may stand for a role or a provider/model
+// ID, but not for another alias.
+func (c ModelConfig) ResolveModel(ref string) (types.Model, error) {
+	name := ref
+	if target, ok := c.Aliases[ref]; ok {
+		name = target
+	}
+	if configured := c.RoleModel(name); configured != nil {
+		return *configured, nil
+	}
+	if model, ok := ParseModelRef(name); ok {
+		return model, nil
+	}
+	if name != ref {
+		return types.Model{}, fmt.Errorf("alias %q stands for %q: use provider/model, e.g. openai/gpt-4o-mini, or a configured role", ref, name)
+	}
+	return types.Model{}, fmt.Errorf("unknown model %q: use provider/model, e.g. openai/gpt-4o, an alias from model.aliases, or a configured role (chat, title, think, tool)", ref)
+}
+
+// RoleModel returns the model configured for role, or nil if there is none.
+func (c ModelConfig) RoleModel(role string) *types.Model {
+	switch role {
+	case RoleChat:
+		return c.ChatModel
+	case RoleTitle:
+		return c.TitleModel
+	case RoleThink:
+		return c.ThinkModel
+	case RoleTool:
+		return c.ToolModel
+	}
+	return nil
+}
+
+// ParseModelRef parses a "provider/model" reference. Model IDs may contain
+// further slashes, as OpenRouter's do.
+func ParseModelRef(ref string) (types.Model, bool) {
+	providerName, modelID, ok := strings.Cut(ref, "/")
+	if !ok || providerName == "" || modelID == "" {
+		return types.Model{}, false
+	}
+	return types.Model{Provider: providerName, ModelID: modelID}, true
 }
 
 // FTS strategy constants
 const (
-	FTSStrategyAuto = "auto" // Try direct first, fallback to summary if few results
+	FTSStrategyAuto    = "auto"    // Run direct and summary search together; merge summary results when direct finds few
+	FTSStrategyDirect  = "direct"  // Search the query's own words only
+	FTSStrategySummary = "summary" // Search the words of a tool-model summary of the query
+)
+
+// FTSStrategies lists the valid values of MemoryConfig.FTSStrategy.
+var FTSStrategies = []string{FTSStrategyAuto, FTSStrategyDirect, FTSStrategySummary}
+
+// CheckFTSStrategy returns an error unless strategy is one of FTSStrategies.
+func CheckFTSStrategy(strategy string) error {
+	if slices.Contains(FTSStrategies, strategy) {
+		return nil
+	}
+	return fmt.Errorf("unknown FTS strategy %q (valid: %s)", strategy, strings.Join(FTSStrategies, ", "))
+}
+
+// Memory language settings; any other value names the language to translate queries into
+const (
+	MemoryLanguageAuto = "auto" // translate queries into the dominant language of stored memories
+	MemoryLanguageOff  = "off"  // never translate queries
+)
+
+// Query classifier settings
+const (
+	QueryClassifierOff       = "off"       // retrieve every query the same way
+	QueryClassifierHeuristic = "heuristic" // classify queries by their wording
+	QueryClassifierModel     = "model"     // classify by wording, asking the tool model when it is unclear
 )
 
 // MemoryConfig represents the memory/retrieval configuration
 type MemoryConfig struct {
-	MinSimilarity    float64 `json:"min_similarity"`
-	MemoryTopK       int     `json:"memory_top_k"`
-	HistoryTopK      int     `json:"history_top_k"`
-	MaxInjectedChars int     `json:"max_injected_chars"`
-	FTSStrategy      string  `json:"fts_strategy"`
+	MinSimilarity       float64 `json:"min_similarity"`
+	MemoryTopK          int     `json:"memory_top_k"`
+	HistoryTopK         int     `json:"history_top_k"`
+	MaxInjectedTokens   int     `json:"max_injected_tokens"` // token budget for the retrieved memories returned as text
+	FTSStrategy         string  `json:"fts_strategy"`
+	FTSLatencyBudgetMs  int     `json:"fts_latency_budget_ms"` // how long FTS waits for the tool-model summary strategy
+	FusionVectorWeight  float64 `json:"fusion_vector_weight"`  // share of vector similarity in the score of memories both paths found; FTS gets the rest
+	TransformTimeoutMs  int     `json:"transform_timeout_ms"`  // deadline for each tool-model query rewrite, translation, or transform
+	EmbeddingTimeoutMs  int     `json:"embedding_timeout_ms"`  // deadline for each query embedding
+	RewriteHistoryTurns int     `json:"rewrite_history_turns"` // recent turns used to rewrite follow-up queries
+	EntityLinking       bool    `json:"entity_linking"`        // extract entities on save and boost entity matches
+	StrictRetrieval     bool    `json:"strict_retrieval"`      // fail retrieval if any search path fails instead of returning partial results
+	Language            string  `json:"language"`              // "auto", "off", or the language memories are written in, e.g. "Chinese"
+	// TemporalFilter restricts retrieval to the memories created in the time
+	// named by a phrase in the query, e.g. "yesterday" or "last 3 days". Off
+	// by default, since such phrases are often about the memory itself
+	// ("what should I cook today", "standups are on Monday").
+	TemporalFilter bool `json:"temporal_filter"`
+	// QueryClassifier labels each query as keyword, question, temporal, or
+	// preference, and picks the FTS strategy, the number of tool-model
+	// expansions searched, and whether conversation history rewrites it by
+	// label: "off" (the default), "heuristic", or "model".
+	QueryClassifier string `json:"query_classifier"`
+	// FTSMinTokenLength is the shortest query term, in characters, kept in
+	// full-text search. The default of 1 keeps single letters such as "C" or "R".
+	FTSMinTokenLength int `json:"fts_min_token_length"`
+	// FTSStopWords are query terms left out of full-text search. Unset (null)
+	// uses the built-in list for the query's language, or for Language when it
+	// names one; an empty list searches every term.
+	FTSStopWords []string `json:"fts_stop_words"`
+	// AutoApproveExtracted lets memories saved by agents be retrieved right away
+	// instead of waiting in the review queue.
+	AutoApproveExtracted bool `json:"auto_approve_extracted"`
+	// AutoTag asks the tool model to tag memories saved without tags.
+	AutoTag bool `json:"auto_tag"`
+	// TagVocabulary, when set, is the only tags auto-tagging may choose from.
+	// Saved tags matching an entry take its spelling.
+	TagVocabulary []string `json:"tag_vocabulary,omitempty"`
+	// TagAliases maps alternative tag names to their canonical tag, e.g.
+	// "js" to "javascript". Aliases are rewritten on save and match the
+	// canonical tag in tag filters.
+	TagAliases map[string]string `json:"tag_aliases,omitempty"`
+	// CompressCommands names the commands whose retrieved memories are
+	// summarized by the tool model when they exceed MaxInjectedTokens, instead
+	// of dropping the lowest-ranked ones: "query", "chat", "memory", "mcp",
+	// or "*" for all of them. Summaries keep the IDs of the memories they draw on.
+	CompressCommands []string `json:"compress_commands,omitempty"`
+	// EmbeddingTemplate is a Go text/template for the text embedded for each
+	// memory, e.g. "{{.Text}}\nTags: {{.Tags}}". It may reference {{.Text}},
+	// {{.Tags}} (comma-separated), {{.Kind}}, {{.Source}}, and {{.Title}}, the
+	// file name of the document an ingested chunk came from. Empty embeds the
+	// text alone; queries are always embedded as typed.
+	EmbeddingTemplate string `json:"embedding_template,omitempty"`
+	// ChunkTokens splits memories longer than this many tokens into chunks
+	// stored and embedded as child memories; a search matching any chunk
+	// returns the whole memory. 0 (the default) embeds long memories whole.
+	ChunkTokens int `json:"chunk_tokens,omitempty"`
+	// CacheSize is how many retrieval responses long-running server modes
+	// keep for repeated queries; a negative size turns the cache off.
+	// CacheTTLSecs is how long a response is reused. Any change to the
+	// memories empties the cache.
+	CacheSize    int `json:"cache_size"`
+	CacheTTLSecs int `json:"cache_ttl_secs"`
+	// ArchiveAfterDays and ArchiveBelowConfidence are the archival policy used
+	// by 'gomor memory archive': memories not retrieved for that many days
+	// whose confidence is below the threshold move to the archive.
+	ArchiveAfterDays       int     `json:"archive_after_days,omitempty"`
+	ArchiveBelowConfidence float64 `json:"archive_below_confidence,omitempty"`
+
+	// Deprecated: MaxInjectedChars is read from older configs and converted
+	// to MaxInjectedTokens; use max_injected_tokens instead.
+	MaxInjectedChars int `json:"max_injected_chars,omitempty"`
+
+	// KindWeights scales the relevance of each memory kind; kinds not listed use 1.0.
+	KindWeights map[string]float64 `json:"kind_weights,omitempty"`
+	// KindTopK caps how many results of a kind are returned; 0 or missing means only MemoryTopK applies.
+	KindTopK map[string]int `json:"kind_top_k,omitempty"`
+}
+
+// Compresses reports whether retrieved memories are compressed for command.
+func (c MemoryConfig) Compresses(command string) bool {
+	for _, name := range c.CompressCommands {
+		if name == "*" || (command != "" && name == command) {
+			return true
+		}
+	}
+	return false
+}
+
+// EmbeddingQueueConfig controls the background embedding worker used by server modes
+type EmbeddingQueueConfig struct {
+	Concurrency       int     `json:"concurrency"`
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	PollIntervalSecs  int     `json:"poll_interval_secs"`
+	BatchSize         int     `json:"batch_size"`
+	MaxAttempts       int     `json:"max_attempts"`
+}
+
+// IngestConfig controls how documents are chunked by `gomor ingest`
+type IngestConfig struct {
+	ChunkSize    int    `json:"chunk_size"`    // target chunk length in characters
+	ChunkOverlap int    `json:"chunk_overlap"` // characters repeated between adjacent chunks
+	BatchSize    int    `json:"batch_size"`    // chunks embedded per provider call
+	PDFToText    string `json:"pdf_to_text"`   // external command that writes a PDF's text to stdout
+}
+
+// Chat context policies
+const (
+	ContextPolicyLastTurns   = "last_turns"   // send the most recent ContextTurns turns
+	ContextPolicyTokenBudget = "token_budget" // send as much as fits in ContextTokens, dropping middle turns first
+	ContextPolicySummary     = "summary"      // send the most recent ContextTurns turns plus a summary of older ones
+)
+
+// ChatConfig controls how chat answers are shown and what context they get
+type ChatConfig struct {
+	RawMarkdown   bool   `json:"raw_markdown,omitempty"` // print answers as-is instead of rendering markdown
+	ContextPolicy string `json:"context_policy"`         // how earlier turns are assembled into context
+	ContextTurns  int    `json:"context_turns"`          // turns sent verbatim by last_turns and summary
+	ContextTokens int    `json:"context_tokens"`         // token budget for token_budget
+
+	// SystemPrompt is a Go text/template for the system prompt of chat
+	// sessions. It may reference {{.History}}, the earlier turns as the
+	// context policy renders them, {{.Memories}}, the memories relevant to
+	// the prompt, {{.Date}}, and {{.CWD}}. Empty sends the history alone.
+	SystemPrompt string `json:"system_prompt,omitempty"`
+}
+
+// ModelPrice is a model's price in USD per million tokens
+type ModelPrice struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// HistoryConfig controls what is saved to the conversation history
+type HistoryConfig struct {
+	// AutoSave saves each one-off prompt and its answer as a session of its
+	// own. Unset (null) saves them.
+	AutoSave *bool `json:"auto_save,omitempty"`
+}
+
+// SavesQueries reports whether one-off prompts are saved to history.
+func (h HistoryConfig) SavesQueries() bool {
+	return h.AutoSave == nil || *h.AutoSave
+}
+
+// BudgetConfig sets spending limits for chat requests
+type BudgetConfig struct {
+	DailyUSD   float64 `json:"daily_usd,omitempty"`   // daily spend that triggers a warning; 0 means no limit
+	MonthlyUSD float64 `json:"monthly_usd,omitempty"` // monthly spend that triggers a warning; 0 means no limit
+
+	// Prices adds or overrides entries of the built-in pricing table, keyed by
+	// "provider/model" or a model ID prefix such as "openai/gpt-4o".
+	Prices map[string]ModelPrice `json:"prices,omitempty"`
+}
+
+// ObsidianConfig mirrors memories into an Obsidian vault as markdown notes
+type ObsidianConfig struct {
+	VaultPath string `json:"vault_path,omitempty"` // vault root; empty disables mirroring
+	Folder    string `json:"folder"`               // folder inside the vault that holds the notes
+	AutoSync  bool   `json:"auto_sync,omitempty"`  // write a note whenever a memory is saved
+}
+
+// Moderation actions for a flagged category
+const (
+	ModerationBlock = "block" // keep the text out of history and memories
+	ModerationAllow = "allow" // ignore the category
+)
+
+// ModerationConfig screens prompts, answers, and agent-saved memories before
+// they are stored. It is off unless a provider or rules are set.
+type ModerationConfig struct {
+	Provider   string              `json:"provider,omitempty"`   // "openai" to call the moderation endpoint; empty for rules only
+	Rules      map[string][]string `json:"rules,omitempty"`      // category -> regular expressions that flag it
+	Categories map[string]string   `json:"categories,omitempty"` // category -> "block" or "allow"; unlisted categories are blocked
+}
+
+// Web search providers
+const (
+	WebSearchTavily  = "tavily"
+	WebSearchBrave   = "brave"
+	WebSearchSearxNG = "searxng"
+)
+
+// WebSearchConfig selects the search provider behind the --web flag
+type WebSearchConfig struct {
+	Provider   string `json:"provider,omitempty"`    // "tavily", "brave", or "searxng"; empty disables web search
+	APIKey     string `json:"api_key,omitempty"`     // not needed for searxng
+	BaseURL    string `json:"base_url,omitempty"`    // the instance URL for searxng; overrides the API URL otherwise
+	MaxResults int    `json:"max_results,omitempty"` // snippets fetched per prompt, default 5
+}
+
+// SMTPConfig is the mail server digests are sent through
+type SMTPConfig struct {
+	Host     string   `json:"host,omitempty"`
+	Port     int      `json:"port,omitempty"` // default 587
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from,omitempty"`
+	To       []string `json:"to,omitempty"`
+}
+
+// DigestConfig says where 'gomor digest --send' delivers the digest
+type DigestConfig struct {
+	WebhookURL string     `json:"webhook_url,omitempty"` // receives a JSON POST of {"subject", "text"}
+	SMTP       SMTPConfig `json:"smtp"`
+}
+
+// ChaosConfig injects failures and latency into providers and the memory
+// store to exercise retry, fallback, and degraded paths. It only applies when
+// debug is on.
+type ChaosConfig struct {
+	ErrorRate   float64  `json:"error_rate,omitempty"`   // fraction of calls that fail, 0 to 1
+	ErrorStatus int      `json:"error_status,omitempty"` // HTTP status of injected provider errors, default 503
+	LatencyRate float64  `json:"latency_rate,omitempty"` // fraction of calls that are delayed, 0 to 1
+	LatencyMs   int      `json:"latency_ms,omitempty"`   // delay added to a slowed call
+	Targets     []string `json:"targets,omitempty"`      // "chat", "embedding", "store"; empty means all
+	Seed        *int64   `json:"seed,omitempty"`         // makes the injected failures repeatable
+}
+
+// MCPServerConfig is an MCP server whose tools chat may call. Set Command to
+// run the server over stdio, or URL to connect over streamable HTTP.
+type MCPServerConfig struct {
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"` // added to the server's environment
+	URL     string            `json:"url,omitempty"`
+}
+
+// MCPConfig lists the MCP servers 'gomor chat' connects to as a client, and
+// secures 'gomor mcp --http' as a server
+type MCPConfig struct {
+	Servers   map[string]MCPServerConfig `json:"servers,omitempty"`    // keyed by a short name that prefixes the server's tools
+	HTTPToken string                     `json:"http_token,omitempty"` // bearer token HTTP clients of 'gomor mcp --http' must send; empty allows any
+}
+
+// HooksConfig runs shell commands around each query. Every hook gets a JSON
+// event on stdin.
+type HooksConfig struct {
+	PreQuery       string `json:"pre_query,omitempty"`       // may rewrite the prompt by writing {"prompt": "..."}; a non-zero exit cancels the query
+	PostResponse   string `json:"post_response,omitempty"`   // told about each answer; failures are only reported
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"` // per hook run, 30 by default
 }
 
 // Config represents the application configuration
 type Config struct {
-	Providers ProviderConfigs `json:"providers"`
-	Model     ModelConfig     `json:"model"`
-	Memory    MemoryConfig    `json:"memory"`
-	Debug     bool            `json:"debug,omitempty"`
+	Providers      ProviderConfigs      `json:"providers"`
+	Model          ModelConfig          `json:"model"`
+	Memory         MemoryConfig         `json:"memory"`
+	EmbeddingQueue EmbeddingQueueConfig `json:"embedding_queue"`
+	Ingest         IngestConfig         `json:"ingest"`
+	Obsidian       ObsidianConfig       `json:"obsidian"`
+	Chat           ChatConfig           `json:"chat"`
+	History        HistoryConfig        `json:"history"`
+	Budget         BudgetConfig         `json:"budget"`
+	Moderation     ModerationConfig     `json:"moderation"`
+	MCP            MCPConfig            `json:"mcp"`
+	WebSearch      WebSearchConfig      `json:"web_search"`
+	Digest         DigestConfig         `json:"digest"`
+	Hooks          HooksConfig          `json:"hooks"`
+	Offline        bool                 `json:"offline,omitempty"` // refuse network calls to providers
+	Debug          bool                 `json:"debug,omitempty"`
+	Chaos          ChaosConfig          `json:"chaos"` // debug only
+}
+
+// WithoutSecrets returns a copy of c without its API keys, SMTP password, MCP
+// HTTP token, and MCP server environments, for sharing the config with another
+// machine.
+func (c Config) WithoutSecrets() Config {
+	c.Providers.OpenAI.APIKey = ""
+	c.Providers.Google.APIKey = ""
+	c.Providers.Anthropic.APIKey = ""
+	c.WebSearch.APIKey = ""
+	c.Digest.SMTP.Password = ""
+	c.MCP.HTTPToken = ""
+	if c.MCP.Servers != nil {
+		servers := make(map[string]MCPServerConfig, len(c.MCP.Servers))
+		for name, server := range c.MCP.Servers {
+			server.Env = nil
+			servers[name] = server
+		}
+		c.MCP.Servers = servers
+	}
+	return c
+}
+
+// KeepSecrets fills the secrets c lacks from other, so that a shared config
+// replacing other keeps the keys already set up on this machine.
+func (c *Config) KeepSecrets(other *Config) {
+	keep := func(secret *string, previous string) {
+		if *secret == "" {
+			*secret = previous
+		}
+	}
+	keep(&c.Providers.OpenAI.APIKey, other.Providers.OpenAI.APIKey)
+	keep(&c.Providers.Google.APIKey, other.Providers.Google.APIKey)
+	keep(&c.Providers.Anthropic.APIKey, other.Providers.Anthropic.APIKey)
+	keep(&c.WebSearch.APIKey, other.WebSearch.APIKey)
+	keep(&c.Digest.SMTP.Password, other.Digest.SMTP.Password)
+	keep(&c.MCP.HTTPToken, other.MCP.HTTPToken)
+	for name, server := range c.MCP.Servers {
+		if previous, ok := other.MCP.Servers[name]; ok && server.Env == nil {
+			server.Env = previous.Env
+			c.MCP.Servers[name] = server
+		}
+	}
+}
+
+// forceOffline is set by the global --offline flag and overrides the config.
+var forceOffline bool
+
+// SetOffline forces offline mode for every config loaded afterwards.
+func SetOffline(offline bool) {
+	forceOffline = offline
 }
 
 // DefaultConfig returns the default configuration
@@ -106,42 +533,140 @@ func DefaultConfig() *Config {
 			},
 		},
 		Memory: MemoryConfig{
-			MinSimilarity:    0.40,
-			MemoryTopK:       10,
-			HistoryTopK:      10,
-			MaxInjectedChars: 4000,
-			FTSStrategy:      FTSStrategyAuto,
+			MinSimilarity:       0.40,
+			MemoryTopK:          10,
+			HistoryTopK:         10,
+			MaxInjectedTokens:   1000,
+			FTSStrategy:         FTSStrategyAuto,
+			FTSLatencyBudgetMs:  3000,
+			FusionVectorWeight:  0.6,
+			TransformTimeoutMs:  2000,
+			EmbeddingTimeoutMs:  1500,
+			RewriteHistoryTurns: 4,
+			Language:            MemoryLanguageAuto,
+			QueryClassifier:     QueryClassifierOff,
+			FTSMinTokenLength:   1,
+			CacheSize:           128,
+			CacheTTLSecs:        300,
+			KindWeights: map[string]float64{
+				"preference":     1.0,
+				"fact":           1.0,
+				"episodic":       0.9,
+				"document-chunk": 0.8,
+			},
+			KindTopK: map[string]int{
+				"document-chunk": 3,
+			},
+		},
+		EmbeddingQueue: EmbeddingQueueConfig{
+			Concurrency:       2,
+			RequestsPerSecond: 5,
+			PollIntervalSecs:  5,
+			BatchSize:         32,
+			MaxAttempts:       5,
+		},
+		Ingest: IngestConfig{
+			ChunkSize:    1200,
+			ChunkOverlap: 200,
+			BatchSize:    32,
+			PDFToText:    "pdftotext",
+		},
+		Obsidian: ObsidianConfig{
+			Folder: "gomor",
+		},
+		Chat: ChatConfig{
+			ContextPolicy: ContextPolicyLastTurns,
+			ContextTurns:  20,
+			ContextTokens: 4000,
 		},
 		Debug: false,
 	}
 }
 
-// GetConfigPath returns the path to the configuration file
-func GetConfigPath() (string, error) {
+// goos and userConfigDir are variables so tests can resolve Windows paths
+// on any platform.
+var (
+	goos          = runtime.GOOS
+	userConfigDir = os.UserConfigDir
+)
+
+// GetGomorDir returns the directory holding the config, the memory database,
+// and backups, creating it if needed. It is ~/.gomor, except on Windows where
+// it is %APPDATA%\gomor, unless a ~/.gomor from an earlier install exists.
+func GetGomorDir() (string, error) {
+	dir, err := gomorDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create gomor directory: %w", err)
+	}
+	return dir, nil
+}
+
+func gomorDir() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return "", fmt.Errorf("failed to get user home directory: %v", err)
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	legacy := filepath.Join(homeDir, GomorDir)
+	if goos != "windows" {
+		return legacy, nil
+	}
+	if info, err := os.Stat(legacy); err == nil && info.IsDir() {
+		return legacy, nil
+	}
+	appData, err := userConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get application data directory: %w", err)
 	}
-	gDir := filepath.Join(homeDir, GomorDir)
-	if err := os.MkdirAll(gDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create gomor directory: %v", err)
+	return filepath.Join(appData, strings.TrimPrefix(GomorDir, ".")), nil
+}
+
+// GetConfigPath returns the path to the configuration file
+func GetConfigPath() (string, error) {
+	gDir, err := GetGomorDir()
+	if err != nil {
+		return "", err
 	}
 	return filepath.Join(gDir, SettingFile), nil
 }
 
 // GetDBPath returns the path to the memory database file.
 func GetDBPath() (string, error) {
-	homeDir, err := os.UserHomeDir()
+	gDir, err := GetGomorDir()
 	if err != nil {
-		return "", fmt.Errorf("failed to get user home directory: %w", err)
+		return "", err
 	}
+	return filepath.Join(gDir, DBFile), nil
+}
 
-	gDir := filepath.Join(homeDir, GomorDir)
-	if err := os.MkdirAll(gDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create gomor directory: %w", err)
+// GetBackupDir returns the directory holding automatic database backups.
+func GetBackupDir() (string, error) {
+	gDir, err := GetGomorDir()
+	if err != nil {
+		return "", err
 	}
 
-	return filepath.Join(gDir, DBFile), nil
+	backupDir := filepath.Join(gDir, BackupsDir)
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	return backupDir, nil
+}
+
+// ExpandHome replaces a leading "~" in path with the user's home directory.
+// Both "~/" and, on Windows, "~\" start a home-relative path.
+func ExpandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !(goos == "windows" && strings.HasPrefix(path, `~\`)) {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve home directory: %w", err)
+	}
+	return filepath.Join(home, path[1:]), nil
 }
 
 // LoadConfig loads the configuration from file
@@ -153,21 +678,26 @@ func LoadConfig() (*Config, error) {
 
 	// If config file doesn't exist, return default config
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return DefaultConfig(), nil
+		config := DefaultConfig()
+		config.Offline = forceOffline
+		return config, nil
 	}
 
 	data, err := os.ReadFile(configPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %v", err)
+		return nil, ConfigErrorf("failed to read config file: %v", err)
 	}
 
 	var config Config
 	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %v", err)
+		return nil, ConfigErrorf("failed to parse config file: %v", err)
 	}
 
 	// Apply defaults for missing fields
 	applyDefaults(&config)
+	if forceOffline {
+		config.Offline = true
+	}
 
 	return &config, nil
 }
@@ -203,12 +733,99 @@ func applyDefaults(config *Config) {
 	if config.Memory.HistoryTopK == 0 {
 		config.Memory.HistoryTopK = defaultConfig.Memory.HistoryTopK
 	}
-	if config.Memory.MaxInjectedChars == 0 {
-		config.Memory.MaxInjectedChars = defaultConfig.Memory.MaxInjectedChars
+	if config.Memory.MaxInjectedTokens == 0 {
+		// Older configs set a character budget; about 4 characters make a token.
+		if config.Memory.MaxInjectedChars > 0 {
+			config.Memory.MaxInjectedTokens = (config.Memory.MaxInjectedChars + 3) / 4
+		} else {
+			config.Memory.MaxInjectedTokens = defaultConfig.Memory.MaxInjectedTokens
+		}
 	}
+	config.Memory.MaxInjectedChars = 0
 	if config.Memory.FTSStrategy == "" {
 		config.Memory.FTSStrategy = defaultConfig.Memory.FTSStrategy
 	}
+	if config.Memory.FTSLatencyBudgetMs == 0 {
+		config.Memory.FTSLatencyBudgetMs = defaultConfig.Memory.FTSLatencyBudgetMs
+	}
+	if config.Memory.FusionVectorWeight <= 0 || config.Memory.FusionVectorWeight >= 1 {
+		config.Memory.FusionVectorWeight = defaultConfig.Memory.FusionVectorWeight
+	}
+	if config.Memory.TransformTimeoutMs == 0 {
+		config.Memory.TransformTimeoutMs = defaultConfig.Memory.TransformTimeoutMs
+	}
+	if config.Memory.EmbeddingTimeoutMs == 0 {
+		config.Memory.EmbeddingTimeoutMs = defaultConfig.Memory.EmbeddingTimeoutMs
+	}
+	if config.Memory.RewriteHistoryTurns == 0 {
+		config.Memory.RewriteHistoryTurns = defaultConfig.Memory.RewriteHistoryTurns
+	}
+	if config.Memory.Language == "" {
+		config.Memory.Language = defaultConfig.Memory.Language
+	}
+	if config.Memory.QueryClassifier == "" {
+		config.Memory.QueryClassifier = defaultConfig.Memory.QueryClassifier
+	}
+	if config.Memory.FTSMinTokenLength <= 0 {
+		config.Memory.FTSMinTokenLength = defaultConfig.Memory.FTSMinTokenLength
+	}
+	if config.Memory.CacheSize == 0 {
+		config.Memory.CacheSize = defaultConfig.Memory.CacheSize
+	}
+	if config.Memory.CacheTTLSecs <= 0 {
+		config.Memory.CacheTTLSecs = defaultConfig.Memory.CacheTTLSecs
+	}
+	if config.Memory.KindWeights == nil {
+		config.Memory.KindWeights = defaultConfig.Memory.KindWeights
+	}
+	if config.Memory.KindTopK == nil {
+		config.Memory.KindTopK = defaultConfig.Memory.KindTopK
+	}
+
+	// Apply default embedding queue config if not set
+	if config.EmbeddingQueue.Concurrency == 0 {
+		config.EmbeddingQueue.Concurrency = defaultConfig.EmbeddingQueue.Concurrency
+	}
+	if config.EmbeddingQueue.RequestsPerSecond == 0 {
+		config.EmbeddingQueue.RequestsPerSecond = defaultConfig.EmbeddingQueue.RequestsPerSecond
+	}
+	if config.EmbeddingQueue.PollIntervalSecs == 0 {
+		config.EmbeddingQueue.PollIntervalSecs = defaultConfig.EmbeddingQueue.PollIntervalSecs
+	}
+	if config.EmbeddingQueue.BatchSize == 0 {
+		config.EmbeddingQueue.BatchSize = defaultConfig.EmbeddingQueue.BatchSize
+	}
+	if config.EmbeddingQueue.MaxAttempts == 0 {
+		config.EmbeddingQueue.MaxAttempts = defaultConfig.EmbeddingQueue.MaxAttempts
+	}
+
+	// Apply default ingest config if not set
+	if config.Ingest.ChunkSize == 0 {
+		config.Ingest.ChunkSize = defaultConfig.Ingest.ChunkSize
+	}
+	if config.Ingest.ChunkOverlap == 0 {
+		config.Ingest.ChunkOverlap = defaultConfig.Ingest.ChunkOverlap
+	}
+	if config.Ingest.BatchSize == 0 {
+		config.Ingest.BatchSize = defaultConfig.Ingest.BatchSize
+	}
+	if config.Ingest.PDFToText == "" {
+		config.Ingest.PDFToText = defaultConfig.Ingest.PDFToText
+	}
+	if config.Obsidian.Folder == "" {
+		config.Obsidian.Folder = defaultConfig.Obsidian.Folder
+	}
+
+	// Apply default chat context config if not set
+	if config.Chat.ContextPolicy == "" {
+		config.Chat.ContextPolicy = defaultConfig.Chat.ContextPolicy
+	}
+	if config.Chat.ContextTurns == 0 {
+		config.Chat.ContextTurns = defaultConfig.Chat.ContextTurns
+	}
+	if config.Chat.ContextTokens == 0 {
+		config.Chat.ContextTokens = defaultConfig.Chat.ContextTokens
+	}
 }
 
 // SaveConfig saves the configuration to file
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGomorDirOnWindows(t *testing.T) {
+	oldGOOS, oldConfigDir := goos, userConfigDir
+	defer func() { goos, userConfigDir = oldGOOS, oldConfigDir }()
+
+	home, appData := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	goos = "windows"
+	userConfigDir = func() (string, error) { return appData, nil }
+
+	dir, err := gomorDir()
+	if err != nil {
+		t.Fatalf("gomorDir: %v", err)
+	}
+	if want := filepath.Join(appData, "gomor"); dir != want {
+		t.Fatalf("expected %s on a fresh install, got %s", want, dir)
+	}
+
+	// An earlier install keeps its ~/.gomor.
+	legacy := filepath.Join(home, GomorDir)
+	if err := os.Mkdir(legacy, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if dir, err = gomorDir(); err != nil || dir != legacy {
+		t.Fatalf("expected %s, got %s (%v)", legacy, dir, err)
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	for path, want := range map[string]string{
+		"~":            home,
+		"~/notes":      filepath.Join(home, "notes"),
+		"/vault":       "/vault",
+		"~alice/notes": "~alice/notes",
+	} {
+		if got, err := ExpandHome(path); err != nil || got != want {
+			t.Errorf("ExpandHome(%q) = %q (%v), want %q", path, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrConfig matches, with errors.Is, every error caused by a missing or
+// invalid config, such as an unset model or API key.
+var ErrConfig = errors.New("config error")
+
+type configError struct {
+	err error
+}
+
+func (e *configError) Error() string { return e.err.Error() }
+
+func (e *configError) Unwrap() error { return e.err }
+
+func (e *configError) Is(target error) bool { return target == ErrConfig }
+
+// ConfigErrorf formats an error that matches ErrConfig.
+func ConfigErrorf(format string, args ...any) error {
+	return &configError{err: fmt.Errorf(format, args...)}
+}
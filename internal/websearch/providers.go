@@ -0,0 +1,133 @@
+package websearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	tavilyURL = "https://api.tavily.com"
+	braveURL  = "https://api.search.brave.com"
+)
+
+// Tavily searches with the Tavily API.
+type Tavily struct {
+	APIKey     string
+	BaseURL    string // default https://api.tavily.com
+	HTTPClient *http.Client
+}
+
+// Search returns up to limit results for query.
+func (t *Tavily) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	body, err := json.Marshal(map[string]any{"query": query, "max_results": limit})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint(t.BaseURL, tavilyURL, "/search"), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+t.APIKey)
+
+	var resp struct {
+		Results []struct {
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Content string `json:"content"`
+		} `json:"results"`
+	}
+	if err := do(t.HTTPClient, "tavily", req, &resp); err != nil {
+		return nil, err
+	}
+	results := make([]Result, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		results = append(results, Result{Title: clean(r.Title), URL: r.URL, Snippet: clean(r.Content)})
+	}
+	return limitResults(results, limit), nil
+}
+
+// Brave searches with the Brave Search API.
+type Brave struct {
+	APIKey     string
+	BaseURL    string // default https://api.search.brave.com
+	HTTPClient *http.Client
+}
+
+// Search returns up to limit results for query.
+func (b *Brave) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	params := url.Values{"q": {query}, "count": {strconv.Itoa(limit)}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint(b.BaseURL, braveURL, "/res/v1/web/search")+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Subscription-Token", b.APIKey)
+
+	var resp struct {
+		Web struct {
+			Results []struct {
+				Title       string `json:"title"`
+				URL         string `json:"url"`
+				Description string `json:"description"`
+			} `json:"results"`
+		} `json:"web"`
+	}
+	if err := do(b.HTTPClient, "brave", req, &resp); err != nil {
+		return nil, err
+	}
+	results := make([]Result, 0, len(resp.Web.Results))
+	for _, r := range resp.Web.Results {
+		results = append(results, Result{Title: clean(r.Title), URL: r.URL, Snippet: clean(r.Description)})
+	}
+	return limitResults(results, limit), nil
+}
+
+// SearxNG searches a SearxNG instance, which must allow the JSON format.
+type SearxNG struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// Search returns up to limit results for query.
+func (s *SearxNG) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	params := url.Values{"q": {query}, "format": {"json"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint(s.BaseURL, "", "/search")+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Results []struct {
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Content string `json:"content"`
+		} `json:"results"`
+	}
+	if err := do(s.HTTPClient, "searxng", req, &resp); err != nil {
+		return nil, err
+	}
+	results := make([]Result, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		results = append(results, Result{Title: clean(r.Title), URL: r.URL, Snippet: clean(r.Content)})
+	}
+	return limitResults(results, limit), nil
+}
+
+func endpoint(baseURL, defaultURL, path string) string {
+	if baseURL == "" {
+		baseURL = defaultURL
+	}
+	return strings.TrimRight(baseURL, "/") + path
+}
+
+func limitResults(results []Result, limit int) []Result {
+	if limit > 0 && len(results) > limit {
+		return results[:limit]
+	}
+	return results
+}
@@ -0,0 +1,162 @@
+// Package websearch fetches web results for a prompt from Tavily, Brave, or a
+// SearxNG instance, and fuses them with retrieved memories into a prompt that
+// asks the model to cite them.
+package websearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+// ErrNotConfigured is returned when no search provider is configured.
+var ErrNotConfigured = utils.ConfigErrorf(`web search is not configured: set web_search.provider to "tavily", "brave", or "searxng" in the config`)
+
+// ErrOffline is returned instead of a Searcher when offline mode is on.
+var ErrOffline = errors.New(`offline mode: web search is disabled (drop --offline or set "offline": false in the config)`)
+
+// DefaultMaxResults is how many results are fetched when the config sets none.
+const DefaultMaxResults = 5
+
+// requestTimeout bounds one search request.
+const requestTimeout = 15 * time.Second
+
+// Result is one web page found for a query.
+type Result struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Snippet string `json:"snippet"`
+}
+
+// Searcher searches the web.
+type Searcher interface {
+	Search(ctx context.Context, query string, limit int) ([]Result, error)
+}
+
+// FromConfig returns the configured search provider and how many results to
+// fetch with it.
+func FromConfig(config *utils.Config) (Searcher, int, error) {
+	if config.Offline {
+		return nil, 0, ErrOffline
+	}
+	cfg := config.WebSearch
+	limit := cfg.MaxResults
+	if limit <= 0 {
+		limit = DefaultMaxResults
+	}
+
+	httpClient := &http.Client{Timeout: requestTimeout}
+	switch cfg.Provider {
+	case "":
+		return nil, 0, ErrNotConfigured
+	case utils.WebSearchTavily:
+		if cfg.APIKey == "" {
+			return nil, 0, fmt.Errorf("web_search.api_key is required for tavily")
+		}
+		return &Tavily{APIKey: cfg.APIKey, BaseURL: cfg.BaseURL, HTTPClient: httpClient}, limit, nil
+	case utils.WebSearchBrave:
+		if cfg.APIKey == "" {
+			return nil, 0, fmt.Errorf("web_search.api_key is required for brave")
+		}
+		return &Brave{APIKey: cfg.APIKey, BaseURL: cfg.BaseURL, HTTPClient: httpClient}, limit, nil
+	case utils.WebSearchSearxNG:
+		if cfg.BaseURL == "" {
+			return nil, 0, fmt.Errorf("web_search.base_url is required for searxng")
+		}
+		return &SearxNG{BaseURL: cfg.BaseURL, HTTPClient: httpClient}, limit, nil
+	default:
+		return nil, 0, fmt.Errorf("unknown web search provider %q (valid: tavily, brave, searxng)", cfg.Provider)
+	}
+}
+
+// do sends req and decodes the JSON response into v, classifying HTTP
+// failures like provider errors.
+func do(httpClient *http.Client, provider string, req *http.Request, v any) error {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return client.WrapError(provider, 0, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		message := strings.TrimSpace(string(body))
+		if message == "" {
+			message = resp.Status
+		}
+		return client.WrapError(provider, resp.StatusCode, fmt.Errorf("%s search failed: %s", provider, message))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", provider, err)
+	}
+	return nil
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// clean strips markup and collapses whitespace in a title or snippet.
+func clean(text string) string {
+	text = htmlTag.ReplaceAllString(text, "")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Prompt asks prompt with the web results and the retrieved memories as
+// context. Results are numbered so the answer can cite them as [1], [2], ....
+func Prompt(prompt, memories string, results []Result) string {
+	var sb strings.Builder
+	sb.WriteString("Answer the prompt at the end. ")
+	sb.WriteString("Use the web results when they help and cite each one you use by its number in brackets, e.g. [1]. ")
+	sb.WriteString("The memories are what you know about the user; prefer them for facts about the user and their work.\n")
+	if memories != "" {
+		sb.WriteString("\nMemories:\n" + strings.TrimSpace(memories) + "\n")
+	}
+	if len(results) > 0 {
+		sb.WriteString("\nWeb results:\n")
+		for i, r := range results {
+			fmt.Fprintf(&sb, "[%d] %s (%s)\n", i+1, r.Title, r.URL)
+			if r.Snippet != "" {
+				sb.WriteString(r.Snippet + "\n")
+			}
+		}
+	}
+	sb.WriteString("\nPrompt: " + prompt)
+	return sb.String()
+}
+
+var citation = regexp.MustCompile(`\[(\d+)\]`)
+
+// Sources lists the results answer cites, in order of their numbers, or every
+// result when it cites none.
+func Sources(answer string, results []Result) string {
+	cited := make(map[int]bool)
+	for _, m := range citation.FindAllStringSubmatch(answer, -1) {
+		if n, err := strconv.Atoi(m[1]); err == nil && n >= 1 && n <= len(results) {
+			cited[n] = true
+		}
+	}
+
+	var sb strings.Builder
+	for i, r := range results {
+		if len(cited) == 0 || cited[i+1] {
+			fmt.Fprintf(&sb, "[%d] %s - %s\n", i+1, r.Title, r.URL)
+		}
+	}
+	if sb.Len() == 0 {
+		return ""
+	}
+	return "Sources:\n" + sb.String()
+}
@@ -0,0 +1,141 @@
+package websearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/austiecodes/gomor/internal/client"
+	"github.com/austiecodes/gomor/internal/utils"
+)
+
+func TestProvidersParseResults(t *testing.T) {
+	tests := []struct {
+		name     string
+		reply    string
+		searcher func(url string) Searcher
+		check    func(t *testing.T, r *http.Request)
+	}{
+		{
+			name:  "tavily",
+			reply: `{"results":[{"title":"Go 1.25","url":"https://go.dev/blog","content":"Released  today."},{"title":"extra","url":"https://x"}]}`,
+			searcher: func(url string) Searcher {
+				return &Tavily{APIKey: "tvly-key", BaseURL: url}
+			},
+			check: func(t *testing.T, r *http.Request) {
+				var body map[string]any
+				_ = json.NewDecoder(r.Body).Decode(&body)
+				if r.Method != http.MethodPost || r.Header.Get("Authorization") != "Bearer tvly-key" || body["query"] != "go release" {
+					t.Errorf("unexpected tavily request: %s %v %v", r.Method, r.Header, body)
+				}
+			},
+		},
+		{
+			name:  "brave",
+			reply: `{"web":{"results":[{"title":"<strong>Go</strong> 1.25","url":"https://go.dev/blog","description":"Released <strong>today</strong>."}]}}`,
+			searcher: func(url string) Searcher {
+				return &Brave{APIKey: "brave-key", BaseURL: url}
+			},
+			check: func(t *testing.T, r *http.Request) {
+				if r.URL.Path != "/res/v1/web/search" || r.URL.Query().Get("q") != "go release" || r.Header.Get("X-Subscription-Token") != "brave-key" {
+					t.Errorf("unexpected brave request: %s %v", r.URL, r.Header)
+				}
+			},
+		},
+		{
+			name:  "searxng",
+			reply: `{"results":[{"title":"Go 1.25","url":"https://go.dev/blog","content":"Released today."}]}`,
+			searcher: func(url string) Searcher {
+				return &SearxNG{BaseURL: url + "/"}
+			},
+			check: func(t *testing.T, r *http.Request) {
+				if r.URL.Path != "/search" || r.URL.Query().Get("format") != "json" {
+					t.Errorf("unexpected searxng request: %s", r.URL)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tt.check(t, r)
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, tt.reply)
+			}))
+			defer server.Close()
+
+			results, err := tt.searcher(server.URL).Search(context.Background(), "go release", 1)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			want := Result{Title: "Go 1.25", URL: "https://go.dev/blog", Snippet: "Released today."}
+			if len(results) != 1 || results[0] != want {
+				t.Fatalf("expected %+v, got %+v", want, results)
+			}
+		})
+	}
+}
+
+func TestSearchClassifiesHTTPErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid api key", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	_, err := (&Brave{APIKey: "bad", BaseURL: server.URL}).Search(context.Background(), "q", 5)
+	if !errors.Is(err, client.ErrAuth) || !strings.Contains(err.Error(), "invalid api key") {
+		t.Fatalf("expected an auth error with the message, got %v", err)
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	config := utils.DefaultConfig()
+	if _, _, err := FromConfig(config); !errors.Is(err, ErrNotConfigured) {
+		t.Fatalf("expected ErrNotConfigured, got %v", err)
+	}
+
+	config.WebSearch = utils.WebSearchConfig{Provider: utils.WebSearchSearxNG, BaseURL: "http://localhost:8888"}
+	searcher, limit, err := FromConfig(config)
+	if err != nil || limit != DefaultMaxResults {
+		t.Fatalf("FromConfig: %v, limit %d", err, limit)
+	}
+	if _, ok := searcher.(*SearxNG); !ok {
+		t.Fatalf("expected a SearxNG searcher, got %T", searcher)
+	}
+
+	config.Offline = true
+	if _, _, err := FromConfig(config); !errors.Is(err, ErrOffline) {
+		t.Fatalf("expected ErrOffline, got %v", err)
+	}
+}
+
+func TestPromptAndSources(t *testing.T) {
+	results := []Result{
+		{Title: "Go blog", URL: "https://go.dev/blog", Snippet: "Go 1.25 is out."},
+		{Title: "Release notes", URL: "https://go.dev/doc/go1.25"},
+		{Title: "Unused", URL: "https://example.com"},
+	}
+	prompt := Prompt("what's new in go?", "1. The user writes Go daily.", results)
+	for _, want := range []string{"Memories:\n1. The user writes Go daily.", "[1] Go blog (https://go.dev/blog)\nGo 1.25 is out.", "Prompt: what's new in go?"} {
+		if !strings.Contains(prompt, want) {
+			t.Fatalf("expected prompt to contain %q, got:\n%s", want, prompt)
+		}
+	}
+
+	got := Sources("Go 1.25 is out [1], see the notes [2][1].", results)
+	if got != "Sources:\n[1] Go blog - https://go.dev/blog\n[2] Release notes - https://go.dev/doc/go1.25\n" {
+		t.Fatalf("unexpected cited sources:\n%s", got)
+	}
+	if got := Sources("no citations", results[:1]); got != "Sources:\n[1] Go blog - https://go.dev/blog\n" {
+		t.Fatalf("expected every result when none is cited, got:\n%s", got)
+	}
+	if got := Sources("answer", nil); got != "" {
+		t.Fatalf("expected no sources without results, got %q", got)
+	}
+}